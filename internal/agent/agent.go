@@ -8,9 +8,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/croberts/obot/internal/index"
 	"github.com/croberts/obot/internal/model"
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/tokens"
 )
 
 // Agent executes processes and performs file operations.
@@ -19,8 +21,9 @@ type Agent struct {
 	mu sync.Mutex
 
 	// Multi-model coordination
-	models       *model.Coordinator
-	currentModel orchestrate.ModelType
+	models        *model.Coordinator
+	currentModel  orchestrate.ModelType
+	modelOverride orchestrate.ModelType
 
 	// Context tracking
 	currentSchedule orchestrate.ScheduleID
@@ -38,13 +41,54 @@ type Agent struct {
 	// Callbacks
 	onAction   func(Action)
 	onComplete func()
+	onOutput   func(Action, OutputLine)
+	onTokens   func(TokenUsage)
 
 	// Execution state
 	executing bool
+	replaying bool
 	stopCh    chan struct{}
 
+	// Last model exchange, kept for debugging suspended runs
+	lastPrompt   string
+	lastResponse string
+	lastTokens   TokenUsage
+
+	// The conversation of the current process run
+	conversation []Turn
+
+	// The project of a monorepo the agent's writes are confined to
+	scope *Scope
+
 	// Plugins
 	plugins []Plugin
+
+	// Action policy and the callback asking the user to confirm an action
+	policy        *Policy
+	confirmAction func(PolicyCheck) bool
+
+	// Runs shell commands elsewhere than on the host when set
+	wrapCommand func(command, dir string) (string, []string)
+
+	// Lint, format, and test toolchains in detection order; nil uses the defaults
+	toolchains []Toolchain
+
+	// Compile check after file writes, and the model repairs allowed per write
+	compileCheck   bool
+	compileRepairs int
+
+	// Databases the agent may inspect, as DSNs by name
+	databases map[string]string
+
+	// Background processes by name, stopped when Execute returns
+	background map[string]*BackgroundProcess
+
+	// Language servers by toolchain name, stopped when Execute returns
+	lspMu           sync.Mutex
+	languageServers map[string]*languageServer
+
+	// Trigram indexes for searches without ripgrep, by absolute root
+	searchIndexes map[string]*index.TrigramIndex
 }
 
 // NewAgent creates a new agent with model coordination and tracking.
@@ -66,6 +110,15 @@ func (a *Agent) RegisterPlugin(p Plugin) {
 	a.plugins = append(a.plugins, p)
 }
 
+// SetPolicy sets the policy evaluated before each action. confirm is asked
+// about actions that require confirmation; if it is nil they are blocked.
+func (a *Agent) SetPolicy(policy *Policy, confirm func(PolicyCheck) bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policy = policy
+	a.confirmAction = confirm
+}
+
 // SetContext sets the current schedule and process context
 func (a *Agent) SetContext(schedule orchestrate.ScheduleID, process orchestrate.ProcessID) {
 	a.mu.Lock()
@@ -74,6 +127,14 @@ func (a *Agent) SetContext(schedule orchestrate.ScheduleID, process orchestrate.
 	a.currentProcess = process
 }
 
+// SetModelOverride forces the model used for subsequent executions.
+// Pass an empty ModelType to restore schedule-based selection.
+func (a *Agent) SetModelOverride(m orchestrate.ModelType) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.modelOverride = m
+}
+
 // SetActionCallback sets the callback for when an action is performed
 func (a *Agent) SetActionCallback(callback func(Action)) {
 	a.mu.Lock()
@@ -95,7 +156,9 @@ func (a *Agent) Execute(ctx context.Context, schedule orchestrate.ScheduleID, pr
 	a.executing = true
 	a.currentSchedule = schedule
 	a.currentProcess = process
+	a.conversation = nil
 	plugins := a.plugins
+	override := a.modelOverride
 	a.mu.Unlock()
 
 	// Call OnBeforeExecute hooks
@@ -110,6 +173,8 @@ func (a *Agent) Execute(ctx context.Context, schedule orchestrate.ScheduleID, pr
 
 	var execErr error
 	defer func() {
+		a.StopAllBackground()
+		a.StopLanguageServers()
 		a.mu.Lock()
 		a.executing = false
 		a.mu.Unlock()
@@ -122,12 +187,38 @@ func (a *Agent) Execute(ctx context.Context, schedule orchestrate.ScheduleID, pr
 
 	// Select model based on schedule/process
 	a.currentModel = a.selectModel(schedule, process)
+	if override != "" {
+		a.currentModel = override
+	}
+
+	// Wait for or downgrade from an exhausted quota
+	role, err := a.models.Acquire(ctx, a.currentModel)
+	if err != nil {
+		return err
+	}
+	a.currentModel = role
 
 	client := a.models.Get(a.currentModel)
 	if client == nil {
 		return fmt.Errorf("no client found for model type %v", a.currentModel)
 	}
 
+	// Hand off from the previous model with a summary of its work. A failed
+	// summary is reported through the coordinator and does not stop the run.
+	a.mu.Lock()
+	lastResponse := a.lastResponse
+	a.mu.Unlock()
+	_ = a.models.HandoffProtocol(ctx, model.Handoff{
+		From:     a.models.GetActiveModel(),
+		To:       a.currentModel,
+		Schedule: schedule,
+		Process:  process,
+		Context:  lastResponse,
+	})
+	if handoff := a.models.TakeHandoffPrompt(a.currentModel); handoff != "" {
+		prompt = handoff + "\n" + prompt
+	}
+
 	return a.executeWithModel(ctx, client, prompt)
 }
 
@@ -147,10 +238,30 @@ func (a *Agent) executeWithModel(ctx context.Context, client *ollama.Client, pro
 	systemPrompt := a.agentSystemPrompt()
 
 	// Stream and parse actions
-	resp, _, err := client.Generate(ctx, systemPrompt+"\n\n"+prompt)
+	fullPrompt := systemPrompt + "\n\n" + prompt
+	a.mu.Lock()
+	a.lastPrompt = fullPrompt
+	a.lastResponse = ""
+	a.mu.Unlock()
+
+	resp, stats, err := client.Generate(ctx, fullPrompt)
+	a.addExchange(a.currentModel, fullPrompt, resp, err)
 	if err != nil {
 		return err
 	}
+	used := 0
+	if stats != nil {
+		used = stats.TotalTokens
+	}
+	if used == 0 {
+		// The server did not report counts
+		used = tokens.Count(fullPrompt) + tokens.Count(resp)
+	}
+	a.recordTokens(a.currentModel, int64(used))
+
+	a.mu.Lock()
+	a.lastResponse = resp
+	a.mu.Unlock()
 
 	// Simple completion check for now
 	if strings.Contains(resp, "COMPLETE") {
@@ -183,7 +294,20 @@ ALLOWED ACTIONS:
 11. runCommand(command)
 12. editFile(path, edits)
 13. delegate(content)
-14. COMPLETE
+14. startBackground(name, command, healthCheck)
+15. checkBackground(name)
+16. backgroundLogs(name)
+17. stopBackground(name)
+18. httpRequest(method, url, headers, body, expectStatus, expectBody)
+19. listTables(database)
+20. queryDB(database, selectStatement, maxRows)
+21. dockerBuild(image, context)
+22. dockerRun(image, command, mount)
+23. renameSymbol(path, symbol, newName) - renames a symbol and updates its references through the language server; use it instead of editing each reference
+24. diagnostics(path) - the errors and warnings the language server reports for a file
+25. hover(path, symbol, line) - the type, signature, and documentation of a symbol
+26. references(path, symbol, line) - where a symbol is declared and used
+27. COMPLETE
 
 RULES:
 - You CANNOT select schedules or navigate between processes.
@@ -204,6 +328,7 @@ func (a *Agent) recordAction(action Action) {
 	a.actions = append(a.actions, action)
 	a.tracker.IncrementByType(action.Type)
 	a.recorder.Record(action)
+	a.addTurnLocked(TurnAction, "", actionTurn(&action))
 	callback := a.onAction
 	a.mu.Unlock()
 
@@ -324,12 +449,8 @@ func (a *Agent) CopyDir(ctx context.Context, srcPath, dstPath string) error {
 
 // RunCommand runs a command
 func (a *Agent) RunCommand(ctx context.Context, command string) (int, string, error) {
-	action := Action{
-		Type:    ActionRunCommand,
-		Command: command,
-	}
-	err := a.executeAction(ctx, &action)
-	return action.ExitCode, action.Output, err
+	result, err := a.RunCommandWith(ctx, command, CommandOptions{})
+	return result.ExitCode, result.Output, err
 }
 
 // CompleteProcess marks the current process as completed
@@ -358,6 +479,50 @@ func (a *Agent) GetStats() *ActionStats {
 	return &stats
 }
 
+// LastExchange returns the most recent prompt sent to the model and its response.
+func (a *Agent) LastExchange() (prompt, response string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastPrompt, a.lastResponse
+}
+
+// ReplayLastAction re-executes a copy of the most recent action outside of a
+// process execution. Plugins are bypassed so the replay is not rejected by
+// the limits that suspended the run, except those recording actions, see
+// ReplayPlugin. It is intended for manual debugging.
+func (a *Agent) ReplayLastAction(ctx context.Context) (*Action, error) {
+	a.mu.Lock()
+	if a.executing {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("agent is executing")
+	}
+	if len(a.actions) == 0 {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("no actions to replay")
+	}
+	last := a.actions[len(a.actions)-1]
+	a.executing = true
+	a.replaying = true
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		a.executing = false
+		a.replaying = false
+		a.mu.Unlock()
+	}()
+
+	replay := last
+	replay.ID = ""
+	replay.Timestamp = time.Time{}
+	replay.ExitCode = 0
+	replay.Output = ""
+	replay.Metadata = map[string]any{"replay_of": last.ID}
+
+	err := a.executeAction(ctx, &replay)
+	return &replay, err
+}
+
 // GetRecorder returns the recorder
 func (a *Agent) GetRecorder() *Recorder {
 	return a.recorder
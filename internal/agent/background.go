@@ -0,0 +1,351 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Background process limits
+const (
+	// DefaultHealthTimeout bounds how long a background process may take to
+	// pass its health check.
+	DefaultHealthTimeout = 30 * time.Second
+
+	healthInterval   = 250 * time.Millisecond
+	stopGracePeriod  = 3 * time.Second
+	maxBackgroundLog = 64 * 1024 // bytes of log kept per process
+)
+
+// BackgroundOptions configures a background process.
+type BackgroundOptions struct {
+	Dir string
+
+	// HealthCheck is polled after start until it passes: an http:// or
+	// https:// URL must answer with a status below 500, anything else is
+	// a shell command that must exit 0. Empty skips the check.
+	HealthCheck string
+	Timeout     time.Duration // health check timeout; 0 uses DefaultHealthTimeout
+}
+
+// BackgroundProcess is a long-running command managed by the agent, such as
+// a development server that verification steps talk to. Background
+// processes are stopped when the orchestration process that started them
+// ends.
+type BackgroundProcess struct {
+	Name    string
+	Command string
+	Dir     string
+	Started time.Time
+
+	cmd  *exec.Cmd
+	logs *logBuffer
+	done chan struct{}
+	err  error // set when done is closed
+}
+
+// Running reports whether the process has not exited.
+func (p *BackgroundProcess) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Logs returns the most recent combined output of the process.
+func (p *BackgroundProcess) Logs() string {
+	return p.logs.String()
+}
+
+// status describes the process for action output.
+func (p *BackgroundProcess) status() string {
+	if p.Running() {
+		return fmt.Sprintf("%s running (pid %d, up %s)", p.Name, p.cmd.Process.Pid, time.Since(p.Started).Round(time.Second))
+	}
+	if p.err != nil {
+		return fmt.Sprintf("%s exited: %v", p.Name, p.err)
+	}
+	return p.Name + " exited"
+}
+
+// logBuffer keeps the tail of a process's output.
+type logBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if over := len(b.data) - maxBackgroundLog; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// StartBackground starts command as the background process name and waits
+// for its health check. A process that exits or fails its health check is
+// stopped and reported as an error.
+func (a *Agent) StartBackground(ctx context.Context, name, command string, opts BackgroundOptions) error {
+	action := Action{
+		Type:        ActionStartBackground,
+		Content:     name,
+		Command:     command,
+		Dir:         opts.Dir,
+		HealthCheck: opts.HealthCheck,
+		Timeout:     opts.Timeout,
+	}
+	return a.executeAction(ctx, &action)
+}
+
+// CheckBackground reports whether the background process name is running.
+func (a *Agent) CheckBackground(ctx context.Context, name string) (string, error) {
+	action := Action{Type: ActionCheckBackground, Content: name}
+	err := a.executeAction(ctx, &action)
+	return action.Output, err
+}
+
+// BackgroundLogs returns the recent output of the background process name.
+func (a *Agent) BackgroundLogs(ctx context.Context, name string) (string, error) {
+	action := Action{Type: ActionBackgroundLogs, Content: name}
+	err := a.executeAction(ctx, &action)
+	return action.Output, err
+}
+
+// StopBackground stops the background process name.
+func (a *Agent) StopBackground(ctx context.Context, name string) error {
+	action := Action{Type: ActionStopBackground, Content: name}
+	return a.executeAction(ctx, &action)
+}
+
+// Background returns the background processes, sorted by name.
+func (a *Agent) Background() []*BackgroundProcess {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	procs := make([]*BackgroundProcess, 0, len(a.background))
+	for _, p := range a.background {
+		procs = append(procs, p)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].Name < procs[j].Name })
+	return procs
+}
+
+// StopAllBackground stops every background process. It is called when an
+// orchestration process ends and is safe to call at any time.
+func (a *Agent) StopAllBackground() {
+	a.mu.Lock()
+	procs := a.background
+	a.background = nil
+	a.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range procs {
+		wg.Add(1)
+		go func(p *BackgroundProcess) {
+			defer wg.Done()
+			stopProcess(p)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// handleStartBackground starts a background process and waits until it is healthy.
+func (a *Agent) handleStartBackground(ctx context.Context, action *Action) error {
+	name := action.Content
+	if name == "" {
+		return errors.New("background process name is required")
+	}
+	if action.Command == "" {
+		return errors.New("background process command is required")
+	}
+	a.mu.Lock()
+	if p, ok := a.background[name]; ok && p.Running() {
+		a.mu.Unlock()
+		return fmt.Errorf("background process %q is already running", name)
+	}
+	a.mu.Unlock()
+
+	shell, args := a.shellCommand(action.Command, action.Dir)
+	cmd := exec.Command(shell, args...)
+	cmd.Env = os.Environ()
+	cmd.Dir = action.Dir
+	setProcessGroup(cmd)
+	p := &BackgroundProcess{
+		Name:    name,
+		Command: action.Command,
+		Dir:     action.Dir,
+		cmd:     cmd,
+		logs:    &logBuffer{},
+		done:    make(chan struct{}),
+	}
+	cmd.Stdout = p.logs
+	cmd.Stderr = p.logs
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start background process %q: %w", name, err)
+	}
+	p.Started = time.Now()
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+
+	a.mu.Lock()
+	if a.background == nil {
+		a.background = make(map[string]*BackgroundProcess)
+	}
+	a.background[name] = p
+	a.mu.Unlock()
+	action.Metadata["pid"] = cmd.Process.Pid
+
+	if err := a.waitHealthy(ctx, p, action.HealthCheck, action.Timeout); err != nil {
+		a.mu.Lock()
+		delete(a.background, name)
+		a.mu.Unlock()
+		stopProcess(p)
+		action.Output = p.Logs()
+		return fmt.Errorf("background process %q: %w", name, err)
+	}
+	action.Output = p.status()
+	return nil
+}
+
+// handleCheckBackground reports a background process's state.
+func (a *Agent) handleCheckBackground(ctx context.Context, action *Action) error {
+	p, err := a.backgroundProcess(action.Content)
+	if err != nil {
+		return err
+	}
+	action.Output = p.status()
+	action.Metadata["running"] = p.Running()
+	return nil
+}
+
+// handleBackgroundLogs returns a background process's recent output.
+func (a *Agent) handleBackgroundLogs(ctx context.Context, action *Action) error {
+	p, err := a.backgroundProcess(action.Content)
+	if err != nil {
+		return err
+	}
+	action.Output = p.Logs()
+	return nil
+}
+
+// handleStopBackground stops a background process.
+func (a *Agent) handleStopBackground(ctx context.Context, action *Action) error {
+	p, err := a.backgroundProcess(action.Content)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	delete(a.background, p.Name)
+	a.mu.Unlock()
+	stopProcess(p)
+	action.Output = p.Logs()
+	return nil
+}
+
+// backgroundProcess looks up a background process by name.
+func (a *Agent) backgroundProcess(name string) (*BackgroundProcess, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, ok := a.background[name]
+	if !ok {
+		return nil, fmt.Errorf("no background process named %q", name)
+	}
+	return p, nil
+}
+
+// waitHealthy polls the health check until it passes, the process exits,
+// or the timeout elapses.
+func (a *Agent) waitHealthy(ctx context.Context, p *BackgroundProcess, check string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+	for {
+		if !p.Running() {
+			return fmt.Errorf("exited during startup: %v", p.err)
+		}
+		if check == "" || a.healthy(ctx, check, p.Dir) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check %q did not pass within %s", check, timeout)
+		case <-p.done:
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthy runs one health check. Where commands are wrapped, such as in a
+// container, the check runs there too, since that is where the process
+// listens; a URL is then fetched with curl or wget.
+func (a *Agent) healthy(ctx context.Context, check, dir string) bool {
+	isURL := strings.HasPrefix(check, "http://") || strings.HasPrefix(check, "https://")
+	a.mu.Lock()
+	wrapped := a.wrapCommand != nil
+	a.mu.Unlock()
+	if isURL && wrapped {
+		check = urlCheck(check)
+	} else if isURL {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, check, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode < 500
+	}
+	name, args := a.shellCommand(check, dir)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
+// urlCheck returns a shell command that passes when url answers with a
+// status below 500, as the host's health check of a URL does.
+func urlCheck(url string) string {
+	q := "'" + strings.ReplaceAll(url, "'", `'\''`) + "'"
+	return `code=$(curl -s -o /dev/null -w '%{http_code}' ` + q + ` 2>/dev/null || ` +
+		`wget -S -q -O /dev/null ` + q + ` 2>&1 | awk '/HTTP\//{c=$2} END{print c}'); ` +
+		`[ "${code:-0}" -gt 0 ] 2>/dev/null && [ "$code" -lt 500 ]`
+}
+
+// stopProcess terminates a background process and its children, killing
+// them if they do not exit within the grace period.
+func stopProcess(p *BackgroundProcess) {
+	if !p.Running() {
+		return
+	}
+	terminateProcessGroup(p.cmd)
+	select {
+	case <-p.done:
+	case <-time.After(stopGracePeriod):
+		killProcessGroup(p.cmd)
+		<-p.done
+	}
+}
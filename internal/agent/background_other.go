@@ -0,0 +1,18 @@
+//go:build !unix
+
+package agent
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups are unavailable.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills cmd; there is no graceful stop.
+func terminateProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
+
+// killProcessGroup kills cmd.
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
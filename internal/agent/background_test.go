@@ -0,0 +1,153 @@
+//go:build unix
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBackgroundProcess_Lifecycle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	a := newCommandAgent()
+	ctx := context.Background()
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+
+	// The child sleep must be stopped along with the shell
+	cmd := "echo server up; sleep 30 & echo $! > " + pidFile + "; wait"
+	if err := a.StartBackground(ctx, "dev", cmd, BackgroundOptions{Dir: dir, HealthCheck: srv.URL}); err != nil {
+		t.Fatalf("StartBackground() error = %v", err)
+	}
+	if err := a.StartBackground(ctx, "dev", "sleep 1", BackgroundOptions{}); err == nil {
+		t.Error("starting a second process with the same name should fail")
+	}
+
+	status, err := a.CheckBackground(ctx, "dev")
+	if err != nil || !strings.Contains(status, "dev running") {
+		t.Errorf("CheckBackground() = %q, %v", status, err)
+	}
+	waitFor(t, func() bool {
+		logs, _ := a.BackgroundLogs(ctx, "dev")
+		return strings.Contains(logs, "server up")
+	})
+
+	var childPid int
+	waitFor(t, func() bool {
+		data, _ := os.ReadFile(pidFile)
+		childPid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		return childPid > 0
+	})
+
+	a.StopAllBackground()
+	if len(a.Background()) != 0 {
+		t.Errorf("Background() = %v after StopAllBackground", a.Background())
+	}
+	if _, err := a.CheckBackground(ctx, "dev"); err == nil {
+		t.Error("CheckBackground() should fail for a stopped process")
+	}
+	waitFor(t, func() bool {
+		return syscall.Kill(childPid, 0) != nil
+	})
+}
+
+func TestBackgroundProcess_FailedStart(t *testing.T) {
+	a := newCommandAgent()
+	ctx := context.Background()
+
+	err := a.StartBackground(ctx, "crash", "echo boom; exit 1", BackgroundOptions{HealthCheck: "false"})
+	if err == nil || !strings.Contains(err.Error(), "exited during startup") {
+		t.Errorf("StartBackground() error = %v, want exit during startup", err)
+	}
+	last := a.actions[len(a.actions)-1]
+	if !strings.Contains(last.Output, "boom") {
+		t.Errorf("failed start output = %q, want the process logs", last.Output)
+	}
+
+	err = a.StartBackground(ctx, "slow", "sleep 30", BackgroundOptions{HealthCheck: "false", Timeout: 300 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "did not pass") {
+		t.Errorf("StartBackground() error = %v, want health check timeout", err)
+	}
+	if len(a.Background()) != 0 {
+		t.Errorf("unhealthy processes should be stopped, got %d", len(a.Background()))
+	}
+}
+
+func TestBackgroundProcess_StopAndLogs(t *testing.T) {
+	a := newCommandAgent()
+	ctx := context.Background()
+	if err := a.StartBackground(ctx, "worker", "echo working; sleep 30", BackgroundOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		logs, _ := a.BackgroundLogs(ctx, "worker")
+		return logs == "working\n"
+	})
+	if err := a.StopBackground(ctx, "worker"); err != nil {
+		t.Fatalf("StopBackground() error = %v", err)
+	}
+	if err := a.StopBackground(ctx, "worker"); err == nil {
+		t.Error("stopping an unknown process should fail")
+	}
+
+	buf := &logBuffer{}
+	buf.Write([]byte(strings.Repeat("x", maxBackgroundLog)))
+	buf.Write([]byte("tail"))
+	if s := buf.String(); len(s) != maxBackgroundLog || !strings.HasSuffix(s, "tail") {
+		t.Errorf("log buffer kept %d bytes", len(s))
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestHealthy_Wrapped(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		if _, err := exec.LookPath("wget"); err != nil {
+			t.Skip("neither curl nor wget is installed")
+		}
+	}
+	a := newCommandAgent()
+	var wrapped []string
+	a.SetCommandWrapper(func(command, dir string) (string, []string) {
+		wrapped = append(wrapped, command)
+		return "sh", []string{"-c", command}
+	})
+	status := func(code int) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(code) }))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	ctx := context.Background()
+	if !a.healthy(ctx, status(http.StatusNotFound), "") {
+		t.Error("a 404 should pass the health check")
+	}
+	if a.healthy(ctx, status(http.StatusServiceUnavailable), "") || a.healthy(ctx, closed.URL, "") {
+		t.Error("a 503 or a closed port should fail the health check")
+	}
+	if !a.healthy(ctx, "true", "") || len(wrapped) != 4 {
+		t.Errorf("checks run through the wrapper = %q, want all 4", wrapped)
+	}
+}
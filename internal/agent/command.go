@@ -0,0 +1,260 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommandTimeout bounds commands that do not set their own timeout.
+const DefaultCommandTimeout = 10 * time.Minute
+
+// commandWaitDelay is how long a killed command's children may hold its
+// output open before the pipes are closed.
+const commandWaitDelay = 2 * time.Second
+
+// Output streams
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// OutputLine is a line written by a running command. The zero OutputLine
+// announces that a command started.
+type OutputLine struct {
+	Stream string // StreamStdout or StreamStderr
+	Text   string
+}
+
+// CommandOptions configures a single command.
+type CommandOptions struct {
+	Dir     string        // working directory; empty uses the current one
+	Timeout time.Duration // 0 uses DefaultCommandTimeout
+}
+
+// CommandResult is the outcome of a command.
+type CommandResult struct {
+	Command  string
+	Dir      string
+	ExitCode int // -1 when the command did not run or was killed
+	Stdout   string
+	Stderr   string
+	Output   string // stdout and stderr interleaved as written
+	Duration time.Duration
+	TimedOut bool
+}
+
+// SetOutputCallback sets the callback receiving the output of running
+// commands line by line, for showing long-running commands as they go.
+func (a *Agent) SetOutputCallback(callback func(Action, OutputLine)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onOutput = callback
+}
+
+// RunCommandWith runs a shell command with the given working directory and
+// timeout and returns its separated output, exit code, and duration.
+func (a *Agent) RunCommandWith(ctx context.Context, command string, opts CommandOptions) (CommandResult, error) {
+	action := Action{
+		Type:    ActionRunCommand,
+		Command: command,
+		Dir:     opts.Dir,
+		Timeout: opts.Timeout,
+	}
+	err := a.executeAction(ctx, &action)
+	return commandResult(action), err
+}
+
+// RunCheck runs a shell command that orchestration itself needs between
+// processes, such as measuring test coverage, as a run_command action: under
+// the policy, the scope, and the plugins, and through the command wrapper,
+// like the model's commands. It fails while a process is executing.
+func (a *Agent) RunCheck(ctx context.Context, command string, opts CommandOptions) (CommandResult, error) {
+	a.mu.Lock()
+	if a.executing {
+		a.mu.Unlock()
+		return CommandResult{Command: command, ExitCode: -1}, fmt.Errorf("agent is executing")
+	}
+	a.executing = true
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.executing = false
+		a.mu.Unlock()
+	}()
+	return a.RunCommandWith(ctx, command, opts)
+}
+
+// WriteFile writes a file that orchestration itself produces between
+// processes, such as the project report, as a create_file action, or an
+// edit_file action when the file exists: under the policy, the scope, and
+// the plugins, and recorded like the model's writes. It fails while a
+// process is executing.
+func (a *Agent) WriteFile(ctx context.Context, path, content string) error {
+	a.mu.Lock()
+	if a.executing {
+		a.mu.Unlock()
+		return fmt.Errorf("agent is executing")
+	}
+	a.executing = true
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.executing = false
+		a.mu.Unlock()
+	}()
+	action := Action{Type: ActionCreateFile, Path: path, Content: content}
+	if _, err := os.Stat(path); err == nil {
+		action.Type = ActionEditFile
+	}
+	return a.executeAction(ctx, &action)
+}
+
+// handleRunCommand executes a shell command with timeout and environment
+// protection, capturing stdout and stderr separately and streaming their
+// lines to the output callback.
+func (a *Agent) handleRunCommand(ctx context.Context, action *Action) error {
+	name, args := a.shellCommand(action.Command, action.Dir)
+	return a.runProcess(ctx, action, name, args...)
+}
+
+// SetCommandWrapper sets how shell commands are run, for running them
+// somewhere other than the host, such as in a container. The wrapper is
+// given the command's working directory on the host, empty for the current
+// one, to run it in the matching directory. A nil wrapper runs commands
+// with sh -c.
+func (a *Agent) SetCommandWrapper(wrap func(command, dir string) (name string, args []string)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.wrapCommand = wrap
+}
+
+// shellCommand returns the program and arguments running a shell command
+// in dir.
+func (a *Agent) shellCommand(command, dir string) (string, []string) {
+	a.mu.Lock()
+	wrap := a.wrapCommand
+	a.mu.Unlock()
+	if wrap != nil {
+		return wrap(command, dir)
+	}
+	return "sh", []string{"-c", command}
+}
+
+// runProcess runs name with args for a command action: in action.Dir, within
+// action.Timeout, with the output captured into and streamed from action.
+func (a *Agent) runProcess(ctx context.Context, action *Action, name string, args ...string) error {
+	timeout := action.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	cmd.Env = os.Environ()
+	cmd.Dir = action.Dir
+	cmd.WaitDelay = commandWaitDelay
+
+	a.mu.Lock()
+	notify := a.onOutput
+	a.mu.Unlock()
+	if notify != nil {
+		notify(*action, OutputLine{})
+	}
+
+	out := &commandOutput{notify: notify, action: *action}
+	cmd.Stdout = out.stream(StreamStdout)
+	cmd.Stderr = out.stream(StreamStderr)
+
+	start := time.Now()
+	err := cmd.Run()
+	action.Duration = time.Since(start)
+	out.flush()
+	action.Stdout = out.stdout.String()
+	action.Stderr = out.stderr.String()
+	action.Output = out.combined.String()
+
+	if err == nil {
+		action.ExitCode = 0
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		action.ExitCode = exitErr.ExitCode()
+	} else {
+		action.ExitCode = -1
+	}
+	if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		action.Metadata["timed_out"] = true
+		return fmt.Errorf("command timed out after %s: %w", timeout, context.DeadlineExceeded)
+	}
+	return fmt.Errorf("command failed with exit code %d: %w", action.ExitCode, err)
+}
+
+// commandOutput collects a command's output and splits each stream into
+// lines for the output callback.
+type commandOutput struct {
+	mu       sync.Mutex
+	notify   func(Action, OutputLine)
+	action   Action
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	combined bytes.Buffer
+	partial  map[string]string // unterminated last line per stream
+}
+
+// stream returns the writer for one of the command's streams.
+func (o *commandOutput) stream(name string) *streamWriter {
+	return &streamWriter{out: o, name: name}
+}
+
+// flush reports unterminated last lines.
+func (o *commandOutput) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, name := range []string{StreamStdout, StreamStderr} {
+		if text := o.partial[name]; text != "" && o.notify != nil {
+			o.notify(o.action, OutputLine{Stream: name, Text: text})
+		}
+	}
+	o.partial = nil
+}
+
+// streamWriter writes one stream of a command's output.
+type streamWriter struct {
+	out  *commandOutput
+	name string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	o := w.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if w.name == StreamStdout {
+		o.stdout.Write(p)
+	} else {
+		o.stderr.Write(p)
+	}
+	o.combined.Write(p)
+
+	if o.notify == nil {
+		return len(p), nil
+	}
+	if o.partial == nil {
+		o.partial = make(map[string]string)
+	}
+	lines := strings.Split(o.partial[w.name]+string(p), "\n")
+	o.partial[w.name] = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		o.notify(o.action, OutputLine{Stream: w.name, Text: strings.TrimRight(line, "\r")})
+	}
+	return len(p), nil
+}
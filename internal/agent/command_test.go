@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/model"
+)
+
+func newCommandAgent() *Agent {
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	return a
+}
+
+func TestRunCommandWith_Streams(t *testing.T) {
+	a := newCommandAgent()
+	var mu sync.Mutex
+	var lines []OutputLine
+	a.SetOutputCallback(func(_ Action, line OutputLine) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, line)
+	})
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "marker"), nil, 0644)
+	result, err := a.RunCommandWith(context.Background(), "ls; echo oops >&2; printf tail; exit 3", CommandOptions{Dir: dir})
+	if err == nil {
+		t.Fatal("expected error for non-zero exit")
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+	if result.Stdout != "marker\ntail" || result.Stderr != "oops\n" {
+		t.Errorf("Stdout = %q, Stderr = %q", result.Stdout, result.Stderr)
+	}
+	if len(result.Output) != len(result.Stdout)+len(result.Stderr) {
+		t.Errorf("Output = %q should combine both streams", result.Output)
+	}
+	if result.Duration <= 0 || result.TimedOut {
+		t.Errorf("Duration = %v, TimedOut = %v", result.Duration, result.TimedOut)
+	}
+
+	want := map[OutputLine]bool{
+		{}:                                     true,
+		{Stream: StreamStdout, Text: "marker"}: true,
+		{Stream: StreamStderr, Text: "oops"}:   true,
+		{Stream: StreamStdout, Text: "tail"}:   true,
+	}
+	if len(lines) != len(want) || lines[0] != (OutputLine{}) {
+		t.Fatalf("streamed lines = %+v", lines)
+	}
+	for _, l := range lines {
+		if !want[l] {
+			t.Errorf("unexpected streamed line %+v", l)
+		}
+	}
+}
+
+func TestRunCommandWith_Timeout(t *testing.T) {
+	a := newCommandAgent()
+	start := time.Now()
+	result, err := a.RunCommandWith(context.Background(), "sleep 5", CommandOptions{Timeout: 100 * time.Millisecond})
+	if err == nil || !result.TimedOut {
+		t.Fatalf("RunCommandWith() = %+v, %v; want a timeout", result, err)
+	}
+	if result.ExitCode != -1 {
+		t.Errorf("ExitCode = %d, want -1", result.ExitCode)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("timed out command ran for %v", elapsed)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	code, output, err := newCommandAgent().RunCommand(context.Background(), "echo hi")
+	if err != nil || code != 0 || output != "hi\n" {
+		t.Errorf("RunCommand() = %d, %q, %v", code, output, err)
+	}
+}
+
+func TestSetCommandWrapper(t *testing.T) {
+	a := newCommandAgent()
+	a.SetCommandWrapper(func(command, dir string) (string, []string) {
+		return "sh", []string{"-c", "echo wrapped: " + command + " in " + filepath.Base(dir)}
+	})
+	dir := filepath.Join(t.TempDir(), "sub")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	result, err := a.RunCommandWith(context.Background(), "go test", CommandOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("RunCommandWith() error = %v", err)
+	}
+	if result.Stdout != "wrapped: go test in sub\n" {
+		t.Errorf("Stdout = %q", result.Stdout)
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	a := NewAgent(model.NewCoordinator(nil))
+	a.SetCommandWrapper(func(command, dir string) (string, []string) {
+		return "sh", []string{"-c", "echo wrapped: " + command}
+	})
+	result, err := a.RunCheck(context.Background(), "go test", CommandOptions{})
+	if err != nil || result.Stdout != "wrapped: go test\n" {
+		t.Fatalf("RunCheck() = %+v, %v; want the wrapped command", result, err)
+	}
+	if actions := a.GetActions(); len(actions) != 1 || actions[0].Type != ActionRunCommand {
+		t.Errorf("actions = %+v, want the check recorded", actions)
+	}
+
+	a.executing = true
+	if _, err := a.RunCheck(context.Background(), "true", CommandOptions{}); err == nil {
+		t.Error("RunCheck() ran while a process was executing")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "REPORT.md")
+	a := NewAgent(model.NewCoordinator(nil))
+	if err := a.WriteFile(context.Background(), path, "first\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.WriteFile(context.Background(), path, "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "second\n" {
+		t.Errorf("file = %q, %v; want the second write", data, err)
+	}
+	actions := a.GetActions()
+	if len(actions) != 2 || actions[0].Type != ActionCreateFile || actions[1].Type != ActionEditFile {
+		t.Errorf("actions = %+v, want a create and an edit recorded", actions)
+	}
+}
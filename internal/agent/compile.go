@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/analyzer"
+	"github.com/croberts/obot/internal/fixer"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/tokens"
+)
+
+// Compile check limits
+const (
+	// DefaultCompileRepairs is how many times the model may repair a file
+	// that fails its compile check.
+	DefaultCompileRepairs = 2
+
+	compileCheckTimeout = 2 * time.Minute
+	maxCompileErrors    = 4000 // bytes of check output given to the model
+)
+
+// Compile check outcomes, recorded in the action's "compile_check" metadata
+const (
+	CompilePassed   = "passed"
+	CompileRepaired = "repaired"
+	CompileFailed   = "failed"
+	CompileSkipped  = "skipped" // the check's tool is not installed
+)
+
+// SetCompileCheck enables a compile check of each source file the agent
+// creates or edits, using its toolchain's Check command. When the check
+// fails the errors are fed back to the model for a corrected file, up to
+// repairs times (0 uses DefaultCompileRepairs), before the process
+// continues.
+func (a *Agent) SetCompileCheck(enabled bool, repairs int) {
+	if repairs <= 0 {
+		repairs = DefaultCompileRepairs
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.compileCheck = enabled
+	a.compileRepairs = repairs
+}
+
+// checkCompile runs the compile check for a written file and asks the model
+// to repair it while it fails. Each repair is an edit action of its own, so
+// that it passes the policy and plugins and is recorded like any edit. A
+// file that still fails is left in place with the errors in the action's
+// output and diagnostics.
+func (a *Agent) checkCompile(ctx context.Context, action *Action) {
+	a.mu.Lock()
+	enabled, repairs := a.compileCheck, a.compileRepairs
+	a.mu.Unlock()
+	if !enabled {
+		return
+	}
+	tc, ok := a.fileToolchain(action.Path)
+	if !ok || tc.Check == "" {
+		return
+	}
+	command := expandToolchainCommand(tc.Check, action.Path)
+	dir := toolchainDir(tc, tc.Check, action.Path)
+
+	status := CompilePassed
+	output, exitCode := a.runCompileCheck(ctx, command, dir)
+	for attempt := 1; exitCode != 0; attempt++ {
+		if exitCode == 127 {
+			status = CompileSkipped
+			break
+		}
+		status = CompileFailed
+		if attempt > repairs || ctx.Err() != nil {
+			break
+		}
+		content, err := a.repairFile(ctx, action.Path, output)
+		if err != nil {
+			action.Metadata["compile_repair_error"] = err.Error()
+			break
+		}
+		repair := &Action{
+			ID:       fmt.Sprintf("%s.%d", action.ID, attempt),
+			Type:     ActionEditFile,
+			Path:     action.Path,
+			Content:  content,
+			Metadata: map[string]any{"compile_repair": action.ID},
+		}
+		if err := a.executeAction(ctx, repair); err != nil {
+			action.Metadata["compile_repair_error"] = err.Error()
+			break
+		}
+		if action.Type == ActionCreateFile {
+			action.Content = content
+		}
+		action.Metadata["compile_repairs"] = attempt
+		output, exitCode = a.runCompileCheck(ctx, command, dir)
+		if exitCode == 0 {
+			status = CompileRepaired
+		}
+	}
+
+	action.Metadata["compile_check"] = status
+	if status == CompileFailed {
+		action.Output = output
+		action.ToolResult = &ToolchainResult{Toolchain: tc.Name, Diagnostics: parseDiagnostics(output)}
+	}
+}
+
+// runCompileCheck runs a compile check command in dir and returns its output
+// and exit code.
+func (a *Agent) runCompileCheck(ctx context.Context, command, dir string) (string, int) {
+	check := Action{
+		Type:     ActionRunCommand,
+		Command:  command,
+		Dir:      dir,
+		Timeout:  compileCheckTimeout,
+		Metadata: make(map[string]any),
+	}
+	_ = a.handleRunCommand(ctx, &check)
+	return check.Output, check.ExitCode
+}
+
+// repairFile gives the model a file and its compile errors and returns the
+// corrected file.
+func (a *Agent) repairFile(ctx context.Context, path, checkOutput string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	role := a.currentModel
+	a.mu.Unlock()
+	if role == "" {
+		role = orchestrate.ModelCoder
+	}
+	client := a.models.Get(role)
+	if client == nil {
+		return "", fmt.Errorf("no client found for model type %v", role)
+	}
+
+	if len(checkOutput) > maxCompileErrors {
+		checkOutput = checkOutput[:maxCompileErrors] + "\n... (truncated)"
+	}
+	lang := analyzer.DetectLanguage(path)
+	prompt := fmt.Sprintf("The file %s you just wrote fails to compile:\n\n```\n%s\n```\n\n"+
+		"Current content:\n\n```%s\n%s\n```\n\n"+
+		"Fix the errors without changing anything else. Reply with the complete corrected file in a single code block.",
+		path, strings.TrimSpace(checkOutput), lang, string(data))
+
+	resp, stats, err := client.Generate(ctx, prompt)
+	a.addExchange(role, prompt, resp, err)
+	if err != nil {
+		return "", err
+	}
+	used := 0
+	if stats != nil {
+		used = stats.TotalTokens
+	}
+	if used == 0 {
+		used = tokens.Count(prompt) + tokens.Count(resp)
+	}
+	a.recordTokens(role, int64(used))
+
+	code := fixer.ExtractCode(resp, lang)
+	if strings.TrimSpace(code) == "" {
+		return "", fmt.Errorf("model returned no code for %s", path)
+	}
+	return code + "\n", nil
+}
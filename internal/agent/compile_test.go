@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// newRepairAgent returns an executing agent whose models answer every
+// generate request with resp, checking ".chk" files with check.
+func newRepairAgent(t *testing.T, resp, check string) *Agent {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: resp, Done: true})
+	}))
+	t.Cleanup(srv.Close)
+	a := NewAgent(model.NewCoordinator(ollama.NewClient(ollama.WithBaseURL(srv.URL))))
+	a.executing = true
+	a.SetToolchains([]Toolchain{{Name: "chk", Extensions: []string{".chk"}, Check: check}})
+	a.SetCompileCheck(true, 2)
+	return a
+}
+
+func TestCompileCheck_Repairs(t *testing.T) {
+	a := newRepairAgent(t, "Here you go:\n```\nok: fixed\n```", "grep -q fixed {path} || { echo {path}:1: syntax error; exit 1; }")
+	path := filepath.Join(t.TempDir(), "main.chk")
+
+	if err := a.CreateFile(context.Background(), path, "broken\n"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ok: fixed\n" {
+		t.Errorf("file = %q, want repaired content", data)
+	}
+	// The repair, an edit action of its own, is recorded before the create
+	actions := a.GetActions()
+	action := actions[len(actions)-1]
+	if action.Metadata["compile_check"] != CompileRepaired || action.Metadata["compile_repairs"] != 1 {
+		t.Errorf("metadata = %v", action.Metadata)
+	}
+	if action.Content != "ok: fixed\n" {
+		t.Errorf("Content = %q", action.Content)
+	}
+	if len(actions) != 2 || actions[0].Type != ActionEditFile || actions[0].Metadata["compile_repair"] != action.ID {
+		t.Errorf("actions = %+v, want the create and its repair edit", actions)
+	}
+}
+
+// denyEdits is a plugin refusing every edit.
+type denyEdits struct{ BasePlugin }
+
+func (*denyEdits) Name() string { return "deny-edits" }
+
+func (*denyEdits) OnBeforeAction(ctx context.Context, action *Action) error {
+	if action.Type == ActionEditFile {
+		return errors.New("edits are not allowed")
+	}
+	return nil
+}
+
+func TestCompileCheck_RepairDenied(t *testing.T) {
+	a := newRepairAgent(t, "```\nok: fixed\n```", "grep -q fixed {path} || { echo {path}:1: syntax error; exit 1; }")
+	dir := t.TempDir()
+	a.RegisterPlugin(&denyEdits{})
+
+	if err := a.CreateFile(context.Background(), filepath.Join(dir, "other.chk"), "broken\n"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "other.chk")); string(data) != "broken\n" {
+		t.Errorf("file = %q, want the refused repair not written", data)
+	}
+	if action := a.GetActions()[len(a.GetActions())-1]; action.Metadata["compile_check"] != CompileFailed || action.Metadata["compile_repair_error"] == nil {
+		t.Errorf("metadata = %v, want the refused repair recorded", action.Metadata)
+	}
+}
+
+func TestCompileCheck_Failed(t *testing.T) {
+	a := newRepairAgent(t, "```\nstill broken\n```", "grep -q fixed {path} || { echo {path}:1: syntax error; exit 1; }")
+	path := filepath.Join(t.TempDir(), "main.chk")
+
+	if err := a.CreateFile(context.Background(), path, "broken\n"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	actions := a.GetActions()
+	action := actions[len(actions)-1]
+	if action.Metadata["compile_check"] != CompileFailed || action.Metadata["compile_repairs"] != 2 {
+		t.Errorf("metadata = %v", action.Metadata)
+	}
+	if action.ToolResult == nil || len(action.ToolResult.Diagnostics) != 1 || action.ToolResult.Diagnostics[0].Message != "syntax error" {
+		t.Errorf("ToolResult = %+v", action.ToolResult)
+	}
+}
+
+func TestCompileCheck_ReportsTokens(t *testing.T) {
+	a := newRepairAgent(t, "```\nok: fixed\n```", "grep -q fixed {path} || exit 1")
+	a.SetContext(orchestrate.ScheduleImplement, orchestrate.Process2)
+	var usage []TokenUsage
+	a.SetTokenCallback(func(u TokenUsage) { usage = append(usage, u) })
+
+	if err := a.CreateFile(context.Background(), filepath.Join(t.TempDir(), "main.chk"), "broken\n"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if len(usage) != 1 || usage[0].Schedule != orchestrate.ScheduleImplement || usage[0].Process != orchestrate.Process2 || usage[0].Tokens <= 0 {
+		t.Fatalf("usage = %+v, want one Implement P2 request", usage)
+	}
+	if got := a.models.GetTokenCounts()[usage[0].Model]; got != usage[0].Tokens {
+		t.Errorf("coordinator counted %d tokens for %s, want %d", got, usage[0].Model, usage[0].Tokens)
+	}
+}
+
+func TestCompileCheck_SkipsMissingTool(t *testing.T) {
+	a := newRepairAgent(t, "", "obot-no-such-checker {path}")
+	path := filepath.Join(t.TempDir(), "main.chk")
+
+	if err := a.CreateFile(context.Background(), path, "x\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.GetActions()[0].Metadata["compile_check"]; got != CompileSkipped {
+		t.Errorf("compile_check = %v, want %s", got, CompileSkipped)
+	}
+
+	a.SetCompileCheck(false, 0)
+	if err := a.CreateFile(context.Background(), path, "x\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := a.GetActions()[1].Metadata["compile_check"]; ok {
+		t.Errorf("compile_check = %v with the check disabled", got)
+	}
+}
+
+func TestCompileCheck_Conversation(t *testing.T) {
+	a := newRepairAgent(t, "```\nok: fixed\n```", "grep -q fixed {path} || { echo {path}:1: syntax error; exit 1; }")
+
+	if err := a.CreateFile(context.Background(), filepath.Join(t.TempDir(), "main.chk"), "broken\n"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	turns := a.Conversation()
+	if len(turns) != 4 {
+		t.Fatalf("conversation = %+v, want a prompt, a response, the repair, and the action", turns)
+	}
+	if turns[0].Kind != TurnPrompt || !strings.Contains(turns[0].Content, "syntax error") {
+		t.Errorf("turn 0 = %+v, want the repair prompt", turns[0])
+	}
+	if turns[1].Kind != TurnResponse || turns[2].Kind != TurnAction || turns[3].Kind != TurnAction {
+		t.Errorf("turns = %+v", turns)
+	}
+}
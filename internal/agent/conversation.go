@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// TurnKind is what a conversation turn is.
+type TurnKind string
+
+const (
+	TurnPrompt   TurnKind = "prompt"   // sent to a model
+	TurnResponse TurnKind = "response" // a model's reply
+	TurnAction   TurnKind = "action"   // an action the agent took
+	TurnError    TurnKind = "error"    // a failed model request
+)
+
+// Turn is one turn of the agent's conversation in a process.
+type Turn struct {
+	Kind    TurnKind
+	Model   orchestrate.ModelType // for prompts, responses, and errors
+	Content string
+	Time    time.Time
+}
+
+// Conversation returns the agent's conversation since its last Execute
+// began: the prompts it sent its models, their replies, and its actions.
+func (a *Agent) Conversation() []Turn {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	turns := make([]Turn, len(a.conversation))
+	copy(turns, a.conversation)
+	return turns
+}
+
+// addTurnLocked appends a turn to the conversation. a.mu must be held.
+func (a *Agent) addTurnLocked(kind TurnKind, model orchestrate.ModelType, content string) {
+	a.conversation = append(a.conversation, Turn{Kind: kind, Model: model, Content: content, Time: time.Now()})
+}
+
+// addExchange appends a request to model and its outcome to the
+// conversation.
+func (a *Agent) addExchange(model orchestrate.ModelType, prompt, response string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.addTurnLocked(TurnPrompt, model, prompt)
+	if err != nil {
+		a.addTurnLocked(TurnError, model, err.Error())
+		return
+	}
+	a.addTurnLocked(TurnResponse, model, response)
+}
+
+// turnOutputLimit is how much of a command's output an action turn keeps,
+// from its end, where errors usually are.
+const turnOutputLimit = 4000
+
+// actionTurn describes an action for the conversation, with the output of
+// a command it ran.
+func actionTurn(action *Action) string {
+	text := strings.TrimPrefix(action.ActionOutput(), "Agent • ")
+	output := strings.TrimSpace(action.Output)
+	if output == "" {
+		return text
+	}
+	if len(output) > turnOutputLimit {
+		output = "..." + output[len(output)-turnOutputLimit:]
+	}
+	return text + "\n" + output
+}
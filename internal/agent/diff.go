@@ -1,10 +1,13 @@
 package agent
 
 import (
+	"os"
 	"sort"
 	"strings"
 
 	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 // Edit represents a file edit operation
@@ -17,8 +20,8 @@ type Edit struct {
 
 // computeDiff uses go-difflib for unified diff and converts to obot style.
 func computeDiff(oldContent, newContent string) *DiffSummary {
-	oldLines := difflib.SplitLines(oldContent)
-	newLines := difflib.SplitLines(newContent)
+	oldLines := splitLines(oldContent)
+	newLines := splitLines(newContent)
 
 	summary := &DiffSummary{
 		Additions:   make([]DiffLine, 0),
@@ -63,51 +66,25 @@ func computeDiff(oldContent, newContent string) *DiffSummary {
 				summary.TotalAdded++
 			}
 		case 'r': // replace
-			// For small replacements (single line), perform character-level diffing
-			if op.I2-op.I1 == 1 && op.J2-op.J1 == 1 {
-				oldLine := oldLines[op.I1]
-				newLine := newLines[op.J1]
-
-				diffedOld, diffedNew := computeCharDiff(oldLine, newLine)
-
-				dlDel := DiffLine{
-					LineNumber: op.I1 + 1,
-					Content:    diffedOld,
+			for i := op.I1; i < op.I2; i++ {
+				dl := DiffLine{
+					LineNumber: i + 1,
+					Content:    oldLines[i],
 					Type:       DiffLineDelete,
 				}
-				summary.Deletions = append(summary.Deletions, dlDel)
-				summary.Interleaved = append(summary.Interleaved, dlDel)
+				summary.Deletions = append(summary.Deletions, dl)
+				summary.Interleaved = append(summary.Interleaved, dl)
 				summary.TotalRemoved++
-
-				dlAdd := DiffLine{
-					LineNumber: op.J1 + 1,
-					Content:    diffedNew,
+			}
+			for i := op.J1; i < op.J2; i++ {
+				dl := DiffLine{
+					LineNumber: i + 1,
+					Content:    newLines[i],
 					Type:       DiffLineAdd,
 				}
-				summary.Additions = append(summary.Additions, dlAdd)
-				summary.Interleaved = append(summary.Interleaved, dlAdd)
+				summary.Additions = append(summary.Additions, dl)
+				summary.Interleaved = append(summary.Interleaved, dl)
 				summary.TotalAdded++
-			} else {
-				for i := op.I1; i < op.I2; i++ {
-					dl := DiffLine{
-						LineNumber: i + 1,
-						Content:    oldLines[i],
-						Type:       DiffLineDelete,
-					}
-					summary.Deletions = append(summary.Deletions, dl)
-					summary.Interleaved = append(summary.Interleaved, dl)
-					summary.TotalRemoved++
-				}
-				for i := op.J1; i < op.J2; i++ {
-					dl := DiffLine{
-						LineNumber: i + 1,
-						Content:    newLines[i],
-						Type:       DiffLineAdd,
-					}
-					summary.Additions = append(summary.Additions, dl)
-					summary.Interleaved = append(summary.Interleaved, dl)
-					summary.TotalAdded++
-				}
 			}
 		}
 	}
@@ -115,8 +92,19 @@ func computeDiff(oldContent, newContent string) *DiffSummary {
 	return summary
 }
 
+// fileDiff diffs the file at path, empty if it does not exist, against
+// newContent. The unchanged lines are dropped so that an action does not
+// carry a copy of the whole file.
+func fileDiff(path, newContent string) *DiffSummary {
+	old, _ := os.ReadFile(path)
+	diff := computeDiff(string(old), newContent)
+	diff.Context, diff.Interleaved = nil, nil
+	return diff
+}
+
 // computeCharDiff performs character-level diffing between two strings.
-// It returns the strings with ANSI escape codes for highlighting differences.
+// It returns the strings with ANSI escape codes for highlighting differences,
+// for rendering only; stored diffs hold the plain lines.
 func computeCharDiff(oldLine, newLine string) (string, string) {
 	oldChars := strings.Split(oldLine, "")
 	newChars := strings.Split(newLine, "")
@@ -124,14 +112,12 @@ func computeCharDiff(oldLine, newLine string) (string, string) {
 	m := difflib.NewMatcher(oldChars, newChars)
 	var oldRes, newRes strings.Builder
 
-	// ANSI constants (local to avoid dependency on ui package)
-	const (
-		reset     = "\033[0m"
-		redBg     = "\033[41;37m" // Red background, white text
-		greenBg   = "\033[42;30m" // Green background, black text
-		redUnder  = "\033[4;31m"  // Red underline
-		greenUnder = "\033[4;32m" // Green underline
-	)
+	// ANSI styles (local to avoid dependency on ui package), empty when
+	// colors are off
+	reset, redUnder, greenUnder := "\033[0m", "\033[4;31m", "\033[4;32m"
+	if !term.Colors() {
+		reset, redUnder, greenUnder = "", "", ""
+	}
 
 	for _, op := range m.GetOpCodes() {
 		switch op.Tag {
@@ -244,10 +230,21 @@ func ComputeDiffFromEdits(edits []Edit) *DiffSummary {
 	return summary
 }
 
-// RenderInterleaved returns the interleaved diff as a formatted string.
+// RenderInterleaved returns the interleaved diff as a formatted string. A
+// line replaced by a single line has its changed characters highlighted
+// when colors are on.
 func (ds *DiffSummary) RenderInterleaved() string {
 	var result strings.Builder
-	for _, line := range ds.Interleaved {
+	lines := ds.Interleaved
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if replacedLine(lines, i) {
+			oldLine, newLine := computeCharDiff(line.Content, lines[i+1].Content)
+			result.WriteString("- " + oldLine + "\n")
+			result.WriteString("+ " + newLine + "\n")
+			i++
+			continue
+		}
 		switch line.Type {
 		case DiffLineAdd:
 			result.WriteString("+ " + line.Content + "\n")
@@ -260,6 +257,15 @@ func (ds *DiffSummary) RenderInterleaved() string {
 	return result.String()
 }
 
+// replacedLine reports whether lines[i] is a single deleted line followed by
+// the single line that replaced it.
+func replacedLine(lines []DiffLine, i int) bool {
+	return lines[i].Type == DiffLineDelete &&
+		i+1 < len(lines) && lines[i+1].Type == DiffLineAdd &&
+		(i == 0 || lines[i-1].Type != DiffLineDelete) &&
+		(i+2 == len(lines) || lines[i+2].Type != DiffLineAdd)
+}
+
 // splitLines splits a string into lines
 func splitLines(s string) []string {
 	if s == "" {
@@ -3,9 +3,14 @@ package agent
 import (
 	"strings"
 	"testing"
+
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 func TestRenderInterleaved(t *testing.T) {
+	defer term.SetColors(term.Colors())
+	term.SetColors(false)
+
 	ds := &DiffSummary{
 		Interleaved: []DiffLine{
 			{LineNumber: 1, Content: "line 1", Type: DiffLineContext},
@@ -40,3 +45,18 @@ func TestComputeDiffInterleaved(t *testing.T) {
 		t.Errorf("Interleaved diff missing modified line")
 	}
 }
+
+func TestComputeDiff_StoresPlainText(t *testing.T) {
+	defer term.SetColors(term.Colors())
+	term.SetColors(true)
+
+	summary := computeDiff("a\nold line\n", "a\nnew line\n")
+	for _, line := range summary.Interleaved {
+		if strings.Contains(line.Content, "\x1b") {
+			t.Errorf("stored diff line has escapes: %q", line.Content)
+		}
+	}
+	if got := summary.RenderInterleaved(); !strings.Contains(got, "\x1b[4;32m") {
+		t.Errorf("RenderInterleaved() = %q, want the changed characters highlighted", got)
+	}
+}
@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Default container resource limits
+const (
+	DefaultDockerCPUs   = 2.0
+	DefaultDockerMemory = "2g"
+	dockerPidsLimit     = 512
+	dockerWorkdir       = "/workspace"
+)
+
+// dockerSeq numbers the containers started by this process.
+var dockerSeq atomic.Int64
+
+// containerNameRe matches the name flag of a docker_run command, which
+// differs between runs of the same command.
+var containerNameRe = regexp.MustCompile(` --name obot-\d+-\d+\b`)
+
+// DockerSpec describes a container build or run. Containers have no
+// network access unless Network is set, and run with CPU, memory, and
+// process limits.
+type DockerSpec struct {
+	Image string // tag to build, or image to run
+
+	// Build
+	Context    string // build context directory; "." when empty
+	Dockerfile string // path to the Dockerfile; Context/Dockerfile when empty
+
+	// Run
+	Command string // shell command run in the container; the image default when empty
+	Mount   string // directory mounted at /workspace; none when empty
+
+	CPUs    float64 // 0 uses DefaultDockerCPUs
+	Memory  string  // e.g. "512m"; empty uses DefaultDockerMemory
+	Network bool    // allow network access
+}
+
+// DockerBuild builds an image from spec.Context tagged spec.Image.
+func (a *Agent) DockerBuild(ctx context.Context, spec DockerSpec, timeout time.Duration) (CommandResult, error) {
+	action := Action{Type: ActionDockerBuild, Docker: &spec, Timeout: timeout}
+	err := a.executeAction(ctx, &action)
+	return commandResult(action), err
+}
+
+// DockerRun runs spec.Command in a new container of spec.Image, removed
+// when it exits.
+func (a *Agent) DockerRun(ctx context.Context, spec DockerSpec, timeout time.Duration) (CommandResult, error) {
+	action := Action{Type: ActionDockerRun, Docker: &spec, Timeout: timeout}
+	err := a.executeAction(ctx, &action)
+	return commandResult(action), err
+}
+
+// handleDockerBuild builds an image with the action's spec.
+func (a *Agent) handleDockerBuild(ctx context.Context, action *Action) error {
+	if action.Docker == nil || action.Docker.Image == "" {
+		return errors.New("docker image tag is required")
+	}
+	if err := checkDockerArgs(*action.Docker); err != nil {
+		return err
+	}
+	args := dockerBuildArgs(*action.Docker)
+	action.Command = "docker " + strings.Join(args, " ")
+	return a.runProcess(ctx, action, "docker", args...)
+}
+
+// handleDockerRun runs a container with the action's spec. A container
+// outliving its timeout or cancellation is removed.
+func (a *Agent) handleDockerRun(ctx context.Context, action *Action) error {
+	if action.Docker == nil || action.Docker.Image == "" {
+		return errors.New("docker image is required")
+	}
+	if err := checkDockerArgs(*action.Docker); err != nil {
+		return err
+	}
+	spec := *action.Docker
+	if spec.Mount != "" {
+		abs, err := filepath.Abs(spec.Mount)
+		if err != nil {
+			return err
+		}
+		spec.Mount = abs
+	}
+	name := fmt.Sprintf("obot-%d-%d", os.Getpid(), dockerSeq.Add(1))
+	args := dockerRunArgs(spec, name)
+	action.Command = "docker " + strings.Join(args, " ")
+	action.Metadata["container"] = name
+
+	err := a.runProcess(ctx, action, "docker", args...)
+	if err != nil && (errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil) {
+		_ = exec.Command("docker", "rm", "-f", name).Run()
+	}
+	return err
+}
+
+// checkDockerArgs rejects spec values docker would read as flags, such as an
+// image of --privileged.
+func checkDockerArgs(spec DockerSpec) error {
+	for _, f := range []struct{ name, value string }{
+		{"image", spec.Image}, {"build context", spec.Context}, {"Dockerfile", spec.Dockerfile},
+		{"memory limit", spec.Memory}, {"mount", spec.Mount},
+	} {
+		if strings.HasPrefix(f.value, "-") {
+			return fmt.Errorf("docker %s must not start with -: %q", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// dockerBuildArgs returns the docker arguments building spec.
+func dockerBuildArgs(spec DockerSpec) []string {
+	buildCtx := spec.Context
+	if buildCtx == "" {
+		buildCtx = "."
+	}
+	args := []string{"build", "--tag", spec.Image}
+	if spec.Dockerfile != "" {
+		args = append(args, "--file", spec.Dockerfile)
+	}
+	if !spec.Network {
+		args = append(args, "--network", "none")
+	}
+	return append(args, buildCtx)
+}
+
+// dockerRunArgs returns the docker arguments running spec in the container name.
+func dockerRunArgs(spec DockerSpec, name string) []string {
+	cpus := spec.CPUs
+	if cpus <= 0 {
+		cpus = DefaultDockerCPUs
+	}
+	memory := spec.Memory
+	if memory == "" {
+		memory = DefaultDockerMemory
+	}
+	args := []string{"run", "--rm", "--name", name,
+		"--cpus", strconv.FormatFloat(cpus, 'f', -1, 64),
+		"--memory", memory,
+		"--pids-limit", strconv.Itoa(dockerPidsLimit),
+		"--security-opt", "no-new-privileges",
+	}
+	if !spec.Network {
+		args = append(args, "--network", "none")
+	}
+	if spec.Mount != "" {
+		args = append(args, "--volume", spec.Mount+":"+dockerWorkdir, "--workdir", dockerWorkdir)
+	}
+	args = append(args, spec.Image)
+	if spec.Command != "" {
+		args = append(args, "sh", "-c", spec.Command)
+	}
+	return args
+}
+
+// commandResult returns the result of a command action.
+func commandResult(action Action) CommandResult {
+	return CommandResult{
+		Command:  action.Command,
+		Dir:      action.Dir,
+		ExitCode: action.ExitCode,
+		Stdout:   action.Stdout,
+		Stderr:   action.Stderr,
+		Output:   action.Output,
+		Duration: action.Duration,
+		TimedOut: action.Metadata["timed_out"] == true,
+	}
+}
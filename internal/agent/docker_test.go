@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDockerBuildArgs(t *testing.T) {
+	got := dockerBuildArgs(DockerSpec{Image: "app:test"})
+	want := []string{"build", "--tag", "app:test", "--network", "none", "."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerBuildArgs() = %v, want %v", got, want)
+	}
+
+	got = dockerBuildArgs(DockerSpec{Image: "app:test", Context: "svc", Dockerfile: "svc/Dockerfile.dev", Network: true})
+	want = []string{"build", "--tag", "app:test", "--file", "svc/Dockerfile.dev", "svc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerBuildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	got := dockerRunArgs(DockerSpec{Image: "golang:1.24", Command: "go test ./...", Mount: "/src"}, "obot-1-1")
+	want := []string{"run", "--rm", "--name", "obot-1-1", "--cpus", "2", "--memory", "2g",
+		"--pids-limit", "512", "--security-opt", "no-new-privileges", "--network", "none",
+		"--volume", "/src:/workspace", "--workdir", "/workspace", "golang:1.24", "sh", "-c", "go test ./..."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerRunArgs() =\n%v\nwant\n%v", got, want)
+	}
+
+	got = dockerRunArgs(DockerSpec{Image: "app", CPUs: 0.5, Memory: "256m", Network: true}, "c")
+	want = []string{"run", "--rm", "--name", "c", "--cpus", "0.5", "--memory", "256m",
+		"--pids-limit", "512", "--security-opt", "no-new-privileges", "app"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerRunArgs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestDockerActions_Validation(t *testing.T) {
+	a := newCommandAgent()
+	if _, err := a.DockerRun(context.Background(), DockerSpec{}, 0); err == nil {
+		t.Error("DockerRun() without an image should fail")
+	}
+	if _, err := a.DockerBuild(context.Background(), DockerSpec{}, 0); err == nil {
+		t.Error("DockerBuild() without a tag should fail")
+	}
+	for _, spec := range []DockerSpec{
+		{Image: "--privileged"},
+		{Image: "app", Context: "--volume=/:/host"},
+		{Image: "app", Dockerfile: "-"},
+		{Image: "app", Memory: "--privileged"},
+	} {
+		if _, err := a.DockerRun(context.Background(), spec, 0); err == nil || !strings.Contains(err.Error(), "must not start with -") {
+			t.Errorf("DockerRun(%+v) error = %v, want a rejected flag", spec, err)
+		}
+		if _, err := a.DockerBuild(context.Background(), spec, 0); err == nil || !strings.Contains(err.Error(), "must not start with -") {
+			t.Errorf("DockerBuild(%+v) error = %v, want a rejected flag", spec, err)
+		}
+	}
+
+	networked := &Action{Type: ActionDockerRun, Docker: &DockerSpec{Image: "app", Network: true}}
+	if classes := classifyAction(networked); len(classes) != 1 || classes[0] != ClassNetworkCommand {
+		t.Errorf("networked run classes = %v", classes)
+	}
+	if classes := classifyAction(&Action{Type: ActionDockerRun, Docker: &DockerSpec{Image: "app"}}); len(classes) != 0 {
+		t.Errorf("sandboxed run classes = %v", classes)
+	}
+}
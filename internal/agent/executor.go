@@ -7,8 +7,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/croberts/obot/internal/index"
 )
 
 // executeAction is the internal entry point for all agent actions.
@@ -48,6 +51,9 @@ func (a *Agent) executeAction(ctx context.Context, action *Action) error {
 	action.Metadata["process"] = a.currentProcess.String()
 	action.Metadata["model"] = string(a.currentModel)
 	plugins := a.plugins
+	if a.replaying {
+		plugins = replayPlugins(plugins)
+	}
 	a.mu.Unlock()
 
 	// 3. Call OnBeforeAction hooks
@@ -78,6 +84,14 @@ func (a *Agent) executeAction(ctx context.Context, action *Action) error {
 			err = a.handleMoveFile(ctx, action)
 		case ActionCopyFile:
 			err = a.handleCopyFile(ctx, action)
+		case ActionRenameSymbol:
+			err = a.handleRenameSymbol(ctx, action)
+		case ActionDiagnostics:
+			err = a.handleDiagnostics(ctx, action)
+		case ActionHover:
+			err = a.handleHover(ctx, action)
+		case ActionReferences:
+			err = a.handleReferences(ctx, action)
 		case ActionCreateDir:
 			err = a.handleCreateDir(ctx, action)
 		case ActionDeleteDir:
@@ -90,12 +104,26 @@ func (a *Agent) executeAction(ctx context.Context, action *Action) error {
 			err = a.handleCopyDir(ctx, action)
 		case ActionRunCommand:
 			err = a.handleRunCommand(ctx, action)
-		case ActionLint:
-			err = a.handleLint(ctx, action)
-		case ActionFormat:
-			err = a.handleFormat(ctx, action)
-		case ActionTest:
-			err = a.handleTest(ctx, action)
+		case ActionLint, ActionFormat, ActionTest:
+			err = a.runToolchain(ctx, action)
+		case ActionStartBackground:
+			err = a.handleStartBackground(ctx, action)
+		case ActionCheckBackground:
+			err = a.handleCheckBackground(ctx, action)
+		case ActionBackgroundLogs:
+			err = a.handleBackgroundLogs(ctx, action)
+		case ActionStopBackground:
+			err = a.handleStopBackground(ctx, action)
+		case ActionHTTPRequest:
+			err = a.handleHTTPRequest(ctx, action)
+		case ActionDBListTables:
+			err = a.handleDBListTables(ctx, action)
+		case ActionDBQuery:
+			err = a.handleDBQuery(ctx, action)
+		case ActionDockerBuild:
+			err = a.handleDockerBuild(ctx, action)
+		case ActionDockerRun:
+			err = a.handleDockerRun(ctx, action)
 		case ActionReadFile:
 			err = a.handleReadFile(ctx, action)
 		case ActionSearchFiles:
@@ -109,6 +137,14 @@ func (a *Agent) executeAction(ctx context.Context, action *Action) error {
 		default:
 			err = fmt.Errorf("unsupported action type: %s", action.Type)
 		}
+		// The edits of a rename are checked together, once all are made,
+		// and a repair is checked by the check that asked for it
+		_, inRename := action.Metadata["symbol_rename"]
+		_, inRepair := action.Metadata["compile_repair"]
+		if err == nil && (action.Type == ActionCreateFile || action.Type == ActionEditFile && !inRename && !inRepair ||
+			action.Type == ActionRenameSymbol) {
+			a.checkCompile(ctx, action)
+		}
 
 		err = a.finalizeAction(action, start, err)
 	}
@@ -121,11 +157,35 @@ func (a *Agent) executeAction(ctx context.Context, action *Action) error {
 	return err
 }
 
+// replayPlugins returns the plugins that see replayed actions.
+func replayPlugins(plugins []Plugin) []Plugin {
+	var kept []Plugin
+	for _, p := range plugins {
+		if rp, ok := p.(ReplayPlugin); ok && rp.OnReplay() {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
 // preExecuteValidation performs checks before an action is executed.
 func (a *Agent) preExecuteValidation(action *Action) error {
+	// Within a monorepo project, the scope says which paths may be read
+	// and written; reads may leave the project
+	a.mu.Lock()
+	scope := a.scope
+	a.mu.Unlock()
+	if scope != nil {
+		if err := scope.check(action); err != nil {
+			return err
+		}
+		return a.checkPolicy(action)
+	}
+
 	// Path validation for all file/dir operations
 	switch action.Type {
-	case ActionCreateFile, ActionDeleteFile, ActionEditFile, ActionReadFile, 
+	case ActionCreateFile, ActionDeleteFile, ActionEditFile, ActionReadFile, ActionRenameSymbol,
+	     ActionDiagnostics, ActionHover, ActionReferences,
 	     ActionCreateDir, ActionDeleteDir, ActionListDir, ActionLint, ActionFormat, ActionTest:
 		if err := validatePath(action.Path); err != nil {
 			return err
@@ -139,6 +199,33 @@ func (a *Agent) preExecuteValidation(action *Action) error {
 			return err
 		}
 	}
+	return a.checkPolicy(action)
+}
+
+// checkPolicy blocks the action or asks for confirmation as the policy requires.
+func (a *Agent) checkPolicy(action *Action) error {
+	a.mu.Lock()
+	policy, confirm := a.policy, a.confirmAction
+	a.mu.Unlock()
+	if policy == nil {
+		return nil
+	}
+
+	check := policy.Evaluate(action)
+	if _, inRename := action.Metadata["symbol_rename"]; inRename && check.Decision == PolicyConfirm && action.Metadata["policy"] == "confirmed" {
+		// The edits of a symbol rename are confirmed before any is applied
+		return nil
+	}
+	action.Metadata["policy"] = string(check.Decision)
+	switch check.Decision {
+	case PolicyBlock:
+		return &PolicyError{Check: check}
+	case PolicyConfirm:
+		if confirm == nil || !confirm(check) {
+			return &PolicyError{Check: check, Declined: confirm != nil}
+		}
+		action.Metadata["policy"] = "confirmed"
+	}
 	return nil
 }
 
@@ -152,6 +239,7 @@ func (a *Agent) finalizeAction(action *Action, start time.Time, err error) error
 		action.Metadata["status"] = "failed"
 	} else {
 		action.Metadata["status"] = "success"
+		a.notifyLanguageServers(action)
 	}
 
 	// Record the finished action
@@ -161,6 +249,10 @@ func (a *Agent) finalizeAction(action *Action, start time.Time, err error) error
 
 // handleCreateFile creates a new file with the specified content.
 func (a *Agent) handleCreateFile(ctx context.Context, action *Action) error {
+	if err := prepareGoSource(ctx, action); err != nil {
+		return err
+	}
+
 	// Ensure parent directory exists
 	dir := filepath.Dir(action.Path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -168,10 +260,12 @@ func (a *Agent) handleCreateFile(ctx context.Context, action *Action) error {
 	}
 
 	// Create/Overwrite file
+	diff := fileDiff(action.Path, action.Content)
 	err := os.WriteFile(action.Path, []byte(action.Content), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write file %s: %w", action.Path, err)
 	}
+	action.Diff = diff
 
 	// Add file metadata to action
 	if meta, metaErr := getFileMetadata(action.Path); metaErr == nil {
@@ -185,10 +279,12 @@ func (a *Agent) handleCreateFile(ctx context.Context, action *Action) error {
 
 // handleDeleteFile removes a file from the filesystem.
 func (a *Agent) handleDeleteFile(ctx context.Context, action *Action) error {
+	diff := fileDiff(action.Path, "")
 	err := os.Remove(action.Path)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete file %s: %w", action.Path, err)
 	}
+	action.Diff = diff
 	return nil
 }
 
@@ -201,7 +297,15 @@ func (a *Agent) handleEditFile(ctx context.Context, action *Action) error {
 
 	// If action.Content is provided, we treat it as the new full content (full file replacement).
 	if action.Content != "" {
-		return os.WriteFile(action.Path, []byte(action.Content), 0644)
+		if err := prepareGoSource(ctx, action); err != nil {
+			return err
+		}
+		diff := fileDiff(action.Path, action.Content)
+		if err := os.WriteFile(action.Path, []byte(action.Content), 0644); err != nil {
+			return err
+		}
+		action.Diff = diff
+		return nil
 	}
 
 	// Placeholder for actual edit logic
@@ -234,7 +338,12 @@ func (a *Agent) handleCopyFile(ctx context.Context, action *Action) error {
 		return err
 	}
 
-	return os.WriteFile(action.NewPath, data, 0644)
+	diff := fileDiff(action.NewPath, string(data))
+	if err := os.WriteFile(action.NewPath, data, 0644); err != nil {
+		return err
+	}
+	action.Diff = diff
+	return nil
 }
 
 // handleCreateDir creates a new directory.
@@ -257,125 +366,49 @@ func (a *Agent) handleMoveDir(ctx context.Context, action *Action) error {
 	return os.Rename(action.Path, action.NewPath)
 }
 
-// handleCopyDir copies a directory recursively.
+// handleCopyDir copies a directory recursively, ignored files included.
+// Symlinks are copied as symlinks; other special files, which cannot be
+// copied, are listed in the "skipped" metadata.
 func (a *Agent) handleCopyDir(ctx context.Context, action *Action) error {
-	return filepath.Walk(action.Path, func(path string, info os.FileInfo, err error) error {
+	var skipped []string
+	err := filepath.WalkDir(action.Path, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-
 		rel, err := filepath.Rel(action.Path, path)
 		if err != nil {
 			return err
 		}
-
 		targetPath := filepath.Join(action.NewPath, rel)
 
-		if info.IsDir() {
-			return os.MkdirAll(targetPath, info.Mode())
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(targetPath, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, targetPath)
+		case !info.Mode().IsRegular():
+			skipped = append(skipped, filepath.ToSlash(rel))
+			return nil
 		}
 
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-
-		return os.WriteFile(targetPath, data, info.Mode())
+		return os.WriteFile(targetPath, data, info.Mode().Perm())
 	})
-}
-
-// handleRunCommand executes a shell command with timeout and environment protection.
-func (a *Agent) handleRunCommand(ctx context.Context, action *Action) error {
-	cmd := exec.CommandContext(ctx, "sh", "-c", action.Command)
-	cmd.Env = os.Environ()
-	
-	output, err := cmd.CombinedOutput()
-	action.Output = string(output)
-	
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			action.ExitCode = exitErr.ExitCode()
-		} else {
-			action.ExitCode = -1
-		}
-		return fmt.Errorf("command failed with exit code %d: %w", action.ExitCode, err)
-	}
-	
-	action.ExitCode = 0
-	return nil
-}
-
-// handleLint runs a linter on the specified path.
-func (a *Agent) handleLint(ctx context.Context, action *Action) error {
-	lang := detectLanguage(action.Path)
-	var cmdStr string
-	switch lang {
-	case "go":
-		cmdStr = "go vet " + action.Path
-	case "python":
-		cmdStr = "pylint " + action.Path
-	case "javascript", "typescript":
-		cmdStr = "eslint " + action.Path
-	default:
-		return fmt.Errorf("unsupported language for linting: %s", lang)
-	}
-
-	action.Command = cmdStr
-	return a.handleRunCommand(ctx, action)
-}
-
-// handleFormat runs a formatter on the specified path.
-func (a *Agent) handleFormat(ctx context.Context, action *Action) error {
-	lang := detectLanguage(action.Path)
-	var cmdStr string
-	switch lang {
-	case "go":
-		cmdStr = "go fmt " + action.Path
-	case "python":
-		cmdStr = "black " + action.Path
-	case "javascript", "typescript":
-		cmdStr = "prettier --write " + action.Path
-	default:
-		return fmt.Errorf("unsupported language for formatting: %s", lang)
-	}
-
-	action.Command = cmdStr
-	return a.handleRunCommand(ctx, action)
-}
-
-// handleTest runs tests on the specified path.
-func (a *Agent) handleTest(ctx context.Context, action *Action) error {
-	lang := detectLanguage(action.Path)
-	var cmdStr string
-	switch lang {
-	case "go":
-		cmdStr = "go test -v " + action.Path
-	case "python":
-		cmdStr = "pytest " + action.Path
-	case "javascript", "typescript":
-		cmdStr = "npm test " + action.Path
-	default:
-		return fmt.Errorf("unsupported language for testing: %s", lang)
-	}
-
-	action.Command = cmdStr
-	return a.handleRunCommand(ctx, action)
-}
-
-func detectLanguage(path string) string {
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".go":
-		return "go"
-	case ".py":
-		return "python"
-	case ".js", ".jsx":
-		return "javascript"
-	case ".ts", ".tsx":
-		return "typescript"
-	default:
-		return "unknown"
+	if len(skipped) > 0 {
+		action.Metadata["skipped"] = skipped
 	}
+	return err
 }
 
 // handleReadFile reads the content of a file.
@@ -397,10 +430,17 @@ func (a *Agent) handleReadFile(ctx context.Context, action *Action) error {
 	return nil
 }
 
-// handleSearchFiles searches for files matching a pattern using glob.
+// handleSearchFiles searches file contents for the regular expression in
+// action.Content, limited to the globs in the "paths" metadata.
 func (a *Agent) handleSearchFiles(ctx context.Context, action *Action) error {
+	paths, _ := action.Metadata["paths"].([]string)
+
 	// Use ripgrep if available
-	cmd := exec.CommandContext(ctx, "rg", "--line-number", "--no-heading", "--color", "never", action.Content, action.Path)
+	cmd := exec.CommandContext(ctx, "rg", "--line-number", "--no-heading", "--color", "never")
+	for _, p := range paths {
+		cmd.Args = append(cmd.Args, "--glob", p)
+	}
+	cmd.Args = append(cmd.Args, "--", action.Content, action.Path)
 	if action.Path == "" {
 		cmd.Args[len(cmd.Args)-1] = "."
 	}
@@ -411,41 +451,61 @@ func (a *Agent) handleSearchFiles(ctx context.Context, action *Action) error {
 		return nil
 	}
 
-	// Fallback to filepath.Walk
-	return a.manualSearch(action, action.Content, action.Path)
+	// Fall back to the workspace trigram index
+	return a.indexedSearch(action, action.Content, action.Path, paths)
 }
 
-func (a *Agent) manualSearch(action *Action, pattern, scope string) error {
+// indexedSearch searches scope with the trigram index of its directory,
+// built on first use. A pattern that is not a valid regular expression is
+// matched literally.
+func (a *Agent) indexedSearch(action *Action, pattern, scope string, paths []string) error {
 	if scope == "" {
 		scope = "."
 	}
+	root := scope
+	if info, err := os.Stat(scope); err != nil {
+		return err
+	} else if !info.IsDir() {
+		root = filepath.Dir(scope)
+		paths = append([]string{"/" + filepath.Base(scope)}, paths...)
+	}
+
+	query := index.SearchQuery{Pattern: pattern, Paths: paths}
+	if _, err := regexp.Compile(pattern); err != nil {
+		query.Literal = true
+	}
+	idx := a.searchIndex(root)
+	matches, err := idx.Search(query)
+	if err != nil {
+		return err
+	}
+
 	var sb strings.Builder
-	err := filepath.Walk(scope, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
-			return nil
-		}
-		// Skip binary files and large files
-		if info.Size() > 1024*1024 {
-			return nil
-		}
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return nil
-		}
-		
-		content := string(data)
-		if strings.Contains(content, pattern) {
-			lines := strings.Split(content, "\n")
-			for i, line := range lines {
-				if strings.Contains(line, pattern) {
-					sb.WriteString(fmt.Sprintf("%s:%d:%s\n", path, i+1, strings.TrimSpace(line)))
-				}
-			}
-		}
-		return nil
-	})
+	for _, m := range matches {
+		sb.WriteString(fmt.Sprintf("%s:%d:%s\n", filepath.Join(root, filepath.FromSlash(m.Path)), m.Line, m.Text))
+	}
 	action.Output = sb.String()
-	return err
+	action.Metadata["index_files"] = idx.Len()
+	return nil
+}
+
+// searchIndex returns the trigram index of root, creating it on first use.
+func (a *Agent) searchIndex(root string) *index.TrigramIndex {
+	key := root
+	if abs, err := filepath.Abs(root); err == nil {
+		key = abs
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.searchIndexes == nil {
+		a.searchIndexes = make(map[string]*index.TrigramIndex)
+	}
+	idx, ok := a.searchIndexes[key]
+	if !ok {
+		idx = index.NewTrigramIndex(root)
+		a.searchIndexes[key] = idx
+	}
+	return idx
 }
 
 // handleListDir lists the contents of a directory.
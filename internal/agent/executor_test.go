@@ -84,3 +84,100 @@ func TestExecuteAction(t *testing.T) {
 		}
 	})
 }
+
+func TestReplayLastAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay.txt")
+	a := NewAgent(model.NewCoordinator(nil))
+	ctx := context.Background()
+
+	if _, err := a.ReplayLastAction(ctx); err == nil {
+		t.Fatal("expected error with no recorded actions")
+	}
+
+	a.executing = true
+	if err := a.executeAction(ctx, &Action{Type: ActionCreateFile, Path: path, Content: "v1"}); err != nil {
+		t.Fatalf("executeAction failed: %v", err)
+	}
+	a.executing = false
+
+	// A tripped loop detector must not block a manual replay.
+	ld := NewLoopDetector(2, 0.5)
+	ld.detected = &LoopDetectedError{Reason: "stuck", Strategy: StrategyEscalate}
+	a.RegisterPlugin(ld)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := a.ReplayLastAction(ctx)
+	if err != nil {
+		t.Fatalf("ReplayLastAction failed: %v", err)
+	}
+	if replay.Metadata["replay_of"] != "A00001" {
+		t.Errorf("replay_of = %v, want A00001", replay.Metadata["replay_of"])
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "v1" {
+		t.Errorf("replayed file = %q, %v; want v1", data, err)
+	}
+	if len(a.GetActions()) != 2 {
+		t.Errorf("actions = %d, want 2", len(a.GetActions()))
+	}
+}
+
+func TestIndexedSearch(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\nfunc Run() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("Run( the tests\n"), 0644)
+	a := NewAgent(model.NewCoordinator(nil))
+
+	action := Action{Metadata: map[string]any{}}
+	if err := a.indexedSearch(&action, `func \w+\(`, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "a.go") + ":2:func Run() {}\n"; action.Output != want {
+		t.Errorf("regex search output = %q, want %q", action.Output, want)
+	}
+
+	// An invalid regular expression is matched literally
+	if err := a.indexedSearch(&action, "Run(", dir, []string{"*.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "b.txt") + ":1:Run( the tests\n"; action.Output != want {
+		t.Errorf("literal search output = %q, want %q", action.Output, want)
+	}
+
+	// A file scope searches only that file, reusing the directory's index
+	if err := a.indexedSearch(&action, "Run", filepath.Join(dir, "a.go"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "a.go") + ":2:func Run() {}\n"; action.Output != want {
+		t.Errorf("file search output = %q, want %q", action.Output, want)
+	}
+	if len(a.searchIndexes) != 1 {
+		t.Errorf("got %d search indexes, want 1", len(a.searchIndexes))
+	}
+}
+
+func TestHandleCopyDir(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "build"), 0755)
+	os.WriteFile(filepath.Join(src, ".gitignore"), []byte("build/\n"), 0644)
+	os.WriteFile(filepath.Join(src, "build", "out.bin"), []byte("artifact"), 0644)
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main\n"), 0644)
+	if err := os.Symlink("main.go", filepath.Join(src, "link.go")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	a := NewAgent(model.NewCoordinator(nil))
+	action := Action{Type: ActionCopyDir, Path: src, NewPath: dst, Metadata: map[string]any{}}
+	if err := a.handleCopyDir(context.Background(), &action); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dst, "build", "out.bin")); err != nil || string(data) != "artifact" {
+		t.Errorf("ignored file not copied: %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "link.go")); err != nil || link != "main.go" {
+		t.Errorf("symlink not copied: %q, %v", link, err)
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// goimportsTimeout bounds a goimports run on a generated file.
+const goimportsTimeout = 10 * time.Second
+
+// SyntaxError rejects a write whose content does not parse.
+type SyntaxError struct {
+	Path        string
+	Diagnostics []Diagnostic
+}
+
+// Error lists the syntax errors as path:line:col: message lines.
+func (e *SyntaxError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s does not parse (%d errors), file not written:", e.Path, len(e.Diagnostics))
+	for _, d := range e.Diagnostics {
+		fmt.Fprintf(&sb, "\n%s:%d:%d: %s", d.Path, d.Line, d.Column, d.Message)
+	}
+	return sb.String()
+}
+
+// prepareGoSource parses the Go content an action is about to write and
+// rejects it with a *SyntaxError when it does not parse. Content that
+// parses is run through goimports, or gofmt when goimports is not
+// installed. Other files are left alone.
+func prepareGoSource(ctx context.Context, action *Action) error {
+	if filepath.Ext(action.Path) != ".go" || action.Content == "" {
+		return nil
+	}
+	src := []byte(action.Content)
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, action.Path, src, parser.AllErrors|parser.ParseComments); err != nil {
+		syntaxErr := &SyntaxError{Path: action.Path}
+		var list scanner.ErrorList
+		if errors.As(err, &list) {
+			for _, e := range list {
+				syntaxErr.Diagnostics = append(syntaxErr.Diagnostics, Diagnostic{
+					Path:     action.Path,
+					Line:     e.Pos.Line,
+					Column:   e.Pos.Column,
+					Severity: "error",
+					Message:  e.Msg,
+				})
+			}
+		} else {
+			syntaxErr.Diagnostics = []Diagnostic{{Path: action.Path, Severity: "error", Message: err.Error()}}
+		}
+		action.ToolResult = &ToolchainResult{Toolchain: "go", Diagnostics: syntaxErr.Diagnostics}
+		action.Metadata["syntax_errors"] = len(syntaxErr.Diagnostics)
+		return syntaxErr
+	}
+
+	formatted, tool := goimports(ctx, action.Path, src)
+	if formatted == nil {
+		var err error
+		if formatted, err = format.Source(src); err != nil {
+			return nil
+		}
+		tool = "gofmt"
+	}
+	action.Content = string(formatted)
+	action.Metadata["formatted_by"] = tool
+	return nil
+}
+
+// goimports formats src with goimports, resolving imports against the
+// file's directory. It returns nil when goimports is not installed or fails.
+func goimports(ctx context.Context, path string, src []byte) ([]byte, string) {
+	bin, err := exec.LookPath("goimports")
+	if err != nil {
+		return nil, ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, goimportsTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, bin, "-srcdir", filepath.Dir(path))
+	cmd.Stdin = bytes.NewReader(src)
+	out, err := cmd.Output()
+	if err != nil || len(out) == 0 {
+		return nil, ""
+	}
+	return out, "goimports"
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateFile_RejectsUnparsableGo(t *testing.T) {
+	a := newCommandAgent()
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	err := a.CreateFile(context.Background(), path, "package main\n\nfunc main() {\n\tif true {\n}\n")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("CreateFile() error = %v, want *SyntaxError", err)
+	}
+	if len(syntaxErr.Diagnostics) == 0 || syntaxErr.Diagnostics[0].Line == 0 {
+		t.Errorf("Diagnostics = %+v", syntaxErr.Diagnostics)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("unparsable file was written")
+	}
+	if action := a.GetActions()[0]; action.ToolResult == nil || action.Metadata["syntax_errors"] == nil {
+		t.Errorf("action = %+v", action)
+	}
+}
+
+func TestCreateFile_FormatsGo(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no goimports
+	a := newCommandAgent()
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	if err := a.CreateFile(context.Background(), path, "package main\nfunc main(){ println( 1 ) }"); err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "package main\n\nfunc main() { println(1) }\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+	if got := a.GetActions()[0].Metadata["formatted_by"]; got != "gofmt" {
+		t.Errorf("formatted_by = %v", got)
+	}
+}
@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// HTTP request limits
+const (
+	// DefaultHTTPTimeout bounds requests that do not set their own timeout.
+	DefaultHTTPTimeout = 30 * time.Second
+
+	maxHTTPBody = 1024 * 1024 // bytes of response body read
+)
+
+// HTTPRequest is a request the agent sends to verify an endpoint, with
+// optional assertions on the response.
+type HTTPRequest struct {
+	Method  string            `json:"method,omitempty"` // GET when empty
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Timeout time.Duration     `json:"timeout,omitempty"` // 0 uses DefaultHTTPTimeout
+
+	ExpectStatus int    `json:"expect_status,omitempty"` // 0 skips the status check
+	ExpectBody   string `json:"expect_body,omitempty"`   // regular expression the body must match
+}
+
+// HTTPResponse is the response to an HTTPRequest.
+type HTTPResponse struct {
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body"`
+	Duration time.Duration     `json:"duration"`
+
+	// Failures lists the assertions the response did not meet.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Passed reports whether the response met every assertion.
+func (r *HTTPResponse) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// RequestHTTP sends req and checks its assertions. The response is returned
+// even when an assertion fails; the error then lists the failures.
+func (a *Agent) RequestHTTP(ctx context.Context, req HTTPRequest) (*HTTPResponse, error) {
+	action := Action{
+		Type:    ActionHTTPRequest,
+		Request: &req,
+	}
+	err := a.executeAction(ctx, &action)
+	return action.Response, err
+}
+
+// handleHTTPRequest sends the action's request and checks the response.
+func (a *Agent) handleHTTPRequest(ctx context.Context, action *Action) error {
+	req := action.Request
+	if req == nil || req.URL == "" {
+		return errors.New("http request URL is required")
+	}
+	if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+		return fmt.Errorf("unsupported URL %q: only http and https are allowed", req.URL)
+	}
+	var bodyRe *regexp.Regexp
+	if req.ExpectBody != "" {
+		re, err := regexp.Compile(req.ExpectBody)
+		if err != nil {
+			return fmt.Errorf("invalid body assertion: %w", err)
+		}
+		bodyRe = re
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.URL, body)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	action.Command = method + " " + req.URL
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, req.URL, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBody))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if len(data) == maxHTTPBody {
+		data = trimPartialRune(data)
+	}
+
+	result := &HTTPResponse{
+		Status:   resp.StatusCode,
+		Headers:  make(map[string]string, len(resp.Header)),
+		Body:     string(data),
+		Duration: time.Since(start),
+	}
+	for k := range resp.Header {
+		result.Headers[k] = resp.Header.Get(k)
+	}
+	if req.ExpectStatus != 0 && resp.StatusCode != req.ExpectStatus {
+		result.Failures = append(result.Failures, fmt.Sprintf("status %d, want %d", resp.StatusCode, req.ExpectStatus))
+	}
+	if bodyRe != nil && !bodyRe.Match(data) {
+		result.Failures = append(result.Failures, fmt.Sprintf("body does not match %q", req.ExpectBody))
+	}
+
+	action.Response = result
+	action.Duration = result.Duration
+	action.Output = fmt.Sprintf("HTTP %d (%s)\n%s", resp.StatusCode, result.Duration.Round(time.Millisecond), result.Body)
+	if !result.Passed() {
+		return fmt.Errorf("%s %s: assertion failed: %s", method, req.URL, strings.Join(result.Failures, "; "))
+	}
+	return nil
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off at the end of b.
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"created":` + string(body) + `}`))
+	}))
+	defer srv.Close()
+
+	a := newCommandAgent()
+	ctx := context.Background()
+
+	resp, err := a.RequestHTTP(ctx, HTTPRequest{
+		Method:       "post",
+		URL:          srv.URL + "/users",
+		Headers:      map[string]string{"X-Token": "secret"},
+		Body:         `"ada"`,
+		ExpectStatus: http.StatusCreated,
+		ExpectBody:   `"created":"ada"`,
+	})
+	if err != nil {
+		t.Fatalf("RequestHTTP() error = %v", err)
+	}
+	if resp.Status != http.StatusCreated || resp.Headers["Content-Type"] != "application/json" || !resp.Passed() {
+		t.Errorf("response = %+v", resp)
+	}
+	if got := a.actions[len(a.actions)-1].ActionOutput(); got != "Agent • Requested POST "+srv.URL+"/users (HTTP 201)" {
+		t.Errorf("ActionOutput() = %q", got)
+	}
+
+	resp, err = a.RequestHTTP(ctx, HTTPRequest{URL: srv.URL, ExpectStatus: http.StatusOK, ExpectBody: "created"})
+	if err == nil || !strings.Contains(err.Error(), "assertion failed") {
+		t.Fatalf("RequestHTTP() error = %v, want assertion failure", err)
+	}
+	if resp == nil || len(resp.Failures) != 2 || resp.Status != http.StatusBadRequest {
+		t.Errorf("failed response = %+v", resp)
+	}
+
+	if _, err := a.RequestHTTP(ctx, HTTPRequest{URL: "file:///etc/passwd"}); err == nil {
+		t.Error("non-HTTP URLs should be rejected")
+	}
+}
+
+func TestClassifyHTTPRequest(t *testing.T) {
+	local := &Action{Type: ActionHTTPRequest, Request: &HTTPRequest{URL: "http://localhost:8080/health"}}
+	if classes := classifyAction(local); len(classes) != 0 {
+		t.Errorf("local request classes = %v", classes)
+	}
+	remote := &Action{Type: ActionHTTPRequest, Request: &HTTPRequest{URL: "https://example.com/api"}}
+	if classes := classifyAction(remote); len(classes) != 1 || classes[0] != ClassNetworkCommand {
+		t.Errorf("remote request classes = %v", classes)
+	}
+}
+
+func TestTrimPartialRune(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"abc", "abc"},
+		{"abé", "abé"},
+		{"ab\xc3", "ab"},
+		{"ab\xe2\x82", "ab"},
+		{"ab€", "ab€"},
+	}
+	for _, tt := range tests {
+		if got := string(trimPartialRune([]byte(tt.in))); got != tt.want {
+			t.Errorf("trimPartialRune(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
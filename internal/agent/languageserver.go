@@ -0,0 +1,358 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Language server limits
+const (
+	// lspStartTimeout bounds how long a language server may take to start
+	// and initialize.
+	lspStartTimeout = time.Minute
+	// lspRequestTimeout bounds a hover or references request.
+	lspRequestTimeout = 30 * time.Second
+	// lspDiagnosticsWait is how long the agent waits for the diagnostics of
+	// a document the server has not yet analyzed.
+	lspDiagnosticsWait = 15 * time.Second
+	// maxReferences caps the references given to the model.
+	maxReferences = 100
+)
+
+// SymbolRef names a symbol by an occurrence in the action's file.
+type SymbolRef struct {
+	Name string
+	Line int // 1-based line of an occurrence in the file; 0 finds one
+}
+
+// Location is a place in a file, such as a reference to a symbol.
+type Location struct {
+	Path   string
+	Line   int
+	Column int    // 1-based, in UTF-16 code units as language servers count
+	Text   string // the line, trimmed
+}
+
+// languageServer is a running language server of a toolchain, or the error
+// it failed to start with, which is not retried until the servers stop.
+type languageServer struct {
+	client *lspClient
+	err    error
+}
+
+// languageServer returns the running language server of tc, starting it in
+// the working directory when it is not running.
+func (a *Agent) languageServer(ctx context.Context, tc Toolchain) (*lspClient, error) {
+	a.lspMu.Lock()
+	defer a.lspMu.Unlock()
+	if s, ok := a.languageServers[tc.Name]; ok {
+		return s.client, s.err
+	}
+	if a.languageServers == nil {
+		a.languageServers = make(map[string]*languageServer)
+	}
+
+	s := &languageServer{}
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, lspStartTimeout)
+	defer cancel()
+	name, args := a.shellCommand(tc.LanguageServer, "")
+	s.client, s.err = startLSP(ctx, root, name, args...)
+	if s.err != nil {
+		s.err = fmt.Errorf("start %s: %w", tc.LanguageServer, s.err)
+	}
+	a.languageServers[tc.Name] = s
+	return s.client, s.err
+}
+
+// StopLanguageServers stops the language servers the agent started. It is
+// called when an orchestration process ends and is safe to call at any time.
+func (a *Agent) StopLanguageServers() {
+	a.lspMu.Lock()
+	servers := a.languageServers
+	a.languageServers = nil
+	a.lspMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		if s.client == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(c *lspClient) {
+			defer wg.Done()
+			c.close()
+		}(s.client)
+	}
+	wg.Wait()
+}
+
+// notifyLanguageServers tells the running language servers about the files
+// a successful action created, changed, or deleted.
+func (a *Agent) notifyLanguageServers(action *Action) {
+	type change struct {
+		path string
+		kind int
+	}
+	var changes []change
+	switch action.Type {
+	case ActionCreateFile, ActionCopyFile:
+		changes = []change{{action.Path, 1}}
+		if action.Type == ActionCopyFile {
+			changes = []change{{action.NewPath, 1}}
+		}
+	case ActionEditFile:
+		changes = []change{{action.Path, 2}}
+	case ActionDeleteFile:
+		changes = []change{{action.Path, 3}}
+	case ActionRenameFile, ActionMoveFile:
+		changes = []change{{action.Path, 3}, {action.NewPath, 1}}
+	default:
+		return
+	}
+
+	a.lspMu.Lock()
+	var clients []*lspClient
+	for _, s := range a.languageServers {
+		if s.client != nil {
+			clients = append(clients, s.client)
+		}
+	}
+	a.lspMu.Unlock()
+	for _, c := range clients {
+		for _, ch := range changes {
+			_ = c.fileChanged(ch.path, ch.kind)
+		}
+	}
+}
+
+// fileLanguageServer returns the toolchain of the file at path and its
+// running language server, with the file's current text sent to it.
+func (a *Agent) fileLanguageServer(ctx context.Context, path string) (Toolchain, *lspClient, error) {
+	tc, ok := a.fileToolchain(path)
+	if !ok || tc.LanguageServer == "" {
+		return tc, nil, fmt.Errorf("no language server for %s: it needs one, such as gopls", path)
+	}
+	c, err := a.languageServer(ctx, tc)
+	if err != nil {
+		return tc, nil, err
+	}
+	_, err = c.sync(path, languageID(tc, path))
+	return tc, c, err
+}
+
+// Diagnostics returns the errors and warnings the language server of the
+// file at path reports for it.
+func (a *Agent) Diagnostics(ctx context.Context, path string) ([]Diagnostic, error) {
+	action := Action{Type: ActionDiagnostics, Path: path}
+	err := a.executeAction(ctx, &action)
+	if action.ToolResult == nil {
+		return nil, err
+	}
+	return action.ToolResult.Diagnostics, err
+}
+
+// Hover returns the language server's description of a symbol in the file
+// at path: its type, signature, and documentation.
+func (a *Agent) Hover(ctx context.Context, path string, symbol SymbolRef) (string, error) {
+	action := Action{Type: ActionHover, Path: path, Symbol: &symbol}
+	err := a.executeAction(ctx, &action)
+	return action.Output, err
+}
+
+// References returns the places referencing a symbol in the file at path,
+// its declaration included.
+func (a *Agent) References(ctx context.Context, path string, symbol SymbolRef) ([]Location, error) {
+	action := Action{Type: ActionReferences, Path: path, Symbol: &symbol}
+	err := a.executeAction(ctx, &action)
+	return action.Locations, err
+}
+
+// handleDiagnostics asks the language server for the diagnostics of the
+// action's file, waiting for them when the server has a new text of it.
+func (a *Agent) handleDiagnostics(ctx context.Context, action *Action) error {
+	abs, err := filepath.Abs(action.Path)
+	if err != nil {
+		return err
+	}
+	tc, ok := a.fileToolchain(action.Path)
+	if !ok || tc.LanguageServer == "" {
+		return fmt.Errorf("no language server for %s: it needs one, such as gopls", action.Path)
+	}
+	c, err := a.languageServer(ctx, tc)
+	if err != nil {
+		return err
+	}
+	since := c.diagnosticsGeneration(abs)
+	sent, err := c.sync(action.Path, languageID(tc, action.Path))
+	if err != nil {
+		return err
+	}
+	if !sent && since > 0 {
+		since-- // the latest diagnostics are for the current text
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, lspDiagnosticsWait)
+	defer cancel()
+	items := c.waitDiagnostics(waitCtx, abs, since)
+
+	result := &ToolchainResult{Toolchain: tc.Name}
+	for _, d := range items {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Path:     action.Path,
+			Line:     d.Range.Start.Line + 1,
+			Column:   d.Range.Start.Character + 1,
+			Severity: lspSeverity(d.Severity),
+			Message:  d.Message,
+		})
+	}
+	action.ToolResult = result
+	action.Metadata["toolchain"] = tc.Name
+	action.Metadata["diagnostics"] = len(result.Diagnostics)
+	action.Output = formatDiagnostics(result.Diagnostics)
+	return nil
+}
+
+// handleHover asks the language server to describe the action's symbol.
+func (a *Agent) handleHover(ctx context.Context, action *Action) error {
+	return a.querySymbol(ctx, action, func(ctx context.Context, c *lspClient, pos lspPosition) error {
+		text, err := c.hover(ctx, action.Path, pos)
+		if err == nil && text == "" {
+			err = errors.New("nothing to describe")
+		}
+		action.Output = text
+		return err
+	})
+}
+
+// handleReferences asks the language server for the references to the
+// action's symbol.
+func (a *Agent) handleReferences(ctx context.Context, action *Action) error {
+	return a.querySymbol(ctx, action, func(ctx context.Context, c *lspClient, pos lspPosition) error {
+		locations, err := c.references(ctx, action.Path, pos)
+		if err != nil {
+			return err
+		}
+		if len(locations) == 0 {
+			return errors.New("no references found")
+		}
+		action.Locations = referenceLocations(locations)
+		action.Metadata["references"] = len(locations)
+		action.Output = formatLocations(action.Locations, len(locations))
+		return nil
+	})
+}
+
+// querySymbol runs query at the occurrences of the action's symbol in turn
+// until one succeeds, since some occurrences are in comments or strings.
+func (a *Agent) querySymbol(ctx context.Context, action *Action, query func(context.Context, *lspClient, lspPosition) error) error {
+	s := action.Symbol
+	if s == nil || s.Name == "" {
+		return errors.New("symbol is required")
+	}
+	data, err := os.ReadFile(action.Path)
+	if err != nil {
+		return err
+	}
+	positions := symbolPositions(string(data), s.Name, s.Line)
+	if len(positions) == 0 {
+		return fmt.Errorf("%s does not appear in %s", s.Name, action.Path)
+	}
+	positions = positions[:min(len(positions), maxRenameCandidates)]
+	tc, c, err := a.fileLanguageServer(ctx, action.Path)
+	if err != nil {
+		return err
+	}
+	action.Metadata["toolchain"] = tc.Name
+
+	ctx, cancel := context.WithTimeout(ctx, lspRequestTimeout)
+	defer cancel()
+	for _, pos := range positions {
+		if err = query(ctx, c, pos); err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", action.Type, s.Name, err)
+	}
+	return nil
+}
+
+// referenceLocations converts reference locations, sorted by path and line
+// and capped at maxReferences, with the text of their lines.
+func referenceLocations(locations []lspLocation) []Location {
+	out := make([]Location, 0, len(locations))
+	lines := make(map[string][]string)
+	for _, l := range locations {
+		path, err := uriPath(l.URI)
+		if err != nil {
+			continue
+		}
+		if _, ok := lines[path]; !ok {
+			data, _ := os.ReadFile(path)
+			lines[path] = strings.Split(string(data), "\n")
+		}
+		loc := Location{Path: workingPath(path), Line: l.Range.Start.Line + 1, Column: l.Range.Start.Character + 1}
+		if l.Range.Start.Line < len(lines[path]) {
+			loc.Text = strings.TrimSpace(lines[path][l.Range.Start.Line])
+		}
+		out = append(out, loc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Line < out[j].Line
+	})
+	if len(out) > maxReferences {
+		out = out[:maxReferences]
+	}
+	return out
+}
+
+// lspSeverity names an LSP diagnostic severity.
+func lspSeverity(severity int) string {
+	switch severity {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	case 4:
+		return "hint"
+	}
+	return "info"
+}
+
+// formatDiagnostics formats diagnostics as path:line:col lines for the model.
+func formatDiagnostics(diags []Diagnostic) string {
+	if len(diags) == 0 {
+		return "no problems found\n"
+	}
+	var sb strings.Builder
+	for _, d := range diags {
+		fmt.Fprintf(&sb, "%s:%d:%d: %s: %s\n", d.Path, d.Line, d.Column, d.Severity, d.Message)
+	}
+	return sb.String()
+}
+
+// formatLocations formats locations as path:line:col lines for the model;
+// total is how many were found before the cap.
+func formatLocations(locations []Location, total int) string {
+	var sb strings.Builder
+	for _, l := range locations {
+		fmt.Fprintf(&sb, "%s:%d:%d: %s\n", l.Path, l.Line, l.Column, l.Text)
+	}
+	if total > len(locations) {
+		fmt.Fprintf(&sb, "(%d of %d references)\n", len(locations), total)
+	}
+	return sb.String()
+}
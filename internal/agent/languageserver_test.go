@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLanguageServer_Lookups(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{
+		"a.go": "package p\n\n// total sums xs.\nfunc total(xs []int) int { return 0 }\n",
+		"b.go": "package p\n\nvar t = total(nil)\n",
+	})
+	ctx := context.Background()
+
+	diags, err := a.Diagnostics(ctx, "b.go")
+	if err != nil || len(diags) != 0 {
+		t.Fatalf("Diagnostics(b.go) = %v, %v; want none", diags, err)
+	}
+	os.WriteFile("b.go", []byte("package p\n\nvar t = undefined(nil)\n"), 0644)
+	diags, err = a.Diagnostics(ctx, "b.go")
+	if err != nil || len(diags) != 1 || diags[0].Line != 3 || diags[0].Column != 9 || diags[0].Severity != "error" {
+		t.Fatalf("Diagnostics(b.go) after the change = %+v, %v; want the undefined error at 3:9", diags, err)
+	}
+	os.WriteFile("b.go", []byte("package p\n\nvar t = total(nil)\n"), 0644)
+
+	hover, err := a.Hover(ctx, "a.go", SymbolRef{Name: "total"})
+	if err != nil || hover != "func total(xs []int) int { return 0 }" {
+		t.Errorf("Hover(total) = %q, %v", hover, err)
+	}
+
+	refs, err := a.References(ctx, "a.go", SymbolRef{Name: "total", Line: 4})
+	if err != nil {
+		t.Fatalf("References(total) error = %v", err)
+	}
+	var got []string
+	for _, r := range refs {
+		got = append(got, r.Path+":"+formatInt(r.Line)+":"+formatInt(r.Column))
+	}
+	if strings.Join(got, " ") != "a.go:3:4 a.go:4:6 b.go:3:9" {
+		t.Errorf("References(total) = %v", got)
+	}
+
+	actions := a.GetActions()
+	if last := actions[len(actions)-1]; last.ActionOutput() != "Agent • Found 3 references to total" {
+		t.Errorf("ActionOutput() = %q", last.ActionOutput())
+	}
+	if n := a.GetStats().SymbolLookups; n != 4 {
+		t.Errorf("SymbolLookups = %d, want 4", n)
+	}
+
+	a.StopLanguageServers()
+	if _, err := a.Hover(ctx, "a.go", SymbolRef{Name: "missing"}); err == nil || !strings.Contains(err.Error(), "does not appear") {
+		t.Errorf("Hover(missing) error = %v", err)
+	}
+}
+
+func TestHoverText(t *testing.T) {
+	for _, tt := range []struct{ raw, want string }{
+		{`"plain"`, "plain"},
+		{`{"kind": "markdown", "value": "**x**"}`, "**x**"},
+		{`[{"language": "go", "value": "var x int"}, "doc"]`, "var x int\n\ndoc"},
+		{`null`, ""},
+	} {
+		if got := hoverText([]byte(tt.raw)); got != tt.want {
+			t.Errorf("hoverText(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// LoopStrategy is the strategy change applied when the agent is stuck in a loop.
+// Strategies escalate each time a loop is detected in the same process, over
+// all of its runs.
+type LoopStrategy string
+
+const (
+	// StrategyEscalate re-runs the process with an escalation prompt
+	StrategyEscalate LoopStrategy = "escalate"
+	// StrategySwitchModel re-runs the process on a different model
+	StrategySwitchModel LoopStrategy = "switch_model"
+	// StrategyRevisitPlan abandons the process and forces a Plan schedule revisit
+	StrategyRevisitPlan LoopStrategy = "revisit_plan"
+	// StrategySuspend fails the process once every other strategy was
+	// tried, for the orchestrator to suspend the run
+	StrategySuspend LoopStrategy = "suspend"
+)
+
+// loopStrategies is the escalation ladder, indexed by detection count.
+var loopStrategies = []LoopStrategy{StrategyEscalate, StrategySwitchModel, StrategyRevisitPlan}
+
+// String returns the string representation of the strategy
+func (s LoopStrategy) String() string {
+	return string(s)
+}
+
+// LoopDetectedError is returned when the agent repeats near-identical actions.
+type LoopDetectedError struct {
+	Reason   string
+	Strategy LoopStrategy
+	Actions  []Action
+}
+
+// Error implements the error interface
+func (e *LoopDetectedError) Error() string {
+	return fmt.Sprintf("loop detected (%s): %s", e.Strategy, e.Reason)
+}
+
+// EscalationPrompt returns guidance telling the model to break out of the loop.
+func (e *LoopDetectedError) EscalationPrompt() string {
+	var sb strings.Builder
+	sb.WriteString("WARNING: You are stuck in a loop. " + e.Reason + ".\n")
+	sb.WriteString("Your repeated actions were:\n")
+	for _, a := range e.Actions {
+		sb.WriteString("  - " + a.ActionOutput() + "\n")
+	}
+	sb.WriteString("Stop repeating them. Re-read the relevant files, reconsider the root cause, and take a different approach.")
+	return sb.String()
+}
+
+// Default loop detection thresholds.
+const (
+	DefaultLoopMaxRepeats = 3
+	DefaultLoopSimilarity = 0.9
+	loopHistorySize       = 10
+)
+
+// LoopDetector is an agent plugin that detects when the agent issues
+// near-identical actions repeatedly: writes to the same path with
+// near-identical content, or the same failing command. Once a loop is
+// detected further actions are rejected with a *LoopDetectedError whose
+// Strategy escalates with each detection in the same process, however often
+// the process re-runs, up to StrategySuspend.
+type LoopDetector struct {
+	*BasePlugin
+
+	mu          sync.Mutex
+	maxRepeats  int
+	maxFailures int // failing runs of a command; 0 uses maxRepeats
+	similarity  float64
+	history     []Action
+	process     string         // the process executing, as schedule/process
+	levels      map[string]int // detections by process
+	detected    *LoopDetectedError
+}
+
+// NewLoopDetector creates a loop detector. An action is a repeat when it is
+// at least similarity (0-1) alike an earlier one; maxRepeats occurrences
+// within the recent history trigger detection.
+func NewLoopDetector(maxRepeats int, similarity float64) *LoopDetector {
+	if maxRepeats < 2 {
+		maxRepeats = DefaultLoopMaxRepeats
+	}
+	if similarity <= 0 || similarity > 1 {
+		similarity = DefaultLoopSimilarity
+	}
+	return &LoopDetector{
+		BasePlugin: NewBasePlugin("loop_detector"),
+		maxRepeats: maxRepeats,
+		similarity: similarity,
+		levels:     make(map[string]int),
+	}
+}
+
+// SetMaxFailures sets how many failing runs of the same command are a loop;
+// fewer than 2 uses the repeats of NewLoopDetector.
+func (d *LoopDetector) SetMaxFailures(n int) {
+	if n < 2 {
+		n = 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.maxFailures = n
+}
+
+// Detected returns the loop detected in the current process, if any.
+func (d *LoopDetector) Detected() *LoopDetectedError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.detected
+}
+
+// OnBeforeExecute clears the action history for a new attempt. The
+// process's escalation level is kept so that re-runs of a stuck process
+// escalate.
+func (d *LoopDetector) OnBeforeExecute(ctx context.Context, schedule string, process string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.history = nil
+	d.detected = nil
+	d.process = schedule + "/" + process
+	return nil
+}
+
+// OnBeforeAction rejects actions once a loop has been detected.
+func (d *LoopDetector) OnBeforeAction(ctx context.Context, action *Action) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.detected != nil {
+		return d.detected
+	}
+	return nil
+}
+
+// OnAfterAction compares the action against recent history.
+func (d *LoopDetector) OnAfterAction(ctx context.Context, action *Action) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.detected != nil {
+		return nil
+	}
+
+	repeats := []Action{*action}
+	for _, prev := range d.history {
+		if d.isRepeat(&prev, action) {
+			repeats = append(repeats, prev)
+		}
+	}
+
+	d.history = append(d.history, *action)
+	if len(d.history) > loopHistorySize {
+		d.history = d.history[len(d.history)-loopHistorySize:]
+	}
+
+	var reason string
+	switch action.Type {
+	case ActionEditFile, ActionCreateFile:
+		if len(repeats) < d.maxRepeats {
+			return nil
+		}
+		reason = fmt.Sprintf("wrote near-identical content to %s %d times", action.Path, len(repeats))
+	default:
+		threshold := d.maxRepeats
+		if d.maxFailures > 0 {
+			threshold = d.maxFailures
+		}
+		if len(repeats) < threshold {
+			return nil
+		}
+		reason = fmt.Sprintf("%q failed %d times", loopCommandKey(action), len(repeats))
+	}
+
+	strategy := StrategySuspend
+	if level := d.levels[d.process]; level < len(loopStrategies) {
+		strategy = loopStrategies[level]
+	}
+	d.levels[d.process]++
+
+	d.detected = &LoopDetectedError{
+		Reason:   reason,
+		Strategy: strategy,
+		Actions:  repeats,
+	}
+	return nil
+}
+
+// isRepeat reports whether cur is a near-identical repeat of prev.
+func (d *LoopDetector) isRepeat(prev, cur *Action) bool {
+	if prev.Type != cur.Type {
+		return false
+	}
+	switch cur.Type {
+	case ActionEditFile, ActionCreateFile:
+		return prev.Path == cur.Path &&
+			ContentSimilarity(actionContent(prev), actionContent(cur)) >= d.similarity
+	case ActionRunCommand, ActionLint, ActionFormat, ActionTest, ActionDockerBuild, ActionDockerRun:
+		return actionFailed(prev) && actionFailed(cur) && loopCommandKey(prev) == loopCommandKey(cur)
+	default:
+		return false
+	}
+}
+
+// loopCommandKey returns a whitespace-normalized key for a command action,
+// without the name generated for each container it runs.
+func loopCommandKey(action *Action) string {
+	if action.Command != "" {
+		return containerNameRe.ReplaceAllString(strings.Join(strings.Fields(action.Command), " "), "")
+	}
+	return string(action.Type) + " " + action.Path
+}
+
+// actionContent returns the written content of a file action.
+func actionContent(action *Action) string {
+	if action.Content != "" || action.Diff == nil {
+		return action.Content
+	}
+	var sb strings.Builder
+	for _, l := range action.Diff.Additions {
+		sb.WriteString("+" + l.Content + "\n")
+	}
+	for _, l := range action.Diff.Deletions {
+		sb.WriteString("-" + l.Content + "\n")
+	}
+	return sb.String()
+}
+
+// ContentSimilarity returns the Dice coefficient (0-1) of the trimmed,
+// non-empty lines of a and b.
+func ContentSimilarity(a, b string) float64 {
+	la, lb := contentLines(a), contentLines(b)
+	if len(la) == 0 && len(lb) == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(la))
+	for _, l := range la {
+		counts[l]++
+	}
+	common := 0
+	for _, l := range lb {
+		if counts[l] > 0 {
+			counts[l]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(la)+len(lb))
+}
+
+// contentLines splits s into trimmed, non-empty lines.
+func contentLines(s string) []string {
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// AlternateModel returns the model to switch to when the current one is stuck.
+func AlternateModel(current orchestrate.ModelType) orchestrate.ModelType {
+	if current == orchestrate.ModelCoder {
+		return orchestrate.ModelOrchestrator
+	}
+	return orchestrate.ModelCoder
+}
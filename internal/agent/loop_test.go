@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestContentSimilarity(t *testing.T) {
+	if got := ContentSimilarity("a\nb\nc", "a\nb\nc"); got != 1 {
+		t.Errorf("identical content similarity = %v, want 1", got)
+	}
+	if got := ContentSimilarity("a\nb\nc\nd", "a\nb\nc\ne"); got != 0.75 {
+		t.Errorf("similarity = %v, want 0.75", got)
+	}
+	if got := ContentSimilarity("a", "b"); got != 0 {
+		t.Errorf("disjoint content similarity = %v, want 0", got)
+	}
+}
+
+func TestLoopDetector_Escalation(t *testing.T) {
+	ctx := context.Background()
+	ld := NewLoopDetector(3, 0.8)
+
+	content := "package main\nfunc main() {\n}\n// attempt\n"
+	loopIn := func(process string) *LoopDetectedError {
+		_ = ld.OnBeforeExecute(ctx, "Implement", process)
+		for i := 0; i < 3; i++ {
+			_ = ld.OnAfterAction(ctx, &Action{Type: ActionCreateFile, Path: "main.go", Content: content})
+		}
+		return ld.Detected()
+	}
+	// The strategies escalate over the re-runs of a process, and stay
+	// exhausted when it runs again after a Plan revisit
+	for _, want := range []LoopStrategy{StrategyEscalate, StrategySwitchModel, StrategyRevisitPlan, StrategySuspend, StrategySuspend} {
+		loop := loopIn("1")
+		if loop == nil {
+			t.Fatal("expected loop to be detected")
+		}
+		if loop.Strategy != want {
+			t.Errorf("strategy = %s, want %s", loop.Strategy, want)
+		}
+		var loopErr *LoopDetectedError
+		if err := ld.OnBeforeAction(ctx, &Action{Type: ActionReadFile}); !errors.As(err, &loopErr) {
+			t.Errorf("expected LoopDetectedError, got %v", err)
+		}
+	}
+
+	_ = ld.OnBeforeExecute(ctx, "Implement", "2")
+	if ld.Detected() != nil {
+		t.Error("OnBeforeExecute should clear the detected loop")
+	}
+	if loop := loopIn("2"); loop == nil || loop.Strategy != StrategyEscalate {
+		t.Errorf("loop in another process = %v, want it to start escalating anew", loop)
+	}
+}
+
+func TestLoopDetector_FailingCommand(t *testing.T) {
+	ctx := context.Background()
+	ld := NewLoopDetector(2, 0.9)
+	_ = ld.OnBeforeExecute(ctx, "Implement", "2")
+
+	_ = ld.OnAfterAction(ctx, &Action{Type: ActionRunCommand, Command: "go  build ./...", ExitCode: 1})
+	_ = ld.OnAfterAction(ctx, &Action{Type: ActionRunCommand, Command: "go build ./...", ExitCode: 0})
+	if ld.Detected() != nil {
+		t.Fatal("a successful re-run should not count as a loop")
+	}
+	_ = ld.OnAfterAction(ctx, &Action{Type: ActionRunCommand, Command: "go build ./...", ExitCode: 2})
+	if ld.Detected() == nil {
+		t.Fatal("expected identical failing command to be detected")
+	}
+
+	// Each container run has its own name
+	_ = ld.OnBeforeExecute(ctx, "Implement", "4")
+	for i := 1; i <= 2; i++ {
+		command := fmt.Sprintf("docker run --rm --name obot-42-%d golang sh -c 'go test'", i)
+		_ = ld.OnAfterAction(ctx, &Action{Type: ActionDockerRun, Command: command, ExitCode: 1})
+	}
+	if ld.Detected() == nil {
+		t.Fatal("expected the same failing container run to be detected")
+	}
+
+	// The process budget's repeated failures replace the repeats for commands
+	ld.SetMaxFailures(3)
+	_ = ld.OnBeforeExecute(ctx, "Implement", "3")
+	for i := 1; i <= 3; i++ {
+		_ = ld.OnAfterAction(ctx, &Action{Type: ActionTest, Command: "go test ./...", ExitCode: 1})
+		if detected := ld.Detected() != nil; detected != (i == 3) {
+			t.Fatalf("loop detected = %v after %d failing runs, want it after 3", detected, i)
+		}
+	}
+}
@@ -0,0 +1,638 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// lspClient is a minimal Language Server Protocol client: it runs a
+// language server and exchanges JSON-RPC messages with it over the server's
+// stdin and stdout. A goroutine reads the server's messages, answering its
+// requests and keeping the diagnostics it publishes.
+type lspClient struct {
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	done chan struct{} // closed when the server's output ends
+
+	writeMu sync.Mutex
+	syncMu  sync.Mutex // serializes document updates
+
+	mu          sync.Mutex
+	nextID      int
+	pending     map[int]chan *lspMessage
+	documents   map[string]*lspDocument // open documents by path
+	diagnostics map[string]*lspDiagnostics
+	published   chan struct{} // closed and replaced when diagnostics arrive
+	err         error
+}
+
+// lspDocument is a document open in the server, with the text it was sent.
+type lspDocument struct {
+	version int
+	text    string
+}
+
+// lspDiagnostics are the latest diagnostics published for a document;
+// generation counts the publications.
+type lspDiagnostics struct {
+	generation int
+	items      []lspDiagnostic
+}
+
+// lspPosition is a zero-based line and UTF-16 character offset.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"` // 1 error, 2 warning, 3 information, 4 hint
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// lspWorkspaceEdit is the answer to a rename: text edits by document URI,
+// in changes or in documentChanges.
+type lspWorkspaceEdit struct {
+	Changes         map[string][]lspTextEdit `json:"changes"`
+	DocumentChanges []struct {
+		Kind         string `json:"kind"` // set for file operations
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+		Edits []lspTextEdit `json:"edits"`
+	} `json:"documentChanges"`
+}
+
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// startLSP starts the language server program name with args in root and
+// initializes it for the workspace there. The server runs until close;
+// ctx bounds only its initialization.
+func startLSP(ctx context.Context, root, name string, args ...string) (*lspClient, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = root
+	cmd.Stderr = io.Discard
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := &lspClient{
+		cmd:         cmd,
+		in:          in,
+		done:        make(chan struct{}),
+		pending:     make(map[int]chan *lspMessage),
+		documents:   make(map[string]*lspDocument),
+		diagnostics: make(map[string]*lspDiagnostics),
+		published:   make(chan struct{}),
+	}
+	go c.readLoop(bufio.NewReader(out))
+
+	rootURI := fileURI(root)
+	init := map[string]any{
+		"processId": os.Getpid(),
+		"rootUri":   rootURI,
+		"capabilities": map[string]any{
+			"workspace": map[string]any{
+				"workspaceEdit":         map[string]any{"documentChanges": true},
+				"workspaceFolders":      true,
+				"configuration":         true,
+				"didChangeWatchedFiles": map[string]any{"dynamicRegistration": true},
+			},
+			"textDocument": map[string]any{
+				"synchronization":    map[string]any{"didSave": false},
+				"publishDiagnostics": map[string]any{"versionSupport": true},
+				"hover":              map[string]any{"contentFormat": []string{"markdown", "plaintext"}},
+				"references":         map[string]any{},
+				"rename":             map[string]any{"prepareSupport": false},
+			},
+		},
+		"workspaceFolders": []map[string]string{{"uri": rootURI, "name": filepath.Base(root)}},
+	}
+	if err := c.call(ctx, "initialize", init, nil); err != nil {
+		c.close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	if err := c.notify("initialized", map[string]any{}); err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// sync sends the server the text of the document at path, opening it if
+// needed, and of every open document changed on disk since it was sent. It
+// reports whether the document at path was sent.
+func (c *lspClient) sync(path, languageID string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.mu.Lock()
+	docs := make(map[string]*lspDocument, len(c.documents))
+	for p, d := range c.documents {
+		docs[p] = d
+	}
+	c.mu.Unlock()
+
+	sent := false
+	for p, doc := range docs {
+		data, err := os.ReadFile(p)
+		if err != nil || string(data) == doc.text {
+			continue
+		}
+		doc.version++
+		doc.text = string(data)
+		if err := c.notify("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": fileURI(p), "version": doc.version},
+			"contentChanges": []map[string]string{{"text": doc.text}},
+		}); err != nil {
+			return false, err
+		}
+		sent = sent || p == path
+	}
+	if _, ok := docs[path]; ok {
+		return sent, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	doc := &lspDocument{version: 1, text: string(data)}
+	c.mu.Lock()
+	c.documents[path] = doc
+	c.mu.Unlock()
+	return true, c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri":        fileURI(path),
+			"languageId": languageID,
+			"version":    doc.version,
+			"text":       doc.text,
+		},
+	})
+}
+
+// fileChanged tells the server a file changed on disk; kind is 1 for
+// created, 2 for changed, and 3 for deleted.
+func (c *lspClient) fileChanged(path string, kind int) error {
+	return c.notify("workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": fileURI(path), "type": kind}},
+	})
+}
+
+// rename asks the server for the edits renaming the symbol at pos in the
+// document at path to newName.
+func (c *lspClient) rename(ctx context.Context, path string, pos lspPosition, newName string) (*lspWorkspaceEdit, error) {
+	var edit lspWorkspaceEdit
+	err := c.call(ctx, "textDocument/rename", map[string]any{
+		"textDocument": map[string]string{"uri": fileURI(path)},
+		"position":     pos,
+		"newName":      newName,
+	}, &edit)
+	if err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+// hover returns the server's description of the symbol at pos.
+func (c *lspClient) hover(ctx context.Context, path string, pos lspPosition) (string, error) {
+	var result struct {
+		Contents json.RawMessage `json:"contents"`
+	}
+	err := c.call(ctx, "textDocument/hover", map[string]any{
+		"textDocument": map[string]string{"uri": fileURI(path)},
+		"position":     pos,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	return hoverText(result.Contents), nil
+}
+
+// references returns the locations referencing the symbol at pos, its
+// declaration included.
+func (c *lspClient) references(ctx context.Context, path string, pos lspPosition) ([]lspLocation, error) {
+	var locations []lspLocation
+	err := c.call(ctx, "textDocument/references", map[string]any{
+		"textDocument": map[string]string{"uri": fileURI(path)},
+		"position":     pos,
+		"context":      map[string]bool{"includeDeclaration": true},
+	}, &locations)
+	return locations, err
+}
+
+// diagnosticsGeneration returns how many times diagnostics were published
+// for the document at path.
+func (c *lspClient) diagnosticsGeneration(path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d, ok := c.diagnostics[path]; ok {
+		return d.generation
+	}
+	return 0
+}
+
+// waitDiagnostics returns the diagnostics of the document at path once they
+// are published after generation since, or the latest ones when ctx ends
+// first.
+func (c *lspClient) waitDiagnostics(ctx context.Context, path string, since int) []lspDiagnostic {
+	for {
+		c.mu.Lock()
+		var d lspDiagnostics
+		if latest := c.diagnostics[path]; latest != nil {
+			d = *latest
+		}
+		published := c.published
+		c.mu.Unlock()
+		if d.generation > since {
+			return d.items
+		}
+		select {
+		case <-published:
+		case <-c.done:
+			return nil
+		case <-ctx.Done():
+			return d.items
+		}
+	}
+}
+
+// close shuts the server down, killing it if it does not exit.
+func (c *lspClient) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), lspShutdownTimeout)
+	defer cancel()
+	_ = c.call(ctx, "shutdown", nil, nil)
+	_ = c.notify("exit", nil)
+	c.in.Close()
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+}
+
+// lspShutdownTimeout bounds how long a language server may take to exit.
+const lspShutdownTimeout = 5 * time.Second
+
+// call sends a request and waits for its response.
+func (c *lspClient) call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *lspMessage, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return err
+	}
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return fmt.Errorf("%s: %s", method, msg.Error.Message)
+		}
+		if result == nil || len(msg.Result) == 0 || string(msg.Result) == "null" {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// readLoop reads the server's messages until its output ends.
+func (c *lspClient) readLoop(out *bufio.Reader) {
+	defer close(c.done)
+	for {
+		msg, err := readLSPMessage(out)
+		if err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil:
+			_ = c.answer(msg)
+		case msg.Method == "textDocument/publishDiagnostics":
+			c.publish(msg.Params)
+		case msg.Method != "":
+			// Other notifications, such as log messages
+		case msg.ID != nil:
+			id, err := strconv.Atoi(string(*msg.ID))
+			if err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch := c.pending[id]
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg
+			}
+		}
+	}
+}
+
+// publish keeps the diagnostics the server published for a document.
+func (c *lspClient) publish(params json.RawMessage) {
+	var p struct {
+		URI         string          `json:"uri"`
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	}
+	if json.Unmarshal(params, &p) != nil {
+		return
+	}
+	path, err := uriPath(p.URI)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.diagnostics[path]
+	if d == nil {
+		d = &lspDiagnostics{}
+		c.diagnostics[path] = d
+	}
+	d.generation++
+	d.items = p.Diagnostics
+	close(c.published)
+	c.published = make(chan struct{})
+}
+
+// answer replies to a request from the server: with a null setting for each
+// configuration item asked for, and null for anything else.
+func (c *lspClient) answer(msg *lspMessage) error {
+	var result any
+	if msg.Method == "workspace/configuration" {
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		result = make([]any, len(params.Items))
+	}
+	return c.write(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+}
+
+func (c *lspClient) notify(method string, params any) error {
+	return c.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// write sends a message with its Content-Length header.
+func (c *lspClient) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.in.Write(data)
+	return err
+}
+
+// readLSPMessage reads the next message.
+func readLSPMessage(out *bufio.Reader) (*lspMessage, error) {
+	length := -1
+	for {
+		line, err := out.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("language server closed: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(out, data); err != nil {
+		return nil, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return &msg, nil
+}
+
+// hoverText returns the text of hover contents: markup content, a marked
+// string, or a list of marked strings.
+func hoverText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var markup struct {
+		Value string `json:"value"`
+	}
+	if json.Unmarshal(raw, &markup) == nil && markup.Value != "" {
+		return markup.Value
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			if text := hoverText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return ""
+}
+
+// fileEdits returns the text edits of a workspace edit by file path. File
+// creations, renames, and deletions are refused.
+func (e *lspWorkspaceEdit) fileEdits() (map[string][]lspTextEdit, error) {
+	files := make(map[string][]lspTextEdit)
+	for uri, edits := range e.Changes {
+		path, err := uriPath(uri)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = append(files[path], edits...)
+	}
+	for _, dc := range e.DocumentChanges {
+		if dc.Kind != "" {
+			return nil, fmt.Errorf("the rename would %s a file", dc.Kind)
+		}
+		path, err := uriPath(dc.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = append(files[path], dc.Edits...)
+	}
+	return files, nil
+}
+
+// applyTextEdits applies LSP text edits, which do not overlap, to text.
+func applyTextEdits(text string, edits []lspTextEdit) (string, error) {
+	lines := lineOffsets(text)
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	for _, e := range edits {
+		start, err := byteOffset(text, lines, e.Range.Start)
+		if err != nil {
+			return "", err
+		}
+		end, err := byteOffset(text, lines, e.Range.End)
+		if err != nil {
+			return "", err
+		}
+		if end < start {
+			return "", fmt.Errorf("edit range ends before it starts")
+		}
+		spans = append(spans, span{start, end, e.NewText})
+	}
+	// Apply from the end so earlier offsets stay valid
+	for i := 1; i < len(spans); i++ {
+		for j := i; j > 0 && spans[j].start > spans[j-1].start; j-- {
+			spans[j], spans[j-1] = spans[j-1], spans[j]
+		}
+	}
+	for i, s := range spans {
+		if i > 0 && s.end > spans[i-1].start {
+			return "", fmt.Errorf("overlapping edits")
+		}
+		text = text[:s.start] + s.text + text[s.end:]
+	}
+	return text, nil
+}
+
+// lineOffsets returns the byte offset of the start of each line of text.
+func lineOffsets(text string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// byteOffset converts an LSP position, whose character counts UTF-16 code
+// units, to a byte offset in text.
+func byteOffset(text string, lines []int, pos lspPosition) (int, error) {
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return 0, fmt.Errorf("line %d is out of range", pos.Line+1)
+	}
+	offset := lines[pos.Line]
+	for units := 0; units < pos.Character; {
+		if offset >= len(text) || text[offset] == '\n' {
+			return 0, fmt.Errorf("character %d is out of range on line %d", pos.Character, pos.Line+1)
+		}
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+	return offset, nil
+}
+
+// lspCharacter returns the UTF-16 offset of the byte column col in line.
+func lspCharacter(line string, col int) int {
+	units := 0
+	for _, r := range line[:col] {
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+	return units
+}
+
+// fileURI returns the file URI of path.
+func fileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// uriPath returns the path of a file URI.
+func uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
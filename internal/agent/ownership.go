@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Ownership says who owns a file that is not the project's own code.
+type Ownership string
+
+const (
+	OwnershipVendored   Ownership = "vendored"    // a copy of a dependency
+	OwnershipGenerated  Ownership = "generated"   // output of a code generator
+	OwnershipThirdParty Ownership = "third_party" // code kept from another project
+)
+
+// FileOwner is the ownership of a file and what gave it away.
+type FileOwner struct {
+	Ownership Ownership // empty for the project's own files
+	Reason    string
+}
+
+// ownershipClasses are the policy classes of changes to files by ownership.
+var ownershipClasses = map[Ownership]ActionClass{
+	OwnershipVendored:   ClassVendoredFiles,
+	OwnershipGenerated:  ClassGeneratedFiles,
+	OwnershipThirdParty: ClassThirdPartyFiles,
+}
+
+// ownershipDirs are directories holding code the project does not own,
+// wherever they are in the tree, after GitHub Linguist's vendor.yml.
+var ownershipDirs = map[string]Ownership{
+	"vendor":           OwnershipVendored,
+	"node_modules":     OwnershipVendored,
+	"bower_components": OwnershipVendored,
+	"jspm_packages":    OwnershipVendored,
+	"Godeps":           OwnershipVendored,
+	"Pods":             OwnershipVendored,
+	"Carthage":         OwnershipVendored,
+	"third_party":      OwnershipThirdParty,
+	"third-party":      OwnershipThirdParty,
+	"thirdparty":       OwnershipThirdParty,
+}
+
+// ownershipFiles match the base names of files the project does not own,
+// after GitHub Linguist's generated.rb.
+var ownershipFiles = []struct {
+	pattern   string
+	ownership Ownership
+}{
+	{"*.min.js", OwnershipVendored},
+	{"*.min.css", OwnershipVendored},
+	{"*.pb.go", OwnershipGenerated},
+	{"*.pb.gw.go", OwnershipGenerated},
+	{"*_pb2.py", OwnershipGenerated},
+	{"*_pb2_grpc.py", OwnershipGenerated},
+	{"*.pb.cc", OwnershipGenerated},
+	{"*.pb.h", OwnershipGenerated},
+	{"*_generated.go", OwnershipGenerated},
+	{"zz_generated*.go", OwnershipGenerated},
+	{"*.g.dart", OwnershipGenerated},
+	{"*.designer.cs", OwnershipGenerated},
+	{"go.sum", OwnershipGenerated},
+	{"package-lock.json", OwnershipGenerated},
+	{"yarn.lock", OwnershipGenerated},
+	{"pnpm-lock.yaml", OwnershipGenerated},
+	{"Cargo.lock", OwnershipGenerated},
+	{"poetry.lock", OwnershipGenerated},
+	{"Gemfile.lock", OwnershipGenerated},
+	{"composer.lock", OwnershipGenerated},
+}
+
+// generatedHeaderRe matches the markers code generators put at the top of
+// their output: Go's "// Code generated ... DO NOT EDIT." line and the
+// @generated tag. Looser phrases such as "do not edit" also appear in
+// comments people write by hand.
+var generatedHeaderRe = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$|@generated\b`)
+
+// generatedHeaderLines is how many lines of a file are searched for a
+// generated-code marker.
+const generatedHeaderLines = 10
+
+// linguistAttrs are the .gitattributes attributes that set ownership.
+var linguistAttrs = map[string]Ownership{
+	"linguist-vendored":  OwnershipVendored,
+	"linguist-generated": OwnershipGenerated,
+}
+
+// ownershipAttr is a .gitattributes line setting or unsetting ownership.
+type ownershipAttr struct {
+	pattern   string
+	ownership Ownership
+	set       bool
+}
+
+// OwnershipMap tells which files of a workspace are vendored, generated,
+// or third-party: by the linguist-vendored and linguist-generated
+// attributes of its .gitattributes, by well-known paths, and by the
+// generated-code markers in file headers. It remembers the files it looked
+// at.
+type OwnershipMap struct {
+	workspace string
+	attrs     []ownershipAttr
+
+	mu    sync.Mutex
+	files map[string]FileOwner
+}
+
+// NewOwnershipMap returns the ownership map of workspace, reading its
+// .gitattributes if there is one; an empty workspace resolves paths against
+// the working directory.
+func NewOwnershipMap(workspace string) *OwnershipMap {
+	m := &OwnershipMap{workspace: workspace, files: make(map[string]FileOwner)}
+	f, err := os.Open(filepath.Join(workspace, ".gitattributes"))
+	if err != nil {
+		return m
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			name, value, hasValue := strings.Cut(attr, "=")
+			set := !strings.HasPrefix(name, "-") && !strings.HasPrefix(name, "!")
+			name = strings.TrimLeft(name, "-!")
+			if hasValue {
+				set = value != "false"
+			}
+			if ownership, ok := linguistAttrs[name]; ok {
+				m.attrs = append(m.attrs, ownershipAttr{pattern: fields[0], ownership: ownership, set: set})
+			}
+		}
+	}
+	return m
+}
+
+// Owner returns the ownership of the file at path.
+func (m *OwnershipMap) Owner(path string) FileOwner {
+	abs, err := filepath.Abs(filepath.Join(m.workspace, path))
+	if filepath.IsAbs(path) {
+		abs, err = filepath.Clean(path), nil
+	}
+	if err != nil {
+		return FileOwner{}
+	}
+	m.mu.Lock()
+	owner, ok := m.files[abs]
+	m.mu.Unlock()
+	if ok {
+		return owner
+	}
+	owner = m.owner(abs)
+	m.mu.Lock()
+	m.files[abs] = owner
+	m.mu.Unlock()
+	return owner
+}
+
+// owner works out the ownership of the file at the absolute path abs.
+func (m *OwnershipMap) owner(abs string) FileOwner {
+	rel := filepath.ToSlash(abs)
+	if root, err := filepath.Abs(m.workspace); err == nil {
+		if r, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+		}
+	}
+
+	var owner FileOwner
+	dirs := strings.Split(rel, "/")
+	for i, dir := range dirs {
+		ownership, ok := ownershipDirs[dir]
+		if ok && i == len(dirs)-1 {
+			info, err := os.Stat(abs)
+			ok = err == nil && info.IsDir()
+		}
+		if ok {
+			owner = FileOwner{Ownership: ownership, Reason: "in " + dir + "/"}
+			break
+		}
+	}
+	if owner.Ownership == "" {
+		for _, f := range ownershipFiles {
+			if ok, _ := filepath.Match(f.pattern, dirs[len(dirs)-1]); ok {
+				owner = FileOwner{Ownership: f.ownership, Reason: "named " + f.pattern}
+				break
+			}
+		}
+	}
+
+	// .gitattributes overrides the well-known paths, the last match winning
+	unset := make(map[Ownership]bool)
+	for _, attr := range m.attrs {
+		if !matchAttrPattern(attr.pattern, rel) {
+			continue
+		}
+		switch {
+		case attr.set:
+			owner = FileOwner{Ownership: attr.ownership, Reason: "linguist-" + string(attr.ownership) + " in .gitattributes"}
+			delete(unset, attr.ownership)
+		case owner.Ownership == attr.ownership:
+			owner = FileOwner{}
+			unset[attr.ownership] = true
+		default:
+			unset[attr.ownership] = true
+		}
+	}
+	if owner.Ownership != "" || unset[OwnershipGenerated] {
+		return owner
+	}
+	if marker := generatedHeader(abs); marker != "" {
+		return FileOwner{Ownership: OwnershipGenerated, Reason: "marked " + strconv.Quote(marker)}
+	}
+	return owner
+}
+
+// generatedHeader returns the generated-code marker at the top of the file
+// at path, or "" when there is none or the file cannot be read.
+func generatedHeader(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for i := 0; i < generatedHeaderLines && scanner.Scan(); i++ {
+		if marker := generatedHeaderRe.FindString(strings.TrimRight(scanner.Text(), "\r")); marker != "" {
+			return marker
+		}
+	}
+	return ""
+}
+
+// matchAttrPattern matches a slash-separated path against a .gitattributes
+// pattern: a pattern without a slash matches the base name anywhere, a
+// leading slash anchors it at the workspace, and a trailing "/**" matches
+// everything below a directory.
+func matchAttrPattern(pattern, path string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "**/")
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		pattern = dir + "/**"
+	}
+	return matchPolicyPath(pattern, path)
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/model"
+)
+
+func TestOwnershipMap_Owner(t *testing.T) {
+	ws := t.TempDir()
+	files := map[string]string{
+		".gitattributes": "# ownership\n" +
+			"gen/** linguist-generated\n" +
+			"assets/*.js linguist-vendored=true\n" +
+			"vendor/ours/** -linguist-vendored\n" +
+			"schema.go linguist-generated=false\n",
+		"main.go":               "package main\n",
+		"mock.go":               "// Code generated by MockGen. DO NOT EDIT.\npackage main\n",
+		"schema.go":             "// Code generated by ent, DO NOT EDIT.\npackage main\n",
+		"late.go":               strings.Repeat("//\n", generatedHeaderLines) + "// DO NOT EDIT\n",
+		"web/bundle.js":         "/* @generated */\n",
+		"handwritten.go":        "// Do not edit this by hand; it is auto-generated from docs.\npackage main\n",
+		"extern/lib/lib.go":     "package lib\n",
+		"gen/client.go":         "package gen\n",
+		"assets/jquery.js":      "\n",
+		"vendor/x/x.go":         "package x\n",
+		"vendor/ours/ours.go":   "package ours\n",
+		"third_party/z/z.c":     "\n",
+		"api/v1/api.pb.go":      "package v1\n",
+		"web/package-lock.json": "{}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(ws, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	m := NewOwnershipMap(ws)
+	tests := []struct {
+		path string
+		want Ownership
+	}{
+		{"main.go", ""},
+		{"mock.go", OwnershipGenerated},
+		{"schema.go", ""},
+		{"late.go", ""},
+		{"web/bundle.js", OwnershipGenerated},
+		{"handwritten.go", ""},
+		{"extern/lib/lib.go", ""},
+		{"gen/client.go", OwnershipGenerated},
+		{"assets/jquery.js", OwnershipVendored},
+		{"vendor/x/x.go", OwnershipVendored},
+		{"vendor/ours/ours.go", ""},
+		{"vendor", OwnershipVendored},
+		{"third_party/z/z.c", OwnershipThirdParty},
+		{"api/v1/api.pb.go", OwnershipGenerated},
+		{"web/package-lock.json", OwnershipGenerated},
+		{filepath.Join(ws, "vendor", "x", "new.go"), OwnershipVendored},
+	}
+	for _, tt := range tests {
+		if got := m.Owner(tt.path); got.Ownership != tt.want {
+			t.Errorf("Owner(%s) = %q (%s), want %q", tt.path, got.Ownership, got.Reason, tt.want)
+		}
+	}
+}
+
+func TestLoadTrustedPolicy_OwnershipOverride(t *testing.T) {
+	ws := t.TempDir()
+	os.MkdirAll(filepath.Join(ws, ".obot"), 0755)
+	os.WriteFile(PolicyPath(ws), []byte("rules:\n  - class: generated_files\n    decision: confirm\n"), 0644)
+
+	p, err := LoadTrustedPolicy(ws, nil)
+	if err != nil {
+		t.Fatalf("LoadTrustedPolicy() error = %v", err)
+	}
+	if got := p.Evaluate(&Action{Type: ActionEditFile, Path: filepath.Join(ws, "api.pb.go")}); got.Decision != PolicyConfirm {
+		t.Errorf("generated file: Evaluate() = %s, want confirm", got.Decision)
+	}
+	if got := p.Evaluate(&Action{Type: ActionEditFile, Path: filepath.Join(ws, "vendor", "x.go")}); got.Decision != PolicyBlock {
+		t.Errorf("vendored file: Evaluate() = %s, want block", got.Decision)
+	}
+}
+
+func TestExecuteAction_GeneratedFileBlocked(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mock.go")
+	os.WriteFile(target, []byte("// Code generated by MockGen. DO NOT EDIT.\npackage main\n"), 0644)
+
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	a.SetPolicy(DefaultPolicy(), func(PolicyCheck) bool { return true })
+	err := a.executeAction(context.Background(), &Action{Type: ActionDeleteFile, Path: target})
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || policyErr.Check.Decision != PolicyBlock {
+		t.Fatalf("delete generated file: err = %v, want blocking *PolicyError", err)
+	}
+	if !strings.Contains(err.Error(), "generated code is read-only") || !strings.Contains(err.Error(), "DO NOT EDIT") {
+		t.Errorf("error %q does not say why the file is read-only", err)
+	}
+	if _, statErr := os.Stat(target); statErr != nil {
+		t.Error("generated file was deleted")
+	}
+}
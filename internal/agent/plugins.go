@@ -24,6 +24,16 @@ type Plugin interface {
 	OnAfterExecute(ctx context.Context, schedule string, process string, err error) error
 }
 
+// ReplayPlugin is implemented by plugins that also see replayed actions,
+// such as those recording every action. Other plugins, such as the limits
+// that suspended the run, are bypassed during a replay.
+type ReplayPlugin interface {
+	Plugin
+
+	// OnReplay reports whether the plugin sees replayed actions.
+	OnReplay() bool
+}
+
 // BasePlugin provides a default implementation for the Plugin interface.
 // Other plugins can embed BasePlugin to only implement the methods they need.
 type BasePlugin struct {
@@ -0,0 +1,483 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyDecision is what a policy requires before an action may run.
+type PolicyDecision string
+
+const (
+	PolicyAllow   PolicyDecision = "allow"
+	PolicyConfirm PolicyDecision = "confirm"
+	PolicyBlock   PolicyDecision = "block"
+)
+
+// strictness orders decisions so the strictest matching rule wins.
+func (d PolicyDecision) strictness() int {
+	switch d {
+	case PolicyBlock:
+		return 2
+	case PolicyConfirm:
+		return 1
+	}
+	return 0
+}
+
+// ActionClass groups actions that policies treat alike.
+type ActionClass string
+
+const (
+	ClassDeleteFile         ActionClass = "delete_file"
+	ClassDeleteDir          ActionClass = "delete_dir"
+	ClassNetworkCommand     ActionClass = "network_command"
+	ClassDestructiveCommand ActionClass = "destructive_command"
+	ClassCIFiles            ActionClass = "ci_files"
+	ClassVendoredFiles      ActionClass = "vendored_files"
+	ClassGeneratedFiles     ActionClass = "generated_files"
+	ClassThirdPartyFiles    ActionClass = "third_party_files"
+)
+
+// actionClasses are the classes a policy rule can name.
+var actionClasses = []ActionClass{
+	ClassDeleteFile, ClassDeleteDir, ClassNetworkCommand, ClassDestructiveCommand,
+	ClassCIFiles, ClassVendoredFiles, ClassGeneratedFiles, ClassThirdPartyFiles,
+}
+
+// policyFile is the per-workspace policy file in the .obot directory.
+const policyFile = "policy.yaml"
+
+// PolicyRule applies a decision to actions of a class, or to actions whose
+// paths match one of Paths (glob patterns relative to the workspace) or whose
+// command matches one of Commands (regular expressions).
+type PolicyRule struct {
+	Class    ActionClass    `yaml:"class,omitempty"`
+	Paths    []string       `yaml:"paths,omitempty"`
+	Commands []string       `yaml:"commands,omitempty"`
+	Decision PolicyDecision `yaml:"decision"`
+	Reason   string         `yaml:"reason,omitempty"`
+
+	commandRes []*regexp.Regexp
+}
+
+// Policy decides which actions need human confirmation or are blocked.
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+
+	workspace string
+	owners    *OwnershipMap
+}
+
+// PolicyCheck describes an action that matched a confirm or block rule.
+type PolicyCheck struct {
+	Action   *Action
+	Decision PolicyDecision
+	Classes  []ActionClass
+	Reason   string
+}
+
+// Summary returns a one-line description of the checked action.
+func (c PolicyCheck) Summary() string {
+	target := c.Action.Path
+	if c.Action.Command != "" {
+		target = c.Action.Command
+	}
+	if c.Action.NewPath != "" {
+		target += " → " + c.Action.NewPath
+	}
+	return fmt.Sprintf("%s %s", c.Action.Type, target)
+}
+
+// PolicyError is returned when a policy blocks an action or the user
+// declines to confirm it.
+type PolicyError struct {
+	Check    PolicyCheck
+	Declined bool
+}
+
+func (e *PolicyError) Error() string {
+	verb := "blocked by policy"
+	if e.Declined {
+		verb = "not confirmed"
+	}
+	if e.Check.Reason != "" {
+		return fmt.Sprintf("%s %s: %s", e.Check.Summary(), verb, e.Check.Reason)
+	}
+	return fmt.Sprintf("%s %s", e.Check.Summary(), verb)
+}
+
+// DefaultPolicy asks for confirmation before deleting directories, running
+// network or destructive commands, and modifying CI configuration, and
+// blocks changes to vendored, generated, and third-party files.
+func DefaultPolicy() *Policy {
+	return &Policy{Rules: []PolicyRule{
+		{Class: ClassDeleteDir, Decision: PolicyConfirm, Reason: "deletes a directory tree"},
+		{Class: ClassNetworkCommand, Decision: PolicyConfirm, Reason: "accesses the network"},
+		{Class: ClassDestructiveCommand, Decision: PolicyConfirm, Reason: "may destroy work"},
+		{Class: ClassCIFiles, Decision: PolicyConfirm, Reason: "modifies CI configuration"},
+		{Class: ClassVendoredFiles, Decision: PolicyBlock, Reason: "vendored code is read-only; update the dependency instead"},
+		{Class: ClassGeneratedFiles, Decision: PolicyBlock, Reason: "generated code is read-only; change its source and regenerate it"},
+		{Class: ClassThirdPartyFiles, Decision: PolicyBlock, Reason: "third-party code is read-only"},
+	}, owners: NewOwnershipMap("")}
+}
+
+// PolicyPath returns the policy file path for workspace.
+func PolicyPath(workspace string) string {
+	return filepath.Join(workspace, ".obot", policyFile)
+}
+
+// LoadPolicy returns the default policy for workspace with the rules of its
+// .obot/policy.yaml added. The file comes with the workspace, so its rules
+// can only tighten the defaults; see LoadTrustedPolicy.
+func LoadPolicy(workspace string) (*Policy, error) {
+	return LoadPolicyWithRules(workspace, nil)
+}
+
+// LoadPolicyWithRules is LoadPolicy with rules from elsewhere, such as the
+// workspace's obot.yaml, following those of .obot/policy.yaml. Like the
+// file's, they only add to the default rules and cannot loosen the policy.
+func LoadPolicyWithRules(workspace string, extra []PolicyRule) (*Policy, error) {
+	return loadPolicy(workspace, extra, false)
+}
+
+// LoadTrustedPolicy is LoadPolicyWithRules for a policy file the user
+// trusts as it is now: a file rule for a class replaces the default rule for
+// that class, so it can loosen the policy. The extra rules still only add.
+func LoadTrustedPolicy(workspace string, extra []PolicyRule) (*Policy, error) {
+	return loadPolicy(workspace, extra, true)
+}
+
+// PolicyLoosening returns the rules of workspace's .obot/policy.yaml that
+// would loosen the default policy if the file were trusted, and the SHA-256
+// of the file's contents to pin that trust to. Both are empty without a file.
+func PolicyLoosening(workspace string) ([]PolicyRule, string, error) {
+	file, data, err := readPolicyFile(workspace)
+	if err != nil || data == nil {
+		return nil, "", err
+	}
+	defaults := make(map[ActionClass]PolicyDecision)
+	for _, r := range DefaultPolicy().Rules {
+		defaults[r.Class] = r.Decision
+	}
+	var loosening []PolicyRule
+	for _, r := range file.Rules {
+		if def, ok := defaults[r.Class]; ok && r.Class != "" && r.Decision.strictness() < def.strictness() {
+			loosening = append(loosening, r)
+		}
+	}
+	sum := sha256.Sum256(data)
+	return loosening, hex.EncodeToString(sum[:]), nil
+}
+
+// readPolicyFile parses workspace's .obot/policy.yaml and returns it with
+// its raw contents, which are nil when there is no file.
+func readPolicyFile(workspace string) (Policy, []byte, error) {
+	var file Policy
+	data, err := os.ReadFile(PolicyPath(workspace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return file, nil, nil
+		}
+		return file, nil, err
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return file, nil, fmt.Errorf("parse %s: %w", PolicyPath(workspace), err)
+	}
+	return file, data, nil
+}
+
+// loadPolicy returns the default policy for workspace with the rules of its
+// policy file and extra. Only a trusted file's class rules replace the
+// default rules for their classes.
+func loadPolicy(workspace string, extra []PolicyRule, trusted bool) (*Policy, error) {
+	policy := DefaultPolicy()
+	policy.workspace = workspace
+	policy.owners = NewOwnershipMap(workspace)
+
+	file, _, err := readPolicyFile(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Rules) == 0 && len(extra) == 0 {
+		return policy, nil
+	}
+
+	overridden := make(map[ActionClass]bool)
+	if trusted {
+		for _, r := range file.Rules {
+			if r.Class != "" {
+				overridden[r.Class] = true
+			}
+		}
+	}
+	rules := make([]PolicyRule, 0, len(policy.Rules)+len(file.Rules))
+	for _, r := range policy.Rules {
+		if !overridden[r.Class] {
+			rules = append(rules, r)
+		}
+	}
+	policy.Rules = append(append(rules, file.Rules...), extra...)
+	if err := policy.compile(); err != nil {
+		if len(extra) > 0 {
+			return nil, fmt.Errorf("workspace policy: %w", err)
+		}
+		return nil, fmt.Errorf("parse %s: %w", PolicyPath(workspace), err)
+	}
+	return policy, nil
+}
+
+// compile validates decisions and classes and compiles command patterns.
+func (p *Policy) compile() error {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		switch r.Decision {
+		case PolicyAllow, PolicyConfirm, PolicyBlock:
+		default:
+			return fmt.Errorf("rule %d: invalid decision %q (use allow, confirm, or block)", i+1, r.Decision)
+		}
+		if r.Class != "" && !slices.Contains(actionClasses, r.Class) {
+			return fmt.Errorf("rule %d: unknown class %q", i+1, r.Class)
+		}
+		r.commandRes = r.commandRes[:0]
+		for _, pattern := range r.Commands {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("rule %d: invalid command pattern %q: %w", i+1, pattern, err)
+			}
+			r.commandRes = append(r.commandRes, re)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the strictest decision of the rules matching action.
+func (p *Policy) Evaluate(action *Action) PolicyCheck {
+	owned := p.ownedPaths(action)
+	check := PolicyCheck{Action: action, Decision: PolicyAllow, Classes: classifyAction(action)}
+	for _, class := range []ActionClass{ClassVendoredFiles, ClassGeneratedFiles, ClassThirdPartyFiles} {
+		if _, ok := owned[class]; ok {
+			check.Classes = append(check.Classes, class)
+		}
+	}
+	for _, r := range p.Rules {
+		if !p.matches(r, action, check.Classes) {
+			continue
+		}
+		if r.Decision.strictness() > check.Decision.strictness() {
+			check.Decision = r.Decision
+			check.Reason = r.Reason
+			if why, ok := owned[r.Class]; ok {
+				check.Reason += " (" + why + ")"
+			}
+		}
+	}
+	return check
+}
+
+// ownedPaths returns the ownership classes of the files action changes, each
+// with the file and what gave its ownership away.
+func (p *Policy) ownedPaths(action *Action) map[ActionClass]string {
+	if p.owners == nil {
+		return nil
+	}
+	var paths []string
+	switch action.Type {
+	case ActionCopyFile, ActionCopyDir:
+		paths = []string{action.NewPath}
+	case ActionCreateFile, ActionDeleteFile, ActionEditFile, ActionRenameFile, ActionMoveFile,
+		ActionCreateDir, ActionDeleteDir, ActionRenameDir, ActionMoveDir, ActionRenameSymbol:
+		paths = actionPaths(action)
+	}
+	var owned map[ActionClass]string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		owner := p.owners.Owner(path)
+		class, ok := ownershipClasses[owner.Ownership]
+		if !ok {
+			continue
+		}
+		if owned == nil {
+			owned = make(map[ActionClass]string)
+		}
+		if _, seen := owned[class]; !seen {
+			owned[class] = p.relative(path) + " " + owner.Reason
+		}
+	}
+	return owned
+}
+
+// matches reports whether rule r applies to action.
+func (p *Policy) matches(r PolicyRule, action *Action, classes []ActionClass) bool {
+	for _, c := range classes {
+		if r.Class != "" && r.Class == c {
+			return true
+		}
+	}
+	for _, pattern := range r.Paths {
+		for _, path := range actionPaths(action) {
+			if matchPolicyPath(pattern, p.relative(path)) {
+				return true
+			}
+		}
+	}
+	if action.Command != "" {
+		for _, re := range r.commandRes {
+			if re.MatchString(action.Command) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relative returns path relative to the policy's workspace when possible.
+func (p *Policy) relative(path string) string {
+	if p.workspace != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(p.workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// matchPolicyPath matches a slash-separated path against a glob pattern in
+// which a trailing "/**" matches everything below a directory.
+func matchPolicyPath(pattern, path string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return path == dir || strings.HasPrefix(path, dir+"/")
+	}
+	if ok, _ := filepath.Match(pattern, path); ok {
+		return true
+	}
+	// Patterns without a slash match the base name anywhere
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, filepath.Base(path))
+		return ok
+	}
+	return false
+}
+
+// actionPaths returns the paths an action reads or changes.
+func actionPaths(action *Action) []string {
+	var paths []string
+	if action.Path != "" {
+		paths = append(paths, action.Path)
+	}
+	if action.NewPath != "" {
+		paths = append(paths, action.NewPath)
+	}
+	return paths
+}
+
+// ciPathPatterns match CI configuration files.
+var ciPathPatterns = []string{
+	".github/workflows/**",
+	".gitlab-ci.yml",
+	".circleci/**",
+	".buildkite/**",
+	".travis.yml",
+	"azure-pipelines.yml",
+	"Jenkinsfile",
+	"bitbucket-pipelines.yml",
+}
+
+// networkCommandRe matches commands that reach the network.
+var networkCommandRe = regexp.MustCompile(`(?:^|[\s;&|(])(?:curl|wget|ssh|scp|sftp|rsync|nc|ncat|telnet|ftp)\s|` +
+	`\bgit\s+(?:push|pull|fetch|clone)\b|\b(?:npm|yarn|pnpm)\s+(?:install|add|publish)\b|` +
+	`\bpip3?\s+install\b|\bgo\s+(?:get|install)\b|\bdocker\s+(?:pull|push)\b`)
+
+// destructiveCommandRe matches commands that discard files or history.
+var destructiveCommandRe = regexp.MustCompile(`\brm\s+(?:-[a-zA-Z]*[rf][a-zA-Z]*\s+)+|` +
+	`\bgit\s+(?:reset\s+--hard|clean\s+-[a-zA-Z]*f|push\s+(?:.*\s)?(?:--force|-f)\b|checkout\s+--\s)|` +
+	`\bmkfs\b|\bdd\s+if=|` +
+	// Containers and images, and containers given the host's root or
+	// full privileges
+	`\bdocker\s+(?:(?:container|image|volume|network|system|builder)\s+)?(?:rm|rmi|prune|kill)\b|` +
+	`\bdocker\s+(?:container\s+)?(?:run|create)\b.*(?:\s(?:-v|--volume)[\s=]+/:|\s--mount[\s=]+\S*(?:source|src)=/(?:,|\s|$)|\s--privileged\b)`)
+
+// classifyAction returns the policy classes of an action.
+func classifyAction(action *Action) []ActionClass {
+	var classes []ActionClass
+	switch action.Type {
+	case ActionDeleteFile:
+		classes = append(classes, ClassDeleteFile)
+	case ActionDeleteDir:
+		classes = append(classes, ClassDeleteDir)
+	case ActionRunCommand, ActionStartBackground:
+		if networkCommandRe.MatchString(action.Command) {
+			classes = append(classes, ClassNetworkCommand)
+		}
+		if destructiveCommandRe.MatchString(action.Command) {
+			classes = append(classes, ClassDestructiveCommand)
+		}
+	case ActionHTTPRequest:
+		if action.Request != nil && !isLocalURL(action.Request.URL) {
+			classes = append(classes, ClassNetworkCommand)
+		}
+	case ActionDockerBuild, ActionDockerRun:
+		if action.Docker != nil && action.Docker.Network {
+			classes = append(classes, ClassNetworkCommand)
+		}
+		if action.Type == ActionDockerRun && action.Docker != nil && action.Docker.Mount != "" {
+			if abs, err := filepath.Abs(action.Docker.Mount); err == nil && filepath.Dir(abs) == abs {
+				classes = append(classes, ClassDestructiveCommand)
+			}
+		}
+	}
+
+	switch action.Type {
+	case ActionCreateFile, ActionDeleteFile, ActionEditFile, ActionRenameFile, ActionMoveFile,
+		ActionCopyFile, ActionCreateDir, ActionDeleteDir, ActionRenameDir, ActionMoveDir, ActionCopyDir:
+		for _, path := range actionPaths(action) {
+			if isCIPath(path) {
+				classes = append(classes, ClassCIFiles)
+				break
+			}
+		}
+	}
+	return classes
+}
+
+// isLocalURL reports whether rawURL points at this machine.
+func isLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1", "0.0.0.0":
+		return true
+	}
+	return false
+}
+
+// isCIPath reports whether path is or contains CI configuration.
+func isCIPath(path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for _, pattern := range ciPathPatterns {
+		dir, recursive := strings.CutSuffix(pattern, "/**")
+		if recursive {
+			if strings.HasSuffix(path, "/"+dir) || path == dir ||
+				strings.Contains(path, "/"+dir+"/") || strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+			continue
+		}
+		if filepath.Base(path) == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,10 @@
 package agent
 
 import (
+	"bytes"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +22,7 @@ type Recorder struct {
 	fileDeletes   []Action
 	dirOperations []Action
 	delegations   []Action
+	tagged        int // actions already linked to a session state
 
 	startTime time.Time
 }
@@ -60,6 +64,17 @@ func (r *Recorder) Record(action Action) {
 	}
 }
 
+// TagState links the actions recorded since the last call to the session
+// state stateID, which records the process they ran in.
+func (r *Recorder) TagState(stateID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ; r.tagged < len(r.actions); r.tagged++ {
+		r.actions[r.tagged].StateID = stateID
+	}
+}
+
 // GetAllActions returns all recorded actions
 func (r *Recorder) GetAllActions() []Action {
 	r.mu.Lock()
@@ -259,3 +274,107 @@ func (r *Recorder) GenerateDiff(path string) string {
 
 	return sb.String()
 }
+
+// FileChange is the net change the agent made to one file.
+type FileChange struct {
+	Path        string
+	LinesBefore int // 0 for a file the agent created
+	LinesAfter  int // 0 for a file the agent deleted
+	Added       int
+	Removed     int
+	Actions     []ActionType // in the order applied
+	Size        int64        // final size in bytes, -1 once deleted
+	StateIDs    []string     // session states of the processes that changed it
+}
+
+// FileChanges reports the files the agent created, edited, deleted, renamed,
+// moved, or copied to, in the order first changed. Lines added and removed
+// come from the diff of each action; the final lines and size are read from
+// the file as it is now, and the lines before are derived from them.
+func (r *Recorder) FileChanges() []FileChange {
+	r.mu.Lock()
+	actions := make([]Action, len(r.actions))
+	copy(actions, r.actions)
+	r.mu.Unlock()
+
+	var order []string
+	changes := make(map[string]*FileChange)
+	change := func(path string) *FileChange {
+		c, ok := changes[path]
+		if !ok {
+			c = &FileChange{Path: path}
+			changes[path] = c
+			order = append(order, path)
+		}
+		return c
+	}
+
+	for _, action := range actions {
+		if status, _ := action.Metadata["status"].(string); status == "failed" {
+			continue
+		}
+		var c *FileChange
+		switch action.Type {
+		case ActionCreateFile, ActionEditFile, ActionDeleteFile:
+			c = change(action.Path)
+		case ActionRenameFile, ActionMoveFile:
+			// The change follows the file to its new path
+			c = change(action.Path)
+			delete(changes, action.Path)
+			if i := slices.Index(order, action.Path); i >= 0 {
+				order = slices.Delete(order, i, i+1)
+			}
+			if prev, ok := changes[action.NewPath]; ok {
+				c.Actions = append(prev.Actions, c.Actions...)
+				c.Added += prev.Added
+				c.Removed += prev.Removed
+				stateIDs := slices.Clone(prev.StateIDs)
+				for _, id := range c.StateIDs {
+					if !slices.Contains(stateIDs, id) {
+						stateIDs = append(stateIDs, id)
+					}
+				}
+				c.StateIDs = stateIDs
+			} else {
+				order = append(order, action.NewPath)
+			}
+			c.Path = action.NewPath
+			changes[action.NewPath] = c
+		case ActionCopyFile:
+			c = change(action.NewPath)
+		default:
+			continue
+		}
+		c.Actions = append(c.Actions, action.Type)
+		if action.Diff != nil {
+			c.Added += action.Diff.TotalAdded
+			c.Removed += action.Diff.TotalRemoved
+		}
+		if action.StateID != "" && !slices.Contains(c.StateIDs, action.StateID) {
+			c.StateIDs = append(c.StateIDs, action.StateID)
+		}
+	}
+
+	result := make([]FileChange, 0, len(order))
+	for _, path := range order {
+		c := changes[path]
+		c.Size = -1
+		if data, err := os.ReadFile(path); err == nil {
+			c.Size = int64(len(data))
+			c.LinesAfter = countLines(data)
+		}
+		c.LinesBefore = max(c.LinesAfter-c.Added+c.Removed, 0)
+		result = append(result, *c)
+	}
+	return result
+}
+
+// countLines counts the lines of data, including a last line without a
+// newline.
+func countLines(data []byte) int {
+	n := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/croberts/obot/internal/model"
+)
+
+func TestRecorder_FileChanges(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	ctx := context.Background()
+
+	kept := filepath.Join(dir, "kept.txt")
+	if err := os.WriteFile(kept, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(dir, "gone.txt")
+	if err := os.WriteFile(gone, []byte("x\ny\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "new.txt")
+	renamed := filepath.Join(dir, "renamed.txt")
+
+	run := func(action Action) {
+		t.Helper()
+		if err := a.executeAction(ctx, &action); err != nil {
+			t.Fatalf("%s: %v", action.Type, err)
+		}
+	}
+	run(Action{Type: ActionEditFile, Path: kept, Content: "a\nB\nc\nd\n"})
+	run(Action{Type: ActionCreateFile, Path: created, Content: "one\ntwo"})
+	a.GetRecorder().TagState("0001-S3P1")
+	run(Action{Type: ActionEditFile, Path: kept, Content: "a\nB\nc\nd\ne\n"})
+	run(Action{Type: ActionRenameFile, Path: created, NewPath: renamed})
+	run(Action{Type: ActionDeleteFile, Path: gone})
+	a.GetRecorder().TagState("0002-S3P2")
+
+	changes := a.GetRecorder().FileChanges()
+	byPath := make(map[string]FileChange)
+	for _, c := range changes {
+		byPath[c.Path] = c
+	}
+	if len(changes) != 3 {
+		t.Fatalf("FileChanges() = %d files, want 3: %+v", len(changes), changes)
+	}
+
+	if c := byPath[kept]; c.LinesBefore != 3 || c.LinesAfter != 5 || c.Added != 3 || c.Removed != 1 ||
+		c.Size != 10 || !slices.Equal(c.StateIDs, []string{"0001-S3P1", "0002-S3P2"}) ||
+		!slices.Equal(c.Actions, []ActionType{ActionEditFile, ActionEditFile}) {
+		t.Errorf("edited file = %+v", c)
+	}
+	if c, ok := byPath[renamed]; !ok || c.LinesBefore != 0 || c.LinesAfter != 2 || c.Added != 2 ||
+		!slices.Equal(c.Actions, []ActionType{ActionCreateFile, ActionRenameFile}) {
+		t.Errorf("created then renamed file = %+v", c)
+	}
+	if c := byPath[gone]; c.Size != -1 || c.LinesBefore != 2 || c.LinesAfter != 0 || c.Removed != 2 ||
+		!slices.Equal(c.StateIDs, []string{"0002-S3P2"}) {
+		t.Errorf("deleted file = %+v", c)
+	}
+}
+
+func TestRecorder_FileChangesCopyAndRenameOntoTracked(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	ctx := context.Background()
+
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("1\n2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	copied := filepath.Join(dir, "copy.txt")
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+
+	run := func(action Action) {
+		t.Helper()
+		if err := a.executeAction(ctx, &action); err != nil {
+			t.Fatalf("%s: %v", action.Type, err)
+		}
+	}
+	run(Action{Type: ActionCopyFile, Path: src, NewPath: copied})
+	run(Action{Type: ActionCreateFile, Path: first, Content: "a\n"})
+	a.GetRecorder().TagState("0001-S3P1")
+	run(Action{Type: ActionCreateFile, Path: second, Content: "b\nc\n"})
+	run(Action{Type: ActionRenameFile, Path: second, NewPath: first})
+	a.GetRecorder().TagState("0002-S3P2")
+
+	byPath := make(map[string]FileChange)
+	for _, c := range a.GetRecorder().FileChanges() {
+		byPath[c.Path] = c
+	}
+	if c := byPath[copied]; c.LinesBefore != 0 || c.LinesAfter != 2 || c.Added != 2 {
+		t.Errorf("copy target = %+v", c)
+	}
+	if _, ok := byPath[second]; ok {
+		t.Errorf("renamed file still reported at its old path")
+	}
+	if c := byPath[first]; c.Added != 3 || c.LinesBefore != 0 || c.LinesAfter != 2 ||
+		!slices.Equal(c.StateIDs, []string{"0001-S3P1", "0002-S3P2"}) ||
+		!slices.Equal(c.Actions, []ActionType{ActionCreateFile, ActionCreateFile, ActionRenameFile}) {
+		t.Errorf("file renamed onto a tracked path = %+v", c)
+	}
+}
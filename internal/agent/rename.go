@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Symbol rename limits
+const (
+	renameTimeout = 2 * time.Minute
+
+	// maxRenameCandidates is how many occurrences of a symbol's name are
+	// tried before one is found, since some are in comments or strings.
+	maxRenameCandidates = 5
+)
+
+// SymbolRename renames a symbol and updates its references through the
+// language server of the file's toolchain, such as gopls or
+// typescript-language-server, instead of replacing text.
+type SymbolRename struct {
+	Symbol  string // the current name
+	NewName string
+	Line    int // 1-based line of an occurrence in the file; needed when the name is of more than one symbol in it
+
+	// Set by the rename: the files changed, in order
+	Files []string
+}
+
+// RenameSymbol renames symbol, declared or used in the file at path, to
+// newName in every file referencing it, and returns the files changed.
+func (a *Agent) RenameSymbol(ctx context.Context, path, symbol, newName string) ([]string, error) {
+	action := Action{Type: ActionRenameSymbol, Path: path, Rename: &SymbolRename{Symbol: symbol, NewName: newName}}
+	err := a.executeAction(ctx, &action)
+	return action.Rename.Files, err
+}
+
+// identRe matches identifiers in the languages with rename support.
+var identRe = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$]*$`)
+
+// handleRenameSymbol asks the language server for the edits of the rename,
+// checks every file they change against the scope and policy, and applies
+// them as an edit action per file, so that no file is changed when any is
+// refused.
+func (a *Agent) handleRenameSymbol(ctx context.Context, action *Action) error {
+	r := action.Rename
+	if r == nil || r.Symbol == "" || r.NewName == "" {
+		return errors.New("symbol and new name are required")
+	}
+	if !identRe.MatchString(r.NewName) {
+		return fmt.Errorf("%q is not an identifier", r.NewName)
+	}
+	data, err := os.ReadFile(action.Path)
+	if err != nil {
+		return err
+	}
+	positions := symbolPositions(string(data), r.Symbol, r.Line)
+	if len(positions) == 0 {
+		return fmt.Errorf("%s does not appear in %s", r.Symbol, action.Path)
+	}
+	tc, client, err := a.fileLanguageServer(ctx, action.Path)
+	if err != nil {
+		return err
+	}
+	action.Command = tc.LanguageServer
+	action.Metadata["toolchain"] = tc.Name
+
+	ctx, cancel := context.WithTimeout(ctx, renameTimeout)
+	defer cancel()
+	edit, err := renameEdit(ctx, client, action.Path, r, positions)
+	if err != nil {
+		return err
+	}
+	files, err := edit.fileEdits()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("the language server found nothing to rename for %s", r.Symbol)
+	}
+
+	// Work out every file's new content, check it may be written, and ask
+	// for the confirmations the policy requires, before changing any
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	edits := make([]*Action, 0, len(paths))
+	for _, path := range paths {
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content, err := applyTextEdits(string(old), files[path])
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if content == string(old) {
+			continue
+		}
+		e := &Action{
+			ID:       fmt.Sprintf("%s.%d", action.ID, len(edits)+1),
+			Type:     ActionEditFile,
+			Path:     workingPath(path),
+			Content:  content,
+			Metadata: map[string]any{"symbol_rename": action.ID},
+		}
+		if err := a.checkRenameTarget(e); err != nil {
+			return err
+		}
+		edits = append(edits, e)
+	}
+
+	for _, e := range edits {
+		if err := a.executeAction(ctx, e); err != nil {
+			return fmt.Errorf("rename %s stopped after %d of %d files: %w", r.Symbol, len(r.Files), len(edits), err)
+		}
+		r.Files = append(r.Files, e.Path)
+	}
+	action.Output = "Renamed " + r.Symbol + " to " + r.NewName + " in " + strings.Join(r.Files, ", ")
+	action.Metadata["files"] = len(r.Files)
+	return nil
+}
+
+// renameEdit asks the language server for the edits of the rename at the
+// first of positions it renames. Without a line the name may be of more
+// than one symbol, such as locals of two functions, so every occurrence the
+// edits leave alone is tried too, and a second symbol is an error rather
+// than a guess.
+func renameEdit(ctx context.Context, client *lspClient, path string, r *SymbolRename, positions []lspPosition) (*lspWorkspaceEdit, error) {
+	var edit *lspWorkspaceEdit
+	var renamed []lspTextEdit // the edits of the file at path
+	var err error
+	tries := 0
+	for _, pos := range positions {
+		if edit != nil && (r.Line > 0 || editsPosition(renamed, pos)) {
+			continue
+		}
+		if edit == nil && tries == maxRenameCandidates {
+			break
+		}
+		tries++
+		e, renameErr := client.rename(ctx, path, pos, r.NewName)
+		if renameErr != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("rename %s: %w", r.Symbol, renameErr)
+			}
+			if edit == nil {
+				err = renameErr
+			}
+			continue
+		}
+		if edit != nil {
+			return nil, fmt.Errorf("%s names more than one symbol in %s; give the line of the one to rename", r.Symbol, path)
+		}
+		files, fileErr := e.fileEdits()
+		if fileErr != nil {
+			return nil, fileErr
+		}
+		edit, renamed = e, fileEditsOf(files, path)
+	}
+	if edit == nil {
+		return nil, fmt.Errorf("rename %s: %w", r.Symbol, err)
+	}
+	return edit, nil
+}
+
+// editsPosition reports whether one of edits replaces the text at pos.
+func editsPosition(edits []lspTextEdit, pos lspPosition) bool {
+	for _, e := range edits {
+		start, end := e.Range.Start, e.Range.End
+		if pos.Line == start.Line && pos.Line == end.Line && start.Character <= pos.Character && pos.Character < end.Character {
+			return true
+		}
+	}
+	return false
+}
+
+// fileEditsOf returns the edits of the file at path among files, which the
+// language server may name by another path to it.
+func fileEditsOf(files map[string][]lspTextEdit, path string) []lspTextEdit {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	for name, edits := range files {
+		if other, err := os.Stat(name); err == nil && os.SameFile(info, other) {
+			return edits
+		}
+	}
+	return nil
+}
+
+// checkRenameTarget returns an error when the scope refuses an edit of a
+// rename, the policy blocks it, or the user declines to confirm it. An edit
+// confirmed here is not asked about again when it runs.
+func (a *Agent) checkRenameTarget(e *Action) error {
+	a.mu.Lock()
+	scope := a.scope
+	a.mu.Unlock()
+	if scope != nil {
+		if err := scope.check(e); err != nil {
+			return err
+		}
+	} else if err := validatePath(e.Path); err != nil {
+		return err
+	}
+	return a.checkPolicy(e)
+}
+
+// symbolPositions returns the positions of the occurrences of symbol in
+// text as a whole word, on line (1-based) when it is set.
+func symbolPositions(text, symbol string, line int) []lspPosition {
+	var positions []lspPosition
+	for i, l := range strings.Split(text, "\n") {
+		if line > 0 && i+1 != line {
+			continue
+		}
+		for col := 0; ; col += len(symbol) {
+			j := strings.Index(l[col:], symbol)
+			if j < 0 {
+				break
+			}
+			col += j
+			before, _ := utf8.DecodeLastRuneInString(l[:col])
+			after, _ := utf8.DecodeRuneInString(l[col+len(symbol):])
+			if isIdentRune(before) || isIdentRune(after) {
+				continue
+			}
+			positions = append(positions, lspPosition{Line: i, Character: lspCharacter(l, col)})
+		}
+	}
+	return positions
+}
+
+// isIdentRune reports whether r may be part of an identifier.
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// workingPath returns path relative to the working directory when it is
+// below it.
+func workingPath(path string) string {
+	if cwd, err := os.Getwd(); err == nil && within(cwd, path) {
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			return rel
+		}
+	}
+	return path
+}
+
+// languageID returns the LSP language identifier of a file.
+func languageID(tc Toolchain, path string) string {
+	switch filepath.Ext(path) {
+	case ".tsx":
+		return "typescriptreact"
+	case ".jsx":
+		return "javascriptreact"
+	}
+	return tc.Name
+}
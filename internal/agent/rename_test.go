@@ -0,0 +1,316 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/model"
+)
+
+// TestMain lets the test binary act as a language server for the rename
+// tests when OBOT_FAKE_LSP is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("OBOT_FAKE_LSP") != "" {
+		fakeLanguageServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeLanguageServer answers renames by replacing the identifier at the
+// position as a whole word in every .go file of the workspace, or only on
+// its line when that is marked "// local", and
+// references with every occurrence of it; hovers show the identifier's
+// line. Before a rename it asks the client for its configuration, as gopls
+// does. It reports each line of a document containing "undefined" as an
+// error.
+func fakeLanguageServer() {
+	in := bufio.NewReader(os.Stdin)
+	send := func(msg map[string]any) {
+		data, _ := json.Marshal(msg)
+		fmt.Printf("Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+	var root, opened string
+	for {
+		var length int
+		for {
+			line, err := in.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				break
+			}
+			fmt.Sscanf(line, "Content-Length: %d", &length)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(in, data); err != nil {
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				RootURI      string `json:"rootUri"`
+				TextDocument struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"textDocument"`
+				ContentChanges []struct {
+					Text string `json:"text"`
+				} `json:"contentChanges"`
+				Position lspPosition `json:"position"`
+				NewName  string      `json:"newName"`
+			} `json:"params"`
+		}
+		json.Unmarshal(data, &msg)
+		switch msg.Method {
+		case "initialize":
+			root, _ = uriPath(msg.Params.RootURI)
+			send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]any{"capabilities": map[string]any{"renameProvider": true}}})
+		case "textDocument/didOpen", "textDocument/didChange":
+			opened = msg.Params.TextDocument.Text
+			if len(msg.Params.ContentChanges) > 0 {
+				opened = msg.Params.ContentChanges[0].Text
+			}
+			send(map[string]any{"jsonrpc": "2.0", "method": "window/logMessage", "params": map[string]any{"type": 3, "message": "opened"}})
+			var diags []lspDiagnostic
+			for i, line := range strings.Split(opened, "\n") {
+				if col := strings.Index(line, "undefined"); col >= 0 {
+					diags = append(diags, lspDiagnostic{Range: lspRange{Start: lspPosition{i, col}}, Severity: 1, Message: "undefined: x"})
+				}
+			}
+			send(map[string]any{"jsonrpc": "2.0", "method": "textDocument/publishDiagnostics", "params": map[string]any{"uri": msg.Params.TextDocument.URI, "diagnostics": diags}})
+		case "textDocument/rename", "textDocument/references", "textDocument/hover":
+			if msg.Method == "textDocument/rename" {
+				send(map[string]any{"jsonrpc": "2.0", "id": 99, "method": "workspace/configuration", "params": map[string]any{"items": []any{map[string]any{}}}})
+			}
+			line := strings.Split(opened, "\n")[msg.Params.Position.Line]
+			start := msg.Params.Position.Character
+			end := start
+			for end < len(line) && isIdentRune(rune(line[end])) {
+				end++
+			}
+			old := line[start:end]
+			if strings.HasPrefix(strings.TrimSpace(line), "//") {
+				send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "error": map[string]any{"code": 0, "message": "no identifier found"}})
+				continue
+			}
+			if msg.Method == "textDocument/hover" {
+				send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]any{"contents": map[string]string{"kind": "markdown", "value": line}}})
+				continue
+			}
+			// A name on a line marked "// local" is another symbol, local
+			// to that line
+			local := strings.Contains(line, "// local")
+			changes := map[string][]lspTextEdit{}
+			var locations []lspLocation
+			files, _ := filepath.Glob(filepath.Join(root, "*.go"))
+			for _, f := range files {
+				content, _ := os.ReadFile(f)
+				lines := strings.Split(string(content), "\n")
+				for _, pos := range symbolPositions(string(content), old, 0) {
+					if strings.Contains(lines[pos.Line], "// local") != local ||
+						local && (fileURI(f) != msg.Params.TextDocument.URI || pos.Line != msg.Params.Position.Line) {
+						continue
+					}
+					r := lspRange{Start: pos, End: lspPosition{Line: pos.Line, Character: pos.Character + len(old)}}
+					changes[fileURI(f)] = append(changes[fileURI(f)], lspTextEdit{Range: r, NewText: msg.Params.NewName})
+					locations = append(locations, lspLocation{URI: fileURI(f), Range: r})
+				}
+			}
+			if msg.Method == "textDocument/references" {
+				send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": locations})
+				continue
+			}
+			send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": map[string]any{"changes": changes}})
+		case "shutdown":
+			send(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": nil})
+		case "exit":
+			return
+		}
+	}
+}
+
+// renameWorkspace writes files into a temporary directory, makes it the
+// working directory, and returns an agent whose Go toolchain uses the fake
+// language server.
+func renameWorkspace(t *testing.T, files map[string]string) *Agent {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	t.Chdir(dir)
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	a.SetToolchains([]Toolchain{{Name: "go", LanguageServer: "OBOT_FAKE_LSP=1 " + exe}})
+	t.Cleanup(a.StopLanguageServers)
+	return a
+}
+
+func TestRenameSymbol(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{
+		"a.go": "package p\n\n// total sums xs.\nfunc total(xs []int) int { return 0 }\n",
+		"b.go": "package p\n\nvar subtotal, t = total(nil), total(nil)\n",
+		"c.go": "package p\n",
+	})
+
+	files, err := a.RenameSymbol(context.Background(), "a.go", "total", "sum")
+	if err != nil {
+		t.Fatalf("RenameSymbol() error = %v", err)
+	}
+	if strings.Join(files, ",") != "a.go,b.go" {
+		t.Errorf("files = %v, want [a.go b.go]", files)
+	}
+	b, _ := os.ReadFile("b.go")
+	if string(b) != "package p\n\nvar subtotal, t = sum(nil), sum(nil)\n" {
+		t.Errorf("b.go = %q", b)
+	}
+
+	actions := a.GetActions()
+	if len(actions) != 3 {
+		t.Fatalf("recorded %d actions, want 2 edits and the rename", len(actions))
+	}
+	rename := actions[2]
+	if rename.Type != ActionRenameSymbol || actions[0].Type != ActionEditFile || actions[0].Metadata["symbol_rename"] != rename.ID {
+		t.Errorf("actions = %s, %s, %s; want edits linked to the rename", actions[0].ID, actions[1].ID, rename.ID)
+	}
+	if got := rename.ActionOutput(); got != "Agent • Renamed total to sum (2 files)" {
+		t.Errorf("ActionOutput() = %q", got)
+	}
+}
+
+func TestRenameSymbol_BlockedFileChangesNothing(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{
+		"a.go":      "package p\n\nfunc total() int { return 0 }\n",
+		"z_mock.go": "// Code generated by MockGen. DO NOT EDIT.\npackage p\n\nvar _ = total\n",
+	})
+	a.SetPolicy(DefaultPolicy(), nil)
+
+	_, err := a.RenameSymbol(context.Background(), "a.go", "total", "sum")
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || policyErr.Check.Action.Path != "z_mock.go" {
+		t.Fatalf("RenameSymbol() error = %v, want the policy blocking z_mock.go", err)
+	}
+	if a, _ := os.ReadFile("a.go"); !strings.Contains(string(a), "func total()") {
+		t.Error("a.go was renamed although the rename was refused")
+	}
+}
+
+func TestRenameSymbol_DeclinedChangesNothing(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{
+		"a.go": "package p\n\nfunc total() int { return 0 }\n",
+		"b.go": "package p\n\nvar _ = total\n",
+	})
+	policy, err := LoadPolicyWithRules(".", []PolicyRule{{Paths: []string{"b.go"}, Decision: PolicyConfirm}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	asked := 0
+	answer := false
+	a.SetPolicy(policy, func(PolicyCheck) bool { asked++; return answer })
+
+	_, err = a.RenameSymbol(context.Background(), "a.go", "total", "sum")
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || !policyErr.Declined {
+		t.Fatalf("RenameSymbol() error = %v, want b.go declined", err)
+	}
+	if a, _ := os.ReadFile("a.go"); !strings.Contains(string(a), "func total()") {
+		t.Error("a.go was renamed although the edit of b.go was declined")
+	}
+
+	asked, answer = 0, true
+	if _, err := a.RenameSymbol(context.Background(), "a.go", "total", "sum"); err != nil {
+		t.Fatalf("RenameSymbol() error = %v", err)
+	}
+	if asked != 1 {
+		t.Errorf("asked %d times to confirm the edit of b.go, want once", asked)
+	}
+}
+
+func TestRenameSymbol_Ambiguous(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{
+		"a.go": "package p\n\nfunc total() int { return 0 }\n\nfunc f() { total := 1; _ = total } // local\n",
+	})
+	ctx := context.Background()
+
+	if _, err := a.RenameSymbol(ctx, "a.go", "total", "sum"); err == nil || !strings.Contains(err.Error(), "more than one symbol") {
+		t.Fatalf("RenameSymbol() error = %v, want the name found ambiguous", err)
+	}
+	action := Action{Type: ActionRenameSymbol, Path: "a.go", Rename: &SymbolRename{Symbol: "total", NewName: "n", Line: 5}}
+	if err := a.executeAction(ctx, &action); err != nil {
+		t.Fatalf("rename on line 5: %v", err)
+	}
+	want := "package p\n\nfunc total() int { return 0 }\n\nfunc f() { n := 1; _ = n } // local\n"
+	if data, _ := os.ReadFile("a.go"); string(data) != want {
+		t.Errorf("a.go = %q, want only the local renamed", data)
+	}
+}
+
+func TestRenameSymbol_Errors(t *testing.T) {
+	a := renameWorkspace(t, map[string]string{"a.go": "package p\n\nfunc total() {}\n", "a.txt": "total\n"})
+	ctx := context.Background()
+	for _, tt := range []struct{ path, symbol, newName, want string }{
+		{"a.go", "total", "not valid", "not an identifier"},
+		{"a.go", "missing", "sum", "does not appear"},
+		{"a.txt", "total", "sum", "no language server"},
+	} {
+		if _, err := a.RenameSymbol(ctx, tt.path, tt.symbol, tt.newName); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("RenameSymbol(%s, %s, %s) error = %v, want %q", tt.path, tt.symbol, tt.newName, err, tt.want)
+		}
+	}
+}
+
+func TestApplyTextEdits_UTF16(t *testing.T) {
+	text := "s := \"é😀\"; x := x + 1\nx\n"
+	edits := []lspTextEdit{
+		{Range: lspRange{Start: lspPosition{0, 12}, End: lspPosition{0, 13}}, NewText: "y"},
+		{Range: lspRange{Start: lspPosition{0, 17}, End: lspPosition{0, 18}}, NewText: "y"},
+		{Range: lspRange{Start: lspPosition{1, 0}, End: lspPosition{1, 1}}, NewText: "y"},
+	}
+	got, err := applyTextEdits(text, edits)
+	if err != nil {
+		t.Fatalf("applyTextEdits() error = %v", err)
+	}
+	if want := "s := \"é😀\"; y := y + 1\ny\n"; got != want {
+		t.Errorf("applyTextEdits() = %q, want %q", got, want)
+	}
+	if _, err := applyTextEdits(text, []lspTextEdit{{Range: lspRange{Start: lspPosition{5, 0}, End: lspPosition{5, 0}}}}); err == nil {
+		t.Error("applyTextEdits() accepted a line out of range")
+	}
+}
+
+func TestRenameSymbol_Gopls(t *testing.T) {
+	if _, err := exec.LookPath("gopls"); err != nil {
+		t.Skip("gopls not installed")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/p\n\ngo 1.21\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package p\n\nfunc Total() int { return 0 }\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package p\n\nvar x = Total()\n"), 0644)
+	t.Chdir(dir)
+
+	a := NewAgent(model.NewCoordinator(nil))
+	a.executing = true
+	if _, err := a.RenameSymbol(context.Background(), "a.go", "Total", "Sum"); err != nil {
+		t.Fatalf("RenameSymbol() error = %v", err)
+	}
+	if b, _ := os.ReadFile("b.go"); !strings.Contains(string(b), "Sum()") {
+		t.Errorf("b.go = %q, want the reference renamed", b)
+	}
+}
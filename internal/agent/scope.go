@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// Scope confines an agent working on one project of a monorepo: it may
+// read anywhere in the repository, but writes files and runs toolchains
+// and commands only within the project. Paths are checked with their
+// symbolic links resolved, so that a link cannot lead out of the project.
+type Scope struct {
+	Root    string // the repository, absolute and resolved
+	Project string // the project's directory, absolute, resolved, and within Root
+}
+
+// NewScope returns the scope of the project in the subdirectory project of
+// the repository at root.
+func NewScope(root, project string) (*Scope, error) {
+	root, err := resolve(root)
+	if err != nil {
+		return nil, err
+	}
+	dir := project
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	if dir, err = resolve(dir); err != nil {
+		return nil, err
+	}
+	if !within(root, dir) || dir == root {
+		return nil, fmt.Errorf("project %s is not a subdirectory of %s", project, root)
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("project %s is not a directory", project)
+	}
+	return &Scope{Root: root, Project: dir}, nil
+}
+
+// Name returns the project's slash-separated path in the repository.
+func (s *Scope) Name() string {
+	rel, _ := filepath.Rel(s.Root, s.Project)
+	return filepath.ToSlash(rel)
+}
+
+// SetScope confines the agent to a project of a monorepo; nil lifts the
+// confinement. Paths are resolved against the working directory, which
+// should be the project's.
+func (a *Agent) SetScope(s *Scope) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scope = s
+}
+
+// check returns an error when action reads outside the repository, or
+// writes, runs a toolchain, or runs a command outside the project. A
+// command without a directory is given the project's.
+func (s *Scope) check(action *Action) error {
+	switch action.Type {
+	case ActionRunCommand, ActionStartBackground:
+		if action.Dir == "" {
+			action.Dir = s.Project
+		}
+		return s.checkWrite(action.Dir)
+	case ActionDockerBuild:
+		if action.Docker == nil {
+			return nil
+		}
+		buildCtx := action.Docker.Context
+		if buildCtx == "" {
+			buildCtx = "."
+		}
+		return s.checkRead(buildCtx)
+	case ActionDockerRun:
+		// The mount is writable from the container
+		if action.Docker == nil || action.Docker.Mount == "" {
+			return nil
+		}
+		return s.checkWrite(action.Docker.Mount)
+	case ActionReadFile, ActionListDir, ActionDiagnostics, ActionHover, ActionReferences:
+		return s.checkRead(action.Path)
+	case ActionSearchFiles:
+		if action.Path == "" {
+			return nil
+		}
+		return s.checkRead(action.Path)
+	case ActionCopyFile, ActionCopyDir:
+		if err := s.checkRead(action.Path); err != nil {
+			return err
+		}
+		return s.checkWrite(action.NewPath)
+	case ActionRenameFile, ActionMoveFile, ActionRenameDir, ActionMoveDir:
+		if err := s.checkWrite(action.Path); err != nil {
+			return err
+		}
+		return s.checkWrite(action.NewPath)
+	case ActionCreateFile, ActionDeleteFile, ActionEditFile, ActionCreateDir, ActionDeleteDir,
+		ActionRenameSymbol, ActionLint, ActionFormat, ActionTest:
+		return s.checkWrite(action.Path)
+	}
+	return nil
+}
+
+func (s *Scope) checkRead(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if abs, err := resolve(path); err != nil || !within(s.Root, abs) {
+		return fmt.Errorf("%s is outside the repository", path)
+	}
+	return nil
+}
+
+func (s *Scope) checkWrite(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if abs, err := resolve(path); err != nil || !within(s.Project, abs) {
+		return fmt.Errorf("%s is outside the project %s: other projects are read-only", path, s.Name())
+	}
+	return nil
+}
+
+// resolve returns the absolute form of path with its symbolic links
+// resolved. The part of path that does not exist yet, such as a file to be
+// created, is kept as it is; a link that cannot be resolved is an error.
+func resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rest := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(real, rest), nil
+		}
+		if _, err := os.Lstat(dir); err == nil {
+			return "", fmt.Errorf("cannot resolve %s", dir)
+		}
+		if filepath.Dir(dir) == dir {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
+
+// within reports whether path is dir or below it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// maxProjects bounds how many projects FindProjects lists.
+const maxProjects = 50
+
+// Project is a project of a monorepo, found by its toolchain's project file.
+type Project struct {
+	Path      string // slash-separated, relative to the repository; "." for its root
+	Toolchain string // e.g. "go"
+}
+
+// FindProjects lists the projects of the repository at root: the
+// directories holding a project file of one of toolchains, such as go.mod
+// or package.json, sorted by path.
+func FindProjects(root string, toolchains []Toolchain) []Project {
+	markers := make(map[string]string)
+	for _, tc := range toolchains {
+		for _, m := range tc.Markers {
+			if _, ok := markers[m]; !ok {
+				markers[m] = tc.Name
+			}
+		}
+	}
+	found := make(map[string]string)
+	_ = fswalk.Walk(root, fswalk.Options{}, func(e fswalk.Entry) error {
+		if len(found) >= maxProjects {
+			return filepath.SkipAll
+		}
+		name, ok := markers[e.Info.Name()]
+		if !ok {
+			return nil
+		}
+		dir := "."
+		if i := strings.LastIndex(e.Rel, "/"); i >= 0 {
+			dir = e.Rel[:i]
+		}
+		if _, seen := found[dir]; !seen {
+			found[dir] = name
+		}
+		return nil
+	})
+	projects := make([]Project, 0, len(found))
+	for dir, name := range found {
+		projects = append(projects, Project{Path: dir, Toolchain: name})
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+	return projects
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScope_Check(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"services/api", "libs/auth"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := NewScope(root, "services/api")
+	if err != nil {
+		t.Fatalf("NewScope() error = %v", err)
+	}
+	if s.Name() != "services/api" {
+		t.Errorf("Name() = %q", s.Name())
+	}
+	api, auth := filepath.Join(root, "services/api"), filepath.Join(root, "libs/auth")
+	// A link in the project to another project does not make it writable
+	if err := os.Symlink(auth, filepath.Join(api, "auth")); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := []*Action{
+		{Type: ActionEditFile, Path: filepath.Join(api, "main.go")},
+		{Type: ActionReadFile, Path: filepath.Join(auth, "token.go")},
+		{Type: ActionSearchFiles, Path: auth},
+		{Type: ActionCopyFile, Path: filepath.Join(auth, "token.go"), NewPath: filepath.Join(api, "token.go")},
+		{Type: ActionTest, Path: api},
+		{Type: ActionRunCommand, Command: "go test ./...", Dir: filepath.Join(api, "cmd")},
+		{Type: ActionDockerRun, Docker: &DockerSpec{Image: "golang", Mount: api}},
+		{Type: ActionReadFile, Path: filepath.Join(api, "auth", "token.go")},
+	}
+	for _, a := range allowed {
+		if err := s.check(a); err != nil {
+			t.Errorf("check(%s %s) = %v, want allowed", a.Type, a.Path, err)
+		}
+	}
+	refused := []*Action{
+		{Type: ActionEditFile, Path: filepath.Join(auth, "token.go")},
+		{Type: ActionReadFile, Path: filepath.Join(root, "..", "secrets")},
+		{Type: ActionMoveFile, Path: filepath.Join(api, "a.go"), NewPath: filepath.Join(auth, "a.go")},
+		{Type: ActionTest, Path: auth},
+		{Type: ActionEditFile, Path: filepath.Join(api, "auth", "token.go")},
+		{Type: ActionCreateFile, Path: filepath.Join(api, "auth", "new", "new.go")},
+		{Type: ActionRunCommand, Command: "make", Dir: auth},
+		{Type: ActionStartBackground, Command: "npm start", Dir: filepath.Join(api, "auth")},
+		{Type: ActionDockerRun, Docker: &DockerSpec{Image: "golang", Mount: root}},
+		{Type: ActionDockerBuild, Docker: &DockerSpec{Image: "x", Context: filepath.Join(root, "..")}},
+	}
+	for _, a := range refused {
+		if err := s.check(a); err == nil {
+			t.Errorf("check(%s %s -> %s) allowed, want refused", a.Type, a.Path, a.NewPath)
+		}
+	}
+
+	run := &Action{Type: ActionRunCommand, Command: "go build"}
+	if err := s.check(run); err != nil || run.Dir != s.Project {
+		t.Errorf("check(run_command) = %v with dir %q, want the project %s", err, run.Dir, s.Project)
+	}
+
+	if _, err := NewScope(root, "../elsewhere"); err == nil {
+		t.Error("NewScope accepted a project outside the repository")
+	}
+	if _, err := NewScope(root, "services/missing"); err == nil {
+		t.Error("NewScope accepted a missing project")
+	}
+}
+
+func TestFindProjects(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"go.mod", "services/api/go.mod", "web/package.json", "node_modules/x/package.json", "docs/README.md"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := FindProjects(root, DefaultToolchains())
+	want := []Project{{Path: ".", Toolchain: "go"}, {Path: "services/api", Toolchain: "go"}, {Path: "web", Toolchain: "javascript"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindProjects() = %+v, want %+v", got, want)
+	}
+}
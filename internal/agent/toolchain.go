@@ -0,0 +1,464 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Toolchain is a language's lint, format, and test commands. Commands are
+// shell templates where {path} is the target path and {dir} the target's
+// directory (with a leading "./" when relative), both shell-quoted. A
+// command naming neither runs in the directory holding the project file.
+type Toolchain struct {
+	Name       string
+	Extensions []string // file extensions, e.g. ".go"
+	Markers    []string // project files identifying the language, e.g. "go.mod"
+
+	Lint   string
+	Format string
+	Test   string
+	Check  string // fast syntax or compile check run after writes
+
+	// LanguageServer starts a Language Server Protocol server on stdin and
+	// stdout, used for renames, diagnostics, hovers, and references
+	LanguageServer string
+
+	// Parsers for the command output; empty leaves the output unparsed.
+	// LintParser may be "lines" (path:line[:col]: message). TestParser may
+	// be "go", "pytest", or "cargo".
+	LintParser string
+	TestParser string
+}
+
+// DefaultToolchains returns the built-in toolchains.
+func DefaultToolchains() []Toolchain {
+	return []Toolchain{
+		{
+			Name:       "go",
+			Extensions: []string{".go"},
+			Markers:    []string{"go.mod"},
+			Lint:       "go vet {dir}",
+			Format:     "gofmt -l -w {path}",
+			Test:       "go test -v {dir}",
+			Check:      "go build -o /dev/null {dir}",
+			LintParser: "lines",
+			TestParser: "go",
+
+			LanguageServer: "gopls serve",
+		},
+		{
+			Name:       "python",
+			Extensions: []string{".py"},
+			Markers:    []string{"pyproject.toml", "setup.py", "setup.cfg", "requirements.txt"},
+			Lint:       "ruff check --output-format concise {path}",
+			Format:     "ruff format {path}",
+			Test:       "pytest -rA {path}",
+			Check:      "python3 -m py_compile {path}",
+			LintParser: "lines",
+			TestParser: "pytest",
+
+			LanguageServer: "pyright-langserver --stdio",
+		},
+		{
+			Name:       "typescript",
+			Extensions: []string{".ts", ".tsx", ".mts", ".cts"},
+			Markers:    []string{"tsconfig.json"},
+			Lint:       "npx --no-install eslint --format unix {path}",
+			Format:     "npx --no-install prettier --write {path}",
+			Test:       "npm test -- {path}",
+			Check:      "npx --no-install tsc --noEmit --pretty false",
+			LintParser: "lines",
+
+			LanguageServer: "npx --no-install typescript-language-server --stdio",
+		},
+		{
+			Name:       "javascript",
+			Extensions: []string{".js", ".jsx", ".mjs", ".cjs"},
+			Markers:    []string{"package.json"},
+			Lint:       "npx --no-install eslint --format unix {path}",
+			Format:     "npx --no-install prettier --write {path}",
+			Test:       "npm test -- {path}",
+			Check:      "node --check {path}",
+			LintParser: "lines",
+
+			LanguageServer: "npx --no-install typescript-language-server --stdio",
+		},
+		{
+			Name:       "rust",
+			Extensions: []string{".rs"},
+			Markers:    []string{"Cargo.toml"},
+			Lint:       "cargo clippy --message-format short",
+			Format:     "cargo fmt",
+			Test:       "cargo test",
+			Check:      "cargo check --quiet --message-format short",
+			LintParser: "lines",
+			TestParser: "cargo",
+		},
+		{
+			Name:       "java",
+			Extensions: []string{".java"},
+			Markers:    []string{"pom.xml", "build.gradle", "build.gradle.kts"},
+			Lint:       "mvn -q -B checkstyle:check",
+			Format:     "google-java-format --replace {path}",
+			Test:       "mvn -q -B test",
+			Check:      "mvn -q -B -o compile",
+			LintParser: "lines",
+		},
+	}
+}
+
+// Diagnostic is a problem reported by a linter.
+type Diagnostic struct {
+	Path     string
+	Line     int
+	Column   int // 0 when not reported
+	Severity string
+	Message  string
+}
+
+// Test outcomes
+const (
+	TestPassed  = "pass"
+	TestFailed  = "fail"
+	TestSkipped = "skip"
+)
+
+// TestCase is the outcome of one test.
+type TestCase struct {
+	Name     string
+	Status   string // TestPassed, TestFailed, or TestSkipped
+	Duration time.Duration
+}
+
+// ToolchainResult is the parsed output of a lint, format, or test command.
+type ToolchainResult struct {
+	Toolchain   string
+	Diagnostics []Diagnostic
+	Tests       []TestCase
+}
+
+// Failed returns the failed tests.
+func (r *ToolchainResult) Failed() []TestCase {
+	var failed []TestCase
+	for _, t := range r.Tests {
+		if t.Status == TestFailed {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// SetToolchains registers custom toolchains. One named like a built-in
+// toolchain overrides the fields it sets; others take precedence over the
+// built-ins during detection.
+func (a *Agent) SetToolchains(custom []Toolchain) {
+	toolchains := DefaultToolchains()
+	var added []Toolchain
+	for _, c := range custom {
+		i := toolchainIndex(toolchains, c.Name)
+		if i < 0 {
+			added = append(added, c)
+			continue
+		}
+		t := &toolchains[i]
+		for _, f := range []struct{ dst, src *string }{
+			{&t.Lint, &c.Lint}, {&t.Format, &c.Format}, {&t.Test, &c.Test}, {&t.Check, &c.Check},
+			{&t.LintParser, &c.LintParser}, {&t.TestParser, &c.TestParser}, {&t.LanguageServer, &c.LanguageServer},
+		} {
+			if *f.src != "" {
+				*f.dst = *f.src
+			}
+		}
+		if len(c.Extensions) > 0 {
+			t.Extensions = c.Extensions
+		}
+		if len(c.Markers) > 0 {
+			t.Markers = c.Markers
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.toolchains = append(added, toolchains...)
+}
+
+// Lint runs the linter of the toolchain detected for path.
+func (a *Agent) Lint(ctx context.Context, path string) (*ToolchainResult, error) {
+	action := Action{Type: ActionLint, Path: path}
+	err := a.executeAction(ctx, &action)
+	return action.ToolResult, err
+}
+
+// Format runs the formatter of the toolchain detected for path.
+func (a *Agent) Format(ctx context.Context, path string) error {
+	action := Action{Type: ActionFormat, Path: path}
+	return a.executeAction(ctx, &action)
+}
+
+// RunTests runs the tests of the toolchain detected for path.
+func (a *Agent) RunTests(ctx context.Context, path string) (*ToolchainResult, error) {
+	action := Action{Type: ActionTest, Path: path}
+	err := a.executeAction(ctx, &action)
+	return action.ToolResult, err
+}
+
+// runToolchain runs the action's lint, format, or test command and parses
+// its output, which is kept even when the command fails.
+func (a *Agent) runToolchain(ctx context.Context, action *Action) error {
+	tc, ok := a.detectToolchain(action.Path)
+	if !ok {
+		return fmt.Errorf("no toolchain found for %s", action.Path)
+	}
+	var template, parser string
+	switch action.Type {
+	case ActionLint:
+		template, parser = tc.Lint, tc.LintParser
+	case ActionFormat:
+		template = tc.Format
+	case ActionTest:
+		template, parser = tc.Test, tc.TestParser
+	}
+	if template == "" {
+		return fmt.Errorf("toolchain %s has no %s command", tc.Name, action.Type)
+	}
+	action.Command = expandToolchainCommand(template, action.Path)
+	action.Dir = toolchainDir(tc, template, action.Path)
+	action.Metadata["toolchain"] = tc.Name
+
+	err := a.handleRunCommand(ctx, action)
+	result := &ToolchainResult{Toolchain: tc.Name}
+	switch parser {
+	case "lines":
+		result.Diagnostics = parseDiagnostics(action.Output)
+	case "go":
+		result.Tests = parseGoTests(action.Stdout)
+	case "pytest":
+		result.Tests = parsePytest(action.Stdout)
+	case "cargo":
+		result.Tests = parseCargoTests(action.Stdout)
+	}
+	action.ToolResult = result
+	if action.Type == ActionLint {
+		action.Metadata["diagnostics"] = len(result.Diagnostics)
+	}
+	if action.Type == ActionTest && len(result.Tests) > 0 {
+		action.Metadata["tests"] = len(result.Tests)
+		action.Metadata["tests_failed"] = len(result.Failed())
+	}
+	return err
+}
+
+// Toolchains returns the built-in and custom toolchains in detection order.
+func (a *Agent) Toolchains() []Toolchain {
+	return a.registeredToolchains()
+}
+
+// registeredToolchains returns the toolchains in detection order.
+func (a *Agent) registeredToolchains() []Toolchain {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.toolchains == nil {
+		return DefaultToolchains()
+	}
+	return a.toolchains
+}
+
+// fileToolchain finds the toolchain for a source file by its extension.
+func (a *Agent) fileToolchain(path string) (Toolchain, bool) {
+	ext := filepath.Ext(path)
+	for _, tc := range a.registeredToolchains() {
+		for _, e := range tc.Extensions {
+			if strings.EqualFold(e, ext) {
+				return tc, true
+			}
+		}
+	}
+	return Toolchain{}, false
+}
+
+// detectToolchain finds the toolchain for path: by file extension, then by
+// the nearest directory holding one of a toolchain's project files.
+func (a *Agent) detectToolchain(path string) (Toolchain, bool) {
+	info, err := os.Stat(path)
+	isDir := err == nil && info.IsDir()
+	if !isDir {
+		if tc, ok := a.fileToolchain(path); ok {
+			return tc, true
+		}
+	}
+	toolchains := a.registeredToolchains()
+
+	dir := path
+	if !isDir {
+		dir = filepath.Dir(path)
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return Toolchain{}, false
+	}
+	for {
+		for _, tc := range toolchains {
+			for _, m := range tc.Markers {
+				if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+					return tc, true
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Toolchain{}, false
+		}
+		dir = parent
+	}
+}
+
+// toolchainDir returns the directory to run a command template for path in.
+// Commands naming no {path} or {dir}, like cargo and mvn, act on the project
+// in their working directory, so they run in the nearest directory holding
+// one of the toolchain's project files. Empty runs in the current directory.
+func toolchainDir(tc Toolchain, template, path string) string {
+	if strings.Contains(template, "{path}") || strings.Contains(template, "{dir}") {
+		return ""
+	}
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, m := range tc.Markers {
+			if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// toolchainIndex returns the index of the toolchain name, or -1.
+func toolchainIndex(toolchains []Toolchain, name string) int {
+	for i, tc := range toolchains {
+		if tc.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandToolchainCommand fills in a command template for path.
+func expandToolchainCommand(template, path string) string {
+	if path == "" {
+		path = "."
+	}
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	if !filepath.IsAbs(dir) && dir != "." && !strings.HasPrefix(dir, "./") && !strings.HasPrefix(dir, "../") {
+		dir = "./" + dir
+	}
+	return strings.NewReplacer("{path}", shellQuote(path), "{dir}", shellQuote(dir)).Replace(template)
+}
+
+// shellQuote quotes s for sh when it contains special characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:@+=,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// diagnosticRe matches "path:line[:col]: [severity:] message" lines,
+// optionally prefixed with a bracketed level as checkstyle prints them.
+var diagnosticRe = regexp.MustCompile(`^(?:\[(\w+)\]\s+)?([^\s:][^:]*):(\d+):(?:(\d+):)?\s*(?:(error|warning|note|info|help)(?:\[\w+\])?:\s*)?(.+)$`)
+
+// parseDiagnostics parses linter output in the common path:line:col format.
+func parseDiagnostics(out string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(out, "\n") {
+		m := diagnosticRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{Path: m[2], Message: strings.TrimSpace(m[6])}
+		d.Line, _ = strconv.Atoi(m[3])
+		d.Column, _ = strconv.Atoi(m[4])
+		d.Severity = strings.ToLower(m[5])
+		if d.Severity == "" {
+			d.Severity = strings.ToLower(m[1])
+		}
+		if d.Severity == "" {
+			d.Severity = "warning"
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// goTestRe matches go test -v result lines.
+var goTestRe = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+) \(([\d.]+)s\)`)
+
+// parseGoTests parses go test -v output.
+func parseGoTests(out string) []TestCase {
+	var tests []TestCase
+	for _, line := range strings.Split(out, "\n") {
+		m := goTestRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		secs, _ := strconv.ParseFloat(m[3], 64)
+		tests = append(tests, TestCase{
+			Name:     m[2],
+			Status:   strings.ToLower(m[1]),
+			Duration: time.Duration(secs * float64(time.Second)),
+		})
+	}
+	return tests
+}
+
+// pytestRe matches the pytest -rA short test summary lines.
+var pytestRe = regexp.MustCompile(`^(PASSED|FAILED|ERROR|SKIPPED|XFAIL|XPASS) (\S+)`)
+
+// parsePytest parses pytest -rA output.
+func parsePytest(out string) []TestCase {
+	statuses := map[string]string{
+		"PASSED": TestPassed, "XPASS": TestPassed,
+		"FAILED": TestFailed, "ERROR": TestFailed,
+		"SKIPPED": TestSkipped, "XFAIL": TestSkipped,
+	}
+	var tests []TestCase
+	for _, line := range strings.Split(out, "\n") {
+		if m := pytestRe.FindStringSubmatch(line); m != nil {
+			tests = append(tests, TestCase{Name: m[2], Status: statuses[m[1]]})
+		}
+	}
+	return tests
+}
+
+// cargoTestRe matches cargo test result lines.
+var cargoTestRe = regexp.MustCompile(`^test (\S+) \.\.\. (ok|FAILED|ignored)`)
+
+// parseCargoTests parses cargo test output.
+func parseCargoTests(out string) []TestCase {
+	statuses := map[string]string{"ok": TestPassed, "FAILED": TestFailed, "ignored": TestSkipped}
+	var tests []TestCase
+	for _, line := range strings.Split(out, "\n") {
+		if m := cargoTestRe.FindStringSubmatch(line); m != nil {
+			tests = append(tests, TestCase{Name: m[1], Status: statuses[m[2]]})
+		}
+	}
+	return tests
+}
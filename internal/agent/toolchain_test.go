@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetectToolchain(t *testing.T) {
+	a := newCommandAgent()
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "Cargo.toml"), nil, 0644)
+	os.MkdirAll(filepath.Join(root, "src", "bin"), 0755)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "go"},
+		{"app/view.TSX", "typescript"},
+		{"app/index.js", "javascript"},
+		{filepath.Join(root, "src", "bin"), "rust"},
+		{filepath.Join(root, "src", "build.sh"), "rust"},
+	}
+	for _, tt := range tests {
+		tc, ok := a.detectToolchain(tt.path)
+		if !ok || tc.Name != tt.want {
+			t.Errorf("detectToolchain(%q) = %q, %v; want %q", tt.path, tc.Name, ok, tt.want)
+		}
+	}
+	if tc, ok := a.detectToolchain(filepath.Join(t.TempDir(), "notes.txt")); ok {
+		t.Errorf("detectToolchain(notes.txt) = %q, want none", tc.Name)
+	}
+}
+
+func TestToolchainDir(t *testing.T) {
+	root := t.TempDir()
+	crate := filepath.Join(root, "crates", "core")
+	os.MkdirAll(filepath.Join(crate, "src"), 0755)
+	os.WriteFile(filepath.Join(crate, "Cargo.toml"), nil, 0644)
+	rust := DefaultToolchains()[toolchainIndex(DefaultToolchains(), "rust")]
+
+	if dir := toolchainDir(rust, rust.Test, filepath.Join(crate, "src", "lib.rs")); dir != crate {
+		t.Errorf("toolchainDir(cargo test) = %q, want %q", dir, crate)
+	}
+	if dir := toolchainDir(rust, rust.Test, filepath.Join(root, "notes.rs")); dir != "" {
+		t.Errorf("toolchainDir() outside a crate = %q, want none", dir)
+	}
+	if dir := toolchainDir(rust, "rustfmt {path}", filepath.Join(crate, "src", "lib.rs")); dir != "" {
+		t.Errorf("toolchainDir() for a command naming its path = %q, want none", dir)
+	}
+}
+
+func TestSetToolchains(t *testing.T) {
+	a := newCommandAgent()
+	a.SetToolchains([]Toolchain{
+		{Name: "go", Lint: "golangci-lint run {dir}"},
+		{Name: "zig", Extensions: []string{".zig"}, Test: "zig build test"},
+	})
+
+	tc, _ := a.detectToolchain("pkg/x.go")
+	if tc.Lint != "golangci-lint run {dir}" || tc.Test != "go test -v {dir}" || tc.TestParser != "go" {
+		t.Errorf("overridden go toolchain = %+v", tc)
+	}
+	if tc, ok := a.detectToolchain("build.zig"); !ok || tc.Name != "zig" {
+		t.Errorf("custom toolchain not detected, got %q", tc.Name)
+	}
+}
+
+func TestExpandToolchainCommand(t *testing.T) {
+	tests := []struct {
+		template, path, want string
+	}{
+		{"go vet {dir}", "internal/agent/agent.go", "go vet ./internal/agent"},
+		{"go vet {dir}", "main.go", "go vet ."},
+		{"black {path}", "my file.py", "black 'my file.py'"},
+		{"cargo test", "src/lib.rs", "cargo test"},
+	}
+	for _, tt := range tests {
+		if got := expandToolchainCommand(tt.template, tt.path); got != tt.want {
+			t.Errorf("expandToolchainCommand(%q, %q) = %q, want %q", tt.template, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	out := `# github.com/x/y
+./main.go:12:5: unreachable code
+src/main.rs:2:9: warning: unused variable: ` + "`x`" + `
+app.py:3: E501 line too long
+[ERROR] /src/App.java:10:5: Missing javadoc. [JavadocMethod]
+FAIL`
+	want := []Diagnostic{
+		{Path: "./main.go", Line: 12, Column: 5, Severity: "warning", Message: "unreachable code"},
+		{Path: "src/main.rs", Line: 2, Column: 9, Severity: "warning", Message: "unused variable: `x`"},
+		{Path: "app.py", Line: 3, Severity: "warning", Message: "E501 line too long"},
+		{Path: "/src/App.java", Line: 10, Column: 5, Severity: "error", Message: "Missing javadoc. [JavadocMethod]"},
+	}
+	if got := parseDiagnostics(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDiagnostics() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTests(t *testing.T) {
+	goOut := "=== RUN   TestA\n--- PASS: TestA (0.50s)\n=== RUN   TestB\n--- FAIL: TestB (0.00s)\n    --- SKIP: TestB/sub (0.00s)\n"
+	want := []TestCase{
+		{Name: "TestA", Status: TestPassed, Duration: 500 * time.Millisecond},
+		{Name: "TestB", Status: TestFailed},
+		{Name: "TestB/sub", Status: TestSkipped},
+	}
+	if got := parseGoTests(goOut); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGoTests() = %+v", got)
+	}
+
+	pyOut := "==== short test summary info ====\nPASSED tests/test_a.py::test_ok\nFAILED tests/test_a.py::test_bad - assert 1 == 2\n"
+	if got := parsePytest(pyOut); len(got) != 2 || got[1].Name != "tests/test_a.py::test_bad" || got[1].Status != TestFailed {
+		t.Errorf("parsePytest() = %+v", got)
+	}
+
+	cargoOut := "running 2 tests\ntest tests::it_works ... ok\ntest tests::slow ... ignored\n"
+	if got := parseCargoTests(cargoOut); len(got) != 2 || got[1].Status != TestSkipped {
+		t.Errorf("parseCargoTests() = %+v", got)
+	}
+}
+
+func TestRunTests_Parsed(t *testing.T) {
+	a := newCommandAgent()
+	a.SetToolchains([]Toolchain{{
+		Name:       "fake",
+		Extensions: []string{".fake"},
+		Test:       "printf -- '--- PASS: TestOK (0.00s)\\n--- FAIL: TestBad (0.01s)\\n'; exit 1",
+		TestParser: "go",
+	}})
+
+	result, err := a.RunTests(context.Background(), "suite.fake")
+	if err == nil {
+		t.Fatal("expected error for failing tests")
+	}
+	if result == nil || result.Toolchain != "fake" || len(result.Tests) != 2 {
+		t.Fatalf("RunTests() result = %+v", result)
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Name != "TestBad" {
+		t.Errorf("Failed() = %+v", failed)
+	}
+}
@@ -35,28 +35,29 @@ func (a *Agent) DelegateTask(ctx context.Context, req DelegationRequest) (*Deleg
 	}
 
 	// Determine model client based on role
-	var client *ollama.Client
+	var modelType orchestrate.ModelType
 	var systemPrompt string
 
 	role := strings.ToLower(req.Role)
 	switch role {
 	case "coder":
-		client = a.models.Get(orchestrate.ModelCoder)
+		modelType = orchestrate.ModelCoder
 		systemPrompt = "You are a coding specialist. Produce correct, minimal code changes."
 	case "researcher":
-		client = a.models.Get(orchestrate.ModelResearcher)
+		modelType = orchestrate.ModelResearcher
 		systemPrompt = "You are a research specialist. Gather accurate, relevant information."
 	case "vision":
-		client = a.models.Get(orchestrate.ModelVision)
+		modelType = orchestrate.ModelVision
 		systemPrompt = "You are a vision specialist. Analyze visual content and describe findings."
 	case "orchestrator":
-		client = a.models.Get(orchestrate.ModelOrchestrator)
+		modelType = orchestrate.ModelOrchestrator
 		systemPrompt = "You are an orchestration specialist. Help with high-level planning and coordination."
 	default:
 		// Try to find a client for the role anyway, but use the provided system prompt or a generic one
-		client = a.models.Get(orchestrate.ModelType(role))
+		modelType = orchestrate.ModelType(role)
 		systemPrompt = "You are a specialist in " + role + "."
 	}
+	client := a.models.Get(modelType)
 
 	if client == nil {
 		return &DelegationResponse{
@@ -84,6 +85,7 @@ func (a *Agent) DelegateTask(ctx context.Context, req DelegationRequest) (*Deleg
 
 	// Execute the delegation
 	resp, stats, err := client.Chat(ctx, messages)
+	a.addExchange(modelType, messages[1].Content, resp, err)
 	if err != nil {
 		return &DelegationResponse{
 			Role:    role,
@@ -96,6 +98,7 @@ func (a *Agent) DelegateTask(ctx context.Context, req DelegationRequest) (*Deleg
 	if stats != nil {
 		tokens = int64(stats.TotalTokens)
 	}
+	a.recordTokens(modelType, tokens)
 
 	return &DelegationResponse{
 		Role:     role,
@@ -22,8 +22,10 @@ func (a *Agent) ReadFile(ctx context.Context, path string) (string, error) {
 	return action.Content, nil
 }
 
-// SearchFiles searches for a pattern in files under the given directory scope.
-func (a *Agent) SearchFiles(ctx context.Context, pattern string, scope string) (string, error) {
+// SearchFiles searches for a regular expression in files under the given
+// directory scope. Optional path globs limit the files searched; globs
+// prefixed with "!" exclude files.
+func (a *Agent) SearchFiles(ctx context.Context, pattern string, scope string, paths ...string) (string, error) {
 	if scope == "" {
 		scope = "."
 	}
@@ -33,6 +35,9 @@ func (a *Agent) SearchFiles(ctx context.Context, pattern string, scope string) (
 		Path:    scope,
 		Content: pattern,
 	}
+	if len(paths) > 0 {
+		action.Metadata = map[string]any{"paths": paths}
+	}
 	err := a.executeAction(ctx, &action)
 	if err != nil {
 		return "", err
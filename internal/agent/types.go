@@ -18,6 +18,12 @@ const (
 	ActionMoveFile   ActionType = "move_file"
 	ActionCopyFile   ActionType = "copy_file"
 
+	// Language server operations
+	ActionRenameSymbol ActionType = "rename_symbol"
+	ActionDiagnostics  ActionType = "diagnostics"
+	ActionHover        ActionType = "hover"
+	ActionReferences   ActionType = "references"
+
 	// Directory operations
 	ActionCreateDir ActionType = "create_dir"
 	ActionDeleteDir ActionType = "delete_dir"
@@ -31,6 +37,23 @@ const (
 	ActionFormat     ActionType = "format"
 	ActionTest       ActionType = "test"
 
+	// Background process operations
+	ActionStartBackground ActionType = "start_background"
+	ActionCheckBackground ActionType = "check_background"
+	ActionBackgroundLogs  ActionType = "background_logs"
+	ActionStopBackground  ActionType = "stop_background"
+
+	// Endpoint verification
+	ActionHTTPRequest ActionType = "http_request"
+
+	// Read-only database inspection
+	ActionDBListTables ActionType = "db_list_tables"
+	ActionDBQuery      ActionType = "db_query"
+
+	// Containerized execution
+	ActionDockerBuild ActionType = "docker_build"
+	ActionDockerRun   ActionType = "docker_run"
+
 	// Read/search operations (Tier 2)
 	ActionReadFile    ActionType = "read_file"
 	ActionSearchFiles ActionType = "search_files"
@@ -58,14 +81,43 @@ type Action struct {
 	LineRanges []LineRange
 	Diff       *DiffSummary
 
+	// Language server operations
+	Rename    *SymbolRename
+	Symbol    *SymbolRef
+	Locations []Location
+
 	// Command operations
-	Command    string
-	ExitCode   int
-	Output     string
+	Command     string
+	ExitCode    int
+	Output      string // stdout and stderr interleaved as written
+	Stdout      string
+	Stderr      string
+	Dir         string        // working directory; empty uses the current one
+	Timeout     time.Duration // 0 uses DefaultCommandTimeout
+	HealthCheck string        // background process health check URL or command
+	Duration    time.Duration
+
+	// HTTP requests
+	Request  *HTTPRequest
+	Response *HTTPResponse
+
+	// Database queries
+	Query       *DBQuery
+	QueryResult *DBResult
+
+	// Container builds and runs
+	Docker *DockerSpec
+
+	// Parsed lint and test output
+	ToolResult *ToolchainResult
 
 	// Process completion
 	ProcessName string
 
+	// Session state recording the process the action ran in, set by the
+	// recorder once the process is checkpointed
+	StateID string
+
 	// Metadata for additional action context
 	Metadata map[string]any
 }
@@ -117,6 +169,21 @@ func (a *Action) ActionOutput() string {
 		return "Agent • Moved " + a.Path + " to " + a.NewPath
 	case ActionCopyFile:
 		return "Agent • Copied " + a.Path + " to " + a.NewPath
+	case ActionRenameSymbol:
+		if a.Rename == nil {
+			return "Agent • Renamed a symbol in " + a.Path
+		}
+		return "Agent • Renamed " + a.Rename.Symbol + " to " + a.Rename.NewName + " (" + formatInt(len(a.Rename.Files)) + " files)"
+	case ActionDiagnostics:
+		n := 0
+		if a.ToolResult != nil {
+			n = len(a.ToolResult.Diagnostics)
+		}
+		return "Agent • Checked " + a.Path + " (" + formatInt(n) + " diagnostics)"
+	case ActionHover:
+		return "Agent • Looked up " + a.symbolName() + " in " + a.Path
+	case ActionReferences:
+		return "Agent • Found " + formatInt(len(a.Locations)) + " references to " + a.symbolName()
 	case ActionCreateDir:
 		return "Agent • Created " + a.Path
 	case ActionDeleteDir:
@@ -135,6 +202,27 @@ func (a *Action) ActionOutput() string {
 		return "Agent • Formatted " + a.Path + " (exit " + formatExitCode(a.ExitCode) + ")"
 	case ActionTest:
 		return "Agent • Tested " + a.Path + " (exit " + formatExitCode(a.ExitCode) + ")"
+	case ActionStartBackground:
+		return "Agent • Started " + a.Content + ": " + a.Command
+	case ActionCheckBackground:
+		return "Agent • Checked " + a.Content
+	case ActionBackgroundLogs:
+		return "Agent • Read logs of " + a.Content
+	case ActionStopBackground:
+		return "Agent • Stopped " + a.Content
+	case ActionHTTPRequest:
+		if a.Response != nil {
+			return "Agent • Requested " + a.Command + " (HTTP " + formatInt(a.Response.Status) + ")"
+		}
+		return "Agent • Requested " + a.Command
+	case ActionDBListTables:
+		return "Agent • Listed tables of " + a.queryDatabase()
+	case ActionDBQuery:
+		return "Agent • Queried " + a.queryDatabase()
+	case ActionDockerBuild:
+		return "Agent • Built image " + a.dockerImage() + " (exit " + formatExitCode(a.ExitCode) + ")"
+	case ActionDockerRun:
+		return "Agent • Ran container " + a.dockerImage() + " (exit " + formatExitCode(a.ExitCode) + ")"
 	case ActionReadFile:
 		return "Agent • Read " + a.Path
 	case ActionSearchFiles:
@@ -193,6 +281,30 @@ func formatInt(n int) string {
 	return string(digits)
 }
 
+// queryDatabase returns the database a query action targets.
+func (a Action) queryDatabase() string {
+	if a.Query == nil {
+		return "database"
+	}
+	return a.Query.Database
+}
+
+// symbolName returns the symbol a language server action looks up.
+func (a Action) symbolName() string {
+	if a.Symbol == nil {
+		return "symbol"
+	}
+	return a.Symbol.Name
+}
+
+// dockerImage returns the image a container action builds or runs.
+func (a Action) dockerImage() string {
+	if a.Docker == nil {
+		return "image"
+	}
+	return a.Docker.Image
+}
+
 // formatExitCode formats an exit code
 func formatExitCode(code int) string {
 	return formatInt(code)
@@ -206,12 +318,16 @@ type ActionStats struct {
 	FilesRenamed     int
 	FilesMoved       int
 	FilesCopied      int
+	SymbolsRenamed   int
+	SymbolLookups    int
 	DirsCreated      int
 	DirsDeleted      int
 	DirsRenamed      int
 	DirsMoved        int
 	DirsCopied       int
 	CommandsRan      int
+	HTTPRequests     int
+	DBQueries        int
 	FilesRead        int
 	FilesSearched    int
 	DirsListed       int
@@ -235,6 +351,10 @@ func (s *ActionStats) IncrementByType(actionType ActionType) {
 		s.FilesMoved++
 	case ActionCopyFile:
 		s.FilesCopied++
+	case ActionRenameSymbol:
+		s.SymbolsRenamed++
+	case ActionDiagnostics, ActionHover, ActionReferences:
+		s.SymbolLookups++
 	case ActionCreateDir:
 		s.DirsCreated++
 	case ActionDeleteDir:
@@ -253,6 +373,12 @@ func (s *ActionStats) IncrementByType(actionType ActionType) {
 		s.CommandsRan++
 	case ActionTest:
 		s.CommandsRan++
+	case ActionStartBackground, ActionDockerBuild, ActionDockerRun:
+		s.CommandsRan++
+	case ActionHTTPRequest:
+		s.HTTPRequests++
+	case ActionDBListTables, ActionDBQuery:
+		s.DBQueries++
 	case ActionReadFile:
 		s.FilesRead++
 	case ActionSearchFiles:
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// TokenUsage is the tokens of one model request, tagged with the schedule
+// and process it was made in.
+type TokenUsage struct {
+	Schedule orchestrate.ScheduleID
+	Process  orchestrate.ProcessID
+	Model    orchestrate.ModelType
+	Tokens   int64
+	Time     time.Time // when the request finished
+}
+
+// SetTokenCallback sets the callback receiving the token usage of each
+// model request the agent makes.
+func (a *Agent) SetTokenCallback(callback func(TokenUsage)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onTokens = callback
+}
+
+// recordTokens records the tokens of a request to model with the
+// coordinator and reports them, tagged with the current process.
+func (a *Agent) recordTokens(model orchestrate.ModelType, used int64) {
+	if used <= 0 {
+		return
+	}
+	if a.models != nil {
+		a.models.RecordTokens(model, used)
+	}
+	a.mu.Lock()
+	usage := TokenUsage{Schedule: a.currentSchedule, Process: a.currentProcess, Model: model, Tokens: used, Time: time.Now()}
+	a.lastTokens = usage
+	callback := a.onTokens
+	a.mu.Unlock()
+	if callback != nil {
+		callback(usage)
+	}
+}
+
+// LastTokenUsage returns the token usage of the agent's most recent model
+// request; its Time is zero before the first.
+func (a *Agent) LastTokenUsage() TokenUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastTokens
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Budget limits how long a single process may run and how much work it may do
+// before the watchdog suspends it. Zero values disable the corresponding limit.
+// Repeated failing commands are the LoopDetector's to find.
+type Budget struct {
+	MaxDuration      time.Duration
+	MaxActions       int
+	MaxRepeatedEdits int // consecutive writes to the same file
+}
+
+// DefaultBudget returns the default per-process budget.
+func DefaultBudget() Budget {
+	return Budget{
+		MaxDuration:      10 * time.Minute,
+		MaxActions:       50,
+		MaxRepeatedEdits: 5,
+	}
+}
+
+// WatchdogSnapshot captures the process state at the moment the watchdog tripped.
+type WatchdogSnapshot struct {
+	Schedule      string
+	Process       string
+	Elapsed       time.Duration
+	ActionCount   int
+	RecentActions []Action
+	Timestamp     time.Time
+}
+
+// BudgetExceededError is returned when a process exceeds its execution budget.
+type BudgetExceededError struct {
+	Reason   string
+	Snapshot WatchdogSnapshot
+}
+
+// Error implements the error interface
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("watchdog suspended %s/P%s: %s", e.Snapshot.Schedule, e.Snapshot.Process, e.Reason)
+}
+
+// Report returns a multi-line description of the suspension for humans and the orchestrator.
+func (e *BudgetExceededError) Report() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Watchdog suspended process P%s in %s schedule: %s\n",
+		e.Snapshot.Process, e.Snapshot.Schedule, e.Reason))
+	sb.WriteString(fmt.Sprintf("Elapsed: %s, actions: %d\n",
+		e.Snapshot.Elapsed.Round(time.Second), e.Snapshot.ActionCount))
+	if len(e.Snapshot.RecentActions) > 0 {
+		sb.WriteString("Recent actions:\n")
+		for _, a := range e.Snapshot.RecentActions {
+			line := a.ActionOutput()
+			if status, ok := a.Metadata["status"].(string); ok && status == "failed" {
+				line += " [failed]"
+			}
+			sb.WriteString("  - " + line + "\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// watchdogRecentActions is the number of actions kept for snapshots.
+const watchdogRecentActions = 5
+
+// Watchdog is an agent plugin that enforces a per-process Budget. When the
+// agent loops (repeatedly editing the same file or re-running a failing
+// command) or exceeds its action or time budget, further actions are rejected
+// with a *BudgetExceededError carrying a snapshot of the process state.
+type Watchdog struct {
+	*BasePlugin
+
+	mu       sync.Mutex
+	budget   Budget
+	schedule string
+	process  string
+	start    time.Time
+	actions  int
+
+	lastWritePath string
+	writeStreak   int
+	recent        []Action
+
+	tripped *BudgetExceededError
+}
+
+// NewWatchdog creates a watchdog enforcing the given budget.
+func NewWatchdog(budget Budget) *Watchdog {
+	return &Watchdog{
+		BasePlugin: NewBasePlugin("watchdog"),
+		budget:     budget,
+	}
+}
+
+// SetBudget replaces the budget applied to subsequent processes.
+func (w *Watchdog) SetBudget(budget Budget) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.budget = budget
+}
+
+// Budget returns the current budget.
+func (w *Watchdog) Budget() Budget {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.budget
+}
+
+// Tripped returns the suspension error if the watchdog has tripped for the current process.
+func (w *Watchdog) Tripped() *BudgetExceededError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tripped
+}
+
+// Trip suspends the current process with the given reason. It is used by
+// callers enforcing limits the plugin cannot observe itself, such as a
+// process deadline expiring while the model is still generating.
+func (w *Watchdog) Trip(reason string) *BudgetExceededError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tripLocked(reason)
+	return w.tripped
+}
+
+// OnBeforeExecute resets the watchdog for a new process.
+func (w *Watchdog) OnBeforeExecute(ctx context.Context, schedule string, process string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.schedule = schedule
+	w.process = process
+	w.start = time.Now()
+	w.actions = 0
+	w.lastWritePath = ""
+	w.writeStreak = 0
+	w.recent = nil
+	w.tripped = nil
+	return nil
+}
+
+// OnBeforeAction rejects actions once the process is over budget.
+func (w *Watchdog) OnBeforeAction(ctx context.Context, action *Action) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.tripped != nil {
+		return w.tripped
+	}
+	if w.budget.MaxDuration > 0 && !w.start.IsZero() && time.Since(w.start) > w.budget.MaxDuration {
+		w.tripLocked(fmt.Sprintf("exceeded max duration of %s", w.budget.MaxDuration))
+		return w.tripped
+	}
+	if w.budget.MaxActions > 0 && w.actions >= w.budget.MaxActions {
+		w.tripLocked(fmt.Sprintf("exceeded max action count of %d", w.budget.MaxActions))
+		return w.tripped
+	}
+	return nil
+}
+
+// OnAfterAction updates counters and loop detection state.
+func (w *Watchdog) OnAfterAction(ctx context.Context, action *Action) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.actions++
+	w.recent = append(w.recent, *action)
+	if len(w.recent) > watchdogRecentActions {
+		w.recent = w.recent[len(w.recent)-watchdogRecentActions:]
+	}
+
+	switch action.Type {
+	case ActionEditFile, ActionCreateFile:
+		if action.Path == w.lastWritePath {
+			w.writeStreak++
+		} else {
+			w.lastWritePath = action.Path
+			w.writeStreak = 1
+		}
+		if w.budget.MaxRepeatedEdits > 0 && w.writeStreak >= w.budget.MaxRepeatedEdits {
+			w.tripLocked(fmt.Sprintf("edited %s %d times in a row", action.Path, w.writeStreak))
+		}
+	default:
+		w.lastWritePath = ""
+		w.writeStreak = 0
+	}
+	return nil
+}
+
+// tripLocked records the suspension; the caller must hold w.mu.
+func (w *Watchdog) tripLocked(reason string) {
+	if w.tripped != nil {
+		return
+	}
+	recent := make([]Action, len(w.recent))
+	copy(recent, w.recent)
+
+	elapsed := time.Duration(0)
+	if !w.start.IsZero() {
+		elapsed = time.Since(w.start)
+	}
+
+	w.tripped = &BudgetExceededError{
+		Reason: reason,
+		Snapshot: WatchdogSnapshot{
+			Schedule:      w.schedule,
+			Process:       w.process,
+			Elapsed:       elapsed,
+			ActionCount:   w.actions,
+			RecentActions: recent,
+			Timestamp:     time.Now(),
+		},
+	}
+}
+
+// actionFailed reports whether an executed action failed.
+func actionFailed(action *Action) bool {
+	if action.ExitCode != 0 {
+		return true
+	}
+	status, _ := action.Metadata["status"].(string)
+	return status == "failed"
+}
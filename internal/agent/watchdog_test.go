@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWatchdog_RepeatedEdits(t *testing.T) {
+	ctx := context.Background()
+	wd := NewWatchdog(Budget{MaxRepeatedEdits: 3})
+	_ = wd.OnBeforeExecute(ctx, "Implement", "1")
+
+	for i := 0; i < 3; i++ {
+		action := &Action{Type: ActionEditFile, Path: "main.go"}
+		if err := wd.OnBeforeAction(ctx, action); err != nil {
+			t.Fatalf("edit %d rejected early: %v", i+1, err)
+		}
+		_ = wd.OnAfterAction(ctx, action)
+	}
+
+	err := wd.OnBeforeAction(ctx, &Action{Type: ActionReadFile, Path: "main.go"})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if budgetErr.Snapshot.ActionCount != 3 || len(budgetErr.Snapshot.RecentActions) != 3 {
+		t.Errorf("snapshot = %+v, want 3 actions", budgetErr.Snapshot)
+	}
+
+	// A new process resets the watchdog.
+	_ = wd.OnBeforeExecute(ctx, "Implement", "2")
+	if wd.Tripped() != nil {
+		t.Error("OnBeforeExecute should reset the watchdog")
+	}
+}
+
+func TestWatchdog_MaxActions(t *testing.T) {
+	ctx := context.Background()
+	wd := NewWatchdog(Budget{MaxActions: 2})
+	_ = wd.OnBeforeExecute(ctx, "Knowledge", "1")
+
+	for i := 0; i < 2; i++ {
+		action := &Action{Type: ActionReadFile, Path: "README.md"}
+		if err := wd.OnBeforeAction(ctx, action); err != nil {
+			t.Fatalf("action %d rejected early: %v", i+1, err)
+		}
+		_ = wd.OnAfterAction(ctx, action)
+	}
+	if err := wd.OnBeforeAction(ctx, &Action{Type: ActionReadFile}); err == nil {
+		t.Error("expected action over budget to be rejected")
+	}
+}
@@ -3,6 +3,8 @@ package analyzer
 import (
 	"fmt"
 	"strings"
+
+	"github.com/croberts/obot/internal/tokens"
 )
 
 // ContextBuilder builds context for the AI model
@@ -88,14 +90,9 @@ func (cb *ContextBuilder) BuildUserPrompt() string {
 	return sb.String()
 }
 
-// EstimateTokens provides a rough estimate of tokens in the context
+// EstimateTokens estimates the tokens in the context with the shared tokenizer
 func (cb *ContextBuilder) EstimateTokens() int {
-	// Rough estimate: 4 characters per token
-	systemPrompt := cb.BuildSystemPrompt()
-	userPrompt := cb.BuildUserPrompt()
-
-	totalChars := len(systemPrompt) + len(userPrompt)
-	return totalChars / 4
+	return tokens.Count(cb.BuildSystemPrompt()) + tokens.Count(cb.BuildUserPrompt())
 }
 
 // Summary returns a summary of what will be fixed
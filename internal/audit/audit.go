@@ -0,0 +1,270 @@
+// Package audit keeps an append-only, hash-chained log of every file and
+// command action the agent performs in a workspace. The log lives outside
+// the session so it survives session cleanup, and each entry commits to the
+// previous one so that edits or deletions are detectable with Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// logFile is the audit log created in the workspace's .obot directory.
+const logFile = "audit.jsonl"
+
+// Entry is one audited action.
+type Entry struct {
+	Seq       int       `json:"seq"`
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id,omitempty"`
+	Schedule  string    `json:"schedule,omitempty"`
+	Process   string    `json:"process,omitempty"`
+	ActionID  string    `json:"action_id,omitempty"`
+	Action    string    `json:"action"`
+	Path      string    `json:"path,omitempty"`
+	NewPath   string    `json:"new_path,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Dir       string    `json:"dir,omitempty"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+
+	// ReplayOf is the ID of the action this one replayed, for an action
+	// replayed while investigating a suspended run.
+	ReplayOf string `json:"replay_of,omitempty"`
+
+	// BeforeHash and AfterHash are SHA-256 digests of the file content
+	// before and after the action. For commands, AfterHash is the digest
+	// of the combined output.
+	BeforeHash string `json:"before_hash,omitempty"`
+	AfterHash  string `json:"after_hash,omitempty"`
+
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// computeHash returns the chain hash of e: the digest of the previous hash
+// and the entry's JSON encoding without its own hash.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Log is an append-only audit log file.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	seq      int
+	lastHash string
+}
+
+// DefaultPath returns the audit log path for workspace.
+func DefaultPath(workspace string) string {
+	return filepath.Join(workspace, ".obot", logFile)
+}
+
+// Open opens the audit log at path for appending, creating it if needed,
+// and continues the hash chain from its last entry.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	entries, err := ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{path: path, file: f}
+	if n := len(entries); n > 0 {
+		l.seq = entries[n-1].Seq
+		l.lastHash = entries[n-1].Hash
+	}
+	return l, nil
+}
+
+// Path returns the file the log writes to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append assigns the next sequence number to e, chains it to the previous
+// entry, and writes it to the log.
+func (l *Log) Append(e Entry) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return e, fmt.Errorf("audit log %s is closed", l.path)
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.Seq = l.seq + 1
+	e.PrevHash = l.lastHash
+	hash, err := e.computeHash()
+	if err != nil {
+		return e, err
+	}
+	e.Hash = hash
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return e, fmt.Errorf("write audit log: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return e, fmt.Errorf("sync audit log: %w", err)
+	}
+	l.seq = e.Seq
+	l.lastHash = e.Hash
+	return e, nil
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// ReadFile reads all entries of the audit log at path.
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses audit log entries, one JSON object per line.
+func Read(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return entries, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// ChainError reports where an audit log's hash chain is broken.
+type ChainError struct {
+	Seq    int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at entry %d: %s", e.Seq, e.Reason)
+}
+
+// Verify checks that entries form an unbroken hash chain with consecutive
+// sequence numbers. It returns a *ChainError for the first bad entry.
+func Verify(entries []Entry) error {
+	prev := ""
+	for i, e := range entries {
+		if e.Seq != i+1 {
+			return &ChainError{Seq: e.Seq, Reason: fmt.Sprintf("expected sequence %d", i+1)}
+		}
+		if e.PrevHash != prev {
+			return &ChainError{Seq: e.Seq, Reason: "previous hash does not match (entry removed or reordered)"}
+		}
+		want, err := e.computeHash()
+		if err != nil {
+			return err
+		}
+		if e.Hash != want {
+			return &ChainError{Seq: e.Seq, Reason: "hash mismatch (entry modified)"}
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// Export writes entries to w as "json" (an indented array) or "csv".
+func Export(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case "", "json":
+		if entries == nil {
+			entries = []Entry{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"seq", "time", "session_id", "schedule", "process", "action_id", "action",
+			"path", "new_path", "command", "dir", "exit_code", "status", "error", "before_hash", "after_hash", "hash"})
+		for _, e := range entries {
+			exitCode := ""
+			if e.ExitCode != nil {
+				exitCode = strconv.Itoa(*e.ExitCode)
+			}
+			_ = cw.Write([]string{strconv.Itoa(e.Seq), e.Time.Format(time.RFC3339Nano), e.SessionID, e.Schedule,
+				e.Process, e.ActionID, e.Action, e.Path, e.NewPath, e.Command, e.Dir, exitCode, e.Status, e.Error,
+				e.BeforeHash, e.AfterHash, e.Hash})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q (use json or csv)", format)
+	}
+}
+
+// HashFile returns the SHA-256 digest of the file at path, or "" if it
+// cannot be read (e.g. it does not exist or is a directory).
+func HashFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return HashBytes(data)
+}
+
+// HashBytes returns the SHA-256 digest of data.
+func HashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
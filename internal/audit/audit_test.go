@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/model"
+)
+
+func TestLog_AppendChainsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".obot", "audit.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := l.Append(Entry{Action: "create_file", Path: "a.go", Status: "success"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	l.Close()
+
+	l, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	e, err := l.Append(Entry{Action: "delete_file", Path: "a.go", Status: "success"})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	l.Close()
+	if e.Seq != 2 || e.PrevHash == "" {
+		t.Errorf("second entry = seq %d prev %q, want chained seq 2", e.Seq, e.PrevHash)
+	}
+
+	entries, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := Verify(entries); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, _ := Open(path)
+	for _, p := range []string{"a.go", "b.go", "c.go"} {
+		if _, err := l.Append(Entry{Action: "edit_file", Path: p, Status: "success"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+	entries, _ := ReadFile(path)
+
+	modified := append([]Entry(nil), entries...)
+	modified[1].Path = "evil.go"
+	removed := []Entry{entries[0], entries[2]}
+
+	for name, tampered := range map[string][]Entry{"modified": modified, "removed": removed} {
+		var chainErr *ChainError
+		if err := Verify(tampered); !errors.As(err, &chainErr) {
+			t.Errorf("%s: Verify() = %v, want *ChainError", name, err)
+		}
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	code := 1
+	entries := []Entry{{Seq: 1, Action: "run_command", Command: "go test ./...", ExitCode: &code, Status: "failed"}}
+	var buf bytes.Buffer
+	if err := Export(&buf, entries, "csv"); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "go test ./...") || !strings.Contains(lines[1], ",1,failed,") {
+		t.Errorf("Export(csv) = %q", buf.String())
+	}
+	if err := Export(&buf, entries, "xml"); err == nil {
+		t.Error("Export(xml) succeeded, want error")
+	}
+}
+
+func TestPlugin_RecordsHashes(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := Open(filepath.Join(dir, "audit.jsonl"))
+	defer l.Close()
+	p := NewPlugin(l, "sess1")
+	ctx := context.Background()
+
+	file := filepath.Join(dir, "main.go")
+	os.WriteFile(file, []byte("old"), 0644)
+	edit := &agent.Action{ID: "A00001", Type: agent.ActionEditFile, Path: file,
+		Metadata: map[string]any{"status": "success", "schedule": "implement", "process": "1"}}
+	if err := p.OnBeforeAction(ctx, edit); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(file, []byte("new"), 0644)
+	if err := p.OnAfterAction(ctx, edit); err != nil {
+		t.Fatal(err)
+	}
+
+	read := &agent.Action{ID: "A00002", Type: agent.ActionReadFile, Path: file}
+	p.OnBeforeAction(ctx, read)
+	p.OnAfterAction(ctx, read)
+
+	entries, _ := ReadFile(l.Path())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (reads are not audited)", len(entries))
+	}
+	e := entries[0]
+	if e.BeforeHash != HashBytes([]byte("old")) || e.AfterHash != HashBytes([]byte("new")) {
+		t.Errorf("hashes = %s -> %s", e.BeforeHash, e.AfterHash)
+	}
+	if e.SessionID != "sess1" || e.Schedule != "implement" || e.Status != "success" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestPlugin_RefusesActionsAfterWriteFailure(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	p := NewPlugin(l, "sess1")
+	l.Close()
+
+	ctx := context.Background()
+	cmd := &agent.Action{ID: "A00001", Type: agent.ActionRunCommand, Command: "ls"}
+	if err := p.OnAfterAction(ctx, cmd); err == nil {
+		t.Fatal("OnAfterAction() on closed log succeeded")
+	}
+	if err := p.OnBeforeAction(ctx, cmd); err == nil {
+		t.Error("OnBeforeAction() allowed an action after the log failed")
+	}
+}
+
+func TestPlugin_RecordsReplays(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	defer l.Close()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	ag.RegisterPlugin(NewPlugin(l, "sess1"))
+	ctx := context.Background()
+
+	if _, err := ag.RunCheck(ctx, "echo hi", agent.CommandOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ag.ReplayLastAction(ctx); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := ReadFile(l.Path())
+	if len(entries) != 2 || entries[0].ReplayOf != "" || entries[1].ReplayOf != entries[0].ActionID {
+		t.Fatalf("entries = %+v, want the command and its replay", entries)
+	}
+}
+
+func TestPlugin_RecordsBackgroundCommands(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	defer l.Close()
+	p := NewPlugin(l, "sess1")
+	ctx := context.Background()
+
+	start := &agent.Action{ID: "A00001", Type: agent.ActionStartBackground, Content: "server",
+		Command: "go run ./cmd/server", Dir: "api"}
+	if err := p.OnBeforeAction(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.OnAfterAction(ctx, start); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := ReadFile(l.Path())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the background command", len(entries))
+	}
+	if e := entries[0]; e.Command != "go run ./cmd/server" || e.Dir != "api" || e.ExitCode != nil {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestPlugin_RecordsDockerCommands(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	defer l.Close()
+	p := NewPlugin(l, "sess1")
+	ctx := context.Background()
+
+	command := "docker run --rm --name obot-1-1 --cpus 2 --memory 2g --pids-limit 512 " +
+		"--security-opt no-new-privileges --network none --volume /src:/workspace --workdir /workspace alpine sh -c ls"
+	run := &agent.Action{ID: "A00001", Type: agent.ActionDockerRun, Command: command, ExitCode: 0,
+		Docker: &agent.DockerSpec{Image: "alpine", Command: "ls", Mount: "/src"}}
+	build := &agent.Action{ID: "A00002", Type: agent.ActionDockerBuild, Command: "docker build --tag app --network none .",
+		Docker: &agent.DockerSpec{Image: "app"}}
+	for _, action := range []*agent.Action{run, build} {
+		if err := p.OnBeforeAction(ctx, action); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.OnAfterAction(ctx, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, _ := ReadFile(l.Path())
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the docker run and build", len(entries))
+	}
+	if e := entries[0]; e.Command != command || e.ExitCode == nil {
+		t.Errorf("docker run entry = %+v", e)
+	}
+	if e := entries[1]; e.Action != "docker_build" || !strings.Contains(e.Command, "--tag app") {
+		t.Errorf("docker build entry = %+v", e)
+	}
+}
@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/redact"
+)
+
+// Plugin is an agent plugin that writes every file and command action to an
+// audit log. If the log cannot be written, further actions are refused so
+// that nothing the agent does goes unrecorded.
+type Plugin struct {
+	*agent.BasePlugin
+
+	log       *Log
+	sessionID string
+
+	mu     sync.Mutex
+	before map[string]string // action ID -> content hash before the action
+	err    error
+}
+
+// NewPlugin creates an audit plugin that records actions of sessionID to log.
+func NewPlugin(log *Log, sessionID string) *Plugin {
+	return &Plugin{
+		BasePlugin: agent.NewBasePlugin("audit"),
+		log:        log,
+		sessionID:  sessionID,
+		before:     make(map[string]string),
+	}
+}
+
+// audited reports whether actions of type t change the workspace or run commands.
+func audited(t agent.ActionType) bool {
+	switch t {
+	case agent.ActionCreateFile, agent.ActionDeleteFile, agent.ActionEditFile,
+		agent.ActionRenameFile, agent.ActionMoveFile, agent.ActionCopyFile,
+		agent.ActionCreateDir, agent.ActionDeleteDir, agent.ActionRenameDir,
+		agent.ActionMoveDir, agent.ActionCopyDir,
+		agent.ActionRunCommand, agent.ActionLint, agent.ActionFormat, agent.ActionTest,
+		agent.ActionStartBackground, agent.ActionDockerBuild, agent.ActionDockerRun:
+		return true
+	}
+	return false
+}
+
+// isCommand reports whether actions of type t run a shell command.
+func isCommand(t agent.ActionType) bool {
+	switch t {
+	case agent.ActionRunCommand, agent.ActionLint, agent.ActionFormat, agent.ActionTest,
+		agent.ActionStartBackground, agent.ActionDockerBuild, agent.ActionDockerRun:
+		return true
+	}
+	return false
+}
+
+// OnReplay records replayed actions too, tagged with the action they
+// replayed.
+func (p *Plugin) OnReplay() bool {
+	return true
+}
+
+// OnBeforeAction refuses the action if the log has failed, and remembers the
+// content hash of files the action may change.
+func (p *Plugin) OnBeforeAction(ctx context.Context, action *agent.Action) error {
+	if !audited(action.Type) {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return fmt.Errorf("audit log unavailable: %w", p.err)
+	}
+	if !isCommand(action.Type) {
+		p.before[action.ID] = HashFile(action.Path)
+	}
+	return nil
+}
+
+// OnAfterAction appends the finished action to the log.
+func (p *Plugin) OnAfterAction(ctx context.Context, action *agent.Action) error {
+	if !audited(action.Type) {
+		return nil
+	}
+	p.mu.Lock()
+	before := p.before[action.ID]
+	delete(p.before, action.ID)
+	p.mu.Unlock()
+
+	e := Entry{
+		Time:       action.Timestamp,
+		SessionID:  p.sessionID,
+		ActionID:   action.ID,
+		Action:     string(action.Type),
+		Path:       action.Path,
+		NewPath:    action.NewPath,
+		BeforeHash: before,
+	}
+	if action.Metadata != nil {
+		e.Schedule, _ = action.Metadata["schedule"].(string)
+		e.Process, _ = action.Metadata["process"].(string)
+		e.Status, _ = action.Metadata["status"].(string)
+		e.ReplayOf, _ = action.Metadata["replay_of"].(string)
+		if msg, ok := action.Metadata["error"].(string); ok {
+			e.Error = redact.String("audit log", msg)
+		}
+	}
+
+	switch {
+	case isCommand(action.Type):
+		// Docker actions carry the docker command line they ran, with the
+		// image, mount, and resource limits
+		e.Command = redact.String("audit log", action.Command)
+		e.Dir = action.Dir
+		if action.Type != agent.ActionStartBackground {
+			// A background process is still running when the action ends
+			code := action.ExitCode
+			e.ExitCode = &code
+		}
+		e.AfterHash = HashBytes([]byte(action.Output))
+	case action.NewPath != "":
+		e.AfterHash = HashFile(action.NewPath)
+	default:
+		e.AfterHash = HashFile(action.Path)
+	}
+
+	if _, err := p.log.Append(e); err != nil {
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Package bench benchmarks models on this machine: generation speed, time
+// to first token, and the memory a loaded model takes. The stored results
+// calibrate ETA estimates and the RAM tier's model selection.
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/tier"
+)
+
+const (
+	// DefaultMaxTokens caps the tokens generated for each prompt.
+	DefaultMaxTokens = 128
+	// MinUsableTokensPerSecond is the generation speed below which a
+	// tier's model is too slow to select.
+	MinUsableTokensPerSecond = 8
+	// maxMemoryShare is the share of RAM a tier's model may take when
+	// loaded.
+	maxMemoryShare = 0.75
+)
+
+// Prompt is a canned benchmark prompt.
+type Prompt struct {
+	Name string
+	Text string
+}
+
+// Prompts are the canned prompts each model is benchmarked on: a fix, an
+// explanation, and code generation, the kinds of requests obot makes.
+var Prompts = []Prompt{
+	{Name: "fix", Text: "Fix the bug in this Go function and return only the corrected code:\n\nfunc sum(xs []int) int {\n\ttotal := 0\n\tfor i := 1; i <= len(xs); i++ {\n\t\ttotal += xs[i]\n\t}\n\treturn total\n}"},
+	{Name: "explain", Text: "Explain in three sentences what a mutex is and when a program needs one."},
+	{Name: "generate", Text: "Write a Python function that parses an ISO 8601 date string and returns the day of the week, with a docstring."},
+}
+
+// Result is a model's benchmark on this machine.
+type Result struct {
+	Model            string        `json:"model"`
+	TokensPerSecond  float64       `json:"tokens_per_second"`
+	TimeToFirstToken time.Duration `json:"time_to_first_token_ns"` // average, after loading
+	LoadTime         time.Duration `json:"load_time_ns"`           // 0 when the model was already loaded
+	MemoryBytes      int64         `json:"memory_bytes"`           // size of the loaded model
+	VRAMBytes        int64         `json:"vram_bytes"`             // of which in GPU memory
+	Prompts          int           `json:"prompts"`
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// Results are the stored benchmarks of a machine, by model.
+type Results struct {
+	RAMGB  int               `json:"ram_gb"` // RAM of the machine benchmarked
+	Models map[string]Result `json:"models"`
+}
+
+// DefaultPath returns where benchmark results are stored.
+func DefaultPath() string {
+	return filepath.Join(config.UnifiedConfigDir(), "bench.json")
+}
+
+// Load reads stored benchmark results; it returns nil without an error
+// when no model was benchmarked.
+func Load(path string) (*Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var r Results
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save stores the benchmark results.
+func (r *Results) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add records a model's benchmark, replacing an earlier one.
+func (r *Results) Add(result Result) {
+	if r.Models == nil {
+		r.Models = make(map[string]Result)
+	}
+	r.Models[result.Model] = result
+}
+
+// Estimate returns how long the model takes to generate the given number
+// of tokens, from its benchmark; false when it was not benchmarked.
+func (r *Results) Estimate(model string, tokens int) (time.Duration, bool) {
+	if r == nil {
+		return 0, false
+	}
+	b, ok := r.Models[model]
+	if !ok || b.TokensPerSecond <= 0 {
+		return 0, false
+	}
+	return b.TimeToFirstToken + time.Duration(float64(tokens)/b.TokensPerSecond*float64(time.Second)), true
+}
+
+// CalibrateTier returns the tier to select on this machine. It steps down
+// from the detected tier while the benchmarks show the tier's model is too
+// slow or too large for the RAM, and up while the next tier's model was
+// benchmarked and is neither. Models not benchmarked leave the tier as it
+// is.
+func (r *Results) CalibrateTier(detected tier.ModelTier, ramGB int) tier.ModelTier {
+	if r == nil {
+		return detected
+	}
+	i := slices.IndexFunc(tier.Tiers, func(t tier.TierInfo) bool { return t.Tier == detected })
+	if i < 0 {
+		return detected
+	}
+	usable := func(i int) (benchmarked, ok bool) {
+		b, found := r.Models[tier.GetModelForTier(tier.Tiers[i].Tier).OllamaTag]
+		if !found {
+			return false, false
+		}
+		fits := ramGB <= 0 || float64(b.MemoryBytes) <= maxMemoryShare*float64(ramGB)*(1<<30)
+		return true, b.TokensPerSecond >= MinUsableTokensPerSecond && fits
+	}
+	for i > 0 {
+		if benchmarked, ok := usable(i); !benchmarked || ok {
+			break
+		}
+		i--
+	}
+	for i+1 < len(tier.Tiers) {
+		if _, ok := usable(i + 1); !ok {
+			break
+		}
+		i++
+	}
+	return tier.Tiers[i].Tier
+}
+
+// Run benchmarks a model on the canned prompts through the Ollama server
+// at baseURL, generating up to maxTokens tokens for each.
+func Run(ctx context.Context, baseURL, model string, maxTokens int) (*Result, error) {
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	client := ollama.NewClient(ollama.WithBaseURL(baseURL), ollama.WithModel(model))
+	client.SetMaxTokens(maxTokens)
+
+	result := &Result{Model: model, Timestamp: time.Now()}
+	var evalTokens int
+	var evalDuration, firstTokens time.Duration
+	for i, p := range Prompts {
+		start := time.Now()
+		var first time.Duration
+		res, err := client.GenerateStream(ctx, p.Text, func(string) {
+			if first == 0 {
+				first = time.Since(start)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s prompt: %w", p.Name, err)
+		}
+		load := time.Duration(res.Stats.LoadDuration)
+		if i == 0 {
+			result.LoadTime = load
+			measureMemory(ctx, client, result)
+		}
+		firstTokens += max(first-load, 0)
+		evalTokens += res.Stats.CompletionTokens
+		evalDuration += time.Duration(res.Stats.EvalDuration)
+		result.Prompts++
+	}
+	if evalDuration > 0 {
+		result.TokensPerSecond = float64(evalTokens) / evalDuration.Seconds()
+	}
+	result.TimeToFirstToken = firstTokens / time.Duration(result.Prompts)
+	return result, nil
+}
+
+// measureMemory records the memory the loaded model takes, when the server
+// reports it.
+func measureMemory(ctx context.Context, client *ollama.Client, result *Result) {
+	running, err := client.RunningModels(ctx)
+	if err != nil {
+		return
+	}
+	for _, m := range running {
+		if m.Name == result.Model || strings.TrimSuffix(m.Name, ":latest") == result.Model {
+			result.MemoryBytes, result.VRAMBytes = m.Size, m.SizeVRAM
+			return
+		}
+	}
+}
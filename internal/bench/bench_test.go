@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/tier"
+)
+
+// benchServer serves streamed generations of 50 tokens in 2 seconds, the
+// first loading the model for a second, and a loaded model of 4 GiB.
+func benchServer(t *testing.T) string {
+	t.Helper()
+	loaded := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		switch r.URL.Path {
+		case "/api/generate":
+			done := ollama.GenerateResponse{Done: true, EvalCount: 50, EvalDuration: int64(2 * time.Second)}
+			if !loaded {
+				done.LoadDuration = int64(time.Second)
+				loaded = true
+			}
+			enc.Encode(ollama.GenerateResponse{Response: "func"})
+			enc.Encode(done)
+		case "/api/ps":
+			enc.Encode(ollama.PsResponse{Models: []ollama.RunningModel{{Name: "coder:latest", Size: 4 << 30, SizeVRAM: 3 << 30}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestRun(t *testing.T) {
+	result, err := Run(context.Background(), benchServer(t), "coder", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TokensPerSecond != 25 || result.Prompts != len(Prompts) {
+		t.Errorf("TokensPerSecond = %.1f over %d prompts, want 25 over %d", result.TokensPerSecond, result.Prompts, len(Prompts))
+	}
+	if result.LoadTime != time.Second || result.MemoryBytes != 4<<30 || result.VRAMBytes != 3<<30 {
+		t.Errorf("LoadTime = %s, memory = %d/%d, want 1s and 4 GiB with 3 in VRAM", result.LoadTime, result.VRAMBytes, result.MemoryBytes)
+	}
+
+	path := filepath.Join(t.TempDir(), "bench.json")
+	results := &Results{RAMGB: 16}
+	results.Add(*result)
+	if err := results.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil || loaded.Models["coder"].TokensPerSecond != 25 {
+		t.Errorf("Load() = %+v, %v", loaded, err)
+	}
+	if missing, err := Load(filepath.Join(t.TempDir(), "none.json")); missing != nil || err != nil {
+		t.Errorf("Load() of a missing file = %+v, %v, want nil", missing, err)
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	results := &Results{}
+	results.Add(Result{Model: "coder", TokensPerSecond: 20, TimeToFirstToken: 500 * time.Millisecond})
+	if d, ok := results.Estimate("coder", 100); !ok || d != 5500*time.Millisecond {
+		t.Errorf("Estimate() = %s, %v, want 5.5s", d, ok)
+	}
+	if _, ok := results.Estimate("other", 100); ok {
+		t.Error("estimated a model not benchmarked")
+	}
+	var none *Results
+	if _, ok := none.Estimate("coder", 100); ok {
+		t.Error("estimated without results")
+	}
+}
+
+func TestCalibrateTier(t *testing.T) {
+	tag := func(t tier.ModelTier) string { return tier.GetModelForTier(t).OllamaTag }
+	tests := []struct {
+		name    string
+		results map[tier.ModelTier]Result
+		want    tier.ModelTier
+	}{
+		{"not benchmarked", nil, tier.TierPerformance},
+		{"fast enough", map[tier.ModelTier]Result{tier.TierPerformance: {TokensPerSecond: 12, MemoryBytes: 20 << 30}}, tier.TierPerformance},
+		{"too slow", map[tier.ModelTier]Result{tier.TierPerformance: {TokensPerSecond: 4}, tier.TierBalanced: {TokensPerSecond: 6}}, tier.TierCompact},
+		{"too large", map[tier.ModelTier]Result{tier.TierPerformance: {TokensPerSecond: 12, MemoryBytes: 30 << 30}}, tier.TierBalanced},
+		{"room to grow", map[tier.ModelTier]Result{tier.TierAdvanced: {TokensPerSecond: 9, MemoryBytes: 20 << 30}}, tier.TierAdvanced},
+	}
+	for _, tt := range tests {
+		results := &Results{}
+		for tr, r := range tt.results {
+			r.Model = tag(tr)
+			results.Add(r)
+		}
+		if got := results.CalibrateTier(tier.TierPerformance, 32); got != tt.want {
+			t.Errorf("%s: CalibrateTier() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
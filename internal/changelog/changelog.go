@@ -0,0 +1,255 @@
+// Package changelog turns the file actions of an orchestration into a
+// human-readable CHANGELOG entry, grouped by the schedule that made each
+// change and the directory, the area of the project, it was made in.
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// FileName is the changelog's name in the workspace.
+const FileName = "CHANGELOG.md"
+
+// rootArea names the area of files at the top of the workspace.
+const rootArea = "(root)"
+
+// Entry is one changelog entry: the changes of a prompt.
+type Entry struct {
+	Title     string // the prompt's first line
+	Date      time.Time
+	SessionID string
+	FlowCode  string
+	Schedules []Schedule
+}
+
+// Schedule is the changes made during one schedule.
+type Schedule struct {
+	Name  string
+	Areas []Area
+}
+
+// Area is the changes made in one directory.
+type Area struct {
+	Dir   string
+	Files []File
+}
+
+// File is what was done to one file or directory.
+type File struct {
+	Name    string   // base name, with a trailing slash for a directory
+	Changes []string // such as "added" or "renamed to `internal/x.go`", in order
+	Added   int
+	Removed int
+}
+
+// verbs describe the file actions a changelog lists.
+var verbs = map[agent.ActionType]string{
+	agent.ActionCreateFile: "added",
+	agent.ActionEditFile:   "edited",
+	agent.ActionDeleteFile: "deleted",
+	agent.ActionRenameFile: "renamed",
+	agent.ActionMoveFile:   "moved",
+	agent.ActionCopyFile:   "copied",
+	agent.ActionCreateDir:  "added",
+	agent.ActionDeleteDir:  "deleted",
+	agent.ActionRenameDir:  "renamed",
+	agent.ActionMoveDir:    "moved",
+	agent.ActionCopyDir:    "copied",
+}
+
+// isDir reports whether actions of type t act on a directory.
+func isDir(t agent.ActionType) bool {
+	switch t {
+	case agent.ActionCreateDir, agent.ActionDeleteDir, agent.ActionRenameDir, agent.ActionMoveDir, agent.ActionCopyDir:
+		return true
+	}
+	return false
+}
+
+// Group groups the successful file actions by schedule, in the order the
+// schedules run, then by directory and file, each in the order first
+// changed. Paths are shown relative to root when under it.
+func Group(actions []agent.Action, root string) []Schedule {
+	type fileKey struct{ schedule, dir, name string }
+	var schedules []Schedule
+	scheduleIndex := make(map[string]int)
+	areaIndex := make(map[[2]string]int)
+	fileIndex := make(map[fileKey]int)
+
+	for _, a := range actions {
+		verb, ok := verbs[a.Type]
+		if !ok {
+			continue
+		}
+		if status, _ := a.Metadata["status"].(string); status == "failed" {
+			continue
+		}
+		sched, _ := a.Metadata["schedule"].(string)
+		if sched == "" {
+			sched = "Other"
+		}
+		rel := relative(root, a.Path)
+		dir, name := path.Split(rel)
+		dir = strings.TrimSuffix(dir, "/")
+		if dir == "" {
+			dir = rootArea
+		}
+		if isDir(a.Type) {
+			name += "/"
+		}
+		if a.NewPath != "" {
+			verb += " to `" + relative(root, a.NewPath) + "`"
+		}
+
+		si, ok := scheduleIndex[sched]
+		if !ok {
+			si = len(schedules)
+			scheduleIndex[sched] = si
+			schedules = append(schedules, Schedule{Name: sched})
+		}
+		s := &schedules[si]
+		ai, ok := areaIndex[[2]string{sched, dir}]
+		if !ok {
+			ai = len(s.Areas)
+			areaIndex[[2]string{sched, dir}] = ai
+			s.Areas = append(s.Areas, Area{Dir: dir})
+		}
+		area := &s.Areas[ai]
+		key := fileKey{sched, dir, name}
+		fi, ok := fileIndex[key]
+		if !ok {
+			fi = len(area.Files)
+			fileIndex[key] = fi
+			area.Files = append(area.Files, File{Name: name})
+		}
+		f := &area.Files[fi]
+		if len(f.Changes) == 0 || f.Changes[len(f.Changes)-1] != verb {
+			f.Changes = append(f.Changes, verb)
+		}
+		if a.Diff != nil {
+			f.Added += a.Diff.TotalAdded
+			f.Removed += a.Diff.TotalRemoved
+		}
+	}
+
+	// Schedules in the order of the flow, the others after them
+	order := func(name string) int {
+		for id, n := range orchestrate.ScheduleNames {
+			if n == name {
+				return int(id)
+			}
+		}
+		return math.MaxInt
+	}
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return order(schedules[i].Name) < order(schedules[j].Name)
+	})
+	return schedules
+}
+
+// relative returns p relative to root, slash-separated, when under it.
+func relative(root, p string) string {
+	if root != "" && filepath.IsAbs(p) {
+		if rel, err := filepath.Rel(root, p); err == nil && filepath.IsLocal(rel) {
+			p = rel
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(p), "./")
+}
+
+// Markdown renders the entry as a second-level section.
+func (e *Entry) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s", e.Date.Format("2006-01-02"))
+	if e.Title != "" {
+		fmt.Fprintf(&b, " — %s", e.Title)
+	}
+	b.WriteString("\n\n")
+	if e.SessionID != "" || e.FlowCode != "" {
+		var meta []string
+		if e.SessionID != "" {
+			meta = append(meta, "Session `"+e.SessionID+"`")
+		}
+		if e.FlowCode != "" {
+			meta = append(meta, "flow `"+e.FlowCode+"`")
+		}
+		b.WriteString(strings.Join(meta, ", ") + ".\n\n")
+	}
+	if len(e.Schedules) == 0 {
+		b.WriteString("No files were changed.\n")
+		return b.String()
+	}
+	for i, s := range e.Schedules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n", s.Name)
+		for _, area := range s.Areas {
+			var files []string
+			for _, f := range area.Files {
+				item := fmt.Sprintf("`%s` %s", f.Name, strings.Join(f.Changes, ", "))
+				if f.Added > 0 || f.Removed > 0 {
+					item += fmt.Sprintf(" (+%d −%d)", f.Added, f.Removed)
+				}
+				files = append(files, item)
+			}
+			fmt.Fprintf(&b, "- **%s**: %s\n", area.Dir, strings.Join(files, "; "))
+		}
+	}
+	return b.String()
+}
+
+// Title returns the first line of a prompt, as an entry's title.
+func Title(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	return line
+}
+
+// Add adds the entry to the changelog file, newest first: after the
+// changelog's title and introduction, before its first entry. A missing
+// changelog is created with a "# Changelog" title.
+func Add(file string, e *Entry) error {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		data = []byte("# Changelog\n")
+	}
+	entry := e.Markdown()
+
+	// The entry goes before the first second-level heading
+	at := len(data)
+	for i := 0; i < len(data); {
+		if bytes.HasPrefix(data[i:], []byte("## ")) {
+			at = i
+			break
+		}
+		next := bytes.IndexByte(data[i:], '\n')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	head, tail := data[:at], data[at:]
+
+	var out bytes.Buffer
+	out.Write(bytes.TrimRight(head, "\n"))
+	out.WriteString("\n\n" + entry)
+	if len(tail) > 0 {
+		out.WriteString("\n")
+		out.Write(tail)
+	}
+	return os.WriteFile(file, out.Bytes(), 0644)
+}
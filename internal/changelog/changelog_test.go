@@ -0,0 +1,101 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/test"
+)
+
+func action(t agent.ActionType, schedule, path string, added, removed int) agent.Action {
+	a := agent.Action{Type: t, Path: path, Metadata: map[string]interface{}{"schedule": schedule, "status": "success"}}
+	if added > 0 || removed > 0 {
+		a.Diff = &agent.DiffSummary{TotalAdded: added, TotalRemoved: removed}
+	}
+	return a
+}
+
+func fixtureActions() []agent.Action {
+	rename := action(agent.ActionRenameFile, "Production", "/ws/internal/api/old.go", 0, 0)
+	rename.NewPath = "/ws/internal/api/legacy.go"
+	failed := action(agent.ActionEditFile, "Implement", "/ws/main.go", 5, 0)
+	failed.Metadata["status"] = "failed"
+	return []agent.Action{
+		action(agent.ActionCreateFile, "Implement", "/ws/internal/api/todo.go", 120, 0),
+		action(agent.ActionCreateFile, "Implement", "/ws/main.go", 30, 0),
+		action(agent.ActionRunCommand, "Implement", "", 0, 0),
+		action(agent.ActionEditFile, "Implement", "/ws/internal/api/todo.go", 4, 2),
+		failed,
+		action(agent.ActionCreateDir, "Implement", "/ws/internal/store", 0, 0),
+		action(agent.ActionEditFile, "Production", "/ws/README.md", 10, 1),
+		rename,
+		action(agent.ActionEditFile, "Plan", "/ws/docs/plan.md", 8, 0),
+	}
+}
+
+func TestGroup(t *testing.T) {
+	schedules := Group(fixtureActions(), "/ws")
+	var names []string
+	for _, s := range schedules {
+		names = append(names, s.Name)
+	}
+	if len(names) != 3 || names[0] != "Plan" || names[1] != "Implement" || names[2] != "Production" {
+		t.Fatalf("schedules = %v, want Plan, Implement, Production", names)
+	}
+	impl := schedules[1]
+	if len(impl.Areas) != 3 || impl.Areas[0].Dir != "internal/api" || impl.Areas[1].Dir != rootArea || impl.Areas[2].Dir != "internal" {
+		t.Fatalf("Implement areas = %+v", impl.Areas)
+	}
+	todo := impl.Areas[0].Files[0]
+	if todo.Name != "todo.go" || len(todo.Changes) != 2 || todo.Added != 124 || todo.Removed != 2 {
+		t.Errorf("todo.go = %+v, want added and edited, +124 -2", todo)
+	}
+	if main := impl.Areas[1].Files[0]; main.Added != 30 {
+		t.Errorf("main.go = %+v, want the failed edit left out", main)
+	}
+	if store := impl.Areas[2].Files[0]; store.Name != "store/" {
+		t.Errorf("internal/store = %+v, want a directory", store)
+	}
+}
+
+func TestEntry_Markdown_Golden(t *testing.T) {
+	e := &Entry{
+		Title:     Title("Build a REST API for todos\nwith SQLite"),
+		Date:      time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		SessionID: "sess-1",
+		FlowCode:  "S1P123S2P123S3P123S4P123S5P123",
+		Schedules: Group(fixtureActions(), "/ws"),
+	}
+	test.AssertGolden(t, "changelog_entry", []byte(e.Markdown()))
+}
+
+func TestAdd(t *testing.T) {
+	file := filepath.Join(t.TempDir(), FileName)
+	day := func(d int) *Entry {
+		return &Entry{Title: "Prompt", Date: time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)}
+	}
+
+	if err := Add(file, day(1)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(file)
+	want := "# Changelog\n\n## 2026-01-01 — Prompt\n\nNo files were changed.\n"
+	if string(got) != want {
+		t.Fatalf("new changelog =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := os.WriteFile(file, []byte("# Changes\n\nAll notable changes.\n\n## 2025-12-31\n\n- Older\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(file, day(2)); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = os.ReadFile(file)
+	want = "# Changes\n\nAll notable changes.\n\n## 2026-01-02 — Prompt\n\nNo files were changed.\n\n## 2025-12-31\n\n- Older\n"
+	if string(got) != want {
+		t.Errorf("changelog =\n%s\nwant\n%s", got, want)
+	}
+}
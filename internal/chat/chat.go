@@ -0,0 +1,208 @@
+// Package chat posts compact progress updates of an orchestration run to a
+// team chat, a Slack channel through an incoming webhook or a Matrix room,
+// so that a team can follow long autonomous runs.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/redact"
+)
+
+// Poster posts a message to a chat.
+type Poster interface {
+	// Name names the chat in warnings.
+	Name() string
+	Post(ctx context.Context, text string) error
+}
+
+// postTimeout bounds posting one message.
+const postTimeout = 10 * time.Second
+
+// Slack posts to a Slack channel through an incoming webhook.
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Name returns "slack".
+func (s *Slack) Name() string { return "slack" }
+
+// Post posts text to the webhook's channel.
+func (s *Slack) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	return send(ctx, s.Client, http.MethodPost, s.WebhookURL, "", body)
+}
+
+// Matrix posts to a Matrix room as the user of an access token.
+type Matrix struct {
+	Homeserver  string // such as https://matrix.org
+	RoomID      string // such as !abc123:matrix.org
+	AccessToken string
+	Client      *http.Client
+
+	mu  sync.Mutex
+	txn int
+}
+
+// Name returns "matrix".
+func (m *Matrix) Name() string { return "matrix" }
+
+// Post sends text to the room as an m.text message.
+func (m *Matrix) Post(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]string{"msgtype": "m.text", "body": text})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.txn++
+	txn := fmt.Sprintf("obot-%d-%d", time.Now().UnixNano(), m.txn)
+	m.mu.Unlock()
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimSuffix(m.Homeserver, "/"), url.PathEscape(m.RoomID), txn)
+	return send(ctx, m.Client, http.MethodPut, endpoint, m.AccessToken, body)
+}
+
+// send sends a JSON body, with a bearer token when it is not empty.
+func send(ctx context.Context, client *http.Client, method, endpoint, token string, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// queueSize is the number of messages held for posting; messages beyond it
+// are dropped rather than holding up the run.
+const queueSize = 64
+
+// closeTimeout bounds how long Close waits for the queued messages to be
+// posted; those still queued after it are dropped.
+const closeTimeout = 30 * time.Second
+
+// Notifier posts messages to its chats in order, in the background. A nil
+// Notifier posts nothing.
+type Notifier struct {
+	posters []Poster
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan string
+	done   chan struct{}
+	ctx    context.Context // cancelled to drop the queued messages
+	cancel context.CancelFunc
+}
+
+// New returns a notifier posting to posters, or nil when there are none.
+func New(posters ...Poster) *Notifier {
+	if len(posters) == 0 {
+		return nil
+	}
+	n := &Notifier{
+		posters: posters,
+		queue:   make(chan string, queueSize),
+		done:    make(chan struct{}),
+	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	go n.run()
+	return n
+}
+
+// Post queues text for posting, with any secrets in it redacted.
+func (n *Notifier) Post(text string) {
+	if n == nil {
+		return
+	}
+	text = redact.String("chat", text)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	select {
+	case n.queue <- text:
+	default:
+		slog.Warn("chat queue full, dropping progress update")
+	}
+}
+
+// Close stops taking messages and waits for the queued ones to be posted,
+// for up to closeTimeout.
+func (n *Notifier) Close() {
+	n.stop(closeTimeout)
+}
+
+// Abort stops taking messages, drops the queued ones, and cancels the one
+// being posted, for a run that must exit now.
+func (n *Notifier) Abort() {
+	n.stop(0)
+}
+
+// stop stops taking messages and waits up to wait for the queued ones to be
+// posted before dropping the rest.
+func (n *Notifier) stop(wait time.Duration) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-n.done:
+			return
+		case <-timer.C:
+			slog.Warn("chat posting timed out, dropping queued progress updates")
+		}
+	}
+	n.cancel()
+	<-n.done
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+	for text := range n.queue {
+		if n.ctx.Err() != nil {
+			continue
+		}
+		for _, p := range n.posters {
+			if err := p.Post(n.ctx, text); err != nil {
+				slog.Warn("chat post failed", "chat", p.Name(), "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// recorder records the requests made to it.
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []map[string]string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data, _ := io.ReadAll(req.Body)
+	var body map[string]string
+	_ = json.Unmarshal(data, &body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+}
+
+func TestSlack_Post(t *testing.T) {
+	var r recorder
+	srv := httptest.NewServer(&r)
+	defer srv.Close()
+
+	s := &Slack{WebhookURL: srv.URL + "/hook"}
+	if err := s.Post(context.Background(), "hello"); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if len(r.requests) != 1 || r.requests[0].Method != http.MethodPost || r.requests[0].URL.Path != "/hook" || r.bodies[0]["text"] != "hello" {
+		t.Errorf("requests %v, bodies %v", r.requests, r.bodies)
+	}
+}
+
+func TestMatrix_Post(t *testing.T) {
+	var r recorder
+	srv := httptest.NewServer(&r)
+	defer srv.Close()
+
+	m := &Matrix{Homeserver: srv.URL + "/", RoomID: "!room:example.org", AccessToken: "tok"}
+	ctx := context.Background()
+	if err := m.Post(ctx, "one"); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if err := m.Post(ctx, "two"); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if len(r.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(r.requests))
+	}
+	req := r.requests[0]
+	if req.Method != http.MethodPut || req.Header.Get("Authorization") != "Bearer tok" {
+		t.Errorf("request %s with %q, want a PUT with the token", req.Method, req.Header.Get("Authorization"))
+	}
+	prefix := "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/"
+	if !strings.HasPrefix(req.URL.Path, prefix) || r.requests[1].URL.Path == req.URL.Path {
+		t.Errorf("paths %q and %q, want distinct transactions under %s", req.URL.Path, r.requests[1].URL.Path, prefix)
+	}
+	if r.bodies[0]["msgtype"] != "m.text" || r.bodies[0]["body"] != "one" {
+		t.Errorf("body = %v", r.bodies[0])
+	}
+}
+
+func TestSlack_PostError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	if err := (&Slack{WebhookURL: srv.URL}).Post(context.Background(), "x"); err == nil {
+		t.Error("Post succeeded against a 403")
+	}
+}
+
+// posts records the messages posted to it.
+type posts struct{ texts []string }
+
+func (p *posts) Name() string { return "test" }
+
+func (p *posts) Post(ctx context.Context, text string) error {
+	p.texts = append(p.texts, text)
+	return nil
+}
+
+func TestPlugin(t *testing.T) {
+	var got posts
+	flow := "S1P123"
+	p := NewPlugin(New(&got), func() string { return flow })
+
+	ctx := context.Background()
+	p.OnScheduleStart(ctx, orchestrate.SchedulePlan)
+	p.OnProcessEnd(ctx, orchestrate.SchedulePlan, orchestrate.Process1)
+	flow = "S1P123S2P123"
+	p.Complete("Add a login page\nwith OAuth", "QUALITY ASSESSMENT: GOOD\n")
+	p.Close()
+
+	want := []string{
+		"▶ obot · Plan · flow S1P123",
+		"✓ obot · complete · flow S1P123S2P123\n> Add a login page\n```\nQUALITY ASSESSMENT: GOOD\n```",
+	}
+	if len(got.texts) != len(want) {
+		t.Fatalf("posted %q, want %q", got.texts, want)
+	}
+	for i := range want {
+		if got.texts[i] != want[i] {
+			t.Errorf("post %d = %q, want %q", i, got.texts[i], want[i])
+		}
+	}
+
+	var none *Plugin
+	none.Complete("prompt", "")
+	none.Close()
+	none.Abort()
+	if New() != nil {
+		t.Error("New without posters returned a notifier")
+	}
+}
+
+// blocked is a chat whose posts wait for their context to end.
+type blocked struct{ started chan struct{} }
+
+func (b *blocked) Name() string { return "blocked" }
+
+func (b *blocked) Post(ctx context.Context, text string) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestNotifier_AbortDropsQueue(t *testing.T) {
+	b := &blocked{started: make(chan struct{}, 1)}
+	n := New(b)
+	for i := 0; i < 10; i++ {
+		n.Post("update")
+	}
+	<-b.started
+	done := make(chan struct{})
+	go func() {
+		n.Abort()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Abort() waited for the queued updates")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	long := strings.Repeat("é", maxPrompt+10)
+	if q := quote(long); len([]rune(q)) != maxPrompt || !strings.HasSuffix(q, "…") {
+		t.Errorf("quote of a long prompt = %q", q)
+	}
+}
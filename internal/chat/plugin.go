@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// maxPrompt is the longest prompt quoted in a message, in runes.
+const maxPrompt = 120
+
+// Plugin is an orchestrator plugin that posts each schedule transition with
+// the flow code so far, and the run's end with its TLDR.
+type Plugin struct {
+	*orchestrate.BaseOrchestratorPlugin
+
+	n        *Notifier
+	flowCode func() string
+}
+
+// NewPlugin returns a plugin posting through n, reading the flow code from
+// flowCode.
+func NewPlugin(n *Notifier, flowCode func() string) *Plugin {
+	return &Plugin{
+		BaseOrchestratorPlugin: orchestrate.NewBaseOrchestratorPlugin("chat"),
+		n:                      n,
+		flowCode:               flowCode,
+	}
+}
+
+// OnScheduleStart posts the transition to a schedule.
+func (p *Plugin) OnScheduleStart(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	p.n.Post(fmt.Sprintf("▶ obot · %s · flow %s", scheduleID, p.flowCode()))
+	return nil
+}
+
+// Complete posts the end of the run of prompt with its TLDR, which may be
+// empty.
+func (p *Plugin) Complete(prompt, tldr string) {
+	if p == nil {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "✓ obot · complete · flow %s\n> %s", p.flowCode(), quote(prompt))
+	if tldr = strings.TrimSpace(tldr); tldr != "" {
+		fmt.Fprintf(&b, "\n```\n%s\n```", tldr)
+	}
+	p.n.Post(b.String())
+}
+
+// Fail posts the failure of the run.
+func (p *Plugin) Fail(err error) {
+	if p == nil {
+		return
+	}
+	p.n.Post(fmt.Sprintf("✗ obot · failed · flow %s\n%s", p.flowCode(), err))
+}
+
+// Close waits for the updates to be posted, for a bounded time.
+func (p *Plugin) Close() {
+	if p == nil {
+		return
+	}
+	p.n.Close()
+}
+
+// Abort drops the updates not yet posted.
+func (p *Plugin) Abort() {
+	if p == nil {
+		return
+	}
+	p.n.Abort()
+}
+
+// quote returns the first line of prompt, shortened to maxPrompt runes.
+func quote(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	if r := []rune(line); len(r) > maxPrompt {
+		line = string(r[:maxPrompt-1]) + "…"
+	}
+	return line
+}
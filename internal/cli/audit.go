@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/audit"
+)
+
+var (
+	auditLogPath      string
+	auditExportFormat string
+	auditExportOutput string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of agent actions",
+	Long: `Every file write, delete, and command the agent runs is appended to a
+hash-chained audit log in .obot/audit.jsonl of the workspace. Use these
+commands to verify the log has not been tampered with and to export it for
+compliance review.`,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the audit log hash chain",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveAuditLogPath()
+		if err != nil {
+			return err
+		}
+		entries, err := audit.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read audit log: %w", err)
+		}
+		if err := audit.Verify(entries); err != nil {
+			return err
+		}
+		printSuccess(fmt.Sprintf("Audit log %s is intact (%d entries)", path, len(entries)))
+		return nil
+	},
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the audit log as JSON or CSV",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveAuditLogPath()
+		if err != nil {
+			return err
+		}
+		entries, err := audit.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read audit log: %w", err)
+		}
+		if err := audit.Verify(entries); err != nil {
+			printWarning(fmt.Sprintf("Exporting a log that fails verification: %v", err))
+		}
+
+		var w io.Writer = os.Stdout
+		if auditExportOutput != "" {
+			f, err := os.Create(auditExportOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := audit.Export(w, entries, auditExportFormat); err != nil {
+			return err
+		}
+		if auditExportOutput != "" {
+			printSuccess(fmt.Sprintf("Exported %d audit entries to %s", len(entries), auditExportOutput))
+		}
+		return nil
+	},
+}
+
+// resolveAuditLogPath returns the --log path or the current workspace's log.
+func resolveAuditLogPath() (string, error) {
+	if auditLogPath != "" {
+		return auditLogPath, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return audit.DefaultPath(wd), nil
+}
+
+func init() {
+	auditCmd.PersistentFlags().StringVar(&auditLogPath, "log", "", "Audit log file (default: .obot/audit.jsonl in the current directory)")
+	auditExportCmd.Flags().StringVar(&auditExportFormat, "format", "json", "Export format: json|csv")
+	auditExportCmd.Flags().StringVarP(&auditExportOutput, "output", "o", "", "Write the export to a file instead of stdout")
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	rootCmd.AddCommand(auditCmd)
+}
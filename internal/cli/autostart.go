@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// ensureOllama starts `ollama serve` for client when --start-ollama or
+// ollama.auto_start is set and the server is not running. The returned stop
+// function stops a server it started if ollama.stop_on_exit is set, and
+// otherwise leaves it running; callers defer it. A server that cannot be
+// started is reported as ErrOllamaUnavailable.
+func ensureOllama(ctx context.Context, client *ollama.Client) (stop func(), err error) {
+	o := config.DefaultUnifiedConfig().Ollama
+	if cfg != nil && cfg.Unified != nil {
+		o = cfg.Unified.Ollama
+	}
+	stop = func() {}
+	if !startOllama && !o.AutoStart {
+		return stop, nil
+	}
+
+	// The server inherits the log file, which can be closed once it starts
+	var log io.Writer = io.Discard
+	if f, err := os.OpenFile(filepath.Join(logDir(), "ollama-serve.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err == nil {
+		defer f.Close()
+		log = f
+	}
+	server, err := client.EnsureServer(ctx, ollama.ServerOptions{
+		Timeout: time.Duration(o.StartSeconds) * time.Second,
+		Log:     log,
+	})
+	if err != nil {
+		oe := errs.New(errs.ErrOllamaUnavailable, "Ollama", err.Error(), errs.FrozenState{})
+		oe.Cause = err
+		return nil, oe
+	}
+	if server == nil {
+		return stop, nil
+	}
+
+	printInfo(fmt.Sprintf("Started ollama serve (pid %d) at %s", server.Pid(), client.BaseURL()))
+	if o.StopOnExit {
+		stop = server.Stop
+	}
+	return stop, nil
+}
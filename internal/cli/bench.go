@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/bench"
+	"github.com/croberts/obot/internal/fixer"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	"github.com/croberts/obot/internal/tier"
+)
+
+var (
+	benchMaxTokens int
+	benchTiers     bool
+)
+
+// benchResults are the stored benchmarks of this machine, nil when no model
+// was benchmarked.
+var benchResults *bench.Results
+
+// fixNoteTokens is about how long the plan and review answers of a fix are.
+const fixNoteTokens = 150
+
+// fixETA estimates how long a fix of targetTokens takes model on this
+// machine, from its benchmark. Each fix pass generates the target again;
+// above the fast preset a plan comes first and a review after each pass,
+// and the thorough preset may take a second pass.
+func fixETA(model string, quality fixer.QualityPreset, targetTokens int) (time.Duration, bool) {
+	fix, ok := benchResults.Estimate(model, targetTokens)
+	if !ok {
+		return 0, false
+	}
+	if quality == fixer.QualityFast {
+		return fix, true
+	}
+	note, _ := benchResults.Estimate(model, fixNoteTokens)
+	passes := 1
+	if quality == fixer.QualityThorough {
+		passes = 2
+	}
+	return note + time.Duration(passes)*(fix+note), true
+}
+
+// typicalDuration returns how long a process usually takes model: the
+// average of its past runs by model, or when model has none, of its runs
+// by another model scaled by the two models' benchmarked speeds.
+func typicalDuration(profiles *resource.Profiles, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, model string) (time.Duration, bool) {
+	if prof, ok := profiles.Get(schedID, procID, model); ok && prof.AverageDuration() > 0 {
+		return prof.AverageDuration(), true
+	}
+	if benchResults == nil {
+		return 0, false
+	}
+	current, ok := benchResults.Models[model]
+	if !ok || current.TokensPerSecond <= 0 {
+		return 0, false
+	}
+	var best resource.ProcessProfile
+	for _, prof := range profiles.ForProcess(schedID, procID) {
+		other, ok := benchResults.Models[prof.Model]
+		if ok && other.TokensPerSecond > 0 && len(prof.Durations) > len(best.Durations) {
+			best = prof
+		}
+	}
+	if len(best.Durations) == 0 {
+		return 0, false
+	}
+	scale := benchResults.Models[best.Model].TokensPerSecond / current.TokensPerSecond
+	return time.Duration(float64(best.AverageDuration()) * scale), true
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [model...]",
+	Short: "Benchmark models on this machine",
+	Long: `Benchmark models on this machine: generation speed (tokens/sec), time to
+first token, load time, and the memory the loaded model takes, across a few
+canned prompts.
+
+Without models, benchmarks the active model and the models configured for
+each role; --tiers adds each RAM tier's coder model that is installed.
+
+Results are stored in ~/.config/ollamabot/bench.json. obot uses them to
+estimate how long a fix takes, to scale how long orchestration processes
+take from past runs by another model, and to step the RAM tier down when
+its model runs too slow or large here, or up when the next tier's model runs
+well.`,
+	RunE: runBench,
+}
+
+func init() {
+	benchCmd.Flags().IntVar(&benchMaxTokens, "tokens", bench.DefaultMaxTokens, "Tokens to generate for each prompt")
+	benchCmd.Flags().BoolVar(&benchTiers, "tiers", false, "Also benchmark each RAM tier's installed coder model")
+	rootCmd.AddCommand(benchCmd)
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	printBanner()
+	ctx := cmd.Context()
+	installed, err := client.ListModels(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot connect to Ollama: %v\nIs Ollama running? Start with: ollama serve", err)
+	}
+	names := make([]string, len(installed))
+	for i, m := range installed {
+		names[i] = m.Name
+	}
+
+	models := args
+	if len(models) == 0 {
+		models = benchModels(names)
+	}
+	// Stored benchmarks of another machine sharing the config were not
+	// loaded, so they are replaced
+	results := benchResults
+	if results == nil {
+		results = &bench.Results{RAMGB: tierManager.SystemInfo.RAMGB}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %-28s %10s %10s %10s %12s\n", "Model", "tok/s", "TTFT", "Load", "Memory")
+	benchmarked := 0
+	for _, model := range models {
+		if !slices.Contains(names, model) && !slices.Contains(names, model+":latest") {
+			printWarning(fmt.Sprintf("%s is not installed; install with: ollama pull %s", model, model))
+			continue
+		}
+		result, err := bench.Run(ctx, client.BaseURL(), model, benchMaxTokens)
+		if err != nil {
+			printWarning(fmt.Sprintf("%s: %v", model, err))
+			continue
+		}
+		results.Add(*result)
+		benchmarked++
+		fmt.Printf("  %s %10.1f %10s %10s %12s\n", cyan(fmt.Sprintf("%-28s", model)), result.TokensPerSecond,
+			result.TimeToFirstToken.Round(time.Millisecond), result.LoadTime.Round(time.Millisecond), formatBenchMemory(result))
+	}
+	fmt.Println()
+	if benchmarked == 0 {
+		return fmt.Errorf("no model benchmarked")
+	}
+	if err := results.Save(bench.DefaultPath()); err != nil {
+		return fmt.Errorf("failed to save benchmarks: %v", err)
+	}
+	printSuccess(fmt.Sprintf("Saved %d benchmarks to %s", benchmarked, bench.DefaultPath()))
+
+	detected := tierManager.SystemInfo.DetectedTier
+	if calibrated := results.CalibrateTier(detected, results.RAMGB); calibrated != detected {
+		printInfo(fmt.Sprintf("Tier: %s (detected %s; calibrated by the benchmarks)", yellow(calibrated.DisplayName()), detected.DisplayName()))
+	} else {
+		printInfo(fmt.Sprintf("Tier: %s", yellow(detected.DisplayName())))
+	}
+	return nil
+}
+
+// benchModels returns the models benchmarked by default: the active model,
+// the models the unified config gives each role at the detected tier, and
+// with --tiers every tier's coder model among the installed ones.
+func benchModels(installed []string) []string {
+	models := []string{tierManager.GetActiveModel()}
+	if cfg != nil && cfg.Unified != nil {
+		for _, role := range []string{"orchestrator", "coder", "researcher", "vision"} {
+			models = append(models, cfg.Unified.GetModelForRole(role, string(tierManager.SystemInfo.DetectedTier)))
+		}
+	}
+	if benchTiers {
+		for _, t := range tier.Tiers {
+			if tag := tier.GetModelForTier(t.Tier).OllamaTag; slices.Contains(installed, tag) {
+				models = append(models, tag)
+			}
+		}
+	}
+	var unique []string
+	for _, m := range models {
+		if m != "" && !slices.Contains(unique, m) {
+			unique = append(unique, m)
+		}
+	}
+	return unique
+}
+
+// formatBenchMemory formats the memory a loaded model takes and how much of
+// it is in GPU memory.
+func formatBenchMemory(r *bench.Result) string {
+	if r.MemoryBytes == 0 {
+		return "-"
+	}
+	gb := func(b int64) string { return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(b)/(1<<30)), ".0") }
+	if r.VRAMBytes > 0 && r.VRAMBytes < r.MemoryBytes {
+		return fmt.Sprintf("%s GB (%s GPU)", gb(r.MemoryBytes), gb(r.VRAMBytes))
+	}
+	return gb(r.MemoryBytes) + " GB"
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/bench"
+	"github.com/croberts/obot/internal/fixer"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+)
+
+func TestFixETA(t *testing.T) {
+	saved := benchResults
+	t.Cleanup(func() { benchResults = saved })
+	benchResults = &bench.Results{Models: map[string]bench.Result{
+		"coder": {Model: "coder", TokensPerSecond: 50, TimeToFirstToken: time.Second},
+	}}
+
+	tests := []struct {
+		quality fixer.QualityPreset
+		want    time.Duration
+	}{
+		{fixer.QualityFast, 3 * time.Second},      // the fix: 1s + 100 tokens at 50/s
+		{fixer.QualityBalanced, 11 * time.Second}, // plan, fix, and review of 4s each for the notes
+		{fixer.QualityThorough, 18 * time.Second}, // plan, then the fix and review twice
+	}
+	for _, tt := range tests {
+		if got, ok := fixETA("coder", tt.quality, 100); !ok || got != tt.want {
+			t.Errorf("fixETA(%s) = %s, %v, want %s", tt.quality, got, ok, tt.want)
+		}
+	}
+	if _, ok := fixETA("other", fixer.QualityFast, 100); ok {
+		t.Error("estimated a model that was not benchmarked")
+	}
+}
+
+func TestTypicalDuration(t *testing.T) {
+	saved := benchResults
+	t.Cleanup(func() { benchResults = saved })
+	benchResults = &bench.Results{Models: map[string]bench.Result{
+		"small": {Model: "small", TokensPerSecond: 40},
+		"large": {Model: "large", TokensPerSecond: 10},
+	}}
+	profiles := resource.NewProfiles("")
+	profiles.Record(orchestrate.ScheduleImplement, orchestrate.Process1, "small", 1, 2*time.Minute)
+
+	if got, ok := typicalDuration(profiles, orchestrate.ScheduleImplement, orchestrate.Process1, "small"); !ok || got != 2*time.Minute {
+		t.Errorf("typical duration = %s, %v, want the past runs' 2m", got, ok)
+	}
+	if got, ok := typicalDuration(profiles, orchestrate.ScheduleImplement, orchestrate.Process1, "large"); !ok || got != 8*time.Minute {
+		t.Errorf("typical duration by a 4× slower model = %s, %v, want 8m", got, ok)
+	}
+	if _, ok := typicalDuration(profiles, orchestrate.ScheduleImplement, orchestrate.Process1, "unbenchmarked"); ok {
+		t.Error("calibrated a model that was not benchmarked")
+	}
+	if _, ok := typicalDuration(profiles, orchestrate.ScheduleImplement, orchestrate.Process2, "small"); ok {
+		t.Error("estimated a process without past runs")
+	}
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/changelog"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// writeChangelog adds an entry for the prompt's file changes to the
+// CHANGELOG.md of the workspace the agent worked in, for --changelog.
+func writeChangelog(orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session) {
+	if !orchChangelog {
+		return
+	}
+	workspace, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write changelog: "+err.Error()))
+		return
+	}
+	entry := &changelog.Entry{
+		Title:     changelog.Title(orch.GetPrompt()),
+		Date:      time.Now(),
+		SessionID: sess.GetID(),
+		FlowCode:  orch.GetFlowCode(),
+		Schedules: changelog.Group(ag.GetActions(), workspace),
+	}
+	path := filepath.Join(workspace, changelog.FileName)
+	if err := changelog.Add(path, entry); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write changelog: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Changelog"), ui.FormatBullet()+ui.FormatValue(path))
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/croberts/obot/internal/chat"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// runChat posts the progress of an orchestration to the configured team
+// chats; nil when there are none.
+var runChat atomic.Pointer[chat.Plugin]
+
+// setupChat creates runChat for the chats of the config and registers it
+// with orch.
+func setupChat(orch *orchestrate.Orchestrator) error {
+	if cfg == nil || cfg.Unified == nil {
+		return nil
+	}
+	c := cfg.Unified.Chat
+	client := &http.Client{}
+	var posters []chat.Poster
+	if c.Slack.WebhookURL != "" {
+		posters = append(posters, &chat.Slack{WebhookURL: configSecret(c.Slack.WebhookURL), Client: client})
+	}
+	if c.Matrix.Homeserver != "" || c.Matrix.RoomID != "" {
+		if c.Matrix.Homeserver == "" || c.Matrix.RoomID == "" || c.Matrix.AccessToken == "" {
+			return fmt.Errorf("chat.matrix needs homeserver, room_id, and access_token")
+		}
+		posters = append(posters, &chat.Matrix{
+			Homeserver:  c.Matrix.Homeserver,
+			RoomID:      c.Matrix.RoomID,
+			AccessToken: configSecret(c.Matrix.AccessToken),
+			Client:      client,
+		})
+	}
+	n := chat.New(posters...)
+	if n == nil {
+		return nil
+	}
+	runChat.Store(chat.NewPlugin(n, orch.GetFlowCode))
+	orch.RegisterPlugin(runChat.Load())
+	return nil
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+)
+
+// maxProgressChars bounds the command output shown on the agent status line.
+const maxProgressChars = 80
+
+// commandProgress describes a running command for the agent status line:
+// the command itself, then its latest output line.
+func commandProgress(command string, line agent.OutputLine) string {
+	text := "Running " + command
+	if out := strings.TrimSpace(line.Text); out != "" {
+		text = command + ": " + out
+		if line.Stream == agent.StreamStderr {
+			text = command + " (stderr): " + out
+		}
+	}
+	if r := []rune(text); len(r) > maxProgressChars {
+		text = string(r[:maxProgressChars-3]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/judge"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// Consultation flags of the orchestrate command; -1 leaves the timeout to
+// the config.
+var (
+	orchClarifyTimeout  int
+	orchFeedbackTimeout int
+	orchNoAISubstitute  bool
+	orchSubPersona      string
+)
+
+// consultationTimeouts returns the configured timeouts of a kind of
+// consultation, Clarify or Feedback.
+func consultationTimeouts(kind consultation.ConsultationType) config.ConsultationTimeouts {
+	consult := config.DefaultUnifiedConfig().Orchestration.Consultation
+	if cfg != nil && cfg.Unified != nil {
+		consult = cfg.Unified.Orchestration.Consultation
+	}
+	if kind == consultation.ConsultationClarify {
+		return consult.Clarify
+	}
+	return consult.Feedback
+}
+
+// substitutePersona returns the AI substitute's persona: the
+// --substitute-persona flag, or orchestration.consultation.substitute_persona.
+func substitutePersona() consultation.Persona {
+	if orchSubPersona != "" {
+		return consultation.Persona(orchSubPersona)
+	}
+	if cfg != nil && cfg.Unified != nil {
+		return consultation.Persona(cfg.Unified.Orchestration.Consultation.SubstitutePersona)
+	}
+	return consultation.PersonaPermissive
+}
+
+// consultationConfig returns the handler config of a kind of consultation:
+// its configured timeouts, with the interactive timeout when reading from a
+// terminal, overridden by the --clarify-timeout, --feedback-timeout, and
+// --no-ai-substitute flags, and the AI substitute's persona and policy.
+// Its waits are charged to the human.
+func consultationConfig(kind consultation.ConsultationType) *consultation.Config {
+	t := consultationTimeouts(kind)
+	timeout := t.Timeout(term.IsTerminal(os.Stdin))
+	switch {
+	case kind == consultation.ConsultationClarify && orchClarifyTimeout >= 0:
+		timeout = orchClarifyTimeout
+	case kind == consultation.ConsultationFeedback && orchFeedbackTimeout >= 0:
+		timeout = orchFeedbackTimeout
+	}
+	return &consultation.Config{
+		TimeoutSeconds:   timeout,
+		CountdownSeconds: t.CountdownSeconds,
+		AllowAISub:       t.AISubstitute && !orchNoAISubstitute,
+		Persona:          substitutePersona(),
+		Policy:           t.SubstitutePolicy,
+		Notifier:         runNotifier.Load(),
+		OnRequest:        openConsultation,
+		OnWait:           closeConsultation,
+	}
+}
+
+// historyEmbedTimeout bounds embedding a consultation question for the
+// history, so a missing embedding model does not hold up the question.
+const historyEmbedTimeout = 10 * time.Second
+
+// consultationHistory opens the consultation history of the workspace,
+// matching questions by the embeddings of the researcher model when there
+// is one. It returns nil, for no history, when the history cannot be read.
+func consultationHistory(researcher *ollama.Client) *consultation.History {
+	workspace, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	var embed consultation.EmbedFunc
+	if researcher != nil {
+		embed = func(ctx context.Context, text string) ([]float64, error) {
+			ctx, cancel := context.WithTimeout(ctx, historyEmbedTimeout)
+			defer cancel()
+			return researcher.Embeddings(ctx, researcher.GetModel(), text)
+		}
+	}
+	history, err := consultation.OpenHistory(consultation.HistoryPath(workspace), embed)
+	if err != nil {
+		slog.Warn("consultation history unavailable", "error", err)
+		return nil
+	}
+	return history
+}
+
+// newConsultationHandler returns a handler asking the human at the
+// terminal for a kind of consultation, recording the answers in sess when
+// it is not nil and offering earlier answers from history, which may be
+// nil. The AI substitute answers with the orchestrator model of models,
+// charging its tokens to the model's quota, or with canned answers when
+// models is nil. It reads the answers through in, the run's one buffered
+// reader of stdin, so that input typed ahead is not lost between prompts.
+func newConsultationHandler(kind consultation.ConsultationType, sess *orchsession.Session, history *consultation.History, models *model.Coordinator, in *bufio.Reader) *consultation.Handler {
+	config := consultationConfig(kind)
+	config.Buffered = in
+	config.History = history
+	if models != nil {
+		substitute := models.Get(orchestrate.ModelOrchestrator)
+		config.AIModel = substitute
+		config.OnTokens = func(tokens int64) { models.RecordClientTokens(substitute, tokens) }
+	}
+	if sess != nil {
+		config.OnAnswer = func(req consultation.Request, resp *consultation.Response) {
+			sess.RecordConsultation(consultationRecord(req, resp))
+		}
+	}
+	return consultation.NewHandler(os.Stdin, os.Stdout, config)
+}
+
+// feedbackRisks returns why the changes up for Feedback are high risk: the
+// planner's high-risk subtasks, and the security expert's issues when the
+// judges already reviewed the session at a quality gate.
+func feedbackRisks(orch *orchestrate.Orchestrator, sess *orchsession.Session) []string {
+	var risks []string
+	for _, n := range orch.GetNotes(orchestrate.NoteFilter{Types: []orchestrate.NoteType{orchestrate.NoteRisk}, Source: "planner"}) {
+		if len(n.Tags) > 0 { // a subtask's, not plan drift
+			risks = append(risks, n.Content)
+		}
+	}
+	if analysis := sessionAnalysis(sess); analysis != nil {
+		if security := analysis.Experts[string(judge.ExpertSecurity)]; security != nil && security.Report != nil {
+			for _, issue := range security.Report.Issues {
+				risks = append(risks, "Security: "+issue)
+			}
+		}
+	}
+	return risks
+}
+
+// consultationRecord returns the session record of a consultation's answer,
+// with the option picked rather than the raw text when it offered options.
+func consultationRecord(req consultation.Request, resp *consultation.Response) orchsession.ConsultationRecord {
+	rec := orchsession.ConsultationRecord{
+		Type:      string(req.Type),
+		Question:  req.Question,
+		Options:   req.Options,
+		Answer:    resp.Content,
+		Source:    string(resp.Source),
+		Persona:   resp.Persona,
+		Timestamp: resp.Timestamp,
+	}
+	if c := resp.Choice; c != nil {
+		rec.Choice = &orchsession.ConsultationChoice{Key: c.Key, Option: c.Option, Rationale: c.Rationale}
+	}
+	return rec
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/judge"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestConsultationConfig(t *testing.T) {
+	oldClarify, oldFeedback, oldNoSub, oldPersona := orchClarifyTimeout, orchFeedbackTimeout, orchNoAISubstitute, orchSubPersona
+	t.Cleanup(func() {
+		orchClarifyTimeout, orchFeedbackTimeout, orchNoAISubstitute, orchSubPersona = oldClarify, oldFeedback, oldNoSub, oldPersona
+	})
+	orchClarifyTimeout, orchFeedbackTimeout, orchNoAISubstitute, orchSubPersona = -1, -1, false, ""
+
+	clarify := consultationConfig(consultation.ConsultationClarify)
+	if clarify.TimeoutSeconds != 60 || clarify.CountdownSeconds != 15 || !clarify.AllowAISub {
+		t.Errorf("clarify config = %+v, want the 60s default with an AI substitute", clarify)
+	}
+	if feedback := consultationConfig(consultation.ConsultationFeedback); feedback.TimeoutSeconds != 300 {
+		t.Errorf("feedback timeout = %d, want 300 when not at a terminal", feedback.TimeoutSeconds)
+	}
+
+	if clarify.Persona != consultation.PersonaPermissive {
+		t.Errorf("persona = %q, want the permissive default", clarify.Persona)
+	}
+
+	orchFeedbackTimeout, orchNoAISubstitute = 0, true
+	feedback := consultationConfig(consultation.ConsultationFeedback)
+	if feedback.TimeoutSeconds != 0 || feedback.AllowAISub {
+		t.Errorf("feedback config = %+v, want no limit and no AI substitute from the flags", feedback)
+	}
+}
+
+func TestConsultationRecord(t *testing.T) {
+	req := consultation.FormatClarifyRequest("Caching", "Cache key", []string{"By user", "By session"})
+	resp := &consultation.Response{
+		Content: "B: sessions expire",
+		Source:  consultation.ResponseSourceHuman,
+		Choice:  &consultation.Choice{Key: "B", Option: "By session", Rationale: "sessions expire"},
+	}
+	rec := consultationRecord(req, resp)
+	if rec.Type != "clarify" || rec.Source != "human" || len(rec.Options) != 2 {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.Choice == nil || rec.Choice.Key != "B" || rec.Choice.Rationale != "sessions expire" {
+		t.Errorf("choice = %+v, want B with its rationale", rec.Choice)
+	}
+
+	sub := consultationRecord(consultation.Request{Type: consultation.ConsultationFeedback}, &consultation.Response{
+		Content: "Not approved",
+		Source:  consultation.ResponseSourceAISubstitute,
+		Persona: "conservative",
+	})
+	if sub.Source != "ai_substitute" || sub.Persona != "conservative" {
+		t.Errorf("substitute record = %+v, want it tagged with its persona", sub)
+	}
+}
+
+func TestFeedbackRisks(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+	if risks := feedbackRisks(orch, sess); len(risks) != 0 {
+		t.Errorf("risks = %q, want none", risks)
+	}
+
+	orch.AddTypedNote(orchestrate.NoteRisk, "Subtask [T-002] is high risk: changes auth", "planner", "T-002")
+	orch.AddTypedNote(orchestrate.NoteRisk, "Plan drift: 3 files changed off the plan", "planner")
+	data, _ := json.Marshal(&judge.Analysis{Experts: map[string]*judge.ExpertAnalysis{
+		"security": {Expert: judge.ExpertSecurity, Report: &judge.ExpertReport{Issues: []string{"high: token logged"}}},
+	}})
+	sess.SetAnalysis(data, "")
+
+	risks := feedbackRisks(orch, sess)
+	if len(risks) != 2 || risks[0] != "Subtask [T-002] is high risk: changes auth" || risks[1] != "Security: high: token logged" {
+		t.Errorf("risks = %q, want the high-risk subtask and the security issue", risks)
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+var (
+	chatRun  int
+	chatFull bool
+)
+
+var sessionChatCmd = &cobra.Command{
+	Use:   "chat <session-id> <SnPn>",
+	Short: "Read the agent's conversation in a process of a session",
+	Long: `Read what the agent sent its models during a process, what they replied,
+and the actions it took, run by run, to find out why a process went wrong.
+The process is given as in the flow code: S3P1 is Implement's first process.
+
+Examples:
+  obot session chat 1712345678 S3P1
+  obot session chat 1712345678 S3P2 --run 2 --full`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedID, procID, err := parseProcessRef(args[1])
+		if err != nil {
+			return err
+		}
+		baseDir := orchsession.DefaultBaseDir()
+		store, err := newSessionStorage(baseDir)
+		if err != nil {
+			return err
+		}
+		s, err := orchsession.LoadWithStorage(baseDir, store, args[0])
+		if err != nil {
+			return fmt.Errorf("load session: %w", err)
+		}
+		runs := s.GetConversations(schedID, procID)
+		if len(runs) == 0 {
+			printInfo(fmt.Sprintf("No conversation recorded for %s in session %s.", strings.ToUpper(args[1]), args[0]))
+			return nil
+		}
+		if chatRun > len(runs) {
+			return fmt.Errorf("--run %d: %s ran %d times", chatRun, strings.ToUpper(args[1]), len(runs))
+		}
+		printConversations(os.Stdout, runs, chatRun, chatFull)
+		return nil
+	},
+}
+
+func init() {
+	sessionChatCmd.Flags().IntVar(&chatRun, "run", 0, "Only the nth run of the process")
+	sessionChatCmd.Flags().BoolVar(&chatFull, "full", false, "Show whole messages instead of their first lines")
+	usfSessionCmd.AddCommand(sessionChatCmd)
+}
+
+// processRefPattern matches a process as written in a flow code, like S3P1.
+var processRefPattern = regexp.MustCompile(`^[Ss](\d+)[Pp](\d+)$`)
+
+// parseProcessRef parses a process written as in a flow code, like S3P1.
+func parseProcessRef(ref string) (orchestrate.ScheduleID, orchestrate.ProcessID, error) {
+	m := processRefPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return 0, 0, fmt.Errorf("process %q: want a schedule and process like S3P1", ref)
+	}
+	s, _ := strconv.Atoi(m[1])
+	p, _ := strconv.Atoi(m[2])
+	schedID, procID := orchestrate.ScheduleID(s), orchestrate.ProcessID(p)
+	if _, ok := orchestrate.ProcessNames[schedID][procID]; !ok {
+		return 0, 0, fmt.Errorf("unknown process %q", ref)
+	}
+	return schedID, procID, nil
+}
+
+// recordConversation records the agent's conversation in a process run in
+// the session, with the error the run failed with.
+func recordConversation(sess *orchsession.Session, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, turns []agent.Turn, runErr error) {
+	if len(turns) == 0 {
+		return
+	}
+	rec := orchsession.ConversationRecord{Schedule: schedID, Process: procID}
+	for _, t := range turns {
+		rec.Turns = append(rec.Turns, orchsession.ConversationTurn{
+			Kind:      string(t.Kind),
+			Model:     string(t.Model),
+			Content:   t.Content,
+			Timestamp: t.Time,
+		})
+	}
+	if runErr != nil {
+		rec.Error = runErr.Error()
+	}
+	sess.RecordConversation(rec)
+}
+
+// printConversations writes the conversations of a process's runs, or of
+// its nth run only when n is positive.
+func printConversations(w io.Writer, runs []orchsession.ConversationRecord, n int, full bool) {
+	for i, rec := range runs {
+		if n > 0 && i+1 != n {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s / %s · run %d of %d · %s\n", cyan(fmt.Sprintf("S%dP%d", rec.Schedule, rec.Process)),
+			orchestrate.ScheduleNames[rec.Schedule], orchestrate.ProcessNames[rec.Schedule][rec.Process],
+			i+1, len(runs), rec.Timestamp.Format("2006-01-02 15:04:05"))
+		for _, t := range rec.Turns {
+			label := t.Kind
+			if t.Model != "" {
+				label += " (" + t.Model + ")"
+			}
+			printTranscriptText(w, label, t.Content, nil, full)
+		}
+		if rec.Error != "" {
+			fmt.Fprintf(w, "  %s %s\n", red("✗ failed:"), rec.Error)
+		}
+		fmt.Fprintln(w)
+	}
+}
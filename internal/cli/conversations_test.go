@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestParseProcessRef(t *testing.T) {
+	s, p, err := parseProcessRef("s3p2")
+	if err != nil || s != orchestrate.ScheduleImplement || p != orchestrate.Process2 {
+		t.Errorf("parseProcessRef(s3p2) = %v, %v, %v", s, p, err)
+	}
+	for _, ref := range []string{"S9P1", "S3P4", "S3", "Implement"} {
+		if _, _, err := parseProcessRef(ref); err == nil {
+			t.Errorf("parseProcessRef accepted %s", ref)
+		}
+	}
+}
+
+func TestPrintConversations(t *testing.T) {
+	runs := []orchsession.ConversationRecord{
+		{Schedule: orchestrate.ScheduleImplement, Process: orchestrate.Process1, Turns: []orchsession.ConversationTurn{
+			{Kind: orchsession.TurnPrompt, Model: "coder", Content: "write the handler"},
+		}},
+		{Schedule: orchestrate.ScheduleImplement, Process: orchestrate.Process1, Turns: []orchsession.ConversationTurn{
+			{Kind: orchsession.TurnPrompt, Model: "coder", Content: "fix the handler"},
+			{Kind: orchsession.TurnAction, Content: "Ran go build ./...\nhandler.go:3: undefined: x"},
+		}, Error: "build failed"},
+	}
+
+	var out bytes.Buffer
+	printConversations(&out, runs, 2, false)
+	got := out.String()
+	for _, want := range []string{"run 2 of 2", "prompt (coder)", "fix the handler", "── action ──", "undefined: x", "build failed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "write the handler") {
+		t.Errorf("--run 2 printed the first run:\n%s", got)
+	}
+}
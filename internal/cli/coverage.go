@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/scan"
+)
+
+// readOnlyActions cannot change the workspace, so they leave a coverage
+// measurement current.
+var readOnlyActions = map[agent.ActionType]bool{
+	agent.ActionReadFile:     true,
+	agent.ActionSearchFiles:  true,
+	agent.ActionListDir:      true,
+	agent.ActionHover:        true,
+	agent.ActionReferences:   true,
+	agent.ActionDiagnostics:  true,
+	agent.ActionDBListTables: true,
+	agent.ActionDBQuery:      true,
+}
+
+// coverageMeasure returns how the coverage guide measures the coverage of
+// workspace: the scanner's commands run as the agent's actions, in its
+// container when isolated and under its policy and plugins, and a
+// measurement is reused until the agent takes an action that may have
+// changed the workspace.
+func coverageMeasure(ag *agent.Agent, workspace string) func(context.Context) (*scan.CoverageReport, error) {
+	scanner := scan.NewCoverageScanner(workspace)
+	scanner.SetRunner(func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+		words := []string{name}
+		for _, arg := range args {
+			words = append(words, shellWord(arg))
+		}
+		opts := agent.CommandOptions{}
+		if rel, err := filepath.Rel(workspace, dir); err == nil && rel != "." {
+			opts.Dir = rel
+		}
+		res, err := ag.RunCheck(ctx, strings.Join(words, " "), opts)
+		// Failing tests still write coverage
+		if err != nil && strings.TrimSpace(res.Stdout) == "" {
+			return nil, err
+		}
+		return []byte(res.Stdout), nil
+	})
+
+	var last *scan.CoverageReport
+	measuredAt := -1 // the number of actions when last measured
+	return func(ctx context.Context) (*scan.CoverageReport, error) {
+		actions := ag.GetActions()
+		if last != nil && !changedSince(actions, measuredAt) {
+			return last, nil
+		}
+		report, err := scanner.Measure(ctx)
+		if err != nil {
+			return nil, err
+		}
+		last, measuredAt = report, len(ag.GetActions())
+		return report, nil
+	}
+}
+
+// changedSince reports whether any action after the first n may have
+// changed the workspace.
+func changedSince(actions []agent.Action, n int) bool {
+	for _, a := range actions[min(n, len(actions)):] {
+		if !readOnlyActions[a.Type] {
+			return true
+		}
+	}
+	return false
+}
+
+// shellWord quotes s for sh unless it is a plain word.
+func shellWord(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=./:,+@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
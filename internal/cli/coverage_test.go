@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+)
+
+func TestChangedSince(t *testing.T) {
+	actions := []agent.Action{
+		{Type: agent.ActionRunCommand},
+		{Type: agent.ActionReadFile},
+		{Type: agent.ActionSearchFiles},
+	}
+	if changedSince(actions, 1) {
+		t.Error("reads changed the workspace")
+	}
+	if !changedSince(actions, 0) {
+		t.Error("a command did not change the workspace")
+	}
+	if changedSince(actions, 5) {
+		t.Error("no actions changed the workspace")
+	}
+}
+
+func TestShellWord(t *testing.T) {
+	for in, want := range map[string]string{
+		"-coverprofile=.obot/cover-1.out": "-coverprofile=.obot/cover-1.out",
+		"./...":                           "./...",
+		"a b":                             "'a b'",
+		"it's":                            `'it'\''s'`,
+		"":                                "''",
+	} {
+		if got := shellWord(in); got != want {
+			t.Errorf("shellWord(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
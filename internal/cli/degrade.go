@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"log/slog"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+// applyDegradation gives the coordinator what it reduces under memory
+// pressure: the tier's context window, at most maxTokens (context.max_tokens)
+// when that is set, and for each role the model its tier mapping gives the
+// next tier down.
+func applyDegradation(coord *model.Coordinator, models config.ModelsConfig, maxTokens int) {
+	if tierManager != nil {
+		window := tierManager.GetContextWindow()
+		if maxTokens > 0 {
+			window = min(window, maxTokens)
+		}
+		if contextWindowFlag > 0 {
+			window = contextWindowFlag
+		}
+		coord.SetContextWindow(window)
+	}
+
+	roles := map[orchestrate.ModelType]config.ModelRoleConfig{
+		orchestrate.ModelOrchestrator: models.Orchestrator,
+		orchestrate.ModelCoder:        models.Coder,
+		orchestrate.ModelResearcher:   models.Researcher,
+		orchestrate.ModelVision:       models.Vision,
+	}
+	smaller := make(map[orchestrate.ModelType]string)
+	for role, rc := range roles {
+		if m := coord.GetModel(role); m != nil {
+			if name := rc.SmallerModel(m.Name); name != "" {
+				smaller[role] = name
+			}
+		}
+	}
+	coord.SetSmallerModels(smaller)
+}
+
+// degradeUnderPressure matches the mitigations to the current memory
+// pressure before a process runs, and records each decision in the
+// session, the resource summary, and the orchestrator's notes.
+func degradeUnderPressure(orch *orchestrate.Orchestrator, coord *model.Coordinator, resMon *resource.Monitor, sess *orchsession.Session, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) {
+	level, res := resMon.Pressure()
+	steps := coord.Degrade(level)
+	if len(steps) == 0 {
+		return
+	}
+
+	d := resource.Degradation{Level: level, Resource: res, Steps: steps}
+	resMon.RecordDegradation(d)
+	sess.RecordDegradation(orchsession.DegradationRecord{
+		Level:    string(level),
+		Resource: res,
+		Schedule: orchestrate.ScheduleNames[schedID],
+		Process:  orchestrate.ProcessNames[schedID][procID],
+		Steps:    steps,
+	})
+	slog.Warn("memory pressure mitigation", "level", level, "resource", res, "steps", steps)
+	orch.AddTypedNote(orchestrate.NoteConstraint, d.String(), "system", "resource", res)
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/fsutil"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/planner"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// What --plan-drift does when the agent's work drifts from the approved plan
+const (
+	planDriftOff     = "off"
+	planDriftWarn    = "warn"
+	planDriftRevisit = "revisit" // warn, and revisit the Plan schedule
+)
+
+// planDriftFiles is how many files changed off the approved plan make the
+// agent's work diverge from it, when they also outnumber the subtasks
+// addressed. Each further planDriftFiles such files warn again.
+const planDriftFiles = 3
+
+// plannedTasks returns the subtasks of an approved plan as the session
+// records them.
+func plannedTasks(plan *planner.Plan) []orchsession.PlannedTask {
+	tasks := make([]orchsession.PlannedTask, len(plan.Tasks))
+	for i, task := range plan.Tasks {
+		tasks[i] = orchsession.PlannedTask{
+			ID:      task.ID,
+			Risk:    string(task.Risk),
+			Summary: planner.TaskLine(plan.Root, task),
+		}
+		if task.File != "" {
+			tasks[i].File = filepath.ToSlash(fsutil.RelPath(plan.Root, task.File))
+		}
+	}
+	return tasks
+}
+
+// planDriftTracker is an agent plugin that follows the agent's file changes
+// against the plan approved with --approve-plan. It warns when the
+// Implement schedule's work diverges from the plan, and when Production
+// starts with high-risk subtasks untouched; in revisit mode the first
+// warning of each kind also sends orchestration back to the Plan schedule.
+// Without an approved plan it does nothing.
+type planDriftTracker struct {
+	*agent.BasePlugin
+
+	orch      *orchestrate.Orchestrator
+	sess      *orchsession.Session
+	workspace string
+	revisit   bool
+	out       io.Writer
+
+	mu                sync.Mutex
+	warnedOffPlan     int // files off the plan at the last divergence warning
+	checkedProduction bool
+}
+
+// newPlanDriftTracker returns the tracker of mode, warn or revisit, for
+// the plan approved in sess.
+func newPlanDriftTracker(orch *orchestrate.Orchestrator, sess *orchsession.Session, workspace, mode string, out io.Writer) *planDriftTracker {
+	return &planDriftTracker{
+		BasePlugin: agent.NewBasePlugin("plan-drift"),
+		orch:       orch,
+		sess:       sess,
+		workspace:  workspace,
+		revisit:    mode == planDriftRevisit,
+		out:        out,
+	}
+}
+
+// checkPlanDrift validates a --plan-drift mode.
+func checkPlanDrift(mode string) error {
+	switch mode {
+	case planDriftOff, planDriftWarn, planDriftRevisit:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q (use off, warn, or revisit)", mode)
+}
+
+// OnAfterAction records the files a file action changed.
+func (t *planDriftTracker) OnAfterAction(ctx context.Context, action *agent.Action) error {
+	switch action.Type {
+	case agent.ActionCreateFile, agent.ActionEditFile, agent.ActionDeleteFile,
+		agent.ActionRenameFile, agent.ActionMoveFile, agent.ActionCopyFile:
+	default:
+		return nil
+	}
+	for _, path := range []string{action.Path, action.NewPath} {
+		if path != "" {
+			t.sess.RecordPlanFile(t.relPath(path))
+		}
+	}
+	return nil
+}
+
+// OnBeforeExecute checks the high-risk subtasks when Production starts.
+func (t *planDriftTracker) OnBeforeExecute(ctx context.Context, schedule, process string) error {
+	if schedule == orchestrate.ScheduleProduction.String() {
+		t.checkHighRisk()
+	}
+	return nil
+}
+
+// OnAfterExecute checks for divergence after each Implement process.
+func (t *planDriftTracker) OnAfterExecute(ctx context.Context, schedule, process string, err error) error {
+	if schedule == orchestrate.ScheduleImplement.String() {
+		t.checkDivergence()
+	}
+	return nil
+}
+
+// checkDivergence warns when the files changed off the plan outnumber the
+// subtasks addressed.
+func (t *planDriftTracker) checkDivergence() {
+	plan := t.sess.GetApprovedPlan()
+	if plan == nil {
+		return
+	}
+	t.mu.Lock()
+	offPlan := len(plan.OffPlan)
+	if offPlan < planDriftFiles || offPlan <= plan.Addressed() || offPlan < t.warnedOffPlan+planDriftFiles {
+		t.mu.Unlock()
+		return
+	}
+	first := t.warnedOffPlan == 0
+	t.warnedOffPlan = offPlan
+	t.mu.Unlock()
+
+	files := plan.OffPlan
+	if len(files) > 5 {
+		files = append(files[:5:5], "...")
+	}
+	t.warn(fmt.Sprintf("work diverges from the approved plan: %d files changed off the plan (%s), %d of %d subtasks addressed",
+		offPlan, strings.Join(files, ", "), plan.Addressed(), len(plan.Tasks)), first)
+}
+
+// checkHighRisk warns, once, of high-risk subtasks whose files the agent
+// has not changed.
+func (t *planDriftTracker) checkHighRisk() {
+	plan := t.sess.GetApprovedPlan()
+	if plan == nil {
+		return
+	}
+	t.mu.Lock()
+	checked := t.checkedProduction
+	t.checkedProduction = true
+	t.mu.Unlock()
+	if checked {
+		return
+	}
+
+	var untouched []string
+	for _, task := range plan.Pending() {
+		if task.Risk == string(planner.RiskHigh) {
+			untouched = append(untouched, task.ID)
+		}
+	}
+	if len(untouched) > 0 {
+		t.warn("high-risk subtasks untouched at Production: "+strings.Join(untouched, ", "), true)
+	}
+}
+
+// warn reports plan drift and records it in the notes; revisit sends
+// orchestration back to the Plan schedule in revisit mode.
+func (t *planDriftTracker) warn(reason string, revisit bool) {
+	fmt.Fprintf(t.out, "%s %s\n", ui.FormatWarning("Drift"), ui.FormatBullet()+ui.FormatValue(reason))
+	t.orch.AddTypedNote(orchestrate.NoteRisk, "Plan drift: "+reason, "planner")
+	if t.revisit && revisit {
+		_ = t.orch.RequestScheduleRevisit(orchestrate.SchedulePlan, "plan drift: "+reason)
+	}
+}
+
+// relPath returns path slash-separated and relative to the workspace.
+func (t *planDriftTracker) relPath(path string) string {
+	if filepath.IsAbs(path) {
+		path = fsutil.RelPath(t.workspace, path)
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
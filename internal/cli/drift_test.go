@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestPlanDriftTracker(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+	sess.SetApprovedPlan([]orchsession.PlannedTask{
+		{ID: "T-001", File: "main.go", Risk: "moderate"},
+		{ID: "T-002", File: "auth/token.go", Risk: "high"},
+	})
+	var out bytes.Buffer
+	tracker := newPlanDriftTracker(orch, sess, "/repo", planDriftRevisit, &out)
+	ctx := context.Background()
+	implement := orchestrate.ScheduleImplement.String()
+
+	for _, path := range []string{"/repo/main.go", "a.go", "b.go"} {
+		tracker.OnAfterAction(ctx, &agent.Action{Type: agent.ActionEditFile, Path: path})
+	}
+	tracker.OnAfterExecute(ctx, implement, "1", nil)
+	if out.Len() > 0 {
+		t.Fatalf("warned before the work diverged:\n%s", out.String())
+	}
+
+	tracker.OnAfterAction(ctx, &agent.Action{Type: agent.ActionRenameFile, Path: "c.go", NewPath: "d.go"})
+	tracker.OnAfterAction(ctx, &agent.Action{Type: agent.ActionReadFile, Path: "e.go"})
+	tracker.OnAfterExecute(ctx, implement, "2", nil)
+	if !strings.Contains(out.String(), "4 files changed off the plan (a.go, b.go, c.go, d.go), 1 of 2 subtasks addressed") {
+		t.Fatalf("no divergence warning:\n%s", out.String())
+	}
+	if id, ok := orch.PendingRevisit(); !ok || id != orchestrate.SchedulePlan {
+		t.Errorf("PendingRevisit() = %v, %v; want the Plan schedule", id, ok)
+	}
+
+	out.Reset()
+	tracker.OnBeforeExecute(ctx, orchestrate.ScheduleProduction.String(), "1")
+	tracker.OnBeforeExecute(ctx, orchestrate.ScheduleProduction.String(), "2")
+	if got := strings.Count(out.String(), "high-risk subtasks untouched at Production: T-002"); got != 1 {
+		t.Errorf("high-risk warnings = %d, want 1:\n%s", got, out.String())
+	}
+}
+
+func TestPlanDriftTracker_NoPlan(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	var out bytes.Buffer
+	tracker := newPlanDriftTracker(orch, orchsession.NewSessionWithBaseDir(t.TempDir()), "/repo", planDriftWarn, &out)
+	for _, path := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		tracker.OnAfterAction(context.Background(), &agent.Action{Type: agent.ActionCreateFile, Path: path})
+	}
+	tracker.OnAfterExecute(context.Background(), orchestrate.ScheduleImplement.String(), "1", nil)
+	tracker.OnBeforeExecute(context.Background(), orchestrate.ScheduleProduction.String(), "1")
+	if out.Len() > 0 {
+		t.Errorf("warned without an approved plan:\n%s", out.String())
+	}
+}
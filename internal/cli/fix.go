@@ -19,6 +19,7 @@ import (
 	"github.com/croberts/obot/internal/fixer"
 	"github.com/croberts/obot/internal/scan"
 	"github.com/croberts/obot/internal/stats"
+	"github.com/croberts/obot/internal/tokens"
 )
 
 // runFix is the main fix command logic
@@ -61,6 +62,13 @@ func runFix(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("file not found: %s", filePath)
 	}
 
+	// Start Ollama if asked to and it is not running
+	stopOllama, err := ensureOllama(context.Background(), client)
+	if err != nil {
+		return err
+	}
+	defer stopOllama()
+
 	// Check Ollama connection
 	printInfo("Checking Ollama connection...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -153,6 +161,9 @@ func runFix(cmd *cobra.Command, args []string) error {
 
 	// Run inference with streaming
 	fmt.Println()
+	if eta, ok := fixETA(model, quality, tokens.Approximate(fileCtx.GetTargetLines())); ok {
+		fmt.Printf("%s %s\n", color.HiBlackString("Estimated time:"), color.HiBlackString("~"+eta.Round(time.Second).String()+" (obot bench)"))
+	}
 	if verbose {
 		fmt.Printf("%s %s\n", cyan("Model thinking..."), color.HiBlackString("(Ctrl+C to cancel)"))
 		fmt.Println(strings.Repeat("─", 50))
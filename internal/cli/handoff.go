@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+// newHandoffCallback stores completed model handoffs in the session and
+// notes handoffs whose summary had to be discarded.
+func newHandoffCallback(orch *orchestrate.Orchestrator, sess *orchsession.Session) func(model.Handoff, error) {
+	return func(h model.Handoff, err error) {
+		if err != nil {
+			orch.AddNote(fmt.Sprintf("Handoff from %s to %s without summary: %v", h.From, h.To, err), "system")
+			return
+		}
+		sess.RecordHandoff(orchsession.HandoffRecord{
+			From:          string(h.From),
+			To:            string(h.To),
+			Schedule:      h.Schedule.String(),
+			Process:       h.Process.String(),
+			State:         h.Summary.State,
+			OpenQuestions: h.Summary.OpenQuestions,
+			Artifacts:     h.Summary.Artifacts,
+			Timestamp:     h.Timestamp,
+		})
+		for _, q := range h.Summary.OpenQuestions {
+			orch.AddTypedNote(orchestrate.NoteTodo, "Open question from "+string(h.From)+": "+q, "handoff")
+		}
+	}
+}
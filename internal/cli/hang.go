@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// orchHangFactor is the --hang-factor flag: how many times its typical
+// duration a process runs before it is reported as possibly hung.
+var orchHangFactor float64
+
+// minHangThreshold keeps processes that typically take seconds from being
+// reported for running a little over their usual time.
+const minHangThreshold = 2 * time.Minute
+
+// hangResponseTail is how much of the model's last response a hang report
+// shows.
+const hangResponseTail = 200
+
+// runHangs is the hang detector of the running orchestration, which is
+// paused while a consultation waits on the human.
+var runHangs atomic.Pointer[hangDetector]
+
+// hangDetector reports processes that run far longer than their past runs
+// took, saying what the agent was last doing, to catch runs that hang
+// silently overnight.
+type hangDetector struct {
+	profiles *resource.Profiles
+	ag       *agent.Agent
+	factor   float64
+	out      io.Writer
+
+	mu      sync.Mutex
+	current *hangWatch // the process being watched, if any
+}
+
+func newHangDetector(profiles *resource.Profiles, ag *agent.Agent, factor float64, out io.Writer) *hangDetector {
+	return &hangDetector{profiles: profiles, ag: ag, factor: factor, out: out}
+}
+
+// watch starts watching a run of a process by model and returns the
+// function that stops watching it. Processes without past runs to compare
+// with are not watched.
+func (h *hangDetector) watch(ctx context.Context, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, model string) (stop func()) {
+	if h.factor <= 0 {
+		return func() {}
+	}
+	typical, ok := typicalDuration(h.profiles, schedID, procID, model)
+	if !ok {
+		return func() {}
+	}
+	threshold := max(time.Duration(float64(typical)*h.factor), minHangThreshold)
+	started := time.Now()
+	var w *hangWatch
+	w = newHangWatch(threshold, func() {
+		now := time.Now()
+		elapsed := w.elapsed(now)
+		report := h.report(schedID, procID, started, elapsed, typical, now)
+		headline, _, _ := strings.Cut(report, "\n")
+		slog.Warn("process may be hung", "schedule", schedID.String(), "process", procID.String(),
+			"elapsed", elapsed.Round(time.Second), "typical", typical.Round(time.Second))
+		fmt.Fprintln(h.out, ui.FormatWarning(report))
+		runNotifier.Load().Notify(ctx, "obot: process may be hung", headline)
+	})
+	h.mu.Lock()
+	h.current = w
+	h.mu.Unlock()
+	return func() {
+		w.stop()
+		h.mu.Lock()
+		if h.current == w {
+			h.current = nil
+		}
+		h.mu.Unlock()
+	}
+}
+
+// pause stops the clock of the watched process while the run waits on the
+// human, so that a slow answer is not reported as a hang.
+func (h *hangDetector) pause() {
+	if w := h.watched(); w != nil {
+		w.pause()
+	}
+}
+
+// resume restarts the clock pause stopped.
+func (h *hangDetector) resume() {
+	if w := h.watched(); w != nil {
+		w.resume()
+	}
+}
+
+// typical returns how long a process usually takes model, see
+// typicalDuration.
+func (h *hangDetector) typical(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, model string) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	return typicalDuration(h.profiles, schedID, procID, model)
+}
+
+func (h *hangDetector) watched() *hangWatch {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.current
+}
+
+// hangWatch calls fire once a process has run for threshold, not counting
+// the time it was paused.
+type hangWatch struct {
+	mu        sync.Mutex
+	threshold time.Duration
+	fire      func()
+	timer     *time.Timer
+	ran       time.Duration // running time before the current stretch
+	resumed   time.Time     // start of the current stretch
+	pauses    int           // pauses not yet resumed; nested consultations pause twice
+	done      bool          // fired or stopped
+}
+
+func newHangWatch(threshold time.Duration, fire func()) *hangWatch {
+	w := &hangWatch{threshold: threshold, fire: fire, resumed: time.Now()}
+	w.timer = time.AfterFunc(threshold, w.expire)
+	return w
+}
+
+func (w *hangWatch) expire() {
+	w.mu.Lock()
+	if w.done || w.pauses > 0 {
+		w.mu.Unlock()
+		return
+	}
+	w.done = true
+	w.mu.Unlock()
+	w.fire()
+}
+
+func (w *hangWatch) pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pauses++
+	if w.pauses == 1 {
+		w.timer.Stop()
+		w.ran += time.Since(w.resumed)
+	}
+}
+
+func (w *hangWatch) resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pauses == 0 {
+		return
+	}
+	w.pauses--
+	if w.pauses == 0 {
+		w.resumed = time.Now()
+		if !w.done {
+			w.timer = time.AfterFunc(max(w.threshold-w.ran, 0), w.expire)
+		}
+	}
+}
+
+func (w *hangWatch) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	w.timer.Stop()
+}
+
+// elapsed returns how long the process has run at now, not counting the
+// time it was paused.
+func (w *hangWatch) elapsed(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pauses > 0 {
+		return w.ran
+	}
+	return w.ran + now.Sub(w.resumed)
+}
+
+// report describes a process that started at started and has run for
+// elapsed, not counting waits on the human, against its typical duration,
+// with the agent's last action and model request in it.
+func (h *hangDetector) report(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, started time.Time, elapsed, typical time.Duration, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s P%d (%s) may be hung: running %s, %.1f× its typical %s",
+		orchestrate.ScheduleNames[schedID], procID, orchestrate.ProcessNames[schedID][procID],
+		elapsed.Round(time.Second), float64(elapsed)/float64(typical), typical.Round(time.Second))
+
+	b.WriteString("\n  Last action: ")
+	actions := h.ag.GetActions()
+	if n := len(actions); n > 0 && !actions[n-1].Timestamp.Before(started) {
+		last := actions[n-1]
+		fmt.Fprintf(&b, "%s, %s ago", last.ActionOutput(), now.Sub(last.Timestamp).Round(time.Second))
+	} else {
+		b.WriteString("none in this process")
+	}
+
+	// A request in flight has cleared the last response
+	b.WriteString("\n  Last model request: ")
+	usage := h.ag.LastTokenUsage()
+	current := !usage.Time.Before(started)
+	if current {
+		fmt.Fprintf(&b, "%s tokens, %s ago", formatNumber(int(usage.Tokens)), now.Sub(usage.Time).Round(time.Second))
+	} else {
+		b.WriteString("none in this process")
+	}
+	prompt, response := h.ag.LastExchange()
+	switch {
+	case prompt != "" && response == "":
+		b.WriteString("\n  Waiting on the model's response")
+	case current:
+		tail := strings.Join(strings.Fields(response), " ")
+		if r := []rune(tail); len(r) > hangResponseTail {
+			tail = "..." + string(r[len(r)-hangResponseTail:])
+		}
+		b.WriteString("\n  Last response: " + tail)
+	}
+	return b.String()
+}
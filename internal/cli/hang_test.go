@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+)
+
+func TestHangDetector_Report(t *testing.T) {
+	h := newHangDetector(resource.NewProfiles(""), agent.NewAgent(model.NewCoordinator(nil)), 3, io.Discard)
+	started := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	got := h.report(orchestrate.ScheduleImplement, orchestrate.Process2, started, 31*time.Minute, 10*time.Minute, started.Add(40*time.Minute))
+	want := "Implement P2 (" + orchestrate.ProcessNames[orchestrate.ScheduleImplement][orchestrate.Process2] + ") may be hung: running 31m0s, 3.1× its typical 10m0s\n" +
+		"  Last action: none in this process\n" +
+		"  Last model request: none in this process"
+	if got != want {
+		t.Errorf("report =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHangWatch_Paused(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	w := newHangWatch(50*time.Millisecond, func() { fired <- struct{}{} })
+	defer w.stop()
+
+	// A consultation opening and one nested in it
+	w.pause()
+	w.pause()
+	w.resume()
+	select {
+	case <-fired:
+		t.Fatal("fired while waiting on the human")
+	case <-time.After(150 * time.Millisecond):
+	}
+	if e := w.elapsed(time.Now()); e >= 50*time.Millisecond {
+		t.Errorf("elapsed = %s while paused, want the time before the pause", e)
+	}
+
+	w.resume()
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("did not fire once resumed")
+	}
+	if e := w.elapsed(time.Now()); e < 50*time.Millisecond || e >= 150*time.Millisecond {
+		t.Errorf("elapsed = %s, want the threshold without the pause", e)
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// ollamaHeartbeat returns how model requests are watched for stalls, from
+// the ollama section of the config.
+func ollamaHeartbeat() ollama.Heartbeat {
+	o := config.DefaultUnifiedConfig().Ollama
+	if cfg != nil && cfg.Unified != nil {
+		o = cfg.Unified.Ollama
+	}
+	return ollama.Heartbeat{
+		FirstToken: time.Duration(o.LoadSeconds) * time.Second,
+		Idle:       time.Duration(o.StallSeconds) * time.Second,
+		Retries:    o.StallRetries,
+	}
+}
+
+// watchForStalls has the client of every model role watch its requests for
+// stalls, so a hung Ollama fails the request rather than the run waiting
+// forever.
+func watchForStalls(modelCoord *model.Coordinator) {
+	heartbeat := ollamaHeartbeat()
+	roles := []orchestrate.ModelType{orchestrate.ModelOrchestrator, orchestrate.ModelCoder, orchestrate.ModelResearcher, orchestrate.ModelVision}
+	for _, role := range roles {
+		if client := modelCoord.Get(role); client != nil {
+			client.SetHeartbeat(heartbeat)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/hooks"
+	"github.com/croberts/obot/internal/isolate"
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// trustHookScripts asks whether to run hook scripts the user has not
+// trusted; replaced in tests.
+var trustHookScripts = promptHookTrust
+
+// newHookPlugin returns the plugin running the hooks of workspace for
+// --hooks: its scripts, once the user trusts them as they are now, and the
+// commands of its obot.yaml. The scripts are pinned as the user trusted
+// them, so one changed or added during the run is skipped. In an isolated
+// run they run in the container on the copy. It returns nil when there is
+// nothing to run.
+func newHookPlugin(workspace, sessionID string, iso *isolate.Workspace, in *bufio.Reader) *hooks.Plugin {
+	runner := hooks.NewRunner(workspace, sessionID)
+	if iso != nil {
+		runner = hooks.NewRunner(iso.Copy, sessionID)
+		runner.SetContainer(func(command string) (string, []string) {
+			return iso.CommandArgs(command, "")
+		}, isolate.Workdir)
+	}
+	runner.SetCommands(workspaceHooks())
+
+	dir := hooks.Dir(workspace)
+	scripts, err := runner.Pin()
+	var hash string
+	if err == nil && len(scripts) > 0 {
+		hash, err = runner.Fingerprint()
+	}
+	switch {
+	case err != nil:
+		printWarning("Not running the hook scripts in " + dir + ": " + err.Error())
+		runner.SkipScripts()
+	case len(scripts) > 0 && !config.IsTrusted(config.TrustedWorkspacesPath(), dir, hash) && !trustHookScripts(in, dir, scripts, hash):
+		printWarning("Not running the untrusted hook scripts in " + dir)
+		runner.SkipScripts()
+	}
+	if len(runner.Scripts()) == 0 && len(workspaceHooks()) == 0 {
+		return nil
+	}
+	return hooks.NewPlugin(runner, func(err error) {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning(err.Error()))
+	})
+}
+
+// promptHookTrust lists the hook scripts and asks on in whether to trust
+// them, recording the answer yes. Without a terminal to ask on, they are
+// not trusted.
+func promptHookTrust(in *bufio.Reader, dir string, scripts []string, hash string) bool {
+	if !term.IsTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Println(ui.FormatWarning("--hooks runs these scripts around every process and schedule:"))
+	for _, s := range scripts {
+		fmt.Println("  " + ui.FormatValue(s))
+	}
+	fmt.Print("Trust them? [y/N] ")
+	if !confirmed(in) {
+		return false
+	}
+	if err := config.RecordTrust(config.TrustedWorkspacesPath(), dir, hash); err != nil {
+		printWarning("Failed to record the trust: " + err.Error())
+	}
+	return true
+}
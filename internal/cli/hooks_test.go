@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/hooks"
+)
+
+func TestNewHookPlugin_Trust(t *testing.T) {
+	workspace := t.TempDir()
+	savedTrust, savedWorkspace := trustHookScripts, runWorkspace.Load()
+	t.Cleanup(func() { trustHookScripts = savedTrust; runWorkspace.Store(savedWorkspace) })
+	runWorkspace.Store(nil)
+
+	if p := newHookPlugin(workspace, "sess-1", nil, nil); p != nil {
+		t.Error("newHookPlugin() without hooks: want none")
+	}
+
+	if err := os.MkdirAll(hooks.Dir(workspace), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooks.Dir(workspace), hooks.PreProcess), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	asked := 0
+	for _, trusted := range []bool{false, true} {
+		trustHookScripts = func(*bufio.Reader, string, []string, string) bool { asked++; return trusted }
+		if p := newHookPlugin(workspace, "sess-1", nil, nil); (p != nil) != trusted {
+			t.Errorf("newHookPlugin() with scripts trusted %v = %v", trusted, p)
+		}
+	}
+	if asked != 2 {
+		t.Errorf("asked %d times, want each time the scripts are not trusted", asked)
+	}
+
+	// The commands of obot.yaml still run without the scripts
+	runWorkspace.Store(&config.WorkspaceConfig{Hooks: map[string]string{hooks.PostProcess: "true"}})
+	trustHookScripts = func(*bufio.Reader, string, []string, string) bool { return false }
+	if p := newHookPlugin(workspace, "sess-1", nil, nil); p == nil {
+		t.Error("newHookPlugin() with obot.yaml commands and untrusted scripts: want the commands run")
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// applyOllamaHosts routes the model roles to the hosts in ollama.hosts and
+// starts health-checking them until ctx is done. Without hosts every role
+// keeps using the single Ollama URL.
+func applyOllamaHosts(ctx context.Context, coord *model.Coordinator, unified *config.UnifiedConfig) error {
+	if len(unified.Ollama.Hosts) == 0 {
+		return nil
+	}
+
+	hosts := make([]*ollama.Host, 0, len(unified.Ollama.Hosts))
+	for i, hc := range unified.Ollama.Hosts {
+		if hc.Name == "" {
+			return fmt.Errorf("ollama.hosts[%d]: name is required", i)
+		}
+		h, err := ollama.NewHost(hc.Name, hc.URL, hc.MaxConcurrent)
+		if err != nil {
+			return fmt.Errorf("ollama.hosts[%d]: %w", i, err)
+		}
+		hosts = append(hosts, h)
+	}
+	routes := map[orchestrate.ModelType][]string{
+		orchestrate.ModelOrchestrator: unified.Models.Orchestrator.Hosts,
+		orchestrate.ModelCoder:        unified.Models.Coder.Hosts,
+		orchestrate.ModelResearcher:   unified.Models.Researcher.Hosts,
+		orchestrate.ModelVision:       unified.Models.Vision.Hosts,
+	}
+	if err := coord.SetHosts(hosts, routes); err != nil {
+		return err
+	}
+
+	if coord.CheckHosts(ctx) == 0 {
+		return fmt.Errorf("no ollama host is answering: %s", hostSummary(coord))
+	}
+	for _, s := range coord.HostStatuses() {
+		if !s.Healthy {
+			printWarning(fmt.Sprintf("Ollama host %s (%s) is not answering; its roles fail over", s.Name, s.URL))
+		}
+	}
+	if secs := unified.Ollama.HostCheckSeconds; secs > 0 {
+		go coord.WatchHosts(ctx, time.Duration(secs)*time.Second)
+	}
+	return nil
+}
+
+// hostSummary returns the state of the hosts on one line.
+func hostSummary(coord *model.Coordinator) string {
+	statuses := coord.HostStatuses()
+	parts := make([]string, len(statuses))
+	for i, s := range statuses {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, " · ")
+}
+
+// newHostCallback records hosts going down or coming back as notes, so the
+// orchestrator knows why a role slowed or moved.
+func newHostCallback(orch *orchestrate.Orchestrator) func(model.HostEvent) {
+	return func(e model.HostEvent) {
+		slog.Warn("ollama host changed", "host", e.Host, "url", e.URL, "healthy", e.Healthy, "error", e.Err)
+		orch.AddTypedNote(orchestrate.NoteConstraint, "Ollama "+e.String(), "system", "host", e.Host)
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+// recordHTTPAction stores an HTTP request action and its response in the
+// session so that verification results survive the run.
+func recordHTTPAction(sess *orchsession.Session, a agent.Action) {
+	if sess == nil || a.Request == nil {
+		return
+	}
+	rec := orchsession.HTTPRecord{
+		Method:    strings.ToUpper(a.Request.Method),
+		URL:       a.Request.URL,
+		Timestamp: a.Timestamp,
+	}
+	if rec.Method == "" {
+		rec.Method = "GET"
+	}
+	rec.Schedule, _ = a.Metadata["schedule"].(string)
+	rec.Process, _ = a.Metadata["process"].(string)
+	if r := a.Response; r != nil {
+		rec.Status = r.Status
+		rec.Body = r.Body
+		rec.Duration = r.Duration
+		rec.Failures = r.Failures
+	} else if msg, ok := a.Metadata["error"].(string); ok {
+		rec.Error = msg
+	}
+	sess.RecordHTTP(rec)
+}
@@ -53,7 +53,32 @@ var initCmd = &cobra.Command{
 			printInfo(".obot/rules.obotrules already exists, skipping")
 		}
 
-		// 4. Create cache and session paths
+		// 4. Create action policy template
+		policyPath := filepath.Join(obotDir, "policy.yaml")
+		if _, err := os.Stat(policyPath); os.IsNotExist(err) {
+			policyTemplate := `# Action policy for obot orchestrate.
+# decision: allow | confirm | block
+# Built-in classes: delete_file, delete_dir, network_command,
+# destructive_command, ci_files. By default delete_dir, network_command,
+# destructive_command, and ci_files require confirmation. Rules that loosen
+# the defaults apply only once you trust this file:
+#   obot config trust .obot/policy.yaml
+rules:
+  # - class: network_command
+  #   decision: block
+  #   reason: this workspace must stay offline
+  # - paths: ["migrations/**"]
+  #   decision: confirm
+  # - commands: ['^terraform\s+apply']
+  #   decision: block
+`
+			if err := os.WriteFile(policyPath, []byte(policyTemplate), 0644); err != nil {
+				return fmt.Errorf("failed to create policy.yaml: %w", err)
+			}
+			printSuccess("Created .obot/policy.yaml template")
+		}
+
+		// 5. Create cache and session paths
 		cacheDir := filepath.Join(obotDir, "cache")
 		if err := os.MkdirAll(cacheDir, 0755); err != nil {
 			return fmt.Errorf("failed to create cache directory: %w", err)
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/croberts/obot/internal/agent"
 )
 
 func TestInitCreatesProperStructure(t *testing.T) {
@@ -42,4 +44,9 @@ func TestInitCreatesProperStructure(t *testing.T) {
 	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
 		t.Error("cache directory was not created")
 	}
+
+	// Verify the policy template parses
+	if _, err := agent.LoadPolicy("."); err != nil {
+		t.Errorf("policy.yaml template is invalid: %v", err)
+	}
 }
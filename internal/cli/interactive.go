@@ -14,6 +14,7 @@ import (
 
 	"github.com/croberts/obot/internal/config"
 	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/redact"
 	"github.com/croberts/obot/internal/ui"
 )
 
@@ -244,7 +245,7 @@ func showHistory(history []chatMessage) {
 func saveChat(s *chatSession) {
 	filename := fmt.Sprintf("chat_%s.json", time.Now().Format("20060102_150405"))
 	data, _ := json.MarshalIndent(s.history, "", "  ")
-	err := os.WriteFile(filename, data, 0644)
+	err := os.WriteFile(filename, redact.JSON("chat history", data), 0644)
 	if err != nil {
 		fmt.Printf(ui.Red("Error saving chat: %v\n"), err)
 	} else {
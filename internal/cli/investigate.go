@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// investigateHelp lists the commands available in the investigate REPL.
+const investigateHelp = `Commands:
+  state          Show orchestrator state, flow code, and stats
+  error          Show the suspending error
+  notes          Show session notes
+  actions [n]    Show the last n agent actions (default 5)
+  prompt         Show the last prompt sent to the model
+  response       Show the last model response
+  rerun          Re-run the last agent action
+  resume         Retry the failed process
+  skip           Skip to the next valid process state
+  abort          Abort and save the session
+  help           Show this help`
+
+// investigator is an interactive debug REPL for a suspended orchestration.
+type investigator struct {
+	orch   *orchestrate.Orchestrator
+	ag     *agent.Agent
+	err    *errs.OrchestrationError
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+// investigateSuspension runs the investigate REPL until the user resumes,
+// skips, or aborts, and returns the corresponding suspension action.
+func investigateSuspension(ctx context.Context, oe *errs.OrchestrationError, orch *orchestrate.Orchestrator, ag *agent.Agent, reader *bufio.Reader, out io.Writer) errs.SuspensionAction {
+	inv := &investigator{orch: orch, ag: ag, err: oe, reader: reader, out: out}
+	return inv.run(ctx)
+}
+
+// run reads and dispatches commands.
+func (inv *investigator) run(ctx context.Context) errs.SuspensionAction {
+	fmt.Fprintf(inv.out, "\n%s %s\n", ui.FormatLabelBold("Investigate"), ui.FormatValueMuted("(type 'help' for commands)"))
+	for {
+		fmt.Fprint(inv.out, "investigate> ")
+		line, err := inv.reader.ReadString('\n')
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			if err != nil {
+				return errs.ActionAbort
+			}
+			continue
+		}
+
+		switch strings.ToLower(fields[0]) {
+		case "state":
+			inv.showState()
+		case "error":
+			inv.showError()
+		case "notes":
+			inv.showNotes()
+		case "actions":
+			n := 5
+			if len(fields) > 1 {
+				if v, convErr := strconv.Atoi(fields[1]); convErr == nil && v > 0 {
+					n = v
+				}
+			}
+			inv.showActions(n)
+		case "prompt":
+			prompt, _ := inv.ag.LastExchange()
+			inv.showText("Last prompt", prompt)
+		case "response":
+			_, response := inv.ag.LastExchange()
+			inv.showText("Last response", response)
+		case "rerun":
+			inv.rerun(ctx)
+		case "resume", "retry", "r":
+			return errs.ActionRetry
+		case "skip", "s":
+			return errs.ActionSkip
+		case "abort", "quit", "exit", "a":
+			return errs.ActionAbort
+		case "help", "?":
+			fmt.Fprintln(inv.out, investigateHelp)
+		default:
+			fmt.Fprintf(inv.out, "Unknown command %q. Type 'help' for commands.\n", fields[0])
+		}
+
+		if err != nil {
+			return errs.ActionAbort
+		}
+	}
+}
+
+// showState prints the orchestrator state.
+func (inv *investigator) showState() {
+	stats := inv.orch.GetStats()
+	fmt.Fprintf(inv.out, "  State:       %s\n", inv.orch.State())
+	fmt.Fprintf(inv.out, "  Schedule:    %s\n", inv.err.State.Schedule)
+	fmt.Fprintf(inv.out, "  Process:     %s\n", inv.err.State.Process)
+	fmt.Fprintf(inv.out, "  Flow Code:   %s\n", ui.FormatFlowCode(inv.orch.GetFlowCode()))
+	fmt.Fprintf(inv.out, "  Schedulings: %d\n", stats.TotalSchedulings)
+	fmt.Fprintf(inv.out, "  Processes:   %d\n", stats.TotalProcesses)
+	fmt.Fprintf(inv.out, "  Actions:     %d\n", stats.TotalActions)
+}
+
+// showError prints the suspending error.
+func (inv *investigator) showError() {
+	fmt.Fprintf(inv.out, "  %s\n", inv.err.Error())
+	fmt.Fprintf(inv.out, "  Component: %s\n", inv.err.Component)
+	fmt.Fprintf(inv.out, "  Rule:      %s\n", inv.err.Rule)
+	if inv.err.Cause != nil {
+		fmt.Fprintf(inv.out, "  Cause:     %v\n", inv.err.Cause)
+	}
+	for _, s := range inv.err.Solutions {
+		fmt.Fprintf(inv.out, "  • %s\n", s)
+	}
+}
+
+// showNotes prints all session notes.
+func (inv *investigator) showNotes() {
+	notes := inv.orch.GetNotes()
+	if len(notes) == 0 {
+		fmt.Fprintln(inv.out, "  (no notes)")
+		return
+	}
+	for _, n := range notes {
+		marker := " "
+		if !n.Reviewed {
+			marker = "*"
+		}
+		label := n.Source
+		if n.Type != "" && n.Type != orchestrate.NoteGeneral {
+			label += "/" + string(n.Type)
+		}
+		fmt.Fprintf(inv.out, "  %s [%s] %s", marker, label, n.Content)
+		if len(n.Tags) > 0 {
+			fmt.Fprintf(inv.out, " #%s", strings.Join(n.Tags, " #"))
+		}
+		fmt.Fprintln(inv.out)
+	}
+}
+
+// showActions prints the last n agent actions.
+func (inv *investigator) showActions(n int) {
+	actions := inv.ag.GetActions()
+	if len(actions) == 0 {
+		fmt.Fprintln(inv.out, "  (no actions)")
+		return
+	}
+	if len(actions) > n {
+		actions = actions[len(actions)-n:]
+	}
+	for _, a := range actions {
+		status, _ := a.Metadata["status"].(string)
+		fmt.Fprintf(inv.out, "  %s %s [%s]\n", a.ID, a.ActionOutput(), status)
+	}
+}
+
+// showText prints a labelled block of text.
+func (inv *investigator) showText(label, text string) {
+	if text == "" {
+		fmt.Fprintf(inv.out, "  (no %s)\n", strings.ToLower(label))
+		return
+	}
+	fmt.Fprintf(inv.out, "%s\n%s\n", ui.FormatLabel(label), text)
+}
+
+// rerun replays the last agent action and prints its outcome.
+func (inv *investigator) rerun(ctx context.Context) {
+	action, err := inv.ag.ReplayLastAction(ctx)
+	if action == nil {
+		fmt.Fprintf(inv.out, "  %s\n", ui.FormatWarning(err.Error()))
+		return
+	}
+	fmt.Fprintf(inv.out, "  %s\n", action.ActionOutput())
+	if action.Output != "" {
+		fmt.Fprintln(inv.out, action.Output)
+	}
+	if err != nil {
+		fmt.Fprintf(inv.out, "  %s\n", ui.FormatWarning("failed: "+err.Error()))
+		return
+	}
+	fmt.Fprintln(inv.out, "  succeeded")
+}
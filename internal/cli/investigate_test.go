@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestInvestigateSuspension(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	orch.AddNote("check the build", "user")
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	oe := errs.NewNavigationError("bad jump", errs.FrozenState{Schedule: "Plan", Process: "P2 Clarify"})
+
+	var out strings.Builder
+	input := "state\nnotes\nactions\nprompt\nrerun\nbogus\nskip\n"
+	got := investigateSuspension(context.Background(), oe, orch, ag, bufio.NewReader(strings.NewReader(input)), &out)
+	if got != errs.ActionSkip {
+		t.Errorf("action = %s, want %s", got, errs.ActionSkip)
+	}
+
+	for _, want := range []string{"Flow Code", "check the build", "(no actions)", "(no last prompt)", "no actions to replay", "Unknown command"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
+
+func TestInvestigateSuspension_EOFAborts(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	oe := errs.NewNavigationError("bad jump", errs.FrozenState{})
+
+	var out strings.Builder
+	got := investigateSuspension(context.Background(), oe, orch, ag, bufio.NewReader(strings.NewReader("state")), &out)
+	if got != errs.ActionAbort {
+		t.Errorf("action at EOF = %s, want %s", got, errs.ActionAbort)
+	}
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/croberts/obot/internal/isolate"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// startIsolation copies the workspace, starts its container, and moves into
+// the copy so that every file action of the run lands there.
+func startIsolation(ctx context.Context, workspace, image string) (*isolate.Workspace, error) {
+	iso, err := isolate.New(workspace)
+	if err != nil {
+		return nil, err
+	}
+	image = isolate.ImageFor(workspace, image)
+	fmt.Printf("%s %s\n", ui.FormatLabel("Isolated"), ui.FormatBullet()+ui.FormatValue("Starting "+image+"..."))
+	if err := iso.Start(ctx, image); err != nil {
+		iso.Close()
+		return nil, err
+	}
+	if err := os.Chdir(iso.Copy); err != nil {
+		iso.Close()
+		return nil, err
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Isolated"), ui.FormatBullet()+ui.FormatValue("Working in "+iso.Copy))
+	return iso, nil
+}
+
+// finishIsolation returns to the host workspace and stops the container.
+// After a completed run the changes are shown and applied to the host if
+// the user approves; otherwise a copy with changes is kept for inspection
+// and one without is removed.
+func finishIsolation(iso *isolate.Workspace, completed bool, in *bufio.Reader, out io.Writer) {
+	_ = os.Chdir(iso.Host)
+	iso.Stop()
+	if !completed {
+		if changes, err := iso.Changes(); err == nil && len(changes) == 0 {
+			iso.Close()
+			return
+		}
+		fmt.Fprintln(out, ui.FormatWarning("Run did not complete; isolated changes kept in "+iso.Copy))
+		return
+	}
+
+	changes, err := iso.Changes()
+	if err != nil {
+		fmt.Fprintln(out, ui.FormatWarning("Failed to diff isolated workspace: "+err.Error()+"; changes kept in "+iso.Copy))
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(out, ui.FormatValueMuted("Isolated run made no changes."))
+		iso.Close()
+		return
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "%s %s\n", ui.FormatLabelBold("Isolated"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d changed files", len(changes))))
+	for _, c := range changes {
+		fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted(string(c.Kind)), ui.FormatValue(c.Path))
+	}
+	fmt.Fprint(out, "Show the diff? [y/N] ")
+	if confirmed(in) {
+		for _, c := range changes {
+			fmt.Fprint(out, c.Diff)
+		}
+	}
+	fmt.Fprint(out, "Apply these changes to the workspace? [y/N] ")
+	if !confirmed(in) {
+		fmt.Fprintln(out, ui.FormatValueMuted("Changes not applied; kept in "+iso.Copy))
+		return
+	}
+	if err := iso.Apply(changes); err != nil {
+		fmt.Fprintln(out, ui.FormatWarning("Failed to apply changes: "+err.Error()+"; kept in "+iso.Copy))
+		return
+	}
+	printSuccess(fmt.Sprintf("Applied %d changes to %s", len(changes), iso.Host))
+	iso.Close()
+}
+
+// confirmed reads a yes/no answer, defaulting to no.
+func confirmed(in *bufio.Reader) bool {
+	line, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
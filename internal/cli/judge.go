@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/judge"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/scan"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// judgeConfig returns the judge section of the config.
+func judgeConfig() config.JudgeConfig {
+	if cfg == nil || cfg.Unified == nil {
+		return config.JudgeConfig{}
+	}
+	return cfg.Unified.Judge
+}
+
+// qualityGate returns a termination gate that has the judges review the
+// prompt each time it would terminate and blocks it while the consensus
+// project quality is below the threshold, feeding the recommendations back
+// as notes. It is nil when the judges are off or no threshold is set.
+func qualityGate(orch *orchestrate.Orchestrator, modelCoord *model.Coordinator, ag *agent.Agent, sess *orchsession.Session) orchestrate.TerminationGate {
+	threshold := orchQualityGate
+	if threshold == 0 {
+		threshold = judgeConfig().QualityThreshold
+	}
+	if orchNoJudge || threshold <= 0 {
+		return nil
+	}
+	return func(ctx context.Context) (bool, []string, error) {
+		analysis := judgeSession(ctx, orch, modelCoord, ag, sess)
+		if analysis == nil || analysis.Synthesis == nil {
+			return false, nil, fmt.Errorf("no judge analysis")
+		}
+		quality := analysis.Synthesis.ExpertConsensus.ProjectQualityAvg
+		if quality >= threshold {
+			return true, nil, nil
+		}
+		fmt.Printf("%s %s\n", ui.FormatLabelBold("Judge"), ui.FormatBullet()+ui.FormatWarning(fmt.Sprintf("Quality %.1f%% is below the %.0f%% gate; running another Implement/Production cycle", quality, threshold)))
+		return false, gateNotes(analysis.Synthesis, threshold), nil
+	}
+}
+
+// gateNotes turns a synthesis below the quality gate into notes for the
+// next cycle: the score to beat, then the judges' recommendations.
+func gateNotes(synthesis *judge.SynthesisAnalysis, threshold float64) []string {
+	notes := []string{fmt.Sprintf("Judges rated project quality %.1f%%, below the %.0f%% quality gate; address their recommendations", synthesis.ExpertConsensus.ProjectQualityAvg, threshold)}
+	for _, rec := range synthesis.Recommendations {
+		notes = append(notes, "Judge recommendation: "+rec)
+	}
+	return notes
+}
+
+// judgeSession has the expert judges review the prompt and stores their
+// analysis and rendered TLDR in the session, whose summary then includes
+// the TLDR. It returns the analysis, nil when the judges failed.
+func judgeSession(ctx context.Context, orch *orchestrate.Orchestrator, modelCoord *model.Coordinator, ag *agent.Agent, sess *orchsession.Session) *judge.Analysis {
+	fmt.Printf("%s %s\n", ui.FormatLabelBold("Judge"), ui.FormatBullet()+ui.FormatValue("Reviewing the session..."))
+
+	coord := judge.NewCoordinator(
+		modelCoord.Get(orchestrate.ModelOrchestrator),
+		modelCoord.Get(orchestrate.ModelCoder),
+		modelCoord.Get(orchestrate.ModelResearcher),
+		modelCoord.Get(orchestrate.ModelVision),
+	)
+	if err := coord.Configure(judgeConfig()); err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judge config ignored: "+err.Error())
+	}
+	coord.SetRenderWidth(term.BoxWidth(os.Stdout))
+	coord.SetTokenCallback(modelCoord.RecordClientTokens)
+
+	analysis, err := coord.Analyze(ctx, sess.GetID(), judgeInput(ctx, orch, ag, sess))
+	if err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judge analysis failed: "+err.Error())
+		return nil
+	}
+	if len(analysis.Failures) > 0 {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judges without a report: "+strings.Join(analysis.Failures, ", "))
+	}
+	tldr, err := coord.GetFinalReport(sess.GetID())
+	if err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judge analysis failed: "+err.Error())
+		return nil
+	}
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judge analysis not saved: "+err.Error())
+		return nil
+	}
+	sess.SetAnalysis(data, tldr)
+	return analysis
+}
+
+// sessionAnalysis returns the judges' analysis stored in sess, nil when the
+// session was not judged.
+func sessionAnalysis(sess *orchsession.Session) *judge.Analysis {
+	data, _ := sess.GetAnalysis()
+	if data == nil {
+		return nil
+	}
+	var analysis judge.Analysis
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil
+	}
+	return &analysis
+}
+
+// judgeInput assembles what the judges review from the session: the
+// agent's actions with the last test and lint results, the errors, the
+// sourced notes, the dependency and health findings, the diffs since the
+// run started, the docs coverage of the changed files, the build and vet
+// of the changed Go packages, the last measured test coverage, and, with
+// --mutation, mutation testing of the changed packages.
+func judgeInput(ctx context.Context, orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session) *judge.ExpertInput {
+	input := &judge.ExpertInput{
+		OriginalPrompt: sess.GetPrompt(),
+		FlowCode:       orch.GetFlowCode(),
+		Sources:        judge.SourcesFromNotes(orch.GetNotes()),
+		Findings:       judge.DependencyIssues(dependencyFindings(orch)),
+	}
+	for _, a := range ag.GetActions() {
+		input.Actions = append(input.Actions, strings.TrimPrefix(a.ActionOutput(), "Agent • "))
+		if a.ToolResult == nil {
+			continue
+		}
+		switch a.Type {
+		case agent.ActionTest:
+			results := &judge.TestResults{Total: len(a.ToolResult.Tests), Output: a.Output}
+			for _, t := range a.ToolResult.Tests {
+				switch t.Status {
+				case agent.TestPassed:
+					results.Passed++
+				case agent.TestFailed:
+					results.Failed++
+				}
+			}
+			input.TestResults = results
+		case agent.ActionLint:
+			results := &judge.LintResults{Output: a.Output}
+			for _, d := range a.ToolResult.Diagnostics {
+				if d.Severity == "error" {
+					results.Errors++
+				} else {
+					results.Warnings++
+				}
+			}
+			input.LintResults = results
+		}
+	}
+	for _, rec := range sess.GetErrors() {
+		input.Errors = append(input.Errors, fmt.Sprintf("%s %s: %s", rec.Code, rec.Component, rec.Message))
+	}
+	for _, rec := range sess.GetConsultations() {
+		if rec.Source == string(consultation.ResponseSourceAISubstitute) {
+			input.Substitutions = append(input.Substitutions, judge.Substitution{Type: rec.Type, Question: rec.Question, Answer: rec.Answer, Persona: rec.Persona})
+		}
+	}
+
+	// A resumed isolated run works in a new copy, which lacks the snapshot
+	// taken at the start
+	err := judge.CollectArtifacts(ctx, ".", input, judge.ArtifactOptions{Base: sess.GetBaseline()})
+	if err != nil && sess.GetBaseline() != "" {
+		err = judge.CollectArtifacts(ctx, ".", input, judge.ArtifactOptions{})
+	}
+	if err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judges review without diffs: "+err.Error())
+	}
+	if len(input.FileChanges) > 0 {
+		changed := make([]string, 0, len(input.FileChanges))
+		for path := range input.FileChanges {
+			changed = append(changed, path)
+		}
+		sort.Strings(changed)
+		if docs, err := judge.MeasureDocsCoverage(".", changed); err == nil {
+			input.Docs = docs
+		}
+		if health, err := scan.NewHealthScanner(".").ScanFiles(changed); err == nil {
+			input.Findings = append(input.Findings, judge.HealthIssues(highSeverity(health.Issues))...)
+		}
+		if packages := goPackages(changed); len(packages) > 0 {
+			if build, vet, err := judge.RunGoChecks(ctx, ".", packages); err == nil {
+				input.Build, input.Vet = build, vet
+			}
+			if mutation := judgeConfig().Mutation; orchMutation || mutation.Enabled {
+				input.Mutation = mutationResults(ctx, mutation, packages)
+			}
+		}
+	}
+	if records := sess.GetCoverage(); len(records) > 0 {
+		last := records[len(records)-1]
+		input.Coverage = &judge.CoverageResults{Tool: last.Tool, Percent: last.After, Statements: last.Statements}
+	}
+	return input
+}
+
+// highSeverity returns the high-severity health issues, the ones worth the
+// judges' attention.
+func highSeverity(issues []scan.HealthIssue) []scan.HealthIssue {
+	var high []scan.HealthIssue
+	for _, i := range issues {
+		if i.Severity == scan.SeverityHigh {
+			high = append(high, i)
+		}
+	}
+	return high
+}
+
+// mutationResults runs mutation testing on the changed Go packages. It
+// returns nil when the tester failed, leaving project quality to the
+// experts.
+func mutationResults(ctx context.Context, cfg config.MutationConfig, packages []string) *judge.MutationResults {
+	fmt.Printf("%s %s\n", ui.FormatLabelBold("Judge"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("Mutation testing %d packages...", len(packages))))
+	results, err := judge.RunMutationTesting(ctx, ".", judge.MutationOptions{Command: cfg.Command, Packages: packages})
+	if err != nil {
+		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Judges score quality without mutation testing: "+err.Error())
+		return nil
+	}
+	return results
+}
+
+// goPackages returns the package patterns of the changed Go files that
+// still exist. Files the go command ignores, below testdata, vendor, or a
+// directory starting with . or _, are left out: building them as packages
+// fails.
+func goPackages(changed []string) []string {
+	seen := make(map[string]bool)
+	var packages []string
+	for _, file := range changed {
+		if !strings.HasSuffix(file, ".go") || ignoredByGo(file) {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		pkg := path.Dir(file)
+		if pkg != "." {
+			pkg = "./" + pkg
+		}
+		if !seen[pkg] {
+			seen[pkg] = true
+			packages = append(packages, pkg)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// ignoredByGo reports whether the go command leaves the file out of
+// package patterns such as ./...
+func ignoredByGo(file string) bool {
+	dirs := strings.Split(path.Dir(file), "/")
+	for _, dir := range dirs {
+		if dir == "testdata" || dir == "vendor" || strings.HasPrefix(dir, "_") || strings.HasPrefix(dir, ".") && dir != "." && dir != ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// followUpPrompt seeds a run continuing a judged session with the previous
+// TLDR's recommendations and unresolved issues. An empty prompt repeats the
+// previous session's.
+func followUpPrompt(previous *orchsession.Session, prompt string) (string, error) {
+	data, _ := previous.GetAnalysis()
+	if data == nil {
+		return "", fmt.Errorf("continue session %s: the session was not judged", previous.GetID())
+	}
+	var analysis judge.Analysis
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return "", fmt.Errorf("continue session %s: %w", previous.GetID(), err)
+	}
+	if analysis.Synthesis == nil {
+		return "", fmt.Errorf("continue session %s: the judges produced no synthesis", previous.GetID())
+	}
+	if strings.TrimSpace(prompt) == "" {
+		prompt = previous.GetPrompt()
+	}
+	return judge.FollowUpPrompt(prompt, previous.GetID(), analysis.Synthesis), nil
+}
+
+// linkContinuation records the lineage of a follow-up run in both sessions.
+func linkContinuation(previous, sess *orchsession.Session) {
+	sess.SetContinues(previous.GetID())
+	previous.AddContinuation(sess.GetID())
+	if err := previous.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to save session "+previous.GetID()+": "+err.Error()))
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Session"), ui.FormatBullet()+ui.FormatValue("Continues "+previous.GetID()))
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/scan"
+)
+
+func TestGoPackages(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, file := range []string{"main.go", "internal/a/a.go", "internal/a/a_test.go", "internal/b/b.go",
+		"internal/a/testdata/case.go", "vendor/x/x.go", "_examples/e.go", ".tools/t.go"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("package p\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// internal/c/c.go was deleted
+	got := goPackages([]string{"internal/a/a.go", "internal/a/a_test.go", "main.go", "README.md", "internal/c/c.go",
+		"internal/a/testdata/case.go", "vendor/x/x.go", "_examples/e.go", ".tools/t.go", "internal/b/b.go"})
+	if want := ". ./internal/a ./internal/b"; strings.Join(got, " ") != want {
+		t.Errorf("goPackages() = %q, want %s", got, want)
+	}
+}
+
+func TestDependencyFindings(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	findings := []scan.DependencyFinding{
+		{
+			HealthIssue: scan.HealthIssue{Type: scan.TypeVulnerability, Severity: scan.SeverityHigh, Message: "golang.org/x/net@v0.20.0 GO-2024-0001"},
+			Fix:         "upgrade golang.org/x/net to v0.23.0",
+		},
+		{HealthIssue: scan.HealthIssue{Type: scan.TypeLicense, Severity: scan.SeverityLow, Message: "mystery@0.1.0: license (unknown) could not be determined"}},
+	}
+	// Analyze was retried and scanned again
+	recordDependencyFindings(orch, findings)
+	recordDependencyFindings(orch, findings)
+	if notes := orch.GetNotes(); len(notes) != 2 {
+		t.Errorf("notes = %+v, want one per finding", notes)
+	}
+
+	got := dependencyFindings(orch)
+	if len(got) != 2 {
+		t.Fatalf("dependencyFindings() = %+v, want 2", got)
+	}
+	for i, f := range got {
+		if f.Type != findings[i].Type || f.Severity != findings[i].Severity || f.Message != findings[i].Message || f.Fix != findings[i].Fix {
+			t.Errorf("dependencyFindings()[%d] = %+v, want %+v", i, f, findings[i])
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/junit"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// writeJUnitOut writes the Verify process's test results to path as JUnit
+// XML for --junit-out, a suite per path tested.
+func writeJUnitOut(path string, ag *agent.Agent) {
+	if path == "" {
+		return
+	}
+	report := junit.New("obot verify")
+	for _, a := range verifyResults(ag.GetActions()) {
+		if a.Type == agent.ActionTest {
+			report.AddSuite(a)
+		}
+	}
+	if err := report.Write(path); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write JUnit XML: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("JUnit"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d tests, %d failed → %s", report.Tests, report.Failures, path)))
+}
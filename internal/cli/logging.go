@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/logging"
+)
+
+// closeLog closes the log file opened by setupLogging.
+var closeLog = func() error { return nil }
+
+// logDir returns the directory of the log files, ~/.config/ollamabot/logs.
+func logDir() string {
+	return filepath.Join(config.UnifiedConfigDir(), "logs")
+}
+
+// setupLogging opens the rotating log at --log-level and records the
+// command being run. A log that cannot be opened is reported and skipped:
+// it never stops a command.
+func setupLogging(cmd *cobra.Command) error {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	closeFn, err := logging.Setup(logging.Options{Dir: logDir(), Level: level})
+	if err != nil {
+		printWarning("Logging disabled: " + err.Error())
+		return nil
+	}
+	closeLog = closeFn
+	slog.Info("command started", "command", cmd.CommandPath(), "version", version)
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/croberts/obot/internal/agent"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// newLoopDetector creates the loop detector from the unified config.
+func newLoopDetector() *agent.LoopDetector {
+	if cfg == nil || cfg.Unified == nil {
+		return agent.NewLoopDetector(agent.DefaultLoopMaxRepeats, agent.DefaultLoopSimilarity)
+	}
+	ld := cfg.Unified.Orchestration.LoopDetection
+	return agent.NewLoopDetector(ld.MaxRepeats, ld.Similarity)
+}
+
+// maxRepeatedFailures returns the failing runs of a command in a process
+// that the loop detector takes for a loop, from the process budget of the
+// unified config; 0 uses the detector's repeats.
+func maxRepeatedFailures(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) int {
+	if cfg == nil || cfg.Unified == nil {
+		return 0
+	}
+	return cfg.Unified.GetProcessBudget(orchestrate.ProcessNames[schedID][procID]).MaxRepeatedFailures
+}
+
+// runWithLoopRecovery executes a process and, when the loop detector finds the
+// agent repeating itself, changes strategy: first re-run with an escalation
+// prompt, then re-run on a different model, and then abandon the process and
+// force a Plan schedule revisit. The strategies escalate over every run of
+// the process, so a loop found after all of them fails the process with
+// errRecoveryExhausted.
+func runWithLoopRecovery(
+	ctx context.Context,
+	orch *orchestrate.Orchestrator,
+	ag *agent.Agent,
+	ld *agent.LoopDetector,
+	sess *orchsession.Session,
+	schedID orchestrate.ScheduleID,
+	procID orchestrate.ProcessID,
+	guidance string,
+	run func(ctx context.Context, guidance string) error,
+) error {
+	defer ag.SetModelOverride("")
+
+	processName := orchestrate.ProcessNames[schedID][procID]
+	for {
+		err := run(ctx, guidance)
+		loop := ld.Detected()
+		if loop == nil || ctx.Err() != nil {
+			return err
+		}
+
+		fmt.Printf("%s %s\n", ui.FormatWarning("Loop"), ui.FormatBullet()+ui.FormatValue(loop.Reason))
+		orch.AddNote(fmt.Sprintf("Loop detected in %s: %s (strategy: %s)", processName, loop.Reason, loop.Strategy), "system")
+		recordSessionError(sess, errs.NewProcessError(loop, "Agent", frozenState(orch, ag, schedID, procID)), loop.Strategy.String())
+
+		switch loop.Strategy {
+		case agent.StrategyEscalate:
+			guidance = appendGuidance(guidance, loop.EscalationPrompt())
+		case agent.StrategySwitchModel:
+			alt := agent.AlternateModel(orchestrate.GetScheduleModel(schedID))
+			ag.SetModelOverride(alt)
+			guidance = appendGuidance(guidance, loop.EscalationPrompt())
+			fmt.Printf("%s %s\n", ui.FormatLabel("Loop"), ui.FormatBullet()+ui.FormatValue("Switching to "+alt.String()+" model"))
+		case agent.StrategyRevisitPlan:
+			fmt.Printf("%s %s\n", ui.FormatLabel("Loop"), ui.FormatBullet()+ui.FormatValue("Revisiting Plan schedule"))
+			return orch.RequestScheduleRevisit(orchestrate.SchedulePlan, loop.Reason)
+		default:
+			fmt.Printf("%s %s\n", ui.FormatWarning("Loop"), ui.FormatBullet()+ui.FormatValue("Every recovery strategy was tried, escalating"))
+			return fmt.Errorf("%w: %w", errRecoveryExhausted, loop)
+		}
+	}
+}
+
+// appendGuidance joins extra guidance onto existing guidance.
+func appendGuidance(guidance, extra string) string {
+	if guidance == "" {
+		return extra
+	}
+	if extra == "" {
+		return guidance
+	}
+	return guidance + "\n\n" + extra
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// profilesFile holds the process profiles, in the workspace's .obot
+// directory or the global config directory.
+const profilesFile = "profiles.json"
+
+// profilesPath returns where the process profiles of workspace are kept:
+// its .obot directory when it has one, so each project learns its own
+// profiles, otherwise the global config directory.
+func profilesPath(workspace string) string {
+	if workspace != "" {
+		if info, err := os.Stat(filepath.Join(workspace, ".obot")); err == nil && info.IsDir() {
+			return filepath.Join(workspace, ".obot", profilesFile)
+		}
+	}
+	return filepath.Join(config.UnifiedConfigDir(), profilesFile)
+}
+
+// memoryFeedback predicts the memory of each process, checks the prediction
+// against the process's actual peak, and widens the monitor's safety margin
+// by the recent errors. Peaks and durations are kept as profiles across
+// sessions, so the first run of a process already has a history.
+type memoryFeedback struct {
+	resMon   *resource.Monitor
+	viz      *ui.MemoryVisualization
+	profiles *resource.Profiles
+	model    string
+	started  time.Time
+}
+
+func newMemoryFeedback(resMon *resource.Monitor, profiles *resource.Profiles) *memoryFeedback {
+	viz := ui.NewMemoryVisualization(io.Discard, 0)
+	viz.SetTotalMemory(resMon.GetTotalMemory())
+	return &memoryFeedback{resMon: resMon, viz: viz, profiles: profiles}
+}
+
+// begin predicts the memory of the process about to run with model and
+// starts tracking its peak.
+func (f *memoryFeedback) begin(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, model string) {
+	gb, label, basis := f.viz.PredictForProcess(schedID, procID)
+	if prof, ok := f.profiles.Get(schedID, procID, model); ok {
+		gb = prof.AverageMemoryGB()
+		basis = fmt.Sprintf("%s profile (n=%d, ~%s)", model, len(prof.MemoryGB), prof.AverageDuration().Round(time.Second))
+	}
+	if margin := f.resMon.GetSafetyMargin(); margin > 0 {
+		basis += fmt.Sprintf(", %.2f GB margin", margin)
+	}
+	f.viz.SetPrediction(gb, label, basis)
+	f.model = model
+	f.started = time.Now()
+	f.resMon.BeginProcess()
+}
+
+// end records the process's actual peak and how far the prediction was
+// from it, then updates the safety margin, the accuracy for the summary,
+// and the stored profile.
+func (f *memoryFeedback) end(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) {
+	peak := f.resMon.EndProcess(schedID, procID)
+	predicted, label, basis := f.viz.GetPrediction()
+
+	f.viz.Update(peak, 0)
+	f.viz.RecordPredictionAccuracy()
+	f.viz.RecordProcessUsage(schedID, procID, peak)
+
+	margin := f.viz.SafetyMarginGB()
+	f.resMon.SetSafetyMargin(margin)
+	f.resMon.SetPredictionAccuracy(f.viz.GetPredictionAccuracy())
+	slog.Debug("memory prediction checked", "process", label, "basis", basis,
+		"predicted_gb", predicted, "actual_gb", peak, "margin_gb", margin)
+
+	f.profiles.Record(schedID, procID, f.model, peak, time.Since(f.started))
+	if err := f.profiles.Save(); err != nil {
+		slog.Warn("failed to save process profiles", "error", err)
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/croberts/obot/internal/notify"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// runNotifier alerts the user when an orchestration needs them or ends.
+var runNotifier atomic.Pointer[notify.Notifier]
+
+// setupNotifier creates runNotifier for the --notify mode, or for
+// platforms.cli.notify when the flag is not given. The bell rings only on
+// a terminal.
+func setupNotifier(mode string) error {
+	if mode == "" && cfg != nil && cfg.Unified != nil {
+		mode = cfg.Unified.Platforms.CLI.Notify
+	}
+	m, err := notify.ParseMode(mode)
+	if err != nil {
+		return err
+	}
+	var bell io.Writer
+	if term.IsTerminal(os.Stdout) {
+		bell = os.Stdout
+	}
+	runNotifier.Store(notify.New(m, bell))
+	return nil
+}
@@ -3,24 +3,39 @@ package cli
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/audit"
+	"github.com/croberts/obot/internal/config"
 	"github.com/croberts/obot/internal/consultation"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/index"
+	"github.com/croberts/obot/internal/isolate"
+	"github.com/croberts/obot/internal/judge"
+	"github.com/croberts/obot/internal/logging"
 	"github.com/croberts/obot/internal/model"
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/orchestrate"
 	"github.com/croberts/obot/internal/planner"
 	"github.com/croberts/obot/internal/resource"
 	"github.com/croberts/obot/internal/router"
+	"github.com/croberts/obot/internal/scan"
 	"github.com/croberts/obot/internal/schedule"
+	"github.com/croberts/obot/internal/tools"
 	orchsession "github.com/croberts/obot/internal/session"
 	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
+	"github.com/croberts/obot/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -29,16 +44,34 @@ var (
 	orchHub           string
 	orchLab           string
 	orchSessionID     string
+	orchContinue      string
 	orchListSessions  bool
 	orchRestoreState  string
 	orchDryRun        bool
 	orchExportPath    string
+	orchSummaryOut    string
+	orchSARIFOut      string
+	orchJUnitOut      string
 	orchMemoryLimit   string
 	orchTokenLimit    int64
 	orchTimeout       string
-	orchNoColors      bool
 	orchNoMemGraph    bool
 	orchNoAnimations  bool
+	orchForce         bool
+	orchCompileCheck  bool
+	orchNoJudge       bool
+	orchNotify        string
+	orchQualityGate   float64
+	orchMutation      bool
+	orchIsolated      bool
+	orchIsolatedImage string
+	orchHooks         bool
+	orchApprovePlan   bool
+	orchPlanDrift     string
+
+	orchNoProjectReport bool
+	orchNoCoverage      bool
+	orchChangelog       bool
 )
 
 var orchestrateCmd = &cobra.Command{
@@ -61,6 +94,19 @@ NAVIGATION RULES:
   - From P2: go to P1, P2, or P3
   - From P3: go to P2, P3, or terminate schedule
 
+CUSTOM SCHEDULES:
+  Additional schedules (up to 4) can be defined under orchestration.schedules
+  in ~/.config/ollamabot/config.yaml with an id, three processes, a model
+  role, and optional consultation settings. They run before Production.
+
+WORKSPACE CONFIGURATION:
+  The workspace's obot.yaml sets the team's prompt templates, limits, model
+  overrides, policy rules, hooks, custom schedules, and defaults for these
+  flags. Flags given on the command line take precedence. Check it with
+  obot config validate. Its hooks, strategy script, and output paths are
+  used only once you trust the file (asked on first use, or obot config
+  trust).
+
 HUMAN CONSULTATION:
   - Clarify (Plan schedule): Optional, on ambiguity detection
   - Feedback (Implement schedule): Mandatory
@@ -69,12 +115,22 @@ PROMPT TERMINATION:
   - All 5 schedules must have run at least once
   - Production must be the last terminated schedule
   - Orchestrator must justify no further improvement is possible
+  - With judge.quality_threshold set, the judges' project quality must reach it
+
+FOLLOW-UP RUNS:
+  --continue <session> starts a new session whose prompt carries the judged
+  session's recommendations and unresolved issues. Both sessions record the
+  lineage.
 
 EXAMPLES:
   obot orchestrate
   obot orchestrate "Build a REST API"
   obot orchestrate --hub "my-api" "Build a REST API"
   obot orchestrate --session abc123
+  obot orchestrate --continue abc123
+  obot orchestrate --approve-plan "Build a REST API"
+  obot orchestrate --summary-out report.md "Build a REST API"
+  obot orchestrate --project services/api "Add rate limiting"
   obot orchestrate --list-sessions`,
 	Args:                  cobra.ArbitraryArgs,
 	DisableFlagsInUseLine: true,
@@ -88,23 +144,58 @@ func init() {
 
 	// Session management flags
 	orchestrateCmd.Flags().StringVar(&orchSessionID, "session", "", "Resume existing session by ID")
+	orchestrateCmd.Flags().StringVar(&orchContinue, "continue", "", "Start a run seeded with a judged session's recommendations and unresolved issues")
 	orchestrateCmd.Flags().BoolVar(&orchListSessions, "list-sessions", false, "List all sessions")
 	orchestrateCmd.Flags().StringVar(&orchRestoreState, "restore", "", "Restore to specific state")
 	orchestrateCmd.Flags().StringVar(&orchExportPath, "export", "", "Export session to path")
+	orchestrateCmd.Flags().StringVar(&orchSummaryOut, "summary-out", "", "Write the prompt summary to a file: .md, .html, .json, or plain text")
+	orchestrateCmd.Flags().StringVar(&orchSARIFOut, "sarif-out", "", "Write Verify lint and test failures and judge issues to a SARIF file, also when the run fails or is interrupted")
+	orchestrateCmd.Flags().StringVar(&orchJUnitOut, "junit-out", "", "Write Verify test results to a JUnit XML file, also when the run fails or is interrupted")
+	orchestrateCmd.Flags().BoolVar(&orchChangelog, "changelog", false, "Add an entry for the prompt's changes to CHANGELOG.md when it completes")
+	orchestrateCmd.Flags().BoolVar(&orchForce, "force", false, "Take over a stale workspace lock")
 
 	// Resource limit flags
 	orchestrateCmd.Flags().StringVar(&orchMemoryLimit, "memory-limit", "", "Set memory limit (e.g., 8GB)")
 	orchestrateCmd.Flags().Int64Var(&orchTokenLimit, "token-limit", 0, "Set token limit (0 = unlimited)")
 	orchestrateCmd.Flags().StringVar(&orchTimeout, "timeout", "", "Set overall timeout (e.g., 30m, 2h)")
+	orchestrateCmd.Flags().Float64Var(&orchHangFactor, "hang-factor", 3, "Report a process running this many times its typical duration as possibly hung (0 = never)")
 
 	// UI flags
-	orchestrateCmd.Flags().BoolVar(&orchNoColors, "no-colors", false, "Disable ANSI colors")
 	orchestrateCmd.Flags().BoolVar(&orchNoMemGraph, "no-memory-graph", false, "Disable memory visualization")
 	orchestrateCmd.Flags().BoolVar(&orchNoAnimations, "no-animations", false, "Disable animations")
+	orchestrateCmd.Flags().IntVar(&orchClarifyTimeout, "clarify-timeout", -1, "Seconds to answer a Clarify consultation, 0 for no limit (default: orchestration.consultation.clarify)")
+	orchestrateCmd.Flags().IntVar(&orchFeedbackTimeout, "feedback-timeout", -1, "Seconds to answer a Feedback consultation, 0 for no limit (default: orchestration.consultation.feedback)")
+	orchestrateCmd.Flags().BoolVar(&orchNoAISubstitute, "no-ai-substitute", false, "Never let a model answer a consultation that timed out")
+	orchestrateCmd.Flags().StringVar(&orchSubPersona, "substitute-persona", "", "How the AI substitute answers: permissive, conservative, or a description of the reviewer (default: orchestration.consultation.substitute_persona)")
+	orchestrateCmd.Flags().StringVar(&orchNotify, "notify", "", "Notify when a consultation opens or the run ends: off, bell, desktop, or all (default: platforms.cli.notify)")
+
+	// Planning
+	orchestrateCmd.Flags().BoolVar(&orchApprovePlan, "approve-plan", false, "Review, edit, and approve the pre-schedule plan before orchestration starts; the Plan schedule keeps to it")
+	orchestrateCmd.Flags().StringVar(&orchStrategy, "strategy", "", "How the next schedule and process are decided: heuristic, llm, hybrid (llm within guardrails), or script (default heuristic)")
+	orchestrateCmd.Flags().StringVar(&orchStrategyScript, "strategy-script", "", "Program deciding for --strategy script: reads the decision context as JSON on stdin and prints {\"next\": N}")
+	orchestrateCmd.Flags().StringVar(&orchPlanDrift, "plan-drift", planDriftRevisit, "When work drifts from the approved plan or leaves high-risk subtasks untouched: off, warn, or revisit (warn and revisit the Plan schedule)")
 
 	// Dry run
 	orchestrateCmd.Flags().BoolVar(&orchDryRun, "dry-run", false, "Simulate without executing")
 
+	// Verification
+	orchestrateCmd.Flags().BoolVar(&orchCompileCheck, "compile-check", false, "Compile-check each written source file and have the model repair errors")
+	orchestrateCmd.Flags().BoolVar(&orchNoJudge, "no-judge", false, "Skip the expert judges' review of the finished prompt")
+	orchestrateCmd.Flags().BoolVar(&orchNoProjectReport, "no-project-report", false, "Do not write PROJECT_REPORT.md into the workspace when the prompt completes")
+	orchestrateCmd.Flags().Float64Var(&orchQualityGate, "quality-gate", 0, "Judge quality (0-100) a prompt must reach to terminate (default: judge.quality_threshold)")
+	orchestrateCmd.Flags().BoolVar(&orchNoCoverage, "no-coverage", false, "Do not measure test coverage in Verify or write tests for its gaps in Scale and Production")
+	orchestrateCmd.Flags().BoolVar(&orchMutation, "mutation", false, "Run mutation testing on the changed Go packages and blend the score into the judges' project quality")
+
+	// Isolation
+	orchestrateCmd.Flags().BoolVar(&orchIsolated, "isolated", false, "Run in a container on a copy of the workspace and review the changes before applying them")
+	orchestrateCmd.Flags().StringVar(&orchIsolatedImage, "isolated-image", "", "Container image for --isolated (default: the devcontainer image)")
+
+	// Hooks
+	orchestrateCmd.Flags().BoolVar(&orchHooks, "hooks", false, "Run the workspace's hooks, the scripts in .obot/hooks and the commands of obot.yaml, around processes and schedules")
+
+	// Monorepos
+	orchestrateCmd.Flags().StringVar(&orchProject, "project", "", "Work on this subdirectory of a monorepo: writes and verification stay within it, other projects are read-only")
+
 	// Add to root command
 	rootCmd.AddCommand(orchestrateCmd)
 }
@@ -120,31 +211,141 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		return restoreOrchestrateState(orchRestoreState)
 	}
 
+	// Every question the run asks on the terminal reads through one buffered
+	// reader, so input typed ahead is not lost between them
+	stdin := bufio.NewReader(os.Stdin)
+
+	// Take the team's standard configuration from the workspace's obot.yaml;
+	// flags given on the command line take precedence
+	if wd, err := os.Getwd(); err == nil {
+		if err := setupWorkspaceConfig(cmd, wd, stdin); err != nil {
+			return err
+		}
+	}
+
+	if err := setupNotifier(orchNotify); err != nil {
+		return fmt.Errorf("--notify: %w", err)
+	}
+	if err := checkPlanDrift(orchPlanDrift); err != nil {
+		return fmt.Errorf("--plan-drift: %w", err)
+	}
+	if err := consultation.ValidatePersona(substitutePersona()); err != nil {
+		return fmt.Errorf("substitute persona: %w", err)
+	}
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Fprintln(os.Stderr, "\n"+ui.FormatWarning("Received interrupt signal, shutting down gracefully..."))
-		cancel()
-	}()
-
 	// Print banner
 	printOrchestrateBanner()
+	if ws := runWorkspace.Load(); ws != nil {
+		fmt.Printf("%s %s\n", ui.FormatLabel("Config"), ui.FormatBullet()+ui.FormatValue(ws.Path()))
+	}
+
+	baseDir := orchsession.DefaultBaseDir()
+	store, err := newSessionStorage(baseDir)
+	if err != nil {
+		return fmt.Errorf("session storage: %w", err)
+	}
+
+	// Within a monorepo the project is the workspace
+	if orchProject != "" && orchIsolated {
+		return fmt.Errorf("--project and --isolated cannot be combined")
+	}
+	scope, err := enterProject(orchProject)
+	if err != nil {
+		return fmt.Errorf("--project: %w", err)
+	}
+
+	// Resume the requested session, or offer to resume one that crashed or
+	// was killed in this workspace
+	workspace, _ := os.Getwd()
+	var resumed, previous *orchsession.Session
+	if orchContinue != "" {
+		if orchSessionID != "" {
+			return fmt.Errorf("--continue and --session cannot be combined")
+		}
+		previous, err = orchsession.LoadWithStorage(baseDir, store, orchContinue)
+		if err != nil {
+			return fmt.Errorf("continue session %s: %w", orchContinue, err)
+		}
+	}
+	if orchSessionID != "" {
+		resumed, err = orchsession.LoadWithStorage(baseDir, store, orchSessionID)
+		if err != nil {
+			return fmt.Errorf("resume session %s: %w", orchSessionID, err)
+		}
+	} else if len(args) == 0 && previous == nil {
+		resumed = offerCrashRecovery(baseDir, store, workspace, stdin, os.Stdout)
+	}
+
+	// Initialize session
+	sess := resumed
+	if sess == nil {
+		sess = orchsession.NewSessionWithBaseDir(baseDir)
+		sess.SetStorage(store)
+	}
+
+	ctx = logging.WithAttrs(ctx, "session", sess.GetID())
+	slog.InfoContext(ctx, "orchestration started", "workspace", workspace, "resumed", resumed != nil)
+
+	// Only one run may orchestrate a workspace at a time
+	wsLock, err := orchsession.AcquireWorkspaceLock(workspace, sess.GetID(), orchForce)
+	if err != nil {
+		return workspaceLockError(err)
+	}
+	defer wsLock.Release()
+
+	// Work on a copy of the workspace inside a container; the changes are
+	// reviewed and applied to the host when the run completes. A forced
+	// exit finishes the isolation too, so that the container does not
+	// outlive the run
+	var iso *isolate.Workspace
+	completed := false
+	closeIsolation := func(completed bool) {}
+	if orchIsolated {
+		iso, err = startIsolation(ctx, workspace, orchIsolatedImage)
+		if err != nil {
+			return fmt.Errorf("isolated workspace: %w", err)
+		}
+		var isoOnce sync.Once
+		closeIsolation = func(completed bool) {
+			isoOnce.Do(func() { finishIsolation(iso, completed, stdin, os.Stdout) })
+		}
+		defer func() { closeIsolation(completed) }()
+	}
+
+	// The judges review the changes made since the run started, not the
+	// ones the user had not committed yet
+	if sess.GetBaseline() == "" {
+		if tree, err := judge.Snapshot(ctx, "."); err == nil {
+			sess.SetBaseline(tree)
+		} else {
+			slog.DebugContext(ctx, "workspace not snapshotted", "error", err)
+		}
+	}
 
 	// Build initial prompt from args or prompt user
 	var initialPrompt string
-	if len(args) > 0 {
+	if resumed != nil {
+		initialPrompt = resumed.GetPrompt()
+	} else if len(args) > 0 {
 		initialPrompt = strings.Join(args, " ")
 	}
 
+	// A follow-up run carries the previous session's recommendations; its
+	// prompt defaults to the previous one
+	if previous != nil {
+		initialPrompt, err = followUpPrompt(previous, initialPrompt)
+		if err != nil {
+			return err
+		}
+	}
+
 	// If no prompt provided, prompt user
 	if initialPrompt == "" {
-		initialPrompt = promptForInput()
+		initialPrompt = promptForInput(stdin)
 		if initialPrompt == "" {
 			fmt.Println(ui.FormatValueMuted("No prompt provided. Exiting."))
 			return nil
@@ -159,18 +360,92 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		ui.FormatBullet()+ui.FormatValue(string(intent)),
 		ui.FormatValueMuted("("+modelRole+")"))
 
+	// Register custom schedules defined in the unified config
+	if cfg != nil && cfg.Unified != nil {
+		orchestrate.ClearCustomSchedules()
+		customIDs, err := schedule.RegisterConfiguredSchedules(cfg.Unified.Orchestration)
+		if err != nil {
+			return fmt.Errorf("custom schedules: %w", err)
+		}
+		for _, id := range customIDs {
+			names := orchestrate.ProcessNames[id]
+			fmt.Printf("%s %s %s\n", ui.FormatLabel("Schedule"),
+				ui.FormatBullet()+ui.FormatValue(orchestrate.ScheduleNames[id]),
+				ui.FormatValueMuted(fmt.Sprintf("(custom: %s → %s → %s)", names[orchestrate.Process1], names[orchestrate.Process2], names[orchestrate.Process3])))
+		}
+	}
+
 	// Initialize components
 	orch := orchestrate.NewOrchestrator()
 	orch.SetPrompt(initialPrompt)
 
-	// Initialize session
-	sess := orchsession.NewSession()
-	sess.SetPrompt(initialPrompt)
+	if resumed == nil {
+		sess.SetPrompt(initialPrompt)
+	}
+	if previous != nil {
+		linkContinuation(previous, sess)
+	}
+	if err := sess.AcquireRunLock(workspace); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to lock session: "+err.Error()))
+	}
+	defer sess.ReleaseRunLock()
+	if err := sess.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to save session: "+err.Error()))
+	}
 
 	// Initialize resource monitor
 	resMon := resource.NewMonitor()
 	resMon.Start()
 	defer resMon.Stop()
+	runClock.Store(resMon)
+	defer func() { runClock.Store(nil) }()
+
+	// Handle signals: the first aborts the current action and flushes the
+	// session, a second forces exit.
+	var flushOnce sync.Once
+	// The agent is created below while the signal goroutine may already be
+	// forcing an exit, so what runs there reads it through runAgent
+	var ag *agent.Agent
+	var runAgent atomic.Pointer[agent.Agent]
+	// CI reads the findings and test results whether the run completes,
+	// fails, is aborted, or is interrupted, so every exit writes them, once
+	writeSARIF := sync.OnceFunc(func() { writeSARIFOut(orchSARIFOut, runAgent.Load(), sess) })
+	writeJUnit := sync.OnceFunc(func() { writeJUnitOut(orchJUnitOut, runAgent.Load()) })
+	flush := func() {
+		flushOnce.Do(func() {
+			ag := runAgent.Load()
+			var summary func()
+			if ag != nil {
+				summary = func() {
+					printPromptSummary(orch, ag, resMon, sess)
+					writeSummaryOut(orchSummaryOut, orch, ag, resMon, sess)
+					writeSARIF()
+					writeJUnit()
+				}
+			}
+			flushInterrupted(orch, ag, sess, summary)
+		})
+	}
+	shutdown := newShutdownCoordinator(cancel, func() {
+		flush()
+		// Background and language server processes run in their own process
+		// groups, out of reach of the terminal's interrupt
+		if ag := runAgent.Load(); ag != nil {
+			ag.StopAllBackground()
+			ag.StopLanguageServers()
+		}
+		runWebhooks.Load().Abort()
+		runChat.Load().Abort()
+		_ = sess.ReleaseRunLock()
+		_ = wsLock.Release()
+		closeIsolation(false)
+		os.Exit(130)
+	}, os.Stderr)
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go shutdown.watch(sigChan)
+	defer shutdown.Done()
 
 	// Initialize Ollama client
 	var ollamaClient *ollama.Client
@@ -179,15 +454,134 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	} else {
 		ollamaClient = ollama.NewClient()
 	}
+	ollamaClient.SetHeartbeat(ollamaHeartbeat())
+	stopOllama, err := ensureOllama(ctx, ollamaClient)
+	if err != nil {
+		return err
+	}
+	defer stopOllama()
 
 	// Initialize model coordinator
 	modelCoord := model.NewCoordinator(ollamaClient)
+	applyWorkspaceModels(modelCoord)
+	if cfg != nil && cfg.Unified != nil {
+		if err := applyModelQuotas(modelCoord, cfg.Unified.Models); err != nil {
+			return err
+		}
+		if err := applyOllamaHosts(ctx, modelCoord, cfg.Unified); err != nil {
+			return err
+		}
+		applyModelTemperatures(modelCoord, cfg.Unified.Models)
+		applyDegradation(modelCoord, cfg.Unified.Models, cfg.Unified.Context.MaxTokens)
+	}
+	recordTranscript(modelCoord, sess)
+	watchForStalls(modelCoord)
+	if err := setupStrategy(modelCoord); err != nil {
+		return err
+	}
+
+	// Track GPU memory apart from RAM, for model selection and the summary
+	resMon.SetGPUProbe(resource.NewGPUProbe(ollamaClient.RunningModels))
+	modelCoord.SetVRAMReporter(resMon)
 
 	// Initialize agent
-	ag := agent.NewAgent(modelCoord)
+	ag = agent.NewAgent(modelCoord)
+	runAgent.Store(ag)
+	if iso != nil {
+		ag.SetCommandWrapper(iso.CommandArgs)
+	}
+	defer writeSARIF()
+	defer writeJUnit()
+
+	// Enforce per-process budgets and detect loops
+	wd := agent.NewWatchdog(agent.DefaultBudget())
+	ag.RegisterPlugin(wd)
+	ld := newLoopDetector()
+	ag.RegisterPlugin(ld)
+
+	// Follow the agent's work against the plan approved with --approve-plan
+	if orchPlanDrift != planDriftOff {
+		ag.RegisterPlugin(newPlanDriftTracker(orch, sess, workspace, orchPlanDrift, os.Stdout))
+	}
+
+	// Record every file and command action in the workspace audit log
+	auditLog, err := audit.Open(audit.DefaultPath(workspace))
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer auditLog.Close()
+	ag.RegisterPlugin(audit.NewPlugin(auditLog, sess.GetID()))
+
+	// Send the run's events to the configured webhooks
+	if err := setupWebhooks(sess.GetID()); err != nil {
+		return err
+	}
+	webhooks := runWebhooks.Load()
+	defer webhooks.Close()
+	if webhooks != nil {
+		orch.RegisterPlugin(webhook.NewPlugin(webhooks))
+	}
+
+	// Post progress updates to the configured team chats
+	if err := setupChat(orch); err != nil {
+		return err
+	}
+	defer runChat.Load().Close()
+
+	// Run the workspace's hooks around processes and schedules when asked to
+	if orchHooks {
+		if plugin := newHookPlugin(workspace, sess.GetID(), iso, stdin); plugin != nil {
+			orch.RegisterPlugin(plugin)
+		}
+	}
+
+	// Save the session as each process ends; after the other plugins, so
+	// their notes are saved too
+	orch.RegisterPlugin(newSessionPlugin(orch, sess))
+
+	// Require confirmation for destructive actions as the workspace policy says
+	policy, err := loadWorkspacePolicy(workspace, workspacePolicyRules(runWorkspace.Load()), stdin)
+	if err != nil {
+		return fmt.Errorf("load policy: %w", err)
+	}
+	ag.SetPolicy(policy, newPolicyConfirmer(stdin, os.Stdout))
+	ag.SetCompileCheck(orchCompileCheck, 0)
+	if cfg != nil && cfg.Unified != nil {
+		ag.SetDatabases(cfg.Unified.Databases)
+		ag.SetToolchains(toolchains(cfg.Unified.Toolchains))
+	}
+	if scope != nil {
+		ag.SetScope(scope)
+		orch.AddTypedNote(orchestrate.NoteConstraint, projectNote(scope, ag.Toolchains()), "monorepo")
+	}
+
+	// Rebuild orchestrator and agent state from a resumed session
+	if resumed != nil {
+		if err := restoreSession(orch, ag, resumed); err != nil {
+			return fmt.Errorf("resume session %s: %w", resumed.GetID(), err)
+		}
+		fmt.Printf("%s %s\n", ui.FormatLabel("Session"), ui.FormatBullet()+ui.FormatValue("Resumed "+resumed.GetID()+" at "+orch.GetFlowCode()))
+	}
+
+	// Keep the prompt running while the judges rate it below the threshold
+	gate := qualityGate(orch, modelCoord, ag, sess)
+	if gate != nil {
+		orch.SetTerminationGate(gate, judgeConfig().MaxGateCycles)
+	}
+
+	// Suspend on process errors and let the user choose how to continue
+	orch.SetErrorHandler(newSuspensionErrorHandler(orch, ag, sess, modelCoord.GetOrchestratorModel(), stdin, os.Stdout))
 
 	// Create status display
-	statusDisplay := ui.NewStatusDisplay(os.Stdout, 80, 250*time.Millisecond)
+	statusDisplay := ui.NewStatusDisplay(os.Stdout, term.Width(os.Stdout), 250*time.Millisecond)
+	stopResize := term.NotifyResize(os.Stdout, statusDisplay.SetWidth)
+	defer stopResize()
+	modelCoord.SetQuotaCallback(newQuotaCallback(orch, statusDisplay))
+	// The orchestrator model's own decisions count against its quota too
+	orch.SetTokenCallback(modelCoord.RecordClientTokens)
+	statusDisplay.SetQuotaStatus(quotaSummary(modelCoord))
+	modelCoord.SetHandoffCallback(newHandoffCallback(orch, sess))
+	modelCoord.SetHostCallback(newHostCallback(orch))
 
 	// Set up orchestrator callbacks
 	orch.SetCallbacks(
@@ -224,12 +618,94 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	// Draw initial status display
 	fmt.Print(ui.FormatLabelBold("Orchestrator") + ui.FormatBullet() + ui.FormatValue("Begin") + "\n")
 
-	// Run pre-orchestration planning (Merges item 278 Planner Integration)
+	// Run pre-orchestration planning (Merges item 278 Planner Integration).
+	// A resumed session already carries its planner notes and approved plan.
+	if resumed == nil {
+		var approve *bufio.Reader
+		if orchApprovePlan {
+			approve = stdin
+		}
+		if err := runPreSchedulePlanning(ctx, orch, sess, initialPrompt, approve); err != nil {
+			sess.SetStatus(orchsession.StatusAborted)
+			saveSession(orch, sess)
+			return fmt.Errorf("orchestration not started: %w", err)
+		}
+	}
+
+	fmt.Print(ui.FormatLabel("Schedule") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
+	fmt.Print(ui.FormatLabel("Process") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
+	fmt.Print(ui.FormatLabel("Agent") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
+	fmt.Println()
+
+	// Start animation loop in background
+	if term.IsTerminal(os.Stdout) {
+		go statusDisplay.RunAnimationLoop()
+	}
+	defer statusDisplay.StopAnimations()
+
+	// Run the orchestration loop
+	err = runOrchestrationLoop(ctx, orch, modelCoord, ag, wd, ld, resMon, sess, statusDisplay, stdin)
+	if shutdown.Interrupted() {
+		shutdown.Done()
+		flush()
+		return nil
+	}
+	shutdown.Done()
+	if errors.Is(err, orchestrate.ErrAborted) {
+		sess.SetStatus(orchsession.StatusAborted)
+		saveSession(orch, sess)
+		return err
+	}
+	if err != nil && err != context.Canceled {
+		recordSessionError(sess, errs.NewProcessError(err, "Orchestrator", currentFrozenState(orch, ag)), "fatal")
+		sess.SetStatus(orchsession.StatusFailed)
+		saveSession(orch, sess)
+		runNotifier.Load().Notify(context.WithoutCancel(ctx), "obot: run failed", err.Error())
+		runChat.Load().Fail(err)
+		return err
+	}
+
+	// Have the expert judges review the prompt, then print the final summary
+	sess.SetStatus(orchsession.StatusCompleted)
+	if !orchNoJudge && gate == nil {
+		judgeSession(ctx, orch, modelCoord, ag, sess)
+	}
+	printPromptSummary(orch, ag, resMon, sess)
+	writeSummaryOut(orchSummaryOut, orch, ag, resMon, sess)
+	writeSARIF()
+	writeJUnit()
+	if sessionAnalysis(sess) != nil {
+		// Add the judges' summary and open issues
+		writeProjectReport(ctx, orch, ag, sess)
+	}
+	writeChangelog(orch, ag, sess)
+	saveSession(orch, sess)
+	completed = true
+	runNotifier.Load().Notify(ctx, "obot: run complete", initialPrompt)
+	_, tldr := sess.GetAnalysis()
+	runChat.Load().Complete(initialPrompt, tldr)
+
+	return nil
+}
+
+// runPreSchedulePlanning builds the pre-schedule plan and feeds its subtasks into the orchestration notes.
+// Given approve, the user reviews and edits the plan from it first, and the
+// approved plan is recorded in sess to bind the Plan schedule; rejecting it
+// returns planner.ErrPlanRejected.
+func runPreSchedulePlanning(ctx context.Context, orch *orchestrate.Orchestrator, sess *orchsession.Session, initialPrompt string, approve *bufio.Reader) error {
 	fmt.Printf("%s %s\n", ui.FormatLabelBold("Planner"), ui.FormatBullet()+ui.FormatValue("Building pre-schedule plan..."))
 	plan, err := planner.BuildPlan(ctx, ".", initialPrompt, planner.DefaultOptions())
 	if err != nil {
 		fmt.Printf("%s %s\n", ui.FormatWarning("⚠"), "Planning failed, continuing with heuristic: "+err.Error())
 	} else if plan != nil && len(plan.Tasks) > 0 {
+		if approve != nil {
+			fmt.Println(ui.FormatValueMuted("  Review the pre-schedule plan:"))
+			if err := planner.Approve(approve, os.Stdout, plan); err != nil {
+				return err
+			}
+			sess.SetApprovedPlan(plannedTasks(plan))
+			orch.AddTypedNote(orchestrate.NoteConstraint, fmt.Sprintf("The human approved a pre-schedule plan of %d subtasks; the Plan schedule keeps to it", len(plan.Tasks)), "planner")
+		}
 		fmt.Println(ui.FormatValueMuted("  Pre-schedule analysis complete:"))
 		for i, task := range plan.Tasks {
 			if i >= 5 {
@@ -245,29 +721,13 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 			fmt.Printf("    %s %s %s\n", riskIcon, ui.FormatValue(task.ID), ui.FormatValueMuted(task.Message))
 
 			// Feed into orchestration notes (Merges item 278 Planner Integration)
-			orch.AddNote(fmt.Sprintf("Planned subtask [%s]: %s (Risk: %s, Rationale: %s)", task.ID, task.Message, task.Risk, task.Rationale), "planner")
+			orch.AddTypedNote(orchestrate.NoteTodo, fmt.Sprintf("Planned subtask [%s]: %s (Risk: %s, Rationale: %s)", task.ID, task.Message, task.Risk, task.Rationale), "planner", task.ID)
+			if task.Risk == planner.RiskHigh {
+				orch.AddTypedNote(orchestrate.NoteRisk, fmt.Sprintf("Subtask [%s] is high risk: %s", task.ID, task.Rationale), "planner", task.ID)
+			}
 		}
 		fmt.Println()
 	}
-
-	fmt.Print(ui.FormatLabel("Schedule") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
-	fmt.Print(ui.FormatLabel("Process") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
-	fmt.Print(ui.FormatLabel("Agent") + ui.FormatBullet() + ui.TextMuted + "..." + ui.Reset + "\n")
-	fmt.Println()
-
-	// Start animation loop in background
-	go statusDisplay.RunAnimationLoop()
-	defer statusDisplay.StopAnimations()
-
-	// Run the orchestration loop
-	err = runOrchestrationLoop(ctx, orch, modelCoord, ag, resMon, sess, statusDisplay)
-	if err != nil && err != context.Canceled {
-		return err
-	}
-
-	// Print final summary
-	printPromptSummary(orch, ag, resMon)
-
 	return nil
 }
 
@@ -277,10 +737,16 @@ func runOrchestrationLoop(
 	orch *orchestrate.Orchestrator,
 	modelCoord *model.Coordinator,
 	ag *agent.Agent,
+	wd *agent.Watchdog,
+	ld *agent.LoopDetector,
 	resMon *resource.Monitor,
 	sess *orchsession.Session,
 	statusDisplay *ui.StatusDisplay,
+	stdin *bufio.Reader,
 ) error {
+	// Decisions are pre-computed while each process runs
+	spec := model.NewSpeculator(modelCoord)
+
 	// Select schedule function - uses the orchestrator model
 	selectScheduleFn := func(ctx context.Context) (orchestrate.ScheduleID, error) {
 		// For first run, start with Knowledge
@@ -289,7 +755,9 @@ func runOrchestrationLoop(
 		}
 
 		// Use the orchestrator model to decide next schedule
-		scheduleID, shouldTerminate, err := modelCoord.SelectNextSchedule(ctx, orch)
+		start := time.Now()
+		scheduleID, shouldTerminate, err := spec.NextSchedule(ctx, orch)
+		resMon.RecordOrchestratorTime(time.Since(start))
 		if err != nil {
 			return 0, err
 		}
@@ -309,7 +777,9 @@ func runOrchestrationLoop(
 		}
 
 		// Use model to decide next process
-		nextProc, shouldTerminate, err := modelCoord.SelectNextProcess(ctx, orch, schedID, lastProc)
+		start := time.Now()
+		nextProc, shouldTerminate, err := spec.NextProcess(ctx, orch, schedID, lastProc)
+		resMon.RecordOrchestratorTime(time.Since(start))
 		if err != nil {
 			return 0, false, err
 		}
@@ -317,21 +787,117 @@ func runOrchestrationLoop(
 		return nextProc, shouldTerminate, nil
 	}
 
-	// Execute process function - runs the agent
-	executeProcessFn := func(ctx context.Context, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) error {
-		// Get the logic handler for this schedule
-		handler := schedule.GetLogicHandler(schedID)
-		if handler != nil {
-			// Execute using the logic handler
-			return handler.ExecuteProcess(ctx, procID, func(ctx context.Context, prompt string) error {
-				modelName := modelCoord.GetModelForSchedule(schedID)
-				return executeAgentProcess(ctx, ag, modelCoord, orch, schedID, procID, modelName, resMon, statusDisplay)
-			})
+	// The Knowledge schedule keeps its documentation index across processes
+	// so that Retrieve can answer questions about the codebase itself
+	knowledge := schedule.NewKnowledgeSchedule()
+	if researcher := modelCoord.Get(orchestrate.ModelResearcher); researcher != nil {
+		if workspace, err := os.Getwd(); err == nil {
+			knowledge.SetDocIndex(index.NewDocIndex(researcher, ""), workspace, orch.GetPrompt())
 		}
+	}
 
-		// Fallback to direct execution if no handler
+	// The Plan and Implement schedules consult the human in Clarify and
+	// Feedback. Clarify offers the answer they gave a like question before;
+	// the orchestrator model answers for them when they do not
+	history := consultationHistory(modelCoord.Get(orchestrate.ModelResearcher))
+	plan := schedule.NewPlanSchedule(newConsultationHandler(consultation.ConsultationClarify, sess, history, modelCoord, stdin))
+	if approved := sess.GetApprovedPlan(); approved != nil {
+		plan.ApprovedPlan = approved.Summaries()
+	}
+	implement := schedule.NewImplementSchedule(newConsultationHandler(consultation.ConsultationFeedback, sess, history, modelCoord, stdin))
+	plan.Response = func() string { _, response := ag.LastExchange(); return response }
+	implement.Risks = func() []string { return feedbackRisks(orch, sess) }
+	implement.Actions = ag.GetActions
+
+	// The Production schedule scans dependencies for vulnerabilities and
+	// license conflicts during Analyze. Unless --no-coverage is given,
+	// Verify measures test coverage with the agent's commands, and Scale
+	// and Production write tests for its gaps before refactoring and in
+	// Harmonize
+	production := schedule.NewProductionSchedule()
+	scale := schedule.NewScaleSchedule()
+	var coverage *schedule.CoverageGuide
+	if workspace, err := os.Getwd(); err == nil {
+		production.SetDependencyScanner(scan.NewDependencyScanner(workspace))
+		if !orchNoCoverage {
+			coverage = schedule.NewCoverageGuide(coverageMeasure(ag, workspace))
+			implement.SetCoverageGuide(coverage)
+			scale.SetCoverageGuide(coverage)
+			production.SetCoverageGuide(coverage)
+		}
+	}
+
+	// Check each process's memory against its prediction, widening the
+	// safety margin that mitigations start from, and keep the profiles for
+	// later sessions
+	workspace, _ := os.Getwd()
+	profiles := resource.NewProfiles(profilesPath(workspace))
+	memFeedback := newMemoryFeedback(resMon, profiles)
+
+	// Report processes running far longer than they did before
+	hangs := newHangDetector(profiles, ag, orchHangFactor, os.Stderr)
+	runHangs.Store(hangs)
+	defer func() { runHangs.Store(nil) }()
+
+	// Execute process function - runs the agent under the watchdog
+	executeProcessFn := func(ctx context.Context, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) error {
+		degradeUnderPressure(orch, modelCoord, resMon, sess, schedID, procID)
 		modelName := modelCoord.GetModelForSchedule(schedID)
-		return executeAgentProcess(ctx, ag, modelCoord, orch, schedID, procID, modelName, resMon, statusDisplay)
+		memFeedback.begin(schedID, procID, modelName)
+		defer memFeedback.end(schedID, procID)
+		defer hangs.watch(ctx, schedID, procID, modelName)()
+		spec.Start(ctx, orch, schedID, procID)
+		runProcess := func(ctx context.Context, guidance string) error {
+			// Get the logic handler for this schedule
+			var handler schedule.LogicHandler
+			switch schedID {
+			case orchestrate.ScheduleKnowledge:
+				handler = knowledge
+			case orchestrate.SchedulePlan:
+				handler = plan
+			case orchestrate.ScheduleImplement:
+				handler = implement
+			case orchestrate.ScheduleScale:
+				handler = scale
+			case orchestrate.ScheduleProduction:
+				handler = production
+			default:
+				handler = schedule.GetLogicHandler(schedID)
+			}
+			if handler != nil {
+				// Execute using the logic handler. Its process instructions
+				// are added to the agent's prompt, followed by any recovery
+				// guidance
+				err := handler.ExecuteProcess(ctx, procID, func(ctx context.Context, prompt string) error {
+					if guidance != "" {
+						prompt += "\n\n" + guidance
+					}
+					return executeAgentProcess(ctx, ag, modelCoord, orch, sess, schedID, procID, modelName, prompt, resMon, statusDisplay)
+				})
+				recordRetrievedDocs(ctx, orch, knowledge.TakeRetrieved())
+				recordDependencyFindings(orch, production.TakeFindings())
+				recordCoverage(sess, coverage.TakeSteps())
+				return err
+			}
+
+			// Fallback to direct execution if no handler
+			return executeAgentProcess(ctx, ag, modelCoord, orch, sess, schedID, procID, modelName, guidance, resMon, statusDisplay)
+		}
+
+		ld.SetMaxFailures(maxRepeatedFailures(schedID, procID))
+		actionsBefore := len(ag.GetActions())
+		err := runWithRetries(ctx, orch, ag, sess, schedID, procID, maxRetries(schedID), func(ctx context.Context, retryGuidance string) error {
+			return runWithWatchdog(ctx, orch, wd, sess, stdin, schedID, procID, func(ctx context.Context, guidance string) error {
+				return runWithLoopRecovery(ctx, orch, ag, ld, sess, schedID, procID, appendGuidance(retryGuidance, guidance), runProcess)
+			})
+		})
+		if err == nil {
+			if schedID == orchestrate.ScheduleProduction && procID == orchestrate.Process3 {
+				writeProjectReport(ctx, orch, ag, sess)
+			}
+			checkpointSession(orch, ag, sess, schedID, procID, actionsBefore)
+		}
+		return err
 	}
 
 	// Run the orchestrator
@@ -344,17 +910,36 @@ func executeAgentProcess(
 	ag *agent.Agent,
 	modelCoord *model.Coordinator,
 	orch *orchestrate.Orchestrator,
+	sess *orchsession.Session,
 	schedID orchestrate.ScheduleID,
 	procID orchestrate.ProcessID,
 	modelName string,
+	guidance string,
 	resMon *resource.Monitor,
 	statusDisplay *ui.StatusDisplay,
 ) error {
 	processName := orchestrate.ProcessNames[schedID][procID]
-	prompt := orch.GetPrompt()
+	prompt, err := runWorkspace.Load().RenderPrompt(config.PromptData{
+		Prompt:   orch.GetPrompt(),
+		Schedule: orchestrate.ScheduleNames[schedID],
+		Process:  processName,
+	})
+	if err != nil {
+		return err
+	}
+	if notes := orch.NotesPrompt(schedID); notes != "" {
+		prompt += "\n\n" + notes
+	}
+	if guidance != "" {
+		prompt += "\n\n" + guidance
+	}
 
-	// Update agent action display
-	statusDisplay.SetAgentAction(fmt.Sprintf("Executing %s...", processName))
+	// Update agent action display, with how long the process usually takes
+	if typical, ok := runHangs.Load().typical(schedID, procID, modelName); ok {
+		statusDisplay.SetAgentAction(fmt.Sprintf("Executing %s (usually ~%s)...", processName, typical.Round(time.Second)))
+	} else {
+		statusDisplay.SetAgentAction(fmt.Sprintf("Executing %s...", processName))
+	}
 
 	// Set agent context
 	ag.SetContext(schedID, procID)
@@ -364,17 +949,40 @@ func executeAgentProcess(
 		statusDisplay.SetAgentAction(a.ActionOutput())
 		printAgentAction(string(a.Type), a.Path)
 		resMon.RecordDiskWrite(int64(len(a.Content))) // Simple disk tracking
+		if a.Type == agent.ActionHTTPRequest {
+			recordHTTPAction(sess, a)
+		}
+	})
+	ag.SetOutputCallback(func(a agent.Action, line agent.OutputLine) {
+		statusDisplay.SetAgentProgress(commandProgress(a.Command, line))
+	})
+
+	// Count the tokens of the process's own model requests
+	var used int64
+	ag.SetTokenCallback(func(u agent.TokenUsage) {
+		if u.Schedule == schedID && u.Process == procID {
+			atomic.AddInt64(&used, u.Tokens)
+		}
 	})
 
 	// Execute the process using the agent
 	// The agent will select the correct model based on schedule/process
-	err := ag.Execute(ctx, schedID, procID, prompt)
+	start := time.Now()
+	err = ag.Execute(ctx, schedID, procID, prompt)
+	resMon.RecordAgentTime(time.Since(start))
+	recordConversation(sess, schedID, procID, ag.Conversation(), err)
+	ag.SetTokenCallback(nil)
+	if used := atomic.LoadInt64(&used); used > 0 {
+		orch.RecordTokens(used)
+		resMon.RecordTokens(schedID, procID, used)
+	}
 	if err != nil {
 		return err
 	}
 
 	// Mark process completion
 	statusDisplay.SetAgentAction(fmt.Sprintf("%s Completed", processName))
+	statusDisplay.SetQuotaStatus(quotaSummary(modelCoord))
 
 	// Record stats
 	stats := ag.GetStats()
@@ -383,6 +991,88 @@ func executeAgentProcess(
 	return nil
 }
 
+// recordDependencyFindings adds a risk note for each dependency scan finding
+// so that later processes and the final analysis see it. A finding already
+// noted, by an Analyze that was retried, is not noted again.
+func recordDependencyFindings(orch *orchestrate.Orchestrator, findings []scan.DependencyFinding) {
+	noted := make(map[string]bool)
+	for _, n := range orch.GetNotes(orchestrate.NoteFilter{Tags: []string{"dependency"}}) {
+		noted[n.Content] = true
+	}
+	for _, f := range findings {
+		content := fmt.Sprintf("[%s] %s", f.Severity, f.Message)
+		if f.Fix != "" {
+			content += " (fix: " + f.Fix + ")"
+		}
+		if noted[content] {
+			continue
+		}
+		noted[content] = true
+		orch.AddTypedNote(orchestrate.NoteRisk, content, "production", "dependency", f.Type)
+	}
+}
+
+// dependencyFindings returns the findings recordDependencyFindings noted.
+func dependencyFindings(orch *orchestrate.Orchestrator) []scan.DependencyFinding {
+	var findings []scan.DependencyFinding
+	for _, n := range orch.GetNotes(orchestrate.NoteFilter{Tags: []string{"dependency"}}) {
+		var f scan.DependencyFinding
+		content := n.Content
+		if i := strings.LastIndex(content, " (fix: "); i >= 0 && strings.HasSuffix(content, ")") {
+			f.Fix = content[i+len(" (fix: ") : len(content)-1]
+			content = content[:i]
+		}
+		severity, message, ok := strings.Cut(strings.TrimPrefix(content, "["), "] ")
+		if !ok {
+			continue
+		}
+		f.Severity, f.Message = severity, message
+		if len(n.Tags) > 1 {
+			f.Type = n.Tags[1]
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// recordCoverage records the coverage each test generation step gained,
+// for the prompt summary.
+func recordCoverage(sess *orchsession.Session, steps []schedule.CoverageStep) {
+	if sess == nil {
+		return
+	}
+	for _, step := range steps {
+		rec := orchsession.CoverageRecord{
+			Step:       step.Step,
+			Tool:       step.Tool,
+			Before:     step.Before.Percent(),
+			After:      step.After.Percent(),
+			Statements: step.After.Statements,
+		}
+		for _, f := range step.Functions {
+			rec.Targets = append(rec.Targets, orchsession.CoverageTarget{
+				Path:     f.Path,
+				Function: f.Function,
+				Before:   f.Before.Percent(),
+				After:    f.After.Percent(),
+			})
+		}
+		sess.RecordCoverage(rec)
+	}
+}
+
+// recordRetrievedDocs adds a sourced note for each retrieved documentation
+// chunk so that claims built on it can cite the file and commit it came from.
+func recordRetrievedDocs(ctx context.Context, orch *orchestrate.Orchestrator, docs []index.DocChunk) {
+	if len(docs) == 0 {
+		return
+	}
+	commit, _ := tools.GitHead(ctx, "")
+	for _, d := range docs {
+		orch.AddSourcedNote("Retrieved documentation: "+d.Title, "knowledge", orchestrate.Provenance{Path: d.Source, Commit: commit})
+	}
+}
+
 // handleHumanConsultation handles Clarify or Feedback processes
 func handleHumanConsultation(
 	ctx context.Context,
@@ -390,11 +1080,16 @@ func handleHumanConsultation(
 	consultType orchestrate.ConsultationType,
 	schedID orchestrate.ScheduleID,
 	procID orchestrate.ProcessID,
+	stdin *bufio.Reader,
 ) {
 	processName := orchestrate.ProcessNames[schedID][procID]
 
-	// Initialize consultation handler
-	handler := consultation.NewHandler(os.Stdin, os.Stdout, nil)
+	// Initialize consultation handler: optional consultations are Clarify's
+	kind := consultation.ConsultationFeedback
+	if consultType == orchestrate.ConsultationOptional {
+		kind = consultation.ConsultationClarify
+	}
+	handler := newConsultationHandler(kind, nil, nil, nil, stdin)
 
 	req := consultation.Request{
 		Type:     consultation.ConsultationType(consultType),
@@ -419,7 +1114,7 @@ func handleHumanConsultation(
 		orch.AddNote(resp.Content, "user")
 		fmt.Printf("%s %s\n", ui.FormatSuccess("✓"), "Response recorded")
 	} else {
-		fmt.Printf("%s %s\n", ui.FormatWarning("⏱"), "Timeout - AI substitute used: "+resp.Content)
+		fmt.Printf("%s %s\n", ui.FormatWarning("⏱"), "Timeout - AI substitute ("+resp.Persona+") used: "+resp.Content)
 		orch.AddNote(resp.Content, "ai-substitute")
 	}
 }
@@ -507,12 +1202,11 @@ func printOrchError(err error) {
 	fmt.Printf("\n%s %s\n", ui.FormatError("Error"), ui.FormatBullet()+err.Error())
 }
 
-func promptForInput() string {
+func promptForInput(reader *bufio.Reader) string {
 	fmt.Println()
 	fmt.Printf("%s %s\n", ui.FormatLabel("→"), ui.FormatValue("Enter your prompt:"))
 	fmt.Print(ui.TokyoBlue + "  > " + ui.Reset)
 
-	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
 		return ""
@@ -520,7 +1214,7 @@ func promptForInput() string {
 	return strings.TrimSpace(input)
 }
 
-func printPromptSummary(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon *resource.Monitor) {
+func printPromptSummary(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon *resource.Monitor, sess *orchsession.Session) {
 	stats := orch.GetStats()
 	flowCode := orch.GetFlowCode()
 	memStats := resMon.GetStats()
@@ -537,7 +1231,7 @@ func printPromptSummary(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon
 
 	// Schedule stats
 	fmt.Printf("%s %s\n", ui.FormatLabel("Schedules"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d Total", stats.TotalSchedulings)))
-	for schedID := orchestrate.ScheduleKnowledge; schedID <= orchestrate.ScheduleProduction; schedID++ {
+	for _, schedID := range orchestrate.AllSchedules() {
 		count := stats.SchedulingsByID[schedID]
 		if count > 0 {
 			fmt.Printf("  %s %s\n", ui.FormatValueMuted("•"), 
@@ -554,6 +1248,13 @@ func printPromptSummary(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon
 	fmt.Printf("%s\n", ui.FormatLabel("Resources"))
 	fmt.Printf("  %s %s\n", ui.FormatValueMuted("Peak Memory:"), 
 		ui.FormatValue(formatBytes(memStats.PeakMemory)))
+	if vram := resMon.GetSummary().VRAM; vram.Source != "" {
+		fmt.Printf("  %s %s\n", ui.FormatValueMuted("Peak VRAM:"),
+			ui.FormatValue(fmt.Sprintf("%.1f GB of %.1f GB (%s)", vram.Peak, vram.Total, vram.Source)))
+	}
+	for _, d := range resMon.Degradations() {
+		fmt.Printf("  %s %s\n", ui.FormatValueMuted("Degraded:"), ui.FormatValue(d.String()))
+	}
 	fmt.Printf("  %s %s\n", ui.FormatValueMuted("Duration:"), 
 		ui.FormatValue(stats.EndTime.Sub(stats.StartTime).Round(time.Millisecond).String()))
 	fmt.Println()
@@ -579,6 +1280,53 @@ func printPromptSummary(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon
 	}
 	fmt.Println()
 
+	// Per-file changes, with the states to inspect them in
+	if files := ag.GetRecorder().FileChanges(); len(files) > 0 {
+		fmt.Printf("%s %s\n", ui.FormatLabel("Files"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d Changed", len(files))))
+		for _, f := range files {
+			lines := fmt.Sprintf("+%d -%d, %d → %d lines", f.Added, f.Removed, f.LinesBefore, f.LinesAfter)
+			if f.Size < 0 {
+				lines += ", deleted"
+			}
+			fmt.Printf("  %s %s %s\n", ui.FormatValueMuted("•"), ui.FormatValue(f.Path), ui.FormatValueMuted(lines))
+			if len(f.StateIDs) > 0 {
+				fmt.Printf("    %s %s\n", ui.FormatValueMuted("States:"), ui.FormatValueMuted(strings.Join(f.StateIDs, ", ")))
+			}
+		}
+		fmt.Println()
+	}
+
+	// Typed notes grouped by type
+	groups := orchestrate.GroupNotesByType(orch.GetNotes())
+	for _, t := range orchestrate.NoteTypes {
+		notes := groups[t]
+		if len(notes) == 0 {
+			continue
+		}
+		fmt.Printf("%s %s\n", ui.FormatLabel("Notes"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%s (%d)", t, len(notes))))
+		for _, n := range notes {
+			fmt.Printf("  %s %s\n", ui.FormatValueMuted("•"), ui.FormatValue(n.Content))
+		}
+		fmt.Println()
+	}
+
+	// Error summary
+	if records := sess.GetErrors(); len(records) > 0 {
+		fmt.Printf("%s %s\n", ui.FormatLabel("Errors"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d Total", len(records))))
+		for _, rec := range records {
+			fmt.Printf("  %s %s %s\n", ui.FormatValueMuted("•"),
+				ui.FormatValue(fmt.Sprintf("%s [%s] %s", rec.Code, rec.Impact, rec.Component)),
+				ui.FormatValueMuted(fmt.Sprintf("→ %s", rec.Resolution)))
+		}
+		fmt.Println()
+	}
+
+	// Expert judges' TLDR
+	if _, tldr := sess.GetAnalysis(); tldr != "" {
+		fmt.Print(tldr)
+		fmt.Println()
+	}
+
 	fmt.Println(ui.TokyoBlue + "─────────────────────────────────────────────────────────────" + ui.Reset)
 	fmt.Println()
 }
@@ -657,3 +1405,15 @@ func restoreOrchestrateState(stateID string) error {
 	fmt.Println()
 	return nil
 }
+
+// workspaceLockError reports a failure to lock the workspace. Contention with
+// another run is reported as a concurrent navigation error.
+func workspaceLockError(err error) error {
+	var locked *orchsession.WorkspaceLockedError
+	if !errors.As(err, &locked) {
+		return fmt.Errorf("workspace lock: %w", err)
+	}
+	oe := errs.New(errs.ErrConcurrentNavigation, "Session", locked.Error(), errs.FrozenState{})
+	oe.Cause = err
+	return oe
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// trustPolicyFile asks whether to apply the rules of a policy file that
+// loosen the default policy; replaced in tests.
+var trustPolicyFile = promptPolicyTrust
+
+// loadWorkspacePolicy loads the policy of workspace with extra rules. The
+// rules of .obot/policy.yaml that loosen the defaults come with whoever
+// wrote the file, so they apply only once the user trusts it as it is now.
+func loadWorkspacePolicy(workspace string, extra []agent.PolicyRule, in *bufio.Reader) (*agent.Policy, error) {
+	loosening, hash, err := agent.PolicyLoosening(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(loosening) == 0 {
+		return agent.LoadPolicyWithRules(workspace, extra)
+	}
+	path := agent.PolicyPath(workspace)
+	if config.IsTrusted(config.TrustedWorkspacesPath(), path, hash) || trustPolicyFile(in, path, loosening, hash) {
+		return agent.LoadTrustedPolicy(workspace, extra)
+	}
+	printWarning(fmt.Sprintf("Ignoring the rules of the untrusted %s that loosen the default policy (see obot config trust)", path))
+	return agent.LoadPolicyWithRules(workspace, extra)
+}
+
+// promptPolicyTrust lists the rules of the policy file at path that loosen
+// the default policy and asks whether to trust the file, recording the
+// answer yes. Without a terminal to ask on, it is not trusted.
+func promptPolicyTrust(in *bufio.Reader, path string, rules []agent.PolicyRule, hash string) bool {
+	if !term.IsTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Println(ui.FormatWarning(path + " loosens the default policy:"))
+	for _, r := range rules {
+		fmt.Println("  " + ui.FormatValue(fmt.Sprintf("%s: %s", r.Class, r.Decision)))
+	}
+	fmt.Print("Trust this file? [y/N] ")
+	if !confirmed(in) {
+		return false
+	}
+	if err := config.RecordTrust(config.TrustedWorkspacesPath(), path, hash); err != nil {
+		printWarning("Failed to record the trust: " + err.Error())
+	}
+	return true
+}
+
+// newPolicyConfirmer returns a callback that asks the user on out whether an
+// action requiring confirmation under the workspace policy may run.
+func newPolicyConfirmer(in *bufio.Reader, out io.Writer) func(agent.PolicyCheck) bool {
+	var mu sync.Mutex
+	return func(check agent.PolicyCheck) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reason := check.Reason
+		if reason == "" {
+			reason = "requires confirmation"
+		}
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "%s %s\n", ui.FormatWarning("Policy"), ui.FormatBullet()+ui.FormatValue(check.Summary()))
+		fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Reason:"), ui.FormatValue(reason))
+		fmt.Fprint(out, "Allow this action? [y/N] ")
+
+		line, _ := in.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		}
+		return false
+	}
+}
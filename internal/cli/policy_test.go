@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+)
+
+func TestLoadWorkspacePolicy_Trust(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, ".obot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "rules:\n  - class: destructive_command\n    decision: allow\n"
+	if err := os.WriteFile(agent.PolicyPath(workspace), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	savedTrust := trustPolicyFile
+	t.Cleanup(func() { trustPolicyFile = savedTrust })
+
+	rm := &agent.Action{Type: agent.ActionRunCommand, Command: "rm -rf ./build"}
+	in := bufio.NewReader(strings.NewReader(""))
+	for _, tt := range []struct {
+		trusted bool
+		want    agent.PolicyDecision
+	}{
+		{false, agent.PolicyConfirm},
+		{true, agent.PolicyAllow},
+	} {
+		asked := 0
+		trustPolicyFile = func(*bufio.Reader, string, []agent.PolicyRule, string) bool { asked++; return tt.trusted }
+		policy, err := loadWorkspacePolicy(workspace, nil, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := policy.Evaluate(rm).Decision; got != tt.want || asked != 1 {
+			t.Errorf("trusted %v: Evaluate() = %s after %d prompts, want %s after one", tt.trusted, got, asked, tt.want)
+		}
+	}
+}
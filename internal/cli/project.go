@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// orchProject is the --project flag of the orchestrate command.
+var orchProject string
+
+// enterProject moves into the project of the monorepo in the subdirectory
+// dir, so that analysis, hashing, the index, and verification cover only
+// it, and returns the scope confining the agent's writes to it. It returns
+// nil, staying put, when dir is empty.
+func enterProject(dir string) (*agent.Scope, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	scope, err := agent.NewScope(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(scope.Project); err != nil {
+		return nil, err
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Project"), ui.FormatBullet()+ui.FormatValue(scope.Name())+ui.FormatValueMuted(" in "+scope.Root))
+	return scope, nil
+}
+
+// projectNote tells the agent which project of the monorepo it works on
+// and where the others it may read are, relative to it.
+func projectNote(scope *agent.Scope, toolchains []agent.Toolchain) string {
+	var others []string
+	for _, p := range agent.FindProjects(scope.Root, toolchains) {
+		if p.Path == scope.Name() {
+			continue
+		}
+		rel, err := filepath.Rel(scope.Project, filepath.Join(scope.Root, filepath.FromSlash(p.Path)))
+		if err != nil {
+			continue
+		}
+		others = append(others, fmt.Sprintf("%s (%s)", filepath.ToSlash(rel), p.Toolchain))
+	}
+	note := fmt.Sprintf("Working on the %s project of a monorepo: create, edit, and delete files and run tests and commands only within it", scope.Name())
+	if len(others) == 0 {
+		return note + "; the rest of the repository is read-only"
+	}
+	return note + "; the other projects are read-only: " + strings.Join(others, ", ")
+}
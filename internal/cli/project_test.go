@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+)
+
+func TestProjectNote(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"services/api/go.mod", "libs/auth/go.mod"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scope, err := agent.NewScope(root, "services/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Working on the services/api project of a monorepo: create, edit, and delete files and run tests and commands only within it; the other projects are read-only: ../../libs/auth (go)"
+	if got := projectNote(scope, agent.DefaultToolchains()); got != want {
+		t.Errorf("projectNote() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/projectreport"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// projectReportData collects the handoff report of a finished run: the
+// workspace's layout, the decisions and other typed notes, the lint and
+// test commands that passed, the judges' summary and open issues, and the
+// files changed.
+func projectReportData(workspace string, orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session) *projectreport.Data {
+	data := &projectreport.Data{
+		Prompt:    orch.GetPrompt(),
+		SessionID: sess.GetID(),
+		Date:      time.Now(),
+		FlowCode:  orch.GetFlowCode(),
+	}
+	if err := data.Survey(workspace, ag.Toolchains()); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Project layout not surveyed: "+err.Error()))
+	}
+	for _, n := range orch.GetNotes() {
+		switch n.Type {
+		case orchestrate.NoteDecision:
+			data.Decisions = append(data.Decisions, n.Content)
+		case orchestrate.NoteConstraint:
+			data.Constraints = append(data.Constraints, n.Content)
+		case orchestrate.NoteTodo:
+			data.Todos = append(data.Todos, n.Content)
+		case orchestrate.NoteRisk:
+			data.Risks = append(data.Risks, n.Content)
+		}
+	}
+	data.AddCommands(ag.GetActions())
+	if analysis := sessionAnalysis(sess); analysis != nil && analysis.Synthesis != nil {
+		data.Summary = analysis.Synthesis.ImplementationSummary
+		for _, issue := range analysis.Synthesis.Issues {
+			data.Issues = append(data.Issues, projectreport.Issue{Description: issue.Description, Resolution: issue.Resolution})
+		}
+	}
+	for _, fc := range ag.GetRecorder().FileChanges() {
+		path := fc.Path
+		if rel, err := filepath.Rel(workspace, path); err == nil && filepath.IsLocal(rel) {
+			path = filepath.ToSlash(rel)
+		}
+		data.Changed = append(data.Changed, path)
+	}
+	return data
+}
+
+// writeProjectReport writes PROJECT_REPORT.md into the workspace the agent
+// worked in, the isolated copy under --isolated, unless --no-project-report
+// is given. It is written as an agent action, so that it is recorded with
+// the run's changes, at the end of each Production cycle and again once the
+// judges have reviewed the session. A report obot did not write is left
+// alone.
+func writeProjectReport(ctx context.Context, orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session) {
+	if orchNoProjectReport {
+		return
+	}
+	workspace, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write project report: "+err.Error()))
+		return
+	}
+	if err := projectreport.CheckReplace(filepath.Join(workspace, projectreport.FileName)); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Project report not written: "+err.Error()))
+		return
+	}
+	content, err := projectreport.Generate(workspace, projectReportData(workspace, orch, ag, sess))
+	if err == nil {
+		err = ag.WriteFile(ctx, projectreport.FileName, string(content))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write project report: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Report"), ui.FormatBullet()+ui.FormatValue(filepath.Join(workspace, projectreport.FileName)))
+}
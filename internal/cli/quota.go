@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// applyModelQuotas configures the per-role quotas from the unified config.
+func applyModelQuotas(coord *model.Coordinator, models config.ModelsConfig) error {
+	roles := map[orchestrate.ModelType]config.RoleQuotaConfig{
+		orchestrate.ModelOrchestrator: models.Orchestrator.Quota,
+		orchestrate.ModelCoder:        models.Coder.Quota,
+		orchestrate.ModelResearcher:   models.Researcher.Quota,
+		orchestrate.ModelVision:       models.Vision.Quota,
+	}
+	for role, q := range roles {
+		fallback := orchestrate.ModelType(q.Fallback)
+		if fallback != "" {
+			if _, ok := roles[fallback]; !ok || fallback == role {
+				return fmt.Errorf("models.%s.quota.fallback: invalid role %q", role, q.Fallback)
+			}
+		}
+		coord.SetQuota(role, model.Quota{
+			RequestsPerMinute: q.RequestsPerMinute,
+			DailyTokens:       q.DailyTokens,
+			Fallback:          fallback,
+		})
+	}
+	return nil
+}
+
+// applyModelTemperatures sets the temperatures the unified config gives
+// the roles.
+func applyModelTemperatures(coord *model.Coordinator, models config.ModelsConfig) {
+	for role, rc := range map[orchestrate.ModelType]config.ModelRoleConfig{
+		orchestrate.ModelOrchestrator: models.Orchestrator,
+		orchestrate.ModelCoder:        models.Coder,
+		orchestrate.ModelResearcher:   models.Researcher,
+		orchestrate.ModelVision:       models.Vision,
+	} {
+		if rc.Temperature != nil {
+			coord.SetTemperature(role, *rc.Temperature)
+		}
+	}
+}
+
+// quotaSummary returns the quota usage shown in the status display.
+func quotaSummary(coord *model.Coordinator) string {
+	statuses := coord.QuotaStatuses()
+	parts := make([]string, len(statuses))
+	for i, s := range statuses {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, " · ")
+}
+
+// newQuotaCallback reports paused and downgraded roles to the orchestrator
+// and the status display.
+func newQuotaCallback(orch *orchestrate.Orchestrator, display *ui.StatusDisplay) func(model.QuotaEvent) {
+	return func(e model.QuotaEvent) {
+		orch.AddTypedNote(orchestrate.NoteConstraint, "Model quota: "+e.String(), "system", "quota", string(e.Role))
+		display.SetQuotaStatus(e.String())
+		if e.Kind == model.QuotaPaused {
+			display.SetAgentAction(fmt.Sprintf("Waiting for %s quota until %s", e.Role, e.Until.Format("15:04:05")))
+		}
+	}
+}
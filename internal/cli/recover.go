@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// offerCrashRecovery looks for a session in workspace whose run did not
+// terminate cleanly, loads it from store, and asks whether to resume it. It
+// returns the session to resume, or nil. A declined session is marked crashed
+// so it is not offered again.
+func offerCrashRecovery(baseDir string, store orchsession.Storage, workspace string, in *bufio.Reader, out io.Writer) *orchsession.Session {
+	candidates, err := orchsession.FindUnterminated(baseDir, store, workspace)
+	if err != nil || len(candidates) == 0 {
+		return nil
+	}
+	sess := candidates[0]
+
+	where := "the beginning"
+	if st := sess.LastState(); st != nil {
+		where = fmt.Sprintf("S%dP%d", st.Schedule, st.Process)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "%s %s\n", ui.FormatWarning("Recovery"), ui.FormatBullet()+ui.FormatValue("Session "+sess.GetID()+" did not terminate cleanly"))
+	fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Prompt:"), ui.FormatValue(sess.GetPrompt()))
+	if flow := sess.GetFlowCode(); flow != "" {
+		fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Flow:"), ui.FormatFlowCode(flow))
+	}
+	fmt.Fprintf(out, "Resume session %s from %s? [y/N] ", sess.GetID(), where)
+
+	line, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return sess
+	}
+
+	sess.SetStatus(orchsession.StatusCrashed)
+	_ = sess.ReleaseRunLock()
+	_ = sess.Save()
+	return nil
+}
+
+// restoreSession rebuilds orchestrator and agent state from a persisted
+// session so the run continues after its last recorded state.
+func restoreSession(orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session) error {
+	saved := sess.GetOrchestratorNotes()
+	notes := make([]orchestrate.Note, len(saved))
+	for i, n := range saved {
+		notes[i] = orchestrate.Note{ID: n.ID, Timestamp: n.Timestamp, Content: n.Content, Source: n.Source,
+			Type: n.Type, Tags: n.Tags, Provenance: n.Provenance}
+	}
+	if err := orch.Restore(sess.GetFlowCode(), notes); err != nil {
+		return err
+	}
+	sess.MarkOrchestratorNotesSynced(len(notes))
+	orch.SetPrompt(sess.GetPrompt())
+
+	if st := sess.LastState(); st != nil {
+		ag.SetContext(st.Schedule, st.Process)
+		if len(st.Actions) > 0 {
+			orch.AddNote(fmt.Sprintf("Actions completed in S%dP%d before the crash: %s", st.Schedule, st.Process, strings.Join(st.Actions, "; ")), "system")
+		}
+	}
+	orch.AddNote("Resumed session "+sess.GetID()+" after an unclean shutdown", "system")
+	sess.SetStatus(orchsession.StatusRunning)
+	return nil
+}
+
+// checkpointSession records the completed process as a session state, links
+// its actions to the state, and saves the session, so a crash can resume
+// from it. actionsBefore is the agent's action count when the process
+// started.
+func checkpointSession(orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, actionsBefore int) {
+	var actions []string
+	if all := ag.GetActions(); len(all) > actionsBefore {
+		for _, a := range all[actionsBefore:] {
+			actions = append(actions, a.ActionOutput())
+		}
+	}
+	stateID := sess.AddState(schedID, procID, actions)
+	ag.GetRecorder().TagState(stateID)
+	_ = persistSession(orch, sess)
+}
+
+// sessionPlugin saves the session whenever a process ends, including one
+// skipped after an error, which has no checkpoint.
+type sessionPlugin struct {
+	*orchestrate.BaseOrchestratorPlugin
+	orch *orchestrate.Orchestrator
+	sess *orchsession.Session
+}
+
+// newSessionPlugin returns a plugin saving sess as orch runs. Register it
+// after the other plugins so that the notes they add when a process ends
+// are saved too.
+func newSessionPlugin(orch *orchestrate.Orchestrator, sess *orchsession.Session) *sessionPlugin {
+	return &sessionPlugin{BaseOrchestratorPlugin: orchestrate.NewBaseOrchestratorPlugin("session"), orch: orch, sess: sess}
+}
+
+// OnProcessEnd saves the session with the notes added since the last save.
+func (p *sessionPlugin) OnProcessEnd(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	return persistSession(p.orch, p.sess)
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+// crashedSession saves a session whose run lock belongs to a dead process.
+func crashedSession(t *testing.T, baseDir, workspace string) *orchsession.Session {
+	t.Helper()
+	sess := orchsession.NewSessionWithBaseDir(baseDir)
+	sess.SetPrompt("add tests")
+	sess.AddState(orchestrate.ScheduleKnowledge, orchestrate.Process1, []string{"Read main.go"})
+	sess.SetFlowCode("S1P1")
+	sess.AddOrchestratorNoteFrom(orchestrate.Note{Content: "Retrieved documentation: Setup", Source: "knowledge",
+		Type: orchestrate.NoteDiscovery, Tags: []string{"docs"}, Provenance: &orchestrate.Provenance{Path: "docs/setup.md", Commit: "abc123"}})
+	if err := sess.Save(); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	lock := `{"pid": -1, "host": "` + host + `", "workspace": "` + workspace + `", "started_at": "` + time.Now().Format(time.RFC3339) + `"}`
+	if err := os.WriteFile(filepath.Join(baseDir, sess.GetID(), "run.lock"), []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+func TestOfferCrashRecovery(t *testing.T) {
+	baseDir := t.TempDir()
+	sess := crashedSession(t, baseDir, "/work")
+
+	if got := offerCrashRecovery(baseDir, orchsession.NewFSStorage(baseDir), "/other", bufio.NewReader(strings.NewReader("y\n")), io.Discard); got != nil {
+		t.Errorf("offered a session from another workspace")
+	}
+
+	got := offerCrashRecovery(baseDir, orchsession.NewFSStorage(baseDir), "/work", bufio.NewReader(strings.NewReader("y\n")), io.Discard)
+	if got == nil || got.GetID() != sess.GetID() {
+		t.Fatalf("offerCrashRecovery() = %v, want session %s", got, sess.GetID())
+	}
+
+	if got := offerCrashRecovery(baseDir, orchsession.NewFSStorage(baseDir), "/work", bufio.NewReader(strings.NewReader("n\n")), io.Discard); got != nil {
+		t.Fatalf("declined recovery returned a session")
+	}
+	loaded, err := orchsession.Load(baseDir, sess.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.GetStatus() != orchsession.StatusCrashed {
+		t.Errorf("declined session status = %q, want %q", loaded.GetStatus(), orchsession.StatusCrashed)
+	}
+	if lock, _ := orchsession.ReadRunLock(baseDir, sess.GetID()); lock != nil {
+		t.Error("declined session is still locked")
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	baseDir := t.TempDir()
+	crashedSession(t, baseDir, "/work")
+	found, err := orchsession.FindUnterminated(baseDir, orchsession.NewFSStorage(baseDir), "/work")
+	if err != nil || len(found) != 1 {
+		t.Fatalf("FindUnterminated() = %d, %v", len(found), err)
+	}
+
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	if err := restoreSession(orch, ag, found[0]); err != nil {
+		t.Fatalf("restoreSession() error = %v", err)
+	}
+	if orch.GetFlowCode() != "S1P1" || orch.GetPrompt() != "add tests" {
+		t.Errorf("restored flow = %q, prompt = %q", orch.GetFlowCode(), orch.GetPrompt())
+	}
+	var sawActions, sawProvenance bool
+	for _, n := range orch.GetNotes() {
+		if strings.Contains(n.Content, "Read main.go") {
+			sawActions = true
+		}
+		if n.Provenance != nil && n.Provenance.String() == "docs/setup.md@abc123" && n.Type == orchestrate.NoteDiscovery && n.HasTag("docs") {
+			sawProvenance = true
+		}
+	}
+	if !sawActions {
+		t.Error("restored notes do not mention the last state's actions")
+	}
+	if !sawProvenance {
+		t.Error("restored notes lost their type, tags, or provenance")
+	}
+}
+
+func TestPersistSession_SavesEachNoteOnce(t *testing.T) {
+	baseDir := t.TempDir()
+	crashedSession(t, baseDir, "/work")
+	found, err := orchsession.FindUnterminated(baseDir, orchsession.NewFSStorage(baseDir), "/work")
+	if err != nil || len(found) != 1 {
+		t.Fatalf("FindUnterminated() = %d, %v", len(found), err)
+	}
+	sess := found[0]
+	orch := orchestrate.NewOrchestrator()
+	if err := restoreSession(orch, agent.NewAgent(model.NewCoordinator(nil)), sess); err != nil {
+		t.Fatal(err)
+	}
+	if err := persistSession(orch, sess); err != nil {
+		t.Fatal(err)
+	}
+	restored := len(orch.GetNotes())
+
+	// A note the orchestrator reviews before the next save, as after a
+	// skipped process, is still saved
+	orch.AddNote("Skipped Plan after error: boom", "system")
+	orch.MarkNotesReviewed()
+	if err := newSessionPlugin(orch, sess).OnProcessEnd(context.Background(), orchestrate.ScheduleKnowledge, orchestrate.Process2); err != nil {
+		t.Fatal(err)
+	}
+	if err := persistSession(orch, sess); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := orchsession.Load(baseDir, sess.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := loaded.GetOrchestratorNotes()
+	if len(notes) != restored+1 || !strings.HasPrefix(notes[len(notes)-1].Content, "Skipped Plan") {
+		t.Errorf("saved %d notes, want the %d restored and the skip note once", len(notes), restored)
+	}
+}
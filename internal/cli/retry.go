@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/croberts/obot/internal/agent"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// defaultMaxRetries is used when no unified config is loaded.
+const defaultMaxRetries = 2
+
+// maxRetriesCap bounds the automatic retries a config may ask for.
+const maxRetriesCap = 5
+
+// errRecoveryExhausted is returned when a process used up its recovery
+// attempts. It is not retried: the orchestrator suspends the run.
+var errRecoveryExhausted = errors.New("recovery attempts exhausted")
+
+// maxRetries returns the number of automatic retries for a failed process in
+// a schedule from the unified config.
+func maxRetries(schedID orchestrate.ScheduleID) int {
+	if cfg == nil || cfg.Unified == nil {
+		return defaultMaxRetries
+	}
+	return cfg.Unified.GetMaxRetries(orchestrate.ScheduleNames[schedID])
+}
+
+// runWithRetries executes a process and, when it fails, re-executes it up to
+// retries times with guidance describing the failure. The last error is
+// returned so that the orchestrator can suspend. Cancellation, aborts, and
+// processes that exhausted their recovery are never retried, and retries is
+// held to maxRetriesCap.
+func runWithRetries(
+	ctx context.Context,
+	orch *orchestrate.Orchestrator,
+	ag *agent.Agent,
+	sess *orchsession.Session,
+	schedID orchestrate.ScheduleID,
+	procID orchestrate.ProcessID,
+	retries int,
+	run func(ctx context.Context, guidance string) error,
+) error {
+	processName := orchestrate.ProcessNames[schedID][procID]
+	retries = min(retries, maxRetriesCap)
+	guidance := ""
+	for attempt := 1; ; attempt++ {
+		actionsBefore := len(ag.GetActions())
+		err := run(ctx, guidance)
+		if err == nil || ctx.Err() != nil || errors.Is(err, orchestrate.ErrAborted) || errors.Is(err, errRecoveryExhausted) || attempt > retries {
+			return err
+		}
+
+		fmt.Printf("%s %s\n", ui.FormatWarning("Retry"),
+			ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%s failed, retrying (%d/%d): %v", processName, attempt, retries, err)))
+		orch.AddNote(fmt.Sprintf("Retrying %s automatically (%d/%d) after error: %v", processName, attempt, retries, err), "system")
+		recordSessionError(sess, errs.NewProcessError(err, "Agent", frozenState(orch, ag, schedID, procID)), "auto-retry")
+
+		guidance = retryGuidance(processName, attempt, err, lastActionSince(ag, actionsBefore))
+	}
+}
+
+// lastActionSince describes the last action the agent took after the first
+// n actions, or returns "" when it took none.
+func lastActionSince(ag *agent.Agent, n int) string {
+	actions := ag.GetActions()
+	if len(actions) <= n {
+		return ""
+	}
+	return actions[len(actions)-1].ActionOutput()
+}
+
+// retryGuidance annotates the prompt of a retried process with what failed
+// and why.
+func retryGuidance(processName string, attempt int, err error, lastAction string) string {
+	guidance := fmt.Sprintf("RETRY: attempt %d of the %s process failed.\nError: %v\n", attempt, processName, err)
+	if lastAction != "" {
+		guidance += "Last action before the failure: " + lastAction + "\n"
+	}
+	return guidance + "Address the cause of this error instead of repeating the step that produced it."
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestRunWithRetries(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+
+	var guidances []string
+	err := runWithRetries(context.Background(), orch, ag, sess, orchestrate.ScheduleImplement, orchestrate.Process2, 2,
+		func(ctx context.Context, guidance string) error {
+			guidances = append(guidances, guidance)
+			if len(guidances) < 3 {
+				return errors.New("go test failed")
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("runWithRetries() = %v", err)
+	}
+	if len(guidances) != 3 || guidances[0] != "" {
+		t.Fatalf("guidances = %q", guidances)
+	}
+	if !strings.Contains(guidances[2], "attempt 2 of the Verify process failed") || !strings.Contains(guidances[2], "go test failed") {
+		t.Errorf("retry guidance = %q", guidances[2])
+	}
+	if records := sess.GetErrors(); len(records) != 2 || records[0].Resolution != "auto-retry" {
+		t.Errorf("recorded errors = %+v", records)
+	}
+
+	calls := 0
+	err = runWithRetries(context.Background(), orch, ag, sess, orchestrate.ScheduleImplement, orchestrate.Process2, 1,
+		func(ctx context.Context, guidance string) error {
+			calls++
+			return errors.New("still failing")
+		})
+	if err == nil || calls != 2 {
+		t.Errorf("runWithRetries() = %v after %d calls, want error after 2", err, calls)
+	}
+
+	calls = 0
+	_ = runWithRetries(context.Background(), orch, ag, sess, orchestrate.ScheduleImplement, orchestrate.Process2, 3,
+		func(ctx context.Context, guidance string) error {
+			calls++
+			return orchestrate.ErrAborted
+		})
+	if calls != 1 {
+		t.Errorf("aborted process ran %d times, want 1", calls)
+	}
+}
+
+func TestRunWithRetries_Capped(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+
+	calls := 0
+	_ = runWithRetries(context.Background(), orch, ag, sess, orchestrate.ScheduleImplement, orchestrate.Process2, 1000,
+		func(ctx context.Context, guidance string) error {
+			calls++
+			return errors.New("still failing")
+		})
+	if calls != maxRetriesCap+1 {
+		t.Errorf("process ran %d times, want %d", calls, maxRetriesCap+1)
+	}
+
+	calls = 0
+	_ = runWithRetries(context.Background(), orch, ag, sess, orchestrate.ScheduleImplement, orchestrate.Process2, 3,
+		func(ctx context.Context, guidance string) error {
+			calls++
+			return errRecoveryExhausted
+		})
+	if calls != 1 {
+		t.Errorf("exhausted process ran %d times, want 1", calls)
+	}
+}
+
+func TestRunWithWatchdog_EscalatesAfterMaxAttempts(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+	wd := agent.NewWatchdog(agent.DefaultBudget())
+
+	defer func(ask func(context.Context, *orchestrate.Orchestrator, *bufio.Reader, string, int) orchestrate.RecoveryDecision) {
+		askRecovery = ask
+	}(askRecovery)
+	asked := 0
+	askRecovery = func(context.Context, *orchestrate.Orchestrator, *bufio.Reader, string, int) orchestrate.RecoveryDecision {
+		asked++
+		return orchestrate.RecoveryRetry
+	}
+
+	calls := 0
+	err := runWithWatchdog(context.Background(), orch, wd, sess, nil, orchestrate.ScheduleImplement, orchestrate.Process2,
+		func(ctx context.Context, guidance string) error {
+			calls++
+			wd.OnBeforeExecute(ctx, "Implement", "Verify")
+			wd.Trip("too many actions")
+			return nil
+		})
+	if !errors.Is(err, errRecoveryExhausted) {
+		t.Fatalf("runWithWatchdog() = %v, want errRecoveryExhausted", err)
+	}
+	if calls != maxWatchdogAttempts || asked != maxWatchdogAttempts-1 {
+		t.Errorf("ran %d times and asked %d times, want %d and %d", calls, asked, maxWatchdogAttempts, maxWatchdogAttempts-1)
+	}
+}
+
+func TestRunWithLoopRecovery_ExhaustedAcrossRuns(t *testing.T) {
+	ctx := context.Background()
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+	ld := agent.NewLoopDetector(2, 0.9)
+
+	calls := 0
+	run := func(ctx context.Context, guidance string) error {
+		calls++
+		_ = ld.OnBeforeExecute(ctx, "Implement", "1")
+		for i := 0; i < 2; i++ {
+			_ = ld.OnAfterAction(ctx, &agent.Action{Type: agent.ActionRunCommand, Command: "go test ./...", ExitCode: 1})
+		}
+		return nil
+	}
+	// Escalate, switch the model, then revisit Plan
+	err := runWithLoopRecovery(ctx, orch, ag, ld, sess, orchestrate.ScheduleImplement, orchestrate.Process1, "", run)
+	if errors.Is(err, errRecoveryExhausted) || calls != 3 {
+		t.Fatalf("first run: %d calls, error %v; want 3 calls and a Plan revisit", calls, err)
+	}
+	// Implement runs again after the revisit; the loop is not recovered anew
+	calls = 0
+	err = runWithLoopRecovery(ctx, orch, ag, ld, sess, orchestrate.ScheduleImplement, orchestrate.Process1, "", run)
+	if !errors.Is(err, errRecoveryExhausted) || calls != 1 {
+		t.Errorf("second run: %d calls, error %v; want 1 call and errRecoveryExhausted", calls, err)
+	}
+}
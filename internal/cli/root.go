@@ -2,14 +2,19 @@ package cli
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/croberts/obot/internal/bench"
 	"github.com/croberts/obot/internal/config"
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/tier"
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 var (
@@ -29,6 +34,11 @@ var (
 	showDiff        bool
 	diffContext     int
 	noSummary       bool
+	noCache         bool
+	startOllama     bool
+	noColors        bool
+	themeFlag       string
+	logLevel        string
 	memGraphEnabled bool
 	fromScan        bool
 	scopeFlag       string
@@ -77,6 +87,13 @@ Examples:
 		if cmd.Flags().Changed("version") {
 			return nil
 		}
+		setupColors(!noColors && term.ColorsWanted(os.Stdout))
+		if err := setupTheme(themeFlag, nil); err != nil {
+			return err
+		}
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
 		if shouldSkipSetup(cmd) {
 			return nil
 		}
@@ -88,10 +105,42 @@ Examples:
 			// Config doesn't exist yet, use defaults
 			cfg = config.Default()
 		}
+		if cfg.Unified != nil {
+			cli := cfg.Unified.Platforms.CLI
+			if !cli.ColorOutput {
+				setupColors(false)
+			}
+			if themeFlag == "" {
+				if err := setupTheme(cli.Theme, cli.ThemeColors); err != nil {
+					printWarning("Config theme ignored: " + err.Error())
+				}
+			}
+		}
+
+		// Cache deterministic responses across runs unless disabled
+		if noCache {
+			ollama.SetDefaultCache(nil)
+		} else {
+			ollama.SetDefaultCache(ollama.NewResponseCache(ollama.DefaultCacheDir(), ollama.DefaultCacheEntries, ollama.DefaultCacheBytes))
+		}
 
 		// Initialize tier manager
 		tierManager = tier.NewManager()
 
+		// Calibrate the tier with this machine's benchmarks, see obot bench
+		if results, err := bench.Load(bench.DefaultPath()); err != nil {
+			printWarning("Benchmarks ignored: " + err.Error())
+		} else if results != nil && results.RAMGB == tierManager.SystemInfo.RAMGB {
+			benchResults = results
+			detected := tierManager.SelectedTier
+			calibrated := results.CalibrateTier(detected, results.RAMGB)
+			tierManager.SetTier(calibrated)
+			// The tier picks the model unless --model does
+			if calibrated != detected && modelFlag == "" {
+				fmt.Fprintf(os.Stderr, "%s Tier: %s (detected %s; calibrated by obot bench)\n", cyan("→"), calibrated.DisplayName(), detected.DisplayName())
+			}
+		}
+
 		// Apply CLI overrides
 		if modelFlag != "" {
 			tierManager.SetModelOverride(modelFlag)
@@ -109,6 +158,7 @@ Examples:
 		client = ollama.NewClient(
 			ollama.WithBaseURL(url),
 			ollama.WithModel(tierManager.GetActiveModel()),
+			ollama.WithHeartbeat(ollamaHeartbeat()),
 		)
 
 		// Configure generation options
@@ -169,7 +219,12 @@ Examples:
 
 // Execute runs the root command
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil {
+		slog.Error("command failed", "error", err)
+	}
+	closeLog()
+	return err
 }
 
 func init() {
@@ -181,6 +236,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&qualityPreset, "quality", "balanced", "Generation quality preset: fast|balanced|thorough")
 	rootCmd.PersistentFlags().BoolVar(&memGraphEnabled, "mem-graph", true, "Show live memory usage graph")
 	rootCmd.PersistentFlags().BoolVar(&noSummary, "no-summary", false, "Disable actions summary")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Disable the response cache for temperature 0 requests")
+	rootCmd.PersistentFlags().BoolVar(&startOllama, "start-ollama", false, "Start `ollama serve` if it is not running (config: ollama.auto_start)")
+	rootCmd.PersistentFlags().BoolVar(&noColors, "no-colors", false, "Disable ANSI colors (also off with NO_COLOR or when output is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&themeFlag, "theme", "", "Color theme: "+strings.Join(ui.ThemeNames(), "|"))
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Level of the log in ~/.config/ollamabot/logs: debug|info|warn|error|off")
 
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Do not write changes to disk")
 	rootCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Skip creation of pre-apply backups")
@@ -224,7 +284,7 @@ func init() {
 func shouldSkipSetup(cmd *cobra.Command) bool {
 	for current := cmd; current != nil; current = current.Parent() {
 		switch current.Name() {
-		case "plan", "review", "version", "fs", "checkpoint", "session", "migrate", "unified", "init":
+		case "plan", "review", "version", "fs", "checkpoint", "session", "migrate", "unified", "init", "audit":
 			return true
 		}
 	}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/sarif"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// verifyResults returns the last lint and test action of each path checked
+// in the Verify process (Implement P2), so that problems fixed later in the
+// run are left out.
+func verifyResults(actions []agent.Action) []agent.Action {
+	latest := make(map[string]int)
+	var results []agent.Action
+	for _, a := range actions {
+		if a.ToolResult == nil || (a.Type != agent.ActionLint && a.Type != agent.ActionTest) {
+			continue
+		}
+		sched, _ := a.Metadata["schedule"].(string)
+		proc, _ := a.Metadata["process"].(string)
+		if sched != orchestrate.ScheduleImplement.String() || proc != orchestrate.Process2.String() {
+			continue
+		}
+		key := string(a.Type) + " " + a.Path
+		if i, ok := latest[key]; ok {
+			results[i] = a
+			continue
+		}
+		latest[key] = len(results)
+		results = append(results, a)
+	}
+	return results
+}
+
+// buildSARIF collects the Verify process's lint diagnostics and failed
+// tests and the judges' issues into a SARIF log.
+func buildSARIF(actions []agent.Action, sess *orchsession.Session) *sarif.Log {
+	log := sarif.New(version)
+	for _, a := range verifyResults(actions) {
+		switch a.Type {
+		case agent.ActionLint:
+			log.AddDiagnostics(a.ToolResult.Toolchain, a.ToolResult.Diagnostics)
+		case agent.ActionTest:
+			log.AddFailedTests(a.ToolResult.Toolchain, a.Path, a.ToolResult.Tests)
+		}
+	}
+	if analysis := sessionAnalysis(sess); analysis != nil && analysis.Synthesis != nil {
+		log.AddJudgeIssues(analysis.Synthesis.Issues)
+	}
+	return log
+}
+
+// writeSARIFOut writes the run's findings to path for --sarif-out.
+func writeSARIFOut(path string, ag *agent.Agent, sess *orchsession.Session) {
+	if path == "" {
+		return
+	}
+	log := buildSARIF(ag.GetActions(), sess)
+	if err := log.Write(path); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write SARIF: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("SARIF"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("%d findings → %s", len(log.Results()), path)))
+}
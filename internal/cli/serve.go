@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/grpcapi"
+	"github.com/croberts/obot/pkg/obot/obotv1"
+)
+
+// Flags of the serve command
+var (
+	serveAddr           string
+	serveTokenFile      string
+	serveReflection     bool
+	serveWorkspaces     []string
+	serveStrategyScript string
+)
+
+// serveTokenEnv is the environment variable that can give the serve
+// command's token instead of a file.
+const serveTokenEnv = "OBOT_SERVE_TOKEN"
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the gRPC API for running orchestrations remotely",
+	Long: `Serves the obot gRPC API described by proto/obot/v1/obot.proto, for IDE
+plugins and programs in other languages: StartRun starts an orchestration,
+StreamEvents follows it, AnswerConsultation answers its Clarify and Feedback
+questions, and GetSummary reports what it did.
+
+Runs go one at a time, in the workspace each names, and save their sessions
+where obot orchestrate does. A run may only name a workspace under one of
+the --allow-workspace directories, the current directory by default, and
+the script strategy always runs the server's --strategy-script.
+
+On a TCP address every call must carry "authorization: Bearer <token>".
+The token is $OBOT_SERVE_TOKEN, the contents of --token-file, or one
+generated into ~/.config/ollamabot/serve.token on first use. On a
+unix:<path> address the socket is made readable only by the user instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts []grpc.ServerOption
+		var lis net.Listener
+		var err error
+		if path, ok := strings.CutPrefix(serveAddr, "unix:"); ok {
+			if lis, err = listenUnix(path); err != nil {
+				return err
+			}
+		} else {
+			token, err := serveToken()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, grpcapi.TokenAuth(token)...)
+			if lis, err = net.Listen("tcp", serveAddr); err != nil {
+				return err
+			}
+		}
+
+		api := grpcapi.NewServer(ollamaURL, "")
+		defer api.Stop()
+		workspaces := serveWorkspaces
+		if len(workspaces) == 0 {
+			workspaces = []string{"."}
+		}
+		if err := api.AllowWorkspaces(workspaces...); err != nil {
+			return err
+		}
+		api.SetStrategyScript(serveStrategyScript)
+		srv := grpc.NewServer(opts...)
+		obotv1.RegisterObotServiceServer(srv, api)
+		if serveReflection {
+			reflection.Register(srv) // for grpcurl and the like
+		}
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sigs)
+		go func() {
+			<-sigs
+			api.Stop()
+			srv.GracefulStop()
+		}()
+
+		printInfo(fmt.Sprintf("Serving the obot gRPC API on %s", lis.Addr()))
+		return srv.Serve(lis)
+	},
+}
+
+// serveToken returns the token clients of a TCP address must present.
+func serveToken() (string, error) {
+	if token := os.Getenv(serveTokenEnv); token != "" {
+		return token, nil
+	}
+	path := serveTokenFile
+	if path == "" {
+		path = filepath.Join(config.UnifiedConfigDir(), "serve.token")
+	}
+	token, err := grpcapi.LoadOrCreateToken(path)
+	if err != nil {
+		return "", fmt.Errorf("token: %w", err)
+	}
+	printInfo("Clients authenticate with the token in " + path)
+	return token, nil
+}
+
+// listenUnix listens on a unix socket at path only the user can connect to.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path) // left by a server that did not stop cleanly
+	}
+	lis, err := listenSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:50051", "Address to listen on: host:port, or unix:<path> for a socket")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "", "File holding the token clients must present on a TCP address")
+	serveCmd.Flags().BoolVar(&serveReflection, "reflection", false, "Serve gRPC reflection, for grpcurl and the like")
+	serveCmd.Flags().StringArrayVar(&serveWorkspaces, "allow-workspace", nil, "Directory runs may work in, with those under it (repeatable; default: the current directory)")
+	serveCmd.Flags().StringVar(&serveStrategyScript, "strategy-script", "", "Program deciding for runs with the script strategy")
+	rootCmd.AddCommand(serveCmd)
+}
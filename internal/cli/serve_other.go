@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cli
+
+import "net"
+
+// listenSocket listens on a unix socket at path; there is no umask, so
+// listenUnix restricts it afterwards.
+func listenSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
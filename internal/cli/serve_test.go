@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnix_OwnerOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "obot.sock")
+	lis, err := listenUnix(path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer lis.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("socket mode = %v, want no group or other access", perm)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package cli
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenSocket listens on a unix socket at path that only the user can
+// connect to from the moment it exists: the socket is created under a umask
+// clearing the group and other bits, rather than chmodded afterwards.
+func listenSocket(path string) (net.Listener, error) {
+	old := syscall.Umask(0177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
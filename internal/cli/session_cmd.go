@@ -12,7 +12,8 @@ import (
 )
 
 var usfSessionCmd = &cobra.Command{
-	Use:   "session",
+	Use:     "session",
+	Aliases: []string{"sessions"},
 	Short: "Manage obot sessions (USF format)",
 	Long:  `List, export, and inspect sessions in the Unified Session Format.`,
 }
@@ -139,6 +140,42 @@ var sessionShowCmd = &cobra.Command{
 	},
 }
 
+var sessionErrorsCmd = &cobra.Command{
+	Use:   "errors [session-id]",
+	Short: "List errors recorded in an orchestration session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseDir := session.DefaultBaseDir()
+		store, err := newSessionStorage(baseDir)
+		if err != nil {
+			return err
+		}
+		s, err := session.LoadWithStorage(baseDir, store, args[0])
+		if err != nil {
+			return fmt.Errorf("load session: %w", err)
+		}
+
+		records := s.GetErrors()
+		if len(records) == 0 {
+			printInfo(fmt.Sprintf("No errors recorded in session %s.", args[0]))
+			return nil
+		}
+
+		fmt.Printf("\n%s Errors in session %s (%d)\n\n", cyan("⚠"), cyan(args[0]), len(records))
+		for _, rec := range records {
+			fmt.Printf("  %s %s [%s] %s\n", red("✗"), rec.Code, rec.Impact, rec.Component)
+			if rec.Schedule != "" {
+				fmt.Printf("    Where:      %s / %s\n", rec.Schedule, rec.Process)
+			}
+			fmt.Printf("    Message:    %s\n", rec.Message)
+			fmt.Printf("    Resolution: %s\n", rec.Resolution)
+			fmt.Printf("    Time:       %s\n", rec.Timestamp.Format("2006-01-02 15:04:05"))
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
 var sessionSaveCmd = &cobra.Command{
 	Use:   "save",
 	Short: "Save the current active session",
@@ -223,6 +260,7 @@ func init() {
 	usfSessionCmd.AddCommand(sessionListCmd)
 	usfSessionCmd.AddCommand(sessionExportCmd)
 	usfSessionCmd.AddCommand(sessionShowCmd)
+	usfSessionCmd.AddCommand(sessionErrorsCmd)
 	usfSessionCmd.AddCommand(sessionSaveCmd)
 	usfSessionCmd.AddCommand(sessionLoadCmd)
 	usfSessionCmd.AddCommand(sessionImportCmd)
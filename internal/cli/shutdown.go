@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// shutdownGracePeriod is how long an interrupted run may take to abort its
+// current action and unwind before the shutdown is forced.
+const shutdownGracePeriod = 10 * time.Second
+
+// shutdownCoordinator turns SIGINT/SIGTERM into an orderly shutdown. The
+// first signal cancels the run so the current action aborts and the caller
+// can flush the session; a second signal, or the grace period expiring,
+// calls force.
+type shutdownCoordinator struct {
+	cancel      context.CancelFunc
+	force       func()
+	out         io.Writer
+	grace       time.Duration
+	interrupted atomic.Bool
+	done        chan struct{}
+	doneOnce    sync.Once
+}
+
+// newShutdownCoordinator creates a coordinator that cancels the run via cancel
+// and calls force when the shutdown must not wait any longer.
+func newShutdownCoordinator(cancel context.CancelFunc, force func(), out io.Writer) *shutdownCoordinator {
+	return &shutdownCoordinator{
+		cancel: cancel,
+		force:  force,
+		out:    out,
+		grace:  shutdownGracePeriod,
+		done:   make(chan struct{}),
+	}
+}
+
+// watch handles signals until the run completes. It is meant to run in its own goroutine.
+func (sc *shutdownCoordinator) watch(sigs <-chan os.Signal) {
+	var sig os.Signal
+	select {
+	case sig = <-sigs:
+	case <-sc.done:
+		return
+	}
+
+	sc.interrupted.Store(true)
+	fmt.Fprintln(sc.out, "\n"+ui.FormatWarning(fmt.Sprintf("Received %s, aborting current action and saving session (press Ctrl+C again to force exit)...", sig)))
+	sc.cancel()
+
+	timer := time.NewTimer(sc.grace)
+	defer timer.Stop()
+	select {
+	case <-sigs:
+		fmt.Fprintln(sc.out, ui.FormatWarning("Forcing exit..."))
+	case <-timer.C:
+		fmt.Fprintln(sc.out, ui.FormatWarning("Shutdown timed out, forcing exit..."))
+	case <-sc.done:
+		return
+	}
+	sc.force()
+}
+
+// Interrupted reports whether a shutdown signal was received.
+func (sc *shutdownCoordinator) Interrupted() bool {
+	return sc.interrupted.Load()
+}
+
+// Done marks the run as finished so watch stops waiting.
+func (sc *shutdownCoordinator) Done() {
+	sc.doneOnce.Do(func() { close(sc.done) })
+}
+
+// flushInterrupted records the interruption, prints the summary, saves the
+// session, and tells the user how to resume it.
+func flushInterrupted(orch *orchestrate.Orchestrator, ag *agent.Agent, sess *orchsession.Session, summary func()) {
+	where := "before the first process"
+	if s, p := orch.CurrentSchedule(), orch.CurrentProcess(); s != nil && p != nil {
+		where = fmt.Sprintf("during %s P%d %s", orchestrate.ScheduleNames[s.ID], p.ID, orchestrate.ProcessNames[s.ID][p.ID])
+	}
+	orch.AddNote("Orchestration interrupted "+where, "system")
+	if ag != nil {
+		if actions := ag.GetActions(); len(actions) > 0 {
+			last := actions[len(actions)-1]
+			if status, _ := last.Metadata["status"].(string); status == "failed" {
+				orch.AddNote("Aborted action: "+last.ActionOutput(), "system")
+			}
+		}
+	}
+
+	sess.SetStatus(orchsession.StatusInterrupted)
+	if summary != nil {
+		summary()
+	}
+	saveSession(orch, sess)
+	printResumeInstructions(os.Stdout, sess.GetID())
+}
+
+// printResumeInstructions prints how to inspect and resume a saved session.
+func printResumeInstructions(out io.Writer, sessionID string) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, ui.FormatLabel("Resume"))
+	fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Session:"), ui.FormatValue(sessionID))
+	fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Inspect:"), ui.FormatValue("obot session show "+sessionID))
+	fmt.Fprintf(out, "  %s %s\n", ui.FormatValueMuted("Resume:"), ui.FormatValue("obot orchestrate --session "+sessionID))
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestShutdownCoordinator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	forced := make(chan struct{})
+	sc := newShutdownCoordinator(cancel, func() { close(forced) }, io.Discard)
+	sc.grace = time.Minute
+
+	sigs := make(chan os.Signal, 2)
+	go sc.watch(sigs)
+
+	sigs <- syscall.SIGINT
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("first signal did not cancel the context")
+	}
+	if !sc.Interrupted() {
+		t.Error("Interrupted() = false after signal")
+	}
+
+	sigs <- syscall.SIGINT
+	select {
+	case <-forced:
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not force exit")
+	}
+}
+
+func TestShutdownCoordinator_DoneStopsWatch(t *testing.T) {
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	forced := make(chan struct{})
+	sc := newShutdownCoordinator(cancel, func() { close(forced) }, io.Discard)
+	sc.grace = 50 * time.Millisecond
+
+	sigs := make(chan os.Signal, 1)
+	go sc.watch(sigs)
+	sigs <- syscall.SIGTERM
+	for !sc.Interrupted() {
+		time.Sleep(time.Millisecond)
+	}
+	sc.Done()
+	sc.Done()
+
+	select {
+	case <-forced:
+		t.Error("force called after Done")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestFlushInterrupted(t *testing.T) {
+	baseDir := t.TempDir()
+	orch := orchestrate.NewOrchestrator()
+	sess := orchsession.NewSessionWithBaseDir(baseDir)
+
+	flushInterrupted(orch, nil, sess, nil)
+
+	loaded, err := orchsession.Load(baseDir, sess.GetID())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.GetStatus() != orchsession.StatusInterrupted {
+		t.Errorf("status = %q, want %q", loaded.GetStatus(), orchsession.StatusInterrupted)
+	}
+	summary, err := os.ReadFile(filepath.Join(baseDir, sess.GetID(), "summary.txt"))
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	if !strings.Contains(string(summary), "Status: interrupted") {
+		t.Errorf("summary = %q", summary)
+	}
+}
+
+func TestPrintResumeInstructions(t *testing.T) {
+	var out strings.Builder
+	printResumeInstructions(&out, "sess-1")
+	if !strings.Contains(out.String(), "obot orchestrate --session sess-1") {
+		t.Errorf("resume instructions = %q, want the --session command", out.String())
+	}
+}
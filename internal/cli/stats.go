@@ -10,6 +10,7 @@ import (
 
 	"github.com/croberts/obot/internal/stats"
 	"github.com/croberts/obot/internal/telemetry"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 // statsCmd shows cost savings statistics
@@ -67,31 +68,20 @@ func runStats(cmd *cobra.Command, args []string) error {
 
 	// Build the box
 	width := 55
-	borderColor := primaryColor
 	titleColor := primaryBoldColor
 	valueColor := color.New(color.FgGreen)
 	labelColor := color.New(color.FgWhite)
 	dimColor := color.New(color.FgHiBlack)
-
-	// Top border
-	borderColor.Println("╭" + strings.Repeat("─", width-2) + "╮")
+	box := layout.NewBox(width).SetRounded(true).SetBorderFunc(primaryColor.SprintFunc())
 
 	// Title
-	title := "obot savings report"
-	padding := (width - 2 - len(title)) / 2
-	borderColor.Print("│")
-	fmt.Print(strings.Repeat(" ", padding))
-	titleColor.Print(title)
-	fmt.Print(strings.Repeat(" ", width-2-padding-len(title)))
-	borderColor.Println("│")
-
-	// Separator
-	borderColor.Println("├" + strings.Repeat("─", width-2) + "┤")
+	box.Line(layout.Center(titleColor.Sprint("obot savings report"), box.InnerWidth()))
+	box.Divider()
 
 	// Usage stats
-	printStatLine(borderColor, labelColor, valueColor, "Total tokens:", formatNumber(summary.TotalTokens), width)
-	printStatLine(borderColor, labelColor, valueColor, "Files fixed:", formatNumber(summary.FilesFixes), width)
-	printStatLine(borderColor, labelColor, valueColor, "Sessions:", formatNumber(summary.Sessions), width)
+	statLine(box, labelColor, valueColor, "Total tokens:", formatNumber(summary.TotalTokens))
+	statLine(box, labelColor, valueColor, "Files fixed:", formatNumber(summary.FilesFixes))
+	statLine(box, labelColor, valueColor, "Sessions:", formatNumber(summary.Sessions))
 
 	// Time range
 	if !summary.FirstUse.IsZero() {
@@ -100,65 +90,32 @@ func runStats(cmd *cobra.Command, args []string) error {
 		if daysUsed < 1 {
 			daysUsed = 1
 		}
-		printStatLine(borderColor, labelColor, dimColor, "Days using obot:", fmt.Sprintf("%d", daysUsed), width)
+		statLine(box, labelColor, dimColor, "Days using obot:", fmt.Sprintf("%d", daysUsed))
 	}
-
-	// Empty line
-	borderColor.Print("│")
-	fmt.Print(strings.Repeat(" ", width-2))
-	borderColor.Println("│")
+	box.Blank()
 
 	// Cost savings header
-	borderColor.Print("│  ")
-	primaryColor.Print("💰 Cost Savings vs Commercial APIs:")
-	fmt.Print(strings.Repeat(" ", width-2-38))
-	borderColor.Println("│")
-
-	// Divider
-	borderColor.Print("│  ")
-	dimColor.Print(strings.Repeat("─", 37))
-	fmt.Print(strings.Repeat(" ", width-2-39))
-	borderColor.Println("│")
+	box.Line(" " + primaryColor.Sprint("💰 Cost Savings vs Commercial APIs:"))
+	box.Line(" " + dimColor.Sprint(strings.Repeat("─", 37)))
 
 	// Savings by provider
 	savingsColor := color.New(color.FgGreen, color.Bold)
-	printSavingsLine(borderColor, labelColor, savingsColor, "Claude Opus 4.5:", summary.ClaudeOpusSavings, width)
-	printSavingsLine(borderColor, labelColor, valueColor, "Claude Sonnet 3.5:", summary.ClaudeSonnetSavings, width)
-	printSavingsLine(borderColor, labelColor, valueColor, "GPT-4o:", summary.GPT4oSavings, width)
-
-	// Empty line
-	borderColor.Print("│")
-	fmt.Print(strings.Repeat(" ", width-2))
-	borderColor.Println("│")
+	savingsLine(box, labelColor, savingsColor, "Claude Opus 4.5:", summary.ClaudeOpusSavings)
+	savingsLine(box, labelColor, valueColor, "Claude Sonnet 3.5:", summary.ClaudeSonnetSavings)
+	savingsLine(box, labelColor, valueColor, "GPT-4o:", summary.GPT4oSavings)
+	box.Blank()
 
 	// Projections
 	if summary.MonthlyProjection > 0 {
-		borderColor.Print("│  ")
-		primaryColor.Print("📈 ")
-		labelColor.Print("Monthly projection: ")
-		savingsColor.Print(formatCurrency(summary.MonthlyProjection))
-		dimColor.Print(" (Opus rate)")
-		remaining := width - 2 - 4 - 20 - len(formatCurrency(summary.MonthlyProjection)) - 12
-		if remaining > 0 {
-			fmt.Print(strings.Repeat(" ", remaining))
-		}
-		borderColor.Println("│")
+		box.Line(" " + primaryColor.Sprint("📈 ") + labelColor.Sprint("Monthly projection: ") +
+			savingsColor.Sprint(formatCurrency(summary.MonthlyProjection)) + dimColor.Sprint(" (Opus rate)"))
 	}
 
 	// Data privacy
 	dataKB := float64(summary.TotalTokens*4) / 1024.0
-	borderColor.Print("│  ")
-	primaryColor.Print("🔒 ")
-	labelColor.Print("Data kept local: ")
-	valueColor.Print(fmt.Sprintf("%.1f KB", dataKB))
-	remaining := width - 2 - 4 - 17 - len(fmt.Sprintf("%.1f KB", dataKB))
-	if remaining > 0 {
-		fmt.Print(strings.Repeat(" ", remaining))
-	}
-	borderColor.Println("│")
+	box.Line(" " + primaryColor.Sprint("🔒 ") + labelColor.Sprint("Data kept local: ") + valueColor.Sprint(fmt.Sprintf("%.1f KB", dataKB)))
 
-	// Bottom border
-	borderColor.Println("╰" + strings.Repeat("─", width-2) + "╯")
+	fmt.Print(box.String())
 
 	// Footer note
 	fmt.Println()
@@ -168,37 +125,14 @@ func runStats(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func printStatLine(border, label, value *color.Color, labelText string, valueText string, width int) {
-	border.Print("│  ")
-	label.Print(labelText)
-	spacing := 25 - len(labelText)
-	if spacing < 1 {
-		spacing = 1
-	}
-	fmt.Print(strings.Repeat(" ", spacing))
-	value.Print(valueText)
-	remaining := width - 2 - 2 - len(labelText) - spacing - len(valueText)
-	if remaining > 0 {
-		fmt.Print(strings.Repeat(" ", remaining))
-	}
-	border.Println("│")
+// statLine adds a label and its value, aligned in a column, to the box.
+func statLine(box *layout.Box, label, value *color.Color, labelText string, valueText string) {
+	box.Line(" " + label.Sprint(layout.Pad(labelText, 24)) + " " + value.Sprint(valueText))
 }
 
-func printSavingsLine(border, label, value *color.Color, labelText string, amount float64, width int) {
-	border.Print("│  ")
-	label.Print(labelText)
-	spacing := 20 - len(labelText)
-	if spacing < 1 {
-		spacing = 1
-	}
-	fmt.Print(strings.Repeat(" ", spacing))
-	valueStr := formatCurrency(amount) + " saved"
-	value.Print(valueStr)
-	remaining := width - 2 - 2 - len(labelText) - spacing - len(valueStr)
-	if remaining > 0 {
-		fmt.Print(strings.Repeat(" ", remaining))
-	}
-	border.Println("│")
+// savingsLine adds a provider's savings, aligned in a column, to the box.
+func savingsLine(box *layout.Box, label, value *color.Color, labelText string, amount float64) {
+	box.Line(" " + label.Sprint(layout.Pad(labelText, 19)) + " " + value.Sprint(formatCurrency(amount)+" saved"))
 }
 
 func formatNumber(n int) string {
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+// newSessionStorage returns the session storage backend selected in the
+// unified config, defaulting to the local sessions directory baseDir.
+func newSessionStorage(baseDir string) (orchsession.Storage, error) {
+	if cfg == nil || cfg.Unified == nil {
+		return orchsession.NewFSStorage(baseDir), nil
+	}
+	sc := cfg.Unified.Sessions.Storage
+	switch strings.ToLower(sc.Backend) {
+	case "", "local":
+		return orchsession.NewFSStorage(baseDir), nil
+	case "s3", "minio":
+		return orchsession.NewS3Storage(orchsession.S3Options{
+			Endpoint:  sc.S3.Endpoint,
+			Region:    sc.S3.Region,
+			Bucket:    sc.S3.Bucket,
+			Prefix:    sc.S3.Prefix,
+			PathStyle: sc.S3.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown session storage backend %q (want local or s3)", sc.Backend)
+	}
+}
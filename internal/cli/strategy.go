@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Decision strategy flags of the orchestrate command
+var (
+	orchStrategy       string
+	orchStrategyScript string
+)
+
+// setupStrategy has modelCoord decide the next schedule and process with
+// the --strategy strategy, or with the --strategy-script program when only
+// it is given.
+func setupStrategy(modelCoord *model.Coordinator) error {
+	name := orchStrategy
+	if name == "" && orchStrategyScript != "" {
+		name = orchestrate.StrategyScript
+	}
+	strategy, err := orchestrate.NewStrategy(name, modelCoord.GetOrchestratorModel(), orchStrategyScript)
+	if err != nil {
+		return fmt.Errorf("--strategy: %w", err)
+	}
+	modelCoord.SetStrategy(strategy)
+	return nil
+}
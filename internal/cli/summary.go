@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/summary"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// newSummaryGenerator fills a summary generator with the run's results.
+func newSummaryGenerator(orch *orchestrate.Orchestrator, ag *agent.Agent, resMon *resource.Monitor, sess *orchsession.Session) *summary.Generator {
+	gen := summary.NewGenerator()
+	gen.SetStats(orch.GetStats())
+	gen.SetFlowCode(orch.GetFlowCode())
+	gen.SetActions(ag.GetStats(), ag.GetRecorder().GenerateEditDetails())
+	gen.SetFileChanges(ag.GetRecorder().FileChanges())
+	resources := resMon.GetSummary()
+	gen.SetResources(resources)
+	for _, run := range resources.Tokens.Flow {
+		gen.AddProcessTokens(run.Schedule, run.Process, run.Tokens)
+	}
+	gen.SetCoverage(sess.GetCoverage())
+	gen.SetNotes(orch.GetNotes())
+	if _, tldr := sess.GetAnalysis(); tldr != "" {
+		gen.SetTLDR(tldr)
+	}
+	return gen
+}
+
+// writeSummaryOut writes the prompt summary to path for --summary-out, in
+// the format its extension names: Markdown, HTML, JSON, or plain text.
+func writeSummaryOut(path string, orch *orchestrate.Orchestrator, ag *agent.Agent, resMon *resource.Monitor, sess *orchsession.Session) {
+	if path == "" {
+		return
+	}
+	data, err := newSummaryGenerator(orch, ag, resMon, sess).Render(summary.FormatForPath(path))
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to write summary: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Summary"), ui.FormatBullet()+ui.FormatValue(path))
+}
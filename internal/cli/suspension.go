@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// newSuspensionErrorHandler returns an orchestrator error handler that shows
+// the suspension UI and maps the user's R/S/A/I choice to a resolution.
+func newSuspensionErrorHandler(
+	orch *orchestrate.Orchestrator,
+	ag *agent.Agent,
+	sess *orchsession.Session,
+	analyzer *ollama.Client,
+	in io.Reader,
+	out io.Writer,
+) orchestrate.ErrorHandler {
+	reader := bufio.NewReader(in)
+	handler := errs.NewSuspensionHandler(out, reader, analyzer, orch)
+
+	return func(ctx context.Context, err error, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) orchestrate.ErrorResolution {
+		oe := errs.NewProcessError(err, "Agent", frozenState(orch, ag, schedID, procID))
+		runNotifier.Load().Notify(ctx, "obot: process suspended", err.Error())
+
+		// The run waits on the human's choice
+		start := time.Now()
+		action := handler.HandleContext(ctx, oe)
+		if action == errs.ActionInvestigate {
+			action = investigateSuspension(ctx, oe, orch, ag, reader, out)
+		}
+		recordHumanWait(time.Since(start))
+
+		resolution := orchestrate.ResolutionAbort
+		switch action {
+		case errs.ActionRetry:
+			resolution = orchestrate.ResolutionRetry
+		case errs.ActionSkip:
+			resolution = orchestrate.ResolutionSkip
+		}
+		recordSessionError(sess, oe, string(resolution))
+		return resolution
+	}
+}
+
+// frozenState captures the orchestrator state for a suspension. ag may be nil.
+func frozenState(orch *orchestrate.Orchestrator, ag *agent.Agent, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) errs.FrozenState {
+	lastAction := "none"
+	var recent []string
+	if ag != nil {
+		actions := ag.GetActions()
+		if len(actions) > 0 {
+			lastAction = actions[len(actions)-1].ActionOutput()
+		}
+		if len(actions) > 5 {
+			actions = actions[len(actions)-5:]
+		}
+		for _, a := range actions {
+			line := a.ActionOutput()
+			if status, ok := a.Metadata["status"].(string); ok && status == "failed" {
+				line += fmt.Sprintf(" [failed: %v]", a.Metadata["error"])
+			}
+			recent = append(recent, line)
+		}
+	}
+	return errs.FrozenState{
+		Schedule:      orchestrate.ScheduleNames[schedID],
+		Process:       fmt.Sprintf("P%d %s", procID, orchestrate.ProcessNames[schedID][procID]),
+		LastAction:    lastAction,
+		FlowCode:      orch.GetFlowCode(),
+		RecentActions: recent,
+	}
+}
+
+// currentFrozenState captures the orchestrator state at its current position.
+func currentFrozenState(orch *orchestrate.Orchestrator, ag *agent.Agent) errs.FrozenState {
+	var schedID orchestrate.ScheduleID
+	var procID orchestrate.ProcessID
+	if s := orch.CurrentSchedule(); s != nil {
+		schedID = s.ID
+	}
+	if p := orch.CurrentProcess(); p != nil {
+		procID = p.ID
+	}
+	return frozenState(orch, ag, schedID, procID)
+}
+
+// recordSessionError records an error occurrence and the resolution taken in the session.
+func recordSessionError(sess *orchsession.Session, oe *errs.OrchestrationError, resolution string) {
+	if sess == nil {
+		return
+	}
+	sess.RecordError(orchsession.ErrorRecord{
+		Code:       string(oe.Code),
+		Impact:     errs.GetImpact(oe.Code).String(),
+		Component:  oe.Component,
+		Message:    oe.Message,
+		Schedule:   oe.State.Schedule,
+		Process:    oe.State.Process,
+		Resolution: resolution,
+		Timestamp:  oe.Timestamp,
+	})
+	sendErrorEvent(oe, resolution)
+}
+
+// saveSession persists the orchestration session with its flow code and notes.
+func saveSession(orch *orchestrate.Orchestrator, sess *orchsession.Session) {
+	if err := persistSession(orch, sess); err != nil {
+		fmt.Fprintln(os.Stderr, ui.FormatWarning("Failed to save session: "+err.Error()))
+		return
+	}
+	fmt.Printf("%s %s\n", ui.FormatLabel("Session"), ui.FormatBullet()+ui.FormatValue("Saved "+sess.GetID()))
+}
+
+// persistSession copies the flow code and the notes not yet persisted into
+// the session and saves it.
+func persistSession(orch *orchestrate.Orchestrator, sess *orchsession.Session) error {
+	sess.SetFlowCode(orch.GetFlowCode())
+	sess.SyncOrchestratorNotes(orch.GetNotes())
+	return sess.Save()
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestSuspensionErrorHandler(t *testing.T) {
+	orch := orchestrate.NewOrchestrator()
+	ag := agent.NewAgent(model.NewCoordinator(nil))
+	sess := orchsession.NewSessionWithBaseDir(t.TempDir())
+
+	tests := []struct {
+		input string
+		want  orchestrate.ErrorResolution
+	}{
+		{"r\n", orchestrate.ResolutionRetry},
+		{"s\n", orchestrate.ResolutionSkip},
+		{"a\n", orchestrate.ResolutionAbort},
+		{"i\ns\n", orchestrate.ResolutionSkip},
+		{"", orchestrate.ResolutionAbort},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		handler := newSuspensionErrorHandler(orch, ag, sess, nil, strings.NewReader(tt.input), &out)
+		got := handler(context.Background(), errors.New("boom"), orchestrate.ScheduleImplement, orchestrate.Process1)
+		if got != tt.want {
+			t.Errorf("input %q: resolution = %s, want %s", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "SUSPENDED") {
+			t.Errorf("input %q: suspension UI not shown", tt.input)
+		}
+	}
+
+	records := sess.GetErrors()
+	if len(records) != len(tests) {
+		t.Fatalf("recorded %d errors, want %d", len(records), len(tests))
+	}
+	if records[0].Resolution != string(orchestrate.ResolutionRetry) || records[0].Schedule != "Implement" {
+		t.Errorf("first record = %+v", records[0])
+	}
+}
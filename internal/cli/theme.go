@@ -1,6 +1,10 @@
 package cli
 
-import "github.com/fatih/color"
+import (
+	"github.com/fatih/color"
+
+	"github.com/croberts/obot/internal/ui"
+)
 
 var (
 	primaryColor     = color.New(color.FgBlue)
@@ -13,3 +17,27 @@ var (
 	yellow  = color.New(color.FgYellow).SprintFunc()
 	red     = color.New(color.FgRed).SprintFunc()
 )
+
+// setupColors turns colored output on or off for the ui package, the
+// packages that consult term.Colors, and the colors above.
+func setupColors(enabled bool) {
+	ui.SetColors(enabled)
+	color.NoColor = !enabled
+}
+
+// setupTheme applies the named theme, with colors overriding its roles.
+// An empty name and no colors leave the current theme.
+func setupTheme(name string, colors map[string]string) error {
+	if name == "" && len(colors) == 0 {
+		return nil
+	}
+	theme, err := ui.ThemeByName(name)
+	if err != nil {
+		return err
+	}
+	if theme, err = theme.WithColors(colors); err != nil {
+		return err
+	}
+	ui.ApplyTheme(theme)
+	return nil
+}
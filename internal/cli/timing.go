@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/resource"
+)
+
+// runClock is the resource monitor of the running orchestration, which the
+// time spent waiting on the human is charged to.
+var runClock atomic.Pointer[resource.Monitor]
+
+// recordHumanWait charges d of waiting on the human to the running
+// orchestration, if any.
+func recordHumanWait(d time.Duration) {
+	if clock := runClock.Load(); clock != nil {
+		clock.RecordHumanWaitTime(d)
+	}
+}
+
+// openConsultation announces a consultation and stops the hang detector's
+// clock while it waits on the human.
+func openConsultation(req consultation.Request) {
+	sendConsultationEvent(req)
+	runHangs.Load().pause()
+}
+
+// closeConsultation charges the wait of a consultation to the run and
+// restarts the hang detector's clock.
+func closeConsultation(d time.Duration) {
+	recordHumanWait(d)
+	runHangs.Load().resume()
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/config"
+)
+
+// toolchains converts configured toolchains for the agent.
+func toolchains(cfgs []config.ToolchainConfig) []agent.Toolchain {
+	out := make([]agent.Toolchain, 0, len(cfgs))
+	for _, c := range cfgs {
+		out = append(out, agent.Toolchain{
+			Name:       c.Name,
+			Extensions: c.Extensions,
+			Markers:    c.Markers,
+			Lint:       c.Lint,
+			Format:     c.Format,
+			Test:       c.Test,
+			Check:      c.Check,
+			LintParser: c.LintParser,
+			TestParser: c.TestParser,
+
+			LanguageServer: c.LanguageServer,
+		})
+	}
+	return out
+}
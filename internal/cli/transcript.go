@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/logging"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+var (
+	transcriptSchedule string
+	transcriptProcess  string
+	transcriptRole     string
+	transcriptSearch   string
+	transcriptPage     int
+	transcriptPageSize int
+	transcriptFull     bool
+)
+
+// transcriptPreviewLines is how many lines of each message are shown
+// without --full.
+const transcriptPreviewLines = 12
+
+var sessionTranscriptCmd = &cobra.Command{
+	Use:   "transcript [session-id]",
+	Short: "Browse the prompts sent to the models in a session and their responses",
+	Long: `Browse the prompts sent to the models during an orchestration session,
+with each response, its model, tokens, and latency.
+
+Filter by --schedule (S3, 3, or Implement), --process (P2 or Verify), or
+--role, search with --search, and page through the results with --page.
+
+Examples:
+  obot session transcript 1712345678 --schedule S2
+  obot session transcript 1712345678 --search "select schedule" --full
+  obot session transcript 1712345678 --page 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseDir := orchsession.DefaultBaseDir()
+		store, err := newSessionStorage(baseDir)
+		if err != nil {
+			return err
+		}
+		s, err := orchsession.LoadWithStorage(baseDir, store, args[0])
+		if err != nil {
+			return fmt.Errorf("load session: %w", err)
+		}
+		filter, err := newTranscriptFilter(transcriptSchedule, transcriptProcess, transcriptRole, transcriptSearch)
+		if err != nil {
+			return err
+		}
+		entries := s.GetTranscript()
+		if len(entries) == 0 {
+			printInfo(fmt.Sprintf("No transcript recorded in session %s.", args[0]))
+			return nil
+		}
+		printTranscript(os.Stdout, entries, filter, transcriptPage, transcriptPageSize, transcriptFull)
+		return nil
+	},
+}
+
+func init() {
+	sessionTranscriptCmd.Flags().StringVar(&transcriptSchedule, "schedule", "", "Only prompts sent in a schedule (S3, 3, or Implement)")
+	sessionTranscriptCmd.Flags().StringVar(&transcriptProcess, "process", "", "Only prompts sent in a process (P2 or Verify)")
+	sessionTranscriptCmd.Flags().StringVar(&transcriptRole, "role", "", "Only prompts sent to a model role (orchestrator, coder, researcher, vision)")
+	sessionTranscriptCmd.Flags().StringVar(&transcriptSearch, "search", "", "Only prompts or responses containing the text")
+	sessionTranscriptCmd.Flags().IntVar(&transcriptPage, "page", 1, "Page of results to show")
+	sessionTranscriptCmd.Flags().IntVar(&transcriptPageSize, "page-size", 10, "Exchanges per page")
+	sessionTranscriptCmd.Flags().BoolVar(&transcriptFull, "full", false, "Show whole messages instead of their first lines")
+	usfSessionCmd.AddCommand(sessionTranscriptCmd)
+}
+
+// recordTranscript has every prompt sent to the models, and the response,
+// recorded in the session, tagged with the schedule and process it was
+// sent in.
+func recordTranscript(modelCoord *model.Coordinator, sess *orchsession.Session) {
+	roles := []orchestrate.ModelType{orchestrate.ModelOrchestrator, orchestrate.ModelCoder, orchestrate.ModelResearcher, orchestrate.ModelVision}
+	for _, role := range roles {
+		client := modelCoord.Get(role)
+		if client == nil {
+			continue
+		}
+		client.SetObserver(func(ctx context.Context, ex ollama.Exchange) {
+			sess.RecordTranscript(transcriptEntry(ctx, string(role), ex))
+		})
+	}
+}
+
+// transcriptEntry converts a completed request to a transcript entry.
+func transcriptEntry(ctx context.Context, role string, ex ollama.Exchange) orchsession.TranscriptEntry {
+	entry := orchsession.TranscriptEntry{
+		Role:     role,
+		Model:    ex.Model,
+		Response: ex.Response,
+		Latency:  ex.Latency,
+	}
+	for _, a := range logging.Attrs(ctx) {
+		switch a.Key {
+		case "schedule":
+			entry.Schedule = a.Value.String()
+		case "process":
+			entry.Process = a.Value.String()
+		}
+	}
+	for _, m := range ex.Messages {
+		entry.Messages = append(entry.Messages, orchsession.TranscriptMessage{Role: m.Role, Content: m.Content})
+	}
+	if ex.Stats != nil {
+		entry.PromptTokens = ex.Stats.PromptTokens
+		entry.CompletionTokens = ex.Stats.CompletionTokens
+		entry.Cached = ex.Stats.Cached
+	}
+	if ex.Err != nil {
+		entry.Error = ex.Err.Error()
+	}
+	return entry
+}
+
+// transcriptFilter selects transcript entries.
+type transcriptFilter struct {
+	schedule string // schedule name, "" for any
+	process  string // process name, "" for any
+	role     string
+	search   string
+}
+
+// newTranscriptFilter resolves the schedule and process flags to names.
+func newTranscriptFilter(schedule, process, role, search string) (transcriptFilter, error) {
+	f := transcriptFilter{role: strings.ToLower(role), search: search}
+	var schedID orchestrate.ScheduleID
+	if schedule != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(schedule), "S"))
+		if err == nil {
+			name, ok := orchestrate.ScheduleNames[orchestrate.ScheduleID(n)]
+			if !ok {
+				return f, fmt.Errorf("unknown schedule %q", schedule)
+			}
+			schedID, f.schedule = orchestrate.ScheduleID(n), name
+		} else {
+			f.schedule = schedule
+		}
+	}
+	if process != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(process), "P"))
+		switch {
+		case err != nil:
+			f.process = process
+		case schedID == 0:
+			return f, fmt.Errorf("--process %s needs --schedule; or give the process name", process)
+		default:
+			name, ok := orchestrate.ProcessNames[schedID][orchestrate.ProcessID(n)]
+			if !ok {
+				return f, fmt.Errorf("unknown process %q", process)
+			}
+			f.process = name
+		}
+	}
+	return f, nil
+}
+
+// matches reports whether an entry passes the filter.
+func (f transcriptFilter) matches(e orchsession.TranscriptEntry) bool {
+	if f.schedule != "" && !strings.EqualFold(e.Schedule, f.schedule) {
+		return false
+	}
+	if f.process != "" && !strings.EqualFold(e.Process, f.process) {
+		return false
+	}
+	if f.role != "" && e.Role != f.role {
+		return false
+	}
+	return f.search == "" || e.Matches(f.search)
+}
+
+// printTranscript writes one page of the entries passing the filter,
+// numbered by their place in the whole transcript.
+func printTranscript(w io.Writer, entries []orchsession.TranscriptEntry, filter transcriptFilter, page, pageSize int, full bool) {
+	var matched []int
+	for i, e := range entries {
+		if filter.matches(e) {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == 0 {
+		fmt.Fprintf(w, "No exchanges match (%d recorded).\n", len(entries))
+		return
+	}
+	pageSize = max(pageSize, 1)
+	pages := (len(matched) + pageSize - 1) / pageSize
+	page = min(max(page, 1), pages)
+	start := (page - 1) * pageSize
+	end := min(start+pageSize, len(matched))
+
+	var highlight *regexp.Regexp
+	if filter.search != "" {
+		highlight = regexp.MustCompile("(?i)" + regexp.QuoteMeta(filter.search))
+	}
+	for _, i := range matched[start:end] {
+		printTranscriptEntry(w, i+1, entries[i], highlight, full)
+	}
+	fmt.Fprintf(w, "Page %d of %d (%d of %d exchanges)", page, pages, len(matched), len(entries))
+	if page < pages {
+		fmt.Fprintf(w, " · --page %d for more", page+1)
+	}
+	fmt.Fprintln(w)
+}
+
+// printTranscriptEntry writes an exchange: its header, each prompt
+// message, and the response.
+func printTranscriptEntry(w io.Writer, n int, e orchsession.TranscriptEntry, highlight *regexp.Regexp, full bool) {
+	where := e.Schedule
+	if e.Process != "" {
+		where += " / " + e.Process
+	}
+	if where == "" {
+		where = "between schedules"
+	}
+	tokens := fmt.Sprintf("%d→%d tokens", e.PromptTokens, e.CompletionTokens)
+	if e.Cached {
+		tokens = "cached"
+	}
+	fmt.Fprintf(w, "%s %s %s · %s · %s · %s · %s\n", cyan(fmt.Sprintf("#%d", n)), e.Role, blue("("+e.Model+")"),
+		where, e.Latency.Round(time.Millisecond), tokens, e.Timestamp.Format("15:04:05"))
+
+	for _, m := range e.Messages {
+		printTranscriptText(w, m.Role, m.Content, highlight, full)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(w, "  %s %s\n", red("✗ error:"), e.Error)
+	} else {
+		printTranscriptText(w, "response", e.Response, highlight, full)
+	}
+	fmt.Fprintln(w)
+}
+
+// printTranscriptText writes a labeled message, indented, cut to its first
+// lines unless full.
+func printTranscriptText(w io.Writer, label, text string, highlight *regexp.Regexp, full bool) {
+	fmt.Fprintf(w, "  %s\n", yellow("── "+label+" ──"))
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	hidden := 0
+	if !full && len(lines) > transcriptPreviewLines {
+		hidden = len(lines) - transcriptPreviewLines
+		lines = lines[:transcriptPreviewLines]
+	}
+	for _, line := range lines {
+		if highlight != nil {
+			line = highlight.ReplaceAllStringFunc(line, func(s string) string { return magenta(s) })
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	if hidden > 0 {
+		fmt.Fprintf(w, "  %s\n", color.HiBlackString(fmt.Sprintf("… %d more lines (--full to show)", hidden)))
+	}
+}
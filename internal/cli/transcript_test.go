@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/logging"
+	"github.com/croberts/obot/internal/ollama"
+	orchsession "github.com/croberts/obot/internal/session"
+)
+
+func TestTranscriptEntry_TagsScheduleAndProcess(t *testing.T) {
+	ctx := logging.WithAttrs(context.Background(), "session", "s1", "schedule", "Implement", "process", "Verify")
+	entry := transcriptEntry(ctx, "coder", ollama.Exchange{
+		Model:    "qwen2.5-coder:14b",
+		Messages: []ollama.Message{{Role: "user", Content: "run the tests"}},
+		Err:      errors.New("request failed"),
+	})
+	if entry.Schedule != "Implement" || entry.Process != "Verify" || entry.Role != "coder" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Error != "request failed" || len(entry.Messages) != 1 {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestNewTranscriptFilter(t *testing.T) {
+	f, err := newTranscriptFilter("S3", "P2", "", "")
+	if err != nil || f.schedule != "Implement" || f.process != "Verify" {
+		t.Errorf("newTranscriptFilter(S3, P2) = %+v, %v", f, err)
+	}
+	if f, err := newTranscriptFilter("plan", "Clarify", "", ""); err != nil || f.schedule != "plan" || f.process != "Clarify" {
+		t.Errorf("newTranscriptFilter(plan, Clarify) = %+v, %v", f, err)
+	}
+	if _, err := newTranscriptFilter("S9", "", "", ""); err == nil {
+		t.Error("newTranscriptFilter accepted S9")
+	}
+	if _, err := newTranscriptFilter("", "P2", "", ""); err == nil {
+		t.Error("newTranscriptFilter accepted P2 without a schedule")
+	}
+}
+
+func TestPrintTranscript(t *testing.T) {
+	var entries []orchsession.TranscriptEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, orchsession.TranscriptEntry{Role: "orchestrator", Model: "m", Schedule: "Plan", Response: "choose Plan"})
+	}
+	entries = append(entries, orchsession.TranscriptEntry{
+		Role:     "coder",
+		Model:    "m",
+		Schedule: "Implement",
+		Messages: []orchsession.TranscriptMessage{{Role: "user", Content: strings.Repeat("line\n", 20)}},
+		Response: "patched the cache",
+	})
+
+	var out bytes.Buffer
+	filter, _ := newTranscriptFilter("S3", "", "", "cache")
+	printTranscript(&out, entries, filter, 1, 10, false)
+	got := out.String()
+	if !strings.Contains(got, "#6") || strings.Contains(got, "#1 ") {
+		t.Errorf("filtered transcript:\n%s", got)
+	}
+	if !strings.Contains(got, "8 more lines") {
+		t.Errorf("long message not cut:\n%s", got)
+	}
+
+	out.Reset()
+	printTranscript(&out, entries, transcriptFilter{}, 2, 4, false)
+	got = out.String()
+	if !strings.Contains(got, "#5") || !strings.Contains(got, "#6") || strings.Contains(got, "#4") {
+		t.Errorf("page 2:\n%s", got)
+	}
+	if !strings.Contains(got, "Page 2 of 2 (6 of 6 exchanges)") {
+		t.Errorf("page footer missing:\n%s", got)
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/stats"
+)
+
+var tuneSessions int
+
+// statsTuneCmd profiles recent orchestration sessions and suggests settings
+// to tune
+var statsTuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Profile recent sessions and suggest orchestration tuning",
+	Long: `Profile the most recent orchestration sessions: which schedules consume
+the most time and tokens, how often processes are retried, how much of the
+work the approved plans predicted, and how often prompts repeat without a
+cache hit. Then suggest concrete changes, such as lowering the coder
+temperature or enabling the prompt cache.
+
+Suggestions need at least 3 sessions.`,
+	Args: cobra.NoArgs,
+	RunE: runStatsTune,
+}
+
+func init() {
+	statsTuneCmd.Flags().IntVar(&tuneSessions, "sessions", 20, "Number of most recent sessions to profile")
+	statsCmd.AddCommand(statsTuneCmd)
+}
+
+func runStatsTune(cmd *cobra.Command, args []string) error {
+	sessions, err := recentSessions(tuneSessions)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		printInfo("No sessions to profile; run obot orchestrate first.")
+		return nil
+	}
+	report := stats.Tune(sessions)
+	printTuneReport(report)
+	return nil
+}
+
+// recentSessions loads up to n of the most recent sessions, skipping ones
+// that cannot be read.
+func recentSessions(n int) ([]*orchsession.Session, error) {
+	baseDir := orchsession.DefaultBaseDir()
+	store, err := newSessionStorage(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := orchsession.ListStoredSessions(store)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	var sessions []*orchsession.Session
+	for _, id := range ids {
+		if s, err := orchsession.LoadWithStorage(baseDir, store, id); err == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b *orchsession.Session) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if n > 0 && len(sessions) > n {
+		sessions = sessions[:n]
+	}
+	return sessions, nil
+}
+
+// printTuneReport prints the profile and the suggestions.
+func printTuneReport(r *stats.TuneReport) {
+	fmt.Println()
+	fmt.Printf("%s Tuning Report (%d sessions)\n", cyan("📈"), r.Sessions)
+	fmt.Println()
+
+	fmt.Printf("  %-14s %6s %9s %10s %7s %11s %8s\n", "Schedule", "Runs", "Requests", "Tokens", "Share", "Model time", "Retried")
+	for _, p := range r.Schedules {
+		share := 0.0
+		if r.Tokens > 0 {
+			share = float64(p.Tokens) * 100 / float64(r.Tokens)
+		}
+		fmt.Printf("  %-14s %6d %9d %10d %6.0f%% %11s %7.0f%%\n",
+			p.Schedule, p.Runs, p.Requests, p.Tokens, share, p.ModelTime.Round(time.Second), p.RetryRate()*100)
+	}
+	fmt.Println()
+
+	fmt.Printf("  %s %d (%d failed)\n", color.HiBlackString("Model requests:"), r.Requests, r.FailedRequests)
+	fmt.Printf("  %s %d served from the cache, %d repeated an earlier prompt\n", color.HiBlackString("Prompt cache:"), r.CachedRequests, r.RepeatedPrompt)
+	fmt.Printf("  %s %.0f%% of process runs\n", color.HiBlackString("Retry rate:"), r.RetryRate()*100)
+	if accuracy, ok := r.PlanAccuracy(); ok {
+		fmt.Printf("  %s %.0f%% (%d of %d subtasks addressed, %d off-plan files)\n",
+			color.HiBlackString("Plan accuracy:"), accuracy*100, r.PlanAddressed, r.PlanTasks, r.OffPlan)
+	} else {
+		fmt.Printf("  %s %s\n", color.HiBlackString("Plan accuracy:"), "no approved plans")
+	}
+	fmt.Println()
+
+	if r.Sessions < stats.MinTuneSessions {
+		printWarning(fmt.Sprintf("Suggestions need at least %d sessions; profiled %d.", stats.MinTuneSessions, r.Sessions))
+		return
+	}
+	if len(r.Suggestions) == 0 {
+		printSuccess("Nothing to tune: no schedule dominates, retries are rare, and the cache is used.")
+		return
+	}
+	fmt.Printf("%s Suggestions\n", yellow("💡"))
+	for i, s := range r.Suggestions {
+		fmt.Printf("  %d. %s\n", i+1, s)
+	}
+	fmt.Println()
+}
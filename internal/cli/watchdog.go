@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/consultation"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui"
+)
+
+// watchdogConsultTimeout is how long a human has to answer a watchdog suspension
+// before the orchestrator decides instead.
+const watchdogConsultTimeout = 60
+
+// maxWatchdogAttempts bounds how many times a process suspended by the
+// watchdog runs before it is escalated to the orchestrator's suspension
+// handling, however the recovery decisions come back.
+const maxWatchdogAttempts = 3
+
+// askRecovery asks how to recover from a watchdog suspension; tests replace it.
+var askRecovery = askRecoveryDecision
+
+// processBudget returns the watchdog budget for a process from the unified config.
+func processBudget(schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) agent.Budget {
+	if cfg == nil || cfg.Unified == nil {
+		return agent.DefaultBudget()
+	}
+	pb := cfg.Unified.GetProcessBudget(orchestrate.ProcessNames[schedID][procID])
+	return agent.Budget{
+		MaxDuration:      time.Duration(pb.MaxDurationSeconds) * time.Second,
+		MaxActions:       pb.MaxActions,
+		MaxRepeatedEdits: pb.MaxRepeatedEdits,
+	}
+}
+
+// runWithWatchdog executes a process under its watchdog budget. When the
+// watchdog suspends the process, the state is snapshotted into the session and
+// the human (or, on timeout, the orchestrator) decides whether to retry, skip,
+// or adjust, answering on in. After maxWatchdogAttempts suspensions the
+// process fails with errRecoveryExhausted instead. run receives guidance text
+// to append to the agent prompt.
+func runWithWatchdog(
+	ctx context.Context,
+	orch *orchestrate.Orchestrator,
+	wd *agent.Watchdog,
+	sess *orchsession.Session,
+	in *bufio.Reader,
+	schedID orchestrate.ScheduleID,
+	procID orchestrate.ProcessID,
+	run func(ctx context.Context, guidance string) error,
+) error {
+	budget := processBudget(schedID, procID)
+	wd.SetBudget(budget)
+
+	guidance := ""
+	for attempt := 1; ; attempt++ {
+		procCtx, cancel := ctx, context.CancelFunc(func() {})
+		if budget.MaxDuration > 0 {
+			procCtx, cancel = context.WithTimeout(ctx, budget.MaxDuration)
+		}
+		err := run(procCtx, guidance)
+		deadlineHit := procCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+
+		tripped := wd.Tripped()
+		if tripped == nil && deadlineHit {
+			tripped = wd.Trip(fmt.Sprintf("exceeded max duration of %s", budget.MaxDuration))
+		}
+		if tripped == nil {
+			return err
+		}
+
+		report := tripped.Report()
+		orch.SetState(orchestrate.StateSuspended)
+		snapshotWatchdogState(sess, schedID, procID, tripped)
+
+		if attempt >= maxWatchdogAttempts {
+			recordSessionError(sess, errs.NewProcessError(tripped, "Watchdog", frozenState(orch, nil, schedID, procID)), "escalated")
+			fmt.Printf("%s %s\n", ui.FormatWarning("Watchdog"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("Suspended %d times, escalating", attempt)))
+			return fmt.Errorf("%w after %d watchdog suspensions: %w", errRecoveryExhausted, attempt, tripped)
+		}
+
+		decision := askRecovery(ctx, orch, in, report, attempt)
+		recordSessionError(sess, errs.NewProcessError(tripped, "Watchdog", frozenState(orch, nil, schedID, procID)), decision.String())
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		orch.SetState(orchestrate.StateActive)
+		orch.AddNote(fmt.Sprintf("Watchdog suspension resolved with %s: %s", decision, tripped.Reason), "watchdog")
+
+		switch decision {
+		case orchestrate.RecoverySkip:
+			fmt.Printf("%s %s\n", ui.FormatLabel("Watchdog"), ui.FormatBullet()+ui.FormatValueMuted("Skipping process"))
+			return nil
+		case orchestrate.RecoveryAdjust:
+			guidance = "The previous attempt was suspended by the watchdog.\n" + report +
+				"\nDo not repeat the same actions. Change strategy and finish within budget."
+		default:
+			guidance = ""
+		}
+		fmt.Printf("%s %s\n", ui.FormatLabel("Watchdog"), ui.FormatBullet()+ui.FormatValue(fmt.Sprintf("Re-running process (%s)", decision)))
+	}
+}
+
+// snapshotWatchdogState records the suspended process as a session state and note.
+func snapshotWatchdogState(sess *orchsession.Session, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, tripped *agent.BudgetExceededError) {
+	if sess == nil {
+		return
+	}
+	actions := make([]string, 0, len(tripped.Snapshot.RecentActions))
+	for _, a := range tripped.Snapshot.RecentActions {
+		actions = append(actions, a.ActionOutput())
+	}
+	stateID := sess.AddState(schedID, procID, actions)
+	sess.AddOrchestratorNote(fmt.Sprintf("[%s] %s", stateID, tripped.Report()), "watchdog")
+}
+
+// askRecoveryDecision shows the suspension report and asks how to proceed,
+// reading the answer through the shared stdin reader in. If the human does
+// not answer in time, the orchestrator decides.
+func askRecoveryDecision(ctx context.Context, orch *orchestrate.Orchestrator, in *bufio.Reader, report string, attempt int) orchestrate.RecoveryDecision {
+	fmt.Printf("\n%s %s\n", ui.FormatWarning("Watchdog"), ui.FormatBullet()+ui.FormatValue("Process suspended"))
+	for _, line := range strings.Split(report, "\n") {
+		fmt.Printf("  %s\n", ui.FormatValueMuted(line))
+	}
+
+	handler := consultation.NewHandler(os.Stdin, os.Stdout, &consultation.Config{
+		TimeoutSeconds:   watchdogConsultTimeout,
+		CountdownSeconds: 15,
+		AllowAISub:       false,
+		Notifier:         runNotifier.Load(),
+		OnRequest:        openConsultation,
+		OnWait:           closeConsultation,
+		Buffered:         in,
+	})
+	resp, err := handler.Request(ctx, consultation.Request{
+		Type:     "watchdog",
+		Question: "The watchdog suspended this process. Retry, skip, or adjust the plan?",
+		Context:  report,
+		Options:  []string{"[R]etry", "[S]kip", "[A]djust"},
+	})
+	if err == nil {
+		if decision, ok := orchestrate.ParseRecoveryDecision(resp.Content); ok {
+			return decision
+		}
+	}
+
+	decision := orch.DecideRecovery(ctx, report, attempt)
+	fmt.Printf("%s %s\n", ui.FormatLabel("Orchestrator"), ui.FormatBullet()+ui.FormatValue("Watchdog decision: "+decision.String()))
+	return decision
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/croberts/obot/internal/consultation"
+	errs "github.com/croberts/obot/internal/error"
+	"github.com/croberts/obot/internal/webhook"
+)
+
+// runWebhooks sends the events of an orchestration to the configured
+// webhooks; nil when there are none.
+var runWebhooks atomic.Pointer[webhook.Dispatcher]
+
+// setupWebhooks creates runWebhooks for the webhooks of the config, sending
+// the events of sessionID.
+func setupWebhooks(sessionID string) error {
+	if cfg == nil || cfg.Unified == nil {
+		return nil
+	}
+	var hooks []webhook.Hook
+	for i, w := range cfg.Unified.Webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhooks[%d]: url %q is not an http or https URL", i, w.URL)
+		}
+		hooks = append(hooks, webhook.Hook{URL: w.URL, Secret: configSecret(w.Secret)})
+	}
+	runWebhooks.Store(webhook.New(sessionID, hooks))
+	return nil
+}
+
+// configSecret returns a configured secret, read from the environment when
+// written as ${NAME}.
+func configSecret(secret string) string {
+	if name, ok := strings.CutPrefix(secret, "${"); ok && strings.HasSuffix(name, "}") {
+		return os.Getenv(strings.TrimSuffix(name, "}"))
+	}
+	return secret
+}
+
+// sendConsultationEvent announces a consultation opening to the webhooks.
+func sendConsultationEvent(req consultation.Request) {
+	runWebhooks.Load().Send(webhook.Event{
+		Event:   webhook.EventConsultationRequested,
+		Message: req.Question,
+		Details: map[string]string{"type": string(req.Type)},
+	})
+}
+
+// sendErrorEvent sends an error of the run to the webhooks, with the fields
+// it is recorded in the session with.
+func sendErrorEvent(oe *errs.OrchestrationError, resolution string) {
+	runWebhooks.Load().Send(webhook.Event{
+		Time:     oe.Timestamp,
+		Event:    webhook.EventError,
+		Schedule: oe.State.Schedule,
+		Process:  oe.State.Process,
+		Message:  oe.Message,
+		Details: map[string]string{
+			"code":       string(oe.Code),
+			"impact":     errs.GetImpact(oe.Code).String(),
+			"component":  oe.Component,
+			"resolution": resolution,
+		},
+	})
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/schedule"
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// runWorkspace is the obot.yaml of the workspace being orchestrated, nil
+// when it has none.
+var runWorkspace atomic.Pointer[config.WorkspaceConfig]
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [obot.yaml]",
+	Short: "Validate the unified config and the workspace's obot.yaml",
+	Long: `Validate ~/.config/ollamabot/config.yaml and the workspace's obot.yaml
+against their schemas: unknown settings, values out of range, prompt
+templates, policy rules, and custom schedules.
+
+Without a path, validates the obot.yaml of the current workspace, the
+nearest one in the current directory or a parent up to the repository root.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigValidate,
+}
+
+var configTrustCmd = &cobra.Command{
+	Use:   "trust [obot.yaml | .obot/policy.yaml]",
+	Short: "Trust the workspace's obot.yaml to run commands and write files",
+	Long: `Trust the hooks, strategy script, and output paths of the workspace's
+obot.yaml, as it is now, so that obot orchestrate uses them without asking.
+Given a .obot/policy.yaml, trust its rules that loosen the default policy.
+A change to the file needs trusting again.
+
+Without a path, trusts the obot.yaml of the current workspace.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		} else if wd, err := os.Getwd(); err == nil {
+			path = config.FindWorkspaceConfig(wd)
+		}
+		if path == "" {
+			return fmt.Errorf("no %s in this workspace", config.WorkspaceFile)
+		}
+		if filepath.Base(path) == filepath.Base(agent.PolicyPath("")) {
+			_, hash, err := agent.PolicyLoosening(filepath.Dir(filepath.Dir(path)))
+			if err != nil {
+				return err
+			}
+			if hash == "" {
+				return fmt.Errorf("no policy file at %s", path)
+			}
+			if err := config.RecordTrust(config.TrustedWorkspacesPath(), path, hash); err != nil {
+				return err
+			}
+			printSuccess("Trusted " + path)
+			return nil
+		}
+		ws, err := config.LoadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		if err := ws.Trust(config.TrustedWorkspacesPath()); err != nil {
+			return err
+		}
+		printSuccess("Trusted " + path)
+		return nil
+	},
+}
+
+// trustWorkspace asks whether to use the privileged settings of an
+// obot.yaml the user has not trusted; replaced in tests.
+var trustWorkspace = promptWorkspaceTrust
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configTrustCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	problems := 0
+	report := func(source string, errs []error) {
+		for _, err := range errs {
+			printError(fmt.Sprintf("%s: %v", source, err))
+		}
+		problems += len(errs)
+	}
+
+	unified, err := config.LoadUnifiedConfig()
+	if err != nil {
+		report(config.UnifiedConfigPath(), []error{err})
+		unified = config.DefaultUnifiedConfig()
+	} else if err := config.ValidateUnifiedConfig(unified); err != nil {
+		report(config.UnifiedConfigPath(), []error{err})
+	}
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	} else if wd, err := os.Getwd(); err == nil {
+		path = config.FindWorkspaceConfig(wd)
+	}
+	if path == "" {
+		printInfo("No " + config.WorkspaceFile + " in this workspace.")
+	} else if ws, err := config.LoadWorkspaceConfig(path); err != nil {
+		report(path, []error{err})
+	} else {
+		report(path, validateWorkspace(ws, unified))
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d configuration problems", problems)
+	}
+	printSuccess("Configuration is valid")
+	return nil
+}
+
+// validateWorkspace checks ws against the schema, then builds what it
+// configures the way obot orchestrate does: the custom schedules on top of
+// the user's, and the workspace policy.
+func validateWorkspace(ws *config.WorkspaceConfig, unified *config.UnifiedConfig) []error {
+	errs := ws.Validate(unified)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	ws.Apply(unified)
+	orchestrate.ClearCustomSchedules()
+	if _, err := schedule.RegisterConfiguredSchedules(unified.Orchestration); err != nil {
+		errs = append(errs, fmt.Errorf("schedules: %w", err))
+	}
+	orchestrate.ClearCustomSchedules()
+
+	if _, err := agent.LoadPolicyWithRules(filepath.Dir(ws.Path()), workspacePolicyRules(ws)); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// setupWorkspaceConfig loads the obot.yaml of the workspace dir is in and
+// applies it to the run: it sets the flags not given on the command line
+// and merges its schedules, retries, and budgets into the configuration.
+// Whether to trust its privileged settings is asked on in.
+func setupWorkspaceConfig(cmd *cobra.Command, dir string, in *bufio.Reader) error {
+	path := config.FindWorkspaceConfig(dir)
+	if path == "" {
+		return nil
+	}
+	ws, err := config.LoadWorkspaceConfig(path)
+	if err != nil {
+		return err
+	}
+	var base *config.UnifiedConfig
+	if cfg != nil {
+		base = cfg.Unified
+	}
+	if errs := ws.Validate(base); len(errs) > 0 {
+		if len(errs) > 1 {
+			return fmt.Errorf("%s: %v (and %d more problems; see obot config validate)", path, errs[0], len(errs)-1)
+		}
+		return fmt.Errorf("%s: %v", path, errs[0])
+	}
+
+	// Commands and output paths come from whoever wrote the file, so they
+	// need the user's trust
+	if len(ws.Privileged()) > 0 && !ws.Trusted(config.TrustedWorkspacesPath()) && !trustWorkspace(in, ws) {
+		printWarning(fmt.Sprintf("Ignoring the hooks, strategy script, and output paths of the untrusted %s (see obot config trust)", path))
+		ws.DropPrivileged()
+	}
+
+	for name, value := range workspaceFlagDefaults(ws) {
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	if base != nil {
+		ws.Apply(base)
+	}
+	runWorkspace.Store(ws)
+	return nil
+}
+
+// promptWorkspaceTrust shows the privileged settings of ws and asks on in
+// whether to trust it, recording the answer yes. Without a terminal to ask on, the
+// file is not trusted.
+func promptWorkspaceTrust(in *bufio.Reader, ws *config.WorkspaceConfig) bool {
+	if !term.IsTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Println(ui.FormatWarning(ws.Path() + " runs commands and writes files outside the agent's actions:"))
+	for _, s := range ws.Privileged() {
+		fmt.Println("  " + ui.FormatValue(s))
+	}
+	fmt.Print("Trust this file? [y/N] ")
+	if !confirmed(in) {
+		return false
+	}
+	if err := ws.Trust(config.TrustedWorkspacesPath()); err != nil {
+		printWarning("Failed to record the trust: " + err.Error())
+	}
+	return true
+}
+
+// workspaceFlagDefaults returns the values obot.yaml gives obot
+// orchestrate's flags, by flag name.
+func workspaceFlagDefaults(ws *config.WorkspaceConfig) map[string]string {
+	defaults := make(map[string]string)
+	setString := func(name, value string) {
+		if value != "" {
+			defaults[name] = value
+		}
+	}
+	setBool := func(name string, value bool) {
+		if value {
+			defaults[name] = "true"
+		}
+	}
+
+	l := ws.Limits
+	if l.TokenLimit > 0 {
+		defaults["token-limit"] = strconv.FormatInt(l.TokenLimit, 10)
+	}
+	setString("memory-limit", l.MemoryLimit)
+	setString("timeout", l.Timeout)
+
+	o := ws.Orchestrate
+	setBool("approve-plan", o.ApprovePlan)
+	setString("plan-drift", o.PlanDrift)
+	setString("strategy", o.Strategy)
+	setString("strategy-script", o.StrategyScript)
+	setBool("compile-check", o.CompileCheck)
+	setBool("no-judge", o.NoJudge)
+	if o.QualityGate > 0 {
+		defaults["quality-gate"] = strconv.FormatFloat(o.QualityGate, 'g', -1, 64)
+	}
+	setBool("mutation", o.Mutation)
+	setBool("changelog", o.Changelog)
+	setString("notify", o.Notify)
+	setBool("hooks", o.Hooks)
+	setString("summary-out", o.SummaryOut)
+	setString("sarif-out", o.SARIFOut)
+	setString("junit-out", o.JUnitOut)
+	return defaults
+}
+
+// applyWorkspaceModels gives each role the model obot.yaml overrides it
+// with.
+func applyWorkspaceModels(coord *model.Coordinator) {
+	ws := runWorkspace.Load()
+	if ws == nil {
+		return
+	}
+	for role, name := range ws.Models {
+		coord.SetModel(orchestrate.ModelType(role), name)
+	}
+}
+
+// workspacePolicyRules converts the policy rules of obot.yaml.
+func workspacePolicyRules(ws *config.WorkspaceConfig) []agent.PolicyRule {
+	if ws == nil {
+		return nil
+	}
+	rules := make([]agent.PolicyRule, len(ws.Policy))
+	for i, r := range ws.Policy {
+		rules[i] = agent.PolicyRule{
+			Class:    agent.ActionClass(r.Class),
+			Paths:    r.Paths,
+			Commands: r.Commands,
+			Decision: agent.PolicyDecision(r.Decision),
+			Reason:   r.Reason,
+		}
+	}
+	return rules
+}
+
+// workspaceHooks returns the hook commands of obot.yaml.
+func workspaceHooks() map[string]string {
+	if ws := runWorkspace.Load(); ws != nil {
+		return ws.Hooks
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/croberts/obot/internal/config"
+)
+
+func TestSetupWorkspaceConfig(t *testing.T) {
+	dir := t.TempDir()
+	content := `limits:
+  timeout: 45m
+  max_retries: 1
+models:
+  coder: qwen2.5-coder:14b
+orchestrate:
+  approve_plan: true
+  quality_gate: 85
+  notify: desktop
+`
+	if err := os.WriteFile(filepath.Join(dir, config.WorkspaceFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	savedCfg, savedWorkspace := cfg, runWorkspace.Load()
+	t.Cleanup(func() { cfg = savedCfg; runWorkspace.Store(savedWorkspace) })
+	cfg = config.Default()
+
+	var approvePlan bool
+	var qualityGate float64
+	var timeout, notify string
+	cmd := &cobra.Command{Use: "orchestrate"}
+	cmd.Flags().BoolVar(&approvePlan, "approve-plan", false, "")
+	cmd.Flags().Float64Var(&qualityGate, "quality-gate", 0, "")
+	cmd.Flags().StringVar(&timeout, "timeout", "", "")
+	cmd.Flags().StringVar(&notify, "notify", "", "")
+	if err := cmd.ParseFlags([]string{"--notify", "bell"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setupWorkspaceConfig(cmd, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !approvePlan || qualityGate != 85 || timeout != "45m" {
+		t.Errorf("flags = approve-plan %v, quality-gate %v, timeout %q; want obot.yaml's", approvePlan, qualityGate, timeout)
+	}
+	if notify != "bell" {
+		t.Errorf("notify = %q, want the command line's bell over obot.yaml", notify)
+	}
+	if got := cfg.Unified.GetMaxRetries("implement"); got != 1 {
+		t.Errorf("max retries = %d, want obot.yaml's 1", got)
+	}
+	if ws := runWorkspace.Load(); ws == nil || ws.Models["coder"] != "qwen2.5-coder:14b" {
+		t.Errorf("runWorkspace = %+v", ws)
+	}
+
+	// The commands and output paths of a file the user does not trust are
+	// ignored
+	savedTrust := trustWorkspace
+	t.Cleanup(func() { trustWorkspace = savedTrust })
+	content = "hooks:\n  pre_process: make db-snapshot\norchestrate:\n  sarif_out: out.sarif\n"
+	if err := os.WriteFile(filepath.Join(dir, config.WorkspaceFile), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sarifOut string
+	cmd.Flags().StringVar(&sarifOut, "sarif-out", "", "")
+	for _, trusted := range []bool{false, true} {
+		trustWorkspace = func(*bufio.Reader, *config.WorkspaceConfig) bool { return trusted }
+		sarifOut = ""
+		if err := setupWorkspaceConfig(cmd, dir, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(workspaceHooks()) > 0 && sarifOut == "out.sarif"; got != trusted {
+			t.Errorf("trusted %v: hooks %v, sarif-out %q", trusted, workspaceHooks(), sarifOut)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, config.WorkspaceFile), []byte("orchestrate:\n  plan_drift: sometimes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := setupWorkspaceConfig(cmd, dir, nil); err == nil || !strings.Contains(err.Error(), "plan_drift") {
+		t.Errorf("invalid obot.yaml: %v, want the problem", err)
+	}
+}
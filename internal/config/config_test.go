@@ -56,3 +56,69 @@ func TestUnifiedConfigDir(t *testing.T) {
 		t.Errorf("UnifiedConfigDir should end with .config/ollamabot, got %q", dir)
 	}
 }
+
+func TestUnifiedConfig_GetProcessBudget(t *testing.T) {
+	cfg := DefaultUnifiedConfig()
+	cfg.Orchestration.Budget.Processes = map[string]ProcessBudget{
+		"implement": {MaxActions: 120},
+	}
+
+	b := cfg.GetProcessBudget("Implement")
+	if b.MaxActions != 120 {
+		t.Errorf("MaxActions = %d, want override 120", b.MaxActions)
+	}
+	if b.MaxDurationSeconds != cfg.Orchestration.Budget.MaxDurationSeconds {
+		t.Errorf("MaxDurationSeconds = %d, want default %d", b.MaxDurationSeconds, cfg.Orchestration.Budget.MaxDurationSeconds)
+	}
+	if d := cfg.GetProcessBudget("Verify"); d != cfg.Orchestration.Budget.ProcessBudget {
+		t.Errorf("GetProcessBudget(Verify) = %+v, want defaults", d)
+	}
+}
+
+func TestUnifiedConfig_GetMaxRetries(t *testing.T) {
+	cfg := DefaultUnifiedConfig()
+	cfg.Orchestration.Retry.Schedules = map[string]int{"production": 0}
+
+	if n := cfg.GetMaxRetries("Production"); n != 0 {
+		t.Errorf("GetMaxRetries(Production) = %d, want override 0", n)
+	}
+	if n := cfg.GetMaxRetries("Implement"); n != cfg.Orchestration.Retry.MaxRetries {
+		t.Errorf("GetMaxRetries(Implement) = %d, want default %d", n, cfg.Orchestration.Retry.MaxRetries)
+	}
+}
+
+func TestConsultationTimeouts_Timeout(t *testing.T) {
+	cfg := DefaultUnifiedConfig().Orchestration.Consultation
+	if got := cfg.Clarify.Timeout(true); got != 60 {
+		t.Errorf("Clarify.Timeout(interactive) = %d, want 60", got)
+	}
+	if got := cfg.Feedback.Timeout(false); got != 300 {
+		t.Errorf("Feedback.Timeout() = %d, want 300", got)
+	}
+	if got := cfg.Feedback.Timeout(true); got != 0 {
+		t.Errorf("Feedback.Timeout(interactive) = %d, want 0 (no limit)", got)
+	}
+}
+
+func TestModelRoleConfig_SmallerModel(t *testing.T) {
+	rc := ModelRoleConfig{TierMapping: map[string]string{
+		"minimal":     "qwen2.5-coder:1.5b",
+		"compact":     "qwen2.5-coder:7b",
+		"balanced":    "qwen2.5-coder:7b",
+		"performance": "qwen2.5-coder:14b",
+	}}
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"qwen2.5-coder:14b", "qwen2.5-coder:7b"},
+		{"qwen2.5-coder:7b", "qwen2.5-coder:1.5b"},
+		{"qwen2.5-coder:1.5b", ""},
+		{"llama3:8b", ""},
+	}
+	for _, tt := range tests {
+		if got := rc.SmallerModel(tt.model); got != tt.want {
+			t.Errorf("SmallerModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// TrustedWorkspacesPath returns where the workspace files the user trusts
+// to run commands, such as obot.yaml and hook scripts, are recorded.
+func TrustedWorkspacesPath() string {
+	return filepath.Join(UnifiedConfigDir(), "trusted_workspaces.json")
+}
+
+// IsTrusted reports whether the user trusts path with the contents hashing
+// to hash, as recorded in store.
+func IsTrusted(store, path, hash string) bool {
+	trusted, err := loadTrusted(store)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	return err == nil && hash != "" && trusted[abs] == hash
+}
+
+// RecordTrust records in store that the user trusts path with the contents
+// hashing to hash, replacing the hash trusted before.
+func RecordTrust(store, path, hash string) error {
+	trusted, err := loadTrusted(store)
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	trusted[abs] = hash
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(store, data, 0600)
+}
+
+// loadTrusted reads the hashes of the trusted files, by absolute path.
+func loadTrusted(store string) (map[string]string, error) {
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(store)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return trusted, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", store, err)
+	}
+	return trusted, nil
+}
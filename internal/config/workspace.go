@@ -0,0 +1,442 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile is the name of a workspace's run configuration, kept at the
+// root of the workspace and shared by the team through version control.
+const WorkspaceFile = "obot.yaml"
+
+// WorkspaceConfigVersion is the version of the obot.yaml schema.
+const WorkspaceConfigVersion = "1"
+
+// WorkspaceConfig is a workspace's standard run configuration: what
+// obot orchestrate uses when it is run there without flags. Flags given on
+// the command line take precedence over it.
+type WorkspaceConfig struct {
+	Version string `yaml:"version,omitempty"`
+
+	// Prompts are templates of the prompt each process is given, by
+	// lowercase process name, or "default" for processes without one of
+	// their own. They are Go templates of PromptData.
+	Prompts map[string]string `yaml:"prompts,omitempty"`
+
+	// Limits bound the run and its processes.
+	Limits WorkspaceLimits `yaml:"limits,omitempty"`
+
+	// Models overrides the model of each role (orchestrator, coder,
+	// researcher, vision).
+	Models map[string]string `yaml:"models,omitempty"`
+
+	// Policy adds rules to the workspace policy, in the form of the rules
+	// of .obot/policy.yaml, which they follow. They can only tighten it:
+	// their decision is confirm or block, and the default rules still
+	// apply.
+	Policy []PolicyRuleConfig `yaml:"policy,omitempty"`
+
+	// Hooks are shell commands run at the points of the hook scripts in
+	// .obot/hooks (pre_process, post_process, post_schedule), by hook
+	// name. A script of the same name takes precedence. Like the strategy
+	// script and output paths, they are only used once the user trusts
+	// the file.
+	Hooks map[string]string `yaml:"hooks,omitempty"`
+
+	// Schedules adds custom schedules, or replaces those of the same ID
+	// in the user's configuration.
+	Schedules []ScheduleConfig `yaml:"schedules,omitempty"`
+
+	// Orchestrate sets the defaults of obot orchestrate's flags.
+	Orchestrate WorkspaceRunConfig `yaml:"orchestrate,omitempty"`
+
+	path string
+	hash string // SHA-256 of the file's contents
+}
+
+// WorkspaceLimits bounds a run and its processes.
+type WorkspaceLimits struct {
+	TokenLimit  int64  `yaml:"token_limit,omitempty"`
+	MemoryLimit string `yaml:"memory_limit,omitempty"` // such as 8GB
+	Timeout     string `yaml:"timeout,omitempty"`      // such as 30m or 2h
+
+	// MaxRetries replaces the automatic retries of a failed process;
+	// Retries sets them for individual schedules by lowercase name.
+	MaxRetries *int           `yaml:"max_retries,omitempty"`
+	Retries    map[string]int `yaml:"retries,omitempty"`
+
+	// Budget overrides the non-zero limits of the process budgets.
+	Budget BudgetConfig `yaml:"budget,omitempty"`
+}
+
+// PolicyRuleConfig is a workspace policy rule: a decision (confirm or
+// block) for actions of a class, or whose paths match one of Paths or
+// whose command matches one of Commands.
+type PolicyRuleConfig struct {
+	Class    string   `yaml:"class,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
+	Commands []string `yaml:"commands,omitempty"`
+	Decision string   `yaml:"decision"`
+	Reason   string   `yaml:"reason,omitempty"`
+}
+
+// WorkspaceRunConfig holds the defaults of obot orchestrate's flags of the
+// same names.
+type WorkspaceRunConfig struct {
+	ApprovePlan    bool    `yaml:"approve_plan,omitempty"`
+	PlanDrift      string  `yaml:"plan_drift,omitempty"`
+	Strategy       string  `yaml:"strategy,omitempty"`
+	StrategyScript string  `yaml:"strategy_script,omitempty"`
+	CompileCheck   bool    `yaml:"compile_check,omitempty"`
+	NoJudge        bool    `yaml:"no_judge,omitempty"`
+	QualityGate    float64 `yaml:"quality_gate,omitempty"`
+	Mutation       bool    `yaml:"mutation,omitempty"`
+	Changelog      bool    `yaml:"changelog,omitempty"`
+	Notify         string  `yaml:"notify,omitempty"`
+	Hooks          bool    `yaml:"hooks,omitempty"`
+	SummaryOut     string  `yaml:"summary_out,omitempty"`
+	SARIFOut       string  `yaml:"sarif_out,omitempty"`
+	JUnitOut       string  `yaml:"junit_out,omitempty"`
+}
+
+// PromptData is what a prompt template is rendered with.
+type PromptData struct {
+	Prompt   string // the prompt being orchestrated
+	Schedule string // schedule name, such as Implement
+	Process  string // process name, such as Verify
+}
+
+// Values the schema accepts
+var (
+	workspaceRoles     = []string{"orchestrator", "coder", "researcher", "vision"}
+	workspaceHooks     = []string{"pre_process", "post_process", "post_schedule"}
+	workspaceDecisions = []string{"confirm", "block"}
+	workspaceClasses   = []string{
+		"delete_file", "delete_dir", "network_command", "destructive_command",
+		"ci_files", "vendored_files", "generated_files", "third_party_files",
+	}
+	workspacePlanDrifts = []string{"off", "warn", "revisit"}
+	workspaceStrategies = []string{"heuristic", "llm", "hybrid", "script"}
+	workspaceNotifiers  = []string{"off", "bell", "desktop", "all"}
+)
+
+// FindWorkspaceConfig returns the obot.yaml of the workspace dir is in:
+// the nearest one in dir or a parent, up to the root of the repository. It
+// returns "" when there is none.
+func FindWorkspaceConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, WorkspaceFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspaceConfig reads a workspace's obot.yaml. Fields the schema does
+// not know are errors, so that a misspelled setting is not silently
+// ignored.
+func LoadWorkspaceConfig(path string) (*WorkspaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	w := &WorkspaceConfig{path: path, hash: hex.EncodeToString(sum[:])}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(w); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Path returns the file the configuration was read from.
+func (w *WorkspaceConfig) Path() string {
+	return w.path
+}
+
+// Privileged returns the settings that run commands or write files outside
+// the agent's actions: hooks, enabling them, the strategy script, and
+// output paths. A
+// freshly cloned repository could use them against the user, so they are
+// only used once the user trusts the file.
+func (w *WorkspaceConfig) Privileged() []string {
+	var settings []string
+	for _, name := range sortedKeys(w.Hooks) {
+		settings = append(settings, fmt.Sprintf("hooks.%s: %s", name, w.Hooks[name]))
+	}
+	o := w.Orchestrate
+	if o.Hooks {
+		settings = append(settings, "orchestrate.hooks: true (runs the scripts in .obot/hooks)")
+	}
+	for _, s := range []struct{ name, value string }{
+		{"orchestrate.strategy_script", o.StrategyScript},
+		{"orchestrate.summary_out", o.SummaryOut},
+		{"orchestrate.sarif_out", o.SARIFOut},
+		{"orchestrate.junit_out", o.JUnitOut},
+	} {
+		if s.value != "" {
+			settings = append(settings, s.name+": "+s.value)
+		}
+	}
+	return settings
+}
+
+// DropPrivileged removes the settings Privileged returns, for a file the
+// user does not trust.
+func (w *WorkspaceConfig) DropPrivileged() {
+	w.Hooks = nil
+	o := &w.Orchestrate
+	if o.Strategy == "script" {
+		o.Strategy = ""
+	}
+	o.Hooks = false
+	o.StrategyScript, o.SummaryOut, o.SARIFOut, o.JUnitOut = "", "", "", ""
+}
+
+// Trusted reports whether the user trusts the file as it is now: a change
+// to it after it was trusted needs trusting again.
+func (w *WorkspaceConfig) Trusted(store string) bool {
+	return IsTrusted(store, w.path, w.hash)
+}
+
+// Trust records that the user trusts the file as it is now.
+func (w *WorkspaceConfig) Trust(store string) error {
+	return RecordTrust(store, w.path, w.hash)
+}
+
+// Validate checks the configuration against the schema and returns every
+// problem found. Prompt templates are checked against the processes of
+// base's schedules and the workspace's own; base may be nil.
+func (w *WorkspaceConfig) Validate(base *UnifiedConfig) []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if w.Version != "" && w.Version != WorkspaceConfigVersion {
+		add("version: unsupported version %q (use %q)", w.Version, WorkspaceConfigVersion)
+	}
+
+	if base == nil {
+		base = DefaultUnifiedConfig()
+	}
+	processes := []string{"default"}
+	for _, sc := range append(slices.Clone(base.Orchestration.Schedules), w.Schedules...) {
+		for _, p := range sc.Processes {
+			processes = append(processes, strings.ToLower(p))
+		}
+	}
+	for _, name := range sortedKeys(w.Prompts) {
+		if !slices.Contains(processes, name) {
+			add("prompts.%s: unknown process (use a lowercase process name or default)", name)
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(w.Prompts[name])
+		if err == nil {
+			err = tmpl.Execute(io.Discard, PromptData{})
+		}
+		if err != nil {
+			add("prompts.%s: %v", name, err)
+		}
+	}
+
+	l := w.Limits
+	if l.TokenLimit < 0 {
+		add("limits.token_limit: must not be negative")
+	}
+	if l.Timeout != "" {
+		if d, err := time.ParseDuration(l.Timeout); err != nil || d <= 0 {
+			add("limits.timeout: %q is not a positive duration such as 30m or 2h", l.Timeout)
+		}
+	}
+	if l.MaxRetries != nil && *l.MaxRetries < 0 {
+		add("limits.max_retries: must not be negative")
+	}
+	for _, name := range sortedKeys(l.Retries) {
+		if l.Retries[name] < 0 {
+			add("limits.retries.%s: must not be negative", name)
+		}
+	}
+	if l.Budget.MaxDurationSeconds < 0 || l.Budget.MaxActions < 0 || l.Budget.MaxRepeatedEdits < 0 || l.Budget.MaxRepeatedFailures < 0 {
+		add("limits.budget: limits must not be negative")
+	}
+
+	for _, role := range sortedKeys(w.Models) {
+		if !slices.Contains(workspaceRoles, role) {
+			add("models.%s: unknown role (use %s)", role, strings.Join(workspaceRoles, ", "))
+		} else if strings.TrimSpace(w.Models[role]) == "" {
+			add("models.%s: model is required", role)
+		}
+	}
+
+	for i, r := range w.Policy {
+		if r.Decision == "allow" {
+			add("policy[%d]: obot.yaml can only tighten the policy (use confirm or block; allow actions in .obot/policy.yaml)", i)
+		} else if !slices.Contains(workspaceDecisions, r.Decision) {
+			add("policy[%d]: invalid decision %q (use confirm or block)", i, r.Decision)
+		}
+		if r.Class != "" && !slices.Contains(workspaceClasses, r.Class) {
+			add("policy[%d]: unknown class %q (use %s)", i, r.Class, strings.Join(workspaceClasses, ", "))
+		}
+		if r.Class == "" && len(r.Paths) == 0 && len(r.Commands) == 0 {
+			add("policy[%d]: a rule needs a class, paths, or commands", i)
+		}
+		for _, pattern := range r.Commands {
+			if _, err := regexp.Compile(pattern); err != nil {
+				add("policy[%d]: invalid command pattern %q: %v", i, pattern, err)
+			}
+		}
+		for _, pattern := range r.Paths {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				add("policy[%d]: invalid path pattern %q", i, pattern)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(w.Hooks) {
+		if !slices.Contains(workspaceHooks, name) {
+			add("hooks.%s: unknown hook (use %s)", name, strings.Join(workspaceHooks, ", "))
+		} else if strings.TrimSpace(w.Hooks[name]) == "" {
+			add("hooks.%s: command is required", name)
+		}
+	}
+
+	for i, sc := range w.Schedules {
+		if strings.TrimSpace(sc.ID) == "" {
+			add("schedules[%d]: id is required", i)
+		}
+		if len(sc.Processes) != 3 {
+			add("schedules[%d]: a schedule has exactly 3 processes, got %d", i, len(sc.Processes))
+		}
+	}
+
+	o := w.Orchestrate
+	if o.PlanDrift != "" && !slices.Contains(workspacePlanDrifts, o.PlanDrift) {
+		add("orchestrate.plan_drift: %q is not one of %s", o.PlanDrift, strings.Join(workspacePlanDrifts, ", "))
+	}
+	if o.Strategy != "" && !slices.Contains(workspaceStrategies, o.Strategy) {
+		add("orchestrate.strategy: %q is not one of %s", o.Strategy, strings.Join(workspaceStrategies, ", "))
+	}
+	if o.Strategy == "script" && o.StrategyScript == "" {
+		add("orchestrate.strategy_script: required by the script strategy")
+	}
+	if o.Notify != "" && !slices.Contains(workspaceNotifiers, o.Notify) {
+		add("orchestrate.notify: %q is not one of %s", o.Notify, strings.Join(workspaceNotifiers, ", "))
+	}
+	if o.QualityGate < 0 || o.QualityGate > 100 {
+		add("orchestrate.quality_gate: must be between 0 and 100")
+	}
+	return errs
+}
+
+// Apply merges the workspace's schedules, retries, and process budgets into
+// the user's configuration.
+func (w *WorkspaceConfig) Apply(u *UnifiedConfig) {
+	for _, sc := range w.Schedules {
+		i := slices.IndexFunc(u.Orchestration.Schedules, func(s ScheduleConfig) bool { return strings.EqualFold(s.ID, sc.ID) })
+		if i >= 0 {
+			u.Orchestration.Schedules[i] = sc
+		} else {
+			u.Orchestration.Schedules = append(u.Orchestration.Schedules, sc)
+		}
+	}
+
+	l := w.Limits
+	if l.MaxRetries != nil {
+		u.Orchestration.Retry.MaxRetries = *l.MaxRetries
+	}
+	if len(l.Retries) > 0 && u.Orchestration.Retry.Schedules == nil {
+		u.Orchestration.Retry.Schedules = make(map[string]int)
+	}
+	for name, n := range l.Retries {
+		u.Orchestration.Retry.Schedules[strings.ToLower(name)] = n
+	}
+
+	budget := &u.Orchestration.Budget
+	budget.ProcessBudget = overrideBudget(budget.ProcessBudget, l.Budget.ProcessBudget)
+	if len(l.Budget.Processes) > 0 && budget.Processes == nil {
+		budget.Processes = make(map[string]ProcessBudget)
+	}
+	for name, b := range l.Budget.Processes {
+		name = strings.ToLower(name)
+		budget.Processes[name] = overrideBudget(budget.Processes[name], b)
+	}
+}
+
+// overrideBudget returns budget with the non-zero limits of override.
+func overrideBudget(budget, override ProcessBudget) ProcessBudget {
+	if override.MaxDurationSeconds != 0 {
+		budget.MaxDurationSeconds = override.MaxDurationSeconds
+	}
+	if override.MaxActions != 0 {
+		budget.MaxActions = override.MaxActions
+	}
+	if override.MaxRepeatedEdits != 0 {
+		budget.MaxRepeatedEdits = override.MaxRepeatedEdits
+	}
+	if override.MaxRepeatedFailures != 0 {
+		budget.MaxRepeatedFailures = override.MaxRepeatedFailures
+	}
+	return budget
+}
+
+// RenderPrompt returns the prompt of a process: the workspace's template
+// for it, or its default template, rendered with data. Without a template
+// it is data.Prompt.
+func (w *WorkspaceConfig) RenderPrompt(data PromptData) (string, error) {
+	if w == nil {
+		return data.Prompt, nil
+	}
+	name := strings.ToLower(data.Process)
+	text, ok := w.Prompts[name]
+	if !ok {
+		name = "default"
+		if text, ok = w.Prompts[name]; !ok {
+			return data.Prompt, nil
+		}
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// sortedKeys returns the keys of m in order, for stable messages.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
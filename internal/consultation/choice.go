@@ -0,0 +1,122 @@
+package consultation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxChoiceAttempts is how many answers that match no option a Clarify
+// consultation takes before it gives up on the human.
+const maxChoiceAttempts = 3
+
+// Choice is an answer to a consultation with options: the option picked,
+// by key, and the rationale given with it.
+type Choice struct {
+	Key       string `json:"key"` // A, B, C, ...
+	Option    string `json:"option"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// String formats the choice as "B) option: rationale".
+func (c Choice) String() string {
+	s := c.Key + ") " + c.Option
+	if c.Rationale != "" {
+		s += ": " + c.Rationale
+	}
+	return s
+}
+
+// optionMention finds an option named in prose, as in "I pick option B".
+var optionMention = regexp.MustCompile(`(?i)\boption\s+([a-z])\b`)
+
+// ParseChoice maps an answer to one of options. The answer starts with the
+// option's key ("B", "b)", "(B)", "Option B: ...") or text, and the rest
+// is the rationale; an answer that names "option B" anywhere, as models
+// tend to, maps to it too. A key must be followed by punctuation or
+// nothing, so an answer like "A different approach" is not taken for A.
+func ParseChoice(answer string, options []string) (Choice, bool) {
+	answer = strings.TrimSpace(answer)
+	if len(options) == 0 || answer == "" {
+		return Choice{}, false
+	}
+
+	rest := answer
+	named := false
+	for _, word := range []string{"option ", "choice "} {
+		if len(rest) > len(word) && strings.EqualFold(rest[:len(word)], word) {
+			rest = strings.TrimSpace(rest[len(word):])
+			named = true
+		}
+	}
+	rest = strings.TrimPrefix(rest, "(")
+	if key, ok := leadingKey(rest, len(options), named); ok {
+		return newChoice(key, options, rest[1:]), true
+	}
+
+	for i, opt := range options {
+		if opt != "" && len(answer) >= len(opt) && strings.EqualFold(answer[:len(opt)], opt) {
+			return newChoice(i, options, answer[len(opt):]), true
+		}
+	}
+
+	if m := optionMention.FindStringSubmatch(answer); m != nil {
+		if i := int(unicode.ToUpper(rune(m[1][0])) - 'A'); i < len(options) {
+			return Choice{Key: optionKey(i), Option: options[i], Rationale: answer}, true
+		}
+	}
+	return Choice{}, false
+}
+
+// leadingKey returns the index of the option whose key s starts with. The
+// key must be followed by punctuation or nothing, or, when named says the
+// answer began with "option" or "choice", by a space: "A different
+// approach" starts with a word, not a key.
+func leadingKey(s string, n int, named bool) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	i := int(unicode.ToUpper(rune(s[0])) - 'A')
+	if i < 0 || i >= n {
+		return 0, false
+	}
+	if len(s) == 1 {
+		return i, true
+	}
+	next := rune(s[1])
+	switch {
+	case strings.ContainsRune(")].:,-;", next):
+		return i, true
+	case next == ' ' && named:
+		return i, true
+	}
+	return 0, false
+}
+
+// newChoice returns the choice of options[i] with rest of the answer, less
+// its separators, as the rationale.
+func newChoice(i int, options []string, rest string) Choice {
+	return Choice{
+		Key:       optionKey(i),
+		Option:    options[i],
+		Rationale: strings.TrimSpace(strings.TrimLeft(rest, " )].:,-;—")),
+	}
+}
+
+// optionKey returns the key of the i-th option: A, B, C, ...
+func optionKey(i int) string {
+	return string(rune('A' + i))
+}
+
+// invalidChoiceNotice tells the human their answer matched no option.
+func invalidChoiceNotice(answer string, options []string) string {
+	keys := optionKey(0)
+	if len(options) > 1 {
+		keys += "-" + optionKey(len(options)-1)
+	}
+	if strings.TrimSpace(answer) == "" {
+		return fmt.Sprintf("Choose an option: answer %s, optionally followed by your reasoning", keys)
+	}
+	return fmt.Sprintf("%q is not one of the options: answer %s, optionally followed by your reasoning", answer, keys)
+}
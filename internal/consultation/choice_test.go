@@ -0,0 +1,39 @@
+package consultation
+
+import "testing"
+
+func TestParseChoice(t *testing.T) {
+	options := []string{"Key the cache by user", "Key the cache by session", "No cache"}
+	tests := []struct {
+		answer string
+		want   Choice
+		ok     bool
+	}{
+		{"B", Choice{Key: "B", Option: options[1]}, true},
+		{"b) sessions expire anyway", Choice{Key: "B", Option: options[1], Rationale: "sessions expire anyway"}, true},
+		{"(A) - per user is simpler", Choice{Key: "A", Option: options[0], Rationale: "per user is simpler"}, true},
+		{"Option C: keep it simple", Choice{Key: "C", Option: options[2], Rationale: "keep it simple"}, true},
+		{"C, because it is simplest", Choice{Key: "C", Option: options[2], Rationale: "because it is simplest"}, true},
+		{"option C because it is simplest", Choice{Key: "C", Option: options[2], Rationale: "because it is simplest"}, true},
+		{"no cache, we can add one later", Choice{Key: "C", Option: options[2], Rationale: "we can add one later"}, true},
+		{"I would go with option a here.", Choice{Key: "A", Option: options[0], Rationale: "I would go with option a here."}, true},
+		{"a different approach entirely", Choice{}, false},
+		{"A different approach entirely", Choice{}, false},
+		{"B is fine", Choice{}, false},
+		{"D", Choice{}, false},
+		{"", Choice{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseChoice(tt.answer, options)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseChoice(%q) = %+v, %v; want %+v, %v", tt.answer, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestChoice_String(t *testing.T) {
+	c := Choice{Key: "B", Option: "By session", Rationale: "sessions expire"}
+	if got := c.String(); got != "B) By session: sessions expire" {
+		t.Errorf("String() = %q", got)
+	}
+}
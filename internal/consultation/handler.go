@@ -2,15 +2,20 @@
 package consultation
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/croberts/obot/internal/notify"
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/layout"
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 // ResponseSource indicates who provided the consultation response.
@@ -28,26 +33,72 @@ type Handler struct {
 	// I/O
 	reader io.Reader
 	writer io.Writer
+	in     *bufio.Reader // buffers reader across requests
 
 	// AI Model
 	aiModel *ollama.Client
 
+	// Notifier alerts the user when a consultation opens
+	notifier *notify.Notifier
+
+	// History of the human's answers, offered again for like questions
+	history *History
+
 	// Configuration
 	timeoutSeconds   int
 	countdownSeconds int
 	allowAISub       bool
+	persona          Persona
+	policy           string
 
 	// Callbacks
 	onTimeout    func()
 	onResponse   func(string, ResponseSource) // response, source
+	onAnswer     func(Request, *Response)
+	onRequest    func(Request)
+	onWait       func(time.Duration)
+	onTokens     func(int64)
+	input        func(context.Context, Request) (string, error)
 }
 
 // Config contains consultation configuration
 type Config struct {
-	TimeoutSeconds   int
+	TimeoutSeconds   int // 0 waits for the human without a limit
 	CountdownSeconds int
 	AllowAISub       bool
 	AIModel          *ollama.Client
+	Notifier         *notify.Notifier // alerts the user when a consultation opens; nil for none
+	History          *History         // earlier answers offered as defaults; nil for none
+
+	// Persona sets how the AI substitute answers, permissive when empty.
+	// Policy adds rules of its own for this kind of question.
+	Persona Persona
+	Policy  string
+
+	// OnAnswer is called with every answer, the human's or the AI
+	// substitute's, such as to record it in the session
+	OnAnswer func(Request, *Response)
+
+	// OnRequest is called as each consultation opens, such as to announce
+	// it to webhooks
+	OnRequest func(Request)
+
+	// OnWait is called with how long each consultation held up the run,
+	// such as to account for the time spent waiting on the human
+	OnWait func(time.Duration)
+
+	// OnTokens is called with the tokens each AI substitute answer used,
+	// such as to charge them to the model's quota
+	OnTokens func(tokens int64)
+
+	// Input, when set, supplies the human's answers in place of the
+	// reader, such as from a remote client. It must return once ctx is done.
+	Input func(ctx context.Context, req Request) (string, error)
+
+	// Buffered, when set, already buffers the reader for the rest of the
+	// program. The handler reads through it rather than buffering the reader
+	// again, so input buffered there is neither lost nor read twice.
+	Buffered *bufio.Reader
 }
 
 // DefaultConfig returns the default consultation configuration
@@ -66,13 +117,27 @@ func NewHandler(reader io.Reader, writer io.Writer, config *Config) *Handler {
 		config = DefaultConfig()
 	}
 
+	in := config.Buffered
+	if in == nil {
+		in = bufio.NewReader(reader)
+	}
 	return &Handler{
 		reader:           reader,
+		in:               in,
 		writer:           writer,
 		aiModel:          config.AIModel,
+		notifier:         config.Notifier,
+		history:          config.History,
+		onAnswer:         config.OnAnswer,
+		onRequest:        config.OnRequest,
+		onWait:           config.OnWait,
+		onTokens:         config.OnTokens,
+		input:            config.Input,
 		timeoutSeconds:   config.TimeoutSeconds,
 		countdownSeconds: config.CountdownSeconds,
 		allowAISub:       config.AllowAISub,
+		persona:          config.Persona,
+		policy:           config.Policy,
 	}
 }
 
@@ -98,6 +163,12 @@ type Request struct {
 	Question  string
 	Context   string
 	Options   []string // For Clarify: A, B, C, D
+	Default   string   // answer an empty response gives; the answer to a like question from History when empty, except for Feedback
+
+	// Risks says why the changes up for Feedback are high risk, such as
+	// high-risk planned subtasks or security findings. The AI substitute
+	// never approves them: the run waits for the human however long it takes.
+	Risks []string
 }
 
 // Response represents a consultation response
@@ -105,141 +176,248 @@ type Response struct {
 	Content      string
 	Source       ResponseSource
 	Timestamp    time.Time
+	Choice       *Choice // option picked, for a Clarify with options
+	Persona      string  // the AI substitute's persona, for its answers
 }
 
-// Request displays a consultation request and waits for response
+// Request displays a consultation request and waits for response. The
+// timeout stops while the user writes the response in their editor.
 func (h *Handler) Request(ctx context.Context, req Request) (*Response, error) {
+	// Offer the answer given last time to a question like this one. An
+	// approval is of the changes at hand, never of earlier ones, so Feedback
+	// offers none: pressing Enter must not approve a new set of changes
+	if req.Default == "" && req.Type != ConsultationFeedback {
+		req.Default = defaultAnswer(req, h.history.Similar(ctx, req))
+	}
+
+	if h.onWait != nil {
+		defer func(start time.Time) { h.onWait(time.Since(start)) }(time.Now())
+	}
+
+	// High-risk changes wait for the human without a time limit
+	timeoutSeconds := h.timeoutSeconds
+	if h.refusesSubstitute(req) {
+		timeoutSeconds = 0
+	}
+
 	// Display consultation UI
-	h.displayConsultation(req)
+	h.displayConsultation(req, timeoutSeconds)
+	h.notifier.Notify(ctx, "obot: consultation requested", req.Question)
+	if h.onRequest != nil {
+		h.onRequest(req)
+	}
+
+	// The timer and the response field share the region below the box
+	v := newView(h.writer)
+	if req.Default != "" {
+		v.hint = "Press Enter to answer as shown above"
+	}
+	defer func() { v.close() }()
 
 	// Create response channel
 	responseCh := make(chan string, 1)
 	errorCh := make(chan error, 1)
-
-	// Start input reader
-	go func() {
-		resp, err := h.readInput()
+	editingCh := make(chan struct{})
+	editing := sync.OnceFunc(func() { close(editingCh) })
+
+	// Start input reader; it stops when the request returns. An answer
+	// matching none of a Clarify's options starts it again.
+	inputCtx, stopInput := context.WithCancel(ctx)
+	defer stopInput()
+	read := func() {
+		resp, err := h.readInput(inputCtx, req, v, editing)
 		if err != nil {
 			errorCh <- err
 			return
 		}
 		responseCh <- resp
-	}()
-	
-	// Start countdown
-	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(h.timeoutSeconds)*time.Second)
-	defer cancel()
+	}
+	go read()
 
-	// Start countdown display goroutine
+	// Start countdown, unless the human has all the time they need
 	countdownCh := make(chan struct{})
-	go h.runCountdown(timeoutCtx, countdownCh)
+	stopCountdown := sync.OnceFunc(func() { close(countdownCh) })
+	var timeoutCh <-chan time.Time
+	if timeoutSeconds > 0 {
+		timeout := time.NewTimer(time.Duration(timeoutSeconds) * time.Second)
+		defer timeout.Stop()
+		timeoutCh = timeout.C
+
+		v.setCountdown(timeoutSeconds, timeoutSeconds <= h.countdownSeconds, h.allowAISub)
+		go h.runCountdown(ctx, countdownCh, v)
+	} else if h.refusesSubstitute(req) {
+		v.setStatus(ui.ANSIYellow + "High-risk changes" + ui.TextMuted + " · the run waits for your answer" + ui.Reset)
+	} else {
+		v.setStatus(ui.TextSecondary + "No time limit" + ui.TextMuted + " · respond when ready" + ui.Reset)
+	}
 
 	// Wait for response or timeout
-	select {
-	case response := <-responseCh:
-		close(countdownCh)
-		if h.onResponse != nil {
-			h.onResponse(response, ResponseSourceHuman)
-		}
-		return &Response{
-			Content:   response,
-			Source:    ResponseSourceHuman,
-			Timestamp: time.Now(),
-		}, nil
-
-	case err := <-errorCh:
-		close(countdownCh)
-		return nil, err
-
-	case <-timeoutCtx.Done():
-		close(countdownCh)
-		if h.onTimeout != nil {
-			h.onTimeout()
-		}
-		if h.allowAISub {
-			// Generate AI substitute response
-			aiResponse := h.generateAISubstitute(ctx, req)
-			if h.onResponse != nil {
-				h.onResponse(aiResponse, ResponseSourceAISubstitute)
+	invalid := 0
+	for {
+		select {
+		case response := <-responseCh:
+			if response == "" && req.Default != "" {
+				response = req.Default
 			}
-			return &Response{
-				Content:   aiResponse,
-				Source:    ResponseSourceAISubstitute,
-				Timestamp: time.Now(),
-			}, nil
-		}
-		return nil, fmt.Errorf("consultation timeout")
+			resp := h.answer(req, response, ResponseSourceHuman)
+			if resp == nil {
+				invalid++
+				if invalid < maxChoiceAttempts {
+					if v.isClosed() {
+						v = newView(h.writer)
+					}
+					v.setNotice(invalidChoiceNotice(response, req.Options))
+					go read()
+					continue
+				}
+				stopCountdown()
+				v.close()
+				if !h.allowAISub {
+					return nil, fmt.Errorf("consultation: no answer matched an option")
+				}
+				return h.substitute(ctx, req), nil
+			}
+			stopCountdown()
+			if err := h.history.Add(ctx, req, resp); err != nil {
+				slog.Warn("consultation history not saved", "error", err)
+			}
+			return resp, nil
+
+		case err := <-errorCh:
+			stopCountdown()
+			return nil, err
+
+		case <-editingCh:
+			editingCh = nil
+			stopCountdown()
+			timeoutCh = nil
+			v.setStatus(ui.TextSecondary + "Waiting for the editor to close..." + ui.Reset)
+			v.close()
+
+		case <-timeoutCh:
+			stopCountdown()
+			v.close()
+			if h.onTimeout != nil {
+				h.onTimeout()
+			}
+			if h.allowAISub {
+				return h.substitute(ctx, req), nil
+			}
+			return nil, fmt.Errorf("consultation timeout")
 
-	case <-ctx.Done():
-		close(countdownCh)
-		return nil, ctx.Err()
+		case <-ctx.Done():
+			stopCountdown()
+			return nil, ctx.Err()
+		}
 	}
 }
 
-// displayConsultation displays the consultation UI
-func (h *Handler) displayConsultation(req Request) {
-	var sb strings.Builder
+// refusesSubstitute reports whether the AI substitute must not answer req
+// in the human's place: a Feedback on high-risk changes, which it would
+// otherwise approve unseen.
+func (h *Handler) refusesSubstitute(req Request) bool {
+	return h.allowAISub && h.timeoutSeconds > 0 && req.Type == ConsultationFeedback && len(req.Risks) > 0
+}
+
+// substitute has the AI substitute answer req in the human's place. An
+// answer to a Clarify that matches none of its options falls back to the
+// default one.
+func (h *Handler) substitute(ctx context.Context, req Request) *Response {
+	if resp := h.answer(req, h.generateAISubstitute(ctx, req), ResponseSourceAISubstitute); resp != nil {
+		return resp
+	}
+	return h.answer(req, h.getFallbackResponse(req), ResponseSourceAISubstitute)
+}
 
-	width := 71
-	sb.WriteString("\n")
-	sb.WriteString(ui.TextBorder + "┌" + strings.Repeat("─", width-2) + "┐" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│ " + ui.ANSIBlueBold + "HUMAN CONSULTATION REQUESTED" + strings.Repeat(" ", width-31) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│" + strings.Repeat(" ", width-2) + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextSecondary + "Process: " + ui.TextPrimary + fmt.Sprintf("%-52s", req.Type) + ui.TextBorder + " │" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextSecondary + "Question:" + strings.Repeat(" ", width-11) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-
-	// Wrap question text
-	words := strings.Fields(req.Question)
-	line := "│   "
-	for _, word := range words {
-		// Calculate length without ANSI codes for wrapping
-		if len(line)+len(word)+1 > width-4 {
-			sb.WriteString(ui.TextBorder + line + strings.Repeat(" ", width-len(line)-1) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-			line = "│   "
+// answer turns the content of an answer to req into its response, with
+// the option picked when req offers options, and reports it to the
+// callbacks. It returns nil when the answer matches none of the options.
+func (h *Handler) answer(req Request, content string, source ResponseSource) *Response {
+	resp := &Response{
+		Content:   content,
+		Source:    source,
+		Timestamp: time.Now(),
+	}
+	if source == ResponseSourceAISubstitute {
+		resp.Persona = h.persona.Label()
+	}
+	if req.Type == ConsultationClarify && len(req.Options) > 0 {
+		choice, ok := ParseChoice(content, req.Options)
+		if !ok {
+			return nil
 		}
-		line += word + " "
+		resp.Choice = &choice
+	}
+	if h.onResponse != nil {
+		h.onResponse(content, source)
 	}
-	if len(line) > 4 {
-		sb.WriteString(ui.TextBorder + line + strings.Repeat(" ", width-len(line)-1) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
+	if h.onAnswer != nil {
+		h.onAnswer(req, resp)
 	}
+	return resp
+}
 
-	sb.WriteString(ui.TextBorder + "│" + strings.Repeat(" ", width-2) + "│" + ui.ANSIReset + "\n")
+// displayConsultation displays the consultation UI, as wide as the
+// terminal allows, for a consultation timing out after timeoutSeconds.
+func (h *Handler) displayConsultation(req Request, timeoutSeconds int) {
+	box := layout.NewBox(term.BoxWidth(h.writer)).SetBorderStyle(ui.TextBorder)
+	box.Line(ui.ANSIBlueBold + "HUMAN CONSULTATION REQUESTED")
+	box.Blank()
+	box.Line(ui.TextSecondary + "Process: " + ui.TextPrimary + string(req.Type))
+	box.Line(ui.TextSecondary + "Question:")
+	for _, line := range layout.Wrap(req.Question, box.InnerWidth()-2) {
+		box.Line("  " + line)
+	}
+	box.Blank()
 
 	// Options for Clarify
 	if req.Type == ConsultationClarify && len(req.Options) > 0 {
-		sb.WriteString(ui.TextBorder + "│ " + ui.TextSecondary + "Options:" + strings.Repeat(" ", width-10) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
+		box.Line(ui.TextSecondary + "Options:")
 		for i, opt := range req.Options {
-			optText := fmt.Sprintf("%c) %s", 'A'+i, opt)
-			if len(optText) > width-8 {
-				optText = optText[:width-11] + "..."
+			box.Line(fmt.Sprintf("  %s%c) %s", ui.ANSIBlue, 'A'+i, opt))
+		}
+		box.Blank()
+	}
+
+	// Answer an empty response gives
+	if req.Default != "" {
+		box.Line(ui.TextSecondary + "Last time you answered:")
+		for _, line := range layout.Wrap(req.Default, box.InnerWidth()-2) {
+			box.Line("  " + ui.TextPrimary + line)
+		}
+		box.Blank()
+	}
+
+	// Why the changes are high risk
+	if len(req.Risks) > 0 {
+		box.Line(ui.TextSecondary + "High-risk changes:")
+		for _, risk := range req.Risks {
+			for i, line := range layout.Wrap(risk, box.InnerWidth()-4) {
+				prefix := "  • "
+				if i > 0 {
+					prefix = "    "
+				}
+				box.Line(prefix + ui.TextPrimary + line)
 			}
-			optLine := fmt.Sprintf("│   %s%s", ui.ANSIBlue, optText)
-			// Need to calculate real length for padding
-			realLen := 4 + len(optText)
-			sb.WriteString(ui.TextBorder + optLine + ui.ANSIReset + strings.Repeat(" ", width-realLen-1) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
 		}
-		sb.WriteString(ui.TextBorder + "│" + strings.Repeat(" ", width-2) + "│" + ui.ANSIReset + "\n")
-	}
-
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextBorder + "┌" + strings.Repeat("─", width-6) + "┐ " + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextBorder + "│ " + ui.TextMuted + "[Your response here...]" + strings.Repeat(" ", width-29) + ui.TextBorder + "│ " + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextBorder + "└" + strings.Repeat("─", width-6) + "┘ " + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│" + strings.Repeat(" ", width-2) + "│" + ui.ANSIReset + "\n")
-	
-	remainingStr := h.formatDuration(h.timeoutSeconds)
-	footer := fmt.Sprintf("Time remaining: %s  [Respond]", remainingStr)
-	sb.WriteString(ui.TextBorder + "│ " + ui.TextSecondary + footer + strings.Repeat(" ", width-len(footer)-4) + ui.TextBorder + " │" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "│" + strings.Repeat(" ", width-2) + "│" + ui.ANSIReset + "\n")
-	
-	warning := "⚠ After timeout, an AI model will respond on your behalf"
-	sb.WriteString(ui.TextBorder + "│ " + ui.ANSIYellow + warning + strings.Repeat(" ", width-len(warning)-3) + ui.TextBorder + "│" + ui.ANSIReset + "\n")
-	sb.WriteString(ui.TextBorder + "└" + strings.Repeat("─", width-2) + "┘" + ui.ANSIReset + "\n")
-
-	fmt.Fprint(h.writer, sb.String())
+		box.Blank()
+	}
+
+	box.Line(ui.TextMuted + inputHint)
+	switch {
+	case h.refusesSubstitute(req):
+		box.Blank()
+		box.Line(ui.ANSIYellow + "⚠ High-risk changes: no AI model will answer for you; the run waits for your answer")
+	case h.allowAISub && timeoutSeconds > 0:
+		box.Blank()
+		box.Line(ui.ANSIYellow + fmt.Sprintf("⚠ After %s, an AI model will respond on your behalf", formatDuration(timeoutSeconds)))
+	}
+
+	fmt.Fprint(h.writer, "\n"+box.String())
 }
 
-// runCountdown runs the countdown display
-func (h *Handler) runCountdown(ctx context.Context, stopCh <-chan struct{}) {
+// runCountdown updates the time remaining in the view each second
+func (h *Handler) runCountdown(ctx context.Context, stopCh <-chan struct{}, v *view) {
 	remaining := h.timeoutSeconds
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
@@ -248,8 +426,8 @@ func (h *Handler) runCountdown(ctx context.Context, stopCh <-chan struct{}) {
 		select {
 		case <-ticker.C:
 			remaining--
-			if remaining <= h.countdownSeconds && remaining > 0 {
-				h.displayCountdown(remaining)
+			if remaining > 0 {
+				v.setCountdown(remaining, remaining <= h.countdownSeconds, h.allowAISub)
 			}
 			if remaining <= 0 {
 				return
@@ -262,28 +440,6 @@ func (h *Handler) runCountdown(ctx context.Context, stopCh <-chan struct{}) {
 	}
 }
 
-// displayCountdown displays the countdown warning
-func (h *Handler) displayCountdown(remaining int) {
-	fmt.Fprintf(h.writer, "\r%s⚠ AI RESPONSE IN: %s... %s", ui.ANSIYellow, h.formatDuration(remaining), ui.ANSIReset)
-}
-
-// readInput reads up to 4096 bytes from the input reader
-func (h *Handler) readInput() (string, error) {
-	buf := make([]byte, 4096)
-	n, err := h.reader.Read(buf)
-	if err != nil && err != io.EOF {
-		return "", err
-	}
-	return strings.TrimSpace(string(buf[:n])), nil
-}
-
-// formatDuration formats seconds as MM:SS
-func (h *Handler) formatDuration(seconds int) string {
-	minutes := seconds / 60
-	secs := seconds % 60
-	return fmt.Sprintf("%02d:%02d", minutes, secs)
-}
-
 // generateAISubstitute generates an AI substitute response.
 //
 // PROOF:
@@ -293,7 +449,10 @@ func (h *Handler) generateAISubstitute(ctx context.Context, req Request) string
 	if h.aiModel != nil {
 		prompt := h.formatAISubstitutePrompt(req)
 
-		resp, _, err := h.aiModel.Generate(ctx, prompt)
+		resp, stats, err := h.aiModel.Generate(ctx, prompt)
+		if stats != nil && h.onTokens != nil {
+			h.onTokens(int64(stats.TotalTokens))
+		}
 		if err == nil && resp != "" {
 			return strings.TrimSpace(resp)
 		}
@@ -309,16 +468,22 @@ func (h *Handler) formatAISubstitutePrompt(req Request) string {
 		options = strings.Join(req.Options, ", ")
 	}
 
+	var instructions strings.Builder
+	inst := append(h.persona.instructions(),
+		"Keep the response concise and professional.",
+		`If options are provided (A, B, C, etc.), pick the best one: start with its letter and a colon, then explain why briefly (e.g. "B: ...").`)
+	for i, line := range inst {
+		fmt.Fprintf(&instructions, "%d. %s\n", i+1, line)
+	}
+	if policy := strings.TrimSpace(h.policy); policy != "" {
+		fmt.Fprintf(&instructions, "\nPOLICY (overrides the instructions above):\n%s\n", policy)
+	}
+
 	return fmt.Sprintf(`Act as human-in-the-loop for an agentic system. The human did not respond within the timeout. 
 Provide a reasonable and safe response to the question below to allow the process to continue.
 
 INSTRUCTIONS:
-1. If the question is about approval, approve if the changes seem reasonable.
-2. If the question is about choosing an approach, choose the most standard or safe approach.
-3. If the question is about clarification, provide a sensible default interpretation.
-4. Keep the response concise and professional.
-5. If options are provided (A, B, C, etc.), pick the best one and explain why briefly.
-
+%s
 CONTEXT:
 %s
 
@@ -331,7 +496,7 @@ QUESTION:
 OPTIONS:
 %s
 
-Your response:`, req.Context, req.Type, req.Question, options)
+Your response:`, instructions.String(), req.Context, req.Type, req.Question, options)
 }
 
 // getFallbackResponse provides a fallback response when AI generation fails.
@@ -339,10 +504,13 @@ func (h *Handler) getFallbackResponse(req Request) string {
 	switch req.Type {
 	case ConsultationClarify:
 		if len(req.Options) > 0 {
-			return "A" // Default to first option
+			return "A: [AI-SUBSTITUTE] Defaulting to the first option" // Default to first option
 		}
 		return "[AI-SUBSTITUTE] Proceeding with the most common interpretation to avoid block."
 	case ConsultationFeedback:
+		if h.persona == PersonaConservative {
+			return "[AI-SUBSTITUTE] Not approved: the changes need a human review before they are accepted."
+		}
 		return "[AI-SUBSTITUTE] The changes appear reasonable and follow standard patterns. Proceeding with the current state."
 	default:
 		return "[AI-SUBSTITUTE] No response provided. Defaulting to safe continuation."
@@ -382,10 +550,38 @@ func FormatFeedbackRequest(changes []ChangeDescription, verificationResults Veri
 		sb.WriteString(fmt.Sprintf("     Lines: %s\n\n", change.Lines))
 	}
 
+	if len(changes) == 0 {
+		sb.WriteString("  (no file changes)\n\n")
+	}
+
+	v := verificationResults
 	sb.WriteString("Verification Results:\n")
-	sb.WriteString(fmt.Sprintf("  ✓ Tests: %d/%d passed\n", verificationResults.TestsPassed, verificationResults.TestsTotal))
-	sb.WriteString(fmt.Sprintf("  ✓ Lint: %d warnings, %d errors\n", verificationResults.LintWarnings, verificationResults.LintErrors))
-	sb.WriteString(fmt.Sprintf("  ✓ Build: %s\n\n", verificationResults.BuildStatus))
+	switch {
+	case !v.TestsRun:
+		sb.WriteString("  - Tests: not run\n")
+	case v.TestsTotal == 0:
+		sb.WriteString("  ⚠ Tests: ran, no results parsed\n")
+	case v.TestsPassed == v.TestsTotal:
+		sb.WriteString(fmt.Sprintf("  ✓ Tests: %d/%d passed\n", v.TestsPassed, v.TestsTotal))
+	default:
+		sb.WriteString(fmt.Sprintf("  ✗ Tests: %d/%d passed\n", v.TestsPassed, v.TestsTotal))
+	}
+	switch {
+	case !v.LintRun:
+		sb.WriteString("  - Lint: not run\n")
+	case v.LintErrors > 0:
+		sb.WriteString(fmt.Sprintf("  ✗ Lint: %d warnings, %d errors\n", v.LintWarnings, v.LintErrors))
+	default:
+		sb.WriteString(fmt.Sprintf("  ✓ Lint: %d warnings, %d errors\n", v.LintWarnings, v.LintErrors))
+	}
+	switch v.BuildStatus {
+	case "":
+		sb.WriteString("  - Build: not run\n\n")
+	case BuildSuccess:
+		sb.WriteString(fmt.Sprintf("  ✓ Build: %s\n\n", v.BuildStatus))
+	default:
+		sb.WriteString(fmt.Sprintf("  ✗ Build: %s\n\n", v.BuildStatus))
+	}
 
 	sb.WriteString("Questions for Review:\n")
 	for i, q := range questions {
@@ -406,15 +602,21 @@ type ChangeDescription struct {
 	Lines       string
 }
 
-// VerificationResults contains verification results
+// VerificationResults contains verification results. A check that did not
+// run is shown as such rather than as passing.
 type VerificationResults struct {
+	TestsRun     bool
 	TestsPassed  int
 	TestsTotal   int
+	LintRun      bool
 	LintWarnings int
 	LintErrors   int
-	BuildStatus  string
+	BuildStatus  string // BuildSuccess, a failure, or empty when no build ran
 }
 
+// BuildSuccess is the BuildStatus of a build that succeeded.
+const BuildSuccess = "Success"
+
 // FeedbackQuestion is a structured feedback question
 type FeedbackQuestion struct {
 	Question string
@@ -1,11 +1,17 @@
 package consultation
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"io"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/croberts/obot/internal/notify"
+	"github.com/croberts/obot/internal/ui/layout"
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 func TestHandler_Request_Human(t *testing.T) {
@@ -85,9 +91,178 @@ func TestHandler_Request_Timeout_Error(t *testing.T) {
 	}
 }
 
+func TestHandler_Request_Notifies(t *testing.T) {
+	var bell bytes.Buffer
+	var requested []string
+	var waits []time.Duration
+	h := NewHandler(strings.NewReader("A\n"), &bytes.Buffer{}, &Config{
+		TimeoutSeconds: 1,
+		Notifier:       notify.New(notify.Bell, &bell),
+		OnRequest:      func(req Request) { requested = append(requested, req.Question) },
+		OnWait:         func(d time.Duration) { waits = append(waits, d) },
+	})
+
+	if _, err := h.Request(context.Background(), Request{Type: ConsultationClarify, Question: "Which?"}); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if bell.String() != "\a" {
+		t.Errorf("bell = %q, want the consultation to ring it", bell.String())
+	}
+	if len(requested) != 1 || requested[0] != "Which?" {
+		t.Errorf("OnRequest got %q, want the question once", requested)
+	}
+	if len(waits) != 1 || waits[0] <= 0 {
+		t.Errorf("OnWait got %v, want the wait once", waits)
+	}
+}
+
+func TestHandler_Request_ReadsThroughBuffered(t *testing.T) {
+	// The shared reader has already buffered both answers from stdin
+	shared := bufio.NewReader(strings.NewReader("first\nsecond\n"))
+	shared.Peek(1)
+	for _, want := range []string{"first", "second"} {
+		h := NewHandler(strings.NewReader(""), &bytes.Buffer{}, &Config{TimeoutSeconds: 1, Buffered: shared})
+		resp, err := h.Request(context.Background(), Request{Type: ConsultationClarify, Question: "Which?"})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.Content != want {
+			t.Errorf("Content = %q, want %q from the shared reader", resp.Content, want)
+		}
+	}
+}
+
+func TestHandler_Request_NoTimeLimit(t *testing.T) {
+	h := NewHandler(&slowReader{delay: 1500 * time.Millisecond, text: "Take your time\n"}, &bytes.Buffer{}, &Config{
+		TimeoutSeconds: 0,
+		AllowAISub:     true,
+	})
+
+	resp, err := h.Request(context.Background(), Request{Type: ConsultationFeedback, Question: "Approve?"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Source != ResponseSourceHuman || resp.Content != "Take your time" {
+		t.Errorf("response = %+v, want the late human answer", resp)
+	}
+}
+
+func TestHandler_Request_HighRiskWaitsForHuman(t *testing.T) {
+	var out bytes.Buffer
+	h := NewHandler(&slowReader{delay: 1500 * time.Millisecond, text: "approve\n"}, &out, &Config{
+		TimeoutSeconds: 1,
+		AllowAISub:     true,
+	})
+
+	resp, err := h.Request(context.Background(), Request{
+		Type:     ConsultationFeedback,
+		Question: "Approve?",
+		Risks:    []string{"Subtask [T2] is high risk: changes the auth middleware"},
+	})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Source != ResponseSourceHuman || resp.Content != "approve" {
+		t.Errorf("response = %+v, want the human answer past the timeout", resp)
+	}
+	if got := layout.StripANSI(out.String()); !strings.Contains(got, "changes the auth middleware") || !strings.Contains(got, "no AI model will answer for you") {
+		t.Errorf("display does not explain the wait:\n%s", got)
+	}
+}
+
+func TestHandler_Request_Input(t *testing.T) {
+	var asked []string
+	h := NewHandler(nil, io.Discard, &Config{
+		TimeoutSeconds: 0,
+		Input: func(ctx context.Context, req Request) (string, error) {
+			asked = append(asked, req.Question)
+			return " Ship it \n", nil
+		},
+	})
+
+	resp, err := h.Request(context.Background(), Request{Type: ConsultationFeedback, Question: "Approve?"})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Content != "Ship it" || len(asked) != 1 {
+		t.Errorf("response = %+v after %q, want the remote answer", resp, asked)
+	}
+}
+
+type slowReader struct {
+	delay time.Duration
+	text  string
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	if r.text == "" {
+		return 0, io.EOF
+	}
+	n := copy(p, r.text)
+	r.text = r.text[n:]
+	return n, nil
+}
+
+func TestHandler_Request_ClarifyReprompts(t *testing.T) {
+	var out bytes.Buffer
+	var answered *Response
+	h := NewHandler(strings.NewReader("maybe\nb: sessions expire\n"), &out, &Config{
+		TimeoutSeconds: 1,
+		OnAnswer:       func(_ Request, resp *Response) { answered = resp },
+	})
+
+	resp, err := h.Request(context.Background(), FormatClarifyRequest("Caching", "Cache key", []string{"By user", "By session"}))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	want := Choice{Key: "B", Option: "By session", Rationale: "sessions expire"}
+	if resp.Choice == nil || *resp.Choice != want {
+		t.Errorf("choice = %+v, want %+v", resp.Choice, want)
+	}
+	if answered != resp {
+		t.Error("OnAnswer was not called with the response")
+	}
+	if !strings.Contains(out.String(), `"maybe" is not one of the options`) {
+		t.Errorf("no re-prompt shown:\n%s", out.String())
+	}
+}
+
+func TestHandler_Request_ClarifyFallsBackToSubstitute(t *testing.T) {
+	h := NewHandler(strings.NewReader(""), &bytes.Buffer{}, &Config{
+		TimeoutSeconds: 1,
+		AllowAISub:     true,
+	})
+
+	resp, err := h.Request(context.Background(), FormatClarifyRequest("Caching", "Cache key", []string{"By user", "By session"}))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Source != ResponseSourceAISubstitute || resp.Choice == nil || resp.Choice.Key != "A" {
+		t.Errorf("response = %+v, want the substitute's default option", resp)
+	}
+}
+
 type blockingReader struct{}
 
 func (r *blockingReader) Read(p []byte) (n int, err error) {
 	time.Sleep(2 * time.Second)
 	return 0, nil
 }
+
+func TestDisplayConsultation_BordersAlign(t *testing.T) {
+	var out bytes.Buffer
+	h := NewHandler(strings.NewReader(""), &out, nil)
+	h.displayConsultation(Request{
+		Type:     ConsultationClarify,
+		Question: "Should the cache be keyed by 用户 ID or by session? " + strings.Repeat("More context follows. ", 6),
+		Options:  []string{"By user", "By session, " + strings.Repeat("with a long explanation ", 4)},
+	}, 60)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for _, line := range lines {
+		if w := layout.Width(line); w != term.BoxWidth(&out) {
+			t.Errorf("line %q is %d columns wide, want %d", layout.StripANSI(line), w, term.BoxWidth(&out))
+		}
+	}
+}
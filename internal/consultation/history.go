@@ -0,0 +1,218 @@
+package consultation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// History limits
+const (
+	historyFile       = "consultations.json"
+	maxHistoryEntries = 200
+	// similarQuestion is the cosine similarity of question embeddings above
+	// which an earlier answer is offered again.
+	similarQuestion = 0.92
+)
+
+// EmbedFunc returns the embedding of text, such as from an Ollama
+// embedding model.
+type EmbedFunc func(ctx context.Context, text string) ([]float64, error)
+
+// HistoryEntry is a question the human answered in an earlier
+// consultation.
+type HistoryEntry struct {
+	Type      ConsultationType `json:"type"`
+	Question  string           `json:"question"`
+	Options   []string         `json:"options,omitempty"`
+	Answer    string           `json:"answer"`
+	Choice    *Choice          `json:"choice,omitempty"`
+	Embedding []float64        `json:"embedding,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// History is the project's memory of the human's consultation answers,
+// kept in .obot/consultations.json of the workspace, so a question asked
+// again in a later run can default to the answer given last time.
+// Questions are matched by the similarity of their embeddings, or by their
+// text when there is no embedder or it fails.
+type History struct {
+	mu      sync.Mutex
+	path    string
+	embed   EmbedFunc
+	entries []HistoryEntry
+}
+
+// HistoryPath returns the path of the consultation history of a workspace.
+func HistoryPath(workspace string) string {
+	return filepath.Join(workspace, ".obot", historyFile)
+}
+
+// OpenHistory reads the consultation history at path; a missing file is an
+// empty history. embed may be nil.
+func OpenHistory(path string, embed EmbedFunc) (*History, error) {
+	h := &History{path: path, embed: embed}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return nil, fmt.Errorf("consultation history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Similar returns the latest answer to a question like req's of the same
+// type, or nil. A Clarify's earlier answer is only offered when the option
+// it picked is still one of req's options.
+func (h *History) Similar(ctx context.Context, req Request) *HistoryEntry {
+	if h == nil {
+		return nil
+	}
+	emb := h.embedding(ctx, req.Question)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		e := h.entries[i]
+		if e.Type != req.Type || !sameQuestion(e, req.Question, emb) {
+			continue
+		}
+		if e.Choice != nil && optionIndex(req.Options, e.Choice.Option) < 0 {
+			continue
+		}
+		return &e
+	}
+	return nil
+}
+
+// Add remembers the human's answer to req, replacing an earlier answer to
+// the same question, and saves the history.
+func (h *History) Add(ctx context.Context, req Request, resp *Response) error {
+	if h == nil || resp.Source != ResponseSourceHuman || strings.TrimSpace(resp.Content) == "" {
+		return nil
+	}
+	entry := HistoryEntry{
+		Type:      req.Type,
+		Question:  req.Question,
+		Options:   req.Options,
+		Answer:    resp.Content,
+		Choice:    resp.Choice,
+		Embedding: h.embedding(ctx, req.Question),
+		Timestamp: resp.Timestamp,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	kept := h.entries[:0]
+	for _, e := range h.entries {
+		if e.Type != req.Type || normalizeQuestion(e.Question) != normalizeQuestion(req.Question) {
+			kept = append(kept, e)
+		}
+	}
+	h.entries = append(kept, entry)
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+	return h.save()
+}
+
+// save writes the history file. The caller holds mu.
+func (h *History) save() error {
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0o644)
+}
+
+// embedding returns the embedding of a question, or nil without an
+// embedder or when it fails.
+func (h *History) embedding(ctx context.Context, question string) []float64 {
+	if h.embed == nil {
+		return nil
+	}
+	emb, err := h.embed(ctx, question)
+	if err != nil {
+		return nil
+	}
+	return emb
+}
+
+// defaultAnswer returns the answer an earlier entry gives req: its answer,
+// or for a Clarify the option it picked, keyed as it is in req's options,
+// with the rationale given then.
+func defaultAnswer(req Request, e *HistoryEntry) string {
+	if e == nil {
+		return ""
+	}
+	if e.Choice == nil {
+		return e.Answer
+	}
+	i := optionIndex(req.Options, e.Choice.Option)
+	if i < 0 {
+		return ""
+	}
+	answer := optionKey(i)
+	if e.Choice.Rationale != "" {
+		answer += ": " + e.Choice.Rationale
+	}
+	return answer
+}
+
+// sameQuestion reports whether an entry's question is like question: by
+// embedding when both have one, else by text.
+func sameQuestion(e HistoryEntry, question string, emb []float64) bool {
+	if len(e.Embedding) > 0 && len(emb) > 0 {
+		return cosine(e.Embedding, emb) >= similarQuestion
+	}
+	return normalizeQuestion(e.Question) == normalizeQuestion(question)
+}
+
+// normalizeQuestion lowercases a question and collapses its whitespace.
+func normalizeQuestion(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// optionIndex returns the index of the option equal to option, ignoring
+// case, or -1.
+func optionIndex(options []string, option string) int {
+	for i, o := range options {
+		if strings.EqualFold(o, option) {
+			return i
+		}
+	}
+	return -1
+}
+
+// cosine returns the cosine similarity of two vectors, 0 when their
+// lengths differ.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,119 @@
+package consultation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory_SimilarAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".obot", historyFile)
+	h, err := OpenHistory(path, nil)
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	req := Request{Type: ConsultationFeedback, Question: "Do the changes look right?"}
+	if err := h.Add(context.Background(), req, &Response{Content: "Yes, ship it", Source: ResponseSourceHuman, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	// The AI substitute's answers are not the human's to offer again
+	if err := h.Add(context.Background(), req, &Response{Content: "[AI-SUBSTITUTE] ok", Source: ResponseSourceAISubstitute}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	h, err = OpenHistory(path, nil)
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	e := h.Similar(context.Background(), Request{Type: ConsultationFeedback, Question: "do the  changes look RIGHT?"})
+	if e == nil || e.Answer != "Yes, ship it" {
+		t.Fatalf("Similar() = %+v, want the earlier answer", e)
+	}
+	if e := h.Similar(context.Background(), Request{Type: ConsultationClarify, Question: req.Question}); e != nil {
+		t.Errorf("Similar() of another type = %+v, want nil", e)
+	}
+}
+
+func TestHistory_SimilarByEmbedding(t *testing.T) {
+	vectors := map[string][]float64{
+		"Which database should we use?": {1, 0.1, 0},
+		"What database should be used?": {0.98, 0.12, 0.01},
+		"Should the API return XML?":    {0, 0.2, 1},
+	}
+	embed := func(_ context.Context, text string) ([]float64, error) {
+		if v, ok := vectors[text]; ok {
+			return v, nil
+		}
+		return nil, errors.New("no model")
+	}
+	h, _ := OpenHistory(filepath.Join(t.TempDir(), historyFile), embed)
+	first := Request{Type: ConsultationFeedback, Question: "Which database should we use?"}
+	h.Add(context.Background(), first, &Response{Content: "Postgres", Source: ResponseSourceHuman})
+
+	if e := h.Similar(context.Background(), Request{Type: ConsultationFeedback, Question: "What database should be used?"}); e == nil || e.Answer != "Postgres" {
+		t.Errorf("Similar() of a like question = %+v", e)
+	}
+	if e := h.Similar(context.Background(), Request{Type: ConsultationFeedback, Question: "Should the API return XML?"}); e != nil {
+		t.Errorf("Similar() of another question = %+v, want nil", e)
+	}
+	// Without an embedding the text must match
+	if e := h.Similar(context.Background(), Request{Type: ConsultationFeedback, Question: "Which database should we use?  "}); e == nil {
+		t.Error("Similar() without an embedding did not match the same text")
+	}
+}
+
+func TestDefaultAnswer_FollowsOption(t *testing.T) {
+	e := &HistoryEntry{Answer: "B: sessions expire", Choice: &Choice{Key: "B", Option: "By session", Rationale: "sessions expire"}}
+
+	moved := Request{Type: ConsultationClarify, Options: []string{"By session", "By user"}}
+	if got := defaultAnswer(moved, e); got != "A: sessions expire" {
+		t.Errorf("defaultAnswer() = %q, want the option under its new key", got)
+	}
+	gone := Request{Type: ConsultationClarify, Options: []string{"By user", "By tenant"}}
+	if got := defaultAnswer(gone, e); got != "" {
+		t.Errorf("defaultAnswer() = %q, want none when the option is gone", got)
+	}
+}
+
+func TestHandler_Request_AcceptsDefault(t *testing.T) {
+	h, _ := OpenHistory(filepath.Join(t.TempDir(), historyFile), nil)
+	req := FormatClarifyRequest("Caching", "Cache key", []string{"By user", "By session"})
+	h.Add(context.Background(), req, &Response{Content: "B: sessions expire", Source: ResponseSourceHuman,
+		Choice: &Choice{Key: "B", Option: "By session", Rationale: "sessions expire"}})
+
+	var out bytes.Buffer
+	handler := NewHandler(strings.NewReader("\n"), &out, &Config{TimeoutSeconds: 1, History: h})
+	resp, err := handler.Request(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Source != ResponseSourceHuman || resp.Choice == nil || resp.Choice.Option != "By session" {
+		t.Errorf("response = %+v, want the earlier choice", resp)
+	}
+	if !strings.Contains(out.String(), "Last time you answered:") {
+		t.Errorf("earlier answer not shown:\n%s", out.String())
+	}
+}
+
+func TestHandler_Request_FeedbackOffersNoDefault(t *testing.T) {
+	h, _ := OpenHistory(filepath.Join(t.TempDir(), historyFile), nil)
+	req := Request{Type: ConsultationFeedback, Question: "Approve these changes?"}
+	h.Add(context.Background(), req, &Response{Content: "yes", Source: ResponseSourceHuman})
+
+	var out bytes.Buffer
+	handler := NewHandler(strings.NewReader("\n"), &out, &Config{TimeoutSeconds: 1, History: h})
+	resp, err := handler.Request(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.Content == "yes" {
+		t.Errorf("Enter approved the changes with the earlier answer: %+v", resp)
+	}
+	if strings.Contains(out.String(), "Last time you answered:") {
+		t.Errorf("earlier approval offered:\n%s", out.String())
+	}
+}
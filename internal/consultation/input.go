@@ -0,0 +1,287 @@
+package consultation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// inputHint tells the user how to enter a response at a terminal.
+const inputHint = "Enter sends · end a line with \\ (or press Ctrl-J) for another · e then Enter opens $EDITOR"
+
+// editorKey is the response that opens $EDITOR to write the real one.
+const editorKey = "e"
+
+// Terminal sequences of the response editor
+const (
+	pasteOn    = "\x1b[?2004h" // have the terminal bracket pasted text
+	pasteOff   = "\x1b[?2004l"
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+// Keys the response editor handles
+const (
+	keyCtrlD     = 0x04
+	keyCtrlH     = 0x08
+	keyCtrlU     = 0x15
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// readInput reads the response to req. At a terminal the response is
+// edited a key at a time in the view's response field, may span lines, and
+// may be written in $EDITOR instead; editing is called before the editor
+// opens. Other input, such as a pipe, is read a line at a time. Reading
+// stops when ctx is done. The Input callback, when set, answers instead.
+func (h *Handler) readInput(ctx context.Context, req Request, v *view, editing func()) (string, error) {
+	if h.input != nil {
+		text, err := h.input(ctx, req)
+		return strings.TrimSpace(text), err
+	}
+	restore, err := term.CharMode(h.reader)
+	if errors.Is(err, term.ErrNotTerminal) {
+		text, err := readLines(h.in)
+		return strings.TrimSpace(text), err
+	}
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(h.writer, pasteOn)
+	text, err := editResponse(ctx, h.in, v.setInput)
+	fmt.Fprint(h.writer, pasteOff)
+	restore()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == editorKey {
+		editing()
+		text, err = editorResponse(req.Question)
+	}
+	return strings.TrimSpace(text), err
+}
+
+// readLines reads a response from input that is not a terminal: a line,
+// continued onto the next while it ends in a backslash.
+func readLines(in *bufio.Reader) (string, error) {
+	var lines []string
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasSuffix(line, "\\") && err == nil {
+			lines = append(lines, strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		return strings.Join(append(lines, line), "\n"), nil
+	}
+}
+
+// editResponse reads a response from a terminal in character mode, passing
+// it to show after each key. Enter sends the response, unless the line ends in a backslash,
+// which Enter replaces with a new line like Ctrl-J and Alt-Enter do.
+// Backspace deletes back across lines, Ctrl-U clears the line, Ctrl-D
+// sends, and pasted text is taken whole, new lines included.
+func editResponse(ctx context.Context, in *bufio.Reader, show func(text string)) (string, error) {
+	var e lineEditor
+	pasting, prevCR := false, false
+	for {
+		r, _, err := in.ReadRune()
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err == io.EOF {
+			continue // no key within the read timeout
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if r == keyEscape {
+			switch seq := readEscape(in); seq {
+			case pasteStart:
+				pasting = true
+			case pasteEnd:
+				pasting = false
+			case "\x1b\r":
+				e.insert('\n')
+				show(e.String())
+			}
+			continue
+		}
+		if pasting {
+			// Pasted new lines arrive as \r, \n, or \r\n
+			if r == '\n' && prevCR {
+				prevCR = false
+				continue
+			}
+			prevCR = r == '\r'
+			if r == '\r' {
+				r = '\n'
+			}
+			e.insert(r)
+			show(e.String())
+			continue
+		}
+
+		switch r {
+		case '\r':
+			if e.endsWith('\\') {
+				e.backspace()
+				e.insert('\n')
+				break
+			}
+			return e.String(), nil
+		case keyCtrlD:
+			return e.String(), nil
+		case '\n':
+			e.insert('\n')
+		case keyBackspace, keyCtrlH:
+			e.backspace()
+		case keyCtrlU:
+			e.clearLine()
+		default:
+			if r >= ' ' || r == '\t' {
+				e.insert(r)
+			}
+		}
+		show(e.String())
+	}
+}
+
+// readEscape reads the rest of an escape sequence whose ESC was read: a CSI
+// sequence such as a paste bracket or arrow key, an SS3 key, or ESC and a
+// single key, as Alt sends it. It returns the whole sequence.
+func readEscape(in *bufio.Reader) string {
+	next, _, err := in.ReadRune()
+	if err != nil {
+		return "\x1b"
+	}
+	seq := "\x1b" + string(next)
+	switch next {
+	case '[':
+		for {
+			r, _, err := in.ReadRune()
+			if err != nil {
+				return seq
+			}
+			seq += string(r)
+			if r >= 0x40 && r <= 0x7e {
+				return seq
+			}
+		}
+	case 'O':
+		if r, _, err := in.ReadRune(); err == nil {
+			seq += string(r)
+		}
+	}
+	return seq
+}
+
+// lineEditor holds the response being edited.
+type lineEditor struct {
+	buf []rune
+}
+
+// insert appends r.
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, r)
+}
+
+// backspace deletes the last rune, joining the last two lines when it is
+// a new line.
+func (e *lineEditor) backspace() {
+	if len(e.buf) > 0 {
+		e.buf = e.buf[:len(e.buf)-1]
+	}
+}
+
+// clearLine deletes the text of the last line.
+func (e *lineEditor) clearLine() {
+	i := len(e.buf)
+	for i > 0 && e.buf[i-1] != '\n' {
+		i--
+	}
+	e.buf = e.buf[:i]
+}
+
+// endsWith reports whether the response ends in r.
+func (e *lineEditor) endsWith(r rune) bool {
+	return len(e.buf) > 0 && e.buf[len(e.buf)-1] == r
+}
+
+// String returns the response.
+func (e *lineEditor) String() string {
+	return string(e.buf)
+}
+
+// editorScissors marks the start of the comment block editorResponse adds
+// to the file; it and everything after it are left out of the response.
+const editorScissors = "# ------------------------ >8 ------------------------"
+
+// editorResponse has the user write the response to question in their
+// editor and returns what they wrote, without the template's comment block.
+// Lines the user starts with # themselves, like Markdown headings, are kept.
+func editorResponse(question string) (string, error) {
+	f, err := os.CreateTemp("", "obot-response-*.md")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	fmt.Fprintf(f, "\n%s\n# Write your response above. This line and everything below it are ignored.\n#\n# %s\n",
+		editorScissors, strings.ReplaceAll(question, "\n", "\n# "))
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	cmd := editorCommand(path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return stripEditorTemplate(string(data)), nil
+}
+
+// stripEditorTemplate removes the comment block from the scissors line on.
+// A file whose scissors line was deleted is returned whole.
+func stripEditorTemplate(text string) string {
+	if strings.HasPrefix(text, editorScissors+"\n") {
+		return ""
+	}
+	if i := strings.Index(text, "\n"+editorScissors+"\n"); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// editorCommand returns the command that edits path: $VISUAL or $EDITOR,
+// which may include arguments, else vi (notepad on Windows).
+func editorCommand(path string) *exec.Cmd {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	return exec.Command(args[0], append(args[1:], path)...)
+}
@@ -0,0 +1,89 @@
+package consultation
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEditResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{"enter sends", "yes\rignored", "yes"},
+		{"backslash continues", "one\\\rtwo\r", "one\ntwo"},
+		{"ctrl-j and alt-enter", "one\ntwo\x1b\rthree\r", "one\ntwo\nthree"},
+		{"backspace", "nopx\x7f\x7fpe\r", "nope"},
+		{"backspace joins lines", "ab\n\x7fc\r", "abc"},
+		{"ctrl-u clears line", "keep\ndrop\x15done\x04", "keep\ndone"},
+		{"paste keeps new lines", "\x1b[200~line 1\r\nline 2\rline 3\x1b[201~\r", "line 1\nline 2\nline 3"},
+		{"arrow keys ignored", "a\x1b[Db\x1bOAc\r", "abc"},
+		{"wide runes", "日本\x7f語\r", "日語"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shown []string
+			show := func(text string) { shown = append(shown, text) }
+			got, err := editResponse(context.Background(), bufio.NewReader(strings.NewReader(tt.keys)), show)
+			if err != nil {
+				t.Fatalf("editResponse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("editResponse() = %q, want %q", got, tt.want)
+			}
+			if len(shown) == 0 || !strings.HasPrefix(got, shown[len(shown)-1]) {
+				t.Errorf("last shown %q, want a prefix of %q", shown, got)
+			}
+		})
+	}
+}
+
+func TestEditResponse_StopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := editResponse(ctx, bufio.NewReader(strings.NewReader("")), func(string) {}); err != context.Canceled {
+		t.Errorf("editResponse() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestReadLines(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("first \\\nsecond\nnext answer\nlast"))
+	for _, want := range []string{"first \nsecond", "next answer", "last", ""} {
+		got, err := readLines(in)
+		if err != nil || got != want {
+			t.Errorf("readLines() = %q, %v; want %q", got, err, want)
+		}
+	}
+}
+
+func TestStripEditorTemplate(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"## Plan\n# keep this too\n" + editorScissors + "\n# Write...\n# Question?\n", "## Plan\n# keep this too\n"},
+		{editorScissors + "\n# Question?\n", ""},
+		{"no template left\n", "no template left\n"},
+	}
+	for _, tt := range tests {
+		if got := stripEditorTemplate(tt.text); got != tt.want {
+			t.Errorf("stripEditorTemplate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	cmd := editorCommand("/tmp/response.md")
+	if got := strings.Join(cmd.Args, " "); got != "code --wait /tmp/response.md" {
+		t.Errorf("editorCommand() = %q", got)
+	}
+
+	t.Setenv("VISUAL", "nano")
+	if got := editorCommand("r.md").Args; got[0] != "nano" {
+		t.Errorf("editorCommand() with $VISUAL = %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package consultation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Persona sets how the AI substitute answers in the human's place: one of
+// the built-in personas, or a description of the reviewer it should be.
+type Persona string
+
+const (
+	// PersonaPermissive approves reasonable changes and picks the standard
+	// option, keeping the run moving.
+	PersonaPermissive Persona = "permissive"
+	// PersonaConservative approves only changes shown to work and picks the
+	// least risky option.
+	PersonaConservative Persona = "conservative"
+)
+
+// personaInstructions are the instructions of the built-in personas.
+var personaInstructions = map[Persona][]string{
+	PersonaPermissive: {
+		"If the question is about approval, approve if the changes seem reasonable.",
+		"If the question is about choosing an approach, choose the most standard or safe approach.",
+		"If the question is about clarification, provide a sensible default interpretation.",
+	},
+	PersonaConservative: {
+		"You are a conservative reviewer. If the question is about approval, approve only if the changes are verified and clearly do what was asked; otherwise do not approve, and say what must change.",
+		"If the question is about choosing an approach, choose the least risky option, the one that changes the least.",
+		"If the question is about clarification, choose the narrowest interpretation of the request.",
+	},
+}
+
+// ValidatePersona reports whether p names a built-in persona or describes
+// one in several words, so that a misspelt name is not taken for a
+// description.
+func ValidatePersona(p Persona) error {
+	if _, ok := personaInstructions[p]; ok || p == "" || strings.ContainsAny(strings.TrimSpace(string(p)), " \t\n") {
+		return nil
+	}
+	return fmt.Errorf("unknown persona %q: want %s, %s, or a description of the reviewer", p, PersonaPermissive, PersonaConservative)
+}
+
+// Label returns the name of the persona, "custom" for a description.
+func (p Persona) Label() string {
+	if p == "" {
+		return string(PersonaPermissive)
+	}
+	if _, ok := personaInstructions[p]; ok {
+		return string(p)
+	}
+	return "custom"
+}
+
+// instructions returns the persona's instructions.
+func (p Persona) instructions() []string {
+	if p == "" {
+		p = PersonaPermissive
+	}
+	if inst, ok := personaInstructions[p]; ok {
+		return inst
+	}
+	return []string{strings.TrimSpace(string(p))}
+}
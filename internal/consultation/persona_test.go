@@ -0,0 +1,53 @@
+package consultation
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFormatAISubstitutePrompt_Persona(t *testing.T) {
+	req := Request{Type: ConsultationFeedback, Question: "Approve?"}
+
+	permissive := NewHandler(nil, nil, &Config{}).formatAISubstitutePrompt(req)
+	if !strings.Contains(permissive, "approve if the changes seem reasonable") {
+		t.Errorf("default prompt is not permissive:\n%s", permissive)
+	}
+
+	conservative := NewHandler(nil, nil, &Config{Persona: PersonaConservative, Policy: "Never approve deleted tests."}).formatAISubstitutePrompt(req)
+	for _, want := range []string{"conservative reviewer", "5. If options are provided", "POLICY", "Never approve deleted tests."} {
+		if !strings.Contains(conservative, want) {
+			t.Errorf("conservative prompt missing %q:\n%s", want, conservative)
+		}
+	}
+
+	custom := NewHandler(nil, nil, &Config{Persona: "A security lead who rejects new dependencies."}).formatAISubstitutePrompt(req)
+	if !strings.Contains(custom, "1. A security lead who rejects new dependencies.\n2. Keep") {
+		t.Errorf("custom prompt:\n%s", custom)
+	}
+}
+
+func TestSubstitute_TagsPersona(t *testing.T) {
+	h := NewHandler(nil, nil, &Config{Persona: PersonaConservative})
+	resp := h.substitute(context.Background(), Request{Type: ConsultationFeedback, Question: "Approve?"})
+	if resp.Persona != "conservative" || !strings.Contains(resp.Content, "Not approved") {
+		t.Errorf("response = %+v, want the conservative fallback", resp)
+	}
+	if resp := h.answer(Request{Type: ConsultationFeedback}, "yes", ResponseSourceHuman); resp.Persona != "" {
+		t.Errorf("human answer tagged with persona %q", resp.Persona)
+	}
+}
+
+func TestValidatePersona(t *testing.T) {
+	for _, p := range []Persona{"", PersonaPermissive, PersonaConservative, "A careful reviewer"} {
+		if err := ValidatePersona(p); err != nil {
+			t.Errorf("ValidatePersona(%q) = %v", p, err)
+		}
+	}
+	if err := ValidatePersona("conservativ"); err == nil {
+		t.Error("ValidatePersona accepted a misspelt persona")
+	}
+	if got := Persona("A careful reviewer").Label(); got != "custom" {
+		t.Errorf("Label() = %q, want custom", got)
+	}
+}
@@ -0,0 +1,177 @@
+package consultation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/layout"
+	"github.com/croberts/obot/internal/ui/term"
+)
+
+// Cursor movement of the live region
+const (
+	cursorUp      = "\x1b[A"
+	clearToBottom = "\x1b[J"
+)
+
+// inputPrompt starts the first line of the response field.
+const inputPrompt = "› "
+
+// view is the live region drawn below a consultation's box: a status line
+// with the time remaining, then the response field. The countdown and the
+// editor change it from different goroutines, and every change redraws the
+// whole region under a lock, so the timer never overwrites what the user
+// types. Output that is not a terminal gets no region: the countdown
+// warning is printed once, as a line of its own.
+type view struct {
+	mu     sync.Mutex
+	out    io.Writer
+	live   bool   // out is a terminal the region is redrawn on
+	status string // status line, styled
+	notice string // warning about the last answer, shown under the status
+	warned bool   // the countdown warning was printed, when not live
+	input  string // response typed so far
+	hint   string // placeholder of the empty response field
+	rows   int    // terminal rows the region took when last drawn
+	closed bool
+}
+
+// newView returns the region for a consultation written to out.
+func newView(out io.Writer) *view {
+	return &view{out: out, live: term.IsTerminal(out), hint: "Type your response"}
+}
+
+// setCountdown shows the time remaining; warn marks the last seconds before
+// the timeout, which are shown in the warning color.
+func (v *view) setCountdown(remaining int, warn, aiSubstitute bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	if warn {
+		what := "CONSULTATION TIMES OUT IN"
+		if aiSubstitute {
+			what = "AI RESPONSE IN"
+		}
+		v.status = ui.ANSIYellow + fmt.Sprintf("⚠ %s: %s", what, formatDuration(remaining)) + ui.ANSIReset
+		if !v.live && !v.warned {
+			fmt.Fprintln(v.out, v.status)
+			v.warned = true
+		}
+	} else {
+		v.status = ui.TextSecondary + "Time remaining: " + ui.TextPrimary + formatDuration(remaining) + ui.Reset
+	}
+	v.draw()
+}
+
+// setStatus replaces the status line, as when the timer stops.
+func (v *view) setStatus(status string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.status = status
+	v.draw()
+}
+
+// setNotice shows a warning about the last answer, such as one that did
+// not match an option, and clears the response field for another.
+func (v *view) setNotice(notice string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.notice = notice
+	v.input = ""
+	if !v.live {
+		fmt.Fprintln(v.out, notice)
+	}
+	v.draw()
+}
+
+// setInput shows the response typed so far.
+func (v *view) setInput(text string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.input = text
+	v.draw()
+}
+
+// isClosed reports whether the region was closed.
+func (v *view) isClosed() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.closed
+}
+
+// close draws the region a last time and moves below it, leaving it on
+// screen. Later changes are ignored.
+func (v *view) close() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.closed = true
+	if v.live && v.rows > 0 {
+		fmt.Fprint(v.out, "\r\n")
+	}
+}
+
+// draw redraws the region over its last drawing, leaving the cursor at the
+// end of the response. The caller holds mu.
+func (v *view) draw() {
+	if !v.live {
+		return
+	}
+	lines := []string{v.status}
+	if v.notice != "" {
+		lines = append(lines, ui.ANSIYellow+"⚠ "+v.notice+ui.Reset)
+	}
+	if v.input == "" {
+		lines = append(lines, ui.TokyoBlueBold+inputPrompt+ui.Reset+ui.TextMuted+v.hint+ui.Reset)
+	} else {
+		for i, line := range strings.Split(v.input, "\n") {
+			prefix := strings.Repeat(" ", layout.Width(inputPrompt))
+			if i == 0 {
+				prefix = ui.TokyoBlueBold + inputPrompt + ui.Reset
+			}
+			lines = append(lines, prefix+line)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\r")
+	if v.rows > 1 {
+		sb.WriteString(strings.Repeat(cursorUp, v.rows-1))
+	}
+	sb.WriteString(clearToBottom)
+	sb.WriteString(strings.Join(lines, "\r\n"))
+	if v.input == "" {
+		// Park the cursor after the prompt, on the placeholder
+		sb.WriteString("\r\x1b[" + fmt.Sprint(layout.Width(inputPrompt)) + "C")
+	}
+	fmt.Fprint(v.out, sb.String())
+
+	cols := term.Width(v.out)
+	v.rows = 0
+	for _, line := range lines {
+		v.rows += max(1, (layout.Width(line)+cols-1)/cols)
+	}
+}
+
+// formatDuration formats seconds as MM:SS
+func formatDuration(seconds int) string {
+	minutes := seconds / 60
+	secs := seconds % 60
+	return fmt.Sprintf("%02d:%02d", minutes, secs)
+}
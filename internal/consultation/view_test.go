@@ -0,0 +1,48 @@
+package consultation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestView_RedrawsRegion(t *testing.T) {
+	var out bytes.Buffer
+	v := &view{out: &out, live: true}
+
+	v.setCountdown(60, false, true)
+	v.setInput("first\nsecond")
+	out.Reset()
+
+	// The countdown redraws over the status line and both response lines
+	v.setCountdown(59, false, true)
+	got := out.String()
+	if !strings.HasPrefix(got, "\r"+strings.Repeat(cursorUp, 2)+clearToBottom) {
+		t.Errorf("redraw starts %q, want to move up over the 3-row region", got)
+	}
+	if !strings.Contains(got, "00:59") || !strings.HasSuffix(got, "  second") {
+		t.Errorf("redraw = %q, want the timer then the response", got)
+	}
+
+	v.close()
+	out.Reset()
+	v.setInput("ignored")
+	if out.Len() != 0 {
+		t.Errorf("closed view drew %q", out.String())
+	}
+}
+
+func TestView_NotLive(t *testing.T) {
+	var out bytes.Buffer
+	v := newView(&out)
+
+	v.setCountdown(20, false, true)
+	v.setInput("typing")
+	v.setCountdown(10, true, true)
+	v.setCountdown(9, true, true)
+	v.close()
+
+	if got := out.String(); strings.Count(got, "AI RESPONSE IN") != 1 || strings.Contains(got, "\r") {
+		t.Errorf("output = %q, want the warning printed once as a plain line", got)
+	}
+}
@@ -37,6 +37,7 @@ type Options struct {
 	MaxTodoFiles   int
 	MaxSiblingFiles int
 	MaxPlanTasks   int
+	CacheDir       string // reuse the file index persisted here; empty rescans every file
 }
 
 func DefaultOptions() Options {
@@ -47,6 +48,7 @@ func DefaultOptions() Options {
 		MaxTodoFiles:   6,
 		MaxSiblingFiles: 8,
 		MaxPlanTasks:   10,
+		CacheDir:       index.DefaultCacheDir(),
 	}
 }
 
@@ -90,10 +92,10 @@ func BuildSummary(ctx context.Context, targetPath string, instruction string, op
 		root = filepath.Dir(absPath)
 	}
 
-	idx, err := index.Build(ctx, root, index.Options{
+	idx, err := index.BuildCached(ctx, root, index.Options{
 		MaxFileSize:   opts.MaxFileSize,
 		IncludeHidden: false,
-	})
+	}, opts.CacheDir)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +131,7 @@ func BuildSummary(ctx context.Context, targetPath string, instruction string, op
 		MaxFiles:     opts.MaxTopFiles,
 		MaxFileSize:  opts.MaxFileSize,
 		IncludeHidden: false,
+		CacheDir:     opts.CacheDir,
 	})
 	if err != nil {
 		plan = &planner.Plan{}
@@ -3,23 +3,12 @@ package context
 import (
 	"strings"
 
-	"github.com/pkoukk/tiktoken-go"
+	"github.com/croberts/obot/internal/tokens"
 )
 
-// CountTokens counts tokens using the cl100k_base encoding (tiktoken).
+// CountTokens counts tokens with the shared local tokenizer.
 func CountTokens(text string) int {
-	if text == "" {
-		return 0
-	}
-
-	tke, err := tiktoken.GetEncoding("cl100k_base")
-	if err != nil {
-		// Fallback to heuristic if tiktoken fails
-		return (len(text) + 3) / 4
-	}
-
-	tokens := tke.Encode(text, nil, nil)
-	return len(tokens)
+	return tokens.Count(text)
 }
 
 // CountTokensLines counts tokens across multiple lines.
@@ -27,27 +16,7 @@ func CountTokensLines(lines []string) int {
 	return CountTokens(strings.Join(lines, "\n"))
 }
 
-// TruncateToTokens truncates text to approximately maxTokens.
+// TruncateToTokens truncates text to at most maxTokens tokens.
 func TruncateToTokens(text string, maxTokens int) string {
-	if maxTokens <= 0 {
-		return ""
-	}
-
-	tke, err := tiktoken.GetEncoding("cl100k_base")
-	if err != nil {
-		// Fallback to heuristic
-		maxChars := maxTokens * 4
-		if len(text) <= maxChars {
-			return text
-		}
-		return text[:maxChars]
-	}
-
-	tokens := tke.Encode(text, nil, nil)
-	if len(tokens) <= maxTokens {
-		return text
-	}
-
-	truncatedTokens := tokens[:maxTokens]
-	return tke.Decode(truncatedTokens)
+	return tokens.Truncate(text, maxTokens)
 }
@@ -65,6 +65,16 @@ func (h *Handler) executeDelegation(ctx context.Context, modelType orchestrate.M
 	// Log or track delegation start
 	h.mu.Unlock()
 
+	role, err := h.coordinator.Acquire(ctx, modelType)
+	if err != nil {
+		return "", err
+	}
+	if role != modelType {
+		if fallback := h.coordinator.Get(role); fallback != nil {
+			modelType, client = role, fallback
+		}
+	}
+
 	resp, stats, err := client.Generate(ctx, task)
 	if err != nil {
 		return "", err
@@ -0,0 +1,135 @@
+// Package errs adapts errors from other modules onto the error registry.
+package errs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/session"
+)
+
+// New creates an OrchestrationError for a registry code. Severity,
+// recoverability, and the suggested solution come from the registry.
+func New(code ErrorCode, component, message string, state FrozenState) *OrchestrationError {
+	severity := SeveritySystem
+	switch impact := GetImpact(code); {
+	case impact >= ImpactCritical:
+		severity = SeverityCritical
+	case impact <= ImpactMedium:
+		severity = SeverityWarning
+	}
+
+	var solutions []string
+	if meta, ok := GetMetadata(code); ok {
+		solutions = []string{meta.ActionHint}
+	}
+
+	return &OrchestrationError{
+		Code:        code,
+		Severity:    severity,
+		Component:   component,
+		Message:     message,
+		Timestamp:   time.Now(),
+		State:       state,
+		Solutions:   solutions,
+		Recoverable: IsRecoverable(code),
+	}
+}
+
+// ToOrchestrationError converts an AppError into an OrchestrationError.
+func (e *AppError) ToOrchestrationError(component string, state FrozenState) *OrchestrationError {
+	oe := New(e.Code, component, e.Message, state)
+	oe.Cause = e
+	return oe
+}
+
+// CodeOf returns the registry code for err. It recognizes OrchestrationError
+// and AppError as well as the typed errors of other modules.
+func CodeOf(err error) (ErrorCode, bool) {
+	if err == nil {
+		return "", false
+	}
+
+	var oe *OrchestrationError
+	if errors.As(err, &oe) {
+		return oe.Code, true
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code, true
+	}
+
+	var navErr *orchestrate.NavigationError
+	var navValErr *orchestrate.NavigationValidationError
+	var budgetErr *agent.BudgetExceededError
+	var loopErr *agent.LoopDetectedError
+	var lockErr *session.WorkspaceLockedError
+	var policyErr *agent.PolicyError
+	var pathErr *os.PathError
+	switch {
+	case errors.As(err, &navErr), errors.As(err, &navValErr):
+		return ErrInvalidTransition, true
+	case errors.As(err, &lockErr):
+		return ErrConcurrentNavigation, true
+	case errors.As(err, &policyErr):
+		return ErrForbiddenAction, true
+	case errors.As(err, &loopErr):
+		return ErrCircularNavigation, true
+	case errors.As(err, &budgetErr):
+		return ErrResourceExhausted, true
+	case errors.Is(err, ollama.ErrNotInstalled):
+		return ErrOllamaUnavailable, true
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ollama.ErrStalled):
+		return ErrNetworkTimeout, true
+	case errors.As(err, &pathErr):
+		return ErrFileSystemAccess, true
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "connection refused") || strings.Contains(msg, "no such host"):
+		return ErrOllamaUnavailable, true
+	case strings.Contains(msg, "model") && strings.Contains(msg, "not found"):
+		return ErrModelNotFound, true
+	}
+	return "", false
+}
+
+// Is reports whether err maps onto the given registry code.
+func Is(err error, code ErrorCode) bool {
+	c, ok := CodeOf(err)
+	return ok && c == code
+}
+
+// NewProcessError classifies an error returned while executing a process into
+// an OrchestrationError suitable for the suspension handler. Errors that are
+// already OrchestrationErrors are returned as a copy with the given state
+// attached, leaving the original, which others may hold, unchanged;
+// unclassified errors are reported as state mismatches.
+func NewProcessError(err error, component string, state FrozenState) *OrchestrationError {
+	var existing *OrchestrationError
+	if errors.As(err, &existing) {
+		oe := *existing
+		if oe.State.IsZero() {
+			oe.State = state
+		}
+		oe.Solutions = append([]string(nil), existing.Solutions...)
+		return &oe
+	}
+
+	code, ok := CodeOf(err)
+	if !ok {
+		code = ErrStateMismatch
+	}
+
+	oe := New(code, component, err.Error(), state)
+	oe.Rule = "Process must complete without error"
+	oe.Cause = err
+	return oe
+}
@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/layout"
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 // SuspensionAction represents the user's choice after a suspension.
@@ -27,8 +30,19 @@ type SessionInterface interface {
 	GetFlowCode() string
 }
 
+// analysisTimeout bounds the LLM error analysis so a slow model cannot block the suspension UI.
+const analysisTimeout = 45 * time.Second
+
+// Analysis sources reported in ErrorAnalysis.Source.
+const (
+	AnalysisHardcoded = "hardcoded"
+	AnalysisLLM       = "llm"
+	AnalysisFallback  = "fallback"
+)
+
 // ErrorAnalysis contains the LLM-generated analysis of an error.
 type ErrorAnalysis struct {
+	Source            string
 	WhatHappened      string
 	WhichComponent    string
 	RuleViolated      string
@@ -40,16 +54,21 @@ type ErrorAnalysis struct {
 // SuspensionHandler manages system suspension when a critical error occurs.
 type SuspensionHandler struct {
 	writer  io.Writer
-	reader  io.Reader
+	reader  *bufio.Reader
 	aiModel *ollama.Client
 	session SessionInterface
 }
 
 // NewSuspensionHandler creates a new suspension handler.
+// Pass a *bufio.Reader to share buffered input with other prompts.
 func NewSuspensionHandler(w io.Writer, r io.Reader, model *ollama.Client, session SessionInterface) *SuspensionHandler {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
 	return &SuspensionHandler{
 		writer:  w,
-		reader:  r,
+		reader:  br,
 		aiModel: model,
 		session: session,
 	}
@@ -57,9 +76,14 @@ func NewSuspensionHandler(w io.Writer, r io.Reader, model *ollama.Client, sessio
 
 // Handle processes an orchestration error, displaying UI and waiting for user action.
 func (h *SuspensionHandler) Handle(err *OrchestrationError) SuspensionAction {
+	return h.HandleContext(context.Background(), err)
+}
+
+// HandleContext is Handle with a context bounding the LLM error analysis.
+func (h *SuspensionHandler) HandleContext(ctx context.Context, err *OrchestrationError) SuspensionAction {
 	h.displaySuspension(err)
 
-	analysis := h.analyzeError(err)
+	analysis := h.analyzeError(ctx, err)
 	h.displayAnalysis(analysis)
 
 	h.displaySolutions(analysis.ProposedSolutions)
@@ -69,28 +93,26 @@ func (h *SuspensionHandler) Handle(err *OrchestrationError) SuspensionAction {
 
 // displaySuspension renders the primary suspension box UI.
 func (h *SuspensionHandler) displaySuspension(err *OrchestrationError) {
-	var sb strings.Builder
-	sb.WriteString("\n")
-	sb.WriteString("┌─────────────────────────────────────────────────────────────────────┐\n")
-	sb.WriteString("│ Orchestrator • SUSPENDED                                            │\n")
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(fmt.Sprintf("│ ERROR CODE: %-55s │\n", err.Code))
-	sb.WriteString(fmt.Sprintf("│ MESSAGE:    %-55s │\n", h.truncate(err.Message, 55)))
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString("│ FROZEN STATE:                                                       │\n")
-	sb.WriteString(fmt.Sprintf("│   Schedule:   %-53s │\n", err.State.Schedule))
-	sb.WriteString(fmt.Sprintf("│   Process:    %-53s │\n", err.State.Process))
-	sb.WriteString(fmt.Sprintf("│   LastAction: %-53s │\n", err.State.LastAction))
-	sb.WriteString(fmt.Sprintf("│   Flow Code:  %-53s │\n", h.formatFlowCodeWithError(err.State.FlowCode)))
-	sb.WriteString("└─────────────────────────────────────────────────────────────────────┘\n")
-
-	fmt.Fprint(h.writer, sb.String())
+	box := layout.NewBox(term.BoxWidth(h.writer))
+	box.Line("Orchestrator • SUSPENDED")
+	box.Divider()
+	box.Linef("ERROR CODE: %s", err.Code)
+	box.Clipped(fmt.Sprintf("MESSAGE:    %s", err.Message))
+	box.Blank()
+	box.Line("FROZEN STATE:")
+	box.Linef("  Schedule:   %s", err.State.Schedule)
+	box.Linef("  Process:    %s", err.State.Process)
+	box.Clipped(fmt.Sprintf("  LastAction: %s", err.State.LastAction))
+	box.Clipped(fmt.Sprintf("  Flow Code:  %s", h.formatFlowCodeWithError(err.State.FlowCode)))
+
+	fmt.Fprint(h.writer, "\n"+box.String())
 }
 
 // analyzeError performs an LLM-based analysis or returns hardcoded analysis.
-func (h *SuspensionHandler) analyzeError(err *OrchestrationError) ErrorAnalysis {
+func (h *SuspensionHandler) analyzeError(ctx context.Context, err *OrchestrationError) ErrorAnalysis {
 	if IsHardcoded(err.Code) {
 		return ErrorAnalysis{
+			Source:            AnalysisHardcoded,
 			WhatHappened:      GetHardcodedMessage(err.Code),
 			WhichComponent:    err.Component,
 			RuleViolated:      err.Rule,
@@ -100,6 +122,7 @@ func (h *SuspensionHandler) analyzeError(err *OrchestrationError) ErrorAnalysis
 
 	if h.aiModel == nil {
 		return ErrorAnalysis{
+			Source:            AnalysisFallback,
 			WhatHappened:      err.Message,
 			WhichComponent:    err.Component,
 			RuleViolated:      err.Rule,
@@ -109,12 +132,43 @@ func (h *SuspensionHandler) analyzeError(err *OrchestrationError) ErrorAnalysis
 		}
 	}
 
-	prompt := fmt.Sprintf(`Analyze the following orchestration error and provide a structured analysis.
-Error Code: %s
+	ctx, cancel := context.WithTimeout(ctx, analysisTimeout)
+	defer cancel()
+
+	response, _, llmErr := h.aiModel.Generate(ctx, h.analysisPrompt(err))
+	if llmErr != nil {
+		return ErrorAnalysis{
+			Source:            AnalysisFallback,
+			WhatHappened:      err.Message,
+			WhichComponent:    err.Component,
+			RuleViolated:      err.Rule,
+			RootCause:         fmt.Sprintf("LLM analysis failed: %v", llmErr),
+			ProposedSolutions: err.Solutions,
+		}
+	}
+
+	analysis := h.parseAnalysis(response, err)
+	analysis.Source = AnalysisLLM
+	return analysis
+}
+
+// analysisPrompt builds the orchestrator-model prompt for a concise root-cause analysis.
+func (h *SuspensionHandler) analysisPrompt(err *OrchestrationError) string {
+	recent := "none"
+	if len(err.State.RecentActions) > 0 {
+		recent = "\n- " + strings.Join(err.State.RecentActions, "\n- ")
+	}
+	if err.Cause != nil && err.Cause.Error() != err.Message {
+		recent += "\nUnderlying cause: " + err.Cause.Error()
+	}
+
+	return fmt.Sprintf(`Analyze the following orchestration error. Be concise: one sentence per field and at most 3 list items.
+Error Code: %s (%s)
 Message: %s
 Component: %s
 Rule: %s
 State: Schedule=%s, Process=%s, LastAction=%s, FlowCode=%s
+Recent actions: %s
 
 Format your response exactly as follows:
 WHAT_HAPPENED: <description>
@@ -123,22 +177,10 @@ FACTORS:
 - <factor 1>
 - <factor 2>
 PROPOSED_SOLUTIONS:
-- <solution 1>
-- <solution 2>
-`, err.Code, err.Message, err.Component, err.Rule, err.State.Schedule, err.State.Process, err.State.LastAction, err.State.FlowCode)
-
-	response, _, llmErr := h.aiModel.Generate(context.Background(), prompt)
-	if llmErr != nil {
-		return ErrorAnalysis{
-			WhatHappened:      err.Message,
-			WhichComponent:    err.Component,
-			RuleViolated:      err.Rule,
-			RootCause:         fmt.Sprintf("LLM analysis failed: %v", llmErr),
-			ProposedSolutions: err.Solutions,
-		}
-	}
-
-	return h.parseAnalysis(response, err)
+- <remediation 1>
+- <remediation 2>
+`, err.Code, FormatError(err.Code), err.Message, err.Component, err.Rule,
+		err.State.Schedule, err.State.Process, err.State.LastAction, err.State.FlowCode, recent)
 }
 
 // parseAnalysis parses the LLM response into an ErrorAnalysis struct.
@@ -190,33 +232,36 @@ func (h *SuspensionHandler) parseAnalysis(response string, err *OrchestrationErr
 
 // displayAnalysis renders the error analysis box.
 func (h *SuspensionHandler) displayAnalysis(analysis ErrorAnalysis) {
-	var sb strings.Builder
-	sb.WriteString("\n")
-	sb.WriteString("┌─ ERROR ANALYSIS ────────────────────────────────────────────────────┐\n")
+	box := layout.NewBox(term.BoxWidth(h.writer))
+	switch analysis.Source {
+	case AnalysisLLM:
+		box.SetTitle("ERROR ANALYSIS (LLM)", layout.AlignLeft)
+	default:
+		box.SetTitle("ERROR ANALYSIS", layout.AlignLeft)
+	}
 
-	sb.WriteString("│ WHAT HAPPENED:                                                      │\n")
-	h.wrapAndPrint(&sb, analysis.WhatHappened, 67)
-	sb.WriteString("│                                                                     │\n")
+	box.Line("WHAT HAPPENED:")
+	box.Line(analysis.WhatHappened)
+	box.Blank()
 
 	if analysis.RootCause != "" {
-		sb.WriteString("│ ROOT CAUSE:                                                         │\n")
-		h.wrapAndPrint(&sb, analysis.RootCause, 67)
-		sb.WriteString("│                                                                     │\n")
+		box.Line("ROOT CAUSE:")
+		box.Line(analysis.RootCause)
+		box.Blank()
 	}
 
 	if len(analysis.Factors) > 0 {
-		sb.WriteString("│ CONTRIBUTING FACTORS:                                               │\n")
+		box.Line("CONTRIBUTING FACTORS:")
 		for _, factor := range analysis.Factors {
-			h.wrapAndPrint(&sb, "• "+factor, 67)
+			box.Line("• " + factor)
 		}
-		sb.WriteString("│                                                                     │\n")
+		box.Blank()
 	}
 
-	sb.WriteString(fmt.Sprintf("│ VIOLATED COMPONENT: %-47s │\n", analysis.WhichComponent))
-	sb.WriteString(fmt.Sprintf("│ RULE VIOLATED:      %-47s │\n", analysis.RuleViolated))
-	sb.WriteString("└─────────────────────────────────────────────────────────────────────┘\n")
+	box.Linef("VIOLATED COMPONENT: %s", analysis.WhichComponent)
+	box.Linef("RULE VIOLATED:      %s", analysis.RuleViolated)
 
-	fmt.Fprint(h.writer, sb.String())
+	fmt.Fprint(h.writer, "\n"+box.String())
 }
 
 // displaySolutions renders the solutions and action options.
@@ -239,9 +284,9 @@ func (h *SuspensionHandler) displaySolutions(solutions []string) {
 
 // waitForAction reads a single character from stdin to determine the user's choice.
 func (h *SuspensionHandler) waitForAction() SuspensionAction {
-	scanner := bufio.NewScanner(h.reader)
-	for scanner.Scan() {
-		input := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+	for {
+		line, err := h.reader.ReadString('\n')
+		input := strings.ToUpper(strings.TrimSpace(line))
 		switch input {
 		case "R":
 			return ActionRetry
@@ -252,36 +297,14 @@ func (h *SuspensionHandler) waitForAction() SuspensionAction {
 		case "I":
 			return ActionInvestigate
 		}
+		if err != nil {
+			return ActionAbort // Default to abort on error
+		}
 		fmt.Fprint(h.writer, "Invalid option. Please select [R/S/A/I]: ")
 	}
-	return ActionAbort // Default to abort on error
 }
 
 // formatFlowCodeWithError appends a red X marker to the flow code and applies colors.
 func (h *SuspensionHandler) formatFlowCodeWithError(flowCode string) string {
 	return ui.FormatFlowCode(flowCode + "X")
 }
-
-// wrapAndPrint word-wraps text to fit a specific width within the box UI.
-func (h *SuspensionHandler) wrapAndPrint(sb *strings.Builder, text string, width int) {
-	words := strings.Fields(text)
-	line := "│ "
-	for _, word := range words {
-		if len(line)+len(word)+1 > width+2 {
-			sb.WriteString(line + strings.Repeat(" ", width+3-len(line)) + "│\n")
-			line = "│ "
-		}
-		line += word + " "
-	}
-	if len(line) > 2 {
-		sb.WriteString(line + strings.Repeat(" ", width+3-len(line)) + "│\n")
-	}
-}
-
-// truncate shortens a string to a maximum length with ellipsis.
-func (h *SuspensionHandler) truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
@@ -20,10 +20,16 @@ const (
 
 // FrozenState captures the state of the orchestrator when an error occurs.
 type FrozenState struct {
-	Schedule   string
-	Process    string
-	LastAction string
-	FlowCode   string
+	Schedule      string
+	Process       string
+	LastAction    string
+	FlowCode      string
+	RecentActions []string
+}
+
+// IsZero reports whether no state has been captured.
+func (s FrozenState) IsZero() bool {
+	return s.Schedule == "" && s.Process == "" && s.LastAction == "" && s.FlowCode == "" && len(s.RecentActions) == 0
 }
 
 // OrchestrationError represents a structured error in the orchestration flow.
@@ -37,12 +43,18 @@ type OrchestrationError struct {
 	State       FrozenState
 	Solutions   []string
 	Recoverable bool
+	Cause       error
 }
 
 func (e *OrchestrationError) Error() string {
 	return fmt.Sprintf("[%s] %s: %s", e.Code, e.Severity, e.Message)
 }
 
+// Unwrap returns the underlying cause of the error.
+func (e *OrchestrationError) Unwrap() error {
+	return e.Cause
+}
+
 const (
 	// --- Navigation Violations (E001-E009) ---
 
@@ -202,7 +214,7 @@ var Registry = map[ErrorCode]ErrorMetadata{
 		Description: "Multiple agents attempting concurrent navigation.",
 		Impact:      ImpactCritical,
 		Recoverable: false,
-		ActionHint:  "Implement locking or sequence the agent requests.",
+		ActionHint:  "Wait for the other run to finish, or pass --force to take over a stale workspace lock.",
 	},
 	ErrTargetNotFound: {
 		Code:        ErrTargetNotFound,
@@ -424,45 +436,21 @@ func GetActionHint(code ErrorCode) string {
 
 // NewNavigationError creates a new E001 navigation error.
 func NewNavigationError(message string, state FrozenState) *OrchestrationError {
-	return &OrchestrationError{
-		Code:        ErrInvalidTransition,
-		Severity:    SeverityWarning,
-		Component:   "Orchestrator",
-		Message:     message,
-		Rule:        "P1↔P2↔P3 navigation rule",
-		Timestamp:   time.Now(),
-		State:       state,
-		Solutions:   []string{"Follow 1-2-3 sequence", "Reset to P1"},
-		Recoverable: true,
-	}
+	oe := New(ErrInvalidTransition, "Orchestrator", message, state)
+	oe.Rule = "P1↔P2↔P3 navigation rule"
+	return oe
 }
 
 // NewOrchestratorViolationError creates a new error when orchestrator performs agent actions.
 func NewOrchestratorViolationError(message string, state FrozenState) *OrchestrationError {
-	return &OrchestrationError{
-		Code:        ErrForbiddenAction,
-		Severity:    SeverityCritical,
-		Component:   "Orchestrator",
-		Message:     message,
-		Rule:        "TOOLER violation: orchestrator cannot perform agent actions",
-		Timestamp:   time.Now(),
-		State:       state,
-		Solutions:   []string{"Delegate to Agent", "Refactor orchestration logic"},
-		Recoverable: false,
-	}
+	oe := New(ErrForbiddenAction, "Orchestrator", message, state)
+	oe.Rule = "TOOLER violation: orchestrator cannot perform agent actions"
+	return oe
 }
 
 // NewAgentViolationError creates a new error when agent performs orchestration tasks.
 func NewAgentViolationError(message string, state FrozenState) *OrchestrationError {
-	return &OrchestrationError{
-		Code:        ErrForbiddenAction,
-		Severity:    SeverityCritical,
-		Component:   "Agent",
-		Message:     message,
-		Rule:        "EXECUTOR violation: agent cannot perform orchestration decisions",
-		Timestamp:   time.Now(),
-		State:       state,
-		Solutions:   []string{"Limit agent scope", "Check system prompts"},
-		Recoverable: false,
-	}
+	oe := New(ErrForbiddenAction, "Agent", message, state)
+	oe.Rule = "EXECUTOR violation: agent cannot perform orchestration decisions"
+	return oe
 }
@@ -1,8 +1,20 @@
 package errs
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/session"
 )
 
 func TestRegistry_hasErrorCodes(t *testing.T) {
@@ -86,3 +98,139 @@ func TestGetMetadata(t *testing.T) {
 		t.Error("unknown code should return ok=false")
 	}
 }
+
+func TestNewProcessError(t *testing.T) {
+	state := FrozenState{Schedule: "Implement", Process: "P1 Implement", FlowCode: "S1P1"}
+
+	deadline := NewProcessError(fmt.Errorf("generate: %w", context.DeadlineExceeded), "Agent", state)
+	if deadline.Code != ErrNetworkTimeout {
+		t.Errorf("deadline code = %s, want %s", deadline.Code, ErrNetworkTimeout)
+	}
+	if !errors.Is(deadline, context.DeadlineExceeded) {
+		t.Error("process error should unwrap to its cause")
+	}
+	if deadline.State.FlowCode != state.FlowCode || deadline.State.Process != state.Process {
+		t.Errorf("state = %+v, want %+v", deadline.State, state)
+	}
+
+	refused := NewProcessError(errors.New("dial tcp: connection refused"), "Agent", state)
+	if refused.Code != ErrOllamaUnavailable {
+		t.Errorf("refused code = %s, want %s", refused.Code, ErrOllamaUnavailable)
+	}
+
+	existing := NewNavigationError("bad jump", FrozenState{})
+	got := NewProcessError(existing, "Agent", state)
+	if got.Code != existing.Code || got.Message != existing.Message || got.State.Schedule != state.Schedule {
+		t.Errorf("existing OrchestrationError = %+v, want it with state attached", got)
+	}
+	if !existing.State.IsZero() {
+		t.Error("NewProcessError() changed the state of the error passed in")
+	}
+}
+
+func TestTypedConstructors_UseRegistry(t *testing.T) {
+	for _, oe := range []*OrchestrationError{
+		NewNavigationError("bad jump", FrozenState{}),
+		NewOrchestratorViolationError("edited a file", FrozenState{}),
+		NewAgentViolationError("chose a schedule", FrozenState{}),
+	} {
+		want := New(oe.Code, oe.Component, oe.Message, FrozenState{})
+		if oe.Severity != want.Severity || oe.Recoverable != want.Recoverable || !slices.Equal(oe.Solutions, want.Solutions) {
+			t.Errorf("%s = %+v, want the registry's severity, recoverability, and solutions", oe.Code, oe)
+		}
+		if oe.Rule == "" {
+			t.Errorf("%s has no rule", oe.Code)
+		}
+	}
+}
+
+func TestSuspensionHandler_waitForAction(t *testing.T) {
+	var out strings.Builder
+	h := NewSuspensionHandler(&out, strings.NewReader("x\ns\n"), nil, nil)
+	if got := h.waitForAction(); got != ActionSkip {
+		t.Errorf("waitForAction() = %s, want %s", got, ActionSkip)
+	}
+	if !strings.Contains(out.String(), "Invalid option") {
+		t.Error("expected invalid option prompt")
+	}
+	if got := h.waitForAction(); got != ActionAbort {
+		t.Errorf("waitForAction() at EOF = %s, want %s", got, ActionAbort)
+	}
+}
+
+func TestNew_usesRegistry(t *testing.T) {
+	oe := New(ErrResourceExhausted, "Monitor", "out of memory", FrozenState{})
+	meta, _ := GetMetadata(ErrResourceExhausted)
+	if oe.Recoverable != meta.Recoverable {
+		t.Errorf("Recoverable = %v, want %v", oe.Recoverable, meta.Recoverable)
+	}
+	if len(oe.Solutions) != 1 || oe.Solutions[0] != meta.ActionHint {
+		t.Errorf("Solutions = %v, want registry action hint", oe.Solutions)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"app error", Wrap(errors.New("x"), ErrGitConflict, "merge"), ErrGitConflict},
+		{"navigation", fmt.Errorf("select: %w", &orchestrate.NavigationError{From: 1, To: 3}), ErrInvalidTransition},
+		{"budget", &agent.BudgetExceededError{Reason: "too slow"}, ErrResourceExhausted},
+		{"loop", &agent.LoopDetectedError{Reason: "same edit"}, ErrCircularNavigation},
+		{"timeout", context.DeadlineExceeded, ErrNetworkTimeout},
+		{"ollama not installed", fmt.Errorf("start: %w", ollama.ErrNotInstalled), ErrOllamaUnavailable},
+		{"stalled model", fmt.Errorf("chat: %w", &ollama.StallError{Endpoint: "chat", Model: "m"}), ErrNetworkTimeout},
+		{"workspace lock", &session.WorkspaceLockedError{Workspace: "/work"}, ErrConcurrentNavigation},
+		{"policy", &agent.PolicyError{Check: agent.PolicyCheck{Action: &agent.Action{Type: agent.ActionDeleteDir}}}, ErrForbiddenAction},
+	}
+	for _, tt := range tests {
+		got, ok := CodeOf(tt.err)
+		if !ok || got != tt.want {
+			t.Errorf("%s: CodeOf = %s, %v; want %s", tt.name, got, ok, tt.want)
+		}
+		if !Is(tt.err, tt.want) {
+			t.Errorf("%s: Is(%s) = false", tt.name, tt.want)
+		}
+	}
+
+	if _, ok := CodeOf(errors.New("something odd")); ok {
+		t.Error("unclassified error should have no code")
+	}
+}
+
+func TestSuspensionHandler_LLMAnalysis(t *testing.T) {
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"response": "WHAT_HAPPENED: Tests kept failing\nROOT_CAUSE: Missing import\nPROPOSED_SOLUTIONS:\n- Add the import",
+			"done":     true,
+		})
+	}))
+	defer srv.Close()
+
+	var out strings.Builder
+	h := NewSuspensionHandler(&out, strings.NewReader("a\n"), ollama.NewClient(ollama.WithBaseURL(srv.URL)), nil)
+	oe := New(ErrStateMismatch, "Agent", "go test failed", FrozenState{
+		Schedule:      "Implement",
+		RecentActions: []string{"Agent • Ran go test ./..."},
+	})
+
+	if got := h.HandleContext(context.Background(), oe); got != ActionAbort {
+		t.Errorf("HandleContext() = %s, want %s", got, ActionAbort)
+	}
+	if !strings.Contains(prompt, "Ran go test ./...") {
+		t.Error("analysis prompt should include recent actions")
+	}
+	for _, want := range []string{"ERROR ANALYSIS (LLM)", "Missing import", "Add the import"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package fswalk walks project trees with the exclusions shared by every
+// obot walker: hidden and well-known build directories, ignored extensions,
+// .gitignore and .obotignore patterns, binary files, and a size cap.
+package fswalk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/croberts/obot/internal/fsutil"
+)
+
+// SkipDir may be returned by a WalkFunc called for a directory to skip it.
+var SkipDir = filepath.SkipDir
+
+// Options controls which entries Walk visits. The zero value applies the
+// default directory and extension exclusions and the ignore files.
+type Options struct {
+	IncludeHidden bool
+	IgnoreDirs    map[string]struct{} // nil uses fsutil.DefaultIgnoreDirs
+	IgnoreExts    map[string]struct{} // nil uses fsutil.DefaultIgnoreExts
+	SkipPaths     []string            // absolute paths to skip entirely
+	NoIgnoreFiles bool                // do not apply .gitignore and .obotignore
+	Matcher       *Matcher            // ignore files to apply; nil reads root's
+	MaxFileSize   int64               // skip larger files; 0 disables the cap
+	SkipBinary    bool
+	IncludeDirs   bool // also call fn for directories below the root
+}
+
+// Entry is a file or directory visited by Walk.
+type Entry struct {
+	Path string      // path as passed to Walk joined with the relative path
+	Rel  string      // slash-separated path relative to the root
+	Info os.FileInfo // lstat information
+}
+
+// WalkFunc is called for each visited entry. Returning SkipDir for a
+// directory skips it; any other error stops the walk.
+type WalkFunc func(Entry) error
+
+// Walk visits the files below root that opts does not exclude, in lexical
+// order. If root is a file, only it is visited. Errors reading individual
+// entries are skipped.
+func Walk(root string, opts Options, fn WalkFunc) error {
+	if opts.IgnoreDirs == nil {
+		opts.IgnoreDirs = fsutil.DefaultIgnoreDirs
+	}
+	if opts.IgnoreExts == nil {
+		opts.IgnoreExts = fsutil.DefaultIgnoreExts
+	}
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		if abs, err := filepath.Abs(p); err == nil {
+			skip[abs] = true
+		}
+	}
+	var matcher *Matcher
+	if !opts.NoIgnoreFiles {
+		matcher = opts.Matcher
+		if matcher == nil {
+			matcher = NewMatcher(root)
+		}
+	}
+
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if path == root {
+				return walkErr
+			}
+			return nil
+		}
+		if path == root && d.IsDir() {
+			return nil
+		}
+		if len(skip) > 0 {
+			if abs, err := filepath.Abs(path); err == nil && skip[abs] {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		// A file root is visited by itself
+		rel := filepath.Base(path)
+		if path != root {
+			r, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			rel = filepath.ToSlash(r)
+		}
+
+		if d.IsDir() {
+			if fsutil.ShouldSkipDir(d.Name(), opts.IncludeHidden, opts.IgnoreDirs) || (matcher != nil && matcher.Ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			if !opts.IncludeDirs {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			return fn(Entry{Path: path, Rel: rel, Info: info})
+		}
+
+		if fsutil.ShouldSkipFile(d.Name(), opts.IncludeHidden, opts.IgnoreExts) || (matcher != nil && matcher.Ignored(rel, false)) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+			return nil
+		}
+		if opts.SkipBinary {
+			if isBinary, err := fsutil.IsBinaryFile(path); err != nil || isBinary {
+				return nil
+			}
+		}
+		return fn(Entry{Path: path, Rel: rel, Info: info})
+	})
+}
+
+// Allowed reports whether Walk would visit the file at the slash-separated
+// path rel with opts, apart from the size and binary checks: none of its
+// directories and not the file itself is excluded. A nil matcher skips the
+// ignore files.
+func Allowed(rel string, opts Options, matcher *Matcher) bool {
+	if opts.IgnoreDirs == nil {
+		opts.IgnoreDirs = fsutil.DefaultIgnoreDirs
+	}
+	if opts.IgnoreExts == nil {
+		opts.IgnoreExts = fsutil.DefaultIgnoreExts
+	}
+	dirs := strings.Split(rel, "/")
+	name := dirs[len(dirs)-1]
+	for i, dir := range dirs[:len(dirs)-1] {
+		if fsutil.ShouldSkipDir(dir, opts.IncludeHidden, opts.IgnoreDirs) {
+			return false
+		}
+		if matcher != nil && matcher.Ignored(strings.Join(dirs[:i+1], "/"), true) {
+			return false
+		}
+	}
+	if fsutil.ShouldSkipFile(name, opts.IncludeHidden, opts.IgnoreExts) {
+		return false
+	}
+	return matcher == nil || !matcher.Ignored(rel, false)
+}
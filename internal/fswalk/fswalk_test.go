@@ -0,0 +1,115 @@
+package fswalk
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func walkRels(t *testing.T, root string, opts Options) []string {
+	t.Helper()
+	var rels []string
+	err := Walk(root, opts, func(e Entry) error {
+		rels = append(rels, e.Rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	return rels
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":          "*.log\n!keep.log\ngen/\n/top.txt\ndocs/**/draft.md\n",
+		".obotignore":         "secrets/\n",
+		"main.go":             "package main\n",
+		"top.txt":             "x",
+		"a/top.txt":           "x",
+		"debug.log":           "x",
+		"keep.log":            "x",
+		"gen/out.go":          "x",
+		"secrets/key":         "x",
+		"docs/x/y/draft.md":   "x",
+		"docs/readme.md":      "x",
+		"pkg/.gitignore":      "local_*\n",
+		"pkg/local_a.go":      "x",
+		"pkg/lib.go":          "x",
+		"node_modules/m.js":   "x",
+		"logo.png":            "x",
+		"data.bin":            "a\x00b",
+		"big.txt":             "0123456789",
+		"sessions/state.json": "x",
+	})
+
+	got := walkRels(t, root, Options{SkipBinary: true, MaxFileSize: 5, SkipPaths: []string{filepath.Join(root, "sessions")}})
+	// main.go and big.txt exceed the size cap
+	want := []string{"a/top.txt", "docs/readme.md", "keep.log", "pkg/lib.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+
+	got = walkRels(t, root, Options{NoIgnoreFiles: true, IncludeHidden: true, IgnoreDirs: map[string]struct{}{}, IgnoreExts: map[string]struct{}{}})
+	if len(got) != 19 {
+		t.Errorf("Walk() without exclusions visited %d files, want 19: %v", len(got), got)
+	}
+}
+
+func TestWalk_DirsAndFileRoot(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a/b/c.go": "x", "a/skip/d.go": "x"})
+
+	var dirs []string
+	err := Walk(root, Options{IncludeDirs: true}, func(e Entry) error {
+		if e.Info.IsDir() {
+			dirs = append(dirs, e.Rel)
+			if e.Info.Name() == "skip" {
+				return SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(dirs, []string{"a", "a/b", "a/skip"}) {
+		t.Errorf("dirs = %v, %v", dirs, err)
+	}
+
+	if got := walkRels(t, filepath.Join(root, "a", "b", "c.go"), Options{}); !reflect.DeepEqual(got, []string{"c.go"}) {
+		t.Errorf("Walk(file) = %v", got)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{".gitignore": "gen/\n", "sub/.obotignore": "*.tmp\n"})
+	m := NewMatcher(root)
+
+	tests := map[string]bool{
+		"main.go":           true,
+		"gen/out.go":        false,
+		"sub/x.tmp":         false,
+		"x.tmp":             true,
+		"vendor/dep/dep.go": false,
+		".hidden/file.go":   false,
+		"sub/deeper/lib.go": true,
+	}
+	for rel, want := range tests {
+		if got := Allowed(rel, Options{}, m); got != want {
+			t.Errorf("Allowed(%q) = %t, want %t", rel, got, want)
+		}
+	}
+}
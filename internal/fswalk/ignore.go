@@ -0,0 +1,150 @@
+package fswalk
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFiles are the files whose gitignore-style patterns exclude paths
+// in their directory and below.
+var IgnoreFiles = []string{".gitignore", ".obotignore"}
+
+// rule is a single ignore pattern.
+type rule struct {
+	base     string // slash-separated directory of the ignore file, "" for the root
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool // pattern contains a slash and matches from base
+}
+
+// Matcher applies the .gitignore and .obotignore files of a tree. Ignore
+// files are read lazily, the first time a path below their directory is
+// checked.
+type Matcher struct {
+	root   string
+	rules  []rule
+	loaded map[string]bool
+}
+
+// NewMatcher creates a matcher for the tree at root.
+func NewMatcher(root string) *Matcher {
+	return &Matcher{root: root, loaded: make(map[string]bool)}
+}
+
+// Ignored reports whether the slash-separated path rel, relative to the
+// root, is excluded by an ignore file. The last matching pattern wins, so a
+// negated pattern can re-include a path.
+func (m *Matcher) Ignored(rel string, isDir bool) bool {
+	rel = strings.TrimPrefix(path.Clean("/"+rel), "/")
+	if rel == "" {
+		return false
+	}
+
+	// Load the ignore files of every ancestor, outermost first, so that
+	// deeper files take precedence
+	m.load("")
+	for i := range rel {
+		if rel[i] == '/' {
+			m.load(rel[:i])
+		}
+	}
+
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			sub = rel[len(r.base)+1:]
+		}
+		var match bool
+		if r.anchored {
+			match = matchSegments(strings.Split(r.pattern, "/"), strings.Split(sub, "/"))
+		} else {
+			match, _ = path.Match(r.pattern, path.Base(sub))
+		}
+		if match {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// load reads the ignore files of the directory rel once.
+func (m *Matcher) load(rel string) {
+	if m.loaded[rel] {
+		return
+	}
+	m.loaded[rel] = true
+	for _, name := range IgnoreFiles {
+		m.rules = append(m.rules, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(rel), name), rel)...)
+	}
+}
+
+// readIgnoreFile parses the patterns of an ignore file in directory base.
+func readIgnoreFile(file, base string) []rule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := rule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches any number of segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
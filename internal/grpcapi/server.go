@@ -0,0 +1,390 @@
+// Package grpcapi serves the obot gRPC API described by
+// proto/obot/v1/obot.proto, running orchestrations with pkg/obot.
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/croberts/obot/pkg/obot"
+	"github.com/croberts/obot/pkg/obot/obotv1"
+)
+
+// FinishedRunRetention is how long a finished run's events stay in memory
+// for StreamEvents; after it, GetSummary reads the saved session.
+const FinishedRunRetention = 10 * time.Minute
+
+// Server implements obotv1.ObotServiceServer. Runs outlive the calls that
+// start them and are kept, with their events, until FinishedRunRetention
+// after they finish.
+type Server struct {
+	obotv1.UnimplementedObotServiceServer
+
+	ollamaURL  string
+	sessionDir string
+
+	// dir is the server's own directory, resolved when it starts: the
+	// workspace of a run naming none, and what relative workspaces are
+	// under. Runs change the working directory, so it is not read again
+	dir string
+
+	// Workspaces runs may work in, with the directories under them, and
+	// the program deciding for the script strategy; both are the server's
+	// configuration, never a client's
+	workspaces     []string
+	strategyScript string
+	retention      time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	runs map[string]*run
+}
+
+// NewServer returns a server running orchestrations against the Ollama
+// server at ollamaURL and saving their sessions in sessionDir; empty uses
+// the defaults.
+func NewServer(ollamaURL, sessionDir string) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	dir, _ := filepath.Abs(".")
+	return &Server{
+		ollamaURL:  ollamaURL,
+		sessionDir: sessionDir,
+		dir:        dir,
+		ctx:        ctx,
+		cancel:     cancel,
+		retention:  FinishedRunRetention,
+		runs:       make(map[string]*run),
+	}
+}
+
+// AllowWorkspaces sets the directories runs may work in, with the
+// directories under them. Without any, StartRun refuses every run.
+func (s *Server) AllowWorkspaces(dirs ...string) error {
+	for _, dir := range dirs {
+		resolved, err := resolveDir(s.dir, dir)
+		if err != nil {
+			return fmt.Errorf("workspace %s: %w", dir, err)
+		}
+		s.workspaces = append(s.workspaces, resolved)
+	}
+	return nil
+}
+
+// SetStrategyScript sets the program deciding for runs with the script
+// strategy.
+func (s *Server) SetStrategyScript(path string) {
+	s.strategyScript = path
+}
+
+// resolveDir returns the absolute path of dir, relative to base, with
+// symlinks resolved.
+func resolveDir(base, dir string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(base, dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// allowedWorkspace returns the workspace a run asks for, or the server's
+// own directory when it names none, if it is within an allowed workspace.
+func (s *Server) allowedWorkspace(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	resolved, err := resolveDir(s.dir, dir)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, root := range s.workspaces {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", status.Errorf(codes.PermissionDenied, "workspace %s is not one the server allows", dir)
+}
+
+// TokenAuth returns server options requiring every call to carry token as
+// "authorization: Bearer <token>" metadata.
+func TokenAuth(token string) []grpc.ServerOption {
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			got, ok := strings.CutPrefix(v, "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// LoadOrCreateToken returns the token stored at path, creating a random
+// one readable only by the user when there is none.
+func LoadOrCreateToken(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	token := rand.Text()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Stop cancels the runs in progress.
+func (s *Server) Stop() {
+	s.cancel()
+}
+
+// run is a run the server started, with its events so far.
+type run struct {
+	o *obot.Orchestration
+
+	mu      sync.Mutex
+	events  []*obotv1.Event
+	changed chan struct{} // closed when events or done change
+	done    bool
+	result  *obot.Result
+	err     error
+}
+
+// follow records the run's events as they come and its result at the end.
+func (r *run) follow() {
+	for e := range r.o.Events() {
+		r.mu.Lock()
+		r.events = append(r.events, eventProto(e))
+		r.notifyLocked()
+		r.mu.Unlock()
+	}
+	res, err := r.o.Wait()
+	r.mu.Lock()
+	r.done, r.result, r.err = true, res, err
+	r.notifyLocked()
+	r.mu.Unlock()
+}
+
+func (r *run) notifyLocked() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (s *Server) StartRun(ctx context.Context, req *obotv1.StartRunRequest) (*obotv1.StartRunResponse, error) {
+	if req.GetPrompt() == "" {
+		return nil, status.Error(codes.InvalidArgument, "prompt is required")
+	}
+	if req.GetStrategyScript() != "" {
+		return nil, status.Error(codes.InvalidArgument, "strategy_script is server configuration; start obot serve with --strategy-script")
+	}
+	workspace, err := s.allowedWorkspace(req.GetWorkspace())
+	if err != nil {
+		return nil, err
+	}
+	cfg := obot.Config{
+		Prompt:     req.GetPrompt(),
+		Workspace:  workspace,
+		OllamaURL:  s.ollamaURL,
+		Models:     req.GetModels(),
+		Strategy:   req.GetStrategy(),
+		SessionDir: s.sessionDir,
+		Consult:    req.GetConsult(),
+	}
+	if cfg.Strategy == "script" {
+		cfg.StrategyScript = s.strategyScript
+	}
+	if d := req.GetConsultationTimeout(); d != nil {
+		cfg.ConsultationTimeout = d.AsDuration()
+	}
+	o, err := obot.Start(s.ctx, cfg)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	r := &run{o: o, changed: make(chan struct{})}
+	id := o.SessionID()
+	s.mu.Lock()
+	s.runs[id] = r
+	s.mu.Unlock()
+	go func() {
+		r.follow()
+		time.AfterFunc(s.retention, func() {
+			s.mu.Lock()
+			delete(s.runs, id)
+			s.mu.Unlock()
+		})
+	}()
+	return &obotv1.StartRunResponse{SessionId: o.SessionID()}, nil
+}
+
+func (s *Server) StreamEvents(req *obotv1.StreamEventsRequest, stream obotv1.ObotService_StreamEventsServer) error {
+	r, err := s.run(req.GetSessionId())
+	if err != nil {
+		return err
+	}
+	for sent := 0; ; {
+		r.mu.Lock()
+		events, done, changed := r.events[sent:], r.done, r.changed
+		r.mu.Unlock()
+		for _, e := range events {
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+		sent += len(events)
+		if done {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *Server) AnswerConsultation(ctx context.Context, req *obotv1.AnswerConsultationRequest) (*obotv1.AnswerConsultationResponse, error) {
+	r, err := s.run(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.o.Answer(req.GetConsultationId(), req.GetAnswer()); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &obotv1.AnswerConsultationResponse{}, nil
+}
+
+func (s *Server) GetSummary(ctx context.Context, req *obotv1.GetSummaryRequest) (*obotv1.Summary, error) {
+	id := req.GetSessionId()
+	s.mu.Lock()
+	r, ok := s.runs[id]
+	s.mu.Unlock()
+	if ok {
+		r.mu.Lock()
+		done, res, runErr := r.done, r.result, r.err
+		r.mu.Unlock()
+		if !done {
+			return &obotv1.Summary{SessionId: id, Status: "running", Consultation: consultationProto(r.o.Consultation())}, nil
+		}
+		sum := &obotv1.Summary{SessionId: id, Status: "completed"}
+		if res != nil {
+			sum.Prompt = res.Prompt
+			sum.FlowCode = res.FlowCode
+			sum.Tokens = res.Tokens
+			sum.Actions = int32(res.Actions)
+			sum.FilesChanged = res.FilesChanged
+			sum.Duration = durationpb.New(res.Duration)
+		}
+		if runErr != nil {
+			sum.Error = runErr.Error()
+			sum.Status = "failed"
+			if sess, err := obot.LoadSession(s.sessionDir, id); err == nil {
+				sum.Status = sess.Status
+			}
+		}
+		return sum, nil
+	}
+
+	// A session from before the server started, or from the CLI. Its ID
+	// names a directory under the session directory, so it may not reach
+	// outside it
+	if id == "" || id == "." || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session ID %q", id)
+	}
+	sess, err := obot.LoadSession(s.sessionDir, id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &obotv1.Summary{
+		SessionId: sess.ID,
+		Status:    sess.Status,
+		Prompt:    sess.Prompt,
+		FlowCode:  sess.FlowCode,
+		Duration:  durationpb.New(sess.UpdatedAt.Sub(sess.CreatedAt)),
+	}, nil
+}
+
+// run returns the run of the session with the given ID.
+func (s *Server) run(id string) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no run of session %q", id)
+	}
+	return r, nil
+}
+
+var eventTypes = map[obot.EventType]obotv1.EventType{
+	obot.EventScheduleStart:         obotv1.EventType_EVENT_TYPE_SCHEDULE_START,
+	obot.EventScheduleEnd:           obotv1.EventType_EVENT_TYPE_SCHEDULE_END,
+	obot.EventProcessStart:          obotv1.EventType_EVENT_TYPE_PROCESS_START,
+	obot.EventProcessEnd:            obotv1.EventType_EVENT_TYPE_PROCESS_END,
+	obot.EventAction:                obotv1.EventType_EVENT_TYPE_ACTION,
+	obot.EventError:                 obotv1.EventType_EVENT_TYPE_ERROR,
+	obot.EventPromptTerminated:      obotv1.EventType_EVENT_TYPE_PROMPT_TERMINATED,
+	obot.EventConsultationRequested: obotv1.EventType_EVENT_TYPE_CONSULTATION_REQUESTED,
+}
+
+func eventProto(e obot.Event) *obotv1.Event {
+	return &obotv1.Event{
+		Type:         eventTypes[e.Type],
+		Time:         timestamppb.New(e.Time),
+		Schedule:     e.Schedule,
+		Process:      e.Process,
+		Message:      e.Message,
+		Consultation: consultationProto(e.Consultation),
+	}
+}
+
+func consultationProto(c *obot.Consultation) *obotv1.Consultation {
+	if c == nil {
+		return nil
+	}
+	return &obotv1.Consultation{
+		Id:            c.ID,
+		Type:          c.Type,
+		Question:      c.Question,
+		Context:       c.Context,
+		Options:       c.Options,
+		DefaultAnswer: c.Default,
+	}
+}
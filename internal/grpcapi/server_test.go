@@ -0,0 +1,190 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/pkg/obot/obotv1"
+)
+
+// dial serves a Server whose models answer every request with "COMPLETE",
+// allowing runs in the returned workspace, and returns a client of it.
+func dial(t *testing.T, opts ...grpc.ServerOption) (obotv1.ObotServiceClient, string) {
+	t.Helper()
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: "COMPLETE", Done: true})
+	}))
+	t.Cleanup(model.Close)
+
+	lis := bufconn.Listen(1 << 20)
+	api := NewServer(model.URL, t.TempDir())
+	workspace := t.TempDir()
+	if err := api.AllowWorkspaces(workspace); err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(opts...)
+	obotv1.RegisterObotServiceServer(srv, api)
+	go srv.Serve(lis)
+	t.Cleanup(func() {
+		api.Stop()
+		srv.Stop()
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return obotv1.NewObotServiceClient(conn), workspace
+}
+
+func TestServer_Run(t *testing.T) {
+	ctx := context.Background()
+	client, workspace := dial(t)
+
+	started, err := client.StartRun(ctx, &obotv1.StartRunRequest{Prompt: "add a health check", Workspace: workspace, Consult: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := started.GetSessionId()
+
+	stream, err := client.StreamEvents(ctx, &obotv1.StreamEventsRequest{SessionId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last obotv1.EventType
+	consulted := 0
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		last = e.GetType()
+		if c := e.GetConsultation(); c != nil {
+			consulted++
+			if _, err := client.AnswerConsultation(ctx, &obotv1.AnswerConsultationRequest{SessionId: id, ConsultationId: c.GetId(), Answer: "approve"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if last != obotv1.EventType_EVENT_TYPE_PROMPT_TERMINATED || consulted != 1 {
+		t.Errorf("last event %v after %d consultations, want prompt_terminated after 1", last, consulted)
+	}
+
+	sum, err := client.GetSummary(ctx, &obotv1.GetSummaryRequest{SessionId: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.GetStatus() != "completed" || sum.GetFlowCode() == "" || sum.GetPrompt() != "add a health check" {
+		t.Errorf("summary = %v", sum)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	ctx := context.Background()
+	client, _ := dial(t)
+
+	if _, err := client.StartRun(ctx, &obotv1.StartRunRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StartRun() without a prompt error = %v, want InvalidArgument", err)
+	}
+	if _, err := client.GetSummary(ctx, &obotv1.GetSummaryRequest{SessionId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSummary() error = %v, want NotFound", err)
+	}
+	for _, id := range []string{"../sessions", "a/b", `..\b`, ""} {
+		if _, err := client.GetSummary(ctx, &obotv1.GetSummaryRequest{SessionId: id}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetSummary(%q) error = %v, want InvalidArgument", id, err)
+		}
+	}
+	_, err := client.AnswerConsultation(ctx, &obotv1.AnswerConsultationRequest{SessionId: "missing", ConsultationId: "C1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("AnswerConsultation() error = %v, want NotFound", err)
+	}
+}
+
+func TestServer_Security(t *testing.T) {
+	ctx := context.Background()
+	client, workspace := dial(t)
+
+	req := &obotv1.StartRunRequest{Prompt: "add a health check", Workspace: workspace, Strategy: "script", StrategyScript: "./decide.sh"}
+	if _, err := client.StartRun(ctx, req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("StartRun() with a strategy_script error = %v, want InvalidArgument", err)
+	}
+	req = &obotv1.StartRunRequest{Prompt: "add a health check", Workspace: t.TempDir()}
+	if _, err := client.StartRun(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("StartRun() outside the allowed workspaces error = %v, want PermissionDenied", err)
+	}
+	escape := filepath.Join(workspace, "escape")
+	if err := os.Symlink(t.TempDir(), escape); err != nil {
+		t.Fatal(err)
+	}
+	req.Workspace = escape
+	if _, err := client.StartRun(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("StartRun() through a symlink out of the workspace error = %v, want PermissionDenied", err)
+	}
+
+	client, _ = dial(t, TokenAuth("s3cret")...)
+	summary := &obotv1.GetSummaryRequest{SessionId: "missing"}
+	if _, err := client.GetSummary(ctx, summary); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetSummary() without a token error = %v, want Unauthenticated", err)
+	}
+	wrong := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer guess")
+	if _, err := client.GetSummary(wrong, summary); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("GetSummary() with a wrong token error = %v, want Unauthenticated", err)
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+	if _, err := client.GetSummary(authed, summary); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSummary() with the token error = %v, want NotFound", err)
+	}
+}
+
+func TestServer_AllowedWorkspace_ResolvedAtStart(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	t.Chdir(dir)
+	api := NewServer("", t.TempDir())
+	defer api.Stop()
+	if err := api.AllowWorkspaces(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run changes the working directory; the server's stays its own
+	t.Chdir(t.TempDir())
+	if got, err := api.allowedWorkspace(""); err != nil || got != dir {
+		t.Errorf("allowedWorkspace(\"\") = %q, %v; want the server's %q", got, err, dir)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := api.allowedWorkspace("sub"); err != nil || got != filepath.Join(dir, "sub") {
+		t.Errorf("allowedWorkspace(sub) = %q, %v; want it under the server's directory", got, err)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "serve.token")
+	token, err := LoadOrCreateToken(path)
+	if err != nil || token == "" {
+		t.Fatalf("LoadOrCreateToken() = %q, %v", token, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	if again, err := LoadOrCreateToken(path); err != nil || again != token {
+		t.Errorf("LoadOrCreateToken() again = %q, %v, want the stored %q", again, err, token)
+	}
+}
@@ -0,0 +1,242 @@
+// Package hooks runs the workspace's hook scripts around orchestration
+// processes and schedules, such as to snapshot a database before Implement
+// changes it or to warm caches before a benchmark.
+//
+// A hook is an executable file in the workspace's .obot/hooks directory
+// named for the point it runs at: pre_process, post_process, or
+// post_schedule. It runs in the workspace with the session, schedule, and
+// process in OBOT_* environment variables. A workspace's obot.yaml may give
+// a hook as a shell command instead, which runs the same way when there is
+// no script of its name. In an isolated run, hooks run in its container.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Hook names, the file names of the scripts
+const (
+	PreProcess   = "pre_process"
+	PostProcess  = "post_process"
+	PostSchedule = "post_schedule"
+)
+
+// DefaultTimeout bounds how long a hook may run.
+const DefaultTimeout = 5 * time.Minute
+
+// waitDelay is how long a killed hook's children may hold its output open.
+const waitDelay = 2 * time.Second
+
+// maxOutput is the most of a failed hook's output kept in its error.
+const maxOutput = 2000
+
+// Dir returns the hooks directory of workspace.
+func Dir(workspace string) string {
+	return filepath.Join(workspace, ".obot", "hooks")
+}
+
+// Env is what a hook is told about the point it runs at.
+type Env struct {
+	Schedule    string // schedule name, such as Implement
+	Process     string // process number in the schedule, 1 to 3; empty for post_schedule
+	ProcessName string // process name, such as Verify
+}
+
+// Names are the hook names, in the order they run around a process.
+var Names = []string{PreProcess, PostProcess, PostSchedule}
+
+// Runner runs the hooks of a workspace for a session.
+type Runner struct {
+	workspace string
+	sessionID string
+	timeout   time.Duration
+	commands  map[string]string // shell commands by hook name
+	noScripts bool              // run only the commands
+	pinned    map[string][]byte // script contents by hook name, once pinned
+
+	// wrap runs shell commands elsewhere, such as in a container in
+	// which the workspace is workdir; nil runs them on the host
+	wrap    func(command string) (name string, args []string)
+	workdir string
+}
+
+// NewRunner returns a runner of the hooks of workspace for sessionID.
+func NewRunner(workspace, sessionID string) *Runner {
+	return &Runner{workspace: workspace, sessionID: sessionID, timeout: DefaultTimeout}
+}
+
+// SetCommands sets shell commands to run as hooks, by hook name, where the
+// workspace has no script.
+func (r *Runner) SetCommands(commands map[string]string) {
+	r.commands = commands
+}
+
+// SetContainer runs the hooks through wrap, which returns the program and
+// arguments running a shell command in a container in which the workspace
+// is at workdir.
+func (r *Runner) SetContainer(wrap func(command string) (name string, args []string), workdir string) {
+	r.wrap = wrap
+	r.workdir = workdir
+}
+
+// Scripts returns the paths of the workspace's hook scripts.
+func (r *Runner) Scripts() []string {
+	var scripts []string
+	for _, name := range Names {
+		if path := r.Path(name); path != "" {
+			scripts = append(scripts, path)
+		}
+	}
+	return scripts
+}
+
+// Pin records the contents of the workspace's hook scripts as they are
+// now and returns their paths. From then on Fingerprint hashes those contents and Run refuses a
+// script that has changed since, or that was added after, so that what the
+// user trusted is what runs even when the run itself edits the hooks.
+func (r *Runner) Pin() ([]string, error) {
+	scripts := r.Scripts()
+	pinned := make(map[string][]byte)
+	for _, path := range scripts {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pinned[filepath.Base(path)] = data
+	}
+	r.pinned = pinned
+	return scripts, nil
+}
+
+// Fingerprint returns a hash of the names and contents of the workspace's
+// hook scripts, which changes when any of them does. Once the runner is
+// pinned, it hashes the pinned contents.
+func (r *Runner) Fingerprint() (string, error) {
+	h := sha256.New()
+	for _, path := range r.Scripts() {
+		name := filepath.Base(path)
+		data, ok := r.pinned[name]
+		if r.pinned == nil {
+			var err error
+			if data, err = os.ReadFile(path); err != nil {
+				return "", err
+			}
+		} else if !ok {
+			continue
+		}
+		fmt.Fprintf(h, "%s %d\n", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SkipScripts makes the runner ignore the workspace's scripts and run only
+// the commands, such as when the user does not trust the scripts.
+func (r *Runner) SkipScripts() {
+	r.noScripts = true
+}
+
+// Path returns the script of hook name, or "" when the workspace has none.
+func (r *Runner) Path(name string) string {
+	if r.noScripts {
+		return ""
+	}
+	path := filepath.Join(Dir(r.workspace), name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// Run runs hook name, if the workspace has it, and returns an error with
+// the end of its output when it fails or times out.
+func (r *Runner) Run(ctx context.Context, name string, env Env) error {
+	path := r.Path(name)
+	command := r.commands[name]
+	if path == "" && command == "" {
+		return nil
+	}
+	if path != "" {
+		if info, err := os.Stat(path); err == nil && info.Mode()&0111 == 0 {
+			return fmt.Errorf("hook %s is not executable", path)
+		}
+		if r.pinned != nil {
+			data, err := os.ReadFile(path)
+			if want, ok := r.pinned[name]; !ok || err != nil || !bytes.Equal(data, want) {
+				return fmt.Errorf("hook %s changed since it was trusted; not running it", path)
+			}
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	workspace := r.workspace
+	if r.wrap != nil {
+		workspace = r.workdir
+	}
+	vars := []string{
+		"OBOT_HOOK=" + name,
+		"OBOT_SESSION_ID=" + r.sessionID,
+		"OBOT_WORKSPACE=" + workspace,
+		"OBOT_SCHEDULE=" + env.Schedule,
+		"OBOT_PROCESS=" + env.Process,
+		"OBOT_PROCESS_NAME=" + env.ProcessName,
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case r.wrap != nil:
+		// The container does not see the host's environment, so the
+		// variables are set by the command itself
+		script := command
+		if path != "" {
+			script = shellQuote(r.workdir + "/.obot/hooks/" + name)
+		}
+		prefix := "cd " + shellQuote(r.workdir) + " && export"
+		for _, v := range vars {
+			k, val, _ := strings.Cut(v, "=")
+			prefix += " " + k + "=" + shellQuote(val)
+		}
+		program, args := r.wrap(prefix + " && " + script)
+		cmd = exec.CommandContext(ctx, program, args...)
+	case path != "":
+		cmd = exec.CommandContext(ctx, path)
+	default:
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = r.workspace
+	cmd.WaitDelay = waitDelay
+	cmd.Env = append(os.Environ(), vars...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("hook %s timed out after %s", name, r.timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(out.String())
+		if len(output) > maxOutput {
+			output = "..." + output[len(output)-maxOutput:]
+		}
+		if output != "" {
+			return fmt.Errorf("hook %s: %w: %s", name, err, output)
+		}
+		return fmt.Errorf("hook %s: %w", name, err)
+	}
+	return nil
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"context"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Plugin is an orchestrator plugin that runs pre_process before each
+// process, post_process after it, and post_schedule after each schedule.
+// The orchestrator waits for a hook to finish; a failed hook is reported to
+// onFailure and does not stop the run.
+type Plugin struct {
+	*orchestrate.BaseOrchestratorPlugin
+
+	runner    *Runner
+	onFailure func(error)
+}
+
+// NewPlugin returns a plugin running the hooks of runner, reporting the
+// failed ones to onFailure, which may be nil.
+func NewPlugin(runner *Runner, onFailure func(error)) *Plugin {
+	return &Plugin{
+		BaseOrchestratorPlugin: orchestrate.NewBaseOrchestratorPlugin("hooks"),
+		runner:                 runner,
+		onFailure:              onFailure,
+	}
+}
+
+// OnProcessStart runs pre_process.
+func (p *Plugin) OnProcessStart(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	return p.run(ctx, PreProcess, processEnv(scheduleID, processID))
+}
+
+// OnProcessEnd runs post_process.
+func (p *Plugin) OnProcessEnd(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	return p.run(ctx, PostProcess, processEnv(scheduleID, processID))
+}
+
+// OnScheduleEnd runs post_schedule.
+func (p *Plugin) OnScheduleEnd(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	return p.run(ctx, PostSchedule, Env{Schedule: scheduleID.String()})
+}
+
+func (p *Plugin) run(ctx context.Context, name string, env Env) error {
+	err := p.runner.Run(ctx, name, env)
+	if err != nil && p.onFailure != nil {
+		p.onFailure(err)
+	}
+	return err
+}
+
+// processEnv returns the environment of a hook run around a process.
+func processEnv(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) Env {
+	return Env{
+		Schedule:    scheduleID.String(),
+		Process:     processID.String(),
+		ProcessName: orchestrate.ProcessNames[scheduleID][processID],
+	}
+}
@@ -0,0 +1,246 @@
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/fsutil"
+	"github.com/croberts/obot/internal/fswalk"
+	"github.com/croberts/obot/internal/tools"
+)
+
+// DefaultCacheDir returns the directory where BuildCached persists indexes.
+func DefaultCacheDir() string {
+	return filepath.Join(config.GetConfigDir(), "cache", "index")
+}
+
+// cachedIndex is a persisted index of a directory inside a git repository,
+// keyed by the HEAD commit and the content hashes of the dirty files.
+type cachedIndex struct {
+	Root          string            `json:"root"`
+	Head          string            `json:"head"`
+	Dirty         map[string]string `json:"dirty"` // repository-relative path → content hash
+	MaxFileSize   int64             `json:"max_file_size"`
+	IncludeHidden bool              `json:"include_hidden"`
+	Index         *Index            `json:"index"`
+}
+
+// BuildCached builds the index of the directory root like Build, reusing
+// the index persisted under cacheDir by a previous call. When HEAD or the
+// dirty files changed since then, only the changed files are rescanned.
+// Outside a git repository, for a single file, with semantic indexing, or
+// with an empty cacheDir, it falls back to Build.
+func BuildCached(ctx context.Context, root string, opts Options, cacheDir string) (*Index, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	if cacheDir == "" || !info.IsDir() || opts.EnableSemantic {
+		return Build(ctx, absRoot, opts)
+	}
+	opts = withDefaults(opts)
+
+	top, err := tools.GitTopLevel(ctx, absRoot)
+	if err != nil {
+		return Build(ctx, absRoot, opts)
+	}
+	head, err := tools.GitHead(ctx, absRoot)
+	if err != nil {
+		return Build(ctx, absRoot, opts)
+	}
+	dirtyFiles, err := tools.GitDirtyFiles(ctx, absRoot)
+	if err != nil {
+		return Build(ctx, absRoot, opts)
+	}
+	dirty := make(map[string]string, len(dirtyFiles))
+	for _, p := range dirtyFiles {
+		dirty[p] = hashFile(filepath.Join(top, p))
+	}
+
+	path := filepath.Join(cacheDir, cacheName(absRoot))
+	cached := loadCachedIndex(path)
+	if cached == nil || cached.Root != absRoot || cached.MaxFileSize != opts.MaxFileSize || cached.IncludeHidden != opts.IncludeHidden {
+		idx, err := Build(ctx, absRoot, opts)
+		if err != nil {
+			return nil, err
+		}
+		saveCachedIndex(path, &cachedIndex{Root: absRoot, Head: head, Dirty: dirty, MaxFileSize: opts.MaxFileSize, IncludeHidden: opts.IncludeHidden, Index: idx})
+		return idx, nil
+	}
+
+	changed := make(map[string]bool)
+	if cached.Head != head {
+		files, err := tools.GitChangedFiles(ctx, absRoot, cached.Head, head)
+		if err != nil {
+			// The cached commit is gone, e.g. after a rebase
+			idx, err := Build(ctx, absRoot, opts)
+			if err != nil {
+				return nil, err
+			}
+			saveCachedIndex(path, &cachedIndex{Root: absRoot, Head: head, Dirty: dirty, MaxFileSize: opts.MaxFileSize, IncludeHidden: opts.IncludeHidden, Index: idx})
+			return idx, nil
+		}
+		for _, p := range files {
+			changed[p] = true
+		}
+	}
+	for p, h := range dirty {
+		if cached.Dirty[p] != h {
+			changed[p] = true
+		}
+	}
+	for p := range cached.Dirty {
+		if _, ok := dirty[p]; !ok {
+			changed[p] = true
+		}
+	}
+	if len(changed) == 0 && cached.Head == head {
+		return cached.Index, nil
+	}
+
+	// git reports paths under the resolved repository root
+	resolvedRoot := absRoot
+	if r, err := filepath.EvalSymlinks(absRoot); err == nil {
+		resolvedRoot = r
+	}
+	paths := make([]string, 0, len(changed))
+	for p := range changed {
+		if rel, err := filepath.Rel(resolvedRoot, filepath.Join(top, p)); err == nil && !strings.HasPrefix(rel, "..") {
+			paths = append(paths, filepath.Join(absRoot, rel))
+		}
+	}
+	cached.Index.Refresh(paths, opts)
+	cached.Head, cached.Dirty = head, dirty
+	saveCachedIndex(path, cached)
+	return cached.Index, nil
+}
+
+// Refresh rescans the given absolute paths, adding, updating, or removing
+// their entries. Paths outside the index root or excluded by opts are
+// removed from the index.
+func (idx *Index) Refresh(paths []string, opts Options) {
+	opts = withDefaults(opts)
+	matcher := fswalk.NewMatcher(idx.Root)
+	byPath := make(map[string]int, len(idx.Files))
+	for i, f := range idx.Files {
+		byPath[f.Path] = i
+	}
+	removed := make(map[string]bool)
+
+	for _, path := range paths {
+		meta, ok := idx.rescan(path, opts, matcher)
+		i, exists := byPath[path]
+		switch {
+		case ok && exists:
+			idx.Files[i] = meta
+		case ok:
+			byPath[path] = len(idx.Files)
+			idx.Files = append(idx.Files, meta)
+		case exists:
+			removed[path] = true
+		}
+	}
+
+	if len(removed) > 0 {
+		kept := idx.Files[:0]
+		for _, f := range idx.Files {
+			if !removed[f.Path] {
+				kept = append(kept, f)
+			}
+		}
+		idx.Files = kept
+	}
+	sort.Slice(idx.Files, func(i, j int) bool {
+		return idx.Files[i].RelPath < idx.Files[j].RelPath
+	})
+}
+
+// rescan scans path if Build would index it.
+func (idx *Index) rescan(path string, opts Options, matcher *fswalk.Matcher) (FileMeta, bool) {
+	rel, err := filepath.Rel(idx.Root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return FileMeta{}, false
+	}
+	if !fswalk.Allowed(filepath.ToSlash(rel), walkOptions(opts), matcher) {
+		return FileMeta{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > opts.MaxFileSize {
+		return FileMeta{}, false
+	}
+	if isBinary, err := fsutil.IsBinaryFile(path); err != nil || isBinary {
+		return FileMeta{}, false
+	}
+	meta, err := fileMeta(path, idx.Root, info)
+	return meta, err == nil
+}
+
+// withDefaults fills the zero fields of opts as Build does.
+func withDefaults(opts Options) Options {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultOptions().MaxFileSize
+	}
+	if opts.IgnoreDirs == nil {
+		opts.IgnoreDirs = fsutil.DefaultIgnoreDirs
+	}
+	if opts.IgnoreExts == nil {
+		opts.IgnoreExts = fsutil.DefaultIgnoreExts
+	}
+	return opts
+}
+
+// cacheName names the cache file of a root directory.
+func cacheName(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return hex.EncodeToString(sum[:8]) + ".json"
+}
+
+// hashFile returns the content hash of a file, or "deleted" if it cannot be read.
+func hashFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return "deleted"
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "deleted"
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func loadCachedIndex(path string) *cachedIndex {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var c cachedIndex
+	if err := json.Unmarshal(data, &c); err != nil || c.Index == nil {
+		return nil
+	}
+	return &c
+}
+
+// saveCachedIndex persists the index; a failure only costs a rebuild later.
+func saveCachedIndex(path string, c *cachedIndex) {
+	data, err := json.Marshal(c)
+	if err != nil || os.MkdirAll(filepath.Dir(path), 0755) != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
@@ -0,0 +1,94 @@
+package index
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestBuildCached_Incremental(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"util/str.go": "package util\n// TODO: trim\n",
+	})
+	git(t, root, "init", "-q")
+	git(t, root, "add", ".")
+	git(t, root, "commit", "-q", "-m", "init")
+
+	ctx := context.Background()
+	lines := func() map[string]int {
+		t.Helper()
+		idx, err := BuildCached(ctx, root, Options{}, cacheDir)
+		if err != nil {
+			t.Fatalf("BuildCached() error = %v", err)
+		}
+		m := make(map[string]int)
+		for _, f := range idx.Files {
+			m[f.RelPath] = f.Lines
+		}
+		return m
+	}
+
+	if got := lines(); len(got) != 2 || got["main.go"] != 3 {
+		t.Fatalf("initial index = %v", got)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 1 {
+		t.Fatalf("cache dir has %d entries, want 1", len(entries))
+	}
+
+	// Dirty, untracked, and deleted files are picked up
+	writeFiles(t, root, map[string]string{
+		"main.go":    "package main\n\nfunc main() {\n\tprintln()\n}\n",
+		"new/new.go": "package new\n",
+	})
+	if err := os.Remove(filepath.Join(root, "util", "str.go")); err != nil {
+		t.Fatal(err)
+	}
+	got := lines()
+	if got["main.go"] != 5 || got["new/new.go"] != 1 || len(got) != 2 {
+		t.Errorf("index after edits = %v", got)
+	}
+
+	// Committing the edits keeps the index; reverting an edit restores it
+	git(t, root, "add", "-A")
+	git(t, root, "commit", "-q", "-m", "edit")
+	writeFiles(t, root, map[string]string{"main.go": "package main\n"})
+	if got := lines(); got["main.go"] != 1 {
+		t.Errorf("index with dirty main.go = %v", got)
+	}
+	git(t, root, "checkout", "--", "main.go")
+	if got := lines(); got["main.go"] != 5 || len(got) != 2 {
+		t.Errorf("index after checkout = %v", got)
+	}
+}
+
+func TestBuildCached_OutsideGit(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.go": "package a\n"})
+
+	idx, err := BuildCached(context.Background(), root, Options{}, cacheDir)
+	if err != nil || len(idx.Files) != 1 {
+		t.Fatalf("BuildCached() = %v, %v", idx, err)
+	}
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+		t.Errorf("no cache should be written outside a repository, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,302 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/croberts/obot/internal/fsutil"
+	"github.com/croberts/obot/internal/fswalk"
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// maxChunkChars bounds the size of a documentation chunk so that each chunk
+// fits the embedding model's context.
+const maxChunkChars = 2000
+
+// DocChunk is a piece of project documentation.
+type DocChunk struct {
+	Source string `json:"source"` // file path or package directory, relative to the root
+	Title  string `json:"title"`  // section heading or package name
+	Text   string `json:"text"`
+}
+
+// ID identifies the chunk within a DocIndex.
+func (c DocChunk) ID() string {
+	return c.Source + "#" + c.Title
+}
+
+// CollectDocs gathers the documentation of the project at root: Markdown and
+// text files in docs/, the top-level README, and for every Go package its
+// package comment and exported declarations, as `go doc` would print them.
+func CollectDocs(root string) ([]DocChunk, error) {
+	var chunks []DocChunk
+
+	if data, err := os.ReadFile(filepath.Join(root, "README.md")); err == nil {
+		chunks = append(chunks, splitMarkdown("README.md", string(data))...)
+	}
+
+	docsDir := filepath.Join(root, "docs")
+	if info, err := os.Stat(docsDir); err == nil && info.IsDir() {
+		err := fswalk.Walk(docsDir, fswalk.Options{}, func(e fswalk.Entry) error {
+			switch strings.ToLower(filepath.Ext(e.Path)) {
+			case ".md", ".markdown", ".txt", ".rst":
+			default:
+				return nil
+			}
+			data, err := os.ReadFile(e.Path)
+			if err != nil {
+				return nil
+			}
+			chunks = append(chunks, splitMarkdown(fsutil.RelPath(root, e.Path), string(data))...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pkgChunks, err := collectPackageDocs(root)
+	if err != nil {
+		return nil, err
+	}
+	return append(chunks, pkgChunks...), nil
+}
+
+// splitMarkdown splits a document into one chunk per heading section.
+func splitMarkdown(source, content string) []DocChunk {
+	var chunks []DocChunk
+	title := filepath.Base(source)
+	var section strings.Builder
+
+	flush := func() {
+		text := strings.TrimSpace(section.String())
+		section.Reset()
+		if text != "" {
+			chunks = append(chunks, splitChunk(DocChunk{Source: source, Title: title, Text: text})...)
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(trimmed, "#") {
+			flush()
+			if heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#")); heading != "" {
+				title = heading
+			}
+		}
+		section.WriteString(line)
+		section.WriteByte('\n')
+	}
+	flush()
+	return chunks
+}
+
+// splitChunk breaks a chunk longer than maxChunkChars at paragraph boundaries.
+func splitChunk(c DocChunk) []DocChunk {
+	if len(c.Text) <= maxChunkChars {
+		return []DocChunk{c}
+	}
+
+	var parts []string
+	var cur strings.Builder
+	for _, para := range strings.Split(c.Text, "\n\n") {
+		for len(para) > maxChunkChars {
+			if cur.Len() > 0 {
+				parts = append(parts, cur.String())
+				cur.Reset()
+			}
+			parts = append(parts, para[:maxChunkChars])
+			para = para[maxChunkChars:]
+		}
+		if cur.Len() > 0 && cur.Len()+len(para)+2 > maxChunkChars {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(para)
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+
+	chunks := make([]DocChunk, len(parts))
+	for i, p := range parts {
+		chunks[i] = DocChunk{Source: c.Source, Title: fmt.Sprintf("%s (%d/%d)", c.Title, i+1, len(parts)), Text: p}
+	}
+	return chunks
+}
+
+// collectPackageDocs returns a chunk for every documented Go package under root.
+func collectPackageDocs(root string) ([]DocChunk, error) {
+	var chunks []DocChunk
+	if c, ok := packageDoc(root, root); ok {
+		chunks = append(chunks, splitChunk(c)...)
+	}
+	err := fswalk.Walk(root, fswalk.Options{IncludeDirs: true}, func(e fswalk.Entry) error {
+		if !e.Info.IsDir() {
+			return nil
+		}
+		if e.Info.Name() == "testdata" {
+			return fswalk.SkipDir
+		}
+		if c, ok := packageDoc(root, e.Path); ok {
+			chunks = append(chunks, splitChunk(c)...)
+		}
+		return nil
+	})
+	return chunks, err
+}
+
+// packageDoc renders the documentation of the Go package in dir.
+func packageDoc(root, dir string) (DocChunk, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return DocChunk{}, false
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return DocChunk{}, false
+	}
+	pkg, err := doc.NewFromFiles(fset, files, fsutil.RelPath(root, dir))
+	if err != nil {
+		return DocChunk{}, false
+	}
+
+	var sb strings.Builder
+	if pkg.Doc != "" {
+		sb.WriteString(strings.TrimSpace(pkg.Doc))
+		sb.WriteString("\n\n")
+	}
+	writeDecl := func(kind, name, text string) {
+		if text = pkg.Synopsis(text); text != "" {
+			fmt.Fprintf(&sb, "%s %s: %s\n", kind, name, text)
+		}
+	}
+	for _, f := range pkg.Funcs {
+		writeDecl("func", f.Name, f.Doc)
+	}
+	for _, t := range pkg.Types {
+		writeDecl("type", t.Name, t.Doc)
+		for _, f := range t.Funcs {
+			writeDecl("func", f.Name, f.Doc)
+		}
+		for _, m := range t.Methods {
+			writeDecl("method", t.Name+"."+m.Name, m.Doc)
+		}
+	}
+	text := strings.TrimSpace(sb.String())
+	if text == "" {
+		return DocChunk{}, false
+	}
+	return DocChunk{Source: fsutil.RelPath(root, dir), Title: "package " + pkg.Name, Text: text}, true
+}
+
+// DocIndex is a semantic index of project documentation used to answer
+// questions about the codebase itself.
+type DocIndex struct {
+	semantic *SemanticIndex
+	chunks   map[string]DocChunk
+}
+
+// NewDocIndex creates an empty documentation index that embeds chunks with
+// model through client.
+func NewDocIndex(client *ollama.Client, model string) *DocIndex {
+	return &DocIndex{
+		semantic: NewSemanticIndex(client, model),
+		chunks:   make(map[string]DocChunk),
+	}
+}
+
+// Len returns the number of indexed chunks.
+func (d *DocIndex) Len() int {
+	return len(d.chunks)
+}
+
+// Ingest collects the documentation of the project at root and adds the
+// chunks not yet indexed. It returns the number of chunks added; an error is
+// returned only if no chunk could be embedded.
+func (d *DocIndex) Ingest(ctx context.Context, root string) (int, error) {
+	chunks, err := CollectDocs(root)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	var firstErr error
+	for _, c := range chunks {
+		if err := ctx.Err(); err != nil {
+			return added, err
+		}
+		id := c.ID()
+		if _, ok := d.chunks[id]; ok {
+			continue
+		}
+		if err := d.semantic.AddFile(ctx, id, c.Source+"\n"+c.Title+"\n\n"+c.Text); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		d.chunks[id] = c
+		added++
+	}
+	if added == 0 && firstErr != nil {
+		return 0, firstErr
+	}
+	return added, nil
+}
+
+// Retrieve returns up to limit chunks most relevant to query.
+func (d *DocIndex) Retrieve(ctx context.Context, query string, limit int) ([]DocChunk, error) {
+	ids, err := d.semantic.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DocChunk, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := d.chunks[id]; ok {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// Sources returns the distinct sources of the indexed chunks, sorted.
+func (d *DocIndex) Sources() []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, c := range d.chunks {
+		if !seen[c.Source] {
+			seen[c.Source] = true
+			sources = append(sources, c.Source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
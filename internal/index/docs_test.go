@@ -0,0 +1,160 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// newEmbeddingServer returns an Ollama stand-in whose embeddings are word
+// counts hashed into a small vector, so texts sharing words are similar.
+func newEmbeddingServer(t *testing.T) *ollama.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		vec := make([]float64, 64)
+		for _, word := range strings.Fields(strings.ToLower(req.Prompt)) {
+			h := fnv.New32a()
+			h.Write([]byte(strings.Trim(word, ".,:#()")))
+			vec[h.Sum32()%64]++
+		}
+		_ = json.NewEncoder(w).Encode(ollama.EmbeddingResponse{Embedding: vec})
+	}))
+	t.Cleanup(srv.Close)
+	return ollama.NewClient(ollama.WithBaseURL(srv.URL))
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollectDocs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md":             "# Project\nIntro.\n",
+		"docs/guide.md":         "# Setup\nInstall it.\n\n## Usage\nRun it.\n\n```sh\n# not a heading\n```\n",
+		"docs/logo.png":         "binary",
+		"pkg/limiter/lim.go":    "// Package limiter throttles requests.\npackage limiter\n\n// Wait blocks until a token is free.\nfunc Wait() {}\n\nfunc hidden() {}\n",
+		"pkg/limiter/x_test.go": "// Package limiter is tested.\npackage limiter\n",
+		"vendor/dep/dep.go":     "// Package dep is vendored.\npackage dep\n",
+	})
+
+	chunks, err := CollectDocs(root)
+	if err != nil {
+		t.Fatalf("CollectDocs() error = %v", err)
+	}
+	byID := make(map[string]DocChunk)
+	for _, c := range chunks {
+		byID[c.ID()] = c
+	}
+
+	for _, id := range []string{"README.md#Project", "docs/guide.md#Setup", "docs/guide.md#Usage", "pkg/limiter#package limiter"} {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("missing chunk %q; got %v", id, keys(byID))
+		}
+	}
+	if len(byID) != 4 {
+		t.Errorf("got %d chunks, want 4: %v", len(byID), keys(byID))
+	}
+	if usage := byID["docs/guide.md#Usage"].Text; !strings.Contains(usage, "# not a heading") {
+		t.Errorf("fenced code should stay in its section, got %q", usage)
+	}
+	pkg := byID["pkg/limiter#package limiter"].Text
+	if !strings.Contains(pkg, "throttles requests") || !strings.Contains(pkg, "func Wait: Wait blocks until a token is free.") {
+		t.Errorf("package doc = %q", pkg)
+	}
+	if strings.Contains(pkg, "hidden") || strings.Contains(pkg, "is tested") {
+		t.Errorf("package doc should omit unexported and test docs: %q", pkg)
+	}
+}
+
+func TestSplitChunk(t *testing.T) {
+	para := strings.Repeat("word ", 300) // 1500 chars
+	parts := splitChunk(DocChunk{Source: "a.md", Title: "Big", Text: para + "\n\n" + para})
+	if len(parts) != 2 {
+		t.Fatalf("splitChunk() = %d parts, want 2", len(parts))
+	}
+	for _, p := range parts {
+		if len(p.Text) > maxChunkChars {
+			t.Errorf("part %q has %d chars", p.Title, len(p.Text))
+		}
+	}
+	if parts[1].Title != "Big (2/2)" {
+		t.Errorf("title = %q", parts[1].Title)
+	}
+}
+
+func TestDocIndex_IngestRetrieve(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docs/limits.md": "# Rate limits\nRequests per minute are limited by the quota coordinator.\n",
+		"docs/themes.md": "# Themes\nColors and fonts for the terminal interface.\n",
+	})
+
+	docs := NewDocIndex(newEmbeddingServer(t), "")
+	n, err := docs.Ingest(context.Background(), root)
+	if err != nil || n != 2 {
+		t.Fatalf("Ingest() = %d, %v; want 2, nil", n, err)
+	}
+	if n, _ := docs.Ingest(context.Background(), root); n != 0 {
+		t.Errorf("second Ingest() added %d chunks, want 0", n)
+	}
+
+	got, err := docs.Retrieve(context.Background(), "how are requests per minute limited", 1)
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Source != "docs/limits.md" {
+		t.Errorf("Retrieve() = %+v, want docs/limits.md", got)
+	}
+	if sources := docs.Sources(); len(sources) != 2 {
+		t.Errorf("Sources() = %v", sources)
+	}
+}
+
+func TestDocIndex_IngestFailure(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"README.md": "# Project\nIntro.\n"})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	docs := NewDocIndex(ollama.NewClient(ollama.WithBaseURL(srv.URL)), "")
+	if _, err := docs.Ingest(context.Background(), root); err == nil {
+		t.Error("Ingest() should fail when no chunk can be embedded")
+	}
+	if docs.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", docs.Len())
+	}
+}
+
+func keys(m map[string]DocChunk) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/croberts/obot/internal/analyzer"
 	"github.com/croberts/obot/internal/config"
 	"github.com/croberts/obot/internal/fsutil"
+	"github.com/croberts/obot/internal/fswalk"
 	"github.com/croberts/obot/internal/ollama"
 )
 
@@ -138,69 +139,18 @@ func Build(ctx context.Context, root string, opts Options) (*Index, error) {
 		walkRoot = filepath.Dir(absRoot)
 	}
 
-	if opts.MaxFileSize <= 0 {
-		opts.MaxFileSize = DefaultOptions().MaxFileSize
-	}
-	if opts.IgnoreDirs == nil {
-		opts.IgnoreDirs = fsutil.DefaultIgnoreDirs
-	}
-	if opts.IgnoreExts == nil {
-		opts.IgnoreExts = fsutil.DefaultIgnoreExts
-	}
+	opts = withDefaults(opts)
 
+	// A single file is indexed by itself, relative to its directory
+	walkTarget := walkRoot
+	if targetFile != "" {
+		walkTarget = targetFile
+	}
 	files := make([]FileMeta, 0, 128)
-	err = filepath.WalkDir(walkRoot, func(path string, d os.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-
-		name := d.Name()
-		if d.IsDir() {
-			if fsutil.ShouldSkipDir(name, opts.IncludeHidden, opts.IgnoreDirs) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if targetFile != "" && path != targetFile {
-			return nil
-		}
-
-		if fsutil.ShouldSkipFile(name, opts.IncludeHidden, opts.IgnoreExts) {
-			return nil
-		}
-
-		info, err := d.Info()
-		if err != nil {
-			return nil
-		}
-		if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
-			return nil
-		}
-
-		isBinary, err := fsutil.IsBinaryFile(path)
-		if err != nil || isBinary {
-			return nil
-		}
-
-		lines, todoCount, fixmeCount, symbols, err := scanFile(path, walkRoot)
-		if err != nil {
-			return nil
-		}
-
-		fMeta := FileMeta{
-			Path:       path,
-			RelPath:    fsutil.RelPath(walkRoot, path),
-			SizeBytes:  info.Size(),
-			ModTime:    info.ModTime(),
-			Language:   analyzer.DetectLanguage(path),
-			Lines:      lines,
-			TodoCount:  todoCount,
-			FixmeCount: fixmeCount,
-			Symbols:    symbols,
+	err = fswalk.Walk(walkTarget, walkOptions(opts), func(e fswalk.Entry) error {
+		if fMeta, err := fileMeta(e.Path, walkRoot, e.Info); err == nil {
+			files = append(files, fMeta)
 		}
-		files = append(files, fMeta)
-
 		return nil
 	})
 	if err != nil {
@@ -231,6 +181,37 @@ func Build(ctx context.Context, root string, opts Options) (*Index, error) {
 	}, nil
 }
 
+// walkOptions returns the fswalk options that select the files to index.
+func walkOptions(opts Options) fswalk.Options {
+	return fswalk.Options{
+		IncludeHidden: opts.IncludeHidden,
+		IgnoreDirs:    opts.IgnoreDirs,
+		IgnoreExts:    opts.IgnoreExts,
+		MaxFileSize:   opts.MaxFileSize,
+		SkipBinary:    true,
+	}
+}
+
+// fileMeta scans a file for the index.
+func fileMeta(path, root string, info os.FileInfo) (FileMeta, error) {
+	lines, todoCount, fixmeCount, symbols, err := scanFile(path, root)
+	if err != nil {
+		return FileMeta{}, err
+	}
+
+	return FileMeta{
+		Path:       path,
+		RelPath:    fsutil.RelPath(root, path),
+		SizeBytes:  info.Size(),
+		ModTime:    info.ModTime(),
+		Language:   analyzer.DetectLanguage(path),
+		Lines:      lines,
+		TodoCount:  todoCount,
+		FixmeCount: fixmeCount,
+		Symbols:    symbols,
+	}, nil
+}
+
 func scanFile(path string, root string) (lines int, todoCount int, fixmeCount int, symbols []Symbol, err error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,360 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// maxTrigramFileSize bounds the files indexed for code search.
+const maxTrigramFileSize = 1024 * 1024
+
+// TrigramIndex is an in-memory trigram index of the text files under a root,
+// used for regex code search without external tools. It is built on the
+// first search and brought up to date before every search: files whose size
+// or modification time changed are re-indexed and deleted files dropped.
+// Only trigrams are kept in memory; candidate files are read to match lines.
+type TrigramIndex struct {
+	root string
+	opts fswalk.Options
+
+	mu       sync.Mutex
+	files    map[string]*trigramFile        // by slash-separated relative path
+	postings map[uint32]map[string]struct{} // trigram -> files containing it
+	reindex  int                            // files (re)indexed by the last refresh
+}
+
+// trigramFile is the indexed state of one file.
+type trigramFile struct {
+	size     int64
+	modTime  time.Time
+	trigrams []uint32
+}
+
+// SearchQuery describes a code search.
+type SearchQuery struct {
+	Pattern string // regular expression (RE2 syntax)
+	Literal bool   // match Pattern as a plain string
+
+	// Paths filters the searched files by glob. A glob without a slash
+	// matches the file name; one with a slash matches the path from the
+	// root, and a leading slash anchors it there. A trailing slash matches
+	// a directory's files. Globs prefixed with "!" exclude files. With only
+	// exclusions, every other file is searched.
+	Paths []string
+
+	MaxResults int // stop after this many matches; 0 means no limit
+}
+
+// LineMatch is a line matching a search.
+type LineMatch struct {
+	Path string // slash-separated path relative to the index root
+	Line int    // 1-based
+	Text string
+}
+
+// NewTrigramIndex creates an empty index of the files under root. Nothing is
+// read until the first Search.
+func NewTrigramIndex(root string) *TrigramIndex {
+	return &TrigramIndex{
+		root:     root,
+		opts:     fswalk.Options{MaxFileSize: maxTrigramFileSize, SkipBinary: true},
+		files:    make(map[string]*trigramFile),
+		postings: make(map[uint32]map[string]struct{}),
+	}
+}
+
+// Root returns the indexed directory.
+func (t *TrigramIndex) Root() string {
+	return t.root
+}
+
+// Len returns the number of indexed files.
+func (t *TrigramIndex) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.files)
+}
+
+// Search refreshes the index and returns the lines matching q, ordered by
+// path and line.
+func (t *TrigramIndex) Search(q SearchQuery) ([]LineMatch, error) {
+	pattern := q.Pattern
+	if q.Literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	required := requiredTrigrams(pattern)
+
+	t.mu.Lock()
+	if err := t.refresh(); err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	candidates := t.candidates(required)
+	t.mu.Unlock()
+
+	var matches []LineMatch
+	for _, rel := range candidates {
+		if !matchPaths(q.Paths, rel) {
+			continue
+		}
+		limit := 0
+		if q.MaxResults > 0 {
+			limit = q.MaxResults - len(matches)
+		}
+		matches = append(matches, grepFile(filepath.Join(t.root, filepath.FromSlash(rel)), rel, re, limit)...)
+		if q.MaxResults > 0 && len(matches) >= q.MaxResults {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// refresh re-indexes new and changed files and drops deleted ones. The
+// caller holds t.mu.
+func (t *TrigramIndex) refresh() error {
+	t.reindex = 0
+	seen := make(map[string]bool, len(t.files))
+	err := fswalk.Walk(t.root, t.opts, func(e fswalk.Entry) error {
+		seen[e.Rel] = true
+		if f, ok := t.files[e.Rel]; ok && f.size == e.Info.Size() && f.modTime.Equal(e.Info.ModTime()) {
+			return nil
+		}
+		data, err := os.ReadFile(e.Path)
+		if err != nil {
+			t.remove(e.Rel)
+			return nil
+		}
+		t.add(e.Rel, &trigramFile{size: e.Info.Size(), modTime: e.Info.ModTime(), trigrams: trigrams(data)})
+		t.reindex++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for rel := range t.files {
+		if !seen[rel] {
+			t.remove(rel)
+		}
+	}
+	return nil
+}
+
+// add indexes f as rel, replacing any previous version.
+func (t *TrigramIndex) add(rel string, f *trigramFile) {
+	t.remove(rel)
+	t.files[rel] = f
+	for _, tri := range f.trigrams {
+		set := t.postings[tri]
+		if set == nil {
+			set = make(map[string]struct{})
+			t.postings[tri] = set
+		}
+		set[rel] = struct{}{}
+	}
+}
+
+// remove drops rel from the index.
+func (t *TrigramIndex) remove(rel string) {
+	f, ok := t.files[rel]
+	if !ok {
+		return
+	}
+	delete(t.files, rel)
+	for _, tri := range f.trigrams {
+		if set := t.postings[tri]; set != nil {
+			delete(set, rel)
+			if len(set) == 0 {
+				delete(t.postings, tri)
+			}
+		}
+	}
+}
+
+// candidates returns the sorted files containing every required trigram,
+// or every file when none is required.
+func (t *TrigramIndex) candidates(required []uint32) []string {
+	var result []string
+	if len(required) == 0 {
+		for rel := range t.files {
+			result = append(result, rel)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	sets := make([]map[string]struct{}, 0, len(required))
+	for _, tri := range required {
+		set := t.postings[tri]
+		if len(set) == 0 {
+			return nil
+		}
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+
+	for rel := range sets[0] {
+		found := true
+		for _, set := range sets[1:] {
+			if _, ok := set[rel]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			result = append(result, rel)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// trigrams returns the distinct case-folded trigrams of data.
+func trigrams(data []byte) []uint32 {
+	data = bytes.ToLower(data)
+	seen := make(map[uint32]struct{})
+	for i := 0; i+3 <= len(data); i++ {
+		seen[trigramOf(data[i:i+3])] = struct{}{}
+	}
+	result := make([]uint32, 0, len(seen))
+	for tri := range seen {
+		result = append(result, tri)
+	}
+	return result
+}
+
+func trigramOf(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// requiredTrigrams returns the case-folded trigrams that any text matching
+// pattern must contain. Only literals every match must include are used, so
+// the result may be empty but never excludes a matching file.
+func requiredTrigrams(pattern string) []uint32 {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[uint32]struct{})
+	var result []uint32
+	for _, lit := range requiredLiterals(re.Simplify()) {
+		b := []byte(strings.ToLower(lit))
+		for i := 0; i+3 <= len(b); i++ {
+			tri := trigramOf(b[i : i+3])
+			if _, ok := seen[tri]; !ok {
+				seen[tri] = struct{}{}
+				result = append(result, tri)
+			}
+		}
+	}
+	return result
+}
+
+// requiredLiterals returns the literal strings every match of re contains.
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var result []string
+		var run []rune
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run = append(run, sub.Rune...)
+				continue
+			}
+			if len(run) > 0 {
+				result = append(result, string(run))
+				run = nil
+			}
+			result = append(result, requiredLiterals(sub)...)
+		}
+		if len(run) > 0 {
+			result = append(result, string(run))
+		}
+		return result
+	}
+	return nil
+}
+
+// matchPaths reports whether rel passes the path filters.
+func matchPaths(globs []string, rel string) bool {
+	included, hasIncludes := false, false
+	for _, g := range globs {
+		if g == "" {
+			continue
+		}
+		if exclude, ok := strings.CutPrefix(g, "!"); ok {
+			if matchPath(exclude, rel) {
+				return false
+			}
+			continue
+		}
+		hasIncludes = true
+		if matchPath(g, rel) {
+			included = true
+		}
+	}
+	return included || !hasIncludes
+}
+
+// matchPath reports whether rel matches a single glob as described on
+// SearchQuery.Paths.
+func matchPath(glob, rel string) bool {
+	glob = filepath.ToSlash(glob)
+	if dir, ok := strings.CutSuffix(glob, "/"); ok {
+		dir = strings.TrimPrefix(dir, "/")
+		return dir == "" || strings.HasPrefix(rel, dir+"/")
+	}
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(rel))
+		return ok
+	}
+	ok, _ := path.Match(strings.TrimPrefix(glob, "/"), rel)
+	return ok
+}
+
+// grepFile returns the lines of the file at p matching re, reported as rel.
+// A positive limit stops after that many matches.
+func grepFile(p, rel string, re *regexp.Regexp, limit int) []LineMatch {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var matches []LineMatch
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTrigramFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		if !re.Match(scanner.Bytes()) {
+			continue
+		}
+		matches = append(matches, LineMatch{Path: rel, Line: line, Text: strings.TrimSpace(scanner.Text())})
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,130 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrigramIndex_Search(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":          "package main\n\nfunc HandleRequest() {}\nfunc handleResponse() {}\n",
+		"internal/util.go": "package internal\n\n// handleRequest is unused.\nfunc helper() {}\n",
+		"docs/notes.md":    "HandleRequest is documented here.\n",
+		"ignored/skip.go":  "func HandleRequest() {}\n",
+		".gitignore":       "ignored/\n",
+	})
+	idx := NewTrigramIndex(root)
+
+	tests := []struct {
+		name  string
+		query SearchQuery
+		want  []string
+	}{
+		{"literal", SearchQuery{Pattern: "HandleRequest"}, []string{"docs/notes.md:1", "main.go:3"}},
+		{"regex", SearchQuery{Pattern: `func [hH]andle\w+\(`}, []string{"main.go:3", "main.go:4"}},
+		{"case insensitive", SearchQuery{Pattern: `(?i)handlerequest`}, []string{"docs/notes.md:1", "internal/util.go:3", "main.go:3"}},
+		{"no literal", SearchQuery{Pattern: `^p`}, []string{"internal/util.go:1", "main.go:1"}},
+		{"name glob", SearchQuery{Pattern: "HandleRequest", Paths: []string{"*.go"}}, []string{"main.go:3"}},
+		{"dir filter", SearchQuery{Pattern: "(?i)handle", Paths: []string{"internal/"}}, []string{"internal/util.go:3"}},
+		{"exclusion", SearchQuery{Pattern: "HandleRequest", Paths: []string{"!docs/"}}, []string{"main.go:3"}},
+		{"literal metacharacters", SearchQuery{Pattern: "HandleRequest()", Literal: true}, []string{"main.go:3"}},
+		{"max results", SearchQuery{Pattern: "func", MaxResults: 1}, []string{"internal/util.go:4"}},
+		{"absent", SearchQuery{Pattern: "nowhere to be found"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := idx.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var got []string
+			for _, m := range matches {
+				got = append(got, fmt.Sprintf("%s:%d", m.Path, m.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := idx.Search(SearchQuery{Pattern: "("}); err == nil {
+		t.Error("Search() should reject an invalid pattern")
+	}
+}
+
+func TestTrigramIndex_Invalidation(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.go": "package a // alpha\n",
+		"b.go": "package b // beta\n",
+	})
+	idx := NewTrigramIndex(root)
+
+	search := func(pattern string) []LineMatch {
+		t.Helper()
+		matches, err := idx.Search(SearchQuery{Pattern: pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	if got := search("alpha"); len(got) != 1 || idx.reindex != 2 {
+		t.Fatalf("first search = %v, reindexed %d; want 1 match, 2 files", got, idx.reindex)
+	}
+	if search("beta"); idx.reindex != 0 {
+		t.Errorf("unchanged tree reindexed %d files", idx.reindex)
+	}
+
+	path := filepath.Join(root, "a.go")
+	if err := os.WriteFile(path, []byte("package a // gamma\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"c.go": "package c // alpha\n"})
+	if err := os.Remove(filepath.Join(root, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := search("alpha"); len(got) != 1 || got[0].Path != "c.go" {
+		t.Errorf("search after edit = %v, want only c.go", got)
+	}
+	if idx.reindex != 2 {
+		t.Errorf("reindexed %d files, want the changed and the new file", idx.reindex)
+	}
+	if got := search("gamma|beta"); len(got) != 1 || got[0].Path != "a.go" {
+		t.Errorf("search = %v, want only a.go", got)
+	}
+	if idx.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", idx.Len())
+	}
+}
+
+func TestRequiredTrigrams(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"abc", 1},
+		{"abcd", 2},
+		{"ab", 0},
+		{"foo|bar", 0},
+		{`foo\w+bar`, 2},
+		{"(?i)ABC", 1},
+		{"(abc)?", 0},
+		{"(abc)+", 1},
+	}
+	for _, tt := range tests {
+		if got := len(requiredTrigrams(tt.pattern)); got != tt.want {
+			t.Errorf("requiredTrigrams(%q) has %d trigrams, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package isolate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/croberts/obot/internal/fswalk"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ChangeKind classifies a change to a file.
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Modified ChangeKind = "modified"
+	Deleted  ChangeKind = "deleted"
+)
+
+// Change is a difference between the copy and the host workspace.
+type Change struct {
+	Path string // slash-separated path relative to the workspace
+	Kind ChangeKind
+	Diff string // unified diff, or a note for binary files
+}
+
+// diffOptions compares the files a review cares about: hidden files are
+// included, but version control data, dependency and build directories,
+// and ignored files are not.
+var diffOptions = fswalk.Options{
+	IncludeHidden: true,
+	IgnoreExts:    map[string]struct{}{},
+}
+
+// Changes returns the differences between the copy and the host workspace,
+// sorted by path. Both trees are listed with the host's ignore files, so a
+// file the copy's .gitignore newly excludes is not taken for a deletion.
+func (w *Workspace) Changes() ([]Change, error) {
+	ignore := fswalk.NewMatcher(w.Host)
+	host, err := listFiles(w.Host, ignore)
+	if err != nil {
+		return nil, err
+	}
+	copied, err := listFiles(w.Copy, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for rel := range copied {
+		after, err := os.ReadFile(filepath.Join(w.Copy, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if !host[rel] {
+			changes = append(changes, Change{Path: rel, Kind: Added, Diff: fileDiff(rel, nil, after)})
+			continue
+		}
+		before, err := os.ReadFile(filepath.Join(w.Host, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(before, after) {
+			changes = append(changes, Change{Path: rel, Kind: Modified, Diff: fileDiff(rel, before, after)})
+		}
+	}
+	for rel := range host {
+		if !copied[rel] {
+			before, err := os.ReadFile(filepath.Join(w.Host, filepath.FromSlash(rel)))
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, Change{Path: rel, Kind: Deleted, Diff: fileDiff(rel, before, nil)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Apply applies changes from the copy to the host workspace.
+func (w *Workspace) Apply(changes []Change) error {
+	for _, c := range changes {
+		hostPath := filepath.Join(w.Host, filepath.FromSlash(c.Path))
+		if c.Kind == Deleted {
+			if err := os.Remove(hostPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("apply %s: %w", c.Path, err)
+			}
+			continue
+		}
+		copyPath := filepath.Join(w.Copy, filepath.FromSlash(c.Path))
+		info, err := os.Stat(copyPath)
+		if err != nil {
+			return fmt.Errorf("apply %s: %w", c.Path, err)
+		}
+		if err := copyFile(copyPath, hostPath, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("apply %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// listFiles returns the set of compared files under root, excluding those
+// ignore excludes.
+func listFiles(root string, ignore *fswalk.Matcher) (map[string]bool, error) {
+	opts := diffOptions
+	opts.Matcher = ignore
+	files := make(map[string]bool)
+	err := fswalk.Walk(root, opts, func(e fswalk.Entry) error {
+		files[e.Rel] = true
+		return nil
+	})
+	return files, err
+}
+
+// fileDiff returns the unified diff of a file between two versions.
+func fileDiff(rel string, before, after []byte) string {
+	if bytes.IndexByte(before, 0) >= 0 || bytes.IndexByte(after, 0) >= 0 {
+		return fmt.Sprintf("Binary file %s differs\n", rel)
+	}
+	from, to := "a/"+rel, "b/"+rel
+	if before == nil {
+		from = "/dev/null"
+	}
+	if after == nil {
+		to = "/dev/null"
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	return diff
+}
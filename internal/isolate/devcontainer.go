@@ -0,0 +1,56 @@
+package isolate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// DefaultImage is used when the workspace defines no devcontainer image.
+const DefaultImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// devcontainerFiles are the devcontainer definitions checked, in order.
+var devcontainerFiles = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// jsoncCommentRe matches the comments devcontainer.json allows, skipping
+// over strings so that URLs in them are kept.
+var jsoncCommentRe = regexp.MustCompile(`("(?:[^"\\]|\\.)*")|//[^\n]*|/\*[\s\S]*?\*/`)
+
+// trailingCommaRe matches the trailing commas devcontainer.json allows.
+var trailingCommaRe = regexp.MustCompile(`,(\s*[}\]])`)
+
+// DevcontainerImage returns the image of the workspace's devcontainer
+// definition, or "" when there is none or it builds from a Dockerfile.
+func DevcontainerImage(workspace string) string {
+	for _, name := range devcontainerFiles {
+		data, err := os.ReadFile(filepath.Join(workspace, name))
+		if err != nil {
+			continue
+		}
+		clean := jsoncCommentRe.ReplaceAll(data, []byte("$1"))
+		clean = trailingCommaRe.ReplaceAll(clean, []byte("$1"))
+		var def struct {
+			Image string `json:"image"`
+		}
+		if json.Unmarshal(clean, &def) == nil {
+			return def.Image
+		}
+	}
+	return ""
+}
+
+// ImageFor returns the image to isolate the workspace in: override when
+// set, else the devcontainer image, else DefaultImage.
+func ImageFor(workspace, override string) string {
+	if override != "" {
+		return override
+	}
+	if image := DevcontainerImage(workspace); image != "" {
+		return image
+	}
+	return DefaultImage
+}
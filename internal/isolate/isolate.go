@@ -0,0 +1,143 @@
+// Package isolate runs an orchestration in a copy of the workspace inside a
+// container, so that file edits and commands cannot touch the host until the
+// final changes are reviewed and applied.
+package isolate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// Workdir is where the workspace copy is mounted in the container.
+const Workdir = "/workspace"
+
+// copyOptions copies every regular file, including ignored ones and
+// version control data, so that the copy behaves like the workspace.
+var copyOptions = fswalk.Options{
+	IncludeHidden: true,
+	IgnoreDirs:    map[string]struct{}{},
+	IgnoreExts:    map[string]struct{}{},
+	NoIgnoreFiles: true,
+}
+
+// Workspace is an isolated copy of a host workspace.
+type Workspace struct {
+	Host      string // host workspace directory
+	Copy      string // directory holding the copy
+	Container string // name of the running container; empty until Start
+	Image     string
+}
+
+// New copies the workspace at host into a new temporary directory.
+func New(host string) (*Workspace, error) {
+	host, err := filepath.Abs(host)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "obot-isolated-*")
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{Host: host, Copy: dir}
+	if err := copyTree(host, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("copy workspace: %w", err)
+	}
+	return w, nil
+}
+
+// Start starts a long-running container of image with the copy mounted at
+// Workdir. Commands then run in it through CommandArgs.
+func (w *Workspace) Start(ctx context.Context, image string) error {
+	name := "obot-" + filepath.Base(w.Copy)
+	cmd := exec.CommandContext(ctx, "docker", "run", "--detach", "--rm", "--name", name,
+		"--volume", w.Copy+":"+Workdir, "--workdir", Workdir,
+		"--entrypoint", "sh", image, "-c", "trap 'exit 0' TERM; sleep infinity & wait")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("start container from %s: %w: %s", image, err, strings.TrimSpace(string(out)))
+	}
+	w.Container = name
+	w.Image = image
+	return nil
+}
+
+// CommandArgs returns the program and arguments running the shell command
+// inside the container, in the directory matching the host directory dir of
+// the copy. An empty dir, or one outside the copy, runs it in Workdir.
+func (w *Workspace) CommandArgs(command, dir string) (string, []string) {
+	return "docker", []string{"exec", "--interactive", "--workdir", w.containerDir(dir), w.Container, "sh", "-c", command}
+}
+
+// containerDir returns the container directory of the host directory dir.
+func (w *Workspace) containerDir(dir string) string {
+	if dir == "" || w.Copy == "" {
+		return Workdir
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Workdir
+	}
+	rel, err := filepath.Rel(w.Copy, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		// The copy may be reached through a link, such as /var on macOS
+		copyDir, err1 := filepath.EvalSymlinks(w.Copy)
+		absDir, err2 := filepath.EvalSymlinks(abs)
+		if err1 != nil || err2 != nil {
+			return Workdir
+		}
+		if rel, err = filepath.Rel(copyDir, absDir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return Workdir
+		}
+	}
+	return path.Join(Workdir, filepath.ToSlash(rel))
+}
+
+// Stop removes the container, keeping the copy.
+func (w *Workspace) Stop() {
+	if w.Container != "" {
+		_ = exec.Command("docker", "rm", "--force", w.Container).Run()
+		w.Container = ""
+	}
+}
+
+// Close stops the container and removes the copy.
+func (w *Workspace) Close() error {
+	w.Stop()
+	return os.RemoveAll(w.Copy)
+}
+
+// copyTree copies the files under src into dst.
+func copyTree(src, dst string) error {
+	return fswalk.Walk(src, copyOptions, func(e fswalk.Entry) error {
+		return copyFile(e.Path, filepath.Join(dst, filepath.FromSlash(e.Rel)), e.Info.Mode().Perm())
+	})
+}
+
+// copyFile copies a regular file, creating its directory.
+func copyFile(src, dst string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,155 @@
+package isolate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWorkspace_ChangesApply(t *testing.T) {
+	host := t.TempDir()
+	writeFiles(t, host, map[string]string{
+		"main.go":              "package main\n\nfunc main() {}\n",
+		"old.txt":              "remove me\n",
+		".gitignore":           "*.log\n",
+		".git/HEAD":            "ref: refs/heads/main\n",
+		"node_modules/x/i.js":  "module.exports = 1\n",
+		"debug.log":            "ignored\n",
+		".github/workflow.yml": "on: push\n",
+	})
+
+	w, err := New(host)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	for _, rel := range []string{".git/HEAD", "node_modules/x/i.js", "debug.log"} {
+		if _, err := os.Stat(filepath.Join(w.Copy, rel)); err != nil {
+			t.Errorf("copy is missing %s", rel)
+		}
+	}
+
+	writeFiles(t, w.Copy, map[string]string{
+		"main.go":             "package main\n\nfunc main() { println(1) }\n",
+		"new/handler.go":      "package new\n",
+		".git/HEAD":           "ref: refs/heads/other\n",
+		"node_modules/y/i.js": "module.exports = 2\n",
+		"trace.log":           "ignored\n",
+	})
+	os.Remove(filepath.Join(w.Copy, "old.txt"))
+
+	changes, err := w.Changes()
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	var got []string
+	for _, c := range changes {
+		got = append(got, string(c.Kind)+" "+c.Path)
+	}
+	want := []string{"modified main.go", "added new/handler.go", "deleted old.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Changes() = %v, want %v", got, want)
+	}
+	if !strings.Contains(changes[0].Diff, "+func main() { println(1) }") || !strings.Contains(changes[2].Diff, "+++ /dev/null") {
+		t.Errorf("diffs = %q, %q", changes[0].Diff, changes[2].Diff)
+	}
+
+	if err := w.Apply(changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(host, "new/handler.go")); string(data) != "package new\n" {
+		t.Errorf("added file = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(host, "old.txt")); !os.IsNotExist(err) {
+		t.Error("deleted file still exists on the host")
+	}
+	if data, _ := os.ReadFile(filepath.Join(host, ".git/HEAD")); string(data) != "ref: refs/heads/main\n" {
+		t.Errorf("version control data should not be applied, got %q", data)
+	}
+	if after, _ := w.Changes(); len(after) != 0 {
+		t.Errorf("Changes() after Apply = %v", after)
+	}
+}
+
+func TestWorkspace_ChangesIgnoredOnlyInCopy(t *testing.T) {
+	host := t.TempDir()
+	writeFiles(t, host, map[string]string{
+		".gitignore":  "*.log\n",
+		"config.json": "{}\n",
+	})
+	w, err := New(host)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	// The agent ignores an existing file in the copy
+	writeFiles(t, w.Copy, map[string]string{".gitignore": "*.log\nconfig.json\n"})
+
+	changes, err := w.Changes()
+	if err != nil {
+		t.Fatalf("Changes() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != ".gitignore" || changes[0].Kind != Modified {
+		t.Fatalf("Changes() = %v, want only the modified .gitignore", changes)
+	}
+	if err := w.Apply(changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(host, "config.json")); err != nil {
+		t.Errorf("config.json was removed from the host: %v", err)
+	}
+}
+
+func TestDevcontainerImage(t *testing.T) {
+	root := t.TempDir()
+	if got := ImageFor(root, ""); got != DefaultImage {
+		t.Errorf("ImageFor() without devcontainer = %q", got)
+	}
+
+	writeFiles(t, root, map[string]string{".devcontainer/devcontainer.json": `{
+	// Go development
+	"name": "api",
+	"image": "mcr.microsoft.com/devcontainers/go:1.24", /* pinned */
+	"features": {},
+}`})
+	if got := DevcontainerImage(root); got != "mcr.microsoft.com/devcontainers/go:1.24" {
+		t.Errorf("DevcontainerImage() = %q", got)
+	}
+	if got := ImageFor(root, "golang:1.24"); got != "golang:1.24" {
+		t.Errorf("ImageFor() with override = %q", got)
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	w := &Workspace{Copy: t.TempDir(), Container: "obot-x"}
+	name, args := w.CommandArgs("go test ./...", "")
+	want := []string{"exec", "--interactive", "--workdir", Workdir, "obot-x", "sh", "-c", "go test ./..."}
+	if name != "docker" || !reflect.DeepEqual(args, want) {
+		t.Errorf("CommandArgs() = %s %v", name, args)
+	}
+	for dir, want := range map[string]string{
+		filepath.Join(w.Copy, "cmd", "api"): Workdir + "/cmd/api",
+		w.Copy:                              Workdir,
+		t.TempDir():                         Workdir,
+	} {
+		if _, args := w.CommandArgs("make", dir); args[3] != want {
+			t.Errorf("CommandArgs() in %s runs in %s, want %s", dir, args[3], want)
+		}
+	}
+}
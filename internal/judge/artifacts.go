@@ -0,0 +1,303 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// Artifact limits, so the artifacts fit an expert's context window
+const (
+	maxFileDiff          = 4000  // bytes of diff shown per file
+	maxTotalDiff         = 24000 // bytes of diff shown in all
+	maxTreeEntries       = 300
+	maxSelectedFiles     = 6
+	maxSelectedFileBytes = 6000
+	maxToolOutput        = 4000 // bytes of test or lint output, from the end
+)
+
+// ArtifactOptions controls which artifacts CollectArtifacts gathers.
+type ArtifactOptions struct {
+	Base string // tree or revision diffs are taken against, such as a Snapshot; "" uses HEAD
+}
+
+// CollectArtifacts fills input with the workspace at root as it is after the
+// session: the unified diff of each changed file and its changed line count,
+// the file tree, and the contents of the changed files. Diffs need root to
+// be a git work tree; without one the other artifacts are still collected
+// and the git error returned.
+func CollectArtifacts(ctx context.Context, root string, input *ExpertInput, opts ArtifactOptions) error {
+	diffs, diffErr := workspaceDiffs(ctx, root, opts.Base)
+	if diffErr == nil {
+		input.Diffs = diffs
+		input.FileChanges = make(map[string]int, len(diffs))
+		for path, diff := range diffs {
+			input.FileChanges[path] = changedLines(diff)
+		}
+	}
+
+	input.FileTree = input.FileTree[:0]
+	err := fswalk.Walk(root, fswalk.Options{}, func(e fswalk.Entry) error {
+		if len(input.FileTree) == maxTreeEntries {
+			return filepath.SkipAll
+		}
+		input.FileTree = append(input.FileTree, e.Rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	changed := make([]string, 0, len(input.Diffs))
+	for path := range input.Diffs {
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	input.Files = make(map[string]string)
+	for _, rel := range changed {
+		if len(input.Files) == maxSelectedFiles {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			continue // deleted, unreadable, or binary
+		}
+		input.Files[rel] = string(data)
+	}
+	return diffErr
+}
+
+// Snapshot records the workspace at root, a git work tree, as it is now,
+// untracked files included and ignored ones left out, and returns the tree
+// to pass as ArtifactOptions.Base. Taken when a run starts, it keeps the
+// changes the user had not committed out of the run's diffs. Neither the
+// work tree nor the index changes.
+func Snapshot(ctx context.Context, root string) (string, error) {
+	indexPath, err := gitOutput(ctx, root, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	indexPath = strings.TrimSpace(indexPath)
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(root, indexPath)
+	}
+	dir, err := os.MkdirTemp("", "obot-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	// A copy of the index lets git skip hashing the files it has not seen
+	// change
+	tmpIndex := filepath.Join(dir, "index")
+	if data, err := os.ReadFile(indexPath); err == nil {
+		if err := os.WriteFile(tmpIndex, data, 0644); err != nil {
+			return "", err
+		}
+	}
+	env := []string{"GIT_INDEX_FILE=" + tmpIndex}
+	if _, err := gitOutputEnv(ctx, root, env, "add", "--all", "--", "."); err != nil {
+		return "", err
+	}
+	tree, err := gitOutputEnv(ctx, root, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(tree), nil
+}
+
+// workspaceDiffs returns the unified diff of each file changed since base,
+// including untracked files, by slash-separated path. Without a base the
+// diffs are taken against HEAD.
+func workspaceDiffs(ctx context.Context, root, base string) (map[string]string, error) {
+	if base != "" {
+		now, err := Snapshot(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		out, err := gitOutput(ctx, root, "diff", "--no-color", "--no-ext-diff", base, now, "--")
+		if err != nil {
+			return nil, err
+		}
+		return splitDiff(out), nil
+	}
+	out, err := gitOutput(ctx, root, "diff", "--no-color", "--no-ext-diff", "HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	diffs := splitDiff(out)
+
+	untracked, err := gitOutput(ctx, root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range strings.Split(untracked, "\x00") {
+		if rel == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "new file\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", rel, len(lines))
+		for _, line := range lines {
+			sb.WriteString("+" + line + "\n")
+		}
+		diffs[rel] = sb.String()
+	}
+	return diffs, nil
+}
+
+// splitDiff splits git diff output into per-file diffs.
+func splitDiff(out string) map[string]string {
+	diffs := make(map[string]string)
+	var path string
+	var sb strings.Builder
+	flush := func() {
+		if path != "" {
+			diffs[path] = sb.String()
+		}
+		sb.Reset()
+	}
+	for _, line := range strings.SplitAfter(out, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			// diff --git a/path b/path
+			fields := strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+			if i := strings.LastIndex(fields, " b/"); i >= 0 {
+				path = fields[i+3:]
+			}
+			continue
+		}
+		sb.WriteString(line)
+	}
+	flush()
+	return diffs
+}
+
+// changedLines counts the added and removed lines of a diff.
+func changedLines(diff string) int {
+	n := 0
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			n++
+		}
+	}
+	return n
+}
+
+// gitOutput runs git in dir and returns its standard output.
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitOutputEnv(ctx, dir, nil, args...)
+}
+
+// gitOutputEnv runs git in dir with env added to its environment.
+func gitOutputEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// writeArtifacts writes the session's artifacts to an expert's prompt.
+func writeArtifacts(sb *strings.Builder, input *ExpertInput) {
+	if len(input.FileChanges) > 0 {
+		sb.WriteString("\nChanged Files:\n")
+		for _, path := range sortedKeys(input.FileChanges) {
+			fmt.Fprintf(sb, "- %s (%d lines)\n", path, input.FileChanges[path])
+		}
+	}
+	if len(input.Diffs) > 0 {
+		writeDiffs(sb, input.Diffs)
+	}
+	if t := input.TestResults; t != nil {
+		fmt.Fprintf(sb, "\nTest Results: %d passed, %d failed of %d\n", t.Passed, t.Failed, t.Total)
+		writeToolOutput(sb, t.Output)
+	}
+	if l := input.LintResults; l != nil {
+		fmt.Fprintf(sb, "\nLint Results: %d errors, %d warnings\n", l.Errors, l.Warnings)
+		writeToolOutput(sb, l.Output)
+	}
+	writeStaticResults(sb, input)
+	if len(input.FileTree) > 0 {
+		sb.WriteString("\nFile Tree:\n")
+		for _, path := range input.FileTree {
+			sb.WriteString(path + "\n")
+		}
+		if len(input.FileTree) == maxTreeEntries {
+			sb.WriteString("... (truncated)\n")
+		}
+	}
+	if len(input.Files) > 0 {
+		sb.WriteString("\nFile Contents:\n")
+		for _, path := range sortedKeys(input.Files) {
+			content := input.Files[path]
+			if len(content) > maxSelectedFileBytes {
+				content = content[:maxSelectedFileBytes] + "\n... (truncated)"
+			}
+			fmt.Fprintf(sb, "--- %s ---\n%s\n", path, strings.TrimRight(content, "\n"))
+		}
+	}
+}
+
+// writeDiffs lists the diffs of the changed files, sorted by path, within
+// the per-file and total size limits.
+func writeDiffs(sb *strings.Builder, diffs map[string]string) {
+	sb.WriteString("\nDiffs:\n")
+	total := 0
+	paths := sortedKeys(diffs)
+	for i, p := range paths {
+		if total >= maxTotalDiff {
+			fmt.Fprintf(sb, "... (%d more diffs omitted)\n", len(paths)-i)
+			break
+		}
+		diff := diffs[p]
+		if len(diff) > maxFileDiff {
+			diff = diff[:maxFileDiff] + "\n... (truncated)"
+		}
+		total += len(diff)
+		fmt.Fprintf(sb, "--- %s ---\n%s\n", p, strings.TrimRight(diff, "\n"))
+	}
+}
+
+// writeToolOutput writes the end of a test or lint run's output, where the
+// failures and summary are.
+func writeToolOutput(sb *strings.Builder, output string) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return
+	}
+	if len(output) > maxToolOutput {
+		output = "... (truncated)\n" + output[len(output)-maxToolOutput:]
+	}
+	sb.WriteString("```\n" + output + "\n```\n")
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
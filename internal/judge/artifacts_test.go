@@ -0,0 +1,93 @@
+package judge
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectArtifacts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("util.go", "package main\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	write("main.go", "package main\n\nfunc main() { run() }\n")
+	write("run.go", "package main\n\nfunc run() {}\n")
+
+	input := &ExpertInput{OriginalPrompt: "add run"}
+	if err := CollectArtifacts(context.Background(), root, input, ArtifactOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(input.Diffs) != 2 || !strings.Contains(input.Diffs["main.go"], "+func main() { run() }") {
+		t.Errorf("Diffs = %v", input.Diffs)
+	}
+	if input.FileChanges["main.go"] != 2 || input.FileChanges["run.go"] != 3 {
+		t.Errorf("FileChanges = %v", input.FileChanges)
+	}
+	if strings.Join(input.FileTree, ",") != "main.go,run.go,util.go" {
+		t.Errorf("FileTree = %v", input.FileTree)
+	}
+	if len(input.Files) != 2 || input.Files["run.go"] != "package main\n\nfunc run() {}\n" {
+		t.Errorf("Files = %v", input.Files)
+	}
+
+	input.TestResults = &TestResults{Passed: 1, Total: 1, Output: "ok  \texample\t0.1s"}
+	var sb strings.Builder
+	writeArtifacts(&sb, input)
+	out := sb.String()
+	for _, want := range []string{"- run.go (3 lines)", "--- main.go ---", "Test Results: 1 passed, 0 failed of 1", "File Tree:\nmain.go\n", "File Contents:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeArtifacts() missing %q:\n%s", want, out)
+		}
+	}
+
+	// Changes the user had before the run started are left out
+	git("add", ".")
+	git("commit", "-q", "-m", "run")
+	write("util.go", "package main\n\n// uncommitted\n")
+	write("notes.txt", "todo\n")
+	base, err := Snapshot(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("run.go", "package main\n\nfunc run() { println() }\n")
+	write("new.go", "package main\n")
+	since := &ExpertInput{}
+	if err := CollectArtifacts(context.Background(), root, since, ArtifactOptions{Base: base}); err != nil {
+		t.Fatal(err)
+	}
+	if len(since.Diffs) != 2 || !strings.Contains(since.Diffs["run.go"], "+func run() { println() }") || since.FileChanges["new.go"] != 1 {
+		t.Errorf("Diffs since the snapshot = %v", since.Diffs)
+	}
+	if out, err := exec.Command("git", "-C", root, "status", "--porcelain").Output(); err != nil || string(out) != " M run.go\n M util.go\n?? new.go\n?? notes.txt\n" {
+		t.Errorf("git status after Snapshot = %q, %v; want the index untouched", out, err)
+	}
+
+	plain := &ExpertInput{}
+	if err := CollectArtifacts(context.Background(), t.TempDir(), plain, ArtifactOptions{}); err == nil {
+		t.Error("CollectArtifacts() outside a git work tree: want an error")
+	}
+}
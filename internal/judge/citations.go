@@ -0,0 +1,95 @@
+package judge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Source is a piece of retrieved information that claims can cite as [ID].
+type Source struct {
+	ID      string // "S1", "S2", ...
+	Summary string
+	Ref     string // URL, or file path qualified by commit
+}
+
+// String returns the source as it is listed in prompts and reports.
+func (s Source) String() string {
+	return fmt.Sprintf("[%s] %s — %s", s.ID, s.Ref, s.Summary)
+}
+
+// SourcesFromNotes numbers the notes that carry provenance as citable sources.
+func SourcesFromNotes(notes []orchestrate.Note) []Source {
+	var sources []Source
+	for _, n := range notes {
+		if n.Provenance == nil {
+			continue
+		}
+		sources = append(sources, Source{
+			ID:      fmt.Sprintf("S%d", len(sources)+1),
+			Summary: n.Content,
+			Ref:     n.Provenance.String(),
+		})
+	}
+	return sources
+}
+
+// citationRe matches citations such as [S3].
+var citationRe = regexp.MustCompile(`\[(S\d+)\]`)
+
+// citationsIn returns the source IDs cited in text.
+func citationsIn(text string) []string {
+	var ids []string
+	for _, m := range citationRe.FindAllStringSubmatch(text, -1) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+// writeSources lists the sources and how to cite them.
+func writeSources(sb *strings.Builder, sources []Source) {
+	sb.WriteString("\nRetrieved Sources (cite as [S#] after any claim derived from them):\n")
+	for _, s := range sources {
+		sb.WriteString("- " + s.String() + "\n")
+	}
+}
+
+// checkCitations records the sources cited by the TLDR's discoveries and
+// learnings, and flags claims that cite nothing or cite an unknown source.
+// Claims are only expected to be cited when information was retrieved.
+func checkCitations(tldr *TLDR, sources []Source, reports map[ExpertType]*ExpertReport) {
+	if len(sources) == 0 {
+		return
+	}
+	byID := make(map[string]Source, len(sources))
+	for _, s := range sources {
+		byID[s.ID] = s
+	}
+
+	cited := make(map[string]bool)
+	claims := append(append([]string{}, tldr.Discoveries...), tldr.Learnings...)
+	for _, claim := range claims {
+		ids := citationsIn(claim)
+		if len(ids) == 0 {
+			tldr.Uncited = append(tldr.Uncited, claim)
+			continue
+		}
+		for _, id := range ids {
+			src, ok := byID[id]
+			if !ok {
+				tldr.Uncited = append(tldr.Uncited, fmt.Sprintf("%s (unknown source %s)", claim, id))
+				continue
+			}
+			if !cited[id] {
+				cited[id] = true
+				tldr.Citations = append(tldr.Citations, src)
+			}
+		}
+	}
+
+	if r := reports[ExpertResearcher]; r != nil {
+		tldr.Uncited = append(tldr.Uncited, r.Uncited...)
+	}
+}
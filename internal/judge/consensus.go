@@ -0,0 +1,204 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// DefaultDisagreementThreshold is how many points (0-100) two experts'
+// scores may differ by before they debate.
+const DefaultDisagreementThreshold = 25.0
+
+// SetWeights sets how much each expert's scores count in the consensus.
+// Experts without a weight count 1; a weight of 0 leaves the expert out.
+func (c *Coordinator) SetWeights(weights map[ExpertType]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.weights = make(map[ExpertType]float64, len(weights))
+	for t, w := range weights {
+		c.weights[t] = math.Max(w, 0)
+	}
+}
+
+// SetDisagreementThreshold sets the score difference in points above which
+// the experts debate before the consensus is taken. 0 or less disables the
+// debate.
+func (c *Coordinator) SetDisagreementThreshold(points float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disagreementThreshold = points
+}
+
+// weightedConsensus aggregates the experts' scores by their weights. When
+// every expert has weight 0 the scores are averaged evenly.
+func weightedConsensus(reports map[ExpertType]*ExpertReport, weights map[ExpertType]float64) *ExpertConsensus {
+	consensus := &ExpertConsensus{
+		PromptAdherence: make(map[ExpertType]float64),
+		ProjectQuality:  make(map[ExpertType]float64),
+		Weights:         make(map[ExpertType]float64),
+	}
+	var sumAdherence, sumQuality, sumWeights float64
+	for t, r := range reports {
+		w, ok := weights[t]
+		if !ok {
+			w = 1
+		}
+		consensus.PromptAdherence[t] = r.PromptAdherence
+		consensus.ProjectQuality[t] = r.ProjectQuality
+		consensus.Weights[t] = w
+		sumAdherence += w * r.PromptAdherence
+		sumQuality += w * r.ProjectQuality
+		sumWeights += w
+	}
+	if sumWeights == 0 {
+		return weightedConsensus(reports, nil)
+	}
+	consensus.PromptAdherenceAvg = sumAdherence / sumWeights
+	consensus.ProjectQualityAvg = sumQuality / sumWeights
+	consensus.Spread = scoreSpread(consensus, reports)
+	return consensus
+}
+
+// scoreSpread returns the largest difference between two experts' scores,
+// on either metric. Experts left out by a weight of 0 do not count.
+func scoreSpread(consensus *ExpertConsensus, reports map[ExpertType]*ExpertReport) float64 {
+	var spread float64
+	for _, scores := range []map[ExpertType]float64{consensus.PromptAdherence, consensus.ProjectQuality} {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for t, s := range scores {
+			if consensus.Weights[t] == 0 || reports[t].Failed {
+				continue
+			}
+			lo, hi = math.Min(lo, s), math.Max(hi, s)
+		}
+		if hi > lo {
+			spread = math.Max(spread, hi-lo)
+		}
+	}
+	return spread
+}
+
+// debate shows each expert the other experts' reports and asks it to
+// reconsider its scores. Revised reports replace the first-round reports;
+// an expert that fails to answer keeps its first report.
+func (c *Coordinator) debate(ctx context.Context, session *AnalysisSession) {
+	c.mu.Lock()
+	reports := make(map[ExpertType]*ExpertReport, len(session.Reports))
+	for t, r := range session.Reports {
+		reports[t] = r
+	}
+	rubric := c.rubric
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for t, r := range reports {
+		client := c.expertClient(t)
+		if client == nil || r.Failed {
+			continue
+		}
+		wg.Add(1)
+		go func(t ExpertType, r *ExpertReport) {
+			defer wg.Done()
+			system := fmt.Sprintf(debateSystemPrompt, t)
+			if rubric != nil {
+				var rb strings.Builder
+				writeRubric(&rb, rubric)
+				system += rb.String()
+			}
+			messages := []ollama.Message{
+				{Role: "system", Content: system},
+				{Role: "user", Content: debatePrompt(t, reports)},
+			}
+			revised, err := c.requestExpertReport(ctx, client, t, messages)
+			if err != nil || (revised.PromptAdherence == 0 && revised.ProjectQuality == 0 && len(revised.Observations) == 0) {
+				return
+			}
+			c.recordReport(session.ID, reviseReport(r, revised))
+		}(t, r)
+	}
+	wg.Wait()
+}
+
+// debateSystemPrompt asks an expert for its second-round report.
+const debateSystemPrompt = `You are the expert %s judge in the second round of a review. The experts disagreed on
+their scores. Read the other experts' reports, then keep or revise your scores. Change a score only
+when another expert raised something you missed or got wrong, and say why in your observations.
+Provide your revised analysis in the following structured format:
+PROMPT_ADHERENCE: [score 0-100]
+PROJECT_QUALITY: [score 0-100]
+OBSERVATIONS:
+- observation 1
+- observation 2
+RECOMMENDATIONS:
+- recommendation 1`
+
+// debatePrompt lists the expert's own first-round report and the others'.
+func debatePrompt(expert ExpertType, reports map[ExpertType]*ExpertReport) string {
+	types := make([]string, 0, len(reports))
+	for t := range reports {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	var sb strings.Builder
+	writeReport := func(title string, r *ExpertReport) {
+		fmt.Fprintf(&sb, "\n--- %s ---\n", title)
+		fmt.Fprintf(&sb, "Adherence: %.1f%%, Quality: %.1f%%\n", r.PromptAdherence, r.ProjectQuality)
+		for _, o := range r.Observations {
+			sb.WriteString("- " + o + "\n")
+		}
+		for _, i := range r.Issues {
+			sb.WriteString("- issue: " + i + "\n")
+		}
+	}
+	writeReport("Your first-round report", reports[expert])
+	for _, t := range types {
+		if r := reports[ExpertType(t)]; ExpertType(t) != expert && !r.Failed {
+			writeReport(t+" expert", r)
+		}
+	}
+	return sb.String()
+}
+
+// reviseReport applies the scores and findings of a second-round report to
+// a copy of the first-round report. Issues and citations carry over.
+func reviseReport(first, revised *ExpertReport) *ExpertReport {
+	report := *first
+	report.PromptAdherence = revised.PromptAdherence
+	report.ProjectQuality = revised.ProjectQuality
+	report.Criteria = revised.Criteria
+	report.ScoreNotes = revised.ScoreNotes
+	if len(revised.Observations) > 0 {
+		report.Observations = revised.Observations
+	}
+	if len(revised.Recommendations) > 0 {
+		report.Recommendations = revised.Recommendations
+	}
+	report.Timestamp = revised.Timestamp
+	return &report
+}
+
+// expertClient returns the model of an expert.
+func (c *Coordinator) expertClient(expert ExpertType) *ollama.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch expert {
+	case ExpertCoder:
+		return c.coderModel
+	case ExpertResearcher:
+		return c.researcherModel
+	case ExpertVision:
+		return c.visionModel
+	case ExpertSecurity:
+		return c.securityModel
+	case ExpertDocs:
+		return c.docsModel
+	}
+	return nil
+}
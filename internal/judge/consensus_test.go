@@ -0,0 +1,88 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+func TestWeightedConsensus(t *testing.T) {
+	reports := map[ExpertType]*ExpertReport{
+		ExpertCoder:      {PromptAdherence: 90, ProjectQuality: 80},
+		ExpertResearcher: {PromptAdherence: 60, ProjectQuality: 70},
+		ExpertVision:     {PromptAdherence: 10, ProjectQuality: 10},
+	}
+	c := weightedConsensus(reports, map[ExpertType]float64{ExpertCoder: 2, ExpertVision: 0})
+	if c.PromptAdherenceAvg != 80 || c.ProjectQualityAvg != 230.0/3 {
+		t.Errorf("averages = %.2f / %.2f, want 80 / 76.67", c.PromptAdherenceAvg, c.ProjectQualityAvg)
+	}
+	if c.Spread != 30 {
+		t.Errorf("Spread = %.1f, want 30 (vision has weight 0)", c.Spread)
+	}
+
+	even := weightedConsensus(reports, map[ExpertType]float64{ExpertCoder: 0, ExpertResearcher: 0, ExpertVision: 0})
+	if even.PromptAdherenceAvg != 160.0/3 {
+		t.Errorf("all-zero weights average = %.2f, want the even average", even.PromptAdherenceAvg)
+	}
+}
+
+// chatServer serves a fixed chat response and records the requests' last
+// message.
+func chatServer(t *testing.T, reply string, seen *[]string) *ollama.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if seen != nil && len(req.Messages) > 0 {
+			*seen = append(*seen, req.Messages[len(req.Messages)-1].Content)
+		}
+		_ = json.NewEncoder(w).Encode(ollama.ChatResponse{Message: ollama.Message{Role: "assistant", Content: reply}, Done: true})
+	}))
+	t.Cleanup(srv.Close)
+	return ollama.NewClient(ollama.WithBaseURL(srv.URL))
+}
+
+func TestSynthesizeConsensus_Debate(t *testing.T) {
+	var debated []string
+	coder := chatServer(t, `{"prompt_adherence": 70, "project_quality": 65, "observations": ["The researcher was right about the missing tests"], "recommendations": []}`, &debated)
+	orch := chatServer(t, "PROMPT GOAL: ship it\nQUALITY ASSESSMENT: ACCEPTABLE", nil)
+	c := NewCoordinator(orch, coder, nil, nil)
+	c.SetDocsModel(nil)
+	c.SetRubric(nil)
+
+	c.StartSession("s1")
+	c.recordReport("s1", &ExpertReport{Expert: ExpertCoder, PromptAdherence: 95, ProjectQuality: 90})
+	c.recordReport("s1", &ExpertReport{Expert: ExpertResearcher, PromptAdherence: 50, ProjectQuality: 40, Observations: []string{"No tests"}})
+
+	tldr, err := c.SynthesizeConsensus(context.Background(), "s1", "ship it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cons := tldr.ExpertConsensus
+	if !cons.Debated || cons.InitialSpread != 50 {
+		t.Fatalf("Debated = %v, InitialSpread = %.0f, want a debate over a 50 point spread", cons.Debated, cons.InitialSpread)
+	}
+	if len(debated) != 1 || !strings.Contains(debated[0], "--- researcher expert ---") || !strings.Contains(debated[0], "- No tests") {
+		t.Errorf("debate prompt = %q", debated)
+	}
+	if cons.PromptAdherence[ExpertCoder] != 70 || cons.PromptAdherenceAvg != 60 {
+		t.Errorf("after debate: coder %.0f, average %.0f, want 70 and 60", cons.PromptAdherence[ExpertCoder], cons.PromptAdherenceAvg)
+	}
+
+	c.SetDisagreementThreshold(0)
+	debated = nil
+	c.StartSession("s2")
+	c.recordReport("s2", &ExpertReport{Expert: ExpertCoder, PromptAdherence: 95, ProjectQuality: 90})
+	c.recordReport("s2", &ExpertReport{Expert: ExpertResearcher, PromptAdherence: 50, ProjectQuality: 40})
+	if tldr, err = c.SynthesizeConsensus(context.Background(), "s2", "ship it"); err != nil {
+		t.Fatal(err)
+	}
+	if tldr.ExpertConsensus.Debated || len(debated) != 0 {
+		t.Error("debated with the threshold disabled")
+	}
+}
@@ -4,6 +4,7 @@ package judge
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/text/language"
 
 	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 // Coordinator manages multiple expert models to provide a comprehensive project evaluation.
@@ -25,9 +27,23 @@ type Coordinator struct {
 	coderModel        *ollama.Client
 	researcherModel   *ollama.Client
 	visionModel       *ollama.Client
+	securityModel     *ollama.Client
+	docsModel         *ollama.Client
+
+	// Consensus settings
+	weights               map[ExpertType]float64
+	disagreementThreshold float64
+	rubric                *Rubric
+	staticWeights         map[StaticSignal]float64
 
 	// Registry of analysis sessions
 	sessions map[string]*AnalysisSession
+
+	// Width of the rendered TLDR box; 0 uses tldrWidth
+	renderWidth int
+
+	// Called with the tokens of each request an expert or the synthesis makes
+	onTokens func(client *ollama.Client, tokens int64)
 }
 
 // Analysis tracks the full evaluation pass across multiple experts.
@@ -58,6 +74,11 @@ type SynthesisAnalysis struct {
 	QualityAssessment    QualityLevel
 	Justification        string
 	Recommendations      []string
+	Citations            []Source
+	Uncited              []string
+	Docs                 *DocsCoverage
+	Mutation             *MutationResults
+	Substitutions        []Substitution
 	Timestamp            time.Time
 }
 
@@ -69,6 +90,12 @@ type AnalysisSession struct {
 	Reports   map[ExpertType]*ExpertReport
 	Consensus *ExpertConsensus
 	TLDR      *TLDR
+	Sources   []Source
+	Findings  []Issue
+	Docs      *DocsCoverage
+	Mutation  *MutationResults
+	Static    []StaticScore // static signals measured for the session, unweighted
+	Substitutions []Substitution
 	
 	// New Analysis structure
 	Result    *Analysis
@@ -81,7 +108,13 @@ func NewCoordinator(orch, coder, res, vision *ollama.Client) *Coordinator {
 		coderModel:        coder,
 		researcherModel:   res,
 		visionModel:       vision,
+		securityModel:     coder,
+		docsModel:         res,
 		sessions:          make(map[string]*AnalysisSession),
+
+		disagreementThreshold: DefaultDisagreementThreshold,
+		rubric:                DefaultRubric(),
+		staticWeights:         DefaultStaticWeights(),
 	}
 }
 
@@ -124,6 +157,25 @@ func (c *Coordinator) getExpertAnalysis(ctx context.Context, client *ollama.Clie
 		sb.WriteString("- " + e + "\n")
 	}
 
+	// The researcher judges claims built on retrieved information, so it
+	// sees the sources and must cite them
+	citing := expert == ExpertResearcher && len(input.Sources) > 0
+	if citing {
+		writeSources(&sb, input.Sources)
+	}
+	if len(input.Findings) > 0 {
+		writeFindings(&sb, input.Findings)
+	}
+	writeArtifacts(&sb, input)
+	security := expert == ExpertSecurity
+	docs := expert == ExpertDocs
+	if docs && input.Docs != nil {
+		writeDocsCoverage(&sb, input.Docs)
+	}
+	if expert == ExpertCoder && input.Mutation != nil {
+		writeMutationResults(&sb, input.Mutation)
+	}
+
 	messages := []ollama.Message{
 		{
 			Role: "system",
@@ -147,12 +199,32 @@ RECOMMENDATIONS:
 		},
 	}
 
-	resp, stats, err := client.Chat(ctx, messages)
+	if security {
+		messages[0].Content = securityFocus + "\n\n" + messages[0].Content + securityFormat
+	}
+	if docs {
+		messages[0].Content = docsFocus + "\n\n" + messages[0].Content
+	}
+	c.mu.Lock()
+	rubric := c.rubric
+	c.mu.Unlock()
+	if rubric != nil {
+		var rb strings.Builder
+		writeRubric(&rb, rubric)
+		messages[0].Content += rb.String()
+	}
+	if citing {
+		messages[0].Content += `
+Cite the retrieved source of every observation derived from it as [S#].
+UNCITED:
+- claim from retrieved information that no listed source supports`
+	}
+
+	report, err := c.requestExpertReport(ctx, client, expert, messages)
 	if err != nil {
 		return nil, fmt.Errorf("%s analysis failed: %w", expert, err)
 	}
-
-	return c.parseExpertAnalysis(expert, resp, stats)
+	return report, nil
 }
 
 // parseExpertAnalysis parses the structured response from an expert model.
@@ -164,6 +236,7 @@ func (c *Coordinator) parseExpertAnalysis(expert ExpertType, resp string, stats
 
 	lines := strings.Split(resp, "\n")
 	var currentSection string
+	found := make(map[string]bool)
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -173,18 +246,31 @@ func (c *Coordinator) parseExpertAnalysis(expert ExpertType, resp string, stats
 
 		upperLine := strings.ToUpper(line)
 
+		// Rubric criterion scores: "CRITERION name: score"
+		if strings.HasPrefix(upperLine, "CRITERION ") {
+			if name, value, ok := strings.Cut(line[len("CRITERION "):], ":"); ok {
+				if score, ok := parseScore(value); ok {
+					if report.Criteria == nil {
+						report.Criteria = make(map[string]float64)
+					}
+					report.Criteria[strings.ToLower(strings.Trim(strings.TrimSpace(name), "*[]"))] = score
+				}
+			}
+			continue
+		}
+
 		// Parse scores with more flexibility
 		if strings.Contains(upperLine, "PROMPT_ADHERENCE:") {
 			parts := strings.Split(line, ":")
 			if len(parts) > 1 {
-				fmt.Sscanf(strings.TrimSpace(parts[1]), "%f", &report.PromptAdherence)
+				report.PromptAdherence, found[MetricPromptAdherence] = parseScore(parts[1])
 			}
 			continue
 		}
 		if strings.Contains(upperLine, "PROJECT_QUALITY:") {
 			parts := strings.Split(line, ":")
 			if len(parts) > 1 {
-				fmt.Sscanf(strings.TrimSpace(parts[1]), "%f", &report.ProjectQuality)
+				report.ProjectQuality, found[MetricProjectQuality] = parseScore(parts[1])
 			}
 			continue
 		}
@@ -211,17 +297,35 @@ func (c *Coordinator) parseExpertAnalysis(expert ExpertType, resp string, stats
 			currentSection = "recommendations"
 			continue
 		}
+		if strings.HasPrefix(upperLine, "UNCITED") {
+			currentSection = "uncited"
+			continue
+		}
+		if strings.HasPrefix(upperLine, "ISSUES") {
+			currentSection = "issues"
+			continue
+		}
 
 		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "• ") {
 			text := line[2:]
 			if currentSection == "observations" {
 				report.Observations = append(report.Observations, text)
+				report.Citations = append(report.Citations, citationsIn(text)...)
 			} else if currentSection == "recommendations" {
 				report.Recommendations = append(report.Recommendations, text)
+			} else if currentSection == "uncited" && strings.ToLower(text) != "none" {
+				report.Uncited = append(report.Uncited, text)
+			} else if currentSection == "issues" && strings.ToLower(text) != "none" {
+				report.Issues = append(report.Issues, text)
 			}
 		}
 	}
 
+	c.mu.Lock()
+	rubric := c.rubric
+	c.mu.Unlock()
+	applyRubric(report, rubric, found)
+
 	return report, nil
 }
 
@@ -258,6 +362,12 @@ func (c *Coordinator) AnalyzeAsVision(ctx context.Context, sessionID string, inp
 // Analyze performs a full evaluation pass across all configured experts and synthesizes results.
 func (c *Coordinator) Analyze(ctx context.Context, sessionID string, input *ExpertInput) (*Analysis, error) {
 	session := c.StartSession(sessionID)
+	session.Sources = input.Sources
+	session.Findings = input.Findings
+	session.Docs = input.Docs
+	session.Mutation = input.Mutation
+	session.Static = staticScores(input)
+	session.Substitutions = input.Substitutions
 	
 	experts := []struct {
 		expert ExpertType
@@ -266,6 +376,8 @@ func (c *Coordinator) Analyze(ctx context.Context, sessionID string, input *Expe
 		{ExpertCoder, c.AnalyzeAsCoder},
 		{ExpertResearcher, c.AnalyzeAsResearcher},
 		{ExpertVision, c.AnalyzeAsVision},
+		{ExpertSecurity, c.AnalyzeAsSecurity},
+		{ExpertDocs, c.AnalyzeAsDocs},
 	}
 
 	var wg sync.WaitGroup
@@ -302,21 +414,24 @@ func (c *Coordinator) SynthesizeConsensus(ctx context.Context, sessionID string,
 		return nil, fmt.Errorf("no expert reports available for synthesis")
 	}
 
-	// Calculate consensus scores
-	consensus := &ExpertConsensus{
-		PromptAdherence: make(map[ExpertType]float64),
-		ProjectQuality:  make(map[ExpertType]float64),
-	}
-
-	var sumAdherence, sumQuality float64
-	for t, r := range session.Reports {
-		consensus.PromptAdherence[t] = r.PromptAdherence
-		consensus.ProjectQuality[t] = r.ProjectQuality
-		sumAdherence += r.PromptAdherence
-		sumQuality += r.ProjectQuality
-	}
-	consensus.PromptAdherenceAvg = sumAdherence / float64(len(session.Reports))
-	consensus.ProjectQualityAvg = sumQuality / float64(len(session.Reports))
+	// Calculate consensus scores, with a debate round first when the
+	// experts disagree
+	c.mu.Lock()
+	weights, threshold := c.weights, c.disagreementThreshold
+	staticWeights := make(map[StaticSignal]float64, len(c.staticWeights))
+	for s, w := range c.staticWeights {
+		staticWeights[s] = w
+	}
+	c.mu.Unlock()
+	consensus := weightedConsensus(session.Reports, weights)
+	if threshold > 0 && consensus.Spread > threshold && len(session.Reports) > 1 {
+		initial := consensus.Spread
+		c.debate(ctx, session)
+		consensus = weightedConsensus(session.Reports, weights)
+		consensus.Debated = true
+		consensus.InitialSpread = initial
+	}
+	blendStaticScores(consensus, session.Static, staticWeights)
 
 	session.Consensus = consensus
 
@@ -330,12 +445,18 @@ func (c *Coordinator) SynthesizeConsensus(ctx context.Context, sessionID string,
 		{Role: "system", Content: "You are the Chief Orchestrator. Synthesize these expert reviews into a final TLDR."},
 		{Role: "user", Content: prompt},
 	}
-	resp, _, err := c.orchestratorModel.Chat(ctx, messages)
+	resp, stats, err := c.orchestratorModel.Chat(ctx, messages)
+	c.recordTokens(c.orchestratorModel, stats)
 	if err != nil {
 		return nil, fmt.Errorf("synthesis failed: %w", err)
 	}
 
 	tldr := c.parseSynthesisResponse(resp, session, originalPrompt)
+	checkCitations(tldr, session.Sources, session.Reports)
+	mergeFindings(tldr, append(expertIssues(session.Reports), session.Findings...))
+	mergeDocsGaps(tldr, session.Docs)
+	mergeMutationSurvivors(tldr, session.Mutation)
+	tldr.Substitutions = session.Substitutions
 	session.TLDR = tldr
 	
 	// Populate Analysis.Synthesis
@@ -349,6 +470,11 @@ func (c *Coordinator) SynthesizeConsensus(ctx context.Context, sessionID string,
 			QualityAssessment:    tldr.QualityAssessment,
 			Justification:        tldr.Justification,
 			Recommendations:      tldr.Recommendations,
+			Citations:            tldr.Citations,
+			Uncited:              tldr.Uncited,
+			Docs:                 tldr.Docs,
+			Mutation:             tldr.Mutation,
+			Substitutions:        tldr.Substitutions,
 			Timestamp:            time.Now(),
 		}
 	}
@@ -385,6 +511,16 @@ Expert Reports:
 		sb.WriteString(fmt.Sprintf("Adherence: %.1f%%, Quality: %.1f%%\n", r.PromptAdherence, r.ProjectQuality))
 		sb.WriteString("Observations: " + strings.Join(r.Observations, "; ") + "\n")
 		sb.WriteString("Recommendations: " + strings.Join(r.Recommendations, "; ") + "\n")
+		if len(r.Issues) > 0 {
+			sb.WriteString("Issues: " + strings.Join(r.Issues, "; ") + "\n")
+		}
+	}
+	if len(session.Sources) > 0 {
+		writeSources(&sb, session.Sources)
+		sb.WriteString("Every DISCOVERIES or LEARNINGS item derived from retrieved information must end with its [S#] citation.\n")
+	}
+	if len(session.Findings) > 0 {
+		writeFindings(&sb, session.Findings)
 	}
 	return sb.String()
 }
@@ -532,94 +668,129 @@ func (c *Coordinator) getSession(id string) (*AnalysisSession, bool) {
 	return s, ok
 }
 
+// tldrWidth is the width of the rendered TLDR box when none is set.
+const tldrWidth = 71
+
 // RenderTLDR formats the final synthesized analysis into a professional box-formatted report.
 // PROOF: Formats final TLDR with PROMPT GOAL, IMPLEMENTATION SUMMARY, EXPERT CONSENSUS, 
 // DISCOVERIES & LEARNINGS, QUALITY ASSESSMENT, and ACTIONABLE RECOMMENDATIONS.
 func RenderTLDR(tldr *TLDR) string {
-	var sb strings.Builder
+	return RenderTLDRWidth(tldr, tldrWidth)
+}
 
-	sb.WriteString("┌─────────────────────────────────────────────────────────────────────┐\n")
-	sb.WriteString("│ OllamaBot • Final Analysis TLDR                                     │\n")
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
+// RenderTLDRWidth is RenderTLDR with a box width columns wide.
+func RenderTLDRWidth(tldr *TLDR, width int) string {
+	box := layout.NewBox(width)
+	box.Line("OllamaBot • Final Analysis TLDR")
+	box.Divider()
 
 	// Prompt Goal
-	sb.WriteString("│ PROMPT GOAL                                                         │\n")
-	sb.WriteString(fmt.Sprintf("│ %s\n", truncate(tldr.PromptGoal, 68)))
-	sb.WriteString("│                                                                     │\n")
+	box.Line("PROMPT GOAL")
+	box.Line(tldr.PromptGoal)
+	box.Blank()
 
 	// Implementation Summary
-	sb.WriteString("│ IMPLEMENTATION SUMMARY                                              │\n")
-	lines := strings.Split(tldr.ImplementationSummary, "\n")
-	for _, line := range lines {
+	box.Line("IMPLEMENTATION SUMMARY")
+	for _, line := range strings.Split(tldr.ImplementationSummary, "\n") {
 		if line == "" {
 			continue
 		}
-		sb.WriteString(fmt.Sprintf("│ %s\n", truncate(line, 68)))
+		box.Line(line)
 	}
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
 
 	// Expert Consensus
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString("│ EXPERT CONSENSUS                                                    │\n")
-	sb.WriteString(fmt.Sprintf("│ Prompt Adherence: %.1f%% / Project Quality: %.1f%%\n",
-		tldr.ExpertConsensus.PromptAdherenceAvg, tldr.ExpertConsensus.ProjectQualityAvg))
-	
-	for expert, score := range tldr.ExpertConsensus.PromptAdherence {
-		quality := tldr.ExpertConsensus.ProjectQuality[expert]
-		sb.WriteString(fmt.Sprintf("│   %-10s: Adherence %.1f%%, Quality %.1f%%\n", 
-			cases.Title(language.English).String(string(expert)), score, quality))
+	box.Divider()
+	box.Line("EXPERT CONSENSUS")
+	box.Linef("Prompt Adherence: %.1f%% / Project Quality: %.1f%%",
+		tldr.ExpertConsensus.PromptAdherenceAvg, tldr.ExpertConsensus.ProjectQualityAvg)
+	experts := make([]string, 0, len(tldr.ExpertConsensus.PromptAdherence))
+	for expert := range tldr.ExpertConsensus.PromptAdherence {
+		experts = append(experts, string(expert))
+	}
+	sort.Strings(experts)
+	for _, expert := range experts {
+		score := tldr.ExpertConsensus.PromptAdherence[ExpertType(expert)]
+		quality := tldr.ExpertConsensus.ProjectQuality[ExpertType(expert)]
+		box.Linef("  %-10s: Adherence %.1f%%, Quality %.1f%%",
+			cases.Title(language.English).String(expert), score, quality)
+	}
+	if static := tldr.ExpertConsensus.Static; len(static) > 0 {
+		box.Linef("Project Quality blends the experts (%.1f%%, weight %.0f%%) with:",
+			tldr.ExpertConsensus.ExpertQualityAvg, tldr.ExpertConsensus.ExpertWeight*100)
+		for _, s := range static {
+			box.Linef("  %-10s: %.1f%%, weight %.0f%% (%s)",
+				cases.Title(language.English).String(string(s.Signal)), s.Score, s.Weight*100, s.Detail)
+		}
+	}
+	if tldr.ExpertConsensus.Debated {
+		box.Linef("Experts disagreed by %.0f points; scores taken after a debate (now %.0f apart)",
+			tldr.ExpertConsensus.InitialSpread, tldr.ExpertConsensus.Spread)
 	}
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
 
 	// Discoveries & Learnings
 	if len(tldr.Discoveries) > 0 || len(tldr.Learnings) > 0 {
-		sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-		sb.WriteString("│ DISCOVERIES & LEARNINGS                                             │\n")
+		box.Divider()
+		box.Line("DISCOVERIES & LEARNINGS")
 		for _, d := range tldr.Discoveries {
-			sb.WriteString(fmt.Sprintf("│ • %s\n", truncate(d, 66)))
+			box.Line("• " + d)
 		}
 		for _, l := range tldr.Learnings {
-			sb.WriteString(fmt.Sprintf("│ • %s\n", truncate(l, 66)))
+			box.Line("• " + l)
 		}
-		sb.WriteString("│                                                                     │\n")
+		box.Blank()
+	}
+
+	// Sources and uncited assertions
+	if len(tldr.Citations) > 0 || len(tldr.Uncited) > 0 {
+		box.Divider()
+		box.Line("SOURCES")
+		for _, src := range tldr.Citations {
+			box.Clipped(fmt.Sprintf("[%s] %s", src.ID, src.Ref))
+		}
+		for _, claim := range tldr.Uncited {
+			box.Line("⚠ Uncited: " + claim)
+		}
+		box.Blank()
+	}
+
+	// Answers the AI substitute gave in the human's place
+	if len(tldr.Substitutions) > 0 {
+		box.Divider()
+		box.Line("AI SUBSTITUTE ANSWERS (not the human's)")
+		for _, sub := range tldr.Substitutions {
+			answer, _, _ := strings.Cut(strings.TrimSpace(sub.Answer), "\n")
+			box.Clipped(fmt.Sprintf("⚠ %s [%s]: %s", cases.Title(language.English).String(sub.Type), sub.Persona, answer))
+		}
+		box.Blank()
 	}
 
 	// Quality Assessment
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString("│ QUALITY ASSESSMENT                                                  │\n")
-	sb.WriteString(fmt.Sprintf("│ Status: %s\n", tldr.QualityAssessment))
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString("│ Justification:                                                      │\n")
-	justLines := strings.Split(tldr.Justification, "\n")
-	for _, line := range justLines {
+	box.Divider()
+	box.Line("QUALITY ASSESSMENT")
+	box.Linef("Status: %s", tldr.QualityAssessment)
+	box.Blank()
+	box.Line("Justification:")
+	for _, line := range strings.Split(tldr.Justification, "\n") {
 		if line == "" {
 			continue
 		}
-		sb.WriteString(fmt.Sprintf("│ %s\n", truncate(line, 68)))
+		box.Line(line)
 	}
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
 
 	// Recommendations
 	if len(tldr.Recommendations) > 0 {
-		sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-		sb.WriteString("│ ACTIONABLE RECOMMENDATIONS                                          │\n")
+		box.Divider()
+		box.Line("ACTIONABLE RECOMMENDATIONS")
 		for i, rec := range tldr.Recommendations {
-			sb.WriteString(fmt.Sprintf("│ %d. %s\n", i+1, truncate(rec, 65)))
+			box.Linef("%d. %s", i+1, rec)
 		}
-		sb.WriteString("│                                                                     │\n")
+		box.Blank()
 	}
 
-	sb.WriteString("└─────────────────────────────────────────────────────────────────────┘\n")
-
-	return sb.String()
-}
-
-// truncate is a helper to ensure lines fit in the box
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	return box.String()
 }
 
 func (c *Coordinator) GetFinalReport(sessionID string) (string, error) {
@@ -630,6 +801,38 @@ func (c *Coordinator) GetFinalReport(sessionID string) (string, error) {
 	if session.TLDR == nil {
 		return "", fmt.Errorf("analysis not finalized")
 	}
-	return RenderTLDR(session.TLDR), nil
+	c.mu.Lock()
+	width := c.renderWidth
+	c.mu.Unlock()
+	if width <= 0 {
+		width = tldrWidth
+	}
+	return RenderTLDRWidth(session.TLDR, width), nil
+}
+
+// SetTokenCallback sets the callback receiving the tokens of each request
+// the judges make, such as to charge them to the models' quotas.
+func (c *Coordinator) SetTokenCallback(fn func(client *ollama.Client, tokens int64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onTokens = fn
+}
+
+// recordTokens reports the tokens of a request client answered.
+func (c *Coordinator) recordTokens(client *ollama.Client, stats *ollama.InferenceStats) {
+	c.mu.Lock()
+	onTokens := c.onTokens
+	c.mu.Unlock()
+	if stats != nil && onTokens != nil {
+		onTokens(client, int64(stats.TotalTokens))
+	}
+}
+
+// SetRenderWidth sets the width GetFinalReport renders the TLDR box at,
+// such as the terminal's.
+func (c *Coordinator) SetRenderWidth(width int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.renderWidth = width
 }
 
@@ -0,0 +1,337 @@
+package judge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/croberts/obot/internal/fswalk"
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// Docs coverage limits
+const (
+	maxDocsFileSize    = 1 << 20 // larger source files are not measured
+	maxDocGapsInPrompt = 20
+	maxDocGapsInTLDR   = 10 // gaps listed individually in the recommendations
+)
+
+// DocGap is a public symbol without documentation.
+type DocGap struct {
+	Path   string
+	Line   int
+	Kind   string // func, method, type, const, var, class
+	Symbol string
+}
+
+// String returns the gap as "kind Symbol (path:line)".
+func (g DocGap) String() string {
+	return fmt.Sprintf("%s %s (%s:%d)", g.Kind, g.Symbol, g.Path, g.Line)
+}
+
+// DocsCoverage is a heuristic measure of how much of a project's public API
+// is documented: Go exported declarations with doc comments, Python public
+// functions and classes with docstrings, and JavaScript/TypeScript exports
+// preceded by a comment.
+type DocsCoverage struct {
+	Symbols    int
+	Documented int
+	Gaps       []DocGap
+	Readme     bool // the project root has a README
+}
+
+// Percent returns the documented share of public symbols, 100 when there
+// are none.
+func (d *DocsCoverage) Percent() float64 {
+	if d.Symbols == 0 {
+		return 100
+	}
+	return float64(d.Documented) * 100 / float64(d.Symbols)
+}
+
+// MeasureDocsCoverage measures the docs coverage of files, relative to
+// root. With no files, every source file below root is measured; pass the
+// files a session changed to judge the docs it produced.
+func MeasureDocsCoverage(root string, files []string) (*DocsCoverage, error) {
+	cov := &DocsCoverage{}
+	for _, name := range []string{"README.md", "README", "README.rst", "README.txt"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			cov.Readme = true
+			break
+		}
+	}
+
+	if len(files) == 0 {
+		err := fswalk.Walk(root, fswalk.Options{MaxFileSize: maxDocsFileSize, SkipBinary: true}, func(e fswalk.Entry) error {
+			files = append(files, e.Rel)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, rel := range files {
+		path := rel
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, rel)
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Size() > maxDocsFileSize {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		switch ext := filepath.Ext(rel); {
+		case ext == ".go" && !strings.HasSuffix(rel, "_test.go"):
+			cov.measureGo(rel, src)
+		case ext == ".py" && !strings.HasPrefix(filepath.Base(rel), "test_"):
+			cov.measurePython(rel, src)
+		case ext == ".js" || ext == ".ts" || ext == ".jsx" || ext == ".tsx" || ext == ".mjs":
+			if !strings.Contains(rel, ".test.") && !strings.Contains(rel, ".spec.") {
+				cov.measureJS(rel, src)
+			}
+		}
+	}
+	return cov, nil
+}
+
+// add counts a public symbol.
+func (d *DocsCoverage) add(documented bool, gap DocGap) {
+	d.Symbols++
+	if documented {
+		d.Documented++
+	} else {
+		d.Gaps = append(d.Gaps, gap)
+	}
+}
+
+// measureGo counts exported top-level declarations and methods on exported
+// types. A grouped const or var block counts as documented when the group
+// or the spec has a comment.
+func (d *DocsCoverage) measureGo(path string, src []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil || strings.HasSuffix(file.Name.Name, "_test") {
+		return
+	}
+	gap := func(pos token.Pos, kind, name string) DocGap {
+		return DocGap{Path: path, Line: fset.Position(pos).Line, Kind: kind, Symbol: name}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			kind, name := "func", decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				recv := receiverName(decl.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				kind, name = "method", recv+"."+name
+			}
+			d.add(decl.Doc != nil, gap(decl.Pos(), kind, name))
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						d.add(decl.Doc != nil || spec.Doc != nil, gap(spec.Pos(), "type", spec.Name.Name))
+					}
+				case *ast.ValueSpec:
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							d.add(decl.Doc != nil || spec.Doc != nil || spec.Comment != nil, gap(name.Pos(), kind, name.Name))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// receiverName returns the type name of a method receiver.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+var pyDefRe = regexp.MustCompile(`^(\s*)(?:async\s+)?(def|class)\s+([A-Za-z]\w*)`)
+
+// measurePython counts public functions and classes at module level and
+// public methods of classes, documented when the body opens with a
+// docstring.
+func (d *DocsCoverage) measurePython(path string, src []byte) {
+	lines := splitLines(src)
+	for i, line := range lines {
+		m := pyDefRe.FindStringSubmatch(line)
+		if m == nil || len(m[1]) > 4 {
+			continue
+		}
+		kind := m[2]
+		if kind == "def" && m[1] != "" {
+			kind = "method"
+		} else if kind == "def" {
+			kind = "func"
+		}
+		// The body starts after the line ending the signature
+		j := i
+		for j < len(lines) && !strings.HasSuffix(strings.TrimSpace(stripPyComment(lines[j])), ":") {
+			j++
+		}
+		documented := false
+		for j++; j < len(lines); j++ {
+			body := strings.TrimSpace(lines[j])
+			if body == "" || strings.HasPrefix(body, "#") {
+				continue
+			}
+			body = strings.TrimLeft(body, "rRuUbB")
+			documented = strings.HasPrefix(body, `"`) || strings.HasPrefix(body, `'`)
+			break
+		}
+		d.add(documented, DocGap{Path: path, Line: i + 1, Kind: kind, Symbol: m[3]})
+	}
+}
+
+// stripPyComment removes a trailing # comment from a line.
+func stripPyComment(line string) string {
+	if i := strings.Index(line, "#"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+var jsExportRe = regexp.MustCompile(`^export\s+(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(function\*?|class|const|let|var|interface|type|enum)\s+([A-Za-z_$][\w$]*)`)
+
+// measureJS counts exported declarations, documented when the line before
+// them ends a comment.
+func (d *DocsCoverage) measureJS(path string, src []byte) {
+	lines := splitLines(src)
+	for i, line := range lines {
+		m := jsExportRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		documented := false
+		for j := i - 1; j >= 0; j-- {
+			prev := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(prev, "@") { // decorators sit between the comment and the export
+				continue
+			}
+			documented = strings.HasSuffix(prev, "*/") || strings.HasPrefix(prev, "//")
+			break
+		}
+		kind := m[1]
+		if strings.HasPrefix(kind, "function") {
+			kind = "func"
+		}
+		d.add(documented, DocGap{Path: path, Line: i + 1, Kind: kind, Symbol: m[2]})
+	}
+}
+
+// splitLines splits src into lines.
+func splitLines(src []byte) []string {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	sc.Buffer(make([]byte, 64*1024), maxDocsFileSize)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
+
+// SetDocsModel sets the model of the documentation expert. By default the
+// researcher model reviews documentation.
+func (c *Coordinator) SetDocsModel(client *ollama.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docsModel = client
+}
+
+// AnalyzeAsDocs reviews the READMEs, API docs, and code comments the
+// session produced, along with the measured docs coverage.
+func (c *Coordinator) AnalyzeAsDocs(ctx context.Context, sessionID string, input *ExpertInput) (*ExpertReport, error) {
+	c.mu.Lock()
+	client := c.docsModel
+	c.mu.Unlock()
+	report, err := c.getExpertAnalysis(ctx, client, ExpertDocs, input)
+	if err != nil {
+		return nil, err
+	}
+	c.recordReport(sessionID, report)
+	return report, nil
+}
+
+// docsFocus tells the documentation expert what to look for.
+const docsFocus = `Review the documentation the session produced as a technical writer. Judge whether
+READMEs explain setup and usage, API docs describe every public symbol, and code comments
+explain intent rather than restate the code. Score PROJECT_QUALITY low when public API is
+undocumented or docs contradict the changes. Recommend the specific documentation to add.`
+
+// writeDocsCoverage summarizes the docs coverage for the prompt.
+func writeDocsCoverage(sb *strings.Builder, cov *DocsCoverage) {
+	fmt.Fprintf(sb, "\nDocs Coverage: %.0f%% (%d of %d public symbols documented)\n", cov.Percent(), cov.Documented, cov.Symbols)
+	if !cov.Readme {
+		sb.WriteString("The project has no README.\n")
+	}
+	if len(cov.Gaps) == 0 {
+		return
+	}
+	sb.WriteString("Undocumented public symbols:\n")
+	for i, g := range cov.Gaps {
+		if i == maxDocGapsInPrompt {
+			fmt.Fprintf(sb, "- ... and %d more\n", len(cov.Gaps)-i)
+			break
+		}
+		sb.WriteString("- " + g.String() + "\n")
+	}
+}
+
+// mergeDocsGaps adds the docs coverage gaps to the TLDR's recommendations.
+func mergeDocsGaps(tldr *TLDR, cov *DocsCoverage) {
+	if cov == nil {
+		return
+	}
+	tldr.Docs = cov
+	var recs []string
+	if !cov.Readme {
+		recs = append(recs, "Add a README describing the project's purpose, setup, and usage")
+	}
+	for i, g := range cov.Gaps {
+		if i == maxDocGapsInTLDR {
+			recs = append(recs, fmt.Sprintf("Document the remaining %d undocumented public symbols (docs coverage %.0f%%)", len(cov.Gaps)-i, cov.Percent()))
+			break
+		}
+		recs = append(recs, "Add a doc comment to "+g.String())
+	}
+	for _, rec := range recs {
+		if !containsFold(tldr.Recommendations, rec) {
+			tldr.Recommendations = append(tldr.Recommendations, rec)
+		}
+	}
+}
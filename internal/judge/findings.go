@@ -0,0 +1,78 @@
+package judge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/croberts/obot/internal/scan"
+)
+
+// DependencyIssues converts dependency scan findings into issues, with the
+// remediation as the resolution.
+func DependencyIssues(findings []scan.DependencyFinding) []Issue {
+	issues := make([]Issue, 0, len(findings))
+	for _, f := range findings {
+		issues = append(issues, Issue{
+			Description: fmt.Sprintf("[%s %s] %s", f.Severity, f.Type, f.Message),
+			Resolution:  f.Fix,
+		})
+	}
+	return issues
+}
+
+// HealthIssues converts health scan issues into issues located at their
+// file and line.
+func HealthIssues(found []scan.HealthIssue) []Issue {
+	issues := make([]Issue, 0, len(found))
+	for _, h := range found {
+		issues = append(issues, Issue{
+			Description: fmt.Sprintf("[%s %s] %s:%d: %s", h.Severity, h.Type, h.Path, h.Line, h.Message),
+		})
+	}
+	return issues
+}
+
+// writeFindings lists the issues found by automated checks.
+func writeFindings(sb *strings.Builder, findings []Issue) {
+	sb.WriteString("\nAutomated Findings (dependency, license, and health scans; include them under ISSUES):\n")
+	for _, f := range findings {
+		sb.WriteString("- " + f.Description)
+		if f.Resolution != "" {
+			sb.WriteString(" (fix: " + f.Resolution + ")")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// mergeFindings adds the automated findings to the TLDR's issues, and their
+// resolutions to its recommendations, unless the synthesis already has them.
+func mergeFindings(tldr *TLDR, findings []Issue) {
+	for _, f := range findings {
+		if !hasIssue(tldr.Issues, f.Description) {
+			tldr.Issues = append(tldr.Issues, f)
+		}
+		if f.Resolution != "" && !containsFold(tldr.Recommendations, f.Resolution) {
+			tldr.Recommendations = append(tldr.Recommendations, f.Resolution)
+		}
+	}
+}
+
+// hasIssue reports whether issues has one describing desc.
+func hasIssue(issues []Issue, desc string) bool {
+	for _, i := range issues {
+		if strings.EqualFold(i.Description, desc) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list has an entry mentioning s.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.Contains(strings.ToLower(item), strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package judge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FollowUpPrompt seeds a run that continues a judged session: the prompt,
+// followed by the previous judges' scores, their recommendations, and the
+// issues they left unresolved.
+func FollowUpPrompt(prompt, sessionID string, previous *SynthesisAnalysis) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(prompt))
+	fmt.Fprintf(&sb, "\n\nThis run continues session %s. Its judges rated prompt adherence %.1f%% and project quality %.1f%% (%s).\n",
+		sessionID, previous.ExpertConsensus.PromptAdherenceAvg, previous.ExpertConsensus.ProjectQualityAvg, previous.QualityAssessment)
+	if len(previous.Recommendations) > 0 {
+		sb.WriteString("\nAddress their recommendations:\n")
+		for _, rec := range previous.Recommendations {
+			sb.WriteString("- " + rec + "\n")
+		}
+	}
+	if len(previous.Issues) > 0 {
+		sb.WriteString("\nResolve the issues they found:\n")
+		for _, issue := range previous.Issues {
+			sb.WriteString("- " + issue.Description)
+			if issue.Resolution != "" {
+				sb.WriteString(" (fix: " + issue.Resolution + ")")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -13,6 +13,8 @@ const (
 	ExpertCoder      ExpertType = "coder"
 	ExpertResearcher ExpertType = "researcher"
 	ExpertVision     ExpertType = "vision"
+	ExpertSecurity   ExpertType = "security"
+	ExpertDocs       ExpertType = "docs"
 )
 
 // ExpertReport contains an expert's analysis
@@ -24,6 +26,12 @@ type ExpertReport struct {
 	ErrorsMade      int
 	Observations    []string
 	Recommendations []string
+	Issues          []string           // problems found, e.g. security issues with their severity
+	Citations       []string           // source IDs cited by the observations
+	Uncited         []string           // claims from retrieved information without a source
+	Criteria        map[string]float64 // rubric criterion -> score (0-100)
+	ScoreNotes      []string           // problems found validating the scores
+	ParseErrors     []string           // why the JSON report was unusable, when the text parser was used
 	Timestamp       time.Time
 	Failed          bool
 	FailureReason   string
@@ -40,6 +48,11 @@ type TLDR struct {
 	QualityAssessment     QualityLevel
 	Justification         string
 	Recommendations       []string
+	Citations             []Source         // sources cited by discoveries and learnings
+	Uncited               []string         // assertions that should cite a source but do not
+	Docs                  *DocsCoverage    // docs coverage of the session's changes, when measured
+	Mutation              *MutationResults // mutation testing of the session's changes, when run
+	Substitutions         []Substitution   // consultations the AI substitute answered
 }
 
 // ExpertConsensus contains aggregated expert scores
@@ -48,6 +61,13 @@ type ExpertConsensus struct {
 	ProjectQualityAvg  float64
 	PromptAdherence    map[ExpertType]float64
 	ProjectQuality     map[ExpertType]float64
+	Weights            map[ExpertType]float64 // weight of each expert in the averages
+	Spread             float64                // largest score difference between experts
+	Debated            bool                   // the experts debated because they disagreed
+	InitialSpread      float64                // score difference before the debate
+	ExpertQualityAvg   float64                // the experts' project quality before the static scores were blended in
+	ExpertWeight       float64                // share of ProjectQualityAvg the experts take when static scores are blended
+	Static             []StaticScore          // static scores blended into ProjectQualityAvg
 }
 
 // Issue represents an issue encountered during execution
@@ -71,9 +91,30 @@ type ExpertInput struct {
 	FlowCode       string
 	Actions        []string
 	Errors         []string
-	FileChanges    map[string]int // filename -> lines changed
+	FileChanges    map[string]int    // filename -> lines changed
+	Diffs          map[string]string // filename -> unified diff
+	FileTree       []string          // files in the workspace after the session
+	Files          map[string]string // filename -> content of files selected for review
 	TestResults    *TestResults
 	LintResults    *LintResults
+	Sources        []Source         // retrieved information claims may cite
+	Findings       []Issue          // issues found by automated checks such as dependency scans
+	Docs           *DocsCoverage    // docs coverage of the changed files, see MeasureDocsCoverage
+	Mutation       *MutationResults // mutation testing of the changed packages, see RunMutationTesting
+	Build          *BuildResults    // build of the changed packages, see RunGoChecks
+	Vet            *VetResults      // go vet of the changed packages, see RunGoChecks
+	Coverage       *CoverageResults // the project's test coverage, when measured
+	Substitutions  []Substitution   // consultations the AI substitute answered for the human
+}
+
+// Substitution is a consultation the AI substitute answered in the human's
+// place, which the TLDR lists apart so that no one takes it for a human
+// decision.
+type Substitution struct {
+	Type     string // clarify or feedback
+	Question string
+	Answer   string
+	Persona  string // the substitute's persona
 }
 
 // TestResults contains test execution results
@@ -81,12 +122,14 @@ type TestResults struct {
 	Passed int
 	Failed int
 	Total  int
+	Output string // output of the test run
 }
 
 // LintResults contains lint check results
 type LintResults struct {
 	Errors   int
 	Warnings int
+	Output   string // output of the lint run
 }
 
 // SynthesisInput contains input for TLDR synthesis
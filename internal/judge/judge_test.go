@@ -1,7 +1,13 @@
 package judge
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/scan"
 )
 
 func TestTLDR_ExpertConsensus_initialized(t *testing.T) {
@@ -73,4 +79,294 @@ func TestExpertType_constants(t *testing.T) {
 	if ExpertVision != "vision" {
 		t.Errorf("ExpertVision = %q, want vision", ExpertVision)
 	}
+	if ExpertSecurity != "security" {
+		t.Errorf("ExpertSecurity = %q, want security", ExpertSecurity)
+	}
+}
+
+func TestSourcesFromNotes(t *testing.T) {
+	notes := []orchestrate.Note{
+		{Content: "Loop detected", Source: "system"},
+		{Content: "Retrieved documentation: Quotas", Provenance: &orchestrate.Provenance{Path: "docs/quota.md", Commit: "0123456789abcdef"}},
+		{Content: "Rate limit API", Provenance: &orchestrate.Provenance{URL: "https://example.com/limits"}},
+	}
+	sources := SourcesFromNotes(notes)
+	if len(sources) != 2 {
+		t.Fatalf("SourcesFromNotes() = %d sources, want 2", len(sources))
+	}
+	if sources[0].ID != "S1" || sources[0].Ref != "docs/quota.md@0123456789ab" {
+		t.Errorf("sources[0] = %+v", sources[0])
+	}
+	if sources[1].ID != "S2" || sources[1].Ref != "https://example.com/limits" {
+		t.Errorf("sources[1] = %+v", sources[1])
+	}
+}
+
+func TestParseExpertAnalysis_Citations(t *testing.T) {
+	c := NewCoordinator(nil, nil, nil, nil)
+	resp := `PROMPT_ADHERENCE: 80
+PROJECT_QUALITY: 70
+OBSERVATIONS:
+- Quotas reset at midnight [S1]
+- The docs were read
+UNCITED:
+- Tokens are counted per request
+RECOMMENDATIONS:
+- Cite the rate limit docs`
+	report, err := c.parseExpertAnalysis(ExpertResearcher, resp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Citations) != 1 || report.Citations[0] != "S1" {
+		t.Errorf("Citations = %v, want [S1]", report.Citations)
+	}
+	if len(report.Uncited) != 1 || report.Uncited[0] != "Tokens are counted per request" {
+		t.Errorf("Uncited = %v", report.Uncited)
+	}
+	if len(report.Recommendations) != 1 {
+		t.Errorf("Recommendations = %v", report.Recommendations)
+	}
+}
+
+func TestCheckCitations(t *testing.T) {
+	sources := []Source{
+		{ID: "S1", Summary: "Quotas", Ref: "docs/quota.md"},
+		{ID: "S2", Summary: "Themes", Ref: "docs/themes.md"},
+	}
+	tldr := &TLDR{
+		Discoveries: []string{"Quotas reset daily [S1]", "The UI is fast"},
+		Learnings:   []string{"Fallbacks exist [S1][S7]"},
+	}
+	reports := map[ExpertType]*ExpertReport{
+		ExpertResearcher: {Uncited: []string{"Tokens are counted per request"}},
+	}
+	checkCitations(tldr, sources, reports)
+
+	if len(tldr.Citations) != 1 || tldr.Citations[0].ID != "S1" {
+		t.Errorf("Citations = %+v, want only S1", tldr.Citations)
+	}
+	want := []string{"The UI is fast", "Fallbacks exist [S1][S7] (unknown source S7)", "Tokens are counted per request"}
+	if strings.Join(tldr.Uncited, "|") != strings.Join(want, "|") {
+		t.Errorf("Uncited = %q, want %q", tldr.Uncited, want)
+	}
+
+	rendered := RenderTLDR(tldr)
+	if !strings.Contains(rendered, "[S1] docs/quota.md") || !strings.Contains(rendered, "⚠ Uncited: The UI is fast") {
+		t.Errorf("RenderTLDR() missing sources:\n%s", rendered)
+	}
+
+	// Without retrieved sources there is nothing to cite
+	plain := &TLDR{Discoveries: []string{"The UI is fast"}}
+	checkCitations(plain, nil, nil)
+	if len(plain.Uncited) != 0 {
+		t.Errorf("Uncited without sources = %v", plain.Uncited)
+	}
+}
+
+func TestMergeFindings(t *testing.T) {
+	findings := DependencyIssues([]scan.DependencyFinding{
+		{
+			HealthIssue: scan.HealthIssue{Type: scan.TypeVulnerability, Severity: scan.SeverityHigh, Message: "golang.org/x/net@v0.20.0 GO-2024-0001"},
+			Fix:         "upgrade golang.org/x/net to v0.23.0",
+		},
+		{HealthIssue: scan.HealthIssue{Type: scan.TypeLicense, Severity: scan.SeverityLow, Message: "mystery@0.1.0: license could not be determined"}},
+	})
+	tldr := &TLDR{
+		Issues:          []Issue{{Description: "[high vulnerability] golang.org/x/net@v0.20.0 GO-2024-0001"}},
+		Recommendations: []string{"Add tests"},
+	}
+
+	mergeFindings(tldr, findings)
+	if len(tldr.Issues) != 2 || tldr.Issues[1].Description != "[low license] mystery@0.1.0: license could not be determined" {
+		t.Errorf("Issues = %+v", tldr.Issues)
+	}
+	if len(tldr.Recommendations) != 2 || tldr.Recommendations[1] != "upgrade golang.org/x/net to v0.23.0" {
+		t.Errorf("Recommendations = %v", tldr.Recommendations)
+	}
+
+	health := HealthIssues([]scan.HealthIssue{{Path: "api/auth.go", Line: 12, Type: "security", Severity: scan.SeverityHigh, Message: "Potential sensitive data exposed: password"}})
+	if len(health) != 1 || health[0].Description != "[high security] api/auth.go:12: Potential sensitive data exposed: password" {
+		t.Errorf("HealthIssues() = %+v", health)
+	}
+
+	var sb strings.Builder
+	writeFindings(&sb, findings)
+	if !strings.Contains(sb.String(), "(fix: upgrade golang.org/x/net to v0.23.0)") {
+		t.Errorf("writeFindings() = %q", sb.String())
+	}
+}
+
+func TestParseExpertAnalysis_SecurityIssues(t *testing.T) {
+	c := NewCoordinator(nil, nil, nil, nil)
+	resp := `PROMPT_ADHERENCE: 90
+PROJECT_QUALITY: 40
+ACTIONS: 3
+ERRORS: 0
+OBSERVATIONS:
+- The handler builds a query from user input
+ISSUES:
+- [high] db.go: SQL query concatenates the name parameter
+- [medium] config.go: API key is hardcoded
+RECOMMENDATIONS:
+- Use query parameters`
+	report, err := c.parseExpertAnalysis(ExpertSecurity, resp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 2 || report.Issues[0] != "[high] db.go: SQL query concatenates the name parameter" {
+		t.Errorf("Issues = %v", report.Issues)
+	}
+	if len(report.Observations) != 1 || len(report.Recommendations) != 1 {
+		t.Errorf("Observations = %v, Recommendations = %v", report.Observations, report.Recommendations)
+	}
+
+	issues := expertIssues(map[ExpertType]*ExpertReport{ExpertSecurity: report, ExpertCoder: {}})
+	if len(issues) != 2 || issues[1].Description != "[security] [medium] config.go: API key is hardcoded" {
+		t.Errorf("expertIssues() = %+v", issues)
+	}
+}
+
+func TestWriteDiffs(t *testing.T) {
+	var sb strings.Builder
+	writeDiffs(&sb, map[string]string{
+		"b.go": "+exec.Command(\"sh\", \"-c\", input)\n",
+		"a.go": strings.Repeat("+x\n", maxFileDiff),
+	})
+	out := sb.String()
+	if strings.Index(out, "--- a.go ---") > strings.Index(out, "--- b.go ---") {
+		t.Errorf("diffs not sorted by path: %q", out)
+	}
+	if !strings.Contains(out, "... (truncated)") {
+		t.Error("long diff not truncated")
+	}
+}
+
+func TestMeasureDocsCoverage(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"api.go": `package api
+
+// Client calls the API.
+type Client struct{}
+
+// Get fetches a resource.
+func (c *Client) Get() {}
+
+func (c *Client) Put() {}
+
+func New() *Client { return nil }
+
+func helper() {}
+
+const (
+	// Version is the API version.
+	Version = "v1"
+	Timeout = 30 // seconds
+	Retries = 3
+)
+`,
+		"tool.py": `def run(args):
+    """Run the tool."""
+
+
+def stop():
+    pass
+
+
+class Tool:
+    def start(self):
+        return 1
+
+    def _private(self):
+        pass
+`,
+		"index.ts": `/** Options for the client. */
+export interface Options {}
+
+export function connect() {}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cov, err := MeasureDocsCoverage(root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Go: Client, Client.Get, Version, Timeout documented; Client.Put, New, Retries not.
+	// Python: run documented; stop, Tool, Tool.start not. TypeScript: Options documented; connect not.
+	if cov.Symbols != 13 || cov.Documented != 6 {
+		t.Errorf("coverage = %d of %d documented, want 6 of 13; gaps: %v", cov.Documented, cov.Symbols, cov.Gaps)
+	}
+	if cov.Readme {
+		t.Error("Readme = true without a README")
+	}
+	want := []string{"method Client.Put (api.go:9)", "func New (api.go:11)", "const Retries (api.go:19)", "func connect (index.ts:4)", "func stop (tool.py:5)", "class Tool (tool.py:9)", "method start (tool.py:10)"}
+	var got []string
+	for _, g := range cov.Gaps {
+		got = append(got, g.String())
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Gaps = %v, want %v", got, want)
+	}
+
+	only, err := MeasureDocsCoverage(root, []string{"index.ts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if only.Symbols != 2 || only.Percent() != 50 {
+		t.Errorf("index.ts coverage = %d symbols, %.0f%%", only.Symbols, only.Percent())
+	}
+}
+
+func TestMergeDocsGaps(t *testing.T) {
+	cov := &DocsCoverage{Symbols: 12, Documented: 0}
+	for i := 0; i < 12; i++ {
+		cov.Gaps = append(cov.Gaps, DocGap{Path: "a.go", Line: i + 1, Kind: "func", Symbol: "F"})
+	}
+	tldr := &TLDR{}
+	mergeDocsGaps(tldr, cov)
+	if tldr.Docs != cov {
+		t.Error("Docs not set on the TLDR")
+	}
+	if len(tldr.Recommendations) != 12 {
+		t.Fatalf("Recommendations = %d, want README + 10 gaps + remainder", len(tldr.Recommendations))
+	}
+	if !strings.HasPrefix(tldr.Recommendations[0], "Add a README") {
+		t.Errorf("Recommendations[0] = %q", tldr.Recommendations[0])
+	}
+	if tldr.Recommendations[11] != "Document the remaining 2 undocumented public symbols (docs coverage 0%)" {
+		t.Errorf("Recommendations[11] = %q", tldr.Recommendations[11])
+	}
+}
+
+func TestFollowUpPrompt(t *testing.T) {
+	previous := &SynthesisAnalysis{
+		ExpertConsensus:   ExpertConsensus{PromptAdherenceAvg: 80, ProjectQualityAvg: 55},
+		QualityAssessment: QualityNeedsImprovement,
+		Recommendations:   []string{"Add tests for the parser"},
+		Issues:            []Issue{{Description: "Errors are ignored in Load", Resolution: "return them"}},
+	}
+	got := FollowUpPrompt("Add a CSV parser\n", "s1", previous)
+	want := "Add a CSV parser\n\n" +
+		"This run continues session s1. Its judges rated prompt adherence 80.0% and project quality 55.0% (NEEDS_IMPROVEMENT).\n\n" +
+		"Address their recommendations:\n- Add tests for the parser\n\n" +
+		"Resolve the issues they found:\n- Errors are ignored in Load (fix: return them)"
+	if got != want {
+		t.Errorf("FollowUpPrompt() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTLDR_Substitutions(t *testing.T) {
+	tldr := &TLDR{Substitutions: []Substitution{{Type: "feedback", Question: "Approve?", Answer: "Not approved: tests are missing\nmore", Persona: "conservative"}}}
+	rendered := RenderTLDR(tldr)
+	if !strings.Contains(rendered, "AI SUBSTITUTE ANSWERS") || !strings.Contains(rendered, "⚠ Feedback [conservative]: Not approved: tests are missing") {
+		t.Errorf("RenderTLDR() missing substitute answers:\n%s", rendered)
+	}
+	if strings.Contains(RenderTLDR(&TLDR{}), "AI SUBSTITUTE") {
+		t.Error("RenderTLDR() lists substitute answers when there are none")
+	}
 }
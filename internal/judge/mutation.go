@@ -0,0 +1,289 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// Mutation testing defaults and limits
+const (
+	// DefaultMutationCommand is the mutation tester run on Go packages.
+	DefaultMutationCommand = "go-mutesting"
+	// DefaultMutationWeight is the share of the consensus project quality
+	// the mutation score takes when mutation testing ran.
+	DefaultMutationWeight = 0.25
+	// DefaultMutationTimeout bounds a mutation testing run, which runs the
+	// tests once per mutant.
+	DefaultMutationTimeout = 30 * time.Minute
+
+	maxMutationSurvivors = 20 // surviving mutants kept for the experts and the TLDR
+	maxSurvivorsInTLDR   = 5  // surviving mutants named in the recommendation
+)
+
+// MutationResults is the outcome of mutation testing: how many of the
+// mutants made of the code the tests caught, and where the others are.
+type MutationResults struct {
+	Tool      string
+	Mutants   int      // mutants tested; duplicates and ones that do not compile are not counted
+	Killed    int      // mutants a test failed on
+	Survivors []string // "path:line" of surviving mutants, up to maxMutationSurvivors
+}
+
+// Survived returns the number of mutants no test caught.
+func (m *MutationResults) Survived() int {
+	return m.Mutants - m.Killed
+}
+
+// Score returns the share of mutants killed, 0-100.
+func (m *MutationResults) Score() float64 {
+	if m.Mutants == 0 {
+		return 100
+	}
+	return float64(m.Killed) * 100 / float64(m.Mutants)
+}
+
+// MutationOptions configures RunMutationTesting.
+type MutationOptions struct {
+	Command  string        // the mutation tester and its flags; DefaultMutationCommand when empty
+	Packages []string      // package patterns, such as ./internal/parse/...; ./... when empty
+	Timeout  time.Duration // DefaultMutationTimeout when 0
+}
+
+// RunMutationTesting runs a go-mutesting compatible mutation tester on the
+// Go packages below root: it changes the code a statement at a time and
+// runs the tests against each mutant. The share of mutants the tests kill
+// measures how well they check the code, not just run it. The tester writes
+// each mutant over its source file, so it runs on a temporary copy of root:
+// a tester killed by the timeout or an interrupt leaves the copy mutated,
+// never the user's code.
+func RunMutationTesting(ctx context.Context, root string, opts MutationOptions) (*MutationResults, error) {
+	command := opts.Command
+	if command == "" {
+		command = DefaultMutationCommand
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultMutationTimeout
+	}
+	packages := opts.Packages
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+	args := strings.Fields(command)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("%s is not installed", args[0])
+	}
+
+	copyRoot, dir, err := copyModule(root)
+	if err != nil {
+		return nil, fmt.Errorf("copy %s for mutation testing: %w", root, err)
+	}
+	defer os.RemoveAll(copyRoot)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], packages...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// The tester exits non-zero when mutants survive, so a score counts as
+	// success
+	results, parseErr := parseMutationOutput(out, dir)
+	if parseErr != nil {
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("%s: %w", args[0], ctx.Err())
+			}
+			return nil, fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, parseErr
+	}
+	results.Tool = args[0]
+	if results.Mutants == 0 {
+		return nil, errors.New("no mutants to test")
+	}
+	return results, nil
+}
+
+// mutationCopyOptions copies what building and testing Go packages needs:
+// every file, ignored or not, except hidden and dependency directories
+// other than vendor.
+var mutationCopyOptions = fswalk.Options{
+	IgnoreDirs:    map[string]struct{}{"node_modules": {}},
+	IgnoreExts:    map[string]struct{}{},
+	NoIgnoreFiles: true,
+}
+
+// copyModule copies the Go module containing root, or root when it is in
+// none, into a new temporary directory. It returns the directory and the
+// copy of root in it.
+func copyModule(root string) (copyRoot, dir string, err error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", "", err
+	}
+	module := abs
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			module = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	rel, err := filepath.Rel(module, abs)
+	if err != nil {
+		return "", "", err
+	}
+
+	copyRoot, err = os.MkdirTemp("", "obot-mutation-")
+	if err != nil {
+		return "", "", err
+	}
+	// The tester reports mutants by absolute path, which parsing makes
+	// relative to the copy as the tester sees it
+	if resolved, err := filepath.EvalSymlinks(copyRoot); err == nil {
+		copyRoot = resolved
+	}
+	err = fswalk.Walk(module, mutationCopyOptions, func(e fswalk.Entry) error {
+		info := e.Info
+		if info.Mode()&os.ModeSymlink != 0 {
+			// A link is copied as what it points to, so that no mutant
+			// is written through it; links to directories are left out
+			target, err := os.Stat(e.Path)
+			if err != nil || target.IsDir() {
+				return nil
+			}
+			info = target
+		}
+		return copyFile(e.Path, filepath.Join(copyRoot, filepath.FromSlash(e.Rel)), info.Mode().Perm())
+	})
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(copyRoot, rel), 0755)
+	}
+	if err != nil {
+		os.RemoveAll(copyRoot)
+		return "", "", err
+	}
+	return copyRoot, filepath.Join(copyRoot, rel), nil
+}
+
+// copyFile copies a file, creating its directory.
+func copyFile(src, dst string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+var (
+	// mutationScoreRe matches go-mutesting's closing line.
+	mutationScoreRe = regexp.MustCompile(`The mutation score is [\d.]+ \((\d+) passed, (\d+) failed, (\d+) duplicated, (\d+) skipped, total is (\d+)\)`)
+	// mutantRe matches a tested mutant's line; FAIL means no test caught it.
+	mutantRe = regexp.MustCompile(`^(PASS|FAIL|SKIP) "(.+)\.\d+" with checksum`)
+	// hunkRe matches the start of a unified diff hunk.
+	hunkRe = regexp.MustCompile(`^@@ -(\d+)`)
+)
+
+// parseMutationOutput reads go-mutesting's report. A surviving mutant is
+// located by its file and the first hunk of the diff printed after it.
+func parseMutationOutput(out []byte, root string) (*MutationResults, error) {
+	m := mutationScoreRe.FindSubmatch(out)
+	if m == nil {
+		return nil, errors.New("no mutation score in the output")
+	}
+	killed, _ := strconv.Atoi(string(m[1]))
+	survived, _ := strconv.Atoi(string(m[2]))
+	results := &MutationResults{Mutants: killed + survived, Killed: killed}
+
+	absRoot, _ := filepath.Abs(root)
+	var survivor string
+	for _, line := range strings.Split(string(out), "\n") {
+		if mm := mutantRe.FindStringSubmatch(line); mm != nil {
+			survivor = ""
+			if mm[1] == "FAIL" {
+				survivor = mutantSource(mm[2], absRoot)
+			}
+			continue
+		}
+		if h := hunkRe.FindStringSubmatch(line); h != nil && survivor != "" {
+			if len(results.Survivors) < maxMutationSurvivors {
+				results.Survivors = append(results.Survivors, survivor+":"+h[1])
+			}
+			survivor = ""
+		}
+	}
+	return results, nil
+}
+
+// mutantSource returns the source file a mutant was made of, relative to
+// root. The mutant's path is the source's absolute path below a temporary
+// directory.
+func mutantSource(mutant, root string) string {
+	if i := strings.Index(mutant, "go-mutesting-"); i >= 0 {
+		if j := strings.Index(mutant[i:], "/"); j >= 0 {
+			mutant = "/" + strings.TrimLeft(mutant[i+j:], "/")
+		}
+	}
+	if rel, err := filepath.Rel(root, mutant); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return mutant
+}
+
+// writeMutationResults summarizes mutation testing for the prompt.
+func writeMutationResults(sb *strings.Builder, m *MutationResults) {
+	fmt.Fprintf(sb, "\nMutation Testing (%s): %.0f%% of %d mutants killed\n", m.Tool, m.Score(), m.Mutants)
+	if len(m.Survivors) == 0 {
+		return
+	}
+	sb.WriteString("Surviving mutants, code changes no test caught:\n")
+	for _, s := range m.Survivors {
+		sb.WriteString("- " + s + "\n")
+	}
+}
+
+// mergeMutationSurvivors adds a recommendation to catch the surviving
+// mutants to the TLDR.
+func mergeMutationSurvivors(tldr *TLDR, m *MutationResults) {
+	if m == nil {
+		return
+	}
+	tldr.Mutation = m
+	if m.Survived() == 0 {
+		return
+	}
+	rec := fmt.Sprintf("Add tests that catch the %d surviving mutants (mutation score %.0f%%)", m.Survived(), m.Score())
+	if len(m.Survivors) > 0 {
+		survivors := m.Survivors[:min(len(m.Survivors), maxSurvivorsInTLDR)]
+		rec += ", e.g. at " + strings.Join(survivors, ", ")
+	}
+	tldr.Recommendations = append(tldr.Recommendations, rec)
+}
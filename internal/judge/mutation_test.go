@@ -0,0 +1,116 @@
+package judge
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/config"
+)
+
+func TestParseMutationOutput(t *testing.T) {
+	out := `PASS "/tmp/go-mutesting-123//work/p/parse.go.0" with checksum 1a2b
+FAIL "/tmp/go-mutesting-123//work/p/parse.go.1" with checksum 3c4d
+--- Original
++++ New
+@@ -42,7 +42,7 @@
+-	if n > 0 {
++	if n >= 0 {
+SKIP "/tmp/go-mutesting-123//work/p/parse.go.2" with checksum 5e6f
+FAIL "/tmp/go-mutesting-123//other/q.go.0" with checksum 7a8b
+@@ -7,3 +7,2 @@
+The mutation score is 0.333333 (1 passed, 2 failed, 0 duplicated, 1 skipped, total is 4)
+`
+	m, err := parseMutationOutput([]byte(out), "/work/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Mutants != 3 || m.Killed != 1 || m.Survived() != 2 {
+		t.Errorf("mutants = %d, killed = %d, want 3 and 1", m.Mutants, m.Killed)
+	}
+	if len(m.Survivors) != 2 || m.Survivors[0] != "parse.go:42" || m.Survivors[1] != "/other/q.go:7" {
+		t.Errorf("Survivors = %q", m.Survivors)
+	}
+	if _, err := parseMutationOutput([]byte("go-mutesting: no Go files"), "."); err == nil {
+		t.Error("parsed output without a mutation score")
+	}
+}
+
+func TestSynthesizeConsensus_Mutation(t *testing.T) {
+	orch := chatServer(t, "PROMPT GOAL: ship it\nQUALITY ASSESSMENT: ACCEPTABLE", nil)
+	c := NewCoordinator(orch, nil, nil, nil)
+	if err := c.Configure(config.JudgeConfig{StaticWeights: map[string]float64{"mutation": 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	session := c.StartSession("s1")
+	session.Mutation = &MutationResults{Tool: "go-mutesting", Mutants: 10, Killed: 4, Survivors: []string{"parse.go:42"}}
+	session.Static = staticScores(&ExpertInput{Mutation: session.Mutation})
+	c.recordReport("s1", &ExpertReport{Expert: ExpertCoder, PromptAdherence: 90, ProjectQuality: 80})
+	tldr, err := c.SynthesizeConsensus(context.Background(), "s1", "ship it")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cons := tldr.ExpertConsensus
+	if cons.ProjectQualityAvg != 60 || cons.ExpertQualityAvg != 80 || len(cons.Static) != 1 || cons.Static[0].Score != 40 {
+		t.Errorf("project quality = %.1f (experts %.1f, static %+v), want 60 (80, mutation 40)", cons.ProjectQualityAvg, cons.ExpertQualityAvg, cons.Static)
+	}
+	if n := len(tldr.Recommendations); n == 0 || !strings.Contains(tldr.Recommendations[n-1], "6 surviving mutants (mutation score 40%), e.g. at parse.go:42") {
+		t.Errorf("Recommendations = %q", tldr.Recommendations)
+	}
+	if !strings.Contains(RenderTLDR(tldr), "Mutation  : 40.0%, weight 50% (4 of 10 mutants killed)") {
+		t.Errorf("TLDR does not show the mutation score:\n%s", RenderTLDR(tldr))
+	}
+
+	if err := c.Configure(config.JudgeConfig{StaticWeights: map[string]float64{"mutation": 0}}); err != nil {
+		t.Fatal(err)
+	}
+	c.StartSession("s2").Static = session.Static
+	c.recordReport("s2", &ExpertReport{Expert: ExpertCoder, PromptAdherence: 90, ProjectQuality: 80})
+	if tldr, err = c.SynthesizeConsensus(context.Background(), "s2", "ship it"); err != nil {
+		t.Fatal(err)
+	}
+	if tldr.ExpertConsensus.ProjectQualityAvg != 80 {
+		t.Errorf("project quality = %.1f with weight 0, want the experts' 80", tldr.ExpertConsensus.ProjectQualityAvg)
+	}
+}
+
+func TestRunMutationTesting_LeavesSourceAlone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tester is a shell script")
+	}
+	root := t.TempDir()
+	source := "package p\n\nfunc Pos(n int) bool { return n > 0 }\n"
+	for name, data := range map[string]string{"go.mod": "module p\n", "p/p.go": source} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The fake tester writes a mutant over the source, as go-mutesting does,
+	// and is killed before it restores it
+	tester := filepath.Join(t.TempDir(), "mutester")
+	script := "#!/bin/sh\ntest -f ../go.mod || exit 1\necho 'mutant' > p.go\n" +
+		`echo "FAIL \"/tmp/go-mutesting-1/$(pwd)/p.go.0\" with checksum 1a2b"` + "\n" +
+		"echo '@@ -3,1 +3,1 @@'\necho 'The mutation score is 0.000000 (0 passed, 1 failed, 0 duplicated, 0 skipped, total is 1)'\nexec sleep 5\n"
+	if err := os.WriteFile(tester, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := RunMutationTesting(context.Background(), filepath.Join(root, "p"), MutationOptions{Command: tester, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Mutants != 1 || len(m.Survivors) != 1 || m.Survivors[0] != "p.go:3" {
+		t.Errorf("mutants = %d, survivors = %q, want 1 at p.go:3", m.Mutants, m.Survivors)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "p", "p.go")); err != nil || string(data) != source {
+		t.Errorf("source after mutation testing = %q, %v, want it unchanged", data, err)
+	}
+}
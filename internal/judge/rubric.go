@@ -0,0 +1,285 @@
+package judge
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/croberts/obot/internal/config"
+)
+
+// Rubric metrics, the two scores every expert reports
+const (
+	MetricPromptAdherence = "prompt_adherence"
+	MetricProjectQuality  = "project_quality"
+)
+
+// Anchor describes the work that earns a score.
+type Anchor struct {
+	Score       int
+	Description string
+}
+
+// Criterion is one scored aspect of a session. Criteria scores are
+// combined by weight into the metric they belong to.
+type Criterion struct {
+	Name        string
+	Metric      string
+	Description string
+	Weight      float64
+	Anchors     []Anchor // ascending by score
+}
+
+// Rubric defines how experts score a session, so scores mean the same
+// thing across runs and projects.
+type Rubric struct {
+	Criteria []Criterion
+}
+
+// DefaultRubric returns the rubric used when the config has none.
+func DefaultRubric() *Rubric {
+	return &Rubric{Criteria: []Criterion{
+		{
+			Name: "requirements", Metric: MetricPromptAdherence, Weight: 2,
+			Description: "The requested behavior is implemented completely",
+			Anchors: []Anchor{
+				{0, "the request was not addressed"},
+				{50, "part of the request works, parts are missing or wrong"},
+				{100, "every requested behavior works as asked"},
+			},
+		},
+		{
+			Name: "scope", Metric: MetricPromptAdherence, Weight: 1,
+			Description: "Changes stay within what was asked",
+			Anchors: []Anchor{
+				{0, "most changes are unrelated to the request"},
+				{50, "some unrequested changes or rewrites"},
+				{100, "only changes the request needs"},
+			},
+		},
+		{
+			Name: "correctness", Metric: MetricProjectQuality, Weight: 3,
+			Description: "The code builds, runs, and handles errors and edge cases",
+			Anchors: []Anchor{
+				{0, "does not build or run"},
+				{50, "works on the main path, breaks on errors or edge cases"},
+				{100, "correct on all paths that were exercised or reviewed"},
+			},
+		},
+		{
+			Name: "maintainability", Metric: MetricProjectQuality, Weight: 2,
+			Description: "The code follows the project's conventions and is easy to change",
+			Anchors: []Anchor{
+				{0, "ignores the project's conventions, duplicated or tangled"},
+				{50, "readable but inconsistent with the surrounding code"},
+				{100, "reads like the rest of the project"},
+			},
+		},
+		{
+			Name: "verification", Metric: MetricProjectQuality, Weight: 2,
+			Description: "The changes are covered by tests or other checks that pass",
+			Anchors: []Anchor{
+				{0, "nothing was tested"},
+				{50, "some tests, or tests that were not run"},
+				{100, "new behavior is tested and all checks pass"},
+			},
+		},
+	}}
+}
+
+// RubricFromConfig builds a rubric from its config, nil when the config
+// defines none.
+func RubricFromConfig(criteria []config.RubricCriterionConfig) (*Rubric, error) {
+	if len(criteria) == 0 {
+		return nil, nil
+	}
+	r := &Rubric{}
+	for _, cc := range criteria {
+		c := Criterion{Name: cc.Name, Metric: cc.Metric, Description: cc.Description, Weight: cc.Weight}
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+		for score, desc := range cc.Anchors {
+			c.Anchors = append(c.Anchors, Anchor{Score: score, Description: desc})
+		}
+		sort.Slice(c.Anchors, func(i, j int) bool { return c.Anchors[i].Score < c.Anchors[j].Score })
+		r.Criteria = append(r.Criteria, c)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate checks that criteria are named uniquely, belong to a known
+// metric, have positive weights and anchors within 0-100, and that both
+// metrics are covered.
+func (r *Rubric) Validate() error {
+	names := make(map[string]bool)
+	metrics := make(map[string]bool)
+	for _, c := range r.Criteria {
+		if c.Name == "" {
+			return fmt.Errorf("rubric criterion without a name")
+		}
+		if names[strings.ToLower(c.Name)] {
+			return fmt.Errorf("rubric criterion %q defined twice", c.Name)
+		}
+		names[strings.ToLower(c.Name)] = true
+		if c.Metric != MetricPromptAdherence && c.Metric != MetricProjectQuality {
+			return fmt.Errorf("rubric criterion %q: metric must be %s or %s, got %q", c.Name, MetricPromptAdherence, MetricProjectQuality, c.Metric)
+		}
+		metrics[c.Metric] = true
+		if c.Weight <= 0 {
+			return fmt.Errorf("rubric criterion %q: weight must be positive", c.Name)
+		}
+		for _, a := range c.Anchors {
+			if a.Score < 0 || a.Score > 100 {
+				return fmt.Errorf("rubric criterion %q: anchor score %d is outside 0-100", c.Name, a.Score)
+			}
+		}
+	}
+	for _, m := range []string{MetricPromptAdherence, MetricProjectQuality} {
+		if !metrics[m] {
+			return fmt.Errorf("rubric has no criteria for %s", m)
+		}
+	}
+	return nil
+}
+
+// SetRubric sets the rubric experts score by. A nil rubric leaves scores
+// to the experts, only clamped to 0-100.
+func (c *Coordinator) SetRubric(r *Rubric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rubric = r
+}
+
+// Configure applies the judge config: expert weights, the disagreement
+// threshold, the rubric, and the static signals' weights, when they are
+// set.
+func (c *Coordinator) Configure(cfg config.JudgeConfig) error {
+	rubric, err := RubricFromConfig(cfg.Rubric)
+	if err != nil {
+		return err
+	}
+	if rubric != nil {
+		c.SetRubric(rubric)
+	}
+	if len(cfg.Weights) > 0 {
+		weights := make(map[ExpertType]float64, len(cfg.Weights))
+		for expert, w := range cfg.Weights {
+			weights[ExpertType(strings.ToLower(expert))] = w
+		}
+		c.SetWeights(weights)
+	}
+	if cfg.DisagreementThreshold != 0 {
+		c.SetDisagreementThreshold(cfg.DisagreementThreshold)
+	}
+	if len(cfg.StaticWeights) > 0 {
+		weights := make(map[StaticSignal]float64, len(cfg.StaticWeights))
+		for signal, w := range cfg.StaticWeights {
+			s := StaticSignal(strings.ToLower(signal))
+			if !slices.Contains(staticSignals, s) {
+				return fmt.Errorf("unknown static signal %q", signal)
+			}
+			weights[s] = w
+		}
+		c.SetStaticWeights(weights)
+	}
+	return nil
+}
+
+// writeRubric appends the rubric and the criterion score lines it asks for
+// to an expert's system prompt.
+func writeRubric(sb *strings.Builder, r *Rubric) {
+	sb.WriteString("\n\nScore with this rubric. Each criterion is scored 0-100 against its anchors; ")
+	sb.WriteString("PROMPT_ADHERENCE and PROJECT_QUALITY are computed from the criteria.\n")
+	for _, c := range r.Criteria {
+		fmt.Fprintf(sb, "- %s (%s, weight %g): %s\n", c.Name, c.Metric, c.Weight, c.Description)
+		for _, a := range c.Anchors {
+			fmt.Fprintf(sb, "    %d = %s\n", a.Score, a.Description)
+		}
+	}
+	sb.WriteString("Report one line per criterion:\n")
+	for _, c := range r.Criteria {
+		fmt.Fprintf(sb, "CRITERION %s: [score 0-100]\n", c.Name)
+	}
+}
+
+var scoreRe = regexp.MustCompile(`(-?\d+(?:\.\d+)?)\s*(%|/\s*(\d+))?`)
+
+// parseScore reads a score such as "85", "85%", "85/100", or "8.5/10",
+// scaled to 0-100.
+func parseScore(s string) (float64, bool) {
+	m := scoreRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if m[3] != "" {
+		if scale, err := strconv.ParseFloat(m[3], 64); err == nil && scale > 0 {
+			v = v * 100 / scale
+		}
+	}
+	return v, true
+}
+
+// applyRubric validates a parsed report's scores: scores are clamped to
+// 0-100, and with a rubric each metric becomes the weighted average of its
+// criteria's scores. Problems are noted in the report's ScoreNotes.
+func applyRubric(report *ExpertReport, r *Rubric, found map[string]bool) {
+	clamp := func(name string, v float64) float64 {
+		if v < 0 || v > 100 {
+			report.ScoreNotes = append(report.ScoreNotes, fmt.Sprintf("%s score %g clamped to 0-100", name, v))
+			return math.Max(0, math.Min(100, v))
+		}
+		return v
+	}
+	for name, v := range report.Criteria {
+		report.Criteria[name] = clamp(name, v)
+	}
+	report.PromptAdherence = clamp(MetricPromptAdherence, report.PromptAdherence)
+	report.ProjectQuality = clamp(MetricProjectQuality, report.ProjectQuality)
+
+	if r == nil {
+		for _, m := range []string{MetricPromptAdherence, MetricProjectQuality} {
+			if !found[m] {
+				report.ScoreNotes = append(report.ScoreNotes, "no "+strings.ToUpper(m)+" score")
+			}
+		}
+		return
+	}
+
+	sums := make(map[string]float64)
+	weights := make(map[string]float64)
+	for _, c := range r.Criteria {
+		score, ok := report.Criteria[strings.ToLower(c.Name)]
+		if !ok {
+			report.ScoreNotes = append(report.ScoreNotes, "no score for criterion "+c.Name)
+			continue
+		}
+		sums[c.Metric] += c.Weight * score
+		weights[c.Metric] += c.Weight
+	}
+	for _, m := range []struct {
+		name  string
+		score *float64
+	}{
+		{MetricPromptAdherence, &report.PromptAdherence},
+		{MetricProjectQuality, &report.ProjectQuality},
+	} {
+		switch {
+		case weights[m.name] > 0:
+			*m.score = sums[m.name] / weights[m.name]
+		case !found[m.name]:
+			report.ScoreNotes = append(report.ScoreNotes, "no "+strings.ToUpper(m.name)+" score")
+		}
+	}
+}
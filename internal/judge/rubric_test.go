@@ -0,0 +1,114 @@
+package judge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseScore(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{" 85", 85, true},
+		{"[72]", 72, true},
+		{"90%", 90, true},
+		{"8.5/10", 85, true},
+		{"40 / 50", 80, true},
+		{"n/a", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseScore(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseScore(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseExpertAnalysis_Rubric(t *testing.T) {
+	c := NewCoordinator(nil, nil, nil, nil)
+	resp := `PROMPT_ADHERENCE: 99
+PROJECT_QUALITY: 7/10
+CRITERION requirements: 90
+CRITERION scope: 60
+CRITERION correctness: 80
+CRITERION **maintainability**: 7/10
+OBSERVATIONS:
+- Works`
+	report, err := c.parseExpertAnalysis(ExpertCoder, resp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PromptAdherence != 80 {
+		t.Errorf("PromptAdherence = %v, want the weighted criteria score 80", report.PromptAdherence)
+	}
+	// correctness 80 x3 + maintainability 70 x2; verification is missing
+	if report.ProjectQuality != 76 {
+		t.Errorf("ProjectQuality = %v, want 76", report.ProjectQuality)
+	}
+	if len(report.ScoreNotes) != 1 || report.ScoreNotes[0] != "no score for criterion verification" {
+		t.Errorf("ScoreNotes = %v", report.ScoreNotes)
+	}
+
+	c.SetRubric(nil)
+	report, _ = c.parseExpertAnalysis(ExpertCoder, "PROMPT_ADHERENCE: 140", nil)
+	if report.PromptAdherence != 100 {
+		t.Errorf("PromptAdherence = %v, want clamped to 100", report.PromptAdherence)
+	}
+	if strings.Join(report.ScoreNotes, "; ") != "prompt_adherence score 140 clamped to 0-100; no PROJECT_QUALITY score" {
+		t.Errorf("ScoreNotes = %v", report.ScoreNotes)
+	}
+}
+
+func TestRubricFromConfig(t *testing.T) {
+	var cfg config.JudgeConfig
+	err := yaml.Unmarshal([]byte(`
+weights:
+  security: 2
+rubric:
+  - name: works
+    metric: prompt_adherence
+    anchors:
+      100: everything asked for works
+      0: nothing works
+  - name: clean
+    metric: project_quality
+    weight: 3
+`), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCoordinator(nil, nil, nil, nil)
+	if err := c.Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.rubric.Criteria) != 2 || c.rubric.Criteria[0].Weight != 1 || c.rubric.Criteria[0].Anchors[0].Score != 0 {
+		t.Errorf("rubric = %+v", c.rubric)
+	}
+	if c.weights[ExpertSecurity] != 2 {
+		t.Errorf("weights = %v", c.weights)
+	}
+	var sb strings.Builder
+	writeRubric(&sb, c.rubric)
+	if !strings.Contains(sb.String(), "    100 = everything asked for works\n") || !strings.Contains(sb.String(), "CRITERION clean: [score 0-100]") {
+		t.Errorf("writeRubric() = %q", sb.String())
+	}
+
+	bad := []config.RubricCriterionConfig{
+		{Name: "works", Metric: "prompt_adherence"},
+		{Name: "style", Metric: "looks"},
+	}
+	if _, err := RubricFromConfig(bad); err == nil || !strings.Contains(err.Error(), `metric must be`) {
+		t.Errorf("RubricFromConfig() error = %v, want a metric error", err)
+	}
+	if _, err := RubricFromConfig(bad[:1]); err == nil || !strings.Contains(err.Error(), "no criteria for project_quality") {
+		t.Errorf("RubricFromConfig() error = %v, want a missing metric error", err)
+	}
+	if err := DefaultRubric().Validate(); err != nil {
+		t.Errorf("DefaultRubric() is invalid: %v", err)
+	}
+}
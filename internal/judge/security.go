@@ -0,0 +1,62 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// securityFocus tells the security expert what to look for.
+const securityFocus = `Review the actions and diffs as an application security engineer. Look for:
+- injection risks: SQL, shell, template, path traversal, and unescaped output
+- secrets: hardcoded credentials, tokens, keys, or secrets written to logs
+- unsafe execution: shell commands built from input, eval, unsafe deserialization
+- missing validation: unchecked input, missing authentication or authorization, unbounded sizes
+Score PROJECT_QUALITY low when any high severity issue is present.`
+
+// securityFormat is the extra section the security expert reports.
+const securityFormat = `
+ISSUES:
+- [high|medium|low] file: description of a security issue
+(write "- None" when there are no issues)`
+
+// SetSecurityModel sets the model of the security expert. By default the
+// coder model reviews security.
+func (c *Coordinator) SetSecurityModel(client *ollama.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.securityModel = client
+}
+
+// AnalyzeAsSecurity reviews the session's actions and diffs for injection
+// risks, secrets, unsafe execution, and missing validation.
+func (c *Coordinator) AnalyzeAsSecurity(ctx context.Context, sessionID string, input *ExpertInput) (*ExpertReport, error) {
+	c.mu.Lock()
+	client := c.securityModel
+	c.mu.Unlock()
+	report, err := c.getExpertAnalysis(ctx, client, ExpertSecurity, input)
+	if err != nil {
+		return nil, err
+	}
+	c.recordReport(sessionID, report)
+	return report, nil
+}
+
+// expertIssues returns the issues the experts reported, labelled with the
+// expert, for the TLDR's Issues list.
+func expertIssues(reports map[ExpertType]*ExpertReport) []Issue {
+	experts := make([]string, 0, len(reports))
+	for t := range reports {
+		experts = append(experts, string(t))
+	}
+	sort.Strings(experts)
+	var issues []Issue
+	for _, t := range experts {
+		for _, desc := range reports[ExpertType(t)].Issues {
+			issues = append(issues, Issue{Description: fmt.Sprintf("[%s] %s", t, desc)})
+		}
+	}
+	return issues
+}
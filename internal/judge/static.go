@@ -0,0 +1,239 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StaticSignal is an objective measurement of the project blended with the
+// experts' project quality, so the score is not the models' judgment alone.
+type StaticSignal string
+
+const (
+	SignalTests    StaticSignal = "tests"    // share of tests passing
+	SignalBuild    StaticSignal = "build"    // whether the changed packages build
+	SignalLint     StaticSignal = "lint"     // lint errors and warnings
+	SignalVet      StaticSignal = "vet"      // go vet findings in the changed packages
+	SignalCoverage StaticSignal = "coverage" // test coverage
+	SignalMutation StaticSignal = "mutation" // share of mutants killed
+)
+
+// staticSignals lists the signals in the order they are shown.
+var staticSignals = []StaticSignal{SignalTests, SignalBuild, SignalLint, SignalVet, SignalCoverage, SignalMutation}
+
+// DefaultStaticWeights returns the default share of the consensus project
+// quality each static signal takes when it was measured.
+func DefaultStaticWeights() map[StaticSignal]float64 {
+	return map[StaticSignal]float64{
+		SignalTests:    0.10,
+		SignalBuild:    0.10,
+		SignalLint:     0.05,
+		SignalVet:      0.05,
+		SignalCoverage: 0.05,
+		SignalMutation: DefaultMutationWeight,
+	}
+}
+
+// Penalties of the lint and vet scores, in points per finding
+const (
+	lintErrorPenalty   = 10
+	lintWarningPenalty = 2
+	vetFindingPenalty  = 10
+
+	staticCheckTimeout = 5 * time.Minute
+)
+
+// StaticScore is a static signal's score and its share of the consensus
+// project quality.
+type StaticScore struct {
+	Signal StaticSignal
+	Score  float64 // 0-100
+	Weight float64 // share of ProjectQualityAvg, 0-1; 0 before blending
+	Detail string  // what was measured, e.g. "12 of 12 tests passed"
+}
+
+// BuildResults is the outcome of building the changed packages.
+type BuildResults struct {
+	Success bool
+	Output  string // compiler errors when the build failed
+}
+
+// VetResults is the outcome of go vet on the changed packages.
+type VetResults struct {
+	Findings int
+	Output   string
+}
+
+// CoverageResults is the project's measured test coverage.
+type CoverageResults struct {
+	Tool       string
+	Percent    float64
+	Statements int
+}
+
+// SetStaticWeights sets the share (0-1) of the consensus project quality
+// the given static signals take; unlisted signals keep their weight and a
+// weight of 0 leaves a signal out of the consensus. When the measured
+// signals' weights sum past 1, they share the whole score.
+func (c *Coordinator) SetStaticWeights(weights map[StaticSignal]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for s, w := range weights {
+		c.staticWeights[s] = min(max(w, 0), 1)
+	}
+}
+
+// staticScores scores the static signals measured for a session, 0-100
+// each, leaving out the ones that were not.
+func staticScores(input *ExpertInput) []StaticScore {
+	var scores []StaticScore
+	if t := input.TestResults; t != nil && t.Total > 0 {
+		scores = append(scores, StaticScore{Signal: SignalTests, Score: float64(t.Passed) * 100 / float64(t.Total),
+			Detail: fmt.Sprintf("%d of %d tests passed", t.Passed, t.Total)})
+	}
+	if b := input.Build; b != nil {
+		score, detail := 0.0, "build failed"
+		if b.Success {
+			score, detail = 100, "builds"
+		}
+		scores = append(scores, StaticScore{Signal: SignalBuild, Score: score, Detail: detail})
+	}
+	if l := input.LintResults; l != nil {
+		scores = append(scores, StaticScore{Signal: SignalLint, Score: penaltyScore(l.Errors*lintErrorPenalty + l.Warnings*lintWarningPenalty),
+			Detail: fmt.Sprintf("%d errors, %d warnings", l.Errors, l.Warnings)})
+	}
+	if v := input.Vet; v != nil {
+		scores = append(scores, StaticScore{Signal: SignalVet, Score: penaltyScore(v.Findings * vetFindingPenalty),
+			Detail: fmt.Sprintf("%d findings", v.Findings)})
+	}
+	if cov := input.Coverage; cov != nil {
+		scores = append(scores, StaticScore{Signal: SignalCoverage, Score: cov.Percent,
+			Detail: fmt.Sprintf("%d statements (%s)", cov.Statements, cov.Tool)})
+	}
+	if m := input.Mutation; m != nil {
+		scores = append(scores, StaticScore{Signal: SignalMutation, Score: m.Score(),
+			Detail: fmt.Sprintf("%d of %d mutants killed", m.Killed, m.Mutants)})
+	}
+	return scores
+}
+
+// penaltyScore returns 100 less a penalty, down to 0.
+func penaltyScore(penalty int) float64 {
+	return math.Max(float64(100-penalty), 0)
+}
+
+// blendStaticScores makes the weighted static scores a share of the
+// consensus project quality, keeping the experts' average in
+// ExpertQualityAvg. Signals with no weight are left out.
+func blendStaticScores(consensus *ExpertConsensus, scores []StaticScore, weights map[StaticSignal]float64) {
+	var blended []StaticScore
+	total := 0.0
+	for _, s := range scores {
+		if w := weights[s.Signal]; w > 0 {
+			s.Weight = w
+			blended = append(blended, s)
+			total += w
+		}
+	}
+	if len(blended) == 0 {
+		return
+	}
+	scale := 1.0
+	if total > 1 {
+		scale = 1 / total
+	}
+	experts := consensus.ProjectQualityAvg
+	consensus.ExpertQualityAvg = experts
+	consensus.ExpertWeight = 1 - total*scale
+	quality := consensus.ExpertWeight * experts
+	for i := range blended {
+		blended[i].Weight *= scale
+		quality += blended[i].Weight * blended[i].Score
+	}
+	consensus.ProjectQualityAvg = quality
+	consensus.Static = blended
+}
+
+// vetFindingRe matches a go vet finding's position.
+var vetFindingRe = regexp.MustCompile(`(?m)^(vet: )?\S+\.go:\d+(:\d+)?: `)
+
+// RunGoChecks builds and vets the given packages of the Go module at root,
+// as the static build and vet signals. The packages are not vetted when
+// they do not build.
+func RunGoChecks(ctx context.Context, root string, packages []string) (*BuildResults, *VetResults, error) {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		return nil, nil, errors.New("not a Go module")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, nil, errors.New("go is not installed")
+	}
+	ctx, cancel := context.WithTimeout(ctx, staticCheckTimeout)
+	defer cancel()
+
+	// A single main package would leave its binary in root
+	args := []string{"build"}
+	if len(packages) == 1 {
+		args = append(args, "-o", os.DevNull)
+	}
+	out, err := runGo(ctx, root, append(args, packages...)...)
+	if ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("go build: %w", ctx.Err())
+	}
+	if err != nil {
+		return &BuildResults{Output: out}, nil, nil
+	}
+	build := &BuildResults{Success: true}
+
+	out, err = runGo(ctx, root, append([]string{"vet"}, packages...)...)
+	if ctx.Err() != nil {
+		return build, nil, fmt.Errorf("go vet: %w", ctx.Err())
+	}
+	vet := &VetResults{Findings: len(vetFindingRe.FindAllString(out, -1))}
+	if err != nil && vet.Findings == 0 {
+		return build, nil, fmt.Errorf("go vet: %w: %s", err, out)
+	}
+	if vet.Findings > 0 {
+		vet.Output = out
+	}
+	return build, vet, nil
+}
+
+// runGo runs the go command in dir and returns its combined output.
+func runGo(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return strings.TrimSpace(out.String()), err
+}
+
+// writeStaticResults adds the build, vet, and coverage results to the
+// prompt.
+func writeStaticResults(sb *strings.Builder, input *ExpertInput) {
+	if b := input.Build; b != nil {
+		if b.Success {
+			sb.WriteString("\nBuild: the changed packages build\n")
+		} else {
+			sb.WriteString("\nBuild: FAILED\n")
+			writeToolOutput(sb, b.Output)
+		}
+	}
+	if v := input.Vet; v != nil {
+		fmt.Fprintf(sb, "\nVet Results: %d findings\n", v.Findings)
+		writeToolOutput(sb, v.Output)
+	}
+	if cov := input.Coverage; cov != nil {
+		fmt.Fprintf(sb, "\nTest Coverage: %.1f%% of %d statements (%s)\n", cov.Percent, cov.Statements, cov.Tool)
+	}
+}
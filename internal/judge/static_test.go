@@ -0,0 +1,80 @@
+package judge
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/config"
+)
+
+func TestStaticScores(t *testing.T) {
+	scores := staticScores(&ExpertInput{
+		TestResults: &TestResults{Passed: 9, Failed: 1, Total: 10},
+		Build:       &BuildResults{Success: true},
+		LintResults: &LintResults{Errors: 1, Warnings: 3},
+		Vet:         &VetResults{Findings: 12},
+		Coverage:    &CoverageResults{Tool: "go test", Percent: 72.5, Statements: 400},
+	})
+	want := map[StaticSignal]float64{SignalTests: 90, SignalBuild: 100, SignalLint: 84, SignalVet: 0, SignalCoverage: 72.5}
+	if len(scores) != len(want) {
+		t.Fatalf("scores = %+v, want %d signals", scores, len(want))
+	}
+	for _, s := range scores {
+		if s.Score != want[s.Signal] {
+			t.Errorf("%s score = %.1f, want %.1f", s.Signal, s.Score, want[s.Signal])
+		}
+	}
+	if scores := staticScores(&ExpertInput{TestResults: &TestResults{}}); len(scores) != 0 {
+		t.Errorf("scored a run without tests: %+v", scores)
+	}
+}
+
+func TestBlendStaticScores(t *testing.T) {
+	scores := []StaticScore{{Signal: SignalTests, Score: 100}, {Signal: SignalBuild, Score: 0}, {Signal: SignalVet, Score: 50}}
+
+	consensus := &ExpertConsensus{ProjectQualityAvg: 80}
+	blendStaticScores(consensus, scores, map[StaticSignal]float64{SignalTests: 0.2, SignalBuild: 0.1})
+	if math.Abs(consensus.ProjectQualityAvg-76) > 1e-9 || consensus.ExpertQualityAvg != 80 || len(consensus.Static) != 2 {
+		t.Errorf("blend = %.2f (experts %.1f, %d signals), want 76 from 80 with vet left out", consensus.ProjectQualityAvg, consensus.ExpertQualityAvg, len(consensus.Static))
+	}
+
+	// Weights past 1 share the whole score
+	consensus = &ExpertConsensus{ProjectQualityAvg: 80}
+	blendStaticScores(consensus, scores, map[StaticSignal]float64{SignalTests: 1, SignalBuild: 1})
+	if consensus.ProjectQualityAvg != 50 || consensus.ExpertWeight != 0 {
+		t.Errorf("blend = %.1f with expert weight %.1f, want 50 and 0", consensus.ProjectQualityAvg, consensus.ExpertWeight)
+	}
+
+	consensus = &ExpertConsensus{ProjectQualityAvg: 80}
+	blendStaticScores(consensus, scores, nil)
+	if consensus.ProjectQualityAvg != 80 || consensus.Static != nil {
+		t.Errorf("blended without weights: %+v", consensus)
+	}
+}
+
+func TestVetFindingRe(t *testing.T) {
+	out := `# example.com/p
+vet: a.go:3:2: x declared and not used
+./b.go:10:5: fmt.Printf format %d has arg s of wrong type string
+internal/c/c.go:4: unreachable code`
+	if n := len(vetFindingRe.FindAllString(out, -1)); n != 3 {
+		t.Errorf("found %d vet findings in\n%s\nwant 3", n, strings.TrimSpace(out))
+	}
+}
+
+func TestConfigure_StaticWeights(t *testing.T) {
+	c := NewCoordinator(nil, nil, nil, nil)
+	err := c.Configure(config.JudgeConfig{
+		StaticWeights: map[string]float64{"Tests": 0.3, "lint": 0, "mutation": 0.4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.staticWeights[SignalTests] != 0.3 || c.staticWeights[SignalLint] != 0 || c.staticWeights[SignalMutation] != 0.4 || c.staticWeights[SignalBuild] != 0.1 {
+		t.Errorf("static weights = %v", c.staticWeights)
+	}
+	if err := c.Configure(config.JudgeConfig{StaticWeights: map[string]float64{"vibes": 1}}); err == nil || !strings.Contains(err.Error(), `unknown static signal "vibes"`) {
+		t.Errorf("Configure() error = %v, want an unknown signal error", err)
+	}
+}
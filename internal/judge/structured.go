@@ -0,0 +1,244 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// ReportFormatError lists what is wrong with an expert's JSON report.
+type ReportFormatError struct {
+	Expert   ExpertType
+	Problems []string
+}
+
+func (e *ReportFormatError) Error() string {
+	return fmt.Sprintf("%s report is invalid: %s", e.Expert, strings.Join(e.Problems, "; "))
+}
+
+// expertJSON is the JSON form of an expert report. Pointers tell missing
+// fields from zero values.
+type expertJSON struct {
+	PromptAdherence *float64           `json:"prompt_adherence"`
+	ProjectQuality  *float64           `json:"project_quality"`
+	Actions         int                `json:"actions"`
+	Errors          int                `json:"errors"`
+	Observations    []string           `json:"observations"`
+	Recommendations []string           `json:"recommendations"`
+	Issues          []string           `json:"issues"`
+	Uncited         []string           `json:"uncited"`
+	Criteria        map[string]float64 `json:"criteria"`
+}
+
+// expertReportSchema returns the JSON schema expert reports must match,
+// with a property per rubric criterion.
+func expertReportSchema(r *Rubric) json.RawMessage {
+	score := map[string]any{"type": "number", "minimum": 0, "maximum": 100}
+	list := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	props := map[string]any{
+		"prompt_adherence": score,
+		"project_quality":  score,
+		"actions":          map[string]any{"type": "integer"},
+		"errors":           map[string]any{"type": "integer"},
+		"observations":     list,
+		"recommendations":  list,
+		"issues":           list,
+		"uncited":          list,
+	}
+	required := []string{"prompt_adherence", "project_quality", "observations", "recommendations"}
+	if r != nil {
+		criteria := make(map[string]any, len(r.Criteria))
+		names := make([]string, 0, len(r.Criteria))
+		for _, c := range r.Criteria {
+			criteria[strings.ToLower(c.Name)] = score
+			names = append(names, strings.ToLower(c.Name))
+		}
+		props["criteria"] = map[string]any{"type": "object", "properties": criteria, "required": names}
+		required = append(required, "criteria")
+	}
+	schema, _ := json.Marshal(map[string]any{"type": "object", "properties": props, "required": required})
+	return schema
+}
+
+// jsonInstructions asks for the report as JSON instead of the text format.
+const jsonInstructions = `
+
+Respond with a single JSON object instead of the text format above, using the keys
+prompt_adherence, project_quality (numbers 0-100), actions, errors (integers), and
+observations, recommendations, issues, uncited (arrays of strings)`
+
+// requestExpertReport asks an expert for its report as JSON matching the
+// report schema. An invalid report is re-asked once with the problems
+// listed. A report still invalid is used as far as it goes; a response that
+// is not JSON at all, or a server without JSON mode, falls back to the text
+// parser. Either way the problems are kept in the report's ParseErrors.
+func (c *Coordinator) requestExpertReport(ctx context.Context, client *ollama.Client, expert ExpertType, messages []ollama.Message) (*ExpertReport, error) {
+	c.mu.Lock()
+	rubric := c.rubric
+	c.mu.Unlock()
+
+	jsonMessages := append([]ollama.Message(nil), messages...)
+	jsonMessages[0].Content += jsonInstructions
+	if rubric != nil {
+		jsonMessages[0].Content += `, and criteria (an object of criterion name to score 0-100)`
+	}
+	jsonMessages[0].Content += "."
+	schema := expertReportSchema(rubric)
+
+	resp, stats, err := client.ChatJSON(ctx, jsonMessages, schema)
+	c.recordTokens(client, stats)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// The server may predate JSON mode: ask for the text format
+		resp, stats, terr := client.Chat(ctx, messages)
+		c.recordTokens(client, stats)
+		if terr != nil {
+			return nil, terr
+		}
+		report, _ := c.parseExpertAnalysis(expert, resp, stats)
+		report.ParseErrors = append(report.ParseErrors, "JSON mode failed: "+err.Error())
+		return report, nil
+	}
+
+	report, err := c.parseExpertJSON(expert, resp)
+	if err == nil {
+		return report, nil
+	}
+	first, firstResp := report, resp
+	retry := append(jsonMessages,
+		ollama.Message{Role: "assistant", Content: resp},
+		ollama.Message{Role: "user", Content: fmt.Sprintf("Your report could not be used: %v. Reply again with only the corrected JSON object.", err)},
+	)
+	resp, stats, rerr := client.ChatJSON(ctx, retry, schema)
+	c.recordTokens(client, stats)
+	if rerr == nil {
+		if report, rerr = c.parseExpertJSON(expert, resp); rerr == nil {
+			return report, nil
+		}
+	} else if ctx.Err() != nil {
+		return nil, rerr
+	}
+
+	switch {
+	case report != nil:
+	case first != nil:
+		report = first
+	case rerr != nil && resp == "":
+		report, _ = c.parseExpertAnalysis(expert, firstResp, stats)
+	default:
+		report, _ = c.parseExpertAnalysis(expert, resp, stats)
+	}
+	report.ParseErrors = append(report.ParseErrors, err.Error())
+	if rerr != nil && rerr.Error() != err.Error() {
+		report.ParseErrors = append(report.ParseErrors, rerr.Error())
+	}
+	return report, nil
+}
+
+// parseExpertJSON parses and validates a JSON expert report. A report that
+// decodes but fails validation is returned along with the error.
+func (c *Coordinator) parseExpertJSON(expert ExpertType, resp string) (*ExpertReport, error) {
+	var raw expertJSON
+	if err := json.Unmarshal([]byte(stripCodeFence(resp)), &raw); err != nil {
+		return nil, &ReportFormatError{Expert: expert, Problems: []string{"not a JSON object: " + err.Error()}}
+	}
+
+	c.mu.Lock()
+	rubric := c.rubric
+	c.mu.Unlock()
+
+	var problems []string
+	if raw.PromptAdherence == nil {
+		problems = append(problems, "prompt_adherence is missing")
+	}
+	if raw.ProjectQuality == nil {
+		problems = append(problems, "project_quality is missing")
+	}
+	if raw.Observations == nil {
+		problems = append(problems, "observations is missing")
+	}
+	if raw.Recommendations == nil {
+		problems = append(problems, "recommendations is missing")
+	}
+	if rubric != nil {
+		for _, cr := range rubric.Criteria {
+			if _, ok := lookupFold(raw.Criteria, cr.Name); !ok {
+				problems = append(problems, "criteria."+strings.ToLower(cr.Name)+" is missing")
+			}
+		}
+	}
+
+	report := &ExpertReport{
+		Expert:          expert,
+		ActionsTaken:    raw.Actions,
+		ErrorsMade:      raw.Errors,
+		Observations:    raw.Observations,
+		Recommendations: raw.Recommendations,
+		Issues:          dropNone(raw.Issues),
+		Uncited:         dropNone(raw.Uncited),
+		Timestamp:       time.Now(),
+	}
+	for _, o := range report.Observations {
+		report.Citations = append(report.Citations, citationsIn(o)...)
+	}
+	if len(raw.Criteria) > 0 {
+		report.Criteria = make(map[string]float64, len(raw.Criteria))
+		for name, score := range raw.Criteria {
+			report.Criteria[strings.ToLower(name)] = score
+		}
+	}
+	if raw.PromptAdherence != nil {
+		report.PromptAdherence = *raw.PromptAdherence
+	}
+	if raw.ProjectQuality != nil {
+		report.ProjectQuality = *raw.ProjectQuality
+	}
+	applyRubric(report, rubric, map[string]bool{
+		MetricPromptAdherence: raw.PromptAdherence != nil,
+		MetricProjectQuality:  raw.ProjectQuality != nil,
+	})
+	if len(problems) > 0 {
+		return report, &ReportFormatError{Expert: expert, Problems: problems}
+	}
+	return report, nil
+}
+
+// stripCodeFence removes a Markdown code fence around a response.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	if i := strings.Index(s, "\n"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
+
+// lookupFold looks up a key case-insensitively.
+func lookupFold(m map[string]float64, key string) (float64, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// dropNone removes "None" placeholders from a list.
+func dropNone(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if !strings.EqualFold(strings.TrimSpace(s), "none") {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// scriptedServer answers successive chat requests with replies in order,
+// each using 30 tokens, and records each request.
+func scriptedServer(t *testing.T, replies []string, requests *[]ollama.ChatRequest) *ollama.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		*requests = append(*requests, req)
+		reply := replies[0]
+		if len(replies) > 1 {
+			replies = replies[1:]
+		}
+		if reply == "unsupported" {
+			http.Error(w, "format not supported", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ollama.ChatResponse{Message: ollama.Message{Role: "assistant", Content: reply}, Done: true, PromptEvalCount: 20, EvalCount: 10})
+	}))
+	t.Cleanup(srv.Close)
+	return ollama.NewClient(ollama.WithBaseURL(srv.URL))
+}
+
+func TestRequestExpertReport_JSON(t *testing.T) {
+	var requests []ollama.ChatRequest
+	client := scriptedServer(t, []string{
+		`{"prompt_adherence": 80, "observations": ["ok"]}`,
+		"```json\n" + `{"prompt_adherence": 80, "project_quality": 70, "observations": ["Reads the docs [S1]"], "recommendations": ["Add tests"], "issues": ["None"]}` + "\n```",
+	}, &requests)
+	c := NewCoordinator(nil, nil, nil, nil)
+	c.SetRubric(nil)
+	var charged int64
+	c.SetTokenCallback(func(cl *ollama.Client, tokens int64) {
+		if cl == client {
+			charged += tokens
+		}
+	})
+
+	messages := []ollama.Message{{Role: "system", Content: "Judge."}, {Role: "user", Content: "Session"}}
+	report, err := c.requestExpertReport(context.Background(), client, ExpertCoder, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("%d requests, want the report and one re-ask", len(requests))
+	}
+	if charged != 60 {
+		t.Errorf("charged %d tokens, want both requests' 60", charged)
+	}
+	if !strings.Contains(string(requests[0].Format), `"prompt_adherence"`) {
+		t.Errorf("Format = %s, want the report schema", requests[0].Format)
+	}
+	reask := requests[1].Messages[len(requests[1].Messages)-1].Content
+	if !strings.Contains(reask, "project_quality is missing") || !strings.Contains(reask, "recommendations") {
+		t.Errorf("re-ask = %q, want the problems listed", reask)
+	}
+	if report.ProjectQuality != 70 || len(report.Citations) != 1 || len(report.Issues) != 0 || len(report.ParseErrors) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if messages[0].Content != "Judge." {
+		t.Error("caller's messages were modified")
+	}
+}
+
+func TestRequestExpertReport_Fallback(t *testing.T) {
+	text := "PROMPT_ADHERENCE: 60\nPROJECT_QUALITY: 50\nOBSERVATIONS:\n- Plain text"
+	c := NewCoordinator(nil, nil, nil, nil)
+	c.SetRubric(nil)
+	messages := []ollama.Message{{Role: "system", Content: "Judge."}, {Role: "user", Content: "Session"}}
+
+	// A model that keeps answering in text is parsed as text
+	var requests []ollama.ChatRequest
+	report, err := c.requestExpertReport(context.Background(), scriptedServer(t, []string{text}, &requests), ExpertCoder, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PromptAdherence != 60 || len(report.Observations) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.ParseErrors) != 1 || !strings.Contains(report.ParseErrors[0], "not a JSON object") {
+		t.Errorf("ParseErrors = %v", report.ParseErrors)
+	}
+
+	// A server without JSON mode is asked for the text format
+	requests = nil
+	report, err = c.requestExpertReport(context.Background(), scriptedServer(t, []string{"unsupported", text}, &requests), ExpertCoder, messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[1].Format != nil || requests[1].Messages[0].Content != "Judge." {
+		t.Errorf("fallback request = %+v", requests[len(requests)-1])
+	}
+	if report.ProjectQuality != 50 || len(report.ParseErrors) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+}
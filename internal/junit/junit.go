@@ -0,0 +1,138 @@
+// Package junit writes test results as JUnit XML, the report format CI
+// systems display natively.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+)
+
+// Report is a set of test suites.
+type Report struct {
+	XMLName  xml.Name `xml:"testsuites"`
+	Name     string   `xml:"name,attr"`
+	Tests    int      `xml:"tests,attr"`
+	Failures int      `xml:"failures,attr"`
+	Skipped  int      `xml:"skipped,attr"`
+	Time     string   `xml:"time,attr"`
+	Suites   []Suite  `xml:"testsuite"`
+
+	total time.Duration
+}
+
+// Suite is the tests run by one test command.
+type Suite struct {
+	Name      string  `xml:"name,attr"`
+	Tests     int     `xml:"tests,attr"`
+	Failures  int     `xml:"failures,attr"`
+	Skipped   int     `xml:"skipped,attr"`
+	Time      string  `xml:"time,attr"`
+	Timestamp string  `xml:"timestamp,attr,omitempty"`
+	Cases     []Case  `xml:"testcase"`
+	SystemOut *Output `xml:"system-out"`
+}
+
+// Output is a command's output, kept verbatim.
+type Output struct {
+	Text string `xml:",cdata"`
+}
+
+// Case is one test's outcome.
+type Case struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure"`
+	Skipped   *Skipped `xml:"skipped"`
+}
+
+// Failure marks a failed test.
+type Failure struct {
+	Message string `xml:"message,attr"`
+}
+
+// Skipped marks a skipped test.
+type Skipped struct{}
+
+// maxSystemOut bounds the command output kept in a suite that failed.
+const maxSystemOut = 64 * 1024
+
+// New returns an empty report.
+func New(name string) *Report {
+	return &Report{Name: name, Time: seconds(0)}
+}
+
+// AddSuite adds the tests of a test action as a suite named for its
+// toolchain and path. When the toolchain does not parse its test output,
+// the command itself is the suite's one test. The command's output is
+// kept when a test failed.
+func (r *Report) AddSuite(action agent.Action) {
+	if action.ToolResult == nil {
+		return
+	}
+	suite := Suite{Name: fmt.Sprintf("%s %s", action.ToolResult.Toolchain, action.Path)}
+	if !action.Timestamp.IsZero() {
+		suite.Timestamp = action.Timestamp.UTC().Format("2006-01-02T15:04:05")
+	}
+	tests := action.ToolResult.Tests
+	if len(tests) == 0 {
+		// Output the toolchain cannot parse: the command is the one test
+		status := agent.TestPassed
+		if s, _ := action.Metadata["status"].(string); s == "failed" || action.ExitCode != 0 {
+			status = agent.TestFailed
+		}
+		tests = []agent.TestCase{{Name: action.Command, Status: status, Duration: action.Duration}}
+	}
+	var total time.Duration
+	for _, t := range tests {
+		c := Case{Name: t.Name, Classname: suite.Name, Time: seconds(t.Duration)}
+		switch t.Status {
+		case agent.TestFailed:
+			c.Failure = &Failure{Message: "test failed"}
+			suite.Failures++
+		case agent.TestSkipped:
+			c.Skipped = &Skipped{}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, c)
+		total += t.Duration
+	}
+	suite.Tests = len(suite.Cases)
+	if action.Duration > total {
+		total = action.Duration
+	}
+	suite.Time = seconds(total)
+	if suite.Failures > 0 {
+		out := action.Output
+		if len(out) > maxSystemOut {
+			out = out[len(out)-maxSystemOut:]
+		}
+		suite.SystemOut = &Output{Text: out}
+	}
+
+	r.Suites = append(r.Suites, suite)
+	r.Tests += suite.Tests
+	r.Failures += suite.Failures
+	r.Skipped += suite.Skipped
+	r.total += total
+	r.Time = seconds(r.total)
+}
+
+// Write writes the report to path.
+func (r *Report) Write(path string) error {
+	data, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// seconds formats d as JUnit's decimal seconds.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,55 @@
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/test"
+)
+
+func TestReport_Write(t *testing.T) {
+	at := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	report := New("obot verify")
+	report.AddSuite(agent.Action{
+		Type:      agent.ActionTest,
+		Path:      "./api",
+		Timestamp: at,
+		Output:    "--- FAIL: TestPost\nFAIL\n",
+		ToolResult: &agent.ToolchainResult{Toolchain: "go", Tests: []agent.TestCase{
+			{Name: "TestGet", Status: agent.TestPassed, Duration: 20 * time.Millisecond},
+			{Name: "TestPost", Status: agent.TestFailed, Duration: 5 * time.Millisecond},
+			{Name: "TestSlow", Status: agent.TestSkipped},
+		}},
+	})
+	report.AddSuite(agent.Action{
+		Type:       agent.ActionTest,
+		Path:       "web/app.test.ts",
+		Command:    "npm test -- web/app.test.ts",
+		Timestamp:  at,
+		Duration:   2 * time.Second,
+		Metadata:   map[string]any{"status": "success"},
+		ToolResult: &agent.ToolchainResult{Toolchain: "typescript"},
+	})
+
+	if report.Tests != 4 || report.Failures != 1 || report.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d skipped", report.Tests, report.Failures, report.Skipped)
+	}
+
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	if err := report.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Report
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("written report is not valid XML: %v", err)
+	}
+	test.AssertGolden(t, "junit", data)
+}
@@ -0,0 +1,164 @@
+// Package logging writes obot's persistent structured log: JSON lines in a
+// rotating file, apart from the terminal UI. Lines logged with a context
+// carry the correlation IDs attached to it, such as the session, schedule,
+// and process, and secrets are redacted from them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/croberts/obot/internal/redact"
+)
+
+// Log file defaults
+const (
+	FileName        = "obot.log"
+	DefaultMaxSize  = 10 << 20 // bytes before the file is rotated
+	DefaultMaxFiles = 5        // rotated files kept
+)
+
+// LevelOff disables logging when given as the level.
+const LevelOff = slog.Level(100)
+
+// Options configures Setup.
+type Options struct {
+	Dir      string     // directory of the log files
+	Level    slog.Level // least severe level written
+	MaxSize  int64      // bytes before rotating; 0 uses DefaultMaxSize
+	MaxFiles int        // rotated files kept; 0 uses DefaultMaxFiles
+}
+
+// ParseLevel parses a --log-level value: debug, info, warn, error, or off.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "off", "none":
+		return LevelOff, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (debug, info, warn, error, off)", s)
+}
+
+// Setup opens the log file in opts.Dir and makes a logger writing to it the
+// slog default, so packages log with slog.InfoContext and the like. The
+// returned function closes the file. With LevelOff nothing is opened and
+// logging is discarded.
+func Setup(opts Options) (close func() error, err error) {
+	if opts.Level >= LevelOff {
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return func() error { return nil }, nil
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultMaxFiles
+	}
+	file, err := OpenRotatingFile(filepath.Join(opts.Dir, FileName), opts.MaxSize, opts.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(NewHandler(file, opts.Level)))
+	return file.Close, nil
+}
+
+// NewHandler returns a JSON handler writing records at level and above to
+// w, with the context's correlation IDs added and secrets redacted.
+func NewHandler(w io.Writer, level slog.Level) slog.Handler {
+	return &handler{next: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr})}
+}
+
+// ctxKey keys the correlation attributes in a context.
+type ctxKey struct{}
+
+// WithAttrs returns a context whose log lines carry the given key-value
+// pairs, such as "session", id, in addition to those ctx already carries.
+// A key set again replaces its earlier value.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	attrs := append([]slog.Attr(nil), Attrs(ctx)...)
+	for _, a := range argsToAttrs(args) {
+		replaced := false
+		for i := range attrs {
+			if attrs[i].Key == a.Key {
+				attrs[i], replaced = a, true
+			}
+		}
+		if !replaced {
+			attrs = append(attrs, a)
+		}
+	}
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+// Attrs returns the correlation attributes of ctx.
+func Attrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// argsToAttrs converts alternating keys and values to attributes.
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+// handler adds correlation attributes and redacts messages.
+type handler struct {
+	next slog.Handler
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	msg, _ := logRedactor.Redact(r.Message)
+	out := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	out.AddAttrs(Attrs(ctx)...)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(a)
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name)}
+}
+
+// logRedactor redacts log lines without reporting it.
+var logRedactor = redact.New(nil)
+
+// redactAttr removes secrets from string attribute values.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindString {
+		if v, findings := logRedactor.Redact(a.Value.String()); findings != nil {
+			a.Value = slog.StringValue(v)
+		}
+	}
+	return a
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RotatingFile is a log file that is rotated when it grows past a size:
+// name.log becomes name.1.log, name.1.log becomes name.2.log, and so on,
+// and the oldest beyond the kept count is removed.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotatingFile opens path for appending, creating its directory. The
+// file is rotated once it exceeds maxSize bytes, keeping maxFiles rotated
+// files besides the current one.
+func OpenRotatingFile(path string, maxSize int64, maxFiles int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p, rotating first when p would take the file past its
+// size limit. A single write is never split across files.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Path returns the path of the current file.
+func (r *RotatingFile) Path() string {
+	return r.path
+}
+
+// open opens the current file for appending.
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+	r.file, r.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts the rotated files up by one, drops the oldest, and starts
+// a new current file.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	r.file = nil
+	os.Remove(r.rotated(r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		os.Rename(r.rotated(i), r.rotated(i+1))
+	}
+	if r.maxFiles > 0 {
+		if err := os.Rename(r.path, r.rotated(1)); err != nil && !os.IsNotExist(err) {
+			// Keep writing to the current file rather than closing the log.
+			r.open()
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+// rotated returns the path of the nth rotated file.
+func (r *RotatingFile) rotated(n int) string {
+	ext := filepath.Ext(r.path)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(r.path, ext), n, ext)
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
 )
 
 // Coordinator manages model selection and coordination.
@@ -36,6 +38,35 @@ type Coordinator struct {
 
 	// Statistics
 	tokenCounts map[orchestrate.ModelType]int64
+
+	// Per-role quotas
+	quotas  map[orchestrate.ModelType]*quotaState
+	onQuota func(QuotaEvent)
+	now     func() time.Time
+	sleep   func(context.Context, time.Duration) error
+
+	// Model handoffs
+	handoffs        []Handoff
+	pendingHandoffs map[orchestrate.ModelType]Handoff
+	onHandoff       func(Handoff, error)
+
+	// Ollama hosts the roles are routed to; nil sends every role to ollamaURL
+	hosts *hostSet
+
+	// GPU memory, consulted when selecting a model
+	vram VRAMReporter
+
+	// Quality traded for memory under pressure
+	degrade degradeState
+
+	// How the next schedule and process are decided
+	strategy orchestrate.Strategy
+}
+
+// VRAMReporter reports the GPU memory, such as *resource.Monitor.
+type VRAMReporter interface {
+	GetVRAM() resource.VRAM
+	GetVRAMPressure() resource.PressureStatus
 }
 
 // ModelConfig contains configuration for a specific model
@@ -123,11 +154,17 @@ func NewCoordinator(client *ollama.Client) *Coordinator {
 	}
 
 	c := &Coordinator{
-		client:      client,
-		models:      DefaultModels(),
-		clients:     make(map[orchestrate.ModelType]*ollama.Client),
-		ollamaURL:   url,
-		tokenCounts: make(map[orchestrate.ModelType]int64),
+		client:          client,
+		models:          DefaultModels(),
+		clients:         make(map[orchestrate.ModelType]*ollama.Client),
+		ollamaURL:       url,
+		tokenCounts:     make(map[orchestrate.ModelType]int64),
+		quotas:          make(map[orchestrate.ModelType]*quotaState),
+		pendingHandoffs: make(map[orchestrate.ModelType]Handoff),
+		degrade:         degradeState{level: resource.PressureNormal},
+		now:             time.Now,
+		sleep:           sleepContext,
+		strategy:        orchestrate.HeuristicStrategy{},
 	}
 
 	// Initialize individual clients for each role
@@ -162,8 +199,21 @@ func (c *Coordinator) SetModel(modelType orchestrate.ModelType, name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if _, ok := c.models[modelType]; ok {
+		c.setRoleModel(modelType, name)
+	}
+}
+
+// SetTemperature overrides a role's sampling temperature
+func (c *Coordinator) SetTemperature(modelType orchestrate.ModelType, temperature float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if config, ok := c.models[modelType]; ok {
-		config.Name = name
+		config.Temperature = temperature
+		if client := c.clients[modelType]; client != nil {
+			client.SetTemperature(temperature)
+		}
 	}
 }
 
@@ -188,7 +238,7 @@ func (c *Coordinator) SelectModelForSchedule(scheduleID orchestrate.ScheduleID)
 	case orchestrate.ScheduleProduction:
 		return []orchestrate.ModelType{orchestrate.ModelCoder, orchestrate.ModelVision}
 	default:
-		return []orchestrate.ModelType{orchestrate.ModelCoder}
+		return []orchestrate.ModelType{orchestrate.GetScheduleModel(scheduleID)}
 	}
 }
 
@@ -204,6 +254,11 @@ func (c *Coordinator) SelectModelForProcess(scheduleID orchestrate.ScheduleID, p
 		return orchestrate.ModelResearcher
 	}
 
+	// Custom schedules use the model role from their definition
+	if orchestrate.IsCustomSchedule(scheduleID) {
+		return orchestrate.GetScheduleModel(scheduleID)
+	}
+
 	// Default to coder
 	return orchestrate.ModelCoder
 }
@@ -213,6 +268,31 @@ func (c *Coordinator) RecordTokens(modelType orchestrate.ModelType, tokens int64
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.tokenCounts[modelType] += tokens
+	if st := c.quotas[modelType]; st != nil {
+		st.roll(c.now())
+		st.tokensToday += tokens
+	}
+}
+
+// RecordClientTokens records token usage of a request made through client
+// outside the agent, such as by the judges, charging it to the role whose
+// client it is. Tokens of a client no role has are not recorded.
+func (c *Coordinator) RecordClientTokens(client *ollama.Client, tokens int64) {
+	if client == nil || tokens <= 0 {
+		return
+	}
+	c.mu.Lock()
+	var role orchestrate.ModelType
+	for r, cl := range c.clients {
+		if cl == client {
+			role = r
+			break
+		}
+	}
+	c.mu.Unlock()
+	if role != "" {
+		c.RecordTokens(role, tokens)
+	}
 }
 
 // GetTokenCounts returns token counts by model
@@ -227,26 +307,6 @@ func (c *Coordinator) GetTokenCounts() map[orchestrate.ModelType]int64 {
 	return result
 }
 
-// Handoff transfers control between models
-type Handoff struct {
-	From     orchestrate.ModelType
-	To       orchestrate.ModelType
-	Schedule orchestrate.ScheduleID
-	Process  orchestrate.ProcessID
-	Context  string // Context to pass
-}
-
-// HandoffProtocol executes a model handoff
-func (c *Coordinator) HandoffProtocol(ctx context.Context, handoff Handoff) error {
-	c.mu.Lock()
-	c.activeModel = handoff.To
-	c.mu.Unlock()
-
-	// Log the handoff
-	// In a real implementation, this would manage model lifecycle
-	return nil
-}
-
 // GetActiveModel returns the currently active model
 func (c *Coordinator) GetActiveModel() orchestrate.ModelType {
 	c.mu.Lock()
@@ -333,45 +393,31 @@ func (c *Coordinator) SelectModelForIntent(intent string) orchestrate.ModelType
 	}
 }
 
-// SelectNextSchedule uses the orchestrator model to decide the next schedule
+// SetStrategy sets how the next schedule and process are decided; the
+// default is orchestrate.HeuristicStrategy.
+func (c *Coordinator) SetStrategy(s orchestrate.Strategy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strategy = s
+}
+
+// Strategy returns how the next schedule and process are decided.
+func (c *Coordinator) Strategy() orchestrate.Strategy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.strategy
+}
+
+// SelectNextSchedule decides the next schedule with the strategy; true
+// terminates the prompt.
 func (c *Coordinator) SelectNextSchedule(ctx context.Context, orch *orchestrate.Orchestrator) (orchestrate.ScheduleID, bool, error) {
-	stats := orch.GetStats()
-	
-	// Check if we can and should terminate
-	if orch.CanTerminatePrompt() {
-		// Use orchestrator model to decide - for now, simple heuristic
-		// In full implementation, this would call the LLM
-		return 0, true, nil
-	}
-	
-	// Simple round-robin for demonstration
-	// In full implementation, the orchestrator LLM would decide
-	for schedID := orchestrate.ScheduleKnowledge; schedID <= orchestrate.ScheduleProduction; schedID++ {
-		if stats.SchedulingsByID[schedID] == 0 {
-			return schedID, false, nil
-		}
-	}
-	
-	// If all schedules have run once, return to Production
-	return orchestrate.ScheduleProduction, false, nil
+	return c.Strategy().SelectSchedule(ctx, orch)
 }
 
-// SelectNextProcess uses the model to decide the next process
+// SelectNextProcess decides the process after lastProc with the strategy;
+// true terminates the schedule.
 func (c *Coordinator) SelectNextProcess(ctx context.Context, orch *orchestrate.Orchestrator, schedID orchestrate.ScheduleID, lastProc orchestrate.ProcessID) (orchestrate.ProcessID, bool, error) {
-	// Enforce navigation rules
-	switch lastProc {
-	case orchestrate.Process1:
-		// From P1: go to P2
-		return orchestrate.Process2, false, nil
-	case orchestrate.Process2:
-		// From P2: go to P3
-		return orchestrate.Process3, false, nil
-	case orchestrate.Process3:
-		// From P3: terminate schedule
-		return 0, true, nil
-	default:
-		return orchestrate.Process1, false, nil
-	}
+	return c.Strategy().SelectProcess(ctx, orch, schedID, lastProc)
 }
 
 // RAMTier represents the system RAM capacity.
@@ -397,7 +443,9 @@ const (
 )
 
 // SelectOptimalModel selects the best model role based on intent and RAM tier.
-// If the optimal role is unavailable, it falls back to the orchestrator model.
+// If the optimal role is unavailable, it falls back to the orchestrator model,
+// as it does when VRAM is critically full and only the orchestrator's model
+// is loaded.
 func (c *Coordinator) SelectOptimalModel(intent IntentType, ram RAMTier) orchestrate.ModelType {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -433,9 +481,29 @@ func (c *Coordinator) SelectOptimalModel(intent IntentType, ram RAMTier) orchest
 		return orchestrate.ModelOrchestrator // Final fallback
 	}
 
+	// Under critical VRAM pressure, loading another model would evict one
+	// or spill into RAM, so stay with the orchestrator model if it is loaded
+	if c.vram != nil && optimal != orchestrate.ModelOrchestrator &&
+		c.vram.GetVRAMPressure() == resource.PressureCritical {
+		v := c.vram.GetVRAM()
+		_, optimalLoaded := v.Loaded(c.models[optimal].Name)
+		_, orchLoaded := v.Loaded(c.models[orchestrate.ModelOrchestrator].Name)
+		if !optimalLoaded && orchLoaded {
+			return orchestrate.ModelOrchestrator
+		}
+	}
+
 	return optimal
 }
 
+// SetVRAMReporter sets where SelectOptimalModel reads the GPU memory; nil
+// selects on RAM alone.
+func (c *Coordinator) SetVRAMReporter(r VRAMReporter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vram = r
+}
+
 // GetRAMTier returns the RAM tier based on total system memory in GB.
 func GetRAMTier(totalGB float64) RAMTier {
 	if totalGB < 16 {
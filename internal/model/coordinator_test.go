@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
 )
 
 func TestNewCoordinator(t *testing.T) {
@@ -112,3 +113,45 @@ func TestMapIntent(t *testing.T) {
 		t.Error("optimize prompt should map to IntentOptimization")
 	}
 }
+
+// fakeVRAM reports fixed GPU memory.
+type fakeVRAM struct {
+	vram     resource.VRAM
+	pressure resource.PressureStatus
+}
+
+func (f fakeVRAM) GetVRAM() resource.VRAM                   { return f.vram }
+func (f fakeVRAM) GetVRAMPressure() resource.PressureStatus { return f.pressure }
+
+func TestSelectOptimalModel_VRAMPressure(t *testing.T) {
+	c := NewCoordinator(nil)
+	orchModel := c.GetModel(orchestrate.ModelOrchestrator).Name
+	vram := resource.VRAM{TotalGB: 24, UsedGB: 23.5, Models: []resource.LoadedModel{{Name: orchModel}}}
+
+	c.SetVRAMReporter(fakeVRAM{vram: vram, pressure: resource.PressureNormal})
+	if got := c.SelectOptimalModel(IntentCoding, RAMPerformance); got != orchestrate.ModelCoder {
+		t.Errorf("normal VRAM selected %s, want coder", got)
+	}
+
+	c.SetVRAMReporter(fakeVRAM{vram: vram, pressure: resource.PressureCritical})
+	if got := c.SelectOptimalModel(IntentCoding, RAMPerformance); got != orchestrate.ModelOrchestrator {
+		t.Errorf("critical VRAM selected %s, want the loaded orchestrator", got)
+	}
+
+	vram.Models = append(vram.Models, resource.LoadedModel{Name: c.GetModel(orchestrate.ModelCoder).Name})
+	c.SetVRAMReporter(fakeVRAM{vram: vram, pressure: resource.PressureCritical})
+	if got := c.SelectOptimalModel(IntentCoding, RAMPerformance); got != orchestrate.ModelCoder {
+		t.Errorf("critical VRAM with the coder loaded selected %s, want coder", got)
+	}
+}
+
+func TestCoordinator_SetTemperature(t *testing.T) {
+	c := NewCoordinator(nil)
+	c.SetTemperature(orchestrate.ModelCoder, 0.2)
+	if got := c.GetModel(orchestrate.ModelCoder).Temperature; got != 0.2 {
+		t.Errorf("coder temperature = %v, want 0.2", got)
+	}
+	if got := c.GetModel(orchestrate.ModelResearcher).Temperature; got != 0.5 {
+		t.Errorf("researcher temperature = %v, want its default 0.5", got)
+	}
+}
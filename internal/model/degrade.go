@@ -0,0 +1,162 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+)
+
+// minDegradedContext is the smallest context window pressure reduces to.
+const minDegradedContext = 2048
+
+// degradeState is what Degrade has changed, to restore once the pressure
+// passes.
+type degradeState struct {
+	level         resource.PressureStatus
+	contextWindow int // the undegraded window; 0 leaves the window alone
+	smaller       map[orchestrate.ModelType]string
+	original      map[orchestrate.ModelType]string // models replaced by smaller ones
+}
+
+// SetContextWindow sets the context window of every role's client, the
+// window that Degrade reduces under pressure.
+func (c *Coordinator) SetContextWindow(tokens int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.degrade.contextWindow = tokens
+	c.applyContextWindow()
+}
+
+// SetSmallerModels sets the model each role switches to under critical
+// memory pressure. Roles without one keep their model.
+func (c *Coordinator) SetSmallerModels(smaller map[orchestrate.ModelType]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.degrade.smaller = smaller
+}
+
+// Serialized reports whether work that would run alongside the agent, such
+// as speculative decisions, should wait instead.
+func (c *Coordinator) Serialized() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.degradeSerialized()
+}
+
+func (c *Coordinator) degradeSerialized() bool {
+	for _, m := range resource.Mitigations(c.degrade.level) {
+		if m == resource.MitigateSerialize {
+			return true
+		}
+	}
+	return false
+}
+
+// Degrade applies the mitigations of resource.Mitigations for level,
+// undoing those the level no longer needs, and returns each change made.
+// It returns nothing when level is the level already applied.
+func (c *Coordinator) Degrade(level resource.PressureStatus) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if level == "" {
+		level = resource.PressureNormal
+	}
+	if level == c.degrade.level {
+		return nil
+	}
+	wasSerial := c.degradeSerialized()
+	oldWindow := c.contextWindow()
+	c.degrade.level = level
+
+	var steps []string
+	if window := c.contextWindow(); window != oldWindow {
+		c.applyContextWindow()
+		steps = append(steps, fmt.Sprintf("context window %d -> %d tokens", oldWindow, window))
+	}
+	if serial := c.degradeSerialized(); serial != wasSerial {
+		if serial {
+			steps = append(steps, "speculative decisions paused")
+		} else {
+			steps = append(steps, "speculative decisions resumed")
+		}
+	}
+	steps = append(steps, c.swapModels()...)
+	return steps
+}
+
+// contextWindow returns the window for the applied level: the full window
+// when normal, half under a warning, and a quarter when critical.
+func (c *Coordinator) contextWindow() int {
+	window := c.degrade.contextWindow
+	if window <= 0 {
+		return 0
+	}
+	switch c.degrade.level {
+	case resource.PressureWarning:
+		window /= 2
+	case resource.PressureCritical:
+		window /= 4
+	}
+	return max(window, min(c.degrade.contextWindow, minDegradedContext))
+}
+
+func (c *Coordinator) applyContextWindow() {
+	window := c.contextWindow()
+	if window <= 0 {
+		return
+	}
+	for _, client := range c.clients {
+		client.SetContextWindow(window)
+	}
+}
+
+// swapModels switches roles to their smaller models under critical
+// pressure and back otherwise, returning each switch.
+func (c *Coordinator) swapModels() []string {
+	critical := c.degrade.level == resource.PressureCritical
+	var steps []string
+	for _, role := range c.degradeRoles() {
+		config := c.models[role]
+		if config == nil {
+			continue
+		}
+		if critical {
+			smaller := c.degrade.smaller[role]
+			if _, swapped := c.degrade.original[role]; swapped || smaller == "" || smaller == config.Name {
+				continue
+			}
+			if c.degrade.original == nil {
+				c.degrade.original = make(map[orchestrate.ModelType]string)
+			}
+			c.degrade.original[role] = config.Name
+			steps = append(steps, fmt.Sprintf("%s: %s -> %s", role, config.Name, smaller))
+			c.setRoleModel(role, smaller)
+		} else if original, swapped := c.degrade.original[role]; swapped {
+			delete(c.degrade.original, role)
+			steps = append(steps, fmt.Sprintf("%s: %s -> %s", role, config.Name, original))
+			c.setRoleModel(role, original)
+		}
+	}
+	return steps
+}
+
+// degradeRoles returns the roles in a stable order.
+func (c *Coordinator) degradeRoles() []orchestrate.ModelType {
+	roles := make([]orchestrate.ModelType, 0, len(c.models))
+	for role := range c.models {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
+}
+
+// setRoleModel changes the model a role's client requests.
+func (c *Coordinator) setRoleModel(role orchestrate.ModelType, name string) {
+	c.models[role].Name = name
+	if client := c.clients[role]; client != nil {
+		client.SetModel(name)
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+)
+
+func TestDegrade_StepsAndRestore(t *testing.T) {
+	c := NewCoordinator(nil)
+	coder := c.GetModel(orchestrate.ModelCoder).Name
+	c.SetContextWindow(8192)
+	c.SetSmallerModels(map[orchestrate.ModelType]string{orchestrate.ModelCoder: "tiny-coder"})
+
+	steps := c.Degrade(resource.PressureWarning)
+	if len(steps) != 2 || steps[0] != "context window 8192 -> 4096 tokens" {
+		t.Fatalf("warning steps = %v", steps)
+	}
+	if !c.Serialized() {
+		t.Error("warning did not serialize parallel work")
+	}
+	if c.GetModel(orchestrate.ModelCoder).Name != coder {
+		t.Error("warning switched the coder to a smaller model")
+	}
+	if steps := c.Degrade(resource.PressureWarning); steps != nil {
+		t.Errorf("repeated warning steps = %v, want none", steps)
+	}
+
+	steps = c.Degrade(resource.PressureCritical)
+	if len(steps) != 2 || steps[0] != "context window 4096 -> 2048 tokens" {
+		t.Fatalf("critical steps = %v", steps)
+	}
+	if got := c.Get(orchestrate.ModelCoder).GetModel(); got != "tiny-coder" {
+		t.Errorf("critical coder client model = %s, want tiny-coder", got)
+	}
+
+	steps = c.Degrade(resource.PressureNormal)
+	if len(steps) != 3 || c.Serialized() {
+		t.Fatalf("normal steps = %v, serialized = %v", steps, c.Serialized())
+	}
+	if got := c.GetModel(orchestrate.ModelCoder).Name; got != coder {
+		t.Errorf("restored coder = %s, want %s", got, coder)
+	}
+}
+
+func TestDegrade_ContextFloor(t *testing.T) {
+	c := NewCoordinator(nil)
+	c.SetContextWindow(4096)
+	steps := c.Degrade(resource.PressureCritical)
+	if len(steps) == 0 || steps[0] != "context window 4096 -> 2048 tokens" {
+		t.Errorf("critical steps = %v, want the window floored at 2048", steps)
+	}
+}
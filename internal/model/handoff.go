@@ -0,0 +1,235 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Limits on handoff summaries, which are injected into the incoming model's
+// prompt and must stay small.
+const (
+	maxHandoffContext = 6000 // characters of outgoing work shown to the summarizer
+	maxHandoffChars   = 4000
+	maxHandoffItems   = 15
+)
+
+// Handoff transfers control between models
+type Handoff struct {
+	From     orchestrate.ModelType
+	To       orchestrate.ModelType
+	Schedule orchestrate.ScheduleID
+	Process  orchestrate.ProcessID
+	Context  string // Context to pass
+
+	// Summary is the outgoing model's account of its work, produced by
+	// HandoffProtocol when not supplied.
+	Summary   *HandoffSummary
+	Timestamp time.Time
+}
+
+// HandoffSummary is the structured context an outgoing model leaves for the
+// incoming one.
+type HandoffSummary struct {
+	State         string   `json:"state"`
+	OpenQuestions []string `json:"open_questions,omitempty"`
+	Artifacts     []string `json:"artifacts,omitempty"`
+}
+
+// Validate checks that the summary describes the state and stays within
+// the size limits.
+func (s HandoffSummary) Validate() error {
+	if strings.TrimSpace(s.State) == "" {
+		return errors.New("handoff summary has no STATE")
+	}
+	if len(s.OpenQuestions) > maxHandoffItems {
+		return fmt.Errorf("handoff summary has %d open questions (max %d)", len(s.OpenQuestions), maxHandoffItems)
+	}
+	if len(s.Artifacts) > maxHandoffItems {
+		return fmt.Errorf("handoff summary has %d artifacts (max %d)", len(s.Artifacts), maxHandoffItems)
+	}
+	size := len(s.State)
+	for _, item := range append(append([]string{}, s.OpenQuestions...), s.Artifacts...) {
+		if strings.TrimSpace(item) == "" {
+			return errors.New("handoff summary has an empty item")
+		}
+		size += len(item)
+	}
+	if size > maxHandoffChars {
+		return fmt.Errorf("handoff summary is %d characters (max %d)", size, maxHandoffChars)
+	}
+	return nil
+}
+
+// Prompt formats the summary for the incoming model's first prompt.
+func (s HandoffSummary) Prompt(from orchestrate.ModelType) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HANDOFF FROM %s MODEL:\n", strings.ToUpper(string(from)))
+	sb.WriteString("State: " + s.State + "\n")
+	if len(s.OpenQuestions) > 0 {
+		sb.WriteString("Open questions:\n")
+		for _, q := range s.OpenQuestions {
+			sb.WriteString("- " + q + "\n")
+		}
+	}
+	if len(s.Artifacts) > 0 {
+		sb.WriteString("Artifacts:\n")
+		for _, a := range s.Artifacts {
+			sb.WriteString("- " + a + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// ParseHandoffSummary parses a summary written in the format requested by
+// handoffRequest: a STATE line followed by OPEN QUESTIONS and ARTIFACTS lists.
+func ParseHandoffSummary(resp string) HandoffSummary {
+	var s HandoffSummary
+	var section string
+	for _, line := range strings.Split(resp, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "STATE:"):
+			s.State = strings.TrimSpace(line[len("STATE:"):])
+			section = "state"
+			continue
+		case strings.HasPrefix(upper, "OPEN QUESTIONS:"):
+			section = "questions"
+			continue
+		case strings.HasPrefix(upper, "ARTIFACTS:"):
+			section = "artifacts"
+			continue
+		}
+
+		item, isItem := strings.CutPrefix(line, "- ")
+		if !isItem {
+			item, isItem = strings.CutPrefix(line, "* ")
+		}
+		switch {
+		case section == "state" && !isItem:
+			s.State = strings.TrimSpace(s.State + " " + line)
+		case !isItem || strings.EqualFold(item, "none"):
+		case section == "questions":
+			s.OpenQuestions = append(s.OpenQuestions, item)
+		case section == "artifacts":
+			s.Artifacts = append(s.Artifacts, item)
+		}
+	}
+	return s
+}
+
+// handoffRequest asks the outgoing model to summarize its work.
+func handoffRequest(h Handoff) string {
+	work := h.Context
+	if len(work) > maxHandoffContext {
+		work = work[len(work)-maxHandoffContext:]
+	}
+	return fmt.Sprintf(`You are handing off work to the %s model, which continues with %s %s.
+Summarize your work below for it using EXACTLY this format:
+
+STATE: [one paragraph: what was done and where things stand]
+OPEN QUESTIONS:
+- [unresolved question, or None]
+ARTIFACTS:
+- [file path or other artifact produced or inspected, or None]
+
+YOUR WORK:
+%s`, h.To, orchestrate.ScheduleNames[h.Schedule], orchestrate.ProcessNames[h.Schedule][h.Process], work)
+}
+
+// HandoffProtocol executes a model handoff. When control passes to a
+// different model and the outgoing model left work in Context, the outgoing
+// model summarizes it; a valid summary is queued for the incoming model's
+// next prompt (see TakeHandoffPrompt). Every attempted handoff is reported to
+// the handoff callback. A summary that cannot be produced or is invalid is
+// discarded and returned as an error, but the active model still changes.
+func (c *Coordinator) HandoffProtocol(ctx context.Context, handoff Handoff) error {
+	c.mu.Lock()
+	c.activeModel = handoff.To
+	client := c.clients[handoff.From]
+	c.mu.Unlock()
+
+	if handoff.From == "" || handoff.From == handoff.To {
+		return nil
+	}
+	if handoff.Summary == nil && (client == nil || strings.TrimSpace(handoff.Context) == "") {
+		return nil
+	}
+	if handoff.Timestamp.IsZero() {
+		handoff.Timestamp = c.now()
+	}
+
+	err := c.summarizeHandoff(ctx, client, &handoff)
+
+	c.mu.Lock()
+	if err == nil {
+		c.handoffs = append(c.handoffs, handoff)
+		c.pendingHandoffs[handoff.To] = handoff
+	}
+	notify := c.onHandoff
+	c.mu.Unlock()
+
+	if notify != nil {
+		notify(handoff, err)
+	}
+	return err
+}
+
+// summarizeHandoff asks the outgoing model for the handoff summary unless
+// one was supplied, and validates it.
+func (c *Coordinator) summarizeHandoff(ctx context.Context, client *ollama.Client, handoff *Handoff) error {
+	if handoff.Summary == nil {
+		resp, stats, err := client.Generate(ctx, handoffRequest(*handoff))
+		if stats != nil {
+			c.RecordTokens(handoff.From, int64(stats.TotalTokens))
+		}
+		if err != nil {
+			return fmt.Errorf("handoff summary from %s: %w", handoff.From, err)
+		}
+		summary := ParseHandoffSummary(resp)
+		handoff.Summary = &summary
+	}
+	if err := handoff.Summary.Validate(); err != nil {
+		return fmt.Errorf("invalid handoff from %s to %s: %w", handoff.From, handoff.To, err)
+	}
+	return nil
+}
+
+// TakeHandoffPrompt returns the pending handoff context for model as a
+// prompt section and clears it, or returns "" when there is none.
+func (c *Coordinator) TakeHandoffPrompt(to orchestrate.ModelType) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.pendingHandoffs[to]
+	if !ok {
+		return ""
+	}
+	delete(c.pendingHandoffs, to)
+	return h.Summary.Prompt(h.From)
+}
+
+// SetHandoffCallback sets the function called after each handoff attempt,
+// with the error that discarded its summary, if any.
+func (c *Coordinator) SetHandoffCallback(fn func(Handoff, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onHandoff = fn
+}
+
+// Handoffs returns the completed handoffs in order.
+func (c *Coordinator) Handoffs() []Handoff {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]Handoff, len(c.handoffs))
+	copy(result, c.handoffs)
+	return result
+}
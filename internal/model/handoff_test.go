@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// withGenerateServer points the coder client at a server that answers every
+// generate request with resp, using 120 tokens.
+func withGenerateServer(t *testing.T, c *Coordinator, resp string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: resp, Done: true, PromptEvalCount: 100, EvalCount: 20})
+	}))
+	t.Cleanup(srv.Close)
+	c.clients[orchestrate.ModelCoder] = ollama.NewClient(ollama.WithBaseURL(srv.URL))
+}
+
+func TestParseHandoffSummary(t *testing.T) {
+	s := ParseHandoffSummary(`STATE: Implemented the parser.
+Tests still need fixtures.
+OPEN QUESTIONS:
+- Should errors be wrapped?
+ARTIFACTS:
+* internal/parse/parse.go
+- None`)
+
+	if s.State != "Implemented the parser. Tests still need fixtures." {
+		t.Errorf("State = %q", s.State)
+	}
+	if len(s.OpenQuestions) != 1 || s.OpenQuestions[0] != "Should errors be wrapped?" {
+		t.Errorf("OpenQuestions = %v", s.OpenQuestions)
+	}
+	if len(s.Artifacts) != 1 || s.Artifacts[0] != "internal/parse/parse.go" {
+		t.Errorf("Artifacts = %v", s.Artifacts)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if err := (HandoffSummary{}).Validate(); err == nil {
+		t.Error("Validate() should reject a summary without state")
+	}
+	if err := (HandoffSummary{State: strings.Repeat("x", maxHandoffChars+1)}).Validate(); err == nil {
+		t.Error("Validate() should reject an oversized summary")
+	}
+}
+
+func TestHandoffProtocol_InjectsSummary(t *testing.T) {
+	c := NewCoordinator(nil)
+	withGenerateServer(t, c, "STATE: Wrote the handler.\nOPEN QUESTIONS:\n- Is auth required?\nARTIFACTS:\n- api/handler.go\n")
+
+	var got []Handoff
+	c.SetHandoffCallback(func(h Handoff, err error) {
+		if err != nil {
+			t.Errorf("callback error = %v", err)
+		}
+		got = append(got, h)
+	})
+
+	err := c.HandoffProtocol(context.Background(), Handoff{
+		From:     orchestrate.ModelCoder,
+		To:       orchestrate.ModelResearcher,
+		Schedule: orchestrate.ScheduleImplement,
+		Process:  orchestrate.Process1,
+		Context:  "I wrote api/handler.go",
+	})
+	if err != nil {
+		t.Fatalf("HandoffProtocol() = %v", err)
+	}
+	if c.GetActiveModel() != orchestrate.ModelResearcher {
+		t.Errorf("active model = %s", c.GetActiveModel())
+	}
+	if len(got) != 1 || len(c.Handoffs()) != 1 {
+		t.Fatalf("callback saw %d handoffs, Handoffs() = %d", len(got), len(c.Handoffs()))
+	}
+	if used := c.GetTokenCounts()[orchestrate.ModelCoder]; used != 120 {
+		t.Errorf("coder tokens = %d, want the summary's 120 charged", used)
+	}
+
+	prompt := c.TakeHandoffPrompt(orchestrate.ModelResearcher)
+	for _, want := range []string{"HANDOFF FROM CODER MODEL", "Wrote the handler.", "- Is auth required?", "- api/handler.go"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if c.TakeHandoffPrompt(orchestrate.ModelResearcher) != "" {
+		t.Error("handoff prompt should only be injected once")
+	}
+}
+
+func TestHandoffProtocol_InvalidSummary(t *testing.T) {
+	c := NewCoordinator(nil)
+	withGenerateServer(t, c, "I did some things.")
+
+	var callbackErr error
+	c.SetHandoffCallback(func(h Handoff, err error) { callbackErr = err })
+
+	err := c.HandoffProtocol(context.Background(), Handoff{
+		From:    orchestrate.ModelCoder,
+		To:      orchestrate.ModelResearcher,
+		Context: "work",
+	})
+	if err == nil || callbackErr == nil {
+		t.Fatalf("HandoffProtocol() = %v, callback error = %v; want errors", err, callbackErr)
+	}
+	if c.TakeHandoffPrompt(orchestrate.ModelResearcher) != "" || len(c.Handoffs()) != 0 {
+		t.Error("an invalid summary should not be injected or stored")
+	}
+	if c.GetActiveModel() != orchestrate.ModelResearcher {
+		t.Errorf("active model = %s", c.GetActiveModel())
+	}
+}
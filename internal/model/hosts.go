@@ -0,0 +1,258 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// hostCheckTimeout bounds one health check of a host.
+const hostCheckTimeout = 5 * time.Second
+
+// HostEvent reports that a host stopped or started answering.
+type HostEvent struct {
+	Host    string
+	URL     string
+	Healthy bool
+	Err     error // why the check failed, when not Healthy
+}
+
+// String returns a one-line description of the event.
+func (e HostEvent) String() string {
+	if e.Healthy {
+		return fmt.Sprintf("host %s (%s) is back", e.Host, e.URL)
+	}
+	return fmt.Sprintf("host %s (%s) is down, failing over: %v", e.Host, e.URL, e.Err)
+}
+
+// HostStatus is the current state of a host.
+type HostStatus struct {
+	Name          string
+	URL           string
+	Healthy       bool
+	InFlight      int
+	MaxConcurrent int
+	Roles         []orchestrate.ModelType // roles routed to the host
+}
+
+// String returns a compact description such as "gpu up 1/2".
+func (s HostStatus) String() string {
+	state := "up"
+	if !s.Healthy {
+		state = "down"
+	}
+	if s.MaxConcurrent > 0 {
+		return fmt.Sprintf("%s %s %d/%d", s.Name, state, s.InFlight, s.MaxConcurrent)
+	}
+	return fmt.Sprintf("%s %s %d", s.Name, state, s.InFlight)
+}
+
+// hostSet is the hosts the coordinator routes roles to.
+type hostSet struct {
+	hosts    []*ollama.Host
+	routes   map[orchestrate.ModelType][]*ollama.Host
+	reported map[*ollama.Host]bool // health last reported to the callback
+	onHost   func(HostEvent)
+	http     *http.Client
+}
+
+// SetHosts routes each role's requests to hosts. routes lists, per role,
+// the names of the hosts it may use in order of preference; a role without
+// a route may use every host, in the order given. A request goes to the
+// first healthy host with a free slot, spilling over to the next when the
+// preferred one is busy and failing over when it is down.
+func (c *Coordinator) SetHosts(hosts []*ollama.Host, routes map[orchestrate.ModelType][]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byName := make(map[string]*ollama.Host, len(hosts))
+	for _, h := range hosts {
+		if _, dup := byName[h.Name]; dup {
+			return fmt.Errorf("duplicate ollama host %q", h.Name)
+		}
+		byName[h.Name] = h
+	}
+
+	set := &hostSet{
+		hosts:    hosts,
+		routes:   make(map[orchestrate.ModelType][]*ollama.Host),
+		reported: make(map[*ollama.Host]bool),
+		http:     &http.Client{Timeout: hostCheckTimeout},
+	}
+	if c.hosts != nil {
+		set.onHost = c.hosts.onHost
+	}
+	for role := range c.clients {
+		names := routes[role]
+		if len(names) == 0 {
+			set.routes[role] = hosts
+			continue
+		}
+		for _, name := range names {
+			h, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("models.%s.hosts: unknown host %q", role, name)
+			}
+			set.routes[role] = append(set.routes[role], h)
+		}
+	}
+	for role := range routes {
+		if _, ok := c.clients[role]; !ok {
+			return fmt.Errorf("hosts routed to unknown role %q", role)
+		}
+	}
+	for _, h := range hosts {
+		set.reported[h] = true
+	}
+
+	c.hosts = set
+	for role, client := range c.clients {
+		if len(hosts) == 0 {
+			client.SetHosts(nil)
+		} else {
+			client.SetHosts(pickHost(set.routes[role]))
+		}
+	}
+	return nil
+}
+
+// pickHost returns the picker for a role's route: the first healthy host
+// with a free slot, else the healthy host with the fewest requests
+// waiting for a slot, else the first host not yet tried in case it has come back.
+func pickHost(route []*ollama.Host) ollama.HostPicker {
+	return func(tried map[*ollama.Host]bool) *ollama.Host {
+		var leastQueued, fallback *ollama.Host
+		for _, h := range route {
+			if tried[h] {
+				continue
+			}
+			if fallback == nil {
+				fallback = h
+			}
+			if !h.Healthy() {
+				continue
+			}
+			if !h.Full() {
+				return h
+			}
+			if leastQueued == nil || h.InFlight()-h.MaxConcurrent() < leastQueued.InFlight()-leastQueued.MaxConcurrent() {
+				leastQueued = h
+			}
+		}
+		if leastQueued != nil {
+			return leastQueued
+		}
+		return fallback
+	}
+}
+
+// SetHostCallback sets the function called when a host goes down or comes back.
+func (c *Coordinator) SetHostCallback(fn func(HostEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hosts == nil {
+		c.hosts = &hostSet{reported: make(map[*ollama.Host]bool)}
+	}
+	c.hosts.onHost = fn
+}
+
+// CheckHosts health-checks every host, reporting those that went down or
+// came back since the last check, and returns the number answering.
+func (c *Coordinator) CheckHosts(ctx context.Context) int {
+	c.mu.Lock()
+	set := c.hosts
+	c.mu.Unlock()
+	if set == nil || len(set.hosts) == 0 {
+		return 0
+	}
+
+	errs := make([]error, len(set.hosts))
+	var wg sync.WaitGroup
+	for i, h := range set.hosts {
+		wg.Add(1)
+		go func(i int, h *ollama.Host) {
+			defer wg.Done()
+			errs[i] = h.Check(ctx, set.http)
+		}(i, h)
+	}
+	wg.Wait()
+
+	var events []HostEvent
+	healthy := 0
+	c.mu.Lock()
+	for i, h := range set.hosts {
+		ok := errs[i] == nil
+		if ok {
+			healthy++
+		}
+		if set.reported[h] != ok {
+			set.reported[h] = ok
+			events = append(events, HostEvent{Host: h.Name, URL: h.URL, Healthy: ok, Err: errs[i]})
+		}
+	}
+	notify := set.onHost
+	c.mu.Unlock()
+
+	if notify != nil {
+		for _, e := range events {
+			notify(e)
+		}
+	}
+	return healthy
+}
+
+// WatchHosts checks the hosts every interval until ctx is done.
+func (c *Coordinator) WatchHosts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckHosts(ctx)
+		}
+	}
+}
+
+// HostStatuses returns the state of every host, in the order configured.
+func (c *Coordinator) HostStatuses() []HostStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hosts == nil {
+		return nil
+	}
+
+	statuses := make([]HostStatus, len(c.hosts.hosts))
+	for i, h := range c.hosts.hosts {
+		statuses[i] = HostStatus{
+			Name:          h.Name,
+			URL:           h.URL,
+			Healthy:       h.Healthy(),
+			InFlight:      h.InFlight(),
+			MaxConcurrent: h.MaxConcurrent(),
+		}
+		for _, role := range orderedRoles {
+			for _, r := range c.hosts.routes[role] {
+				if r == h {
+					statuses[i].Roles = append(statuses[i].Roles, role)
+					break
+				}
+			}
+		}
+	}
+	return statuses
+}
+
+// orderedRoles lists the roles in display order.
+var orderedRoles = []orchestrate.ModelType{
+	orchestrate.ModelOrchestrator,
+	orchestrate.ModelCoder,
+	orchestrate.ModelResearcher,
+	orchestrate.ModelVision,
+}
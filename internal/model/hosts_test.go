@@ -0,0 +1,130 @@
+package model
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// ollamaServer answers chat requests with reply and counts them.
+func ollamaServer(t *testing.T, reply string, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			w.Write([]byte(`{"models":[]}`))
+			return
+		}
+		requests.Add(1)
+		w.Write([]byte(`{"message":{"role":"assistant","content":"` + reply + `"},"done":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestHost(t *testing.T, name, url string, maxConcurrent int) *ollama.Host {
+	t.Helper()
+	h, err := ollama.NewHost(name, url, maxConcurrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestSetHosts_RoutesRoles(t *testing.T) {
+	var gpuReqs, cpuReqs atomic.Int32
+	gpu := newTestHost(t, "gpu", ollamaServer(t, "gpu", &gpuReqs).URL, 2)
+	cpu := newTestHost(t, "cpu", ollamaServer(t, "cpu", &cpuReqs).URL, 0)
+
+	c := NewCoordinator(nil)
+	err := c.SetHosts([]*ollama.Host{gpu, cpu}, map[orchestrate.ModelType][]string{
+		orchestrate.ModelCoder:      {"gpu"},
+		orchestrate.ModelResearcher: {"cpu"},
+	})
+	if err != nil {
+		t.Fatalf("SetHosts: %v", err)
+	}
+
+	ctx := context.Background()
+	if got, _, err := c.Get(orchestrate.ModelCoder).Chat(ctx, []ollama.Message{{Role: "user", Content: "code"}}); err != nil || got != "gpu" {
+		t.Errorf("coder answered %q, %v; want gpu", got, err)
+	}
+	if got, _, err := c.Get(orchestrate.ModelResearcher).Chat(ctx, []ollama.Message{{Role: "user", Content: "research"}}); err != nil || got != "cpu" {
+		t.Errorf("researcher answered %q, %v; want cpu", got, err)
+	}
+
+	statuses := c.HostStatuses()
+	if len(statuses) != 2 || statuses[0].Name != "gpu" || statuses[0].MaxConcurrent != 2 {
+		t.Fatalf("HostStatuses() = %v", statuses)
+	}
+	// The orchestrator and vision have no route and use both hosts
+	if got := len(statuses[0].Roles); got != 3 {
+		t.Errorf("gpu serves %v, want orchestrator, coder, vision", statuses[0].Roles)
+	}
+}
+
+func TestSetHosts_FailsOver(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	var reqs atomic.Int32
+	gpu := newTestHost(t, "gpu", downURL, 0)
+	cpu := newTestHost(t, "cpu", ollamaServer(t, "cpu", &reqs).URL, 0)
+
+	c := NewCoordinator(nil)
+	if err := c.SetHosts([]*ollama.Host{gpu, cpu}, map[orchestrate.ModelType][]string{
+		orchestrate.ModelCoder: {"gpu", "cpu"},
+	}); err != nil {
+		t.Fatalf("SetHosts: %v", err)
+	}
+	var events []HostEvent
+	c.SetHostCallback(func(e HostEvent) { events = append(events, e) })
+
+	got, _, err := c.Get(orchestrate.ModelCoder).Chat(context.Background(), []ollama.Message{{Role: "user", Content: "code"}})
+	if err != nil || got != "cpu" {
+		t.Fatalf("coder answered %q, %v; want failover to cpu", got, err)
+	}
+	if gpu.Healthy() {
+		t.Errorf("gpu still healthy after a failed request")
+	}
+
+	if n := c.CheckHosts(context.Background()); n != 1 {
+		t.Errorf("CheckHosts() = %d healthy, want 1", n)
+	}
+	if len(events) != 1 || events[0].Host != "gpu" || events[0].Healthy {
+		t.Errorf("events = %v, want gpu down", events)
+	}
+}
+
+func TestSetHosts_UnknownHost(t *testing.T) {
+	c := NewCoordinator(nil)
+	cpu := newTestHost(t, "cpu", "http://localhost:11434", 0)
+	err := c.SetHosts([]*ollama.Host{cpu}, map[orchestrate.ModelType][]string{
+		orchestrate.ModelCoder: {"gpu"},
+	})
+	if err == nil {
+		t.Fatal("SetHosts accepted an unknown host")
+	}
+}
+
+func TestPickHost_SkipsDownHosts(t *testing.T) {
+	gpu := newTestHost(t, "gpu", "http://gpu:11434", 1)
+	cpu := newTestHost(t, "cpu", "http://localhost:11434", 0)
+	pick := pickHost([]*ollama.Host{gpu, cpu})
+
+	if h := pick(nil); h != gpu {
+		t.Fatalf("pick() = %s, want gpu", h.Name)
+	}
+	gpu.SetHealthy(false)
+	if h := pick(nil); h != cpu {
+		t.Errorf("pick() with gpu down = %s, want cpu", h.Name)
+	}
+	if h := pick(map[*ollama.Host]bool{cpu: true}); h != gpu {
+		t.Errorf("pick() with cpu tried = %v, want gpu in case it is back", h)
+	}
+}
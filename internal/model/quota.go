@@ -0,0 +1,224 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Quota limits how much a model role may be used. Zero values disable the
+// corresponding limit.
+type Quota struct {
+	RequestsPerMinute int
+	DailyTokens       int64
+
+	// Fallback is the role used once DailyTokens is exhausted. Without a
+	// fallback the role pauses until its quota resets at midnight.
+	Fallback orchestrate.ModelType
+}
+
+// QuotaEventKind identifies how the coordinator reacted to a limit.
+type QuotaEventKind string
+
+const (
+	QuotaPaused     QuotaEventKind = "paused"
+	QuotaDowngraded QuotaEventKind = "downgraded"
+)
+
+// QuotaEvent reports that a role was paused or downgraded by its quota.
+type QuotaEvent struct {
+	Kind   QuotaEventKind
+	Role   orchestrate.ModelType
+	To     orchestrate.ModelType // fallback role for QuotaDowngraded
+	Until  time.Time             // end of the pause for QuotaPaused
+	Reason string
+}
+
+// String returns a one-line description of the event.
+func (e QuotaEvent) String() string {
+	if e.Kind == QuotaDowngraded {
+		return fmt.Sprintf("%s downgraded to %s: %s", e.Role, e.To, e.Reason)
+	}
+	return fmt.Sprintf("%s paused until %s: %s", e.Role, e.Until.Format("15:04:05"), e.Reason)
+}
+
+// QuotaStatus is the current usage of a role with a quota.
+type QuotaStatus struct {
+	Role               orchestrate.ModelType
+	RequestsLastMinute int
+	RequestsPerMinute  int
+	TokensToday        int64
+	DailyTokens        int64
+	Exhausted          bool
+}
+
+// String returns a compact description such as "coder 3/30rpm 12k/500k tok".
+func (s QuotaStatus) String() string {
+	parts := []string{string(s.Role)}
+	if s.RequestsPerMinute > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%drpm", s.RequestsLastMinute, s.RequestsPerMinute))
+	}
+	if s.DailyTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s tok", formatTokens(s.TokensToday), formatTokens(s.DailyTokens)))
+	}
+	if s.Exhausted {
+		parts = append(parts, "exhausted")
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatTokens abbreviates a token count.
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%dk", n/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// quotaState tracks the usage of one role against its quota.
+type quotaState struct {
+	quota       Quota
+	requests    []time.Time // request start times within the last minute
+	day         string
+	tokensToday int64
+}
+
+// roll drops requests older than a minute and resets the daily tokens on a new day.
+func (s *quotaState) roll(now time.Time) {
+	if day := now.Format("2006-01-02"); day != s.day {
+		s.day = day
+		s.tokensToday = 0
+	}
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(s.requests) && !s.requests[i].After(cutoff) {
+		i++
+	}
+	s.requests = s.requests[i:]
+}
+
+func (s *quotaState) dailyExhausted() bool {
+	return s.quota.DailyTokens > 0 && s.tokensToday >= s.quota.DailyTokens
+}
+
+func (s *quotaState) rateLimited() bool {
+	return s.quota.RequestsPerMinute > 0 && len(s.requests) >= s.quota.RequestsPerMinute
+}
+
+// SetQuota sets the quota for a role. A zero Quota removes the limits.
+func (c *Coordinator) SetQuota(role orchestrate.ModelType, q Quota) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if q == (Quota{}) {
+		delete(c.quotas, role)
+		return
+	}
+	st := c.quotas[role]
+	if st == nil {
+		st = &quotaState{}
+		c.quotas[role] = st
+	}
+	st.quota = q
+}
+
+// SetQuotaCallback sets the function called when a role is paused or downgraded.
+func (c *Coordinator) SetQuotaCallback(fn func(QuotaEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onQuota = fn
+}
+
+// Acquire reserves a request for role and returns the role to use. When the
+// role's daily token quota is exhausted it is downgraded to its fallback;
+// when it is rate limited, or exhausted without a fallback, Acquire waits
+// until capacity is available or ctx is done.
+func (c *Coordinator) Acquire(ctx context.Context, role orchestrate.ModelType) (orchestrate.ModelType, error) {
+	tried := map[orchestrate.ModelType]bool{}
+	for {
+		c.mu.Lock()
+		st := c.quotas[role]
+		if st == nil {
+			c.mu.Unlock()
+			return role, nil
+		}
+		now := c.now()
+		st.roll(now)
+
+		var event QuotaEvent
+		switch {
+		case st.dailyExhausted():
+			tried[role] = true
+			if fb := st.quota.Fallback; fb != "" && !tried[fb] && c.clients[fb] != nil {
+				event = QuotaEvent{Kind: QuotaDowngraded, Role: role, To: fb, Reason: "daily token quota exhausted"}
+			} else {
+				y, m, d := now.Date()
+				midnight := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+				event = QuotaEvent{Kind: QuotaPaused, Role: role, Until: midnight, Reason: "daily token quota exhausted"}
+			}
+		case st.rateLimited():
+			until := st.requests[0].Add(time.Minute)
+			event = QuotaEvent{Kind: QuotaPaused, Role: role, Until: until, Reason: "request rate limit reached"}
+		default:
+			st.requests = append(st.requests, now)
+			c.mu.Unlock()
+			return role, nil
+		}
+		notify := c.onQuota
+		c.mu.Unlock()
+
+		if notify != nil {
+			notify(event)
+		}
+		if event.Kind == QuotaDowngraded {
+			role = event.To
+			continue
+		}
+		if err := c.sleep(ctx, event.Until.Sub(now)); err != nil {
+			return role, err
+		}
+	}
+}
+
+// QuotaStatuses returns the usage of every role with a quota, sorted by role.
+func (c *Coordinator) QuotaStatuses() []QuotaStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	statuses := make([]QuotaStatus, 0, len(c.quotas))
+	for role, st := range c.quotas {
+		st.roll(now)
+		statuses = append(statuses, QuotaStatus{
+			Role:               role,
+			RequestsLastMinute: len(st.requests),
+			RequestsPerMinute:  st.quota.RequestsPerMinute,
+			TokensToday:        st.tokensToday,
+			DailyTokens:        st.quota.DailyTokens,
+			Exhausted:          st.dailyExhausted(),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Role < statuses[j].Role })
+	return statuses
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// fakeClock makes the coordinator's quota timing deterministic.
+func fakeClock(c *Coordinator, start time.Time) *time.Time {
+	now := start
+	c.now = func() time.Time { return now }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return ctx.Err()
+	}
+	return &now
+}
+
+func TestAcquire_RateLimitPauses(t *testing.T) {
+	c := NewCoordinator(nil)
+	now := fakeClock(c, time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local))
+	start := *now
+	c.SetQuota(orchestrate.ModelCoder, Quota{RequestsPerMinute: 2})
+
+	var events []QuotaEvent
+	c.SetQuotaCallback(func(e QuotaEvent) { events = append(events, e) })
+
+	for i := 0; i < 3; i++ {
+		role, err := c.Acquire(context.Background(), orchestrate.ModelCoder)
+		if err != nil || role != orchestrate.ModelCoder {
+			t.Fatalf("Acquire() = %s, %v", role, err)
+		}
+	}
+	if len(events) != 1 || events[0].Kind != QuotaPaused {
+		t.Fatalf("events = %v, want one pause", events)
+	}
+	if waited := now.Sub(start); waited != time.Minute {
+		t.Errorf("paused for %s, want 1m", waited)
+	}
+}
+
+func TestAcquire_DailyQuotaDowngrades(t *testing.T) {
+	c := NewCoordinator(nil)
+	fakeClock(c, time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local))
+	c.SetQuota(orchestrate.ModelCoder, Quota{DailyTokens: 1000, Fallback: orchestrate.ModelOrchestrator})
+	c.RecordTokens(orchestrate.ModelCoder, 1200)
+
+	var events []QuotaEvent
+	c.SetQuotaCallback(func(e QuotaEvent) { events = append(events, e) })
+
+	role, err := c.Acquire(context.Background(), orchestrate.ModelCoder)
+	if err != nil || role != orchestrate.ModelOrchestrator {
+		t.Fatalf("Acquire() = %s, %v, want orchestrator", role, err)
+	}
+	if len(events) != 1 || events[0].Kind != QuotaDowngraded || events[0].To != orchestrate.ModelOrchestrator {
+		t.Errorf("events = %v", events)
+	}
+
+	statuses := c.QuotaStatuses()
+	if len(statuses) != 1 || !statuses[0].Exhausted || statuses[0].TokensToday != 1200 {
+		t.Errorf("QuotaStatuses() = %+v", statuses)
+	}
+}
+
+func TestAcquire_DailyQuotaPausesUntilMidnight(t *testing.T) {
+	c := NewCoordinator(nil)
+	now := fakeClock(c, time.Date(2026, 3, 1, 23, 0, 0, 0, time.Local))
+	c.SetQuota(orchestrate.ModelCoder, Quota{DailyTokens: 100})
+	c.RecordTokens(orchestrate.ModelCoder, 100)
+
+	if _, err := c.Acquire(context.Background(), orchestrate.ModelCoder); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local); !now.Equal(want) {
+		t.Errorf("resumed at %s, want %s", now, want)
+	}
+	if s := c.QuotaStatuses()[0]; s.TokensToday != 0 {
+		t.Errorf("TokensToday after reset = %d", s.TokensToday)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.RecordTokens(orchestrate.ModelCoder, 100)
+	if _, err := c.Acquire(ctx, orchestrate.ModelCoder); err == nil {
+		t.Error("Acquire() with cancelled context succeeded while paused")
+	}
+}
+
+func TestRecordClientTokens(t *testing.T) {
+	c := NewCoordinator(nil)
+	c.SetQuota(orchestrate.ModelOrchestrator, Quota{DailyTokens: 1000})
+	c.RecordClientTokens(c.Get(orchestrate.ModelOrchestrator), 400)
+	c.RecordClientTokens(ollama.NewClient(), 50) // no role's client
+	if counts := c.GetTokenCounts(); counts[orchestrate.ModelOrchestrator] != 400 || len(counts) != 1 {
+		t.Errorf("token counts = %v, want 400 for the orchestrator only", counts)
+	}
+	if s := c.QuotaStatuses(); len(s) != 1 || s[0].TokensToday != 400 {
+		t.Errorf("quota statuses = %+v, want 400 tokens today", s)
+	}
+}
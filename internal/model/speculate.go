@@ -0,0 +1,142 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Speculator hides orchestrator latency by computing the next process and
+// schedule decisions in the background while the agent executes a process.
+// A speculative decision is used only if the orchestrator state it was
+// computed from still holds when the decision is needed; otherwise the
+// decision is computed again.
+type Speculator struct {
+	coord *Coordinator
+
+	mu       sync.Mutex
+	process  *speculation
+	schedule *speculation
+	hits     int
+	misses   int
+}
+
+// speculation is a decision computed ahead of time for a state key.
+type speculation struct {
+	key  string
+	done chan struct{}
+
+	schedule  orchestrate.ScheduleID
+	process   orchestrate.ProcessID
+	terminate bool
+	err       error
+}
+
+// NewSpeculator creates a speculator that decides with the coordinator.
+func NewSpeculator(c *Coordinator) *Speculator {
+	return &Speculator{coord: c}
+}
+
+// processKey identifies the state a next-process decision depends on: the
+// process it follows and everything the strategy reads, so that a decision
+// speculated before the process added notes or failed is not used after.
+func processKey(orch *orchestrate.Orchestrator, schedID orchestrate.ScheduleID, lastProc orchestrate.ProcessID) string {
+	return fmt.Sprintf("%d/%d/%s", schedID, lastProc, orch.DecisionState())
+}
+
+// scheduleKey identifies the state a next-schedule decision depends on.
+func scheduleKey(orch *orchestrate.Orchestrator) string {
+	return fmt.Sprintf("%t/%s", orch.CanTerminatePrompt(), orch.DecisionState())
+}
+
+// Start begins computing the decisions that follow the completion of procID
+// in schedID: the next process and, if the schedule would terminate, the next
+// schedule. Earlier speculations are discarded. Nothing is computed while
+// the coordinator is serialized under memory pressure; the decisions are
+// then made when needed.
+func (s *Speculator) Start(ctx context.Context, orch *orchestrate.Orchestrator, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) {
+	if s.coord.Serialized() {
+		s.mu.Lock()
+		s.process, s.schedule = nil, nil
+		s.mu.Unlock()
+		return
+	}
+	proc := &speculation{key: processKey(orch, schedID, procID), done: make(chan struct{})}
+	sched := &speculation{key: scheduleKey(orch), done: make(chan struct{})}
+
+	s.mu.Lock()
+	s.process, s.schedule = proc, sched
+	s.mu.Unlock()
+
+	go func() {
+		defer close(sched.done)
+		proc.process, proc.terminate, proc.err = s.coord.SelectNextProcess(ctx, orch, schedID, procID)
+		close(proc.done)
+		if proc.err != nil || !proc.terminate {
+			sched.err = fmt.Errorf("schedule continues after %s", orchestrate.ProcessNames[schedID][procID])
+			return
+		}
+		sched.schedule, sched.terminate, sched.err = s.coord.SelectNextSchedule(ctx, orch)
+	}()
+}
+
+// take returns the speculation in slot if it was computed for key and has
+// finished without error, waiting for it if it is still running. The slot is
+// cleared either way.
+func (s *Speculator) take(ctx context.Context, slot **speculation, key string) (*speculation, bool) {
+	s.mu.Lock()
+	spec := *slot
+	*slot = nil
+	s.mu.Unlock()
+
+	if spec != nil && spec.key == key {
+		select {
+		case <-spec.done:
+			if spec.err == nil {
+				s.record(true)
+				return spec, true
+			}
+		case <-ctx.Done():
+		}
+	}
+	s.record(false)
+	return nil, false
+}
+
+func (s *Speculator) record(hit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hit {
+		s.hits++
+	} else {
+		s.misses++
+	}
+}
+
+// NextProcess returns the process to run after lastProc, using the
+// speculative decision when it matches the current state.
+func (s *Speculator) NextProcess(ctx context.Context, orch *orchestrate.Orchestrator, schedID orchestrate.ScheduleID, lastProc orchestrate.ProcessID) (orchestrate.ProcessID, bool, error) {
+	if spec, ok := s.take(ctx, &s.process, processKey(orch, schedID, lastProc)); ok {
+		return spec.process, spec.terminate, nil
+	}
+	return s.coord.SelectNextProcess(ctx, orch, schedID, lastProc)
+}
+
+// NextSchedule returns the schedule to run next, using the speculative
+// decision when it matches the current state.
+func (s *Speculator) NextSchedule(ctx context.Context, orch *orchestrate.Orchestrator) (orchestrate.ScheduleID, bool, error) {
+	if spec, ok := s.take(ctx, &s.schedule, scheduleKey(orch)); ok {
+		return spec.schedule, spec.terminate, nil
+	}
+	return s.coord.SelectNextSchedule(ctx, orch)
+}
+
+// Stats returns how many decisions were served from speculation and how
+// many had to be computed on demand.
+func (s *Speculator) Stats() (hits, misses int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits, s.misses
+}
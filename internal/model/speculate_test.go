@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestSpeculator_UsesMatchingDecision(t *testing.T) {
+	ctx := context.Background()
+	orch := orchestrate.NewOrchestrator()
+	if err := orch.SelectSchedule(orchestrate.ScheduleKnowledge); err != nil {
+		t.Fatal(err)
+	}
+	spec := NewSpeculator(NewCoordinator(nil))
+
+	spec.Start(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process3)
+	proc, terminate, err := spec.NextProcess(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process3)
+	if err != nil || !terminate || proc != 0 {
+		t.Fatalf("NextProcess() = %d, %t, %v; want schedule termination", proc, terminate, err)
+	}
+	sched, done, err := spec.NextSchedule(ctx, orch)
+	if err != nil || done || sched != orchestrate.SchedulePlan {
+		t.Fatalf("NextSchedule() = %d, %t, %v; want Plan", sched, done, err)
+	}
+	if hits, misses := spec.Stats(); hits != 2 || misses != 0 {
+		t.Errorf("Stats() = %d hits, %d misses; want 2, 0", hits, misses)
+	}
+}
+
+func TestSpeculator_RecomputesOnStateChange(t *testing.T) {
+	ctx := context.Background()
+	orch := orchestrate.NewOrchestrator()
+	if err := orch.SelectSchedule(orchestrate.ScheduleKnowledge); err != nil {
+		t.Fatal(err)
+	}
+	spec := NewSpeculator(NewCoordinator(nil))
+
+	// Speculated for P1, but the orchestrator asks about P2
+	spec.Start(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process1)
+	proc, terminate, err := spec.NextProcess(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process2)
+	if err != nil || terminate || proc != orchestrate.Process3 {
+		t.Fatalf("NextProcess() = %d, %t, %v; want P3", proc, terminate, err)
+	}
+	// P1 does not end the schedule, so no schedule decision was speculated
+	if _, _, err := spec.NextSchedule(ctx, orch); err != nil {
+		t.Fatalf("NextSchedule() error = %v", err)
+	}
+	if hits, misses := spec.Stats(); hits != 0 || misses != 2 {
+		t.Errorf("Stats() = %d hits, %d misses; want 0, 2", hits, misses)
+	}
+}
+
+func TestSpeculator_RecomputesWhenNotesChange(t *testing.T) {
+	ctx := context.Background()
+	orch := orchestrate.NewOrchestrator()
+	if err := orch.SelectSchedule(orchestrate.ScheduleKnowledge); err != nil {
+		t.Fatal(err)
+	}
+	spec := NewSpeculator(NewCoordinator(nil))
+
+	// The process adds a note after the decision was speculated
+	spec.Start(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process3)
+	orch.AddNote("the API needs a second look", "agent")
+	if _, _, err := spec.NextProcess(ctx, orch, orchestrate.ScheduleKnowledge, orchestrate.Process3); err != nil {
+		t.Fatalf("NextProcess() error = %v", err)
+	}
+	if hits, misses := spec.Stats(); hits != 0 || misses != 1 {
+		t.Errorf("Stats() = %d hits, %d misses; want 0, 1", hits, misses)
+	}
+}
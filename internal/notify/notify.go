@@ -0,0 +1,155 @@
+// Package notify alerts the user away from the terminal obot runs in, with
+// a desktop notification and the terminal bell, when a run needs them or
+// has ended.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Mode selects how the user is notified.
+type Mode int
+
+const (
+	Bell    Mode = 1 << iota // ring the terminal bell
+	Desktop                  // show a desktop notification
+
+	Off Mode = 0
+	All      = Bell | Desktop
+)
+
+// commandTimeout bounds how long a desktop notification command may run.
+const commandTimeout = 5 * time.Second
+
+// maxMessage is the longest message shown, in bytes.
+const maxMessage = 200
+
+// ParseMode parses a --notify value: off, bell, desktop, or all.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "off", "none":
+		return Off, nil
+	case "bell":
+		return Bell, nil
+	case "desktop":
+		return Desktop, nil
+	case "all", "on":
+		return All, nil
+	}
+	return Off, fmt.Errorf("unknown notification mode %q (want off, bell, desktop, or all)", s)
+}
+
+// String returns the name ParseMode accepts for m.
+func (m Mode) String() string {
+	switch m {
+	case Off:
+		return "off"
+	case Bell:
+		return "bell"
+	case Desktop:
+		return "desktop"
+	}
+	return "all"
+}
+
+// Notifier sends notifications. A nil Notifier sends none.
+type Notifier struct {
+	mode Mode
+	bell io.Writer
+	goos string
+	run  func(ctx context.Context, name string, args ...string) error
+}
+
+// New returns a notifier for mode. The bell is rung on w, usually the
+// terminal; a nil w never rings it.
+func New(mode Mode, w io.Writer) *Notifier {
+	return &Notifier{
+		mode: mode,
+		bell: w,
+		goos: runtime.GOOS,
+		run: func(ctx context.Context, name string, args ...string) error {
+			return exec.CommandContext(ctx, name, args...).Run()
+		},
+	}
+}
+
+// Notify rings the bell and shows a desktop notification, as the mode
+// allows. Only the first line of message is shown. A failed desktop
+// notification, such as one without notify-send installed, is logged and
+// otherwise ignored.
+func (n *Notifier) Notify(ctx context.Context, title, message string) {
+	if n == nil {
+		return
+	}
+	if n.mode&Bell != 0 && n.bell != nil {
+		fmt.Fprint(n.bell, "\a")
+	}
+	if n.mode&Desktop == 0 {
+		return
+	}
+	name, args := Command(n.goos, title, summarize(message))
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	if err := n.run(ctx, name, args...); err != nil {
+		slog.DebugContext(ctx, "desktop notification failed", "command", name, "error", err)
+	}
+}
+
+// Command returns the command that shows a desktop notification on goos:
+// osascript on macOS, a PowerShell toast on Windows, and notify-send
+// elsewhere.
+func Command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", toastScript(title, message)}
+	}
+	return "notify-send", []string{"--app-name=obot", "--", title, message}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// toastScript returns a PowerShell script showing a Windows toast.
+func toastScript(title, message string) string {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	return strings.Join([]string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+		"$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+		"$x = $t.GetElementsByTagName('text')",
+		"$x.Item(0).AppendChild($t.CreateTextNode(" + quote(title) + ")) > $null",
+		"$x.Item(1).AppendChild($t.CreateTextNode(" + quote(message) + ")) > $null",
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('obot').Show([Windows.UI.Notifications.ToastNotification]::new($t))",
+	}, "; ")
+}
+
+// summarize returns the first non-empty line of message, shortened to
+// maxMessage bytes.
+func summarize(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			message = line
+			break
+		}
+	}
+	if len(message) > maxMessage {
+		cut := maxMessage - 3
+		for cut > 0 && !utf8.RuneStart(message[cut]) {
+			cut--
+		}
+		message = message[:cut] + "..."
+	}
+	return message
+}
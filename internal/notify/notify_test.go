@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]Mode{"": Off, "off": Off, "bell": Bell, "Desktop": Desktop, "all": All}
+	for in, want := range tests {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %v, %v; want %v", in, got, err, want)
+		}
+		if in != "" && !strings.EqualFold(got.String(), in) {
+			t.Errorf("%v.String() = %q", got, got.String())
+		}
+	}
+	if _, err := ParseMode("loud"); err == nil {
+		t.Error("ParseMode(loud) succeeded")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	name, args := Command("darwin", `Say "hi"`, `back\slash`)
+	if name != "osascript" || args[1] != `display notification "back\\slash" with title "Say \"hi\""` {
+		t.Errorf("darwin: %s %q", name, args)
+	}
+	name, args = Command("linux", "-Title", "Body")
+	if name != "notify-send" || !slices.Equal(args[len(args)-3:], []string{"--", "-Title", "Body"}) {
+		t.Errorf("linux: %s %q", name, args)
+	}
+	name, args = Command("windows", "it's", "Body")
+	if name != "powershell" || !strings.Contains(args[len(args)-1], "CreateTextNode('it''s')") {
+		t.Errorf("windows: %s %q", name, args)
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var bell bytes.Buffer
+	var ran []string
+	n := New(All, &bell)
+	n.goos = "linux"
+	n.run = func(ctx context.Context, name string, args ...string) error {
+		ran = append(ran, name+" "+strings.Join(args, " "))
+		return nil
+	}
+
+	n.Notify(context.Background(), "obot", "\nConsultation requested\nmore detail")
+	if bell.String() != "\a" {
+		t.Errorf("bell = %q, want one BEL", bell.String())
+	}
+	if len(ran) != 1 || !strings.HasSuffix(ran[0], "obot Consultation requested") {
+		t.Errorf("ran %q", ran)
+	}
+
+	bell.Reset()
+	n.mode = Bell
+	n.Notify(context.Background(), "obot", "done")
+	if bell.String() != "\a" || len(ran) != 1 {
+		t.Errorf("bell mode rang %q and ran %q", bell.String(), ran)
+	}
+
+	var none *Notifier
+	none.Notify(context.Background(), "obot", "ignored")
+}
+
+func TestSummarize(t *testing.T) {
+	long := strings.Repeat("é", maxMessage)
+	got := summarize(long)
+	if len(got) > maxMessage || !strings.HasSuffix(got, "...") || !strings.HasPrefix(got, "é") {
+		t.Errorf("summarize() = %q (%d bytes)", got, len(got))
+	}
+}
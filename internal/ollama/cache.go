@@ -0,0 +1,269 @@
+package ollama
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Response cache limits
+const (
+	// DefaultCacheEntries is the number of responses a ResponseCache keeps
+	// in memory by default.
+	DefaultCacheEntries = 256
+	// DefaultCacheBytes is the size a ResponseCache lets its directory grow
+	// to by default.
+	DefaultCacheBytes = 64 << 20
+)
+
+// ResponseCache caches responses to deterministic (temperature 0) requests,
+// keyed by a hash of the model, options, and prompt. Recently used responses
+// are kept in memory; every response is also written to disk so that
+// identical prompts are answered instantly across runs. The directory is
+// pruned of its least recently used responses when it outgrows its limit.
+type ResponseCache struct {
+	mu        sync.Mutex
+	dir       string
+	max       int
+	maxBytes  int64
+	diskBytes int64      // size of the directory; -1 until it is first measured
+	order     *list.List // front is most recently used
+	entries   map[string]*list.Element
+	hits      int
+	misses    int
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	key      string
+	response string
+}
+
+// NewResponseCache creates a cache that keeps up to maxEntries responses in
+// memory and persists up to maxBytes of them under dir. An empty dir keeps
+// the cache in memory.
+func NewResponseCache(dir string, maxEntries int, maxBytes int64) *ResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheEntries
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultCacheBytes
+	}
+	return &ResponseCache{
+		dir:       dir,
+		max:       maxEntries,
+		maxBytes:  maxBytes,
+		diskBytes: -1,
+		order:     list.New(),
+		entries:   make(map[string]*list.Element),
+	}
+}
+
+// DefaultCacheDir returns the default response cache directory,
+// ~/.config/ollamabot/cache/responses.
+func DefaultCacheDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "ollamabot", "cache", "responses")
+}
+
+// defaultCache is the cache new clients use, if any.
+var defaultCache *ResponseCache
+
+// SetDefaultCache sets the response cache used by clients created afterwards.
+// A nil cache disables caching for new clients.
+func SetDefaultCache(rc *ResponseCache) {
+	defaultCache = rc
+}
+
+// WithCache sets the response cache for deterministic requests. A nil cache
+// disables caching.
+func WithCache(rc *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = rc
+	}
+}
+
+// Get returns the cached response for key.
+func (rc *ResponseCache) Get(key string) (string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, ok := rc.entries[key]; ok {
+		rc.order.MoveToFront(el)
+		rc.touch(key)
+		rc.hits++
+		return el.Value.(*cacheEntry).response, true
+	}
+	if rc.dir != "" {
+		if data, err := os.ReadFile(rc.path(key)); err == nil {
+			var response string
+			if json.Unmarshal(data, &response) == nil {
+				rc.add(key, response)
+				rc.touch(key)
+				rc.hits++
+				return response, true
+			}
+		}
+	}
+	rc.misses++
+	return "", false
+}
+
+// Put stores the response for key. Failing to persist it is not an error;
+// the response stays cached in memory.
+func (rc *ResponseCache) Put(key, response string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if el, ok := rc.entries[key]; ok {
+		el.Value.(*cacheEntry).response = response
+		rc.order.MoveToFront(el)
+	} else {
+		rc.add(key, response)
+	}
+	if rc.dir == "" {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil || os.MkdirAll(rc.dir, 0755) != nil {
+		return
+	}
+	rc.measure()
+	if info, err := os.Stat(rc.path(key)); err == nil {
+		rc.diskBytes -= info.Size()
+	}
+	tmp := rc.path(key) + ".tmp"
+	if os.WriteFile(tmp, data, 0600) == nil && os.Rename(tmp, rc.path(key)) == nil {
+		rc.diskBytes += int64(len(data))
+	}
+	if rc.diskBytes > rc.maxBytes {
+		rc.prune()
+	}
+}
+
+// touch marks the response for key on disk as used now, so pruning keeps it.
+// The caller must hold rc.mu.
+func (rc *ResponseCache) touch(key string) {
+	if rc.dir != "" {
+		now := time.Now()
+		_ = os.Chtimes(rc.path(key), now, now)
+	}
+}
+
+// measure sums the size of the cache directory the first time it is needed.
+// The caller must hold rc.mu.
+func (rc *ResponseCache) measure() {
+	if rc.diskBytes >= 0 {
+		return
+	}
+	rc.diskBytes = 0
+	for _, f := range rc.files() {
+		rc.diskBytes += f.Size()
+	}
+}
+
+// prune removes the least recently used responses from disk until the
+// directory is back under its limit. The caller must hold rc.mu.
+func (rc *ResponseCache) prune() {
+	files := rc.files()
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, f := range files {
+		if rc.diskBytes <= rc.maxBytes {
+			break
+		}
+		if os.Remove(filepath.Join(rc.dir, f.Name())) == nil {
+			rc.diskBytes -= f.Size()
+		}
+	}
+}
+
+// files returns the responses stored in the cache directory.
+func (rc *ResponseCache) files() []os.FileInfo {
+	entries, err := os.ReadDir(rc.dir)
+	if err != nil {
+		return nil
+	}
+	files := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+	return files
+}
+
+// add inserts a new entry, evicting the least recently used one from memory
+// when the cache is full. The caller must hold rc.mu.
+func (rc *ResponseCache) add(key, response string) {
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, response: response})
+	if rc.order.Len() > rc.max {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of responses held in memory.
+func (rc *ResponseCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.order.Len()
+}
+
+// Stats returns the number of cache hits and misses.
+func (rc *ResponseCache) Stats() (hits, misses int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.hits, rc.misses
+}
+
+func (rc *ResponseCache) path(key string) string {
+	return filepath.Join(rc.dir, key+".json")
+}
+
+// cacheKey hashes everything that determines the response to a request.
+func cacheKey(endpoint, model string, options map[string]any, input any) string {
+	data, _ := json.Marshal(struct {
+		Endpoint string         `json:"endpoint"`
+		Model    string         `json:"model"`
+		Options  map[string]any `json:"options"`
+		Input    any            `json:"input"`
+	}{endpoint, model, options, input})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// deterministic reports whether options sample at temperature 0, so that
+// identical requests produce identical responses.
+func deterministic(options map[string]any) bool {
+	switch t := options["temperature"].(type) {
+	case float64:
+		return t == 0
+	case float32:
+		return t == 0
+	case int:
+		return t == 0
+	}
+	return false
+}
+
+// cached returns the cache key for a request to model with options and the
+// cached response, if the request is deterministic and caching is enabled.
+// An empty key means the response must not be cached.
+func (c *Client) cached(endpoint, model string, options map[string]any, input any) (key, response string, ok bool) {
+	if c.cache == nil || !deterministic(options) {
+		return "", "", false
+	}
+	key = cacheKey(endpoint, model, options, input)
+	response, ok = c.cache.Get(key)
+	return key, response, ok
+}
@@ -0,0 +1,97 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResponseCache_LRU(t *testing.T) {
+	rc := NewResponseCache("", 2, 0)
+	rc.Put("a", "1")
+	rc.Put("b", "2")
+	rc.Get("a")
+	rc.Put("c", "3") // evicts b, the least recently used
+
+	if _, ok := rc.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := rc.Get("a"); !ok || v != "1" {
+		t.Errorf("Get(a) = %q, %t", v, ok)
+	}
+	if rc.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", rc.Len())
+	}
+}
+
+func TestResponseCache_Disk(t *testing.T) {
+	dir := t.TempDir()
+	NewResponseCache(dir, 0, 0).Put("k", "persisted")
+
+	rc := NewResponseCache(dir, 0, 0)
+	if v, ok := rc.Get("k"); !ok || v != "persisted" {
+		t.Errorf("Get(k) from disk = %q, %t", v, ok)
+	}
+	if hits, misses := rc.Stats(); hits != 1 || misses != 0 {
+		t.Errorf("Stats() = %d, %d", hits, misses)
+	}
+}
+
+func TestResponseCache_DiskLimit(t *testing.T) {
+	dir := t.TempDir()
+	rc := NewResponseCache(dir, 0, 25) // room for two 12-byte responses
+	rc.Put("a", "0123456789")
+	rc.Put("b", "0123456789")
+	old := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(dir, "a.json"), old, old.Add(time.Minute))
+	_ = os.Chtimes(filepath.Join(dir, "b.json"), old, old)
+	rc.Get("a") // a is now the most recently used
+	rc.Put("c", "0123456789")
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, err := os.Stat(filepath.Join(dir, key+".json"))
+		if (err == nil) != want {
+			t.Errorf("%s on disk = %t, want %t", key, err == nil, want)
+		}
+	}
+}
+
+func TestClient_GenerateCachesDeterministicRequests(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(GenerateResponse{Response: "summary", Done: true, EvalCount: 5})
+	}))
+	defer srv.Close()
+
+	rc := NewResponseCache(t.TempDir(), 0, 0)
+	c := NewClient(WithBaseURL(srv.URL), WithModel("m"), WithCache(rc))
+	c.SetTemperature(0)
+
+	for i := 0; i < 2; i++ {
+		resp, stats, err := c.Generate(context.Background(), "summarize the repo")
+		if err != nil || resp != "summary" {
+			t.Fatalf("Generate() = %q, %v", resp, err)
+		}
+		if stats.Cached != (i == 1) {
+			t.Errorf("call %d: Cached = %t", i, stats.Cached)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+
+	// Sampling at a non-zero temperature is never cached
+	c.SetTemperature(0.7)
+	if _, _, err := c.Generate(context.Background(), "summarize the repo"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2", calls)
+	}
+}
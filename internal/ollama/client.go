@@ -6,8 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/croberts/obot/internal/redact"
 )
 
 // DefaultBaseURL is the default Ollama server URL
@@ -17,10 +21,35 @@ const DefaultBaseURL = "http://localhost:11434"
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
-	model      string
-	options    map[string]any
+	// mu guards model and options, which are set while requests are in
+	// flight. An options map is never changed once set: setters replace it.
+	mu      sync.RWMutex
+	model   string
+	options map[string]any
+
+	redactor  *redact.Redactor
+	cache     *ResponseCache
+	observer  Observer
+	heartbeat Heartbeat
+	pickHost  HostPicker
+}
+
+// Exchange is a completed request to a model: the prompt sent, the
+// response, and what it cost.
+type Exchange struct {
+	Endpoint string // "generate" or "chat"
+	Model    string
+	Messages []Message // the prompt as sent, redacted; a generate prompt is one user message
+	Response string
+	Stats    *InferenceStats // nil when the request failed
+	Latency  time.Duration
+	Err      error
 }
 
+// Observer is called with each completed Generate, Chat, and ChatJSON
+// request, such as to record a transcript. ctx is the request's.
+type Observer func(ctx context.Context, ex Exchange)
+
 // ClientOption configures the client
 type ClientOption func(*Client)
 
@@ -52,6 +81,21 @@ func WithOptions(opts map[string]any) ClientOption {
 	}
 }
 
+// WithRedactor sets the redactor that scrubs secrets from outgoing prompts.
+// A nil redactor sends prompts unchanged.
+func WithRedactor(r *redact.Redactor) ClientOption {
+	return func(c *Client) {
+		c.redactor = r
+	}
+}
+
+// WithObserver sets the function called with each completed request.
+func WithObserver(fn Observer) ClientOption {
+	return func(c *Client) {
+		c.observer = fn
+	}
+}
+
 // NewClient creates a new Ollama client
 func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
@@ -59,7 +103,9 @@ func NewClient(opts ...ClientOption) *Client {
 		httpClient: &http.Client{
 			Timeout: 5 * time.Minute, // Long timeout for generation
 		},
-		options: make(map[string]any),
+		options:  make(map[string]any),
+		redactor: redact.Default(),
+		cache:    defaultCache,
 	}
 
 	for _, opt := range opts {
@@ -71,14 +117,32 @@ func NewClient(opts ...ClientOption) *Client {
 
 // SetModel sets the model to use for requests
 func (c *Client) SetModel(model string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.model = model
 }
 
+// SetObserver sets the function called with each completed request; nil
+// removes it.
+func (c *Client) SetObserver(fn Observer) {
+	c.observer = fn
+}
+
 // GetModel returns the current model
 func (c *Client) GetModel() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.model
 }
 
+// settings returns the model and options a request is made with. The
+// options must not be changed.
+func (c *Client) settings() (string, map[string]any) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.model, c.options
+}
+
 // BaseURL returns the configured base URL
 func (c *Client) BaseURL() string {
 	return c.baseURL
@@ -130,6 +194,33 @@ func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
 	return tagsResp.Models, nil
 }
 
+// RunningModels returns the models loaded in memory and how much of each
+// is in GPU memory
+func (c *Client) RunningModels(ctx context.Context) ([]RunningModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/ps", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var psResp PsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&psResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return psResp.Models, nil
+}
+
 // HasModel checks if a specific model is available
 func (c *Client) HasModel(ctx context.Context, model string) (bool, error) {
 	models, err := c.ListModels(ctx)
@@ -147,14 +238,45 @@ func (c *Client) HasModel(ctx context.Context, model string) (bool, error) {
 
 // Generate sends a prompt and returns the complete response (non-streaming)
 func (c *Client) Generate(ctx context.Context, prompt string) (string, *InferenceStats, error) {
+	prompt = c.redactPrompt(prompt)
+	start := time.Now()
+	response, stats, err := c.generate(ctx, prompt)
+	c.observe(ctx, "generate", []Message{{Role: "user", Content: prompt}}, response, stats, start, err)
+	return response, stats, err
+}
+
+// generate sends a redacted prompt to /api/generate.
+func (c *Client) generate(ctx context.Context, prompt string) (string, *InferenceStats, error) {
+	model, options := c.settings()
 	reqBody := GenerateRequest{
-		Model:     c.model,
+		Model:     model,
 		Prompt:    prompt,
 		Stream:    false,
-		Options:   c.options,
+		Options:   options,
 		KeepAlive: "30m",
 	}
 
+	key, cached, ok := c.cached("generate", model, options, reqBody.Prompt)
+	if ok {
+		return cached, &InferenceStats{Model: reqBody.Model, Cached: true}, nil
+	}
+
+	// Watched requests stream, to see the tokens arrive
+	if c.heartbeat.Enabled() {
+		reqBody.Stream = true
+		result, err := c.streamWatched(ctx, "generate", reqBody, func(r io.Reader) (*StreamResult, error) {
+			return c.processGenerateStream(r, nil)
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if key != "" {
+			c.cache.Put(key, result.Content)
+		}
+		logInference(ctx, "generate", result.Stats)
+		return result.Content, result.Stats, nil
+	}
+
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -166,14 +288,16 @@ func (c *Client) Generate(ctx context.Context, prompt string) (string, *Inferenc
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
+		slog.WarnContext(ctx, "ollama request failed", "model", reqBody.Model, "error", err)
 		return "", nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		slog.WarnContext(ctx, "ollama request failed", "model", reqBody.Model, "status", resp.StatusCode)
 		return "", nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
@@ -182,20 +306,77 @@ func (c *Client) Generate(ctx context.Context, prompt string) (string, *Inferenc
 		return "", nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	stats := CalculateStats(&genResp, c.model)
+	if key != "" {
+		c.cache.Put(key, genResp.Response)
+	}
+	stats := CalculateStats(&genResp, reqBody.Model)
+	logInference(ctx, "generate", &stats)
 	return genResp.Response, &stats, nil
 }
 
 // Chat sends messages and returns the complete response (non-streaming)
 func (c *Client) Chat(ctx context.Context, messages []Message) (string, *InferenceStats, error) {
+	return c.chat(ctx, messages, nil)
+}
+
+// ChatJSON sends messages and returns a response constrained to JSON
+// matching schema. A nil schema asks for any JSON value.
+func (c *Client) ChatJSON(ctx context.Context, messages []Message, schema json.RawMessage) (string, *InferenceStats, error) {
+	if schema == nil {
+		schema = json.RawMessage(`"json"`)
+	}
+	return c.chat(ctx, messages, schema)
+}
+
+// chat sends a non-streaming chat request with an optional response format.
+func (c *Client) chat(ctx context.Context, messages []Message, format json.RawMessage) (string, *InferenceStats, error) {
+	messages = c.redactMessages(messages)
+	start := time.Now()
+	response, stats, err := c.sendChat(ctx, messages, format)
+	c.observe(ctx, "chat", messages, response, stats, start, err)
+	return response, stats, err
+}
+
+// sendChat sends redacted messages to /api/chat.
+func (c *Client) sendChat(ctx context.Context, messages []Message, format json.RawMessage) (string, *InferenceStats, error) {
+	model, options := c.settings()
 	reqBody := ChatRequest{
-		Model:     c.model,
+		Model:     model,
 		Messages:  messages,
 		Stream:    false,
-		Options:   c.options,
+		Format:    format,
+		Options:   options,
 		KeepAlive: "30m",
 	}
 
+	var cacheInput any = reqBody.Messages
+	if format != nil {
+		cacheInput = struct {
+			Messages []Message
+			Format   string
+		}{reqBody.Messages, string(format)}
+	}
+	key, cached, ok := c.cached("chat", model, options, cacheInput)
+	if ok {
+		return cached, &InferenceStats{Model: reqBody.Model, Cached: true}, nil
+	}
+
+	// Watched requests stream, to see the tokens arrive
+	if c.heartbeat.Enabled() {
+		reqBody.Stream = true
+		result, err := c.streamWatched(ctx, "chat", reqBody, func(r io.Reader) (*StreamResult, error) {
+			return c.processChatStream(r, nil)
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if key != "" {
+			c.cache.Put(key, result.Content)
+		}
+		logInference(ctx, "chat", result.Stats)
+		return result.Content, result.Stats, nil
+	}
+
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -207,14 +388,16 @@ func (c *Client) Chat(ctx context.Context, messages []Message) (string, *Inferen
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
+		slog.WarnContext(ctx, "ollama request failed", "model", reqBody.Model, "error", err)
 		return "", nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		slog.WarnContext(ctx, "ollama request failed", "model", reqBody.Model, "status", resp.StatusCode)
 		return "", nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
@@ -223,15 +406,67 @@ func (c *Client) Chat(ctx context.Context, messages []Message) (string, *Inferen
 		return "", nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	stats := CalculateChatStats(&chatResp, c.model)
+	if key != "" {
+		c.cache.Put(key, chatResp.Message.Content)
+	}
+	stats := CalculateChatStats(&chatResp, reqBody.Model)
+	logInference(ctx, "chat", &stats)
 	return chatResp.Message.Content, &stats, nil
 }
 
+// observe reports a completed request to the observer, if any.
+func (c *Client) observe(ctx context.Context, endpoint string, messages []Message, response string, stats *InferenceStats, start time.Time, err error) {
+	if c.observer == nil {
+		return
+	}
+	c.observer(ctx, Exchange{
+		Endpoint: endpoint,
+		Model:    c.GetModel(),
+		Messages: messages,
+		Response: response,
+		Stats:    stats,
+		Latency:  time.Since(start),
+		Err:      err,
+	})
+}
+
+// logInference logs a completed request to the debug log.
+func logInference(ctx context.Context, endpoint string, stats *InferenceStats) {
+	slog.DebugContext(ctx, "ollama request",
+		"endpoint", endpoint,
+		"model", stats.Model,
+		"prompt_tokens", stats.PromptTokens,
+		"completion_tokens", stats.CompletionTokens,
+		"duration", time.Duration(stats.TotalDuration),
+	)
+}
+
+// redactPrompt removes secrets from a prompt before it leaves the process.
+func (c *Client) redactPrompt(prompt string) string {
+	if c.redactor == nil {
+		return prompt
+	}
+	return c.redactor.RedactFrom("prompt", prompt)
+}
+
+// redactMessages returns messages with secrets removed from their content.
+func (c *Client) redactMessages(messages []Message) []Message {
+	if c.redactor == nil {
+		return messages
+	}
+	out := make([]Message, len(messages))
+	for i, m := range messages {
+		m.Content = c.redactor.RedactFrom("prompt", m.Content)
+		out[i] = m
+	}
+	return out
+}
+
 // Embeddings returns the embedding for a prompt
 func (c *Client) Embeddings(ctx context.Context, model, prompt string) ([]float64, error) {
 	reqBody := EmbeddingRequest{
 		Model:  model,
-		Prompt: prompt,
+		Prompt: c.redactPrompt(prompt),
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -245,7 +480,7 @@ func (c *Client) Embeddings(ctx context.Context, model, prompt string) ([]float6
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -264,22 +499,30 @@ func (c *Client) Embeddings(ctx context.Context, model, prompt string) ([]float6
 	return embResp.Embedding, nil
 }
 
-// SetOption sets a generation option
+// SetOption sets a generation option. Requests in flight keep the options
+// they were made with.
 func (c *Client) SetOption(key string, value any) {
-	c.options[key] = value
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	options := make(map[string]any, len(c.options)+1)
+	for k, v := range c.options {
+		options[k] = v
+	}
+	options[key] = value
+	c.options = options
 }
 
 // SetTemperature sets the temperature for generation
 func (c *Client) SetTemperature(temp float64) {
-	c.options["temperature"] = temp
+	c.SetOption("temperature", temp)
 }
 
 // SetContextWindow sets the context window size
 func (c *Client) SetContextWindow(size int) {
-	c.options["num_ctx"] = size
+	c.SetOption("num_ctx", size)
 }
 
 // SetMaxTokens sets the maximum tokens to generate
 func (c *Client) SetMaxTokens(max int) {
-	c.options["num_predict"] = max
+	c.SetOption("num_predict", max)
 }
@@ -0,0 +1,173 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStalled is matched by errors.Is for a request cancelled because the
+// model sent nothing for too long.
+var ErrStalled = errors.New("model stalled")
+
+// Heartbeat watches a request for the tokens the model streams back. A
+// request that receives nothing within the window is cancelled as stalled,
+// such as when Ollama hangs loading a model or deadlocks, and retried.
+type Heartbeat struct {
+	FirstToken time.Duration // window for the first token, which includes loading the model
+	Idle       time.Duration // window between tokens; 0 disables the heartbeat
+	Retries    int           // stalled requests retried before giving up
+}
+
+// Enabled reports whether requests are watched.
+func (h Heartbeat) Enabled() bool {
+	return h.Idle > 0
+}
+
+// StallError is a request cancelled for want of tokens.
+type StallError struct {
+	Endpoint string // "generate" or "chat"
+	Model    string
+	Waited   time.Duration
+	Tokens   bool // the stall came after the model had started responding
+	Attempts int
+}
+
+func (e *StallError) Error() string {
+	when := "before its first token; the model may be stuck loading"
+	if e.Tokens {
+		when = "mid-response; Ollama may be deadlocked or out of memory"
+	}
+	return fmt.Sprintf("%s with %s stalled: no tokens for %s %s (%d attempts)",
+		e.Endpoint, e.Model, e.Waited.Round(time.Second), when, e.Attempts)
+}
+
+// Is matches ErrStalled.
+func (e *StallError) Is(target error) bool {
+	return target == ErrStalled
+}
+
+// WithHeartbeat watches generate and chat requests for stalls. Watched
+// requests stream their response so that tokens can be seen arriving.
+func WithHeartbeat(h Heartbeat) ClientOption {
+	return func(c *Client) {
+		c.heartbeat = h
+	}
+}
+
+// SetHeartbeat sets how requests are watched for stalls.
+func (c *Client) SetHeartbeat(h Heartbeat) {
+	c.heartbeat = h
+}
+
+// streamWatched posts a streaming request to endpoint and reads the
+// response with process, cancelling it when no tokens arrive within the
+// heartbeat's windows. A stalled request is logged and retried, and a
+// *StallError is returned once the retries are spent.
+func (c *Client) streamWatched(ctx context.Context, endpoint string, reqBody any, process func(io.Reader) (*StreamResult, error)) (*StreamResult, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, stall, err := c.streamOnce(ctx, endpoint, body, process)
+		if stall == nil {
+			return result, err
+		}
+		stall.Attempts = attempt
+		slog.WarnContext(ctx, "ollama request stalled", "endpoint", endpoint, "model", c.GetModel(),
+			"waited", stall.Waited, "tokens", stall.Tokens, "attempt", attempt)
+		if attempt > c.heartbeat.Retries {
+			return nil, stall
+		}
+	}
+}
+
+// streamOnce makes one attempt of streamWatched. It returns a non-nil
+// *StallError when the heartbeat cancelled the request.
+func (c *Client) streamOnce(ctx context.Context, endpoint string, body []byte, process func(io.Reader) (*StreamResult, error)) (*StreamResult, *StallError, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The timer starts with the first-token window and restarts with the
+	// idle window on every read from the stream
+	var stalled, beat atomic.Bool
+	window := c.heartbeat.FirstToken
+	if window <= 0 {
+		window = c.heartbeat.Idle
+	}
+	var last atomic.Int64
+	last.Store(time.Now().UnixNano())
+	timer := time.AfterFunc(window, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer timer.Stop()
+	stall := func() *StallError {
+		if !stalled.Load() {
+			return nil
+		}
+		return &StallError{
+			Endpoint: endpoint,
+			Model:    c.GetModel(),
+			Waited:   time.Since(time.Unix(0, last.Load())),
+			Tokens:   beat.Load(),
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		if s := stall(); s != nil {
+			return nil, s, s
+		}
+		slog.WarnContext(ctx, "ollama request failed", "model", c.GetModel(), "error", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		slog.WarnContext(ctx, "ollama request failed", "model", c.GetModel(), "status", resp.StatusCode)
+		return nil, nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	result, err := process(&heartbeatReader{r: resp.Body, beat: func() {
+		beat.Store(true)
+		last.Store(time.Now().UnixNano())
+		timer.Reset(c.heartbeat.Idle)
+	}})
+	if err != nil {
+		if s := stall(); s != nil {
+			return nil, s, s
+		}
+	}
+	return result, nil, err
+}
+
+// heartbeatReader calls beat on every read that returns data.
+type heartbeatReader struct {
+	r    io.Reader
+	beat func()
+}
+
+func (h *heartbeatReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	if n > 0 {
+		h.beat()
+	}
+	return n, err
+}
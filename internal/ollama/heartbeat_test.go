@@ -0,0 +1,76 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// streamServer streams the chunks of a chat response, pausing before each
+// as long as pauses says.
+func streamServer(t *testing.T, requests *atomic.Int32, pauses []time.Duration, chunks []string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		io.Copy(io.Discard, r.Body) // so a client hanging up cancels r's context
+		flusher := w.(http.Flusher)
+		for i, chunk := range chunks {
+			select {
+			case <-time.After(pauses[i]):
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprintf(w, `{"message":{"role":"assistant","content":%q},"done":%t}`+"\n", chunk, i == len(chunks)-1)
+			flusher.Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_Heartbeat_Streams(t *testing.T) {
+	var requests atomic.Int32
+	srv := streamServer(t, &requests, []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}, []string{"all ", "is ", "well"})
+	c := NewClient(WithBaseURL(srv.URL), WithModel("m"), WithHeartbeat(Heartbeat{FirstToken: time.Second, Idle: 200 * time.Millisecond}))
+
+	got, stats, err := c.Chat(context.Background(), []Message{{Role: "user", Content: "heartbeat streams"}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if got != "all is well" || stats == nil {
+		t.Errorf("Chat() = %q, %+v", got, stats)
+	}
+}
+
+func TestClient_Heartbeat_StallsBeforeFirstToken(t *testing.T) {
+	var requests atomic.Int32
+	srv := streamServer(t, &requests, []time.Duration{time.Minute}, []string{"late"})
+	c := NewClient(WithBaseURL(srv.URL), WithModel("m"), WithHeartbeat(Heartbeat{FirstToken: 100 * time.Millisecond, Idle: 50 * time.Millisecond, Retries: 1}))
+
+	_, _, err := c.Chat(context.Background(), []Message{{Role: "user", Content: "heartbeat stalls loading"}})
+	var stall *StallError
+	if !errors.As(err, &stall) || !errors.Is(err, ErrStalled) {
+		t.Fatalf("Chat() error = %v, want a stall", err)
+	}
+	if stall.Tokens || stall.Attempts != 2 || requests.Load() != 2 {
+		t.Errorf("stall = %+v after %d requests, want 2 attempts without tokens", stall, requests.Load())
+	}
+}
+
+func TestClient_Heartbeat_StallsMidResponse(t *testing.T) {
+	var requests atomic.Int32
+	srv := streamServer(t, &requests, []time.Duration{0, time.Minute}, []string{"half", "never"})
+	c := NewClient(WithBaseURL(srv.URL), WithModel("m"), WithHeartbeat(Heartbeat{FirstToken: time.Second, Idle: 100 * time.Millisecond}))
+
+	_, _, err := c.Chat(context.Background(), []Message{{Role: "user", Content: "heartbeat stalls mid-response"}})
+	var stall *StallError
+	if !errors.As(err, &stall) || !stall.Tokens || stall.Attempts != 1 {
+		t.Fatalf("Chat() error = %v, want a stall after tokens", err)
+	}
+}
@@ -0,0 +1,205 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// Host is an Ollama server that clients share, such as a GPU box serving
+// the coder while embeddings run on the local CPU. It limits the requests
+// in flight to it and remembers whether it last answered.
+type Host struct {
+	Name string
+	URL  string
+
+	target   *url.URL
+	slots    chan struct{} // nil is unlimited
+	inFlight atomic.Int32
+	down     atomic.Bool
+}
+
+// NewHost returns a host serving at rawURL that takes at most
+// maxConcurrent requests at a time; 0 is unlimited. Hosts start healthy.
+func NewHost(name, rawURL string, maxConcurrent int) (*Host, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("host %s: invalid url %q", name, rawURL)
+	}
+	h := &Host{Name: name, URL: rawURL, target: u}
+	if maxConcurrent > 0 {
+		h.slots = make(chan struct{}, maxConcurrent)
+	}
+	return h, nil
+}
+
+// MaxConcurrent returns the limit on requests in flight, 0 for none.
+func (h *Host) MaxConcurrent() int {
+	return cap(h.slots)
+}
+
+// InFlight returns the number of requests being served.
+func (h *Host) InFlight() int {
+	return int(h.inFlight.Load())
+}
+
+// Full reports whether the host is at its concurrency limit.
+func (h *Host) Full() bool {
+	return h.slots != nil && h.InFlight() >= cap(h.slots)
+}
+
+// Healthy reports whether the host answered its last check or request.
+func (h *Host) Healthy() bool {
+	return !h.down.Load()
+}
+
+// SetHealthy records whether the host is answering and reports whether
+// that changed.
+func (h *Host) SetHealthy(healthy bool) bool {
+	return h.down.Swap(!healthy) == healthy
+}
+
+// Check asks the host for its models, as CheckConnection does, and records
+// whether it answered. Checks do not count against the concurrency limit.
+func (h *Host) Check(ctx context.Context, httpClient *http.Client) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.URL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("ollama returned status %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		h.SetHealthy(false)
+		return fmt.Errorf("host %s at %s: %w", h.Name, h.URL, err)
+	}
+	h.SetHealthy(true)
+	return nil
+}
+
+// acquire waits for a free slot on the host.
+func (h *Host) acquire(ctx context.Context) error {
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	h.inFlight.Add(1)
+	return nil
+}
+
+func (h *Host) release() {
+	h.inFlight.Add(-1)
+	if h.slots != nil {
+		<-h.slots
+	}
+}
+
+// HostPicker chooses the host for a request, excluding hosts that already
+// failed it. It returns nil when no host is left to try.
+type HostPicker func(tried map[*Host]bool) *Host
+
+// WithHosts sends generation requests to the hosts pick chooses instead of
+// the base URL. A request that cannot reach its host marks the host down
+// and is retried on the next one pick offers.
+func WithHosts(pick HostPicker) ClientOption {
+	return func(c *Client) {
+		c.pickHost = pick
+	}
+}
+
+// SetHosts sets the picker of WithHosts; nil sends requests to the base URL.
+func (c *Client) SetHosts(pick HostPicker) {
+	c.pickHost = pick
+}
+
+// do sends req to a host chosen by the client's picker, holding one of the
+// host's slots until the response body is closed. Without a picker it
+// sends req as built.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.doWith(c.httpClient, req)
+}
+
+// doWith is do sending with httpClient.
+func (c *Client) doWith(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	if c.pickHost == nil {
+		return httpClient.Do(req)
+	}
+
+	tried := map[*Host]bool{}
+	var lastErr error
+	for {
+		h := c.pickHost(tried)
+		if h == nil {
+			if lastErr == nil {
+				lastErr = errors.New("no ollama host available")
+			}
+			return nil, lastErr
+		}
+		tried[h] = true
+
+		attempt, err := hostRequest(req, h, len(tried) > 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.acquire(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := httpClient.Do(attempt)
+		if err == nil {
+			h.SetHealthy(true)
+			resp.Body = &releaseBody{ReadCloser: resp.Body, host: h}
+			return resp, nil
+		}
+		h.release()
+		if req.Context().Err() != nil || (req.GetBody == nil && req.Body != nil) {
+			return nil, err
+		}
+		if h.SetHealthy(false) {
+			slog.WarnContext(req.Context(), "ollama host down, failing over", "host", h.Name, "url", h.URL, "error", err)
+		}
+		lastErr = err
+	}
+}
+
+// hostRequest returns req addressed to h, with a fresh body when req has
+// already been sent.
+func hostRequest(req *http.Request, h *Host, resend bool) (*http.Request, error) {
+	attempt := req.Clone(req.Context())
+	attempt.URL.Scheme = h.target.Scheme
+	attempt.URL.Host = h.target.Host
+	attempt.Host = ""
+	if resend && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+// releaseBody frees its host's slot when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	host *Host
+	once sync.Once
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.host.release)
+	return err
+}
@@ -1,5 +1,7 @@
 package ollama
 
+import "encoding/json"
+
 // Message represents a chat message
 type Message struct {
 	Role    string   `json:"role"`             // "system", "user", "assistant"
@@ -34,11 +36,12 @@ type GenerateResponse struct {
 
 // ChatRequest is the request body for /api/chat
 type ChatRequest struct {
-	Model     string         `json:"model"`
-	Messages  []Message      `json:"messages"`
-	Stream    bool           `json:"stream"`
-	Options   map[string]any `json:"options,omitempty"`
-	KeepAlive string         `json:"keep_alive,omitempty"`
+	Model     string          `json:"model"`
+	Messages  []Message       `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Format    json.RawMessage `json:"format,omitempty"` // "json" or a JSON schema the response must match
+	Options   map[string]any  `json:"options,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
 }
 
 // ChatResponse is a single response chunk from /api/chat
@@ -68,6 +71,20 @@ type TagsResponse struct {
 	Models []ModelInfo `json:"models"`
 }
 
+// RunningModel is a model loaded in memory, from /api/ps. SizeVRAM is the
+// part of Size held in GPU memory; the rest is in system RAM.
+type RunningModel struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	SizeVRAM  int64  `json:"size_vram"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// PsResponse is the response from /api/ps
+type PsResponse struct {
+	Models []RunningModel `json:"models"`
+}
+
 // EmbeddingRequest is the request body for /api/embeddings
 type EmbeddingRequest struct {
 	Model   string         `json:"model"`
@@ -89,7 +106,9 @@ type InferenceStats struct {
 	PromptEvalDuration int64 // nanoseconds
 	EvalDuration       int64 // nanoseconds
 	TotalDuration      int64 // nanoseconds
+	LoadDuration       int64 // nanoseconds spent loading the model
 	TokensPerSecond    float64
+	Cached             bool // served from the response cache
 }
 
 // CalculateStats calculates inference statistics from a response
@@ -102,6 +121,7 @@ func CalculateStats(resp *GenerateResponse, model string) InferenceStats {
 		PromptEvalDuration: resp.PromptEvalDuration,
 		EvalDuration:       resp.EvalDuration,
 		TotalDuration:      resp.TotalDuration,
+		LoadDuration:       resp.LoadDuration,
 	}
 
 	// Calculate tokens per second
@@ -124,6 +144,7 @@ func CalculateChatStats(resp *ChatResponse, model string) InferenceStats {
 		PromptEvalDuration: resp.PromptEvalDuration,
 		EvalDuration:       resp.EvalDuration,
 		TotalDuration:      resp.TotalDuration,
+		LoadDuration:       resp.LoadDuration,
 	}
 
 	// Calculate tokens per second
@@ -0,0 +1,173 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ErrNotInstalled is matched by errors.Is when the ollama binary needed to
+// start the server cannot be found.
+var ErrNotInstalled = errors.New("ollama is not installed")
+
+// Defaults for starting the server
+const (
+	DefaultServerBinary  = "ollama"
+	DefaultServerTimeout = 30 * time.Second
+
+	serverPollInterval = 250 * time.Millisecond
+	serverStopTimeout  = 5 * time.Second
+)
+
+// ServerOptions configures how EnsureServer starts `ollama serve`.
+type ServerOptions struct {
+	Binary  string        // the ollama binary, looked up on PATH; empty is DefaultServerBinary
+	Timeout time.Duration // how long to wait for the server to answer; 0 is DefaultServerTimeout
+	Log     io.Writer     // receives the server's output; nil discards it
+}
+
+// Server is an `ollama serve` process started by EnsureServer.
+type Server struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error // the process's exit, once done is closed
+}
+
+// EnsureServer makes sure the Ollama server at the client's base URL is
+// running. When it already answers EnsureServer returns a nil *Server;
+// otherwise it starts `ollama serve` listening on the base URL's address
+// and polls it until it answers, returning the started server. A server
+// on another host cannot be started, and a missing binary is reported
+// with ErrNotInstalled.
+func (c *Client) EnsureServer(ctx context.Context, opts ServerOptions) (*Server, error) {
+	if err := c.checkServer(ctx); err == nil {
+		return nil, nil
+	} else if !isLocalURL(c.baseURL) {
+		return nil, fmt.Errorf("cannot start ollama for remote %s: %w", c.baseURL, err)
+	}
+
+	if opts.Binary == "" {
+		opts.Binary = DefaultServerBinary
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultServerTimeout
+	}
+	if opts.Log == nil {
+		opts.Log = io.Discard
+	}
+
+	bin, err := exec.LookPath(opts.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q not found on PATH; install it from https://ollama.com/download", ErrNotInstalled, opts.Binary)
+	}
+
+	cmd := exec.Command(bin, "serve")
+	cmd.Env = os.Environ()
+	if host := serverHost(c.baseURL); host != "" {
+		cmd.Env = append(cmd.Env, "OLLAMA_HOST="+host)
+	}
+	cmd.Stdout = opts.Log
+	cmd.Stderr = opts.Log
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s serve: %w", bin, err)
+	}
+	s := &Server{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		s.err = cmd.Wait()
+		close(s.done)
+	}()
+	slog.InfoContext(ctx, "started ollama serve", "pid", cmd.Process.Pid, "url", c.baseURL)
+
+	if err := c.waitServer(ctx, s, opts.Timeout); err != nil {
+		s.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// waitServer polls the server until it answers, it exits, or timeout passes.
+func (c *Client) waitServer(ctx context.Context, s *Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(serverPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return fmt.Errorf("ollama serve exited before answering at %s: %v", c.baseURL, s.err)
+		case <-ctx.Done():
+			return fmt.Errorf("ollama serve did not answer at %s within %s", c.baseURL, timeout)
+		case <-ticker.C:
+			if c.checkServer(ctx) == nil {
+				return nil
+			}
+		}
+	}
+}
+
+// checkServer is CheckConnection bounded to one poll's worth of waiting.
+func (c *Client) checkServer(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return c.CheckConnection(ctx)
+}
+
+// Pid returns the server's process ID.
+func (s *Server) Pid() int {
+	return s.cmd.Process.Pid
+}
+
+// Stop asks the server to exit and waits for it, killing it if it has not
+// exited in time. Stopping a nil or exited server does nothing.
+func (s *Server) Stop() {
+	if s == nil {
+		return
+	}
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	terminate(s.cmd)
+	select {
+	case <-s.done:
+	case <-time.After(serverStopTimeout):
+		_ = s.cmd.Process.Kill()
+		<-s.done
+	}
+	slog.Info("stopped ollama serve", "pid", s.cmd.Process.Pid)
+}
+
+// isLocalURL reports whether rawURL names this machine.
+func isLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" || host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// serverHost returns the OLLAMA_HOST address for rawURL, host:port.
+func serverHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Port() == "" {
+		return net.JoinHostPort(u.Hostname(), "11434")
+	}
+	return u.Host
+}
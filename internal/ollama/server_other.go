@@ -0,0 +1,13 @@
+//go:build !unix
+
+package ollama
+
+import "os/exec"
+
+// detach is a no-op where process groups are unavailable.
+func detach(cmd *exec.Cmd) {}
+
+// terminate kills cmd; there is no graceful stop.
+func terminate(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,64 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_EnsureServer_AlreadyRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[]}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewClient(WithBaseURL(srv.URL)).EnsureServer(context.Background(), ServerOptions{Binary: "no-such-ollama"})
+	if err != nil {
+		t.Fatalf("EnsureServer: %v", err)
+	}
+	if s != nil {
+		t.Errorf("EnsureServer started a server for a running one")
+	}
+}
+
+func TestClient_EnsureServer_NotInstalled(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close() // nothing answers at url now
+
+	_, err := NewClient(WithBaseURL(url)).EnsureServer(context.Background(), ServerOptions{Binary: "no-such-ollama"})
+	if !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("err = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestClient_EnsureServer_Remote(t *testing.T) {
+	_, err := NewClient(WithBaseURL("http://192.0.2.1:11434")).EnsureServer(context.Background(), ServerOptions{Binary: "no-such-ollama"})
+	if err == nil || errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("err = %v, want a remote server error", err)
+	}
+}
+
+func TestServerHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://localhost:11434", "localhost:11434"},
+		{"http://127.0.0.1", "127.0.0.1:11434"},
+		{"http://0.0.0.0:8080/", "0.0.0.0:8080"},
+	}
+	for _, tt := range tests {
+		if got := serverHost(tt.url); got != tt.want {
+			t.Errorf("serverHost(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+		if !isLocalURL(tt.url) {
+			t.Errorf("isLocalURL(%q) = false", tt.url)
+		}
+	}
+	if isLocalURL("http://example.com:11434") {
+		t.Errorf("isLocalURL(example.com) = true")
+	}
+}
@@ -22,11 +22,12 @@ type StreamResult struct {
 
 // GenerateStream sends a prompt and streams the response
 func (c *Client) GenerateStream(ctx context.Context, prompt string, callback StreamCallback) (*StreamResult, error) {
+	model, options := c.settings()
 	reqBody := GenerateRequest{
-		Model:     c.model,
-		Prompt:    prompt,
+		Model:     model,
+		Prompt:    c.redactPrompt(prompt),
 		Stream:    true,
-		Options:   c.options,
+		Options:   options,
 		KeepAlive: "30m",
 	}
 
@@ -41,7 +42,7 @@ func (c *Client) GenerateStream(ctx context.Context, prompt string, callback Str
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -98,7 +99,7 @@ func (c *Client) processGenerateStream(body io.Reader, callback StreamCallback)
 	}
 
 	result.Content = fullContent
-	stats := CalculateStats(&lastResp, c.model)
+	stats := CalculateStats(&lastResp, c.GetModel())
 	result.Stats = &stats
 
 	return result, nil
@@ -106,11 +107,12 @@ func (c *Client) processGenerateStream(body io.Reader, callback StreamCallback)
 
 // ChatStream sends messages and streams the response
 func (c *Client) ChatStream(ctx context.Context, messages []Message, callback StreamCallback) (*StreamResult, error) {
+	model, options := c.settings()
 	reqBody := ChatRequest{
-		Model:     c.model,
-		Messages:  messages,
+		Model:     model,
+		Messages:  c.redactMessages(messages),
 		Stream:    true,
-		Options:   c.options,
+		Options:   options,
 		KeepAlive: "30m",
 	}
 
@@ -125,7 +127,7 @@ func (c *Client) ChatStream(ctx context.Context, messages []Message, callback St
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -180,7 +182,7 @@ func (c *Client) processChatStream(body io.Reader, callback StreamCallback) (*St
 	}
 
 	result.Content = fullContent
-	stats := CalculateChatStats(&lastResp, c.model)
+	stats := CalculateChatStats(&lastResp, c.GetModel())
 	result.Stats = &stats
 
 	return result, nil
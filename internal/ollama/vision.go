@@ -40,12 +40,13 @@ func (c *Client) GenerateWithVision(ctx context.Context, prompt string, imagePat
 		encodedImages = append(encodedImages, base64.StdEncoding.EncodeToString(data))
 	}
 
+	model, options := c.settings()
 	reqBody := GenerateRequest{
-		Model:     c.model,
-		Prompt:    prompt,
+		Model:     model,
+		Prompt:    c.redactPrompt(prompt),
 		Images:    encodedImages,
 		Stream:    false,
-		Options:   c.options,
+		Options:   options,
 		KeepAlive: "30m",
 	}
 
@@ -74,7 +75,7 @@ func (c *Client) visionRequest(ctx context.Context, path string, reqBody interfa
 		client = &newClient
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.doWith(client, req)
 	if err != nil {
 		return "", nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -91,7 +92,7 @@ func (c *Client) visionRequest(ctx context.Context, path string, reqBody interfa
 		return "", nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	stats := CalculateStats(&genResp, c.model)
+	stats := CalculateStats(&genResp, c.GetModel())
 	return genResp.Response, &stats, nil
 }
 
@@ -126,7 +127,7 @@ func (c *Client) VisionHealthCheck(ctx context.Context) error {
 	tinyPixel := "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
 	
 	reqBody := GenerateRequest{
-		Model:     c.model,
+		Model:     c.GetModel(),
 		Prompt:    "What is in this image?",
 		Images:    []string{tinyPixel},
 		Stream:    false,
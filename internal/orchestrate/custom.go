@@ -0,0 +1,165 @@
+package orchestrate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxScheduleID is the highest schedule ID that can be registered.
+// Flow codes encode schedules as a single digit (S1-S9), which leaves room
+// for four custom schedules beyond the core five.
+const MaxScheduleID ScheduleID = 9
+
+// ScheduleDefinition describes a custom schedule defined beyond the core five.
+type ScheduleDefinition struct {
+	Name         string
+	Description  string
+	Processes    [3]string
+	Model        ModelType
+	Consultation map[ProcessID]ConsultationType
+}
+
+var (
+	customMu        sync.Mutex
+	customSchedules = make(map[ScheduleID]ScheduleDefinition)
+)
+
+// RegisterSchedule registers a custom schedule and returns its assigned ID.
+// Custom schedules take the next free ID after Production. Registration must
+// happen before orchestration starts since the name tables are shared globals.
+func RegisterSchedule(def ScheduleDefinition) (ScheduleID, error) {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	def.Name = strings.TrimSpace(def.Name)
+	if def.Name == "" {
+		return 0, fmt.Errorf("custom schedule name is required")
+	}
+	for _, existing := range ScheduleNames {
+		if strings.EqualFold(existing, def.Name) {
+			return 0, fmt.Errorf("schedule %q is already defined", def.Name)
+		}
+	}
+	for i, p := range def.Processes {
+		if strings.TrimSpace(p) == "" {
+			return 0, fmt.Errorf("schedule %q: process %d name is required", def.Name, i+1)
+		}
+	}
+	if def.Model == "" {
+		def.Model = ModelCoder
+	}
+
+	id := ScheduleProduction + 1
+	for {
+		if _, taken := ScheduleNames[id]; !taken {
+			break
+		}
+		id++
+	}
+	if id > MaxScheduleID {
+		return 0, fmt.Errorf("cannot register schedule %q: at most %d custom schedules are supported",
+			def.Name, int(MaxScheduleID-ScheduleProduction))
+	}
+
+	ScheduleNames[id] = def.Name
+	ProcessNames[id] = map[ProcessID]string{
+		Process1: def.Processes[0],
+		Process2: def.Processes[1],
+		Process3: def.Processes[2],
+	}
+	customSchedules[id] = def
+
+	return id, nil
+}
+
+// ClearCustomSchedules removes all registered custom schedules.
+func ClearCustomSchedules() {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	for id := range customSchedules {
+		delete(ScheduleNames, id)
+		delete(ProcessNames, id)
+	}
+	customSchedules = make(map[ScheduleID]ScheduleDefinition)
+}
+
+// GetScheduleDefinition returns the definition of a custom schedule.
+func GetScheduleDefinition(id ScheduleID) (ScheduleDefinition, bool) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	def, ok := customSchedules[id]
+	return def, ok
+}
+
+// IsCoreSchedule returns true if id is one of the five built-in schedules
+func IsCoreSchedule(id ScheduleID) bool {
+	return id >= ScheduleKnowledge && id <= ScheduleProduction
+}
+
+// IsCustomSchedule returns true if id refers to a registered custom schedule
+func IsCustomSchedule(id ScheduleID) bool {
+	_, ok := GetScheduleDefinition(id)
+	return ok
+}
+
+// IsKnownSchedule returns true if id is a core or registered custom schedule
+func IsKnownSchedule(id ScheduleID) bool {
+	return IsCoreSchedule(id) || IsCustomSchedule(id)
+}
+
+// CustomSchedules returns the IDs of all registered custom schedules in ascending order.
+func CustomSchedules() []ScheduleID {
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	ids := make([]ScheduleID, 0, len(customSchedules))
+	for id := range customSchedules {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// AllSchedules returns every known schedule ID: the core five followed by custom schedules.
+func AllSchedules() []ScheduleID {
+	ids := []ScheduleID{ScheduleKnowledge, SchedulePlan, ScheduleImplement, ScheduleScale, ScheduleProduction}
+	return append(ids, CustomSchedules()...)
+}
+
+// ScheduleSelectionOrder returns the order in which schedules are visited by
+// heuristic selection. Custom schedules run before Production so that
+// Production can remain the last schedule before prompt termination.
+func ScheduleSelectionOrder() []ScheduleID {
+	ids := []ScheduleID{ScheduleKnowledge, SchedulePlan, ScheduleImplement, ScheduleScale}
+	ids = append(ids, CustomSchedules()...)
+	return append(ids, ScheduleProduction)
+}
+
+// describeSchedules returns a numbered list of schedules for orchestrator prompts.
+func describeSchedules() string {
+	core := map[ScheduleID]string{
+		ScheduleKnowledge:  "For gathering information.",
+		SchedulePlan:       "For designing solutions.",
+		ScheduleImplement:  "For executing code.",
+		ScheduleScale:      "For performance tuning.",
+		ScheduleProduction: "For final polish and consistency.",
+	}
+
+	var sb strings.Builder
+	for _, id := range AllSchedules() {
+		names := ProcessNames[id]
+		desc := core[id]
+		if def, ok := GetScheduleDefinition(id); ok {
+			desc = def.Description
+			if desc == "" {
+				desc = "Custom schedule."
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%d: %s (%s, %s, %s) - %s\n",
+			id, ScheduleNames[id], names[Process1], names[Process2], names[Process3], desc))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
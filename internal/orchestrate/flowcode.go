@@ -3,6 +3,8 @@ package orchestrate
 import (
 	"fmt"
 	"strings"
+
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 // FlowCode tracks the orchestration flow as a compact string
@@ -60,7 +62,7 @@ func (f *FlowCode) Parse(code string) ([]FlowEvent, error) {
 				return nil, fmt.Errorf("unexpected end after S at position %d", i-1)
 			}
 			scheduleNum := int(code[i] - '0')
-			if scheduleNum < 1 || scheduleNum > 5 {
+			if scheduleNum < 1 || scheduleNum > int(MaxScheduleID) {
 				return nil, fmt.Errorf("invalid schedule number %d at position %d", scheduleNum, i)
 			}
 			events = append(events, FlowEvent{
@@ -115,8 +117,13 @@ type FlowEvent struct {
 }
 
 // FormatFlowCodeColored returns the flow code with ANSI colors
-// Schedule codes (S1-S5) in white, process codes (P1-P3) in blue, X in red
+// Schedule codes (S1-S5) in white, process codes (P1-P3) in blue, X in red.
+// It returns the code as is when colors are off.
 func FormatFlowCodeColored(code string) string {
+	if !term.Colors() {
+		return code
+	}
+
 	var result strings.Builder
 	
 	const (
@@ -173,7 +180,7 @@ func CalculateFlowStats(code string) (*FlowStats, error) {
 	}
 	
 	// Initialize process counts for all schedules
-	for s := ScheduleKnowledge; s <= ScheduleProduction; s++ {
+	for _, s := range AllSchedules() {
 		stats.ProcessCounts[s] = make(map[ProcessID]int)
 	}
 	
@@ -185,6 +192,9 @@ func CalculateFlowStats(code string) (*FlowStats, error) {
 			stats.TotalSchedulings++
 			stats.ScheduleCounts[event.Schedule]++
 			currentSchedule = event.Schedule
+			if stats.ProcessCounts[currentSchedule] == nil {
+				stats.ProcessCounts[currentSchedule] = make(map[ProcessID]int)
+			}
 		case EventProcess:
 			stats.TotalProcesses++
 			stats.ProcessCounts[currentSchedule][event.Process]++
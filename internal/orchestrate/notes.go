@@ -0,0 +1,164 @@
+package orchestrate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NoteType classifies what a session note records.
+type NoteType string
+
+const (
+	NoteGeneral    NoteType = "general"
+	NoteDecision   NoteType = "decision"
+	NoteConstraint NoteType = "constraint"
+	NoteTodo       NoteType = "todo"
+	NoteDiscovery  NoteType = "discovery"
+	NoteRisk       NoteType = "risk"
+)
+
+// NoteTypes lists the typed note kinds in the order summaries show them.
+var NoteTypes = []NoteType{NoteDecision, NoteConstraint, NoteTodo, NoteDiscovery, NoteRisk}
+
+// ParseNoteType parses a note type name such as "todo" or "Risk".
+func ParseNoteType(s string) (NoteType, error) {
+	t := NoteType(strings.ToLower(strings.TrimSpace(s)))
+	if t == NoteGeneral {
+		return t, nil
+	}
+	for _, known := range NoteTypes {
+		if t == known {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown note type %q (use decision, constraint, todo, discovery, or risk)", s)
+}
+
+// kind returns the note's type, treating notes saved before types existed
+// as general notes.
+func (n Note) kind() NoteType {
+	if n.Type == "" {
+		return NoteGeneral
+	}
+	return n.Type
+}
+
+// HasTag reports whether the note carries tag.
+func (n Note) HasTag(tag string) bool {
+	for _, t := range n.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// NoteFilter selects session notes. Zero fields match every note.
+type NoteFilter struct {
+	Types      []NoteType // any of these types
+	Tags       []string   // all of these tags
+	Source     string
+	Unreviewed bool
+	Since      time.Time
+}
+
+// Matches reports whether n passes the filter.
+func (f NoteFilter) Matches(n Note) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			if n.kind() == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range f.Tags {
+		if !n.HasTag(tag) {
+			return false
+		}
+	}
+	if f.Source != "" && n.Source != f.Source {
+		return false
+	}
+	if f.Unreviewed && n.Reviewed {
+		return false
+	}
+	if !f.Since.IsZero() && n.Timestamp.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// AddTypedNote adds a session note of type t with optional tags.
+func (o *Orchestrator) AddTypedNote(t NoteType, content, source string, tags ...string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	note := Note{
+		ID:        fmt.Sprintf("N%d", len(o.sessionNotes)+1),
+		Timestamp: time.Now(),
+		Content:   content,
+		Source:    source,
+		Type:      t,
+		Tags:      tags,
+	}
+	o.sessionNotes = append(o.sessionNotes, note)
+}
+
+// scheduleNoteTypes are the note types injected into each schedule's prompt.
+var scheduleNoteTypes = map[ScheduleID][]NoteType{
+	ScheduleKnowledge:  {NoteConstraint, NoteDiscovery, NoteTodo},
+	SchedulePlan:       {NoteDecision, NoteConstraint, NoteDiscovery, NoteRisk},
+	ScheduleImplement:  {NoteDecision, NoteConstraint, NoteTodo},
+	ScheduleScale:      {NoteDecision, NoteConstraint, NoteRisk},
+	ScheduleProduction: {NoteConstraint, NoteTodo, NoteRisk},
+}
+
+// NoteTypesForSchedule returns the note types relevant to a schedule.
+// Custom schedules see every typed note.
+func NoteTypesForSchedule(id ScheduleID) []NoteType {
+	if types, ok := scheduleNoteTypes[id]; ok {
+		return types
+	}
+	return NoteTypes
+}
+
+// maxPromptNotes bounds how many notes NotesPrompt includes.
+const maxPromptNotes = 20
+
+// NotesPrompt returns the session notes relevant to a schedule as a prompt
+// section, most recent last, or "" when there are none.
+func (o *Orchestrator) NotesPrompt(id ScheduleID) string {
+	notes := o.GetNotes(NoteFilter{Types: NoteTypesForSchedule(id)})
+	if len(notes) == 0 {
+		return ""
+	}
+	if len(notes) > maxPromptNotes {
+		notes = notes[len(notes)-maxPromptNotes:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SESSION NOTES (from earlier schedules):\n")
+	for _, n := range notes {
+		fmt.Fprintf(&sb, "- [%s] %s", n.kind(), n.Content)
+		if len(n.Tags) > 0 {
+			fmt.Fprintf(&sb, " (tags: %s)", strings.Join(n.Tags, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// GroupNotesByType groups notes by type, keeping their order within a type.
+func GroupNotesByType(notes []Note) map[NoteType][]Note {
+	groups := make(map[NoteType][]Note)
+	for _, n := range notes {
+		groups[n.kind()] = append(groups[n.kind()], n)
+	}
+	return groups
+}
@@ -3,11 +3,14 @@ package orchestrate
 
 import (
 	"context"
+	"log/slog"
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/croberts/obot/internal/logging"
 	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/planner"
 )
@@ -78,12 +81,22 @@ type Orchestrator struct {
 	// AI Client
 	ollamaClient *ollama.Client
 
+	// Called with the tokens of each decision the model makes
+	onTokens func(client *ollama.Client, tokens int64)
+
 	// Statistics
 	stats *OrchestratorStats
 
 	// Planner
 	planner *planner.PreOrchestrationPlanner
 
+	// Forced schedule revisit requested mid-schedule (0 = none)
+	pendingRevisit ScheduleID
+
+	// Position restored from a persisted session; Run continues from here
+	resumeSchedule ScheduleID
+	resumeProcess  ProcessID
+
 	// Callbacks
 	onStateChange   func(OrchestratorState)
 	onScheduleStart func(ScheduleID)
@@ -92,8 +105,17 @@ type Orchestrator struct {
 	onScheduleEnd   func(ScheduleID)
 	onError         func(error)
 
-	// Plugins
+	// Suspension handling for process errors
+	errorHandler ErrorHandler
+
+	// Quality gate consulted before the prompt terminates
+	terminationGate TerminationGate
+	maxGateCycles   int
+	gateCycles      int
+
+	// Plugins, and the context of the run they are called with
 	plugins []OrchestratorPlugin
+	runCtx  context.Context
 }
 
 // NewOrchestrator creates a new orchestrator
@@ -142,12 +164,21 @@ func (o *Orchestrator) State() OrchestratorState {
 	return o.state
 }
 
+// pluginCtxLocked returns the context plugins are called with: that of
+// the run, so that cancelling it stops them too. Called with o.mu held.
+func (o *Orchestrator) pluginCtxLocked() context.Context {
+	if o.runCtx != nil {
+		return o.runCtx
+	}
+	return context.Background()
+}
+
 // SetState updates the orchestrator state
 func (o *Orchestrator) SetState(state OrchestratorState) {
 	o.mu.Lock()
 	o.state = state
 	callback := o.onStateChange
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	o.mu.Unlock()
 
 	if callback != nil {
@@ -155,7 +186,7 @@ func (o *Orchestrator) SetState(state OrchestratorState) {
 	}
 
 	for _, p := range plugins {
-		_ = p.OnStateChange(context.Background(), state)
+		_ = p.OnStateChange(pluginCtx, state)
 	}
 }
 
@@ -187,6 +218,14 @@ func (o *Orchestrator) SetClient(client *ollama.Client) {
 func (o *Orchestrator) DefaultSelectSchedule(ctx context.Context) (ScheduleID, error) {
 	o.mu.Lock()
 	client := o.ollamaClient
+	o.mu.Unlock()
+	return o.selectScheduleWith(ctx, client)
+}
+
+// selectScheduleWith selects the next schedule by asking client, or by the
+// heuristic when client is nil or its answer cannot be parsed.
+func (o *Orchestrator) selectScheduleWith(ctx context.Context, client *ollama.Client) (ScheduleID, error) {
+	o.mu.Lock()
 	prompt := o.prompt
 	history := o.scheduleHistory
 	counts := o.scheduleCounts
@@ -208,31 +247,27 @@ func (o *Orchestrator) DefaultSelectSchedule(ctx context.Context) (ScheduleID, e
 
 	// Build counts string
 	var c []string
-	for id := ScheduleKnowledge; id <= ScheduleProduction; id++ {
+	for _, id := range AllSchedules() {
 		c = append(c, fmt.Sprintf("%s: %d", ScheduleNames[id], counts[id]))
 	}
 	countsStr := strings.Join(c, ", ")
 
 	systemPrompt := `You are the orchestrator for obot. Select the next schedule based on history and intent.
 Valid schedules:
-1: Knowledge (Research, Crawl, Retrieve) - For gathering information.
-2: Plan (Brainstorm, Clarify, Plan) - For designing solutions.
-3: Implement (Implement, Verify, Feedback) - For executing code.
-4: Scale (Scale, Benchmark, Optimize) - For performance tuning.
-5: Production (Analyze, Systemize, Harmonize) - For final polish and consistency.
+` + describeSchedules() + `
 
 Rules:
-- You must run all 5 schedules at least once before terminating.
+- You must run all 5 core schedules (1-5) at least once before terminating.
 - The last schedule MUST be Production.
-- Respond ONLY with the schedule number (1-5) or 0 to terminate prompt.`
+- Respond ONLY with the schedule number or 0 to terminate prompt.`
 
 	userPrompt := fmt.Sprintf(`Initial Prompt: %s
 Schedule History: %s
 Schedule Counts: %s
 
-Next Schedule (1-5, or 0 to terminate):`, prompt, historyStr, countsStr)
+Next Schedule (schedule number, or 0 to terminate):`, prompt, historyStr, countsStr)
 
-	resp, _, err := client.Generate(ctx, systemPrompt+"\n\n"+userPrompt)
+	resp, err := o.generate(ctx, client, systemPrompt+"\n\n"+userPrompt)
 	if err != nil {
 		return 0, fmt.Errorf("llm generation failed: %w", err)
 	}
@@ -249,7 +284,7 @@ Next Schedule (1-5, or 0 to terminate):`, prompt, historyStr, countsStr)
 
 	var selected ScheduleID
 	_, err = fmt.Sscanf(resp, "%d", &selected)
-	if err != nil || selected < ScheduleKnowledge || selected > ScheduleProduction {
+	if err != nil || !IsKnownSchedule(selected) {
 		// Fallback to heuristic if parsing fails
 		return o.heuristicSelectSchedule(), nil
 	}
@@ -261,6 +296,14 @@ Next Schedule (1-5, or 0 to terminate):`, prompt, historyStr, countsStr)
 func (o *Orchestrator) DefaultSelectProcess(ctx context.Context, scheduleID ScheduleID, lastProcess ProcessID) (ProcessID, bool, error) {
 	o.mu.Lock()
 	client := o.ollamaClient
+	o.mu.Unlock()
+	return o.selectProcessWith(ctx, client, scheduleID, lastProcess)
+}
+
+// selectProcessWith selects the next process by asking client, or by the
+// heuristic when client is nil or its answer breaks the navigation rules.
+func (o *Orchestrator) selectProcessWith(ctx context.Context, client *ollama.Client, scheduleID ScheduleID, lastProcess ProcessID) (ProcessID, bool, error) {
+	o.mu.Lock()
 	counts := o.processCounts[scheduleID]
 	o.mu.Unlock()
 
@@ -301,7 +344,7 @@ Process Counts in this Schedule: %s
 
 Next Process (1-3, or 0 to terminate):`, ScheduleNames[scheduleID], lastProcess, countsStr)
 
-	resp, _, err := client.Generate(ctx, systemPrompt+"\n\n"+userPrompt)
+	resp, err := o.generate(ctx, client, systemPrompt+"\n\n"+userPrompt)
 	if err != nil {
 		return 0, false, fmt.Errorf("llm generation failed: %w", err)
 	}
@@ -352,7 +395,7 @@ func (o *Orchestrator) heuristicSelectSchedule() ScheduleID {
 	defer o.mu.Unlock()
 
 	// Ensure all run at least once
-	for id := ScheduleKnowledge; id <= ScheduleProduction; id++ {
+	for _, id := range ScheduleSelectionOrder() {
 		if o.scheduleCounts[id] == 0 {
 			return id
 		}
@@ -362,16 +405,51 @@ func (o *Orchestrator) heuristicSelectSchedule() ScheduleID {
 	return ScheduleProduction
 }
 
-// SelectSchedule selects the next schedule to execute
-// This is called by the orchestrator model to make scheduling decisions
-func (o *Orchestrator) SelectSchedule(scheduleID ScheduleID) error {
-	if err := o.ValidateScheduleSelection(scheduleID); err != nil {
-		return err
+// DecideRecovery asks the orchestrator model how to proceed after the watchdog
+// suspended a process. attempts is the number of suspensions already seen for
+// this process. Without a client, or if the response cannot be parsed, it
+// adjusts once and then skips.
+func (o *Orchestrator) DecideRecovery(ctx context.Context, report string, attempts int) RecoveryDecision {
+	o.mu.Lock()
+	client := o.ollamaClient
+	o.mu.Unlock()
+
+	fallback := RecoveryAdjust
+	if attempts > 1 {
+		fallback = RecoverySkip
 	}
 
-	o.mu.Lock()
+	if client == nil {
+		return fallback
+	}
+
+	prompt := fmt.Sprintf(`You are the orchestrator for obot. The watchdog suspended a process that exceeded its execution budget.
+
+%s
+
+Suspensions so far for this process: %d
+
+Options:
+retry: re-run the process unchanged with a fresh budget
+skip: treat the process as finished and move on
+adjust: re-run the process with this report as guidance to change strategy
+
+Respond ONLY with retry, skip, or adjust.`, report, attempts)
+
+	resp, err := o.generate(ctx, client, prompt)
+	if err != nil {
+		return fallback
+	}
+
+	decision, ok := ParseRecoveryDecision(resp)
+	if !ok {
+		return fallback
+	}
+	return decision
+}
 
-	// Initialize schedule
+// newSchedule initializes a schedule and its processes.
+func newSchedule(scheduleID ScheduleID) *Schedule {
 	schedule := &Schedule{
 		ID:        scheduleID,
 		Name:      ScheduleNames[scheduleID],
@@ -391,8 +469,19 @@ func (o *Orchestrator) SelectSchedule(scheduleID ScheduleID) error {
 			schedule.Processes[i-1].RequiresHumanConsultation = true
 		}
 	}
+	return schedule
+}
 
-	o.currentSchedule = schedule
+// SelectSchedule selects the next schedule to execute
+// This is called by the orchestrator model to make scheduling decisions
+func (o *Orchestrator) SelectSchedule(scheduleID ScheduleID) error {
+	if err := o.ValidateScheduleSelection(scheduleID); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+
+	o.currentSchedule = newSchedule(scheduleID)
 	o.scheduleHistory = append(o.scheduleHistory, scheduleID)
 	o.scheduleCounts[scheduleID]++
 	o.stats.TotalSchedulings++
@@ -412,12 +501,12 @@ func (o *Orchestrator) SelectSchedule(scheduleID ScheduleID) error {
 	// Reset last process for this schedule
 	o.lastProcessBySchedule[scheduleID] = 0
 
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	onScheduleStart := o.onScheduleStart
 	o.mu.Unlock()
 
 	for _, p := range plugins {
-		_ = p.OnScheduleStart(context.Background(), scheduleID)
+		_ = p.OnScheduleStart(pluginCtx, scheduleID)
 	}
 
 	if onScheduleStart != nil {
@@ -469,12 +558,12 @@ func (o *Orchestrator) SelectProcess(processID ProcessID) error {
 	// Update flow code
 	o.flowCode.AddProcess(processID)
 
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	onProcessStart := o.onProcessStart
 	o.mu.Unlock()
 
 	for _, p := range plugins {
-		_ = p.OnProcessStart(context.Background(), scheduleID, processID)
+		_ = p.OnProcessStart(pluginCtx, scheduleID, processID)
 	}
 
 	if onProcessStart != nil {
@@ -522,12 +611,12 @@ func (o *Orchestrator) TerminateProcess() error {
 	o.currentProcess.Terminated = true
 	o.lastProcessBySchedule[scheduleID] = processID
 
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	onProcessEnd := o.onProcessEnd
 	o.mu.Unlock()
 
 	for _, p := range plugins {
-		_ = p.OnProcessEnd(context.Background(), scheduleID, processID)
+		_ = p.OnProcessEnd(pluginCtx, scheduleID, processID)
 	}
 
 	if onProcessEnd != nil {
@@ -571,7 +660,7 @@ func (o *Orchestrator) TerminateSchedule() error {
 	o.currentSchedule.Terminated = true
 	o.currentSchedule.EndTime = time.Now()
 
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	onScheduleEnd := o.onScheduleEnd
 
 	o.currentSchedule = nil
@@ -579,7 +668,7 @@ func (o *Orchestrator) TerminateSchedule() error {
 	o.mu.Unlock()
 
 	for _, p := range plugins {
-		_ = p.OnScheduleEnd(context.Background(), scheduleID)
+		_ = p.OnScheduleEnd(pluginCtx, scheduleID)
 	}
 
 	if onScheduleEnd != nil {
@@ -589,13 +678,149 @@ func (o *Orchestrator) TerminateSchedule() error {
 	return nil
 }
 
+// Restore rebuilds schedule and process history from a persisted flow code
+// and notes, so that Run continues in the last schedule after its last
+// process instead of starting over. It must be called before Run.
+func (o *Orchestrator) Restore(flowCode string, notes []Note) error {
+	events, err := NewFlowCode().Parse(flowCode)
+	if err != nil {
+		return fmt.Errorf("invalid flow code: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.stats.TotalSchedulings > 0 {
+		return fmt.Errorf("cannot restore a running orchestrator")
+	}
+
+	var scheduleID ScheduleID
+	var processID ProcessID
+	for _, ev := range events {
+		switch ev.Type {
+		case EventSchedule:
+			if !IsKnownSchedule(ev.Schedule) {
+				return fmt.Errorf("unknown schedule S%d in flow code", ev.Schedule)
+			}
+			scheduleID, processID = ev.Schedule, 0
+			o.scheduleHistory = append(o.scheduleHistory, scheduleID)
+			o.scheduleCounts[scheduleID]++
+			o.stats.TotalSchedulings++
+			o.stats.SchedulingsByID[scheduleID]++
+			o.flowCode.AddSchedule(scheduleID)
+			if o.processCounts[scheduleID] == nil {
+				o.processCounts[scheduleID] = make(map[ProcessID]int)
+			}
+			if o.stats.ProcessesBySchedule[scheduleID] == nil {
+				o.stats.ProcessesBySchedule[scheduleID] = make(map[ProcessID]int)
+			}
+			o.lastProcessBySchedule[scheduleID] = 0
+		case EventProcess:
+			if scheduleID == 0 {
+				return fmt.Errorf("process P%d before any schedule in flow code", ev.Process)
+			}
+			processID = ev.Process
+			o.processCounts[scheduleID][processID]++
+			o.stats.TotalProcesses++
+			o.stats.ProcessesBySchedule[scheduleID][processID]++
+			o.flowCode.AddProcess(processID)
+			o.lastProcessBySchedule[scheduleID] = processID
+			o.processHistory = append(o.processHistory, ProcessExecution{Schedule: scheduleID, Process: processID})
+		case EventError:
+			o.flowCode.MarkError()
+		}
+	}
+
+	for _, n := range notes {
+		n.Reviewed = true
+		o.sessionNotes = append(o.sessionNotes, n)
+	}
+
+	o.resumeSchedule = scheduleID
+	o.resumeProcess = processID
+	return nil
+}
+
+// takeResumePoint returns and clears the position restored by Restore.
+func (o *Orchestrator) takeResumePoint() (ScheduleID, ProcessID, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	scheduleID, processID := o.resumeSchedule, o.resumeProcess
+	o.resumeSchedule, o.resumeProcess = 0, 0
+	if scheduleID == 0 {
+		return 0, 0, false
+	}
+	o.currentSchedule = newSchedule(scheduleID)
+	return scheduleID, processID, true
+}
+
+// RequestScheduleRevisit interrupts the current schedule once the running
+// process terminates and makes scheduleID the next schedule, bypassing
+// schedule selection. It is used to force a Plan revisit when the agent is stuck.
+func (o *Orchestrator) RequestScheduleRevisit(scheduleID ScheduleID, reason string) error {
+	if !IsKnownSchedule(scheduleID) {
+		return fmt.Errorf("invalid schedule ID: %d", scheduleID)
+	}
+	o.mu.Lock()
+	o.pendingRevisit = scheduleID
+	o.mu.Unlock()
+
+	o.AddNote(fmt.Sprintf("Forced %s schedule revisit: %s", ScheduleNames[scheduleID], reason), "system")
+	return nil
+}
+
+// PendingRevisit returns the schedule requested by RequestScheduleRevisit, if any.
+func (o *Orchestrator) PendingRevisit() (ScheduleID, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.pendingRevisit, o.pendingRevisit != 0
+}
+
+// takePendingRevisit returns and clears the pending revisit.
+func (o *Orchestrator) takePendingRevisit() (ScheduleID, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	id := o.pendingRevisit
+	o.pendingRevisit = 0
+	return id, id != 0
+}
+
+// interruptSchedule ends the current schedule early without the P3
+// requirement enforced by TerminateSchedule.
+func (o *Orchestrator) interruptSchedule() {
+	o.mu.Lock()
+	if o.currentSchedule == nil {
+		o.mu.Unlock()
+		return
+	}
+
+	scheduleID := o.currentSchedule.ID
+	o.currentSchedule.Terminated = true
+	o.currentSchedule.EndTime = time.Now()
+
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
+	onScheduleEnd := o.onScheduleEnd
+
+	o.currentSchedule = nil
+	o.currentProcess = nil
+	o.mu.Unlock()
+
+	for _, p := range plugins {
+		_ = p.OnScheduleEnd(pluginCtx, scheduleID)
+	}
+
+	if onScheduleEnd != nil {
+		go onScheduleEnd(scheduleID)
+	}
+}
+
 // CanTerminatePrompt checks if the prompt can be terminated
 // Prerequisites: All 5 schedules run at least once, Production was last
 func (o *Orchestrator) CanTerminatePrompt() bool {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	// All 5 schedules must have run at least once
+	// All 5 core schedules must have run at least once; custom schedules are optional
 	for id := ScheduleKnowledge; id <= ScheduleProduction; id++ {
 		if o.scheduleCounts[id] < 1 {
 			return false
@@ -634,7 +859,7 @@ func (o *Orchestrator) GetScheduleSelectionContext() map[string]interface{} {
 		"history":   o.scheduleHistory,
 		"counts":    o.scheduleCounts,
 		"notes":     o.sessionNotes,
-		"available": []ScheduleID{1, 2, 3, 4, 5},
+		"available": AllSchedules(),
 	}
 }
 
@@ -643,7 +868,7 @@ func (o *Orchestrator) ValidateScheduleSelection(id ScheduleID) error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	if id < ScheduleKnowledge || id > ScheduleProduction {
+	if !IsKnownSchedule(id) {
 		return fmt.Errorf("invalid schedule ID: %d", id)
 	}
 
@@ -657,13 +882,13 @@ func (o *Orchestrator) ValidateScheduleSelection(id ScheduleID) error {
 func (o *Orchestrator) MarkError() {
 	o.mu.Lock()
 	o.flowCode.MarkError()
-	plugins := o.plugins
+	plugins, pluginCtx := o.plugins, o.pluginCtxLocked()
 	onError := o.onError
 	o.mu.Unlock()
 
 	err := fmt.Errorf("orchestration error")
 	for _, p := range plugins {
-		p.OnError(context.Background(), err)
+		p.OnError(pluginCtx, err)
 	}
 	if onError != nil {
 		onError(err)
@@ -677,6 +902,30 @@ func (o *Orchestrator) GetFlowCode() string {
 	return o.flowCode.String()
 }
 
+// DecisionState identifies the state that schedule and process decisions
+// are made from: the flow code, the schedule and process counts, and the
+// session notes. It changes whenever any of them does, such as when a
+// process adds a note or fails.
+func (o *Orchestrator) DecisionState() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\n", o.flowCode.String())
+	for _, id := range o.scheduleHistory {
+		fmt.Fprintf(h, "S%d ", id)
+	}
+	for _, id := range AllSchedules() {
+		fmt.Fprintf(h, "\n%d:%d", id, o.scheduleCounts[id])
+		for p := Process1; p <= Process3; p++ {
+			fmt.Fprintf(h, " %d", o.processCounts[id][p])
+		}
+	}
+	for _, n := range o.sessionNotes {
+		fmt.Fprintf(h, "\n%s %s %s %q", n.ID, n.Type, n.Source, n.Content)
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
 // GetStats returns the orchestrator statistics
 func (o *Orchestrator) GetStats() *OrchestratorStats {
 	o.mu.Lock()
@@ -717,11 +966,29 @@ func (o *Orchestrator) AddNote(content, source string) {
 		Timestamp: time.Now(),
 		Content:   content,
 		Source:    source,
+		Type:      NoteGeneral,
 		Reviewed:  false,
 	}
 	o.sessionNotes = append(o.sessionNotes, note)
 }
 
+// AddSourcedNote adds a discovery note about retrieved information, recording
+// where it came from so that claims based on it can be cited.
+func (o *Orchestrator) AddSourcedNote(content, source string, prov Provenance) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	note := Note{
+		ID:         fmt.Sprintf("N%d", len(o.sessionNotes)+1),
+		Timestamp:  time.Now(),
+		Content:    content,
+		Source:     source,
+		Type:       NoteDiscovery,
+		Provenance: &prov,
+	}
+	o.sessionNotes = append(o.sessionNotes, note)
+}
+
 // GetUnreviewedNotes returns unreviewed notes
 func (o *Orchestrator) GetUnreviewedNotes() []Note {
 	o.mu.Lock()
@@ -736,6 +1003,27 @@ func (o *Orchestrator) GetUnreviewedNotes() []Note {
 	return unreviewed
 }
 
+// GetNotes returns the session notes matching every filter, or all notes
+// when no filter is given
+func (o *Orchestrator) GetNotes(filters ...NoteFilter) []Note {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	notes := make([]Note, 0, len(o.sessionNotes))
+	for _, n := range o.sessionNotes {
+		matched := true
+		for _, f := range filters {
+			if !f.Matches(n) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			notes = append(notes, n)
+		}
+	}
+	return notes
+}
+
 // MarkNotesReviewed marks all notes as reviewed
 func (o *Orchestrator) MarkNotesReviewed() {
 	o.mu.Lock()
@@ -780,8 +1068,137 @@ func (o *Orchestrator) SetCallbacks(
 	o.onError = onError
 }
 
+// SetTokenCallback sets the callback receiving the tokens of each request
+// the orchestrator model answers, such as to charge them to its quota.
+func (o *Orchestrator) SetTokenCallback(fn func(client *ollama.Client, tokens int64)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.onTokens = fn
+}
+
+// generate asks client for a decision and reports the tokens it used.
+func (o *Orchestrator) generate(ctx context.Context, client *ollama.Client, prompt string) (string, error) {
+	resp, stats, err := client.Generate(ctx, prompt)
+	o.mu.Lock()
+	onTokens := o.onTokens
+	o.mu.Unlock()
+	if stats != nil && onTokens != nil {
+		onTokens(client, int64(stats.TotalTokens))
+	}
+	return resp, err
+}
+
+// SetErrorHandler sets the handler consulted when a process fails. Without a
+// handler, Run returns the first process error.
+func (o *Orchestrator) SetErrorHandler(h ErrorHandler) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errorHandler = h
+}
+
+// SetTerminationGate sets the gate consulted each time the prompt would
+// terminate. Each block forces another Implement/Production cycle, up to
+// maxCycles (0 or less uses DefaultMaxGateCycles); after that the prompt
+// terminates with a risk note. A nil gate lets every prompt terminate.
+func (o *Orchestrator) SetTerminationGate(gate TerminationGate, maxCycles int) {
+	if maxCycles <= 0 {
+		maxCycles = DefaultMaxGateCycles
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.terminationGate = gate
+	o.maxGateCycles = maxCycles
+}
+
+// GateCycles returns how many extra cycles the termination gate forced.
+func (o *Orchestrator) GateCycles() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.gateCycles
+}
+
+// checkTerminationGate consults the termination gate and reports whether it
+// blocks termination. A blocked prompt gets the gate's notes as todos and an
+// Implement revisit. A gate that fails lets the prompt terminate, unless the
+// context is done.
+func (o *Orchestrator) checkTerminationGate(ctx context.Context) (bool, error) {
+	o.mu.Lock()
+	gate, cycles, maxCycles := o.terminationGate, o.gateCycles, o.maxGateCycles
+	o.mu.Unlock()
+	if gate == nil {
+		return false, nil
+	}
+
+	pass, notes, err := gate(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		o.AddNote(fmt.Sprintf("Quality gate skipped: %v", err), "system")
+		return false, nil
+	}
+	if pass {
+		return false, nil
+	}
+	if cycles >= maxCycles {
+		o.AddTypedNote(NoteRisk, fmt.Sprintf("Quality gate still failing after %d extra cycles; terminating anyway", cycles), "quality gate")
+		return false, nil
+	}
+
+	o.mu.Lock()
+	o.gateCycles++
+	o.mu.Unlock()
+	for _, note := range notes {
+		o.AddTypedNote(NoteTodo, note, "quality gate")
+	}
+	if err := o.RequestScheduleRevisit(ScheduleImplement, "quality gate blocked termination"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// executeWithRecovery executes a process, suspending on failure and letting the
+// error handler choose to retry, skip, or abort.
+func (o *Orchestrator) executeWithRecovery(ctx context.Context, executeProcessFn func(context.Context, ScheduleID, ProcessID) error, scheduleID ScheduleID, processID ProcessID) error {
+	for {
+		err := executeProcessFn(ctx, scheduleID, processID)
+		if err == nil {
+			return nil
+		}
+		o.MarkError()
+		slog.ErrorContext(ctx, "process failed", "error", err)
+
+		o.mu.Lock()
+		handler := o.errorHandler
+		o.mu.Unlock()
+		if handler == nil || ctx.Err() != nil {
+			return err
+		}
+
+		o.SetState(StateSuspended)
+		resolution := handler(ctx, err, scheduleID, processID)
+		processName := ProcessNames[scheduleID][processID]
+		slog.InfoContext(ctx, "suspension resolved", "resolution", resolution)
+
+		switch resolution {
+		case ResolutionRetry:
+			o.AddNote(fmt.Sprintf("Retrying %s after error: %v", processName, err), "system")
+			o.SetState(StateActive)
+		case ResolutionSkip:
+			o.AddNote(fmt.Sprintf("Skipped %s after error: %v", processName, err), "system")
+			o.SetState(StateActive)
+			return nil
+		default:
+			return fmt.Errorf("%w: %v", ErrAborted, err)
+		}
+	}
+}
+
 // Run executes the main orchestration loop
 func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Context) (ScheduleID, error), selectProcessFn func(context.Context, ScheduleID, ProcessID) (ProcessID, bool, error), executeProcessFn func(context.Context, ScheduleID, ProcessID) error) error {
+	o.mu.Lock()
+	o.runCtx = ctx
+	o.mu.Unlock()
 	o.SetState(StateBegin)
 
 	// Run pre-orchestration planning
@@ -791,7 +1208,7 @@ func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Co
 			// Feed subtasks into session notes for Knowledge/Plan schedules to use
 			for i, st := range plan.Sequence {
 				risk := plan.Risks[i]
-				o.AddNote(fmt.Sprintf("Subtask [%s] (Risk: %s): %s", st.ID, risk, st.Description), "planner")
+				o.AddTypedNote(NoteTodo, fmt.Sprintf("Subtask [%s] (Risk: %s): %s", st.ID, risk, st.Description), "planner", st.ID)
 			}
 		}
 	}
@@ -809,37 +1226,56 @@ func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Co
 			o.SetState(StateSelecting)
 		}
 
-		// Select schedule
-		o.SetState(StateSelecting)
-		scheduleID, err := selectScheduleFn(ctx)
-		if err != nil {
-			o.MarkError()
-			if o.onError != nil {
-				o.onError(err)
+		// A restored session continues its interrupted schedule
+		scheduleID, lastProcess, resumed := o.takeResumePoint()
+		if !resumed {
+			// Select schedule (a forced revisit bypasses selection)
+			o.SetState(StateSelecting)
+			var revisit bool
+			var err error
+			scheduleID, revisit = o.takePendingRevisit()
+			if !revisit {
+				scheduleID, err = selectScheduleFn(ctx)
+			}
+			if err != nil {
+				o.MarkError()
+				if o.onError != nil {
+					o.onError(err)
+				}
+				return err
 			}
-			return err
-		}
 
-		// Check for prompt termination signal (scheduleID == 0)
-		if scheduleID == 0 {
-			if o.CanTerminatePrompt() {
-				return o.TerminatePrompt()
+			// Check for prompt termination signal (scheduleID == 0)
+			if scheduleID == 0 {
+				if o.CanTerminatePrompt() {
+					blocked, err := o.checkTerminationGate(ctx)
+					if err != nil {
+						return err
+					}
+					if blocked {
+						slog.InfoContext(ctx, "termination blocked by quality gate", "cycle", o.GateCycles())
+						continue
+					}
+					slog.InfoContext(ctx, "prompt terminated", "flow_code", o.GetFlowCode())
+					return o.TerminatePrompt()
+				}
+				return fmt.Errorf("cannot terminate prompt: prerequisites not met")
 			}
-			return fmt.Errorf("cannot terminate prompt: prerequisites not met")
-		}
 
-		if err := o.SelectSchedule(scheduleID); err != nil {
-			o.MarkError()
-			return err
+			if err := o.SelectSchedule(scheduleID); err != nil {
+				o.MarkError()
+				return err
+			}
 		}
 
 		// Run schedule until termination
 		o.SetState(StateActive)
-		lastProcess := ProcessID(0)
+		scheduleCtx := logging.WithAttrs(ctx, "schedule", ScheduleNames[scheduleID])
+		slog.InfoContext(scheduleCtx, "schedule started", "resumed", resumed)
 
 		for {
 			// Select next process
-			processID, terminate, err := selectProcessFn(ctx, scheduleID, lastProcess)
+			processID, terminate, err := selectProcessFn(scheduleCtx, scheduleID, lastProcess)
 			if err != nil {
 				o.MarkError()
 				return err
@@ -850,6 +1286,7 @@ func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Co
 					o.MarkError()
 					return err
 				}
+				slog.InfoContext(scheduleCtx, "schedule terminated")
 				break
 			}
 
@@ -858,11 +1295,15 @@ func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Co
 				return err
 			}
 
-			// Execute process
-			if err := executeProcessFn(ctx, scheduleID, processID); err != nil {
-				o.MarkError()
+			// Execute process, suspending on error
+			processCtx := logging.WithAttrs(scheduleCtx, "process", ProcessNames[scheduleID][processID])
+			slog.InfoContext(processCtx, "process started")
+			start := time.Now()
+			if err := o.executeWithRecovery(processCtx, executeProcessFn, scheduleID, processID); err != nil {
+				slog.ErrorContext(processCtx, "process aborted", "error", err)
 				return err
 			}
+			slog.InfoContext(processCtx, "process completed", "duration", time.Since(start))
 
 			// Complete and terminate process
 			if err := o.CompleteProcess(); err != nil {
@@ -878,6 +1319,12 @@ func (o *Orchestrator) Run(ctx context.Context, selectScheduleFn func(context.Co
 			// Review notes after each process termination
 			o.MarkNotesReviewed()
 
+			// A forced revisit interrupts the schedule
+			if _, ok := o.PendingRevisit(); ok {
+				o.interruptSchedule()
+				break
+			}
+
 			lastProcess = processID
 		}
 	}
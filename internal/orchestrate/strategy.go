@@ -0,0 +1,295 @@
+package orchestrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// Strategy decides which schedule and process run next. A terminate result
+// ends the prompt, from SelectSchedule, or the schedule, from SelectProcess.
+type Strategy interface {
+	Name() string
+	SelectSchedule(ctx context.Context, o *Orchestrator) (next ScheduleID, terminate bool, err error)
+	SelectProcess(ctx context.Context, o *Orchestrator, schedID ScheduleID, last ProcessID) (next ProcessID, terminate bool, err error)
+}
+
+// Strategy names, as given to NewStrategy
+const (
+	StrategyHeuristic = "heuristic"
+	StrategyLLM       = "llm"
+	StrategyHybrid    = "hybrid"
+	StrategyScript    = "script"
+)
+
+// NewStrategy returns the strategy called name. The llm and hybrid
+// strategies ask client, the orchestrator model; the script strategy runs
+// script.
+func NewStrategy(name string, client *ollama.Client, script string) (Strategy, error) {
+	switch name {
+	case "", StrategyHeuristic:
+		return HeuristicStrategy{}, nil
+	case StrategyLLM, StrategyHybrid:
+		if client == nil {
+			return nil, fmt.Errorf("%s strategy: no orchestrator model", name)
+		}
+		if name == StrategyLLM {
+			return &LLMStrategy{Client: client}, nil
+		}
+		return &HybridStrategy{LLM: LLMStrategy{Client: client}}, nil
+	case StrategyScript:
+		if strings.TrimSpace(script) == "" {
+			return nil, fmt.Errorf("script strategy: no script given")
+		}
+		return &ScriptStrategy{Command: script, Timeout: DefaultScriptTimeout}, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q: want %s, %s, %s, or %s", name, StrategyHeuristic, StrategyLLM, StrategyHybrid, StrategyScript)
+}
+
+// HeuristicStrategy decides deterministically: each schedule once in
+// selection order, Production last, then terminate; within a schedule P1,
+// P2, P3, then terminate.
+type HeuristicStrategy struct{}
+
+func (HeuristicStrategy) Name() string { return StrategyHeuristic }
+
+func (HeuristicStrategy) SelectSchedule(ctx context.Context, o *Orchestrator) (ScheduleID, bool, error) {
+	if o.CanTerminatePrompt() {
+		return 0, true, nil
+	}
+	return o.heuristicSelectSchedule(), false, nil
+}
+
+func (HeuristicStrategy) SelectProcess(ctx context.Context, o *Orchestrator, schedID ScheduleID, last ProcessID) (ProcessID, bool, error) {
+	next, terminate := o.heuristicSelectProcess(schedID, last)
+	return next, terminate, nil
+}
+
+// LLMStrategy has the orchestrator model make every decision.
+type LLMStrategy struct {
+	Client *ollama.Client
+}
+
+func (s *LLMStrategy) Name() string { return StrategyLLM }
+
+func (s *LLMStrategy) SelectSchedule(ctx context.Context, o *Orchestrator) (ScheduleID, bool, error) {
+	next, err := o.selectScheduleWith(ctx, s.Client)
+	if err != nil {
+		return 0, false, err
+	}
+	return next, next == 0, nil
+}
+
+func (s *LLMStrategy) SelectProcess(ctx context.Context, o *Orchestrator, schedID ScheduleID, last ProcessID) (ProcessID, bool, error) {
+	if last == 0 {
+		return Process1, false, nil
+	}
+	return o.selectProcessWith(ctx, s.Client, schedID, last)
+}
+
+// Guardrails of HybridStrategy
+const (
+	// HybridMaxScheduleRuns is how many times one schedule may run.
+	HybridMaxScheduleRuns = 3
+	// HybridMaxProcessRuns is how many times a process may run per run of
+	// its schedule.
+	HybridMaxProcessRuns = 3
+)
+
+// HybridStrategy has the orchestrator model decide, within guardrails: a
+// decision that fails, ends the prompt before it may end, or repeats a
+// schedule or process too often is replaced by the heuristic's.
+type HybridStrategy struct {
+	LLM       LLMStrategy
+	Heuristic HeuristicStrategy
+}
+
+func (s *HybridStrategy) Name() string { return StrategyHybrid }
+
+func (s *HybridStrategy) SelectSchedule(ctx context.Context, o *Orchestrator) (ScheduleID, bool, error) {
+	next, terminate, err := s.LLM.SelectSchedule(ctx, o)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "strategy fell back to the heuristic", "decision", "schedule", "error", err)
+	case terminate && !o.CanTerminatePrompt():
+		slog.InfoContext(ctx, "strategy overruled early termination")
+	case !terminate && o.scheduleRuns(next) >= HybridMaxScheduleRuns:
+		slog.InfoContext(ctx, "strategy overruled a repeated schedule", "schedule", ScheduleNames[next])
+	default:
+		return next, terminate, nil
+	}
+	return s.Heuristic.SelectSchedule(ctx, o)
+}
+
+func (s *HybridStrategy) SelectProcess(ctx context.Context, o *Orchestrator, schedID ScheduleID, last ProcessID) (ProcessID, bool, error) {
+	next, terminate, err := s.LLM.SelectProcess(ctx, o, schedID, last)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "strategy fell back to the heuristic", "decision", "process", "error", err)
+	case !terminate && o.processRuns(schedID, next) >= HybridMaxProcessRuns*max(o.scheduleRuns(schedID), 1):
+		slog.InfoContext(ctx, "strategy overruled a repeated process", "process", ProcessNames[schedID][next])
+	default:
+		return next, terminate, nil
+	}
+	return s.Heuristic.SelectProcess(ctx, o, schedID, last)
+}
+
+// scheduleRuns returns how many times a schedule has been selected.
+func (o *Orchestrator) scheduleRuns(id ScheduleID) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.scheduleCounts[id]
+}
+
+// processRuns returns how many times a process has run over all runs of
+// its schedule.
+func (o *Orchestrator) processRuns(schedID ScheduleID, id ProcessID) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.processCounts[schedID][id]
+}
+
+// DefaultScriptTimeout bounds one decision of a script strategy.
+const DefaultScriptTimeout = 30 * time.Second
+
+// DecisionContext is what a script strategy is given, as JSON on its
+// standard input, to decide on.
+type DecisionContext struct {
+	Decision        string         `json:"decision"` // "schedule" or "process"
+	Prompt          string         `json:"prompt"`
+	FlowCode        string         `json:"flow_code"`
+	ScheduleHistory []string       `json:"schedule_history"`
+	ScheduleCounts  map[string]int `json:"schedule_counts"`
+	CanTerminate    bool           `json:"can_terminate"` // whether 0 is an option
+	Schedule        string         `json:"schedule,omitempty"`
+	LastProcess     ProcessID      `json:"last_process,omitempty"`
+	ProcessCounts   map[string]int `json:"process_counts,omitempty"`
+	// Options are the schedule or process numbers the decision may be,
+	// with 0 to terminate when it may.
+	Options []int `json:"options"`
+}
+
+// Decision is what a script strategy prints: the next schedule or process
+// number, 0 to terminate.
+type Decision struct {
+	Next   int    `json:"next"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ScriptStrategy runs a program for every decision. It runs Command with
+// the shell, writes a DecisionContext to its standard input, and reads a
+// Decision from its standard output.
+type ScriptStrategy struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (s *ScriptStrategy) Name() string { return StrategyScript }
+
+func (s *ScriptStrategy) SelectSchedule(ctx context.Context, o *Orchestrator) (ScheduleID, bool, error) {
+	dc := o.decisionContext("schedule")
+	for _, id := range AllSchedules() {
+		dc.Options = append(dc.Options, int(id))
+	}
+	next, err := s.decide(ctx, dc)
+	if err != nil {
+		return 0, false, err
+	}
+	return ScheduleID(next), next == 0, nil
+}
+
+func (s *ScriptStrategy) SelectProcess(ctx context.Context, o *Orchestrator, schedID ScheduleID, last ProcessID) (ProcessID, bool, error) {
+	if last == 0 {
+		return Process1, false, nil
+	}
+	dc := o.decisionContext("process")
+	dc.Schedule = ScheduleNames[schedID]
+	dc.LastProcess = last
+	dc.CanTerminate = NavigationRules[last].CanTerminate
+	dc.ProcessCounts = make(map[string]int)
+	o.mu.Lock()
+	for p, n := range o.processCounts[schedID] {
+		dc.ProcessCounts[p.String()] = n
+	}
+	o.mu.Unlock()
+	for _, p := range NavigationRules[last].AllowedTo {
+		dc.Options = append(dc.Options, int(p))
+	}
+	next, err := s.decide(ctx, dc)
+	if err != nil {
+		return 0, false, err
+	}
+	return ProcessID(next), next == 0, nil
+}
+
+// decisionContext returns the context of a decision common to schedules
+// and processes, without options.
+func (o *Orchestrator) decisionContext(decision string) DecisionContext {
+	dc := DecisionContext{
+		Decision:       decision,
+		Prompt:         o.GetPrompt(),
+		FlowCode:       o.GetFlowCode(),
+		ScheduleCounts: make(map[string]int),
+		CanTerminate:   o.CanTerminatePrompt(),
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, id := range o.scheduleHistory {
+		dc.ScheduleHistory = append(dc.ScheduleHistory, ScheduleNames[id])
+	}
+	for id, n := range o.scheduleCounts {
+		dc.ScheduleCounts[ScheduleNames[id]] = n
+	}
+	return dc
+}
+
+// decide runs the script on dc and returns its decision, which must be one
+// of dc's options.
+func (s *ScriptStrategy) decide(ctx context.Context, dc DecisionContext) (int, error) {
+	input, err := json.Marshal(dc)
+	if err != nil {
+		return 0, err
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScriptTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return 0, fmt.Errorf("strategy script: %w: %s", err, msg)
+		}
+		return 0, fmt.Errorf("strategy script: %w", err)
+	}
+
+	var d Decision
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &d); err != nil {
+		return 0, fmt.Errorf("strategy script printed %q, want {\"next\": N}: %w", strings.TrimSpace(stdout.String()), err)
+	}
+	if d.Next == 0 && dc.CanTerminate {
+		return 0, nil
+	}
+	for _, opt := range dc.Options {
+		if d.Next == opt {
+			if d.Reason != "" {
+				slog.InfoContext(ctx, "strategy script decided", "decision", dc.Decision, "next", d.Next, "reason", d.Reason)
+			}
+			return d.Next, nil
+		}
+	}
+	return 0, fmt.Errorf("strategy script chose %s %d, want one of %v", dc.Decision, d.Next, dc.Options)
+}
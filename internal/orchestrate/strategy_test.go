@@ -0,0 +1,150 @@
+package orchestrate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// answering returns a client whose model answers every request with resp.
+func answering(t *testing.T, resp string) *ollama.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: resp, Done: true})
+	}))
+	t.Cleanup(srv.Close)
+	return ollama.NewClient(ollama.WithBaseURL(srv.URL))
+}
+
+func TestHeuristicStrategy(t *testing.T) {
+	ctx := context.Background()
+	o := NewOrchestrator()
+	var s HeuristicStrategy
+
+	var seen []ScheduleID
+	for {
+		next, terminate, err := s.SelectSchedule(ctx, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if terminate {
+			break
+		}
+		if len(seen) == 10 {
+			t.Fatalf("no termination after %v", seen)
+		}
+		seen = append(seen, next)
+		if err := o.SelectSchedule(next); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []ScheduleID{ScheduleKnowledge, SchedulePlan, ScheduleImplement, ScheduleScale, ScheduleProduction}
+	if len(seen) != len(want) {
+		t.Fatalf("schedules = %v, want %v", seen, want)
+	}
+
+	var procs []ProcessID
+	for last := ProcessID(0); ; {
+		next, terminate, _ := s.SelectProcess(ctx, o, ScheduleImplement, last)
+		if terminate {
+			break
+		}
+		procs = append(procs, next)
+		last = next
+	}
+	if len(procs) != 3 || procs[0] != Process1 || procs[2] != Process3 {
+		t.Errorf("processes = %v, want P1 P2 P3", procs)
+	}
+}
+
+func TestHybridStrategy_Guardrails(t *testing.T) {
+	ctx := context.Background()
+	o := NewOrchestrator()
+	if err := o.SelectSchedule(ScheduleKnowledge); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed request falls back to the heuristic
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	s := &HybridStrategy{LLM: LLMStrategy{Client: ollama.NewClient(ollama.WithBaseURL(down.URL))}}
+	next, terminate, err := s.SelectSchedule(ctx, o)
+	if err != nil || terminate || next != SchedulePlan {
+		t.Errorf("SelectSchedule() = %v, %v, %v; want Plan", next, terminate, err)
+	}
+
+	// A schedule run too often is replaced
+	s = &HybridStrategy{LLM: LLMStrategy{Client: answering(t, "1")}}
+	for range HybridMaxScheduleRuns - 1 {
+		if err := o.SelectSchedule(ScheduleKnowledge); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next, _, _ := s.SelectSchedule(ctx, o); next != SchedulePlan {
+		t.Errorf("SelectSchedule() = %v after %d Knowledge runs, want Plan", next, HybridMaxScheduleRuns)
+	}
+
+	// The model's decision stands within the guardrails
+	s = &HybridStrategy{LLM: LLMStrategy{Client: answering(t, "2")}}
+	if next, terminate, _ := s.SelectProcess(ctx, o, ScheduleKnowledge, Process2); next != Process2 || terminate {
+		t.Errorf("SelectProcess() = %v, %v; want P2", next, terminate)
+	}
+}
+
+func TestScriptStrategy(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.json")
+	script := filepath.Join(dir, "decide.sh")
+	body := "#!/bin/sh\ncat > " + input + "\necho '{\"next\": 3, \"reason\": \"skip ahead\"}'\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewOrchestrator()
+	o.SetPrompt("add a cache")
+	if err := o.SelectSchedule(ScheduleKnowledge); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStrategy(StrategyScript, nil, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, terminate, err := s.SelectSchedule(ctx, o)
+	if err != nil || terminate || next != ScheduleImplement {
+		t.Fatalf("SelectSchedule() = %v, %v, %v; want Implement", next, terminate, err)
+	}
+	var dc DecisionContext
+	data, _ := os.ReadFile(input)
+	if err := json.Unmarshal(data, &dc); err != nil {
+		t.Fatal(err)
+	}
+	if dc.Decision != "schedule" || dc.Prompt != "add a cache" || len(dc.ScheduleHistory) != 1 || dc.ScheduleCounts["Knowledge"] != 1 || dc.CanTerminate {
+		t.Errorf("context = %+v", dc)
+	}
+
+	// P3 may not follow P1
+	_, _, err = s.SelectProcess(ctx, o, ScheduleKnowledge, Process1)
+	if err == nil || !strings.Contains(err.Error(), "want one of [1 2]") {
+		t.Errorf("SelectProcess() error = %v, want the options", err)
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	if s, err := NewStrategy("", nil, ""); err != nil || s.Name() != StrategyHeuristic {
+		t.Errorf("NewStrategy(\"\") = %v, %v; want heuristic", s, err)
+	}
+	for _, name := range []string{StrategyLLM, StrategyScript, "random"} {
+		if _, err := NewStrategy(name, nil, ""); err == nil {
+			t.Errorf("NewStrategy(%q) without a model or script succeeded", name)
+		}
+	}
+}
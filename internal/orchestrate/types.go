@@ -4,11 +4,14 @@
 package orchestrate
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
-// ScheduleID identifies one of the 5 schedules
+// ScheduleID identifies one of the 5 core schedules or a registered custom schedule
 type ScheduleID int
 
 // String returns the display name of the schedule
@@ -163,6 +166,65 @@ func (c ConsultationType) String() string {
 	return string(c)
 }
 
+// RecoveryDecision is the choice made after a process is suspended by the watchdog
+type RecoveryDecision string
+
+const (
+	// RecoveryRetry re-runs the suspended process with a fresh budget
+	RecoveryRetry RecoveryDecision = "retry"
+	// RecoverySkip treats the suspended process as finished and moves on
+	RecoverySkip RecoveryDecision = "skip"
+	// RecoveryAdjust re-runs the process with the suspension report fed back as guidance
+	RecoveryAdjust RecoveryDecision = "adjust"
+)
+
+// String returns the string representation of the recovery decision
+func (d RecoveryDecision) String() string {
+	return string(d)
+}
+
+// ParseRecoveryDecision parses a recovery decision from user or model input.
+// It accepts the full name or its first letter, case-insensitively.
+func ParseRecoveryDecision(s string) (RecoveryDecision, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "r", "retry":
+		return RecoveryRetry, true
+	case "s", "skip":
+		return RecoverySkip, true
+	case "a", "adjust":
+		return RecoveryAdjust, true
+	default:
+		return "", false
+	}
+}
+
+// ErrorResolution is how a suspended orchestration resumes after a process error
+type ErrorResolution string
+
+const (
+	// ResolutionRetry re-executes the failed process
+	ResolutionRetry ErrorResolution = "retry"
+	// ResolutionSkip treats the failed process as done and advances to the next valid state
+	ResolutionSkip ErrorResolution = "skip"
+	// ResolutionAbort stops the orchestration with ErrAborted
+	ResolutionAbort ErrorResolution = "abort"
+)
+
+// ErrAborted is returned by Run when a suspension is resolved with ResolutionAbort
+var ErrAborted = errors.New("orchestration aborted")
+
+// ErrorHandler resolves a process error while the orchestrator is suspended
+type ErrorHandler func(ctx context.Context, err error, scheduleID ScheduleID, processID ProcessID) ErrorResolution
+
+// TerminationGate decides whether a prompt that meets the termination
+// prerequisites may terminate. A blocked prompt receives the returned notes
+// as todos and runs another Implement/Production cycle.
+type TerminationGate func(ctx context.Context) (pass bool, notes []string, err error)
+
+// DefaultMaxGateCycles is how many extra Implement/Production cycles a
+// termination gate may force before the prompt terminates regardless.
+const DefaultMaxGateCycles = 3
+
 // Schedule represents a schedule instance
 type Schedule struct {
 	ID         ScheduleID
@@ -221,11 +283,37 @@ type ProcessExecution struct {
 
 // Note represents a session note
 type Note struct {
-	ID        string
-	Timestamp time.Time
-	Content   string
-	Source    string // "user", "ai-substitute", "system"
-	Reviewed  bool
+	ID         string
+	Timestamp  time.Time
+	Content    string
+	Source     string // "user", "ai-substitute", "system"
+	Reviewed   bool
+	Type       NoteType
+	Tags       []string
+	Provenance *Provenance // where retrieved information came from, if any
+}
+
+// Provenance records where the information in a note was retrieved from.
+type Provenance struct {
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// String returns the URL, or the path qualified by its commit.
+func (p Provenance) String() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	ref := p.Path
+	if p.Commit != "" {
+		commit := p.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		ref += "@" + commit
+	}
+	return ref
 }
 
 // OrchestratorStats tracks orchestration statistics
@@ -340,6 +428,9 @@ func IsValidNavigation(from, to ProcessID) bool {
 
 // GetScheduleModel returns the model type for a given schedule
 func GetScheduleModel(scheduleID ScheduleID) ModelType {
+	if def, ok := GetScheduleDefinition(scheduleID); ok {
+		return def.Model
+	}
 	switch scheduleID {
 	case ScheduleKnowledge:
 		return ModelResearcher
@@ -352,6 +443,12 @@ func GetScheduleModel(scheduleID ScheduleID) ModelType {
 
 // GetProcessConsultationType returns the consultation type for a process
 func GetProcessConsultationType(scheduleID ScheduleID, processID ProcessID) ConsultationType {
+	if def, ok := GetScheduleDefinition(scheduleID); ok {
+		if ct, ok := def.Consultation[processID]; ok {
+			return ct
+		}
+		return ConsultationNone
+	}
 	// Clarify (Plan schedule, Process 2) - Optional
 	if scheduleID == SchedulePlan && processID == Process2 {
 		return ConsultationOptional
@@ -1,6 +1,9 @@
 package orchestrate
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -101,3 +104,343 @@ func TestDuration(t *testing.T) {
 		t.Errorf("Process.Duration() = %v, want %v", p.Duration(), end.Sub(start))
 	}
 }
+
+func TestRegisterSchedule(t *testing.T) {
+	defer ClearCustomSchedules()
+
+	id, err := RegisterSchedule(ScheduleDefinition{
+		Name:         "Security",
+		Processes:    [3]string{"Scan", "Patch", "Audit"},
+		Consultation: map[ProcessID]ConsultationType{Process3: ConsultationMandatory},
+	})
+	if err != nil {
+		t.Fatalf("RegisterSchedule() error = %v", err)
+	}
+	if id != ScheduleProduction+1 {
+		t.Errorf("RegisterSchedule() id = %d, want %d", id, ScheduleProduction+1)
+	}
+	if id.String() != "Security" || ProcessNames[id][Process2] != "Patch" {
+		t.Errorf("custom schedule names not registered: %q %q", id.String(), ProcessNames[id][Process2])
+	}
+	if GetScheduleModel(id) != ModelCoder {
+		t.Errorf("GetScheduleModel() = %v, want coder default", GetScheduleModel(id))
+	}
+	if GetProcessConsultationType(id, Process3) != ConsultationMandatory {
+		t.Error("custom consultation type not applied")
+	}
+
+	order := ScheduleSelectionOrder()
+	if order[len(order)-1] != ScheduleProduction || order[len(order)-2] != id {
+		t.Errorf("ScheduleSelectionOrder() = %v, want custom before Production", order)
+	}
+
+	if _, err := RegisterSchedule(ScheduleDefinition{Name: "security", Processes: [3]string{"a", "b", "c"}}); err == nil {
+		t.Error("duplicate schedule name should be rejected")
+	}
+	if _, err := RegisterSchedule(ScheduleDefinition{Name: "Docs", Processes: [3]string{"a", "", "c"}}); err == nil {
+		t.Error("empty process name should be rejected")
+	}
+
+	code := NewFlowCode()
+	code.AddSchedule(id)
+	code.AddProcess(Process1)
+	stats, err := CalculateFlowStats(code.String())
+	if err != nil {
+		t.Fatalf("CalculateFlowStats(%q) error = %v", code.String(), err)
+	}
+	if stats.ProcessCounts[id][Process1] != 1 {
+		t.Errorf("custom schedule process count = %d, want 1", stats.ProcessCounts[id][Process1])
+	}
+
+	ClearCustomSchedules()
+	if IsKnownSchedule(id) {
+		t.Error("ClearCustomSchedules should unregister custom schedules")
+	}
+}
+
+func TestHeuristicSelectSchedule_Custom(t *testing.T) {
+	defer ClearCustomSchedules()
+
+	id, err := RegisterSchedule(ScheduleDefinition{Name: "Docs", Processes: [3]string{"Survey", "Write", "Review"}})
+	if err != nil {
+		t.Fatalf("RegisterSchedule() error = %v", err)
+	}
+
+	o := NewOrchestrator()
+	var seen []ScheduleID
+	for i := 0; i < 6; i++ {
+		next := o.heuristicSelectSchedule()
+		seen = append(seen, next)
+		if err := o.SelectSchedule(next); err != nil {
+			t.Fatalf("SelectSchedule(%d) error = %v", next, err)
+		}
+	}
+	if seen[4] != id || seen[5] != ScheduleProduction {
+		t.Errorf("selection order = %v, want custom then Production", seen)
+	}
+	if !o.CanTerminatePrompt() {
+		t.Error("prompt should be terminable once Production runs last")
+	}
+}
+
+func TestParseRecoveryDecision(t *testing.T) {
+	tests := []struct {
+		in   string
+		want RecoveryDecision
+		ok   bool
+	}{
+		{"R", RecoveryRetry, true},
+		{" skip ", RecoverySkip, true},
+		{"Adjust", RecoveryAdjust, true},
+		{"abort", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRecoveryDecision(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseRecoveryDecision(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRequestScheduleRevisit(t *testing.T) {
+	o := NewOrchestrator()
+	stop := errors.New("stop")
+
+	selects := 0
+	selectSchedule := func(ctx context.Context) (ScheduleID, error) {
+		selects++
+		if selects > 1 {
+			return 0, stop
+		}
+		return ScheduleImplement, nil
+	}
+	selectProcess := func(ctx context.Context, id ScheduleID, last ProcessID) (ProcessID, bool, error) {
+		if id == SchedulePlan && last != 0 {
+			return 0, false, stop
+		}
+		return Process1, false, nil
+	}
+	execute := func(ctx context.Context, id ScheduleID, p ProcessID) error {
+		if id == ScheduleImplement {
+			return o.RequestScheduleRevisit(SchedulePlan, "stuck")
+		}
+		return nil
+	}
+
+	if err := o.Run(context.Background(), selectSchedule, selectProcess, execute); !errors.Is(err, stop) {
+		t.Fatalf("Run() error = %v, want stop", err)
+	}
+	if got := o.GetFlowCode(); got != "S3P1S2P1X" {
+		t.Errorf("flow code = %q, want S3P1S2P1X", got)
+	}
+	if selects != 1 {
+		t.Errorf("selectSchedule called %d times, want revisit to bypass selection", selects)
+	}
+	if _, ok := o.PendingRevisit(); ok {
+		t.Error("pending revisit should be cleared once taken")
+	}
+}
+
+func TestRun_ErrorHandler(t *testing.T) {
+	fail := errors.New("boom")
+
+	run := func(resolutions ...ErrorResolution) (*Orchestrator, int, error) {
+		o := NewOrchestrator()
+		calls := 0
+		o.SetErrorHandler(func(ctx context.Context, err error, s ScheduleID, p ProcessID) ErrorResolution {
+			r := resolutions[0]
+			resolutions = resolutions[1:]
+			return r
+		})
+		selectSchedule := func(ctx context.Context) (ScheduleID, error) {
+			if o.GetStats().TotalSchedulings > 0 {
+				return 0, fail
+			}
+			return ScheduleKnowledge, nil
+		}
+		selectProcess := func(ctx context.Context, id ScheduleID, last ProcessID) (ProcessID, bool, error) {
+			if last == Process1 {
+				return 0, false, fail
+			}
+			return Process1, false, nil
+		}
+		execute := func(ctx context.Context, id ScheduleID, p ProcessID) error {
+			calls++
+			return fail
+		}
+		err := o.Run(context.Background(), selectSchedule, selectProcess, execute)
+		return o, calls, err
+	}
+
+	_, calls, err := run(ResolutionRetry, ResolutionSkip)
+	if calls != 2 || !errors.Is(err, fail) || errors.Is(err, ErrAborted) {
+		t.Errorf("retry then skip: calls = %d, err = %v", calls, err)
+	}
+
+	o, calls, err := run(ResolutionAbort)
+	if calls != 1 || !errors.Is(err, ErrAborted) {
+		t.Errorf("abort: calls = %d, err = %v", calls, err)
+	}
+	if o.State() != StateSuspended {
+		t.Errorf("state after abort = %s, want %s", o.State(), StateSuspended)
+	}
+}
+
+func TestRun_TerminationGate(t *testing.T) {
+	run := func(maxCycles int, gate TerminationGate) (*Orchestrator, error) {
+		o := NewOrchestrator()
+		o.SetTerminationGate(gate, maxCycles)
+		selectSchedule := func(ctx context.Context) (ScheduleID, error) {
+			if o.CanTerminatePrompt() {
+				return 0, nil
+			}
+			if n := o.GetStats().TotalSchedulings; n < 5 {
+				return ScheduleID(n + 1), nil
+			}
+			return ScheduleProduction, nil
+		}
+		selectProcess := func(ctx context.Context, id ScheduleID, last ProcessID) (ProcessID, bool, error) {
+			if last == Process3 {
+				return 0, true, nil
+			}
+			return last + 1, false, nil
+		}
+		execute := func(ctx context.Context, id ScheduleID, p ProcessID) error { return nil }
+		err := o.Run(context.Background(), selectSchedule, selectProcess, execute)
+		return o, err
+	}
+
+	calls := 0
+	o, err := run(0, func(ctx context.Context) (bool, []string, error) {
+		calls++
+		return calls > 2, []string{"Add tests"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if calls != 3 || o.GateCycles() != 2 {
+		t.Errorf("gate calls = %d, cycles = %d; want 3, 2", calls, o.GateCycles())
+	}
+	if got := o.GetStats().TotalSchedulings; got != 9 {
+		t.Errorf("schedulings = %d, want 9 (two extra Implement/Production cycles)", got)
+	}
+	if todos := o.GetNotes(NoteFilter{Types: []NoteType{NoteTodo}}); len(todos) != 2 || todos[0].Content != "Add tests" {
+		t.Errorf("todo notes = %+v", todos)
+	}
+
+	o, err = run(1, func(ctx context.Context) (bool, []string, error) {
+		return false, nil, nil
+	})
+	if err != nil || o.State() != StatePromptTerminated {
+		t.Fatalf("Run() error = %v, state = %s; want termination after the last cycle", err, o.State())
+	}
+	if o.GateCycles() != 1 || len(o.GetNotes(NoteFilter{Types: []NoteType{NoteRisk}})) != 1 {
+		t.Errorf("cycles = %d, risk notes = %d; want 1, 1", o.GateCycles(), len(o.GetNotes(NoteFilter{Types: []NoteType{NoteRisk}})))
+	}
+}
+
+func TestRestore(t *testing.T) {
+	o := NewOrchestrator()
+	notes := []Note{{ID: "N1", Content: "planned", Source: "planner"}}
+	if err := o.Restore("S1P1P2P3S2P1P2", notes); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got := o.GetFlowCode(); got != "S1P1P2P3S2P1P2" {
+		t.Errorf("flow code = %q", got)
+	}
+	stats := o.GetStats()
+	if stats.TotalSchedulings != 2 || stats.TotalProcesses != 5 {
+		t.Errorf("stats = %d schedulings, %d processes", stats.TotalSchedulings, stats.TotalProcesses)
+	}
+	if got := o.GetNotes(); len(got) != 1 || !got[0].Reviewed {
+		t.Errorf("notes = %+v", got)
+	}
+	if err := o.Restore("S1P1", nil); err == nil {
+		t.Error("Restore() on a restored orchestrator succeeded")
+	}
+
+	// Run continues in S2 after P2 without selecting a schedule
+	stop := errors.New("stop")
+	var gotSched ScheduleID
+	var gotLast ProcessID
+	err := o.Run(context.Background(),
+		func(ctx context.Context) (ScheduleID, error) {
+			t.Error("schedule selected on resume")
+			return 0, stop
+		},
+		func(ctx context.Context, id ScheduleID, last ProcessID) (ProcessID, bool, error) {
+			gotSched, gotLast = id, last
+			return 0, false, stop
+		},
+		func(ctx context.Context, id ScheduleID, p ProcessID) error { return nil },
+	)
+	if !errors.Is(err, stop) || gotSched != SchedulePlan || gotLast != Process2 {
+		t.Errorf("resume: err = %v, schedule = %d, last = %d", err, gotSched, gotLast)
+	}
+
+	if err := NewOrchestrator().Restore("S1P4", nil); err == nil {
+		t.Error("Restore() accepted an invalid flow code")
+	}
+}
+
+func TestProvenanceString(t *testing.T) {
+	tests := []struct {
+		p    Provenance
+		want string
+	}{
+		{Provenance{URL: "https://go.dev/doc", Path: "ignored"}, "https://go.dev/doc"},
+		{Provenance{Path: "docs/a.md", Commit: "0123456789abcdef0123"}, "docs/a.md@0123456789ab"},
+		{Provenance{Path: "README.md"}, "README.md"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+
+	o := NewOrchestrator()
+	o.AddSourcedNote("Retrieved documentation: Setup", "knowledge", Provenance{Path: "docs/setup.md"})
+	notes := o.GetNotes()
+	if len(notes) != 1 || notes[0].Provenance == nil || notes[0].Provenance.Path != "docs/setup.md" {
+		t.Errorf("GetNotes() = %+v", notes)
+	}
+}
+
+func TestNoteFilterAndPrompt(t *testing.T) {
+	o := NewOrchestrator()
+	o.AddNote("Loop detected", "system")
+	o.AddTypedNote(NoteDecision, "Use sqlite for the cache", "agent", "storage")
+	o.AddTypedNote(NoteTodo, "Add migration tests", "planner", "T1", "storage")
+	o.AddTypedNote(NoteRisk, "Migration may lock tables", "planner")
+
+	if got := o.GetNotes(); len(got) != 4 {
+		t.Fatalf("GetNotes() = %d notes, want 4", len(got))
+	}
+	if got := o.GetNotes(NoteFilter{Types: []NoteType{NoteTodo, NoteRisk}}); len(got) != 2 {
+		t.Errorf("type filter = %d notes, want 2", len(got))
+	}
+	if got := o.GetNotes(NoteFilter{Tags: []string{"STORAGE"}}, NoteFilter{Source: "planner"}); len(got) != 1 || got[0].Content != "Add migration tests" {
+		t.Errorf("tag and source filters = %+v", got)
+	}
+	if got := o.GetNotes(NoteFilter{Types: []NoteType{NoteGeneral}}); len(got) != 1 {
+		t.Errorf("general filter = %d notes, want 1", len(got))
+	}
+
+	prompt := o.NotesPrompt(ScheduleImplement)
+	if !strings.Contains(prompt, "[decision] Use sqlite for the cache (tags: storage)") || !strings.Contains(prompt, "[todo] Add migration tests") {
+		t.Errorf("Implement prompt missing notes:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "lock tables") || strings.Contains(prompt, "Loop detected") {
+		t.Errorf("Implement prompt should only include decisions, constraints, and todos:\n%s", prompt)
+	}
+	if !strings.Contains(o.NotesPrompt(ScheduleScale), "[risk] Migration may lock tables") {
+		t.Error("Scale prompt missing risk note")
+	}
+
+	if _, err := ParseNoteType("Risk"); err != nil {
+		t.Errorf("ParseNoteType(Risk) error = %v", err)
+	}
+	if _, err := ParseNoteType("idea"); err == nil {
+		t.Error("ParseNoteType(idea) should fail")
+	}
+}
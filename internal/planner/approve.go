@@ -0,0 +1,163 @@
+package planner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrPlanRejected is returned by Approve when the plan is not approved.
+var ErrPlanRejected = errors.New("plan rejected")
+
+// approveHelp lists the commands of the approval prompt.
+const approveHelp = `Commands:
+  Enter, y                 approve the plan and start
+  rm <id>                  remove a subtask
+  edit <id> <text>         reword a subtask
+  add [file:] <text>       add a subtask, optionally on a file
+  risk <id> <level> [why]  set a subtask's risk: safe, moderate, or high
+  ls                       list the subtasks
+  n, q                     reject the plan and stop
+`
+
+// Approve has the user review plan's subtasks a command at a time, read
+// from in: subtasks may be removed, reworded, added, and have their risk
+// changed, in place, until the user approves the plan. It returns
+// ErrPlanRejected when the user rejects the plan or in ends first.
+func Approve(in *bufio.Reader, out io.Writer, plan *Plan) error {
+	listTasks(out, plan)
+	for {
+		fmt.Fprint(out, "Approve plan? [Y/n, ? for commands] ")
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			fmt.Fprintln(out)
+			return ErrPlanRejected
+		}
+		cmd, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		args = strings.TrimSpace(args)
+
+		switch strings.ToLower(cmd) {
+		case "", "y", "yes":
+			if len(plan.Tasks) == 0 {
+				fmt.Fprintln(out, "The plan has no subtasks; add one or reject it.")
+				continue
+			}
+			return nil
+		case "n", "no", "q", "quit":
+			return ErrPlanRejected
+		case "ls", "list":
+			listTasks(out, plan)
+		case "rm", "remove":
+			i, err := plan.taskIndex(args)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			fmt.Fprintf(out, "Removed %s\n", plan.Tasks[i].ID)
+			plan.Tasks = append(plan.Tasks[:i], plan.Tasks[i+1:]...)
+		case "edit":
+			id, text, _ := strings.Cut(args, " ")
+			i, err := plan.taskIndex(id)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			if text = strings.TrimSpace(text); text == "" {
+				fmt.Fprintln(out, "usage: edit <id> <text>")
+				continue
+			}
+			plan.Tasks[i].Message = text
+			fmt.Fprintln(out, TaskLine(plan.Root, plan.Tasks[i]))
+		case "add":
+			if args == "" {
+				fmt.Fprintln(out, "usage: add [file:] <text>")
+				continue
+			}
+			task := plan.AddTask(args)
+			fmt.Fprintln(out, TaskLine(plan.Root, task))
+		case "risk":
+			fields := strings.SplitN(args, " ", 3)
+			if len(fields) < 2 {
+				fmt.Fprintln(out, "usage: risk <id> <safe|moderate|high> [why]")
+				continue
+			}
+			i, err := plan.taskIndex(fields[0])
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			level, err := ParseRiskLevel(fields[1])
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			plan.Tasks[i].Risk = level
+			plan.Tasks[i].Rationale = "Set when the plan was approved."
+			if len(fields) == 3 {
+				plan.Tasks[i].Rationale = strings.TrimSpace(fields[2])
+			}
+			fmt.Fprintln(out, TaskLine(plan.Root, plan.Tasks[i]))
+		case "?", "h", "help":
+			fmt.Fprint(out, approveHelp)
+		default:
+			fmt.Fprintf(out, "unknown command %q\n%s", cmd, approveHelp)
+		}
+	}
+}
+
+// listTasks writes plan's subtasks, one per line.
+func listTasks(out io.Writer, plan *Plan) {
+	if len(plan.Tasks) == 0 {
+		fmt.Fprintln(out, "No subtasks.")
+		return
+	}
+	for _, task := range plan.Tasks {
+		fmt.Fprintln(out, "  "+TaskLine(plan.Root, task))
+	}
+}
+
+// AddTask appends a subtask written by the user and returns it. Text may
+// name the subtask's file first, as in "main.go: handle errors". Its risk
+// is labeled as for planned tasks.
+func (p *Plan) AddTask(text string) Task {
+	task := Task{ID: p.nextID(), Kind: "manual", Message: strings.TrimSpace(text), FixType: p.FixType}
+	if file, msg, ok := strings.Cut(text, ": "); ok && !strings.ContainsAny(file, " \t") {
+		task.File = file
+		if p.Root != "" && !filepath.IsAbs(file) {
+			task.File = filepath.Join(p.Root, file)
+		}
+		task.Message = strings.TrimSpace(msg)
+	}
+	task.Risk, task.Rationale = NewRiskLabeler().Label(task)
+	p.Tasks = append(p.Tasks, task)
+	return task
+}
+
+// taskIndex returns the index of the task with id, which may be given as
+// "T-002", "t-002", or just "2".
+func (p *Plan) taskIndex(id string) (int, error) {
+	if n, err := strconv.Atoi(id); err == nil {
+		id = nextTaskID(n)
+	}
+	for i, task := range p.Tasks {
+		if strings.EqualFold(task.ID, id) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no subtask %q", id)
+}
+
+// nextID returns an ID after every task's.
+func (p *Plan) nextID() string {
+	last := 0
+	for _, task := range p.Tasks {
+		if n, err := strconv.Atoi(strings.TrimPrefix(task.ID, "T-")); err == nil && n > last {
+			last = n
+		}
+	}
+	return nextTaskID(last + 1)
+}
@@ -0,0 +1,77 @@
+package planner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPlan() *Plan {
+	return &Plan{
+		Root: "/repo",
+		Tasks: []Task{
+			{ID: "T-001", Kind: "todo", File: "/repo/main.go", Line: 3, Message: "handle errors", Risk: RiskModerate},
+			{ID: "T-002", Kind: "todo", File: "/repo/README.md", Message: "document flags", Risk: RiskSafe},
+		},
+	}
+}
+
+func TestApprove_EditsPlan(t *testing.T) {
+	plan := testPlan()
+	in := bufio.NewReader(strings.NewReader(strings.Join([]string{
+		"rm 2",
+		"edit T-001 handle and wrap errors",
+		"add cmd/root.go: add a --verbose flag",
+		"risk t-002 high touches every command",
+		"y\n",
+	}, "\n")))
+	var out bytes.Buffer
+	if err := Approve(in, &out, plan); err != nil {
+		t.Fatalf("Approve() error = %v\n%s", err, out.String())
+	}
+
+	if len(plan.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", plan.Tasks)
+	}
+	if plan.Tasks[0].Message != "handle and wrap errors" {
+		t.Errorf("edited message = %q", plan.Tasks[0].Message)
+	}
+	added := plan.Tasks[1]
+	if added.ID != "T-002" || added.File != filepath.Join("/repo", "cmd/root.go") || added.Message != "add a --verbose flag" {
+		t.Errorf("added task = %+v", added)
+	}
+	if added.Risk != RiskHigh || added.Rationale != "touches every command" {
+		t.Errorf("risk = %s (%s)", added.Risk, added.Rationale)
+	}
+	if line := TaskLine(plan.Root, plan.Tasks[0]); line != "[T-001] [moderate] main.go:3 (todo) handle and wrap errors" {
+		t.Errorf("TaskLine() = %q", line)
+	}
+}
+
+func TestApprove_Rejects(t *testing.T) {
+	for _, input := range []string{"n\n", "rm 9\nq\n", ""} {
+		var out bytes.Buffer
+		err := Approve(bufio.NewReader(strings.NewReader(input)), &out, testPlan())
+		if !errors.Is(err, ErrPlanRejected) {
+			t.Errorf("Approve(%q) error = %v, want ErrPlanRejected", input, err)
+		}
+	}
+}
+
+func TestApprove_EmptyPlanNeedsTask(t *testing.T) {
+	plan := &Plan{Root: "/repo"}
+	in := bufio.NewReader(strings.NewReader("y\nadd write the tests\ny\n"))
+	var out bytes.Buffer
+	if err := Approve(in, &out, plan); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if len(plan.Tasks) != 1 || plan.Tasks[0].File != "" || plan.Tasks[0].ID != "T-001" {
+		t.Errorf("tasks = %+v", plan.Tasks)
+	}
+	if !strings.Contains(out.String(), "has no subtasks") {
+		t.Errorf("empty plan approved without a warning:\n%s", out.String())
+	}
+}
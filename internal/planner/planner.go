@@ -41,6 +41,7 @@ type Options struct {
 	MaxFiles     int
 	MaxFileSize  int64
 	IncludeHidden bool
+	CacheDir     string // reuse the file index persisted here; empty rescans every file
 }
 
 func DefaultOptions() Options {
@@ -49,6 +50,7 @@ func DefaultOptions() Options {
 		MaxFiles:     10,
 		MaxFileSize:  1 * 1024 * 1024,
 		IncludeHidden: false,
+		CacheDir:     index.DefaultCacheDir(),
 	}
 }
 
@@ -83,10 +85,10 @@ func BuildPlan(ctx context.Context, path string, instruction string, opts Option
 		root = filepath.Dir(absPath)
 	}
 
-	idx, err := index.Build(ctx, root, index.Options{
+	idx, err := index.BuildCached(ctx, root, index.Options{
 		MaxFileSize:   opts.MaxFileSize,
 		IncludeHidden: opts.IncludeHidden,
-	})
+	}, opts.CacheDir)
 	if err != nil {
 		return nil, err
 	}
@@ -284,17 +286,25 @@ func RenderText(plan *Plan) string {
 
 	sb.WriteString("Tasks:\n")
 	for _, task := range plan.Tasks {
-		rel := fsutil.RelPath(plan.Root, task.File)
-		riskStr := ""
-		if task.Risk != "" {
-			riskStr = fmt.Sprintf(" [%s]", task.Risk)
-		}
-		if task.Line > 0 {
-			sb.WriteString(fmt.Sprintf("  - [%s]%s %s:%d (%s) %s\n", task.ID, riskStr, rel, task.Line, task.Kind, task.Message))
-		} else {
-			sb.WriteString(fmt.Sprintf("  - [%s]%s %s (%s) %s\n", task.ID, riskStr, rel, task.Kind, task.Message))
-		}
+		sb.WriteString("  - " + TaskLine(plan.Root, task) + "\n")
 	}
 
 	return sb.String()
 }
+
+// TaskLine formats a task on one line, its file relative to root, as
+// "[T-001] [safe] main.go:12 (todo) message".
+func TaskLine(root string, task Task) string {
+	riskStr := ""
+	if task.Risk != "" {
+		riskStr = fmt.Sprintf(" [%s]", task.Risk)
+	}
+	where := ""
+	if task.File != "" {
+		where = " " + fsutil.RelPath(root, task.File)
+		if task.Line > 0 {
+			where += fmt.Sprintf(":%d", task.Line)
+		}
+	}
+	return fmt.Sprintf("[%s]%s%s (%s) %s", task.ID, riskStr, where, task.Kind, task.Message)
+}
@@ -1,6 +1,7 @@
 package planner
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +17,15 @@ const (
 	RiskHigh     RiskLevel = "high"
 )
 
+// ParseRiskLevel parses a risk level name such as "high" or "Safe".
+func ParseRiskLevel(s string) (RiskLevel, error) {
+	switch level := RiskLevel(strings.ToLower(strings.TrimSpace(s))); level {
+	case RiskSafe, RiskModerate, RiskHigh:
+		return level, nil
+	}
+	return "", fmt.Errorf("unknown risk level %q (use safe, moderate, or high)", s)
+}
+
 // RiskLabeler analyzes changes and labels them with a risk level.
 // It provides rationale for the categorization to aid in orchestration decisions.
 type RiskLabeler struct{}
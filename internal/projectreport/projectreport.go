@@ -0,0 +1,276 @@
+// Package projectreport writes PROJECT_REPORT.md, the handoff document of
+// an orchestrated project: what was asked, how the project is laid out, the
+// decisions taken on the way, how to run it, and the issues the judges left
+// open. It is rendered from a Markdown template that a workspace may
+// replace with its own at .obot/PROJECT_REPORT.md.tmpl.
+package projectreport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// FileName is the report's name in the workspace.
+const FileName = "PROJECT_REPORT.md"
+
+// Marker is the first line of a report obot wrote. A report without it is
+// the project's own and is never replaced.
+const Marker = "<!-- Written by obot orchestrate; replaced on the next run -->"
+
+// ErrNotOurs is returned for a report obot did not write.
+var ErrNotOurs = errors.New(FileName + " was not written by obot")
+
+// TemplatePath returns the path of workspace's own report template.
+func TemplatePath(workspace string) string {
+	return filepath.Join(workspace, ".obot", FileName+".tmpl")
+}
+
+// Data is what a report template renders.
+type Data struct {
+	Prompt    string
+	SessionID string
+	Date      time.Time
+	FlowCode  string
+
+	// Summary is the judges' account of the implementation; empty when the
+	// session was not judged.
+	Summary string
+	// Languages are the toolchains whose project files are in the workspace.
+	Languages []string
+	// Layout is the workspace's top-level directories and files.
+	Layout []Entry
+
+	Decisions   []string
+	Constraints []string
+	Todos       []string
+	Risks       []string
+
+	// Commands build, check, and test the project.
+	Commands []Command
+
+	Issues  []Issue
+	Changed []string // files the run changed
+}
+
+// Entry is a top-level directory, with the files below it, or file.
+type Entry struct {
+	Name  string
+	Dir   bool
+	Files int
+}
+
+// Command is a way to check or run the project.
+type Command struct {
+	Purpose string // such as "Test"
+	Command string
+}
+
+// Issue is an issue the judges found, and how to resolve it.
+type Issue struct {
+	Description string
+	Resolution  string
+}
+
+// DefaultTemplate is the report template used when the workspace has none.
+const DefaultTemplate = `# Project Report
+
+> {{firstLine .Prompt}}
+
+Generated by obot on {{.Date.Format "2006-01-02"}}{{with .SessionID}} for session ` + "`{{.}}`" + `{{end}}{{with .FlowCode}} (flow ` + "`{{.}}`" + `){{end}}.
+
+## Architecture Overview
+{{with .Summary}}
+{{.}}
+{{end}}{{with .Languages}}
+Languages: {{join . ", "}}
+{{end}}{{with .Layout}}
+| Path | Files |
+| --- | --- |
+{{range .}}| ` + "`{{.Name}}{{if .Dir}}/{{end}}`" + ` | {{if .Dir}}{{.Files}}{{end}} |
+{{end}}{{else}}
+The workspace is empty.
+{{end}}
+## Decisions
+{{range .Decisions}}
+- {{.}}{{else}}
+No decisions were recorded.{{end}}
+{{with .Constraints}}
+### Constraints
+{{range .}}
+- {{.}}{{end}}
+{{end}}
+## How to Run
+{{with .Commands}}
+| Purpose | Command |
+| --- | --- |
+{{range .}}| {{.Purpose}} | ` + "`{{cell .Command}}`" + ` |
+{{end}}{{else}}
+No build or test commands were found; see the README.
+{{end}}
+## Known Issues
+{{range .Issues}}
+- {{.Description}}{{with .Resolution}}
+  - Resolution: {{.}}{{end}}{{else}}
+The judges found no open issues.{{end}}
+{{with .Risks}}
+### Risks
+{{range .}}
+- {{.}}{{end}}
+{{end}}{{with .Todos}}
+### Follow-ups
+{{range .}}
+- {{.}}{{end}}
+{{end}}{{with .Changed}}
+## Files Changed
+{{range .}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}`
+
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+		return line
+	},
+}
+
+// Render renders data with the template text.
+func Render(text string, data *Data) ([]byte, error) {
+	tmpl, err := template.New(FileName).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse report template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Generate renders data with workspace's template, or DefaultTemplate,
+// into a report starting with Marker.
+func Generate(workspace string, data *Data) ([]byte, error) {
+	text := DefaultTemplate
+	if custom, err := os.ReadFile(TemplatePath(workspace)); err == nil {
+		text = string(custom)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	out, err := Render(text, data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(Marker+"\n"), out...), nil
+}
+
+// CheckReplace returns ErrNotOurs when the report at path exists and obot
+// did not write it.
+func CheckReplace(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if strings.TrimRight(line, "\r\n") != Marker {
+		return ErrNotOurs
+	}
+	return nil
+}
+
+// Survey fills in the languages and layout of the workspace: the
+// toolchains whose project files are at its root, and its top-level
+// entries with the files below the directories, leaving out ignored files.
+func (d *Data) Survey(workspace string, toolchains []agent.Toolchain) error {
+	d.Languages = nil
+	for _, tc := range toolchains {
+		for _, m := range tc.Markers {
+			if _, err := os.Stat(filepath.Join(workspace, m)); err == nil {
+				d.Languages = append(d.Languages, tc.Name)
+				break
+			}
+		}
+	}
+
+	entries := make(map[string]*Entry)
+	err := fswalk.Walk(workspace, fswalk.Options{SkipBinary: true}, func(e fswalk.Entry) error {
+		top, rest, nested := strings.Cut(e.Rel, "/")
+		if top == FileName && !nested {
+			return nil
+		}
+		entry := entries[top]
+		if entry == nil {
+			entry = &Entry{Name: top, Dir: nested}
+			entries[top] = entry
+		}
+		if rest != "" {
+			entry.Files++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.Layout = d.Layout[:0]
+	for _, e := range entries {
+		d.Layout = append(d.Layout, *e)
+	}
+	// Directories first, then files, each by name
+	sort.Slice(d.Layout, func(i, j int) bool {
+		if d.Layout[i].Dir != d.Layout[j].Dir {
+			return d.Layout[i].Dir
+		}
+		return d.Layout[i].Name < d.Layout[j].Name
+	})
+	return nil
+}
+
+// maxCommands bounds the commands listed under How to Run.
+const maxCommands = 10
+
+// AddCommands adds the commands of the actions that linted or tested the
+// project successfully, each once, in the order first run.
+func (d *Data) AddCommands(actions []agent.Action) {
+	seen := make(map[string]bool)
+	for _, c := range d.Commands {
+		seen[c.Command] = true
+	}
+	for _, a := range actions {
+		if len(d.Commands) >= maxCommands {
+			return
+		}
+		purpose, ok := commandPurposes[a.Type]
+		if !ok || a.Command == "" || a.ExitCode != 0 || seen[a.Command] {
+			continue
+		}
+		if status, _ := a.Metadata["status"].(string); status == "failed" {
+			continue
+		}
+		seen[a.Command] = true
+		d.Commands = append(d.Commands, Command{Purpose: purpose, Command: a.Command})
+	}
+}
+
+var commandPurposes = map[agent.ActionType]string{
+	agent.ActionLint: "Lint",
+	agent.ActionTest: "Test",
+}
@@ -0,0 +1,155 @@
+package projectreport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/test"
+)
+
+func fixtureData() *Data {
+	return &Data{
+		Prompt:    "Build a REST API for todos\nwith SQLite storage",
+		SessionID: "sess-1",
+		Date:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		FlowCode:  "S1P123S2P123S3P123S4P123S5P123",
+		Summary:   "A net/http server with a SQLite repository behind a small service layer.",
+		Languages: []string{"go"},
+		Layout: []Entry{
+			{Name: "cmd", Dir: true, Files: 1},
+			{Name: "internal", Dir: true, Files: 6},
+			{Name: "go.mod"},
+		},
+		Decisions:   []string{"Use database/sql with modernc.org/sqlite to avoid cgo"},
+		Constraints: []string{"No external web framework"},
+		Todos:       []string{"Add pagination"},
+		Risks:       []string{"No authentication"},
+		Commands: []Command{
+			{Purpose: "Lint", Command: "go vet ./..."},
+			{Purpose: "Test", Command: "go test -v ./... | tee test.log"},
+		},
+		Issues: []Issue{
+			{Description: "[security] [high] internal/api/todo.go:42: request body size is unbounded", Resolution: "Wrap the body in http.MaxBytesReader"},
+			{Description: "Missing tests for the delete handler"},
+		},
+		Changed: []string{"cmd/server/main.go", "internal/api/todo.go"},
+	}
+}
+
+func TestRender_Golden(t *testing.T) {
+	out, err := Render(DefaultTemplate, fixtureData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertGolden(t, "project_report", out)
+}
+
+func TestRender_Empty(t *testing.T) {
+	out, err := Render(DefaultTemplate, &Data{Prompt: "Fix it", Date: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"The workspace is empty.", "No decisions were recorded.", "No build or test commands were found", "The judges found no open issues."} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("report of an empty run lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "## Files Changed") {
+		t.Errorf("report of an empty run lists changed files:\n%s", out)
+	}
+}
+
+func TestGenerate_WorkspaceTemplate(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, ".obot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(TemplatePath(workspace), []byte("# {{.SessionID}}\n{{range .Issues}}* {{.Description}}\n{{end}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(workspace, fixtureData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Marker + "\n# sess-1\n* [security] [high] internal/api/todo.go:42: request body size is unbounded\n* Missing tests for the delete handler\n"
+	if string(got) != want {
+		t.Errorf("generated:\n%s\nwant\n%s", got, want)
+	}
+
+	if err := os.WriteFile(TemplatePath(workspace), []byte("{{.Nope"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(workspace, fixtureData()); err == nil {
+		t.Error("Generate succeeded with a broken template")
+	}
+}
+
+func TestCheckReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := CheckReplace(path); err != nil {
+		t.Errorf("CheckReplace() of a missing report = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(Marker+"\n# Report\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckReplace(path); err != nil {
+		t.Errorf("CheckReplace() of obot's report = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("# Our own report\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckReplace(path); err != ErrNotOurs {
+		t.Errorf("CheckReplace() of the project's report = %v, want ErrNotOurs", err)
+	}
+}
+
+func TestData_Survey(t *testing.T) {
+	workspace := t.TempDir()
+	for _, f := range []string{"go.mod", "main.go", FileName, "internal/api/todo.go", "internal/store/store.go", ".obot/audit.jsonl"} {
+		path := filepath.Join(workspace, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var d Data
+	if err := d.Survey(workspace, agent.DefaultToolchains()); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Languages) != 1 || d.Languages[0] != "go" {
+		t.Errorf("Languages = %v, want [go]", d.Languages)
+	}
+	want := []Entry{{Name: "internal", Dir: true, Files: 2}, {Name: "go.mod"}, {Name: "main.go"}}
+	if len(d.Layout) != len(want) {
+		t.Fatalf("Layout = %+v, want %+v", d.Layout, want)
+	}
+	for i := range want {
+		if d.Layout[i] != want[i] {
+			t.Errorf("Layout[%d] = %+v, want %+v", i, d.Layout[i], want[i])
+		}
+	}
+}
+
+func TestData_AddCommands(t *testing.T) {
+	actions := []agent.Action{
+		{Type: agent.ActionTest, Command: "go test -v ./internal", ExitCode: 1},
+		{Type: agent.ActionLint, Command: "go vet ./internal"},
+		{Type: agent.ActionFormat, Command: "gofmt -l -w main.go"},
+		{Type: agent.ActionTest, Command: "go test -v ./internal"},
+		{Type: agent.ActionTest, Command: "go test -v ./internal"},
+		{Type: agent.ActionRunCommand, Command: "ls"},
+	}
+	var d Data
+	d.AddCommands(actions)
+	want := []Command{{Purpose: "Lint", Command: "go vet ./internal"}, {Purpose: "Test", Command: "go test -v ./internal"}}
+	if len(d.Commands) != len(want) || d.Commands[0] != want[0] || d.Commands[1] != want[1] {
+		t.Errorf("Commands = %+v, want %+v", d.Commands, want)
+	}
+}
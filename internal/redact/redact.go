@@ -0,0 +1,282 @@
+// Package redact removes secrets such as API keys, tokens, and .env values
+// from text before it is sent to a model or persisted to disk.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Finding reports how many secrets of one kind were redacted.
+type Finding struct {
+	Kind  string
+	Count int
+}
+
+// rule matches one kind of secret. When group is non-zero only that
+// submatch is replaced, keeping e.g. the variable name of an assignment.
+type rule struct {
+	kind  string
+	re    *regexp.Regexp
+	group int
+	check func(string) bool
+}
+
+// rules are applied in order; more specific patterns come first.
+var rules = []rule{
+	{kind: "private_key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{kind: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github_token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "slack_token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: "anthropic_key", re: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{kind: "openai_key", re: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: "stripe_key", re: regexp.MustCompile(`\b[rs]k_(?:live|test)_[0-9A-Za-z]{16,}`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{kind: "url_credentials", re: regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://[^\s:/@"]+:([^\s@/"\\]+)@`), group: 1},
+	// KEY=value lines from .env files and key: value pairs in configs
+	{
+		kind:  "secret_assignment",
+		re:    regexp.MustCompile(`(?i)\b[a-z0-9_.-]*(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)[a-z0-9_.-]*["']?\s*[:=]\s*["']?([^\s"'\\,;]{8,})`),
+		group: 1,
+		check: looksLikeSecretValue,
+	},
+	{kind: "high_entropy", re: regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`), check: isHighEntropy},
+}
+
+// Placeholder returns the text that replaces a secret of the given kind.
+func Placeholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// Redactor scans text for secrets and replaces them with placeholders.
+type Redactor struct {
+	mu     sync.Mutex
+	notify func(source string, findings []Finding)
+}
+
+// New creates a redactor that reports redactions to notify (which may be nil).
+func New(notify func(source string, findings []Finding)) *Redactor {
+	return &Redactor{notify: notify}
+}
+
+// SetNotifier replaces the function called when secrets are redacted.
+func (r *Redactor) SetNotifier(notify func(source string, findings []Finding)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notify = notify
+}
+
+// Redact replaces secrets in text and returns the findings.
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	counts := make(map[string]int)
+	for _, ru := range rules {
+		if !ru.re.MatchString(text) {
+			continue
+		}
+		text = replace(ru, text, counts)
+	}
+
+	if len(counts) == 0 {
+		return text, nil
+	}
+	findings := make([]Finding, 0, len(counts))
+	for kind, n := range counts {
+		findings = append(findings, Finding{Kind: kind, Count: n})
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Kind < findings[j].Kind })
+	return text, findings
+}
+
+// RedactFrom redacts text and notifies about findings, attributing them to source
+// (e.g. "prompt" or "session").
+func (r *Redactor) RedactFrom(source, text string) string {
+	redacted, findings := r.Redact(text)
+	if len(findings) > 0 {
+		r.mu.Lock()
+		notify := r.notify
+		r.mu.Unlock()
+		if notify != nil {
+			notify(source, findings)
+		}
+	}
+	return redacted
+}
+
+// RedactJSONFrom redacts the string values of JSON-encoded data and notifies
+// about findings, attributing them to source. Each string is decoded before
+// it is redacted and encoded again after, so that a match never starts
+// inside an escape sequence and the result stays valid JSON.
+func (r *Redactor) RedactJSONFrom(source string, data []byte) []byte {
+	var out []byte
+	last := 0
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			continue
+		}
+		// Outside strings JSON has no quotes, so this starts a string
+		end := i + 1
+		for end < len(data) && data[end] != '"' {
+			if data[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(data) {
+			break
+		}
+		var value string
+		if json.Unmarshal(data[i:end+1], &value) == nil {
+			if redacted := r.RedactFrom(source, value); redacted != value {
+				encoded, err := json.Marshal(redacted)
+				if err == nil {
+					out = append(out, data[last:i]...)
+					out = append(out, encoded...)
+					last = end + 1
+				}
+			}
+		}
+		i = end
+	}
+	if out == nil {
+		return data
+	}
+	return append(out, data[last:]...)
+}
+
+// replace applies one rule to text, counting replacements by kind.
+func replace(ru rule, text string, counts map[string]int) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range ru.re.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		if ru.group > 0 {
+			start, end = m[2*ru.group], m[2*ru.group+1]
+		}
+		if start < last || start < 0 {
+			continue
+		}
+		secret := text[start:end]
+		if strings.HasPrefix(secret, "[REDACTED:") || (ru.check != nil && !ru.check(secret)) {
+			continue
+		}
+		sb.WriteString(text[last:start])
+		sb.WriteString(Placeholder(ru.kind))
+		last = end
+		counts[ru.kind]++
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// looksLikeSecretValue rejects assignment values that are references or
+// ordinary code rather than literal secrets.
+func looksLikeSecretValue(v string) bool {
+	if strings.ContainsAny(v, "()$<>{}[]") {
+		return false
+	}
+	lower := strings.ToLower(v)
+	for _, placeholder := range []string{"xxx", "changeme", "your", "example", "placeholder", "redacted", "true", "false", "null", "none"} {
+		if strings.Contains(lower, placeholder) {
+			return false
+		}
+	}
+	var hasLetter, hasDigit bool
+	for _, c := range v {
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			hasLetter = true
+		}
+	}
+	return (hasLetter && hasDigit) || entropy(v) >= 3.5
+}
+
+// isHighEntropy reports whether a long token looks like random key material:
+// it mixes upper case, lower case, and digits and has high Shannon entropy.
+// Hex digests and identifiers do not qualify.
+func isHighEntropy(s string) bool {
+	var upper, lower, digit bool
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit && entropy(s) >= 4.3
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := make(map[rune]float64)
+	for _, c := range s {
+		freq[c]++
+	}
+	n := float64(len([]rune(s)))
+	var h float64
+	for _, f := range freq {
+		p := f / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// defaultRedactor warns on stderr once per source and kind.
+var defaultRedactor = New(newWarner(os.Stderr))
+
+// Default returns the process-wide redactor.
+func Default() *Redactor {
+	return defaultRedactor
+}
+
+// String redacts text with the default redactor.
+func String(source, text string) string {
+	return defaultRedactor.RedactFrom(source, text)
+}
+
+// Bytes redacts data with the default redactor. Use JSON for JSON-encoded
+// data.
+func Bytes(source string, data []byte) []byte {
+	return []byte(defaultRedactor.RedactFrom(source, string(data)))
+}
+
+// JSON redacts the string values of JSON-encoded data with the default
+// redactor.
+func JSON(source string, data []byte) []byte {
+	return defaultRedactor.RedactJSONFrom(source, data)
+}
+
+// newWarner returns a notifier that writes one warning per source and kind to w.
+func newWarner(w io.Writer) func(string, []Finding) {
+	var mu sync.Mutex
+	warned := make(map[string]bool)
+	return func(source string, findings []Finding) {
+		mu.Lock()
+		defer mu.Unlock()
+		var kinds []string
+		for _, f := range findings {
+			if key := source + "/" + f.Kind; !warned[key] {
+				warned[key] = true
+				kinds = append(kinds, f.Kind)
+			}
+		}
+		if len(kinds) > 0 {
+			fmt.Fprintf(w, "⚠ Redacted secrets (%s) from %s\n", strings.Join(kinds, ", "), source)
+		}
+	}
+}
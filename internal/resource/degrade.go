@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Mitigation is a step taken to relieve memory pressure at some cost in
+// quality or speed.
+type Mitigation string
+
+const (
+	MitigateContext   Mitigation = "reduce_context" // shrink the models' context window
+	MitigateSerialize Mitigation = "serialize"      // stop work that runs alongside the agent
+	MitigateModel     Mitigation = "smaller_model"  // switch roles to smaller model variants
+)
+
+// Mitigations returns the steps for a pressure level, mildest first. A
+// warning reduces the context window and serializes parallel work; critical
+// pressure also switches to smaller models. Normal pressure needs none, so
+// earlier steps are undone.
+func Mitigations(level PressureStatus) []Mitigation {
+	switch level {
+	case PressureCritical:
+		return []Mitigation{MitigateContext, MitigateSerialize, MitigateModel}
+	case PressureWarning:
+		return []Mitigation{MitigateContext, MitigateSerialize}
+	}
+	return nil
+}
+
+// Degradation is a decision to trade quality for memory, or to restore it
+// once the pressure passes.
+type Degradation struct {
+	Time     time.Time
+	Level    PressureStatus
+	Resource string   // "memory" or "vram", whichever is under more pressure
+	Steps    []string // each change made, such as "coder: qwen2.5-coder:14b -> deepseek-coder:6.7b"
+}
+
+// String returns a one-line description of the decision.
+func (d Degradation) String() string {
+	if d.Level == PressureNormal {
+		return fmt.Sprintf("%s pressure relieved: %s", d.Resource, strings.Join(d.Steps, "; "))
+	}
+	return fmt.Sprintf("%s pressure %s: %s", d.Resource, d.Level, strings.Join(d.Steps, "; "))
+}
+
+// pressureRank orders pressure levels from normal to critical.
+func pressureRank(p PressureStatus) int {
+	switch p {
+	case PressureCritical:
+		return 2
+	case PressureWarning:
+		return 1
+	}
+	return 0
+}
+
+// Pressure returns the worse of the RAM and VRAM pressure, and which
+// resource it is. RAM counts the safety margin as in use.
+func (m *Monitor) Pressure() (PressureStatus, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mem := m.pressure(m.memCurrent+m.safetyMarginGB, m.memTotal)
+	vram := m.pressure(m.vram.UsedGB, m.vram.TotalGB)
+	if pressureRank(vram) > pressureRank(mem) {
+		return vram, "vram"
+	}
+	return mem, "memory"
+}
+
+// RecordDegradation records a degradation decision for the summary.
+func (m *Monitor) RecordDegradation(d Degradation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+	m.degradations = append(m.degradations, d)
+}
+
+// Degradations returns the degradation decisions in the order made.
+func (m *Monitor) Degradations() []Degradation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Degradation(nil), m.degradations...)
+}
@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMitigations(t *testing.T) {
+	if got := Mitigations(PressureNormal); len(got) != 0 {
+		t.Errorf("Mitigations(normal) = %v, want none", got)
+	}
+	if got := Mitigations(PressureWarning); len(got) != 2 || got[0] != MitigateContext {
+		t.Errorf("Mitigations(warning) = %v", got)
+	}
+	if got := Mitigations(PressureCritical); len(got) != 3 || got[2] != MitigateModel {
+		t.Errorf("Mitigations(critical) = %v", got)
+	}
+}
+
+func TestMonitor_PressureTakesWorse(t *testing.T) {
+	m := NewMonitor()
+	m.SetGPUProbe(func(ctx context.Context) (VRAM, error) {
+		return VRAM{TotalGB: 24, UsedGB: 23.5, Source: "nvidia-smi"}, nil
+	})
+	if err := m.UpdateVRAM(context.Background()); err != nil {
+		t.Fatalf("UpdateVRAM: %v", err)
+	}
+	if level, res := m.Pressure(); level != PressureCritical || res != "vram" {
+		t.Errorf("Pressure() = %s, %s; want critical vram", level, res)
+	}
+
+	m.RecordDegradation(Degradation{Level: PressureCritical, Resource: "vram", Steps: []string{"coder: a -> b"}})
+	got := m.GetSummary().Degradations
+	if len(got) != 1 || got[0].Time.IsZero() {
+		t.Fatalf("summary degradations = %+v", got)
+	}
+	if s := got[0].String(); s != "vram pressure critical: coder: a -> b" {
+		t.Errorf("String() = %q", s)
+	}
+}
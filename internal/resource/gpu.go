@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+// ErrNoGPU is returned by a GPUProbe that found neither a GPU nor a model
+// loaded in one.
+var ErrNoGPU = errors.New("no GPU found")
+
+// VRAM is the GPU memory in use, in GB. Models are what Ollama holds in
+// memory, from /api/ps.
+type VRAM struct {
+	TotalGB float64 // 0 when the GPU's size is unknown
+	UsedGB  float64
+	Source  string // "nvidia-smi", "metal", or "ollama" when only /api/ps answered
+	Unified bool   // the GPU shares system RAM, as on Apple silicon
+	Models  []LoadedModel
+}
+
+// LoadedModel is a model Ollama holds in memory and the part of it in VRAM.
+type LoadedModel struct {
+	Name   string
+	SizeGB float64
+	VRAMGB float64
+}
+
+// FreeGB returns the VRAM not in use, 0 when the total is unknown.
+func (v VRAM) FreeGB() float64 {
+	if v.TotalGB <= v.UsedGB {
+		return 0
+	}
+	return v.TotalGB - v.UsedGB
+}
+
+// Loaded reports whether Ollama holds the model in memory.
+func (v VRAM) Loaded(name string) (LoadedModel, bool) {
+	for _, m := range v.Models {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return LoadedModel{}, false
+}
+
+// GPUProbe reads the GPU memory.
+type GPUProbe func(ctx context.Context) (VRAM, error)
+
+// NewGPUProbe returns a probe that sizes the GPU with nvidia-smi, or the
+// Metal working set on Apple silicon, and asks ps for the models loaded,
+// such as ollama.Client.RunningModels. A nil ps skips the models. Metal
+// cannot report its use, so there the models' VRAM is the VRAM used.
+func NewGPUProbe(ps func(context.Context) ([]ollama.RunningModel, error)) GPUProbe {
+	return func(ctx context.Context) (VRAM, error) {
+		var v VRAM
+		if total, used, err := queryNvidiaSMI(ctx); err == nil {
+			v = VRAM{TotalGB: total, UsedGB: used, Source: "nvidia-smi"}
+		} else if total, err := queryMetal(ctx); err == nil {
+			v = VRAM{TotalGB: total, Source: "metal", Unified: true}
+		}
+
+		var modelsVRAM float64
+		if ps != nil {
+			if running, err := ps(ctx); err == nil {
+				for _, m := range running {
+					lm := LoadedModel{Name: m.Name, SizeGB: bytesToGB(m.Size), VRAMGB: bytesToGB(m.SizeVRAM)}
+					v.Models = append(v.Models, lm)
+					modelsVRAM += lm.VRAMGB
+				}
+				if v.Source == "" && modelsVRAM > 0 {
+					v.Source = "ollama"
+				}
+			}
+		}
+		if v.Source == "" {
+			return VRAM{}, ErrNoGPU
+		}
+		if v.Source != "nvidia-smi" {
+			v.UsedGB = modelsVRAM
+		}
+		return v, nil
+	}
+}
+
+// queryNvidiaSMI returns the total and used memory of the NVIDIA GPUs.
+func queryNvidiaSMI(ctx context.Context) (totalGB, usedGB float64, err error) {
+	out, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=memory.total,memory.used", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseNvidiaSMI(string(out))
+}
+
+// parseNvidiaSMI sums the "total, used" MiB lines of nvidia-smi over the GPUs.
+func parseNvidiaSMI(out string) (totalGB, usedGB float64, err error) {
+	gpus := 0
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			continue
+		}
+		total, err1 := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		used, err2 := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		totalGB += total / 1024
+		usedGB += used / 1024
+		gpus++
+	}
+	if gpus == 0 {
+		return 0, 0, fmt.Errorf("unexpected nvidia-smi output %q", out)
+	}
+	return totalGB, usedGB, nil
+}
+
+// queryMetal returns the memory the GPU of an Apple silicon Mac may use:
+// iogpu.wired_limit_mb when set, otherwise macOS's default share of RAM.
+func queryMetal(ctx context.Context) (float64, error) {
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "arm64" {
+		return 0, ErrNoGPU
+	}
+	if out, err := exec.CommandContext(ctx, "sysctl", "-n", "iogpu.wired_limit_mb").Output(); err == nil {
+		if mb, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64); err == nil && mb > 0 {
+			return mb / 1024, nil
+		}
+	}
+	out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0, err
+	}
+	memBytes, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return metalWorkingSet(bytesToGB(memBytes)), nil
+}
+
+// metalWorkingSet returns macOS's default GPU limit for a Mac with ramGB:
+// two thirds of RAM up to 36 GB, three quarters above.
+func metalWorkingSet(ramGB float64) float64 {
+	if ramGB <= 36 {
+		return ramGB * 2 / 3
+	}
+	return ramGB * 3 / 4
+}
+
+func bytesToGB(b int64) float64 {
+	return float64(b) / (1024 * 1024 * 1024)
+}
@@ -0,0 +1,97 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/croberts/obot/internal/ollama"
+)
+
+func TestParseNvidiaSMI(t *testing.T) {
+	total, used, err := parseNvidiaSMI("24576, 2048\n24576, 10240\n")
+	if err != nil {
+		t.Fatalf("parseNvidiaSMI: %v", err)
+	}
+	if total != 48 || used != 12 {
+		t.Errorf("parseNvidiaSMI = %v, %v GB; want 48, 12", total, used)
+	}
+	if _, _, err := parseNvidiaSMI("No devices were found"); err == nil {
+		t.Error("parseNvidiaSMI accepted output without GPUs")
+	}
+}
+
+func TestMetalWorkingSet(t *testing.T) {
+	if got := metalWorkingSet(24); got != 16 {
+		t.Errorf("metalWorkingSet(24) = %v, want 16", got)
+	}
+	if got := metalWorkingSet(64); got != 48 {
+		t.Errorf("metalWorkingSet(64) = %v, want 48", got)
+	}
+}
+
+func TestMonitor_VRAMPressure(t *testing.T) {
+	m := NewMonitor()
+	if err := m.UpdateVRAM(context.Background()); !errors.Is(err, ErrNoGPU) {
+		t.Fatalf("UpdateVRAM without a probe = %v, want ErrNoGPU", err)
+	}
+
+	used := 10.0
+	m.SetGPUProbe(func(ctx context.Context) (VRAM, error) {
+		return VRAM{TotalGB: 24, UsedGB: used, Source: "nvidia-smi", Models: []LoadedModel{{Name: "qwen3:14b", SizeGB: 9, VRAMGB: 9}}}, nil
+	})
+	if err := m.UpdateVRAM(context.Background()); err != nil {
+		t.Fatalf("UpdateVRAM: %v", err)
+	}
+	if p := m.GetVRAMPressure(); p != PressureNormal {
+		t.Errorf("pressure at 10/24 GB = %s, want normal", p)
+	}
+
+	used = 23.5
+	m.UpdateVRAM(context.Background())
+	if p := m.GetVRAMPressure(); p != PressureCritical {
+		t.Errorf("pressure at 23.5/24 GB = %s, want critical", p)
+	}
+	// RAM pressure is tracked apart
+	if p := m.GetPressureStatus(); p == PressureCritical {
+		t.Errorf("RAM pressure = %s, want VRAM kept apart", p)
+	}
+
+	s := m.GetSummary().VRAM
+	if s.Peak != 23.5 || s.Critical != 1 || s.Source != "nvidia-smi" {
+		t.Errorf("VRAM summary = %+v", s)
+	}
+}
+
+func TestMonitor_PredictVRAM(t *testing.T) {
+	m := NewMonitor()
+	m.SetGPUProbe(func(ctx context.Context) (VRAM, error) {
+		return VRAM{TotalGB: 24, UsedGB: 18, Source: "nvidia-smi", Models: []LoadedModel{{Name: "qwen3:14b", SizeGB: 9, VRAMGB: 9}}}, nil
+	})
+	m.UpdateVRAM(context.Background())
+
+	if p := m.PredictVRAM("qwen3:14b", 9); !p.Loaded || p.VRAMGB != 18 || p.SpillGB != 0 {
+		t.Errorf("PredictVRAM(loaded) = %+v", p)
+	}
+	if p := m.PredictVRAM("qwen2.5-coder:14b", 9); p.Loaded || p.VRAMGB != 24 || p.SpillGB != 3 {
+		t.Errorf("PredictVRAM(new) = %+v, want 24 GB in VRAM and 3 GB spilled", p)
+	}
+}
+
+func TestNewGPUProbe_OllamaModels(t *testing.T) {
+	probe := NewGPUProbe(func(ctx context.Context) ([]ollama.RunningModel, error) {
+		return []ollama.RunningModel{{Name: "llava:13b", Size: 8 << 30, SizeVRAM: 6 << 30}}, nil
+	})
+	v, err := probe(context.Background())
+	if err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	m, ok := v.Loaded("llava:13b")
+	if !ok || m.SizeGB != 8 || m.VRAMGB != 6 {
+		t.Errorf("Loaded(llava:13b) = %+v, %v", m, ok)
+	}
+	// Without nvidia-smi the models' VRAM is the VRAM used
+	if v.Source != "nvidia-smi" && v.UsedGB != 6 {
+		t.Errorf("UsedGB = %v, want 6", v.UsedGB)
+	}
+}
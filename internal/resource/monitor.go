@@ -2,14 +2,21 @@
 package resource
 
 import (
+	"context"
 	"fmt"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ring"
 )
 
+// historySamples is how many memory samples the monitor keeps: a little
+// over 8 minutes at its 500ms interval.
+const historySamples = 1000
+
 // Monitor tracks resource usage including memory, disk, tokens, and time.
 type Monitor struct {
 	mu sync.Mutex
@@ -20,15 +27,31 @@ type Monitor struct {
 	memTotal      float64
 	predictedGB   float64
 
+	// GPU memory, tracked apart from system RAM since a model that does not
+	// fit in VRAM spills into RAM and slows down
+	gpuProbe       GPUProbe
+	vram           VRAM
+	vramPeak       float64
+	vramSampled    time.Time
+	vramWarnings   int
+	vramCriticals  int
+
 	// Memory history for prediction
 	memoryHistory map[orchestrate.ScheduleID]map[orchestrate.ProcessID][]float64
 
+	// Prediction feedback: the peak of the running process, the memory
+	// reserved for prediction errors, and how accurate predictions were
+	procPeak           float64
+	safetyMarginGB     float64
+	predictionAccuracy float64
+
 	// Disk tracking
 	diskWritten   int64
 	diskDeleted   int64
 
 	// Token tracking
 	tokenCounts   map[orchestrate.ScheduleID]map[orchestrate.ProcessID]int64
+	tokenFlow     []ProcessTokens
 	tokensUsed    int64
 
 	// Time tracking
@@ -41,6 +64,9 @@ type Monitor struct {
 	warningEvents  int
 	criticalEvents int
 
+	// Quality traded for memory under pressure
+	degradations []Degradation
+
 	// Limits
 	memLimit        *float64
 	diskLimit       *int64
@@ -52,7 +78,7 @@ type Monitor struct {
 	criticalThreshold float64 // Percentage (0.95 = 95%)
 
 	// General history
-	history []float64
+	history *ring.Buffer[float64]
 
 	// Background monitoring
 	stopCh chan struct{}
@@ -90,9 +116,20 @@ func (m *Monitor) monitorLoop() {
 	}
 }
 
+// vramSampleInterval spaces out GPU probes, which run nvidia-smi.
+const vramSampleInterval = 5 * time.Second
+
 // sample collects resource metrics and checks limits.
 func (m *Monitor) sample() {
 	m.UpdateMemory()
+	m.mu.Lock()
+	due := m.gpuProbe != nil && time.Since(m.vramSampled) >= vramSampleInterval
+	m.mu.Unlock()
+	if due {
+		ctx, cancel := context.WithTimeout(context.Background(), vramSampleInterval)
+		_ = m.UpdateVRAM(ctx)
+		cancel()
+	}
 	// Additional sampling like disk/tokens could be added here
 	_ = m.CheckLimits()
 }
@@ -173,7 +210,7 @@ func NewMonitorWithConfig(config *Config) *Monitor {
 		memTotal:          memTotal,
 		memoryHistory:     make(map[orchestrate.ScheduleID]map[orchestrate.ProcessID][]float64),
 		tokenCounts:       make(map[orchestrate.ScheduleID]map[orchestrate.ProcessID]int64),
-		history:           make([]float64, 0, 1000),
+		history:           ring.New[float64](historySamples),
 		startTime:         time.Now(),
 		memLimit:          config.MemoryLimitGB,
 		diskLimit:         config.DiskLimitBytes,
@@ -196,12 +233,12 @@ func (m *Monitor) UpdateMemory() {
 	if m.memCurrent > m.memPeak {
 		m.memPeak = m.memCurrent
 	}
+	if m.memCurrent > m.procPeak {
+		m.procPeak = m.memCurrent
+	}
 
 	// Add to history
-	m.history = append(m.history, m.memCurrent)
-	if len(m.history) > 1000 {
-		m.history = m.history[1:]
-	}
+	m.history.Push(m.memCurrent)
 
 	// Check pressure
 	m.checkPressure()
@@ -222,6 +259,98 @@ func (m *Monitor) checkPressure() {
 	}
 }
 
+// SetGPUProbe sets how GPU memory is read; nil stops tracking it.
+func (m *Monitor) SetGPUProbe(probe GPUProbe) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gpuProbe = probe
+}
+
+// UpdateVRAM probes the GPU memory and checks its pressure.
+func (m *Monitor) UpdateVRAM(ctx context.Context) error {
+	m.mu.Lock()
+	probe := m.gpuProbe
+	m.vramSampled = time.Now()
+	m.mu.Unlock()
+	if probe == nil {
+		return ErrNoGPU
+	}
+
+	v, err := probe(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vram = v
+	if v.UsedGB > m.vramPeak {
+		m.vramPeak = v.UsedGB
+	}
+	switch m.pressure(v.UsedGB, v.TotalGB) {
+	case PressureCritical:
+		m.vramCriticals++
+	case PressureWarning:
+		m.vramWarnings++
+	}
+	return nil
+}
+
+// GetVRAM returns the GPU memory last probed.
+func (m *Monitor) GetVRAM() VRAM {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.vram
+}
+
+// GetVRAMPressure returns the GPU memory pressure, normal when unknown.
+func (m *Monitor) GetVRAMPressure() PressureStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pressure(m.vram.UsedGB, m.vram.TotalGB)
+}
+
+// VRAMPrediction is the GPU memory expected once a model is loaded, and
+// the part of the model that will not fit and spills into system RAM.
+type VRAMPrediction struct {
+	VRAMGB  float64
+	SpillGB float64
+	Loaded  bool // the model is already in memory
+}
+
+// PredictVRAM predicts the GPU memory in use once the model, of sizeGB,
+// serves the next request.
+func (m *Monitor) PredictVRAM(model string, sizeGB float64) VRAMPrediction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v := m.vram
+	if _, ok := v.Loaded(model); ok {
+		return VRAMPrediction{VRAMGB: v.UsedGB, Loaded: true}
+	}
+	p := VRAMPrediction{VRAMGB: v.UsedGB + sizeGB}
+	if v.TotalGB > 0 && p.VRAMGB > v.TotalGB {
+		p.SpillGB = p.VRAMGB - v.TotalGB
+		p.VRAMGB = v.TotalGB
+	}
+	return p
+}
+
+// pressure classifies used of total against the thresholds.
+func (m *Monitor) pressure(used, total float64) PressureStatus {
+	if total <= 0 {
+		return PressureNormal
+	}
+	ratio := used / total
+	if ratio >= m.criticalThreshold {
+		return PressureCritical
+	}
+	if ratio >= m.warningThreshold {
+		return PressureWarning
+	}
+	return PressureNormal
+}
+
 // GetCurrentMemory returns the current memory usage in GB
 func (m *Monitor) GetCurrentMemory() float64 {
 	m.mu.Lock()
@@ -236,6 +365,13 @@ func (m *Monitor) GetPeakMemory() float64 {
 	return m.memPeak
 }
 
+// GetHistory returns the last n memory samples in GB, oldest first.
+func (m *Monitor) GetHistory(n int) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.history.AppendLast(nil, n)
+}
+
 // GetTotalMemory returns the total system memory in GB
 func (m *Monitor) GetTotalMemory() float64 {
 	m.mu.Lock()
@@ -296,6 +432,48 @@ func (m *Monitor) GetPredictedMemory() float64 {
 	return m.predictedGB
 }
 
+// BeginProcess starts tracking the peak memory of a process.
+func (m *Monitor) BeginProcess() {
+	m.UpdateMemory()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.procPeak = m.memCurrent
+}
+
+// EndProcess returns the peak memory since BeginProcess and records it in
+// the history PredictMemory draws on.
+func (m *Monitor) EndProcess(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) float64 {
+	m.UpdateMemory()
+	m.mu.Lock()
+	peak := m.procPeak
+	m.mu.Unlock()
+	m.RecordMemoryForProcess(scheduleID, processID, peak)
+	return peak
+}
+
+// SetSafetyMargin reserves gb on top of the memory in use when judging
+// Pressure, so mitigations start earlier when predictions have missed.
+func (m *Monitor) SetSafetyMargin(gb float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.safetyMarginGB = max(gb, 0)
+}
+
+// GetSafetyMargin returns the memory reserved for prediction errors in GB.
+func (m *Monitor) GetSafetyMargin() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.safetyMarginGB
+}
+
+// SetPredictionAccuracy sets how accurate memory predictions were, from 0
+// to 1, for the summary.
+func (m *Monitor) SetPredictionAccuracy(accuracy float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.predictionAccuracy = accuracy
+}
+
 // RecordDiskWrite records bytes written to disk
 func (m *Monitor) RecordDiskWrite(bytes int64) {
 	m.mu.Lock()
@@ -310,7 +488,8 @@ func (m *Monitor) RecordDiskDelete(bytes int64) {
 	m.diskDeleted += bytes
 }
 
-// RecordTokens records token usage
+// RecordTokens records the tokens used by one run of a process. The runs
+// are kept in order as the token flow of the summary.
 func (m *Monitor) RecordTokens(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID, tokens int64) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -319,6 +498,7 @@ func (m *Monitor) RecordTokens(scheduleID orchestrate.ScheduleID, processID orch
 		m.tokenCounts[scheduleID] = make(map[orchestrate.ProcessID]int64)
 	}
 	m.tokenCounts[scheduleID][processID] += tokens
+	m.tokenFlow = append(m.tokenFlow, ProcessTokens{Schedule: scheduleID, Process: processID, Tokens: tokens})
 	m.tokensUsed += tokens
 }
 
@@ -420,19 +600,7 @@ func (m *Monitor) GetPressureStatus() PressureStatus {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.memTotal <= 0 {
-		return PressureNormal
-	}
-
-	ratio := m.memCurrent / m.memTotal
-
-	if ratio >= m.criticalThreshold {
-		return PressureCritical
-	}
-	if ratio >= m.warningThreshold {
-		return PressureWarning
-	}
-	return PressureNormal
+	return m.pressure(m.memCurrent, m.memTotal)
 }
 
 // PressureStatus represents memory pressure status
@@ -458,9 +626,13 @@ func (e *LimitExceededError) Error() string {
 // ResourceSummary generates a resource summary
 type ResourceSummary struct {
 	Memory MemorySummary
+	VRAM   VRAMSummary
 	Disk   DiskSummary
 	Tokens TokenSummary
 	Time   TimeSummary
+
+	// Degradations are the steps taken under memory pressure
+	Degradations []Degradation
 }
 
 // MemorySummary contains memory statistics
@@ -475,7 +647,18 @@ type MemorySummary struct {
 	LimitGB            *float64 // Compatibility
 	PressureWarnings   int     // Compatibility
 	PressureCritical   int     // Compatibility
-	PredictionAccuracy float64 // Compatibility
+	PredictionAccuracy float64 // 0 until a prediction was checked
+}
+
+// VRAMSummary contains GPU memory statistics; Source is empty when no GPU
+// was probed.
+type VRAMSummary struct {
+	Source   string
+	Peak     float64
+	Current  float64
+	Total    float64
+	Warnings int
+	Critical int
 }
 
 // DiskSummary contains disk statistics
@@ -495,6 +678,14 @@ type TokenSummary struct {
 	Limit      *int64
 	BySchedule map[orchestrate.ScheduleID]int64
 	ByProcess  map[orchestrate.ScheduleID]map[orchestrate.ProcessID]int64
+	Flow       []ProcessTokens // each process run, in order
+}
+
+// ProcessTokens is the tokens used by one run of a process
+type ProcessTokens struct {
+	Schedule orchestrate.ScheduleID
+	Process  orchestrate.ProcessID
+	Tokens   int64
 }
 
 // TimeSummary contains time statistics
@@ -536,7 +727,15 @@ func (m *Monitor) GetSummary() *ResourceSummary {
 			LimitGB:            m.memLimit,
 			PressureWarnings:   m.warningEvents,
 			PressureCritical:   m.criticalEvents,
-			PredictionAccuracy: 0.87,
+			PredictionAccuracy: m.predictionAccuracy,
+		},
+		VRAM: VRAMSummary{
+			Source:   m.vram.Source,
+			Peak:     m.vramPeak,
+			Current:  m.vram.UsedGB,
+			Total:    m.vram.TotalGB,
+			Warnings: m.vramWarnings,
+			Critical: m.vramCriticals,
 		},
 		Disk: DiskSummary{
 			Written:           m.diskWritten,
@@ -552,6 +751,7 @@ func (m *Monitor) GetSummary() *ResourceSummary {
 			Limit:      m.tokenLimit,
 			BySchedule: bySchedule,
 			ByProcess:  byProcess,
+			Flow:       slices.Clone(m.tokenFlow),
 		},
 		Time: TimeSummary{
 			Elapsed:       time.Since(m.startTime),
@@ -561,5 +761,6 @@ func (m *Monitor) GetSummary() *ResourceSummary {
 			HumanWait:     m.humanWaitTime,
 			Orchestrator:  m.orchestratorTime,
 		},
+		Degradations: append([]Degradation(nil), m.degradations...),
 	}
 }
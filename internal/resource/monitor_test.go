@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -46,6 +47,12 @@ func TestMonitor_UpdateMemory(t *testing.T) {
 	if curr < 0 {
 		t.Errorf("GetCurrentMemory should be non-negative, got %v", curr)
 	}
+	for i := 0; i < historySamples+5; i++ {
+		m.UpdateMemory()
+	}
+	if n := len(m.GetHistory(historySamples * 2)); n != historySamples {
+		t.Errorf("history holds %d samples, want %d", n, historySamples)
+	}
 }
 
 func TestMonitor_RecordTokens(t *testing.T) {
@@ -55,6 +62,20 @@ func TestMonitor_RecordTokens(t *testing.T) {
 	if m.GetTotalTokens() != 150 {
 		t.Errorf("GetTotalTokens: got %d", m.GetTotalTokens())
 	}
+	m.RecordTokens(orchestrate.ScheduleImplement, orchestrate.Process2, 30)
+
+	sum := m.GetSummary()
+	if sum.Tokens.ByProcess[orchestrate.ScheduleImplement][orchestrate.Process1] != 150 {
+		t.Errorf("ByProcess: got %v", sum.Tokens.ByProcess)
+	}
+	want := []ProcessTokens{
+		{orchestrate.ScheduleImplement, orchestrate.Process1, 100},
+		{orchestrate.ScheduleImplement, orchestrate.Process1, 50},
+		{orchestrate.ScheduleImplement, orchestrate.Process2, 30},
+	}
+	if !slices.Equal(sum.Tokens.Flow, want) {
+		t.Errorf("Flow: got %v, want %v", sum.Tokens.Flow, want)
+	}
 }
 
 func TestMonitor_RecordDiskWriteDelete(t *testing.T) {
@@ -91,6 +112,19 @@ func TestMonitor_GetSummary(t *testing.T) {
 	}
 }
 
+func TestMonitor_RecordTime(t *testing.T) {
+	m := NewMonitor()
+	m.RecordAgentTime(3 * time.Second)
+	m.RecordAgentTime(2 * time.Second)
+	m.RecordHumanWaitTime(4 * time.Second)
+	m.RecordOrchestratorTime(time.Second)
+
+	got := m.GetSummary().Time
+	if got.AgentActive != 5*time.Second || got.HumanWait != 4*time.Second || got.Orchestrator != time.Second {
+		t.Errorf("Time: got agent %s, human %s, orchestrator %s", got.AgentActive, got.HumanWait, got.Orchestrator)
+	}
+}
+
 func TestMonitor_GetPressureStatus(t *testing.T) {
 	m := NewMonitor()
 	status := m.GetPressureStatus()
@@ -139,3 +173,20 @@ func TestMonitor_PredictMemory(t *testing.T) {
 		t.Errorf("PredictMemory with history: got %v", pred)
 	}
 }
+
+func TestMonitor_ProcessPeakAndMargin(t *testing.T) {
+	m := NewMonitor()
+	m.BeginProcess()
+	peak := m.EndProcess(orchestrate.ScheduleImplement, orchestrate.Process2)
+	if peak <= 0 {
+		t.Fatalf("EndProcess() = %v, want the process peak", peak)
+	}
+	if pred := m.PredictMemory(orchestrate.ScheduleImplement, orchestrate.Process2); pred != peak {
+		t.Errorf("PredictMemory after EndProcess = %v, want %v", pred, peak)
+	}
+
+	m.SetSafetyMargin(m.GetTotalMemory())
+	if level, res := m.Pressure(); level != PressureCritical || res != "memory" {
+		t.Errorf("Pressure() with a margin of all memory = %s, %s; want critical memory", level, res)
+	}
+}
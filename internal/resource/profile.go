@@ -0,0 +1,184 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// maxProfileSamples is how many runs of a process a profile keeps.
+const maxProfileSamples = 10
+
+// ProcessProfile is the memory and duration of a process run by a model,
+// over its most recent runs.
+type ProcessProfile struct {
+	Schedule  orchestrate.ScheduleID `json:"schedule"`
+	Process   orchestrate.ProcessID  `json:"process"`
+	Model     string                 `json:"model"`
+	MemoryGB  []float64              `json:"memory_gb"`
+	Durations []time.Duration        `json:"durations"`
+	Updated   time.Time              `json:"updated"`
+}
+
+// AverageMemoryGB returns the average peak memory of the recorded runs.
+func (p *ProcessProfile) AverageMemoryGB() float64 {
+	if len(p.MemoryGB) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range p.MemoryGB {
+		sum += v
+	}
+	return sum / float64(len(p.MemoryGB))
+}
+
+// AverageDuration returns the average duration of the recorded runs.
+func (p *ProcessProfile) AverageDuration() time.Duration {
+	if len(p.Durations) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range p.Durations {
+		sum += d
+	}
+	return sum / time.Duration(len(p.Durations))
+}
+
+// Profiles stores process profiles across sessions, so that predictions
+// start from past runs instead of defaults.
+type Profiles struct {
+	mu       sync.Mutex
+	profiles map[string]*ProcessProfile
+	path     string
+}
+
+// NewProfiles creates a profile store persisted at storePath, loading the
+// profiles already there. An empty path keeps them in memory.
+func NewProfiles(storePath string) *Profiles {
+	p := &Profiles{
+		profiles: make(map[string]*ProcessProfile),
+		path:     storePath,
+	}
+	_ = p.load()
+	return p
+}
+
+func profileKey(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID, model string) string {
+	return fmt.Sprintf("S%dP%d:%s", scheduleID, processID, model)
+}
+
+// Record adds a run of a process by model, keeping the most recent runs.
+func (p *Profiles) Record(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID, model string, memoryGB float64, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := profileKey(scheduleID, processID, model)
+	prof := p.profiles[key]
+	if prof == nil {
+		prof = &ProcessProfile{Schedule: scheduleID, Process: processID, Model: model}
+		p.profiles[key] = prof
+	}
+	prof.MemoryGB = append(prof.MemoryGB, memoryGB)
+	if len(prof.MemoryGB) > maxProfileSamples {
+		prof.MemoryGB = prof.MemoryGB[1:]
+	}
+	prof.Durations = append(prof.Durations, duration)
+	if len(prof.Durations) > maxProfileSamples {
+		prof.Durations = prof.Durations[1:]
+	}
+	prof.Updated = time.Now()
+}
+
+// Get returns a copy of the profile of a process run by model.
+func (p *Profiles) Get(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID, model string) (ProcessProfile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prof, ok := p.profiles[profileKey(scheduleID, processID, model)]
+	if !ok || len(prof.MemoryGB) == 0 {
+		return ProcessProfile{}, false
+	}
+	c := *prof
+	c.MemoryGB = append([]float64(nil), prof.MemoryGB...)
+	c.Durations = append([]time.Duration(nil), prof.Durations...)
+	return c, true
+}
+
+// ForProcess returns copies of the profiles of a process, one per model it
+// was run by.
+func (p *Profiles) ForProcess(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) []ProcessProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out []ProcessProfile
+	for _, prof := range p.profiles {
+		if prof.Schedule != scheduleID || prof.Process != processID || len(prof.Durations) == 0 {
+			continue
+		}
+		c := *prof
+		c.MemoryGB = append([]float64(nil), prof.MemoryGB...)
+		c.Durations = append([]time.Duration(nil), prof.Durations...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// Save persists the profiles to disk.
+func (p *Profiles) Save() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+
+	list := make([]*ProcessProfile, 0, len(p.profiles))
+	for _, prof := range p.profiles {
+		list = append(list, prof)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Schedule != b.Schedule {
+			return a.Schedule < b.Schedule
+		}
+		if a.Process != b.Process {
+			return a.Process < b.Process
+		}
+		return a.Model < b.Model
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path, data, 0644)
+}
+
+// load reads the profiles from disk.
+func (p *Profiles) load() error {
+	if p.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil // not an error if the file doesn't exist
+	}
+
+	var list []*ProcessProfile
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	for _, prof := range list {
+		p.profiles[profileKey(prof.Schedule, prof.Process, prof.Model)] = prof
+	}
+	return nil
+}
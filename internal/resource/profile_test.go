@@ -0,0 +1,45 @@
+package resource
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestProfiles_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	p := NewProfiles(path)
+	p.Record(orchestrate.ScheduleImplement, orchestrate.Process1, "qwen2.5-coder:14b", 2.0, 10*time.Second)
+	p.Record(orchestrate.ScheduleImplement, orchestrate.Process1, "qwen2.5-coder:14b", 4.0, 20*time.Second)
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := NewProfiles(path)
+	prof, ok := loaded.Get(orchestrate.ScheduleImplement, orchestrate.Process1, "qwen2.5-coder:14b")
+	if !ok {
+		t.Fatal("profile not loaded")
+	}
+	if got := prof.AverageMemoryGB(); got != 3.0 {
+		t.Errorf("AverageMemoryGB() = %v, want 3", got)
+	}
+	if got := prof.AverageDuration(); got != 15*time.Second {
+		t.Errorf("AverageDuration() = %v, want 15s", got)
+	}
+	if _, ok := loaded.Get(orchestrate.ScheduleImplement, orchestrate.Process1, "llama3:8b"); ok {
+		t.Error("profile found for a model that never ran the process")
+	}
+}
+
+func TestProfiles_KeepsRecentRuns(t *testing.T) {
+	p := NewProfiles("")
+	for i := 0; i < maxProfileSamples+5; i++ {
+		p.Record(orchestrate.ScheduleKnowledge, orchestrate.Process2, "m", float64(i), time.Second)
+	}
+	prof, _ := p.Get(orchestrate.ScheduleKnowledge, orchestrate.Process2, "m")
+	if len(prof.MemoryGB) != maxProfileSamples || prof.MemoryGB[0] != 5 {
+		t.Errorf("kept %v, want the last %d runs", prof.MemoryGB, maxProfileSamples)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/croberts/obot/internal/fsutil"
+	"github.com/croberts/obot/internal/fswalk"
 )
 
 type Issue struct {
@@ -77,36 +78,15 @@ func ScanPath(path string, opts Options) ([]Issue, error) {
 	issues := make([]Issue, 0, 64)
 	stopErr := errors.New("max issues reached")
 
-	err = filepath.WalkDir(absPath, func(filePath string, d os.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-		name := d.Name()
-		if d.IsDir() {
-			if fsutil.ShouldSkipDir(name, opts.IncludeHidden, opts.IgnoreDirs) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		if fsutil.ShouldSkipFile(name, opts.IncludeHidden, opts.IgnoreExts) {
-			return nil
-		}
-
-		info, err := d.Info()
-		if err != nil {
-			return nil
-		}
-		if info.Size() > opts.MaxFileSize {
-			return nil
-		}
-
-		isBinary, err := fsutil.IsBinaryFile(filePath)
-		if err != nil || isBinary {
-			return nil
-		}
-
-		fileIssues, err := ScanFile(filePath, opts, absPath)
+	walkOpts := fswalk.Options{
+		IncludeHidden: opts.IncludeHidden,
+		IgnoreDirs:    opts.IgnoreDirs,
+		IgnoreExts:    opts.IgnoreExts,
+		MaxFileSize:   opts.MaxFileSize,
+		SkipBinary:    true,
+	}
+	err = fswalk.Walk(absPath, walkOpts, func(e fswalk.Entry) error {
+		fileIssues, err := ScanFile(e.Path, opts, absPath)
 		if err != nil {
 			return nil
 		}
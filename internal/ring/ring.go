@@ -0,0 +1,140 @@
+// Package ring implements a fixed-capacity ring buffer for sample
+// histories, with O(1) appends at either end and windowed iteration.
+package ring
+
+import "iter"
+
+// Buffer holds up to a fixed number of values, oldest first. Pushing onto
+// a full buffer evicts the oldest value instead of growing, so a history
+// sampled many times a second allocates only once.
+type Buffer[T any] struct {
+	buf   []T
+	start int // index of the oldest value
+	n     int
+}
+
+// New returns an empty buffer holding up to capacity values.
+func New[T any](capacity int) *Buffer[T] {
+	return &Buffer[T]{buf: make([]T, max(capacity, 0))}
+}
+
+// Len returns the number of values held.
+func (b *Buffer[T]) Len() int { return b.n }
+
+// Cap returns the number of values the buffer holds before evicting.
+func (b *Buffer[T]) Cap() int { return len(b.buf) }
+
+// index maps the i-th value, oldest first, to its slot.
+func (b *Buffer[T]) index(i int) int {
+	return (b.start + i) % len(b.buf)
+}
+
+// Push appends v as the newest value. When the buffer is full it evicts
+// and returns the oldest value, with evicted true; a buffer of no capacity
+// evicts v itself.
+func (b *Buffer[T]) Push(v T) (old T, evicted bool) {
+	if len(b.buf) == 0 {
+		return v, true
+	}
+	if b.n == len(b.buf) {
+		old = b.buf[b.start]
+		b.buf[b.start] = v
+		b.start = b.index(1)
+		return old, true
+	}
+	b.buf[b.index(b.n)] = v
+	b.n++
+	return old, false
+}
+
+// PopFront removes and returns the oldest value.
+func (b *Buffer[T]) PopFront() (v T, ok bool) {
+	if b.n == 0 {
+		return v, false
+	}
+	var zero T
+	v = b.buf[b.start]
+	b.buf[b.start] = zero
+	b.start = b.index(1)
+	b.n--
+	return v, true
+}
+
+// PopBack removes and returns the newest value.
+func (b *Buffer[T]) PopBack() (v T, ok bool) {
+	if b.n == 0 {
+		return v, false
+	}
+	var zero T
+	i := b.index(b.n - 1)
+	v = b.buf[i]
+	b.buf[i] = zero
+	b.n--
+	return v, true
+}
+
+// At returns the i-th value, oldest first. It panics if i is out of range.
+func (b *Buffer[T]) At(i int) T {
+	if i < 0 || i >= b.n {
+		panic("ring: index out of range")
+	}
+	return b.buf[b.index(i)]
+}
+
+// Front returns the oldest value.
+func (b *Buffer[T]) Front() (v T, ok bool) {
+	if b.n == 0 {
+		return v, false
+	}
+	return b.buf[b.start], true
+}
+
+// Back returns the newest value.
+func (b *Buffer[T]) Back() (v T, ok bool) {
+	if b.n == 0 {
+		return v, false
+	}
+	return b.buf[b.index(b.n-1)], true
+}
+
+// All iterates over the values, oldest first.
+func (b *Buffer[T]) All() iter.Seq[T] {
+	return b.Last(b.n)
+}
+
+// Last iterates over the newest n values, oldest first.
+func (b *Buffer[T]) Last(n int) iter.Seq[T] {
+	n = min(max(n, 0), b.n)
+	return func(yield func(T) bool) {
+		for i := b.n - n; i < b.n; i++ {
+			if !yield(b.buf[b.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// AppendLast appends the newest n values, oldest first, to dst.
+func (b *Buffer[T]) AppendLast(dst []T, n int) []T {
+	for v := range b.Last(n) {
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// Reset empties the buffer, keeping its capacity.
+func (b *Buffer[T]) Reset() {
+	clear(b.buf)
+	b.start, b.n = 0, 0
+}
+
+// Resize changes the capacity, keeping the newest values that fit.
+func (b *Buffer[T]) Resize(capacity int) {
+	capacity = max(capacity, 0)
+	if capacity == len(b.buf) {
+		return
+	}
+	keep := b.AppendLast(make([]T, 0, capacity), capacity)
+	b.buf = make([]T, capacity)
+	b.start, b.n = 0, copy(b.buf, keep)
+}
@@ -0,0 +1,80 @@
+package ring
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuffer_PushEvictsOldest(t *testing.T) {
+	b := New[int](3)
+	for i := 1; i <= 3; i++ {
+		if _, evicted := b.Push(i); evicted {
+			t.Fatalf("Push(%d) evicted from a buffer with room", i)
+		}
+	}
+	if old, evicted := b.Push(4); !evicted || old != 1 {
+		t.Fatalf("Push(4) = %d, %v; want 1 evicted", old, evicted)
+	}
+	if got := slices.Collect(b.All()); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("All() = %v, want [2 3 4]", got)
+	}
+	if got := b.AppendLast(nil, 2); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("AppendLast(2) = %v, want [3 4]", got)
+	}
+	if b.At(0) != 2 || b.Len() != 3 || b.Cap() != 3 {
+		t.Errorf("At(0) = %d, Len() = %d, Cap() = %d", b.At(0), b.Len(), b.Cap())
+	}
+}
+
+func TestBuffer_Deque(t *testing.T) {
+	b := New[int](4)
+	for i := 1; i <= 6; i++ {
+		b.Push(i)
+	}
+	// Holds 3, 4, 5, 6 with the start wrapped around
+	if v, _ := b.PopFront(); v != 3 {
+		t.Errorf("PopFront() = %d, want 3", v)
+	}
+	if v, _ := b.PopBack(); v != 6 {
+		t.Errorf("PopBack() = %d, want 6", v)
+	}
+	if f, _ := b.Front(); f != 4 {
+		t.Errorf("Front() = %d, want 4", f)
+	}
+	if l, _ := b.Back(); l != 5 {
+		t.Errorf("Back() = %d, want 5", l)
+	}
+	b.PopFront()
+	b.PopFront()
+	if _, ok := b.PopBack(); ok {
+		t.Error("PopBack() on an empty buffer succeeded")
+	}
+}
+
+func TestBuffer_Resize(t *testing.T) {
+	b := New[int](5)
+	for i := 1; i <= 7; i++ {
+		b.Push(i)
+	}
+	b.Resize(3)
+	if got := slices.Collect(b.All()); !slices.Equal(got, []int{5, 6, 7}) {
+		t.Errorf("after shrinking, All() = %v, want [5 6 7]", got)
+	}
+	b.Resize(4)
+	b.Push(8)
+	if got := slices.Collect(b.All()); !slices.Equal(got, []int{5, 6, 7, 8}) {
+		t.Errorf("after growing, All() = %v, want [5 6 7 8]", got)
+	}
+	b.Reset()
+	if b.Len() != 0 || b.Cap() != 4 {
+		t.Errorf("after Reset, Len() = %d, Cap() = %d", b.Len(), b.Cap())
+	}
+}
+
+func TestBuffer_PushAllocatesNothing(t *testing.T) {
+	b := New[float64](100)
+	allocs := testing.AllocsPerRun(1000, func() { b.Push(1.5) })
+	if allocs != 0 {
+		t.Errorf("Push allocated %.1f times per call, want 0", allocs)
+	}
+}
@@ -0,0 +1,252 @@
+// Package sarif writes obot's findings, lint diagnostics, failed tests, and
+// judge issues, as SARIF 2.1.0 logs for GitHub code scanning and other
+// tools that ingest static analysis results.
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/judge"
+)
+
+const (
+	schemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	version   = "2.1.0"
+	toolURI   = "https://github.com/croberts/obot"
+)
+
+// Result levels
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Rule IDs of obot's findings
+const (
+	RuleLint       = "lint"
+	RuleTestFailed = "test-failed"
+	RuleJudgeIssue = "judge-issue"
+)
+
+var rules = []Rule{
+	{ID: RuleLint, ShortDescription: Message{Text: "Linter diagnostic"}},
+	{ID: RuleTestFailed, ShortDescription: Message{Text: "Failed test"}},
+	{ID: RuleJudgeIssue, ShortDescription: Message{Text: "Issue found by the expert judges"}},
+}
+
+// Log is a SARIF log of one run of obot.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the results of one tool invocation.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Tool describes the tool that produced a run.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver is the tool's main component and the rules its results refer to.
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule is a kind of finding.
+type Rule struct {
+	ID               string  `json:"id"`
+	ShortDescription Message `json:"shortDescription"`
+}
+
+// Result is one finding.
+type Result struct {
+	RuleID     string     `json:"ruleId"`
+	Level      string     `json:"level"`
+	Message    Message    `json:"message"`
+	Locations  []Location `json:"locations,omitempty"`
+	Properties Properties `json:"properties,omitempty"`
+}
+
+// Properties are extra details of a result.
+type Properties map[string]string
+
+// Message is a result's or rule's text.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Location is where a result was found.
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a file and optionally a region of it.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is a file's path relative to the repository root.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a position in a file; columns are 1-based and optional.
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// New returns an empty log for obot at toolVersion.
+func New(toolVersion string) *Log {
+	return &Log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs: []Run{{
+			Tool:    Tool{Driver: Driver{Name: "obot", Version: toolVersion, InformationURI: toolURI, Rules: rules}},
+			Results: []Result{},
+		}},
+	}
+}
+
+// Results returns the log's results.
+func (l *Log) Results() []Result {
+	return l.Runs[0].Results
+}
+
+func (l *Log) add(r Result) {
+	l.Runs[0].Results = append(l.Runs[0].Results, r)
+}
+
+// AddDiagnostics adds a result per lint diagnostic.
+func (l *Log) AddDiagnostics(toolchain string, diagnostics []agent.Diagnostic) {
+	for _, d := range diagnostics {
+		level := LevelWarning
+		switch strings.ToLower(d.Severity) {
+		case "error", "fatal":
+			level = LevelError
+		case "info", "note", "hint":
+			level = LevelNote
+		}
+		l.add(Result{
+			RuleID:     RuleLint,
+			Level:      level,
+			Message:    Message{Text: d.Message},
+			Locations:  location(d.Path, d.Line, d.Column),
+			Properties: Properties{"toolchain": toolchain},
+		})
+	}
+}
+
+// AddFailedTests adds a result per failed test, located at path, the file
+// or directory the tests ran for.
+func (l *Log) AddFailedTests(toolchain, path string, tests []agent.TestCase) {
+	for _, t := range tests {
+		if t.Status != agent.TestFailed {
+			continue
+		}
+		l.add(Result{
+			RuleID:     RuleTestFailed,
+			Level:      LevelError,
+			Message:    Message{Text: fmt.Sprintf("Test %s failed", t.Name)},
+			Locations:  location(path, 0, 0),
+			Properties: Properties{"toolchain": toolchain, "test": t.Name},
+		})
+	}
+}
+
+// AddJudgeIssues adds a result per judge issue. An issue's leading tags,
+// such as "[security] [high]", set its level, and a "path:line:" or "path:"
+// after them its location; the resolution is kept as a property.
+func (l *Log) AddJudgeIssues(issues []judge.Issue) {
+	for _, issue := range issues {
+		level, path, line := parseIssue(issue.Description)
+		r := Result{
+			RuleID:    RuleJudgeIssue,
+			Level:     level,
+			Message:   Message{Text: issue.Description},
+			Locations: location(path, line, 0),
+		}
+		if issue.Resolution != "" {
+			r.Properties = Properties{"resolution": issue.Resolution}
+		}
+		l.add(r)
+	}
+}
+
+// Write writes the log to path as indented JSON.
+func (l *Log) Write(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// location returns the location of path, relative to the working
+// directory when under it, or none when path is empty.
+func location(path string, line, column int) []Location {
+	if path == "" {
+		return nil
+	}
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	loc := Location{PhysicalLocation: PhysicalLocation{
+		ArtifactLocation: ArtifactLocation{URI: strings.TrimPrefix(filepath.ToSlash(path), "./")},
+	}}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &Region{StartLine: line, StartColumn: column}
+	}
+	return []Location{loc}
+}
+
+var (
+	issueTag      = regexp.MustCompile(`^\s*\[([^\]]+)\]`)
+	issueLocation = regexp.MustCompile(`^\s*([\w./-]+\.\w+)(?::(\d+))?:\s`)
+)
+
+// parseIssue reads a judge issue's level from its leading tags and its
+// location from a file reference after them.
+func parseIssue(desc string) (level, path string, line int) {
+	level = LevelWarning
+	rest := desc
+	for m := issueTag.FindStringSubmatch(rest); m != nil; m = issueTag.FindStringSubmatch(rest) {
+		for _, word := range strings.Fields(strings.ToLower(m[1])) {
+			switch word {
+			case "critical", "high":
+				level = LevelError
+			case "low", "info":
+				if level != LevelError {
+					level = LevelNote
+				}
+			}
+		}
+		rest = rest[len(m[0]):]
+	}
+	if m := issueLocation.FindStringSubmatch(rest); m != nil {
+		path = m[1]
+		line, _ = strconv.Atoi(m[2])
+	}
+	return level, path, line
+}
@@ -0,0 +1,82 @@
+package sarif
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/judge"
+)
+
+func TestParseIssue(t *testing.T) {
+	tests := []struct {
+		desc  string
+		level string
+		path  string
+		line  int
+	}{
+		{"[security] [high] db.go: SQL query concatenates the name parameter", LevelError, "db.go", 0},
+		{"[security] [medium] internal/config.go:42: API key is hardcoded", LevelWarning, "internal/config.go", 42},
+		{"[low license] mystery@0.1.0: license could not be determined", LevelNote, "", 0},
+		{"Handler lacks tests", LevelWarning, "", 0},
+	}
+	for _, tt := range tests {
+		level, path, line := parseIssue(tt.desc)
+		if level != tt.level || path != tt.path || line != tt.line {
+			t.Errorf("parseIssue(%q) = %q, %q, %d; want %q, %q, %d", tt.desc, level, path, line, tt.level, tt.path, tt.line)
+		}
+	}
+}
+
+func TestLog_Write(t *testing.T) {
+	log := New("1.2.3")
+	log.AddDiagnostics("go", []agent.Diagnostic{
+		{Path: "./main.go", Line: 12, Column: 3, Severity: "error", Message: "undefined: foo"},
+		{Path: "util.go", Line: 4, Message: "unused variable"},
+	})
+	log.AddFailedTests("go", "./api", []agent.TestCase{
+		{Name: "TestGet", Status: agent.TestPassed},
+		{Name: "TestPost", Status: agent.TestFailed},
+	})
+	log.AddJudgeIssues([]judge.Issue{{Description: "[security] [high] db.go: SQL injection", Resolution: "Use query parameters"}})
+
+	path := filepath.Join(t.TempDir(), "obot.sarif")
+	if err := log.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Log
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("written log is not valid JSON: %v", err)
+	}
+	if got.Version != "2.1.0" || got.Schema == "" || got.Runs[0].Tool.Driver.Version != "1.2.3" {
+		t.Errorf("header = %q %q %+v", got.Version, got.Schema, got.Runs[0].Tool.Driver)
+	}
+
+	results := got.Runs[0].Results
+	if len(results) != 4 {
+		t.Fatalf("results = %d, want 4: %+v", len(results), results)
+	}
+	first := results[0]
+	if first.RuleID != RuleLint || first.Level != LevelError || first.Message.Text != "undefined: foo" {
+		t.Errorf("lint result = %+v", first)
+	}
+	if loc := first.Locations[0].PhysicalLocation; loc.ArtifactLocation.URI != "main.go" || loc.Region.StartLine != 12 || loc.Region.StartColumn != 3 {
+		t.Errorf("lint location = %+v", loc)
+	}
+	if results[1].Level != LevelWarning {
+		t.Errorf("diagnostic without severity level = %q, want warning", results[1].Level)
+	}
+	if r := results[2]; r.RuleID != RuleTestFailed || r.Properties["test"] != "TestPost" || r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "api" {
+		t.Errorf("test result = %+v", r)
+	}
+	if r := results[3]; r.RuleID != RuleJudgeIssue || r.Level != LevelError || r.Properties["resolution"] != "Use query parameters" ||
+		r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "db.go" {
+		t.Errorf("judge result = %+v", r)
+	}
+}
@@ -0,0 +1,469 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coverage tools
+const (
+	CoverageGo     = "go test"
+	CoveragePython = "coverage.py"
+)
+
+// coverageTimeout bounds a coverage run, which runs the whole test suite.
+const coverageTimeout = 10 * time.Minute
+
+// Coverage counts statements and the statements of them the tests ran.
+type Coverage struct {
+	Statements int `json:"statements"`
+	Covered    int `json:"covered"`
+}
+
+// Percent returns the percentage of the statements covered; no statements
+// count as fully covered.
+func (c Coverage) Percent() float64 {
+	if c.Statements == 0 {
+		return 100
+	}
+	return float64(c.Covered) / float64(c.Statements) * 100
+}
+
+// Uncovered returns the number of statements the tests did not run.
+func (c Coverage) Uncovered() int {
+	return c.Statements - c.Covered
+}
+
+// PackageCoverage is the coverage of a package, named by its directory
+// relative to the project root.
+type PackageCoverage struct {
+	Package string `json:"package"`
+	Coverage
+}
+
+// FunctionCoverage is the coverage of a function or method.
+type FunctionCoverage struct {
+	Path     string `json:"path"` // relative to the project root
+	Line     int    `json:"line"`
+	Function string `json:"function"`
+	Coverage
+}
+
+// CoverageReport is the test coverage of a project.
+type CoverageReport struct {
+	Tool      string             `json:"tool"`
+	Packages  []PackageCoverage  `json:"packages"`
+	Functions []FunctionCoverage `json:"functions"`
+}
+
+// Total returns the coverage of the whole project.
+func (r *CoverageReport) Total() Coverage {
+	var total Coverage
+	for _, p := range r.Packages {
+		total.Statements += p.Statements
+		total.Covered += p.Covered
+	}
+	return total
+}
+
+// Summary returns a one-line summary of the report.
+func (r *CoverageReport) Summary() string {
+	total := r.Total()
+	return fmt.Sprintf("%.1f%% of %d statements covered (%s)", total.Percent(), total.Statements, r.Tool)
+}
+
+// LeastCoveredPackages returns up to n packages that are not fully covered,
+// the lowest coverage first.
+func (r *CoverageReport) LeastCoveredPackages(n int) []PackageCoverage {
+	var out []PackageCoverage
+	for _, p := range r.Packages {
+		if p.Uncovered() > 0 {
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Percent() != out[j].Percent() {
+			return out[i].Percent() < out[j].Percent()
+		}
+		return out[i].Uncovered() > out[j].Uncovered()
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// Gaps returns up to n functions that are not fully covered, the most
+// uncovered statements first, so that tests written for them gain the most.
+func (r *CoverageReport) Gaps(n int) []FunctionCoverage {
+	var out []FunctionCoverage
+	for _, f := range r.Functions {
+		if f.Uncovered() > 0 {
+			out = append(out, f)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Uncovered() != out[j].Uncovered() {
+			return out[i].Uncovered() > out[j].Uncovered()
+		}
+		return out[i].Percent() < out[j].Percent()
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// FunctionDelta is the coverage of a function in two measurements.
+type FunctionDelta struct {
+	Path     string   `json:"path"`
+	Function string   `json:"function"`
+	Before   Coverage `json:"before"`
+	After    Coverage `json:"after"`
+}
+
+// CoverageDelta compares two measurements of a project's coverage.
+type CoverageDelta struct {
+	Before    Coverage        `json:"before"`
+	After     Coverage        `json:"after"`
+	Functions []FunctionDelta `json:"functions,omitempty"` // the functions compared
+}
+
+// CompareCoverage compares the coverage of before and after, overall and of
+// functions, such as those tests were written for. A function missing
+// from after, as when it was removed, is left out.
+func CompareCoverage(before, after *CoverageReport, functions []FunctionCoverage) CoverageDelta {
+	d := CoverageDelta{Before: before.Total(), After: after.Total()}
+	for _, f := range functions {
+		for _, a := range after.Functions {
+			if a.Path == f.Path && a.Function == f.Function {
+				d.Functions = append(d.Functions, FunctionDelta{Path: f.Path, Function: f.Function, Before: f.Coverage, After: a.Coverage})
+				break
+			}
+		}
+	}
+	return d
+}
+
+// Points returns the change in percentage points.
+func (d CoverageDelta) Points() float64 {
+	return d.After.Percent() - d.Before.Percent()
+}
+
+// String returns the change as "61.2% → 68.0% (+6.8 points)".
+func (d CoverageDelta) String() string {
+	return fmt.Sprintf("%.1f%% → %.1f%% (%+.1f points)", d.Before.Percent(), d.After.Percent(), d.Points())
+}
+
+// CoverageScanner measures the test coverage of a Go project with go test
+// or of a Python project with pytest and coverage.py.
+type CoverageScanner struct {
+	root string
+
+	// run runs a command in root and returns its standard output.
+	run func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+}
+
+// NewCoverageScanner creates a coverage scanner for the project at root.
+func NewCoverageScanner(root string) *CoverageScanner {
+	return &CoverageScanner{root: root, run: runScanner}
+}
+
+// SetRunner sets how the scanner runs its commands, such as through an
+// agent so that they run in its container and under its policy. run runs
+// name with args in dir, always the project root, and returns the
+// standard output. The commands name their output files relative to dir.
+func (s *CoverageScanner) SetRunner(run func(ctx context.Context, dir, name string, args ...string) ([]byte, error)) {
+	s.run = run
+}
+
+// Measure runs the project's tests with coverage. Tests that fail still
+// count toward the coverage of what they ran.
+func (s *CoverageScanner) Measure(ctx context.Context) (*CoverageReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, coverageTimeout)
+	defer cancel()
+	var report *CoverageReport
+	var err error
+	switch {
+	case s.exists("go.mod"):
+		report, err = s.goCoverage(ctx)
+	case s.exists("pyproject.toml"), s.exists("setup.py"), s.exists("requirements.txt"):
+		report, err = s.pythonCoverage(ctx)
+	default:
+		return nil, errors.New("no Go or Python project to measure coverage of")
+	}
+	if err == nil && len(report.Packages) == 0 {
+		err = errors.New("the tests covered no packages")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// goCoverage measures coverage with go test's cover profile, attributing
+// its blocks to the functions go tool cover lists.
+func (s *CoverageScanner) goCoverage(ctx context.Context) (*CoverageReport, error) {
+	profile, rel, err := s.tempFile("cover-*.out")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(profile)
+	if _, err := s.run(ctx, s.root, "go", "test", "-coverprofile="+rel, "./..."); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("go test wrote no cover profile: %v", err)
+	}
+	funcs, err := s.run(ctx, s.root, "go", "tool", "cover", "-func="+rel)
+	if err != nil {
+		return nil, err
+	}
+	return parseGoCoverage(data, funcs, goModulePath(filepath.Join(s.root, "go.mod")))
+}
+
+// coverBlock is a block of statements in a Go cover profile.
+type coverBlock struct {
+	line       int
+	statements int
+	count      int
+}
+
+// parseGoCoverage builds a report from a Go cover profile and the output
+// of go tool cover -func for it. Paths in both are import paths, which
+// are made relative to the root of module.
+func parseGoCoverage(profile, funcs []byte, module string) (*CoverageReport, error) {
+	relative := func(name string) string {
+		if module != "" && strings.HasPrefix(name, module+"/") {
+			return name[len(module)+1:]
+		}
+		return name
+	}
+
+	// Blocks appear once per test binary that covers them
+	blocks := make(map[string]map[string]*coverBlock)
+	sc := bufio.NewScanner(bytes.NewReader(profile))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("parse cover profile: bad line %q", line)
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("parse cover profile: bad line %q", line)
+		}
+		start, _, _ := strings.Cut(fields[0], ".")
+		b := &coverBlock{}
+		b.line, _ = strconv.Atoi(start)
+		b.statements, _ = strconv.Atoi(fields[1])
+		b.count, _ = strconv.Atoi(fields[2])
+		file := relative(line[:colon])
+		if blocks[file] == nil {
+			blocks[file] = make(map[string]*coverBlock)
+		}
+		if old, ok := blocks[file][fields[0]]; ok {
+			old.count = max(old.count, b.count)
+			continue
+		}
+		blocks[file][fields[0]] = b
+	}
+
+	report := &CoverageReport{Tool: CoverageGo}
+	funcsByFile := make(map[string][]int) // indexes into report.Functions
+	sc = bufio.NewScanner(bytes.NewReader(funcs))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 3 || fields[0] == "total:" {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(fields[0], ":"), ":")
+		if len(parts) < 2 {
+			continue
+		}
+		line, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			continue
+		}
+		file := relative(strings.Join(parts[:len(parts)-1], ":"))
+		funcsByFile[file] = append(funcsByFile[file], len(report.Functions))
+		report.Functions = append(report.Functions, FunctionCoverage{Path: file, Line: line, Function: fields[1]})
+	}
+
+	packages := make(map[string]*Coverage)
+	for file, fileBlocks := range blocks {
+		pkg := path.Dir(file)
+		if packages[pkg] == nil {
+			packages[pkg] = &Coverage{}
+		}
+		starts := funcsByFile[file]
+		sort.Slice(starts, func(i, j int) bool { return report.Functions[starts[i]].Line < report.Functions[starts[j]].Line })
+		for _, b := range fileBlocks {
+			c := []*Coverage{packages[pkg]}
+			// The function a block is in is the last one starting before it
+			if i := sort.Search(len(starts), func(i int) bool { return report.Functions[starts[i]].Line > b.line }); i > 0 {
+				c = append(c, &report.Functions[starts[i-1]].Coverage)
+			}
+			for _, c := range c {
+				c.Statements += b.statements
+				if b.count > 0 {
+					c.Covered += b.statements
+				}
+			}
+		}
+	}
+	report.Packages = sortedPackages(packages)
+	return report, nil
+}
+
+// goModulePath returns the module path declared in a go.mod file.
+func goModulePath(gomod string) string {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// pythonCoverage measures coverage with pytest-cov's JSON report.
+func (s *CoverageScanner) pythonCoverage(ctx context.Context) (*CoverageReport, error) {
+	out, rel, err := s.tempFile("coverage-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(out)
+	if _, err := s.run(ctx, s.root, "python3", "-m", "pytest", "-q", "--cov=.", "--cov-report=json:"+rel); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(out)
+	if err == nil && len(data) == 0 {
+		err = errors.New("empty report")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pytest wrote no coverage report (is pytest-cov installed?): %w", err)
+	}
+	return parsePythonCoverage(data)
+}
+
+// parsePythonCoverage builds a report from coverage.py's JSON report,
+// leaving out the tests themselves.
+func parsePythonCoverage(data []byte) (*CoverageReport, error) {
+	type summary struct {
+		Statements int `json:"num_statements"`
+		Covered    int `json:"covered_lines"`
+	}
+	var doc struct {
+		Files map[string]struct {
+			Summary   summary `json:"summary"`
+			Functions map[string]struct {
+				Summary  summary `json:"summary"`
+				Executed []int   `json:"executed_lines"`
+				Missing  []int   `json:"missing_lines"`
+			} `json:"functions"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse coverage report: %w", err)
+	}
+
+	report := &CoverageReport{Tool: CoveragePython}
+	packages := make(map[string]*Coverage)
+	for file, f := range doc.Files {
+		file = filepath.ToSlash(file)
+		if isPythonTest(file) {
+			continue
+		}
+		pkg := path.Dir(file)
+		if packages[pkg] == nil {
+			packages[pkg] = &Coverage{}
+		}
+		packages[pkg].Statements += f.Summary.Statements
+		packages[pkg].Covered += f.Summary.Covered
+		for name, fn := range f.Functions {
+			if name == "" { // the module's top-level code
+				continue
+			}
+			line := 0
+			for _, l := range append(fn.Executed, fn.Missing...) {
+				if line == 0 || l < line {
+					line = l
+				}
+			}
+			report.Functions = append(report.Functions, FunctionCoverage{
+				Path:     file,
+				Line:     line,
+				Function: name,
+				Coverage: Coverage{Statements: fn.Summary.Statements, Covered: fn.Summary.Covered},
+			})
+		}
+	}
+	sort.Slice(report.Functions, func(i, j int) bool {
+		a, b := report.Functions[i], report.Functions[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	report.Packages = sortedPackages(packages)
+	return report, nil
+}
+
+// isPythonTest reports whether a Python file is a test by pytest's naming.
+func isPythonTest(file string) bool {
+	base := path.Base(file)
+	return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") || base == "conftest.py" ||
+		strings.HasPrefix(file, "tests/") || strings.Contains(file, "/tests/")
+}
+
+// sortedPackages returns package coverage sorted by package.
+func sortedPackages(packages map[string]*Coverage) []PackageCoverage {
+	out := make([]PackageCoverage, 0, len(packages))
+	for pkg, c := range packages {
+		out = append(out, PackageCoverage{Package: pkg, Coverage: *c})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Package < out[j].Package })
+	return out
+}
+
+// tempFile returns the path of a new, empty temporary file in the
+// project's .obot directory for a tool to write its report to, and its
+// slash-separated path relative to the root. The file is in the project
+// so that a tool run in a container sees it at the relative path.
+func (s *CoverageScanner) tempFile(pattern string) (path, rel string, err error) {
+	dir := filepath.Join(s.root, ".obot")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", "", err
+	}
+	f.Close()
+	return f.Name(), ".obot/" + filepath.Base(f.Name()), nil
+}
+
+// exists reports whether a file exists at the project root.
+func (s *CoverageScanner) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(s.root, name))
+	return err == nil
+}
@@ -0,0 +1,122 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCoverageScanner returns a coverage scanner for root whose tools write
+// the given reports, keyed by the tool's report flag, and print the given
+// output of go tool cover.
+func fakeCoverageScanner(root string, reports map[string]string, funcs string) *CoverageScanner {
+	s := NewCoverageScanner(root)
+	s.run = func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+		for _, arg := range args {
+			for flag, report := range reports {
+				if path, ok := strings.CutPrefix(arg, flag); ok {
+					return nil, os.WriteFile(filepath.Join(dir, path), []byte(report), 0644)
+				}
+			}
+		}
+		if name == "go" && args[0] == "tool" {
+			return []byte(funcs), nil
+		}
+		return nil, fmt.Errorf("%s is not installed", name)
+	}
+	return s
+}
+
+func TestCoverageScanner_Go(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/p\n\ngo 1.24\n"), 0644)
+	s := fakeCoverageScanner(root, map[string]string{"-coverprofile=": `mode: set
+example.com/p/a.go:3.20,5.2 2 1
+example.com/p/a.go:7.20,12.2 5 0
+example.com/p/a.go:12.2,14.3 1 0
+example.com/p/a.go:12.2,14.3 1 1
+example.com/p/internal/b/b.go:3.14,4.2 1 0
+`}, `example.com/p/a.go:3:			Covered		100.0%
+example.com/p/a.go:7:			Partly		16.7%
+example.com/p/internal/b/b.go:3:	Missed		0.0%
+total:					(statements)	33.3%
+`)
+
+	report, err := s.Measure(context.Background())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if total := report.Total(); total != (Coverage{Statements: 9, Covered: 3}) {
+		t.Errorf("Total() = %+v, want 3 of 9", total)
+	}
+	if got := report.Summary(); got != "33.3% of 9 statements covered (go test)" {
+		t.Errorf("Summary() = %q", got)
+	}
+	pkgs := report.LeastCoveredPackages(5)
+	if len(pkgs) != 2 || pkgs[0].Package != "internal/b" || pkgs[1].Package != "." {
+		t.Errorf("LeastCoveredPackages() = %+v, want internal/b then the root", pkgs)
+	}
+	gaps := report.Gaps(5)
+	if len(gaps) != 2 || gaps[0].Function != "Partly" || gaps[0].Path != "a.go" || gaps[0].Line != 7 || gaps[0].Uncovered() != 5 {
+		t.Errorf("Gaps() = %+v, want Partly in a.go first", gaps)
+	}
+	if gaps := report.Gaps(1); len(gaps) != 1 {
+		t.Errorf("Gaps(1) returned %d functions", len(gaps))
+	}
+}
+
+func TestCoverageScanner_Python(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "pyproject.toml"), nil, 0644)
+	s := fakeCoverageScanner(root, map[string]string{"--cov-report=json:": `{"files": {
+"app/core.py": {"summary": {"num_statements": 10, "covered_lines": 4}, "functions": {
+	"": {"summary": {"num_statements": 2, "covered_lines": 2}, "executed_lines": [1, 2], "missing_lines": []},
+	"parse": {"summary": {"num_statements": 6, "covered_lines": 0}, "executed_lines": [], "missing_lines": [5, 6, 7, 8, 9, 10]},
+	"Loader.load": {"summary": {"num_statements": 2, "covered_lines": 2}, "executed_lines": [13, 14], "missing_lines": []}}},
+"tests/test_core.py": {"summary": {"num_statements": 8, "covered_lines": 8}, "functions": {}}}}`}, "")
+
+	report, err := s.Measure(context.Background())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if len(report.Packages) != 1 || report.Packages[0].Package != "app" {
+		t.Errorf("Packages = %+v, want only app", report.Packages)
+	}
+	gaps := report.Gaps(5)
+	if len(gaps) != 1 || gaps[0].Function != "parse" || gaps[0].Line != 5 {
+		t.Errorf("Gaps() = %+v, want parse at line 5", gaps)
+	}
+}
+
+func TestCoverageScanner_Errors(t *testing.T) {
+	root := t.TempDir()
+	s := fakeCoverageScanner(root, nil, "")
+	if _, err := s.Measure(context.Background()); err == nil || !strings.Contains(err.Error(), "no Go or Python project") {
+		t.Errorf("Measure() error = %v, want no project", err)
+	}
+	os.WriteFile(filepath.Join(root, "requirements.txt"), nil, 0644)
+	if _, err := s.Measure(context.Background()); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("Measure() error = %v, want pytest missing", err)
+	}
+}
+
+func TestCompareCoverage(t *testing.T) {
+	before := &CoverageReport{
+		Packages:  []PackageCoverage{{Package: ".", Coverage: Coverage{Statements: 10, Covered: 5}}},
+		Functions: []FunctionCoverage{{Path: "a.go", Function: "Parse", Coverage: Coverage{Statements: 4, Covered: 0}}},
+	}
+	after := &CoverageReport{
+		Packages:  []PackageCoverage{{Package: ".", Coverage: Coverage{Statements: 10, Covered: 8}}},
+		Functions: []FunctionCoverage{{Path: "a.go", Line: 9, Function: "Parse", Coverage: Coverage{Statements: 4, Covered: 3}}},
+	}
+	d := CompareCoverage(before, after, append(before.Gaps(5), FunctionCoverage{Path: "a.go", Function: "Removed"}))
+	if got := d.String(); got != "50.0% → 80.0% (+30.0 points)" {
+		t.Errorf("String() = %q", got)
+	}
+	if len(d.Functions) != 1 || d.Functions[0].After.Covered != 3 {
+		t.Errorf("Functions = %+v, want Parse alone", d.Functions)
+	}
+}
@@ -0,0 +1,382 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dependency finding types
+const (
+	TypeVulnerability = "vulnerability"
+	TypeLicense       = "license"
+)
+
+// DependencyFinding is a vulnerable dependency or a dependency whose
+// license conflicts with the project's.
+type DependencyFinding struct {
+	HealthIssue
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	ID      string `json:"id,omitempty"`      // advisory ID, e.g. GO-2024-2687 or GHSA-...
+	License string `json:"license,omitempty"` // SPDX identifier of the dependency's license
+	Fix     string `json:"fix,omitempty"`     // remediation, e.g. "upgrade to v0.23.0"
+}
+
+// DependencyReport is the result of a dependency scan.
+type DependencyReport struct {
+	Findings       []DependencyFinding `json:"findings"`
+	ProjectLicense string              `json:"project_license,omitempty"`
+	Scanners       []string            `json:"scanners"`          // scanners that ran
+	Skipped        []string            `json:"skipped,omitempty"` // scanners that could not run, with the reason
+}
+
+// Blocking reports whether the report has critical or high severity findings.
+func (r *DependencyReport) Blocking() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityCritical || f.Severity == SeverityHigh {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary returns a one-line summary of the report.
+func (r *DependencyReport) Summary() string {
+	var vulns, licenses int
+	for _, f := range r.Findings {
+		if f.Type == TypeVulnerability {
+			vulns++
+		} else {
+			licenses++
+		}
+	}
+	return fmt.Sprintf("%d vulnerable dependencies, %d license conflicts (%s)", vulns, licenses, strings.Join(r.Scanners, ", "))
+}
+
+// DependencyScanner scans a project's dependencies for known vulnerabilities
+// (govulncheck, npm audit, pip-audit) and licenses incompatible with the
+// project's own.
+type DependencyScanner struct {
+	root string
+
+	// run runs a scanner command in root and returns its standard output.
+	run func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+}
+
+// NewDependencyScanner creates a dependency scanner for the project at root.
+func NewDependencyScanner(root string) *DependencyScanner {
+	return &DependencyScanner{root: root, run: runScanner}
+}
+
+// Scan runs the scanners that apply to the project. Scanners that are not
+// installed are listed in the report's Skipped list.
+func (s *DependencyScanner) Scan(ctx context.Context) (*DependencyReport, error) {
+	report := &DependencyReport{ProjectLicense: licenseIn(s.root)}
+
+	type scanner struct {
+		name     string
+		manifest string
+		scan     func(context.Context, *DependencyReport) error
+	}
+	scanners := []scanner{
+		{"govulncheck", "go.mod", s.govulncheck},
+		{"npm audit", "package.json", s.npmAudit},
+		{"pip-audit", "requirements.txt", s.pipAudit},
+		{"pip-audit", "pyproject.toml", s.pipAudit},
+		{"go licenses", "go.mod", s.goLicenses},
+		{"npm licenses", "package.json", s.npmLicenses},
+	}
+	ran := make(map[string]bool)
+	for _, sc := range scanners {
+		if ran[sc.name] || !s.exists(sc.manifest) {
+			continue
+		}
+		ran[sc.name] = true
+		if err := sc.scan(ctx, report); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.Skipped = append(report.Skipped, sc.name+": "+err.Error())
+			continue
+		}
+		report.Scanners = append(report.Scanners, sc.name)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityRank(report.Findings[i].Severity) > severityRank(report.Findings[j].Severity)
+	})
+	return report, nil
+}
+
+// govulncheck reports Go vulnerabilities, rating those whose vulnerable
+// code is called higher than those only imported or required.
+func (s *DependencyScanner) govulncheck(ctx context.Context, report *DependencyReport) error {
+	out, err := s.run(ctx, s.root, "govulncheck", "-format", "json", "./...")
+	if err != nil {
+		return err
+	}
+
+	type frame struct {
+		Module   string `json:"module"`
+		Version  string `json:"version"`
+		Package  string `json:"package"`
+		Function string `json:"function"`
+	}
+	type message struct {
+		OSV *struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"osv"`
+		Finding *struct {
+			OSV          string  `json:"osv"`
+			FixedVersion string  `json:"fixed_version"`
+			Trace        []frame `json:"trace"`
+		} `json:"finding"`
+	}
+
+	summaries := make(map[string]string)
+	found := make(map[string]*DependencyFinding)
+	var order []string
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var m message
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("parse govulncheck output: %w", err)
+		}
+		if m.OSV != nil {
+			summaries[m.OSV.ID] = m.OSV.Summary
+		}
+		if m.Finding == nil || len(m.Finding.Trace) == 0 {
+			continue
+		}
+		top := m.Finding.Trace[0]
+		severity := SeverityLow // module required
+		switch {
+		case top.Function != "":
+			severity = SeverityHigh
+		case top.Package != "":
+			severity = SeverityMedium
+		}
+		f, ok := found[m.Finding.OSV]
+		if !ok {
+			f = &DependencyFinding{
+				HealthIssue: HealthIssue{Path: "go.mod", Type: TypeVulnerability},
+				Package:     top.Module,
+				Version:     top.Version,
+				ID:          m.Finding.OSV,
+			}
+			if m.Finding.FixedVersion != "" {
+				f.Fix = "upgrade " + top.Module + " to " + m.Finding.FixedVersion
+			}
+			found[m.Finding.OSV] = f
+			order = append(order, m.Finding.OSV)
+		}
+		if severityRank(severity) > severityRank(f.Severity) {
+			f.Severity = severity
+		}
+	}
+	for _, id := range order {
+		f := found[id]
+		f.Message = vulnMessage(f.Package, f.Version, id, summaries[id])
+		report.Findings = append(report.Findings, *f)
+	}
+	return nil
+}
+
+// npmAudit reports vulnerable npm packages.
+func (s *DependencyScanner) npmAudit(ctx context.Context, report *DependencyReport) error {
+	out, err := s.run(ctx, s.root, "npm", "audit", "--json")
+	if err != nil {
+		return err
+	}
+	var audit struct {
+		Vulnerabilities map[string]struct {
+			Name         string            `json:"name"`
+			Severity     string            `json:"severity"`
+			Range        string            `json:"range"`
+			Via          []json.RawMessage `json:"via"`
+			FixAvailable json.RawMessage   `json:"fixAvailable"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(out, &audit); err != nil {
+		return fmt.Errorf("parse npm audit output: %w", err)
+	}
+
+	names := make([]string, 0, len(audit.Vulnerabilities))
+	for name := range audit.Vulnerabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := audit.Vulnerabilities[name]
+		var id, title string
+		for _, raw := range v.Via {
+			// Entries are advisories, or names of vulnerable dependencies
+			var adv struct {
+				Title string `json:"title"`
+				URL   string `json:"url"`
+			}
+			if json.Unmarshal(raw, &adv) == nil && adv.Title != "" {
+				title = adv.Title
+				if adv.URL != "" {
+					id = path.Base(adv.URL)
+				}
+				break
+			}
+		}
+		if title == "" {
+			title = "depends on a vulnerable package"
+		}
+		f := DependencyFinding{
+			HealthIssue: HealthIssue{Path: "package.json", Type: TypeVulnerability, Severity: npmSeverity(v.Severity)},
+			Package:     name,
+			Version:     v.Range,
+			ID:          id,
+		}
+		f.Message = vulnMessage(name, v.Range, id, title)
+		var fix struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(v.FixAvailable, &fix) == nil && fix.Name != "" {
+			f.Fix = "upgrade " + fix.Name + " to " + fix.Version
+		} else if string(v.FixAvailable) == "true" {
+			f.Fix = "run npm audit fix"
+		}
+		report.Findings = append(report.Findings, f)
+	}
+	return nil
+}
+
+// pipAudit reports vulnerable Python packages.
+func (s *DependencyScanner) pipAudit(ctx context.Context, report *DependencyReport) error {
+	args := []string{"--format", "json"}
+	manifest := "pyproject.toml"
+	if s.exists("requirements.txt") {
+		manifest = "requirements.txt"
+		args = append(args, "--requirement", "requirements.txt")
+	} else {
+		args = append(args, ".")
+	}
+	out, err := s.run(ctx, s.root, "pip-audit", args...)
+	if err != nil {
+		return err
+	}
+
+	type dependency struct {
+		Name  string `json:"name"`
+		Vulns []struct {
+			ID          string   `json:"id"`
+			FixVersions []string `json:"fix_versions"`
+			Description string   `json:"description"`
+		} `json:"vulns"`
+		Version string `json:"version"`
+	}
+	// Newer pip-audit versions wrap the dependency list in an object
+	var deps []dependency
+	var wrapped struct {
+		Dependencies []dependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(out, &wrapped); err == nil {
+		deps = wrapped.Dependencies
+	} else if err := json.Unmarshal(out, &deps); err != nil {
+		return fmt.Errorf("parse pip-audit output: %w", err)
+	}
+
+	for _, d := range deps {
+		for _, v := range d.Vulns {
+			f := DependencyFinding{
+				HealthIssue: HealthIssue{Path: manifest, Type: TypeVulnerability, Severity: SeverityMedium},
+				Package:     d.Name,
+				Version:     d.Version,
+				ID:          v.ID,
+			}
+			f.Message = vulnMessage(d.Name, d.Version, v.ID, firstLine(v.Description))
+			if len(v.FixVersions) > 0 {
+				f.Fix = "upgrade " + d.Name + " to " + v.FixVersions[0]
+			}
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	return nil
+}
+
+// exists reports whether a file exists in the project root.
+func (s *DependencyScanner) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(s.root, name))
+	return err == nil
+}
+
+// runScanner runs a scanner command. Scanners exit non-zero when they find
+// something, so output on stdout counts as success.
+func runScanner(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s is not installed", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && len(bytes.TrimSpace(out)) > 0) {
+		return nil, fmt.Errorf("%w: %s", err, firstLine(stderr.String()))
+	}
+	return out, nil
+}
+
+// vulnMessage describes a vulnerable dependency.
+func vulnMessage(pkg, version, id, summary string) string {
+	msg := pkg
+	if version != "" {
+		msg += "@" + version
+	}
+	if id != "" {
+		msg += " " + id
+	}
+	if summary != "" {
+		msg += ": " + summary
+	}
+	return msg
+}
+
+// npmSeverity maps npm audit severities onto the scan severities.
+func npmSeverity(s string) string {
+	switch s {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "moderate":
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+// severityRank orders severities, most severe highest.
+func severityRank(s string) int {
+	return (&IssuePrioritizer{}).severityValue(s)
+}
+
+// firstLine returns the first non-empty line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeScanner returns a scanner for root whose commands print the given
+// output, keyed by command name.
+func fakeScanner(root string, outputs map[string]string) *DependencyScanner {
+	s := NewDependencyScanner(root)
+	s.run = func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+		key := name
+		if name == "go" || name == "npm" {
+			key = name + " " + args[0]
+		}
+		out, ok := outputs[key]
+		if !ok {
+			return nil, fmt.Errorf("%s is not installed", name)
+		}
+		return []byte(out), nil
+	}
+	return s
+}
+
+func TestDependencyScanner_Vulnerabilities(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"go.mod", "package.json", "requirements.txt"} {
+		os.WriteFile(filepath.Join(root, name), []byte("{}"), 0644)
+	}
+	s := fakeScanner(root, map[string]string{
+		"govulncheck": `{"config":{"scanner_name":"govulncheck"}}
+{"osv":{"id":"GO-2024-0001","summary":"Excessive memory use in x/net"}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v0.23.0","trace":[{"module":"golang.org/x/net","version":"v0.20.0"}]}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v0.23.0","trace":[{"module":"golang.org/x/net","version":"v0.20.0","package":"golang.org/x/net/html","function":"Parse"}]}}`,
+		"npm audit": `{"vulnerabilities":{"lodash":{"name":"lodash","severity":"critical","range":"<4.17.21",
+"via":[{"title":"Prototype Pollution","url":"https://github.com/advisories/GHSA-jf85-cpcp-j695"}],
+"fixAvailable":{"name":"lodash","version":"4.17.21"}}}}`,
+		"pip-audit": `{"dependencies":[{"name":"requests","version":"2.19.0","vulns":[{"id":"PYSEC-2018-28","fix_versions":["2.20.0"],"description":"Leaks credentials.\nMore text."}]}]}`,
+	})
+
+	report, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(report.Findings) != 3 {
+		t.Fatalf("Findings = %+v", report.Findings)
+	}
+	want := []struct{ severity, id, fix string }{
+		{SeverityCritical, "GHSA-jf85-cpcp-j695", "upgrade lodash to 4.17.21"},
+		{SeverityHigh, "GO-2024-0001", "upgrade golang.org/x/net to v0.23.0"},
+		{SeverityMedium, "PYSEC-2018-28", "upgrade requests to 2.20.0"},
+	}
+	for i, w := range want {
+		f := report.Findings[i]
+		if f.Severity != w.severity || f.ID != w.id || f.Fix != w.fix || f.Type != TypeVulnerability {
+			t.Errorf("Findings[%d] = %+v, want %+v", i, f, w)
+		}
+	}
+	if got := report.Findings[1].Message; got != "golang.org/x/net@v0.20.0 GO-2024-0001: Excessive memory use in x/net" {
+		t.Errorf("Message = %q", got)
+	}
+	if !report.Blocking() {
+		t.Error("Blocking() = false with critical findings")
+	}
+	if len(report.Skipped) != 2 || !strings.HasPrefix(report.Skipped[0], "go licenses") {
+		t.Errorf("Skipped = %v", report.Skipped)
+	}
+}
+
+func TestDependencyScanner_Licenses(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "LICENSE"), []byte("MIT License\n\nPermission is hereby granted, free of charge, to any person"), 0644)
+	os.WriteFile(filepath.Join(root, "package.json"), []byte(`{"dependencies":{"left-pad":"^1.0.0","gpl-lib":"^2.0.0","@scope/dual":"^1.0.0","mystery":"^1.0.0"}}`), 0644)
+	for name, manifest := range map[string]string{
+		"left-pad":    `{"version":"1.3.0","license":"WTFPL OR MIT"}`,
+		"gpl-lib":     `{"version":"2.1.0","license":{"type":"GPL-3.0"}}`,
+		"@scope/dual": `{"version":"1.0.0","license":"(MPL-2.0 OR Apache-2.0)"}`,
+		"mystery":     `{"version":"0.1.0"}`,
+	} {
+		dir := filepath.Join(root, "node_modules", filepath.FromSlash(name))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "package.json"), []byte(manifest), 0644)
+	}
+	s := fakeScanner(root, map[string]string{"npm audit": `{"vulnerabilities":{}}`})
+
+	report, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if report.ProjectLicense != "MIT" {
+		t.Errorf("ProjectLicense = %q", report.ProjectLicense)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, f.Severity+" "+f.Package)
+	}
+	if strings.Join(got, ", ") != "high gpl-lib, low mystery" {
+		t.Errorf("Findings = %v", got)
+	}
+}
+
+func TestLicenseConflict(t *testing.T) {
+	tests := []struct {
+		project, dep string
+		want         string
+	}{
+		{"MIT", "BSD-3-Clause", ""},
+		{"MIT", "GPL-2.0-only", SeverityHigh},
+		{"GPL-3.0", "GPL-2.0-or-later", ""},
+		{"GPL-3.0", "AGPL-3.0", SeverityHigh},
+		{"GPL-2.0", "Apache-2.0", SeverityMedium},
+		{"", "LGPL-2.1", SeverityLow},
+		{"Apache-2.0", "", SeverityLow},
+	}
+	for _, tt := range tests {
+		if got, _ := licenseConflict(tt.project, tt.dep); got != tt.want {
+			t.Errorf("licenseConflict(%q, %q) = %q, want %q", tt.project, tt.dep, got, tt.want)
+		}
+	}
+}
+
+func TestIdentifyLicense(t *testing.T) {
+	tests := map[string]string{
+		"                    GNU GENERAL PUBLIC LICENSE\n                       Version 2, June 1991":    "GPL-2.0",
+		"                    GNU GENERAL PUBLIC LICENSE\n                       Version 3, 29 June 2007": "GPL-3.0",
+		"                   GNU LESSER GENERAL PUBLIC LICENSE":                                           "LGPL-3.0",
+		"                                 Apache License\n                           Version 2.0":        "Apache-2.0",
+		"Redistribution and use in source and binary forms ... Neither the name of Google":               "BSD-3-Clause",
+		"All rights reserved.": "",
+	}
+	for text, want := range tests {
+		if got := identifyLicense(text); got != want {
+			t.Errorf("identifyLicense(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/croberts/obot/internal/analyzer"
+	"github.com/croberts/obot/internal/fswalk"
 )
 
 // HealthIssue represents a detected problem in the codebase.
@@ -43,26 +44,15 @@ func (s *HealthScanner) Scan() (*HealthReport, error) {
 		Issues: make([]HealthIssue, 0),
 	}
 
-	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			if strings.Contains(path, ".git") || strings.Contains(path, "node_modules") {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
+	err := fswalk.Walk(s.root, fswalk.Options{}, func(e fswalk.Entry) error {
 		// Only scan code files
-		lang := analyzer.DetectLanguage(path)
+		lang := analyzer.DetectLanguage(e.Path)
 		if !lang.IsCode() {
 			return nil
 		}
 
 		report.FilesScanned++
-		issues, err := s.scanFile(path)
+		issues, err := s.scanFile(e.Path)
 		if err == nil {
 			report.Issues = append(report.Issues, issues...)
 		}
@@ -74,18 +64,46 @@ func (s *HealthScanner) Scan() (*HealthReport, error) {
 		return nil, err
 	}
 
-	// Calculate a simple health score
-	if report.FilesScanned > 0 {
-		deduction := len(report.Issues) * 2
-		report.Score = 100 - deduction
-		if report.Score < 0 {
-			report.Score = 0
+	report.score()
+	return report, nil
+}
+
+// ScanFiles scans the code files among paths, relative to the scanner's
+// root, such as the files a run changed. Missing files are skipped.
+func (s *HealthScanner) ScanFiles(paths []string) (*HealthReport, error) {
+	report := &HealthReport{
+		Issues: make([]HealthIssue, 0),
+	}
+	for _, p := range paths {
+		path := filepath.Join(s.root, filepath.FromSlash(p))
+		if !analyzer.DetectLanguage(path).IsCode() {
+			continue
+		}
+		issues, err := s.scanFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.FilesScanned++
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.score()
+	return report, nil
+}
+
+// score calculates a simple health score from the issues.
+func (r *HealthReport) score() {
+	if r.FilesScanned > 0 {
+		deduction := len(r.Issues) * 2
+		r.Score = 100 - deduction
+		if r.Score < 0 {
+			r.Score = 0
 		}
 	} else {
-		report.Score = 100
+		r.Score = 100
 	}
-
-	return report, nil
 }
 
 // scanFile scans a single file for issues.
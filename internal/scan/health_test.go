@@ -82,3 +82,27 @@ func complex() {
 		t.Errorf("Expected health score to be < 100, got %d", report.Score)
 	}
 }
+
+func TestScanFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"pkg/changed.go":   "package pkg\nvar secret = \"s3cr3t\"\n",
+		"pkg/untouched.go": "package pkg\nvar password = \"hunter2\"\n",
+		"README.md":        "token = abc\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := NewHealthScanner(dir).ScanFiles([]string{"pkg/changed.go", "README.md", "pkg/deleted.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FilesScanned != 1 || len(report.Issues) != 1 || report.Issues[0].Type != "security" || report.Issues[0].Line != 2 {
+		t.Errorf("ScanFiles() = %+v, want the one issue in pkg/changed.go", report)
+	}
+}
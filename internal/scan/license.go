@@ -0,0 +1,245 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// License families, by the obligations they put on the project
+const (
+	licensePermissive      = "permissive"
+	licenseWeakCopyleft    = "weak copyleft"
+	licenseCopyleft        = "copyleft"
+	licenseNetworkCopyleft = "network copyleft"
+	licenseUnknown         = "unknown"
+)
+
+// licenseFiles are the file names a license is looked up in.
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING", "COPYING.md"}
+
+// licenseMarkers identify a license from its text, most specific first.
+var licenseMarkers = []struct {
+	text string
+	spdx string
+}{
+	{"GNU AFFERO GENERAL PUBLIC LICENSE", "AGPL-3.0"},
+	{"GNU LESSER GENERAL PUBLIC LICENSE", "LGPL-3.0"},
+	{"GNU LIBRARY GENERAL PUBLIC LICENSE", "LGPL-2.0"},
+	{"GNU GENERAL PUBLIC LICENSE", "GPL"}, // version decided by identifyLicense
+	{"Mozilla Public License", "MPL-2.0"},
+	{"Eclipse Public License", "EPL-2.0"},
+	{"Apache License", "Apache-2.0"},
+	{"Permission is hereby granted, free of charge", "MIT"},
+	{"Neither the name", "BSD-3-Clause"},
+	{"Redistribution and use in source and binary forms", "BSD-2-Clause"},
+	{"Permission to use, copy, modify, and/or distribute", "ISC"},
+	{"This is free and unencumbered software", "Unlicense"},
+}
+
+// goLicenses checks the licenses of the Go modules the project requires.
+// Modules missing from the module cache are not checked.
+func (s *DependencyScanner) goLicenses(ctx context.Context, report *DependencyReport) error {
+	out, err := s.run(ctx, s.root, "go", "list", "-m", "-json", "all")
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var m struct {
+			Path    string
+			Version string
+			Dir     string
+			Main    bool
+		}
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("parse go list output: %w", err)
+		}
+		if m.Main || m.Dir == "" {
+			continue
+		}
+		s.checkLicense(report, "go.mod", m.Path, m.Version, licenseIn(m.Dir))
+	}
+	return nil
+}
+
+// npmLicenses checks the licenses of the project's installed npm
+// dependencies. Development dependencies are not shipped and not checked.
+func (s *DependencyScanner) npmLicenses(ctx context.Context, report *DependencyReport) error {
+	var manifest struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	data, err := os.ReadFile(filepath.Join(s.root, "package.json"))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("parse package.json: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.root, "node_modules")); err != nil {
+		return fmt.Errorf("node_modules is not installed")
+	}
+
+	names := make([]string, 0, len(manifest.Dependencies))
+	for name := range manifest.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		dir := filepath.Join(s.root, "node_modules", filepath.FromSlash(name))
+		var pkg struct {
+			Version string          `json:"version"`
+			License json.RawMessage `json:"license"`
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+		if err != nil || json.Unmarshal(data, &pkg) != nil {
+			continue
+		}
+		// The license is an SPDX expression, or an object in old packages
+		var license string
+		if json.Unmarshal(pkg.License, &license) != nil {
+			var obj struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal(pkg.License, &obj)
+			license = obj.Type
+		}
+		if license == "" {
+			license = licenseIn(dir)
+		}
+		s.checkLicense(report, "package.json", name, pkg.Version, license)
+	}
+	return nil
+}
+
+// checkLicense adds a finding when a dependency's license is unknown or
+// conflicts with the project license.
+func (s *DependencyScanner) checkLicense(report *DependencyReport, manifest, pkg, version, license string) {
+	severity, reason := licenseConflict(report.ProjectLicense, license)
+	if severity == "" {
+		return
+	}
+	f := DependencyFinding{
+		HealthIssue: HealthIssue{Path: manifest, Type: TypeLicense, Severity: severity},
+		Package:     pkg,
+		Version:     version,
+		License:     license,
+	}
+	f.Message = vulnMessage(pkg, version, "", reason)
+	if severity != SeverityLow {
+		f.Fix = "replace " + pkg + " with a dependency under a compatible license"
+	}
+	report.Findings = append(report.Findings, f)
+}
+
+// licenseConflict rates using a dependency licensed dep in a project
+// licensed project, returning "" when they are compatible.
+func licenseConflict(project, dep string) (severity, reason string) {
+	depFamily := licenseFamily(dep)
+	projectFamily := licenseFamily(project)
+	if project == "" {
+		projectFamily = licensePermissive // unlicensed projects are treated as proprietary
+	}
+	switch {
+	case depFamily == licenseUnknown:
+		if dep == "" {
+			return SeverityLow, "license could not be determined"
+		}
+		return SeverityLow, "unrecognized license " + dep
+	case depFamily == licenseNetworkCopyleft && projectFamily != licenseNetworkCopyleft:
+		return SeverityHigh, dep + " requires the project to be released under the AGPL"
+	case depFamily == licenseCopyleft && projectFamily != licenseCopyleft && projectFamily != licenseNetworkCopyleft:
+		return SeverityHigh, dep + " requires the project to be released under the GPL"
+	case strings.HasPrefix(dep, "Apache-2.0") && strings.HasPrefix(project, "GPL-2.0"):
+		return SeverityMedium, "Apache-2.0 is incompatible with GPL-2.0"
+	case depFamily == licenseWeakCopyleft && projectFamily == licensePermissive:
+		return SeverityLow, dep + " requires changes to the dependency itself to be shared"
+	}
+	return "", ""
+}
+
+// licenseFamily classifies an SPDX license identifier or expression. For
+// "A OR B" expressions the least restrictive choice counts.
+func licenseFamily(spdx string) string {
+	if spdx == "" {
+		return licenseUnknown
+	}
+	if strings.Contains(spdx, " OR ") {
+		best := licenseUnknown
+		for _, part := range strings.Split(strings.Trim(spdx, "()"), " OR ") {
+			if f := licenseFamily(strings.TrimSpace(part)); familyRank(f) < familyRank(best) {
+				best = f
+			}
+		}
+		return best
+	}
+	id := strings.ToUpper(spdx)
+	switch {
+	case strings.HasPrefix(id, "AGPL"):
+		return licenseNetworkCopyleft
+	case strings.HasPrefix(id, "LGPL"), strings.HasPrefix(id, "MPL"), strings.HasPrefix(id, "EPL"), strings.HasPrefix(id, "CDDL"):
+		return licenseWeakCopyleft
+	case strings.HasPrefix(id, "GPL"):
+		return licenseCopyleft
+	}
+	for _, p := range []string{"MIT", "BSD", "APACHE", "ISC", "UNLICENSE", "0BSD", "ZLIB", "CC0", "PYTHON", "PSF", "BLUEOAK"} {
+		if strings.HasPrefix(id, p) {
+			return licensePermissive
+		}
+	}
+	return licenseUnknown
+}
+
+// familyRank orders license families from least to most restrictive.
+func familyRank(family string) int {
+	switch family {
+	case licensePermissive:
+		return 0
+	case licenseWeakCopyleft:
+		return 1
+	case licenseCopyleft:
+		return 2
+	case licenseNetworkCopyleft:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// licenseIn identifies the license file in dir, returning "" when there is
+// none or it is not recognized.
+func licenseIn(dir string) string {
+	for _, name := range licenseFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		return identifyLicense(string(data))
+	}
+	return ""
+}
+
+// identifyLicense identifies a license from its text.
+func identifyLicense(text string) string {
+	for _, m := range licenseMarkers {
+		if !strings.Contains(text, m.text) {
+			continue
+		}
+		if m.spdx == "GPL" {
+			if strings.Contains(text, "Version 2,") {
+				return "GPL-2.0"
+			}
+			return "GPL-3.0"
+		}
+		return m.spdx
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/croberts/obot/internal/scan"
+)
+
+// maxCoverageGaps is how many of the least-covered packages and functions
+// the model is shown and has tests written for.
+const maxCoverageGaps = 10
+
+// CoverageGuide measures the project's test coverage during Verify and
+// runs the test generation step of the Scale and Production schedules,
+// which writes tests for the least-covered functions and measures again.
+type CoverageGuide struct {
+	// Measure measures the project's coverage, such as a coverage
+	// scanner's Measure
+	Measure func(context.Context) (*scan.CoverageReport, error)
+
+	latest *scan.CoverageReport // the last measurement
+	steps  []CoverageStep       // since the last TakeSteps
+}
+
+// CoverageStep is a test generation step and the coverage it gained.
+type CoverageStep struct {
+	Step    string // the schedule and process, e.g. "Production P3"
+	Tool    string
+	Targets []scan.FunctionCoverage
+	scan.CoverageDelta
+}
+
+// NewCoverageGuide creates a coverage guide measuring with measure.
+func NewCoverageGuide(measure func(context.Context) (*scan.CoverageReport, error)) *CoverageGuide {
+	return &CoverageGuide{Measure: measure}
+}
+
+// TakeSteps returns the test generation steps since the last call, so the
+// caller can record their coverage delta for the summary.
+func (g *CoverageGuide) TakeSteps() []CoverageStep {
+	if g == nil {
+		return nil
+	}
+	steps := g.steps
+	g.steps = nil
+	return steps
+}
+
+// measure measures coverage, keeping the report as the latest. A
+// measurement that fails, as when the project has no tests or the tools are
+// not installed, leaves coverage to the model.
+func (g *CoverageGuide) measure(ctx context.Context) *scan.CoverageReport {
+	if g == nil || g.Measure == nil {
+		return nil
+	}
+	report, err := g.Measure(ctx)
+	if err != nil {
+		return nil
+	}
+	g.latest = report
+	return report
+}
+
+// generateTests runs the test generation step: it has the model write
+// tests for the least-covered functions of the latest measurement, then
+// measures again. It returns nil without running the step when coverage
+// cannot be measured or has no gaps.
+func (g *CoverageGuide) generateTests(ctx context.Context, step string, exec func(context.Context, string) error) (*CoverageStep, error) {
+	if g == nil || g.Measure == nil {
+		return nil, nil
+	}
+	before := g.latest
+	if before == nil {
+		if before = g.measure(ctx); before == nil {
+			return nil, nil
+		}
+	}
+	targets := before.Gaps(maxCoverageGaps)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	if err := exec(ctx, testGenerationPrompt(step, before, targets)); err != nil {
+		return nil, err
+	}
+	after := g.measure(ctx)
+	if after == nil {
+		return nil, nil
+	}
+	s := CoverageStep{Step: step, Tool: after.Tool, Targets: targets, CoverageDelta: scan.CompareCoverage(before, after, targets)}
+	g.steps = append(g.steps, s)
+	return &s, nil
+}
+
+// coverageGapsPrompt describes a coverage measurement and its gaps for
+// Verify.
+func coverageGapsPrompt(report *scan.CoverageReport) string {
+	var sb strings.Builder
+	sb.WriteString("COVERAGE: " + report.Summary() + "\n")
+	writeCoverageGaps(&sb, report, report.Gaps(maxCoverageGaps))
+	sb.WriteString("Include the coverage in the verification report. The Scale and Production schedules write tests for these gaps.")
+	return sb.String()
+}
+
+// testGenerationPrompt is the prompt of the test generation step.
+func testGenerationPrompt(step string, report *scan.CoverageReport, targets []scan.FunctionCoverage) string {
+	var sb strings.Builder
+	sb.WriteString("### STEP: TEST GENERATION (" + step + ")\n")
+	sb.WriteString("You are the test engineer. Your mission is to CLOSE COVERAGE GAPS.\n\n")
+	sb.WriteString("COVERAGE: " + report.Summary() + "\n")
+	writeCoverageGaps(&sb, report, targets)
+	sb.WriteString("\nTASKS:\n")
+	sb.WriteString("1. **Read the Gaps**: Read each function listed above and find the branches and error paths no test runs.\n")
+	sb.WriteString("2. **Write Tests**: Add tests for them in the project's existing test files and style, asserting behavior, not just running the code.\n")
+	sb.WriteString("3. **Run the Tests**: Run the new tests and make them pass without changing the code under test.\n\n")
+	sb.WriteString("GUIDELINES:\n")
+	sb.WriteString("- Target the listed functions first, the most uncovered statements first.\n")
+	sb.WriteString("- Do not change production code; report bugs the tests reveal instead of fixing them here.\n")
+	sb.WriteString("- Coverage is measured again after this step.\n\n")
+	sb.WriteString("OUTPUT:\n")
+	sb.WriteString("New passing tests for the coverage gaps.")
+	return sb.String()
+}
+
+// writeCoverageGaps lists the least-covered packages and the given
+// functions of a report.
+func writeCoverageGaps(sb *strings.Builder, report *scan.CoverageReport, functions []scan.FunctionCoverage) {
+	if pkgs := report.LeastCoveredPackages(maxCoverageGaps); len(pkgs) > 0 {
+		sb.WriteString("Least covered packages:\n")
+		for _, p := range pkgs {
+			sb.WriteString(fmt.Sprintf("- %s: %.1f%% (%d of %d statements)\n", p.Package, p.Percent(), p.Covered, p.Statements))
+		}
+	}
+	if len(functions) > 0 {
+		sb.WriteString("Least covered functions:\n")
+		for _, f := range functions {
+			sb.WriteString(fmt.Sprintf("- %s:%d %s: %.1f%% (%d statements uncovered)\n", f.Path, f.Line, f.Function, f.Percent(), f.Uncovered()))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/scan"
+)
+
+// coverageReport returns a report of one package with a function whose
+// covered statements are given.
+func coverageReport(covered int) *scan.CoverageReport {
+	return &scan.CoverageReport{
+		Tool:      scan.CoverageGo,
+		Packages:  []scan.PackageCoverage{{Package: "internal/parse", Coverage: scan.Coverage{Statements: 10, Covered: 5 + covered}}},
+		Functions: []scan.FunctionCoverage{{Path: "internal/parse/parse.go", Line: 12, Function: "Parse", Coverage: scan.Coverage{Statements: 5, Covered: covered}}},
+	}
+}
+
+func TestCoverageGuide_VerifyThenHarmonize(t *testing.T) {
+	measurements := []*scan.CoverageReport{coverageReport(0), coverageReport(3)}
+	guide := NewCoverageGuide(func(context.Context) (*scan.CoverageReport, error) {
+		r := measurements[0]
+		measurements = measurements[1:]
+		return r, nil
+	})
+	var prompts []string
+	exec := func(_ context.Context, prompt string) error {
+		prompts = append(prompts, prompt)
+		return nil
+	}
+
+	implement := NewImplementSchedule(nil)
+	implement.SetCoverageGuide(guide)
+	if err := implement.Verify(context.Background(), exec); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !strings.Contains(prompts[0], "COVERAGE: 50.0% of 10 statements covered (go test)") ||
+		!strings.Contains(prompts[0], "- internal/parse/parse.go:12 Parse: 0.0% (5 statements uncovered)") {
+		t.Errorf("Verify prompt does not list the gaps:\n%s", prompts[0])
+	}
+
+	production := NewProductionSchedule()
+	production.SetCoverageGuide(guide)
+	if err := production.Harmonize(context.Background(), exec); err != nil {
+		t.Fatalf("Harmonize() error = %v", err)
+	}
+	if len(prompts) != 3 || !strings.HasPrefix(prompts[1], "### STEP: TEST GENERATION (Production P3)") {
+		t.Fatalf("Harmonize ran %d prompts, want the test generation step before it", len(prompts)-1)
+	}
+	if !strings.Contains(prompts[2], "TEST GENERATION: coverage 50.0% → 80.0% (+30.0 points)") {
+		t.Errorf("Harmonize prompt lacks the coverage delta:\n%s", prompts[2])
+	}
+
+	steps := guide.TakeSteps()
+	if len(steps) != 1 || steps[0].Step != "Production P3" || len(steps[0].Functions) != 1 || steps[0].Functions[0].After.Covered != 3 {
+		t.Errorf("TakeSteps() = %+v", steps)
+	}
+	if steps := guide.TakeSteps(); len(steps) != 0 {
+		t.Errorf("TakeSteps() returned %d steps again", len(steps))
+	}
+}
+
+func TestCoverageGuide_SkipsStep(t *testing.T) {
+	for name, measure := range map[string]func(context.Context) (*scan.CoverageReport, error){
+		"no gaps":      func(context.Context) (*scan.CoverageReport, error) { return coverageReport(5), nil },
+		"not measured": func(context.Context) (*scan.CoverageReport, error) { return nil, errors.New("go is not installed") },
+	} {
+		scale := NewScaleSchedule()
+		scale.SetCoverageGuide(NewCoverageGuide(measure))
+		runs := 0
+		scale.Scale(context.Background(), func(context.Context, string) error {
+			runs++
+			return nil
+		})
+		if runs != 1 {
+			t.Errorf("%s: Scale ran %d prompts, want 1", name, runs)
+		}
+	}
+}
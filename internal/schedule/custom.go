@@ -0,0 +1,141 @@
+// Package schedule implements the schedule logic for obot orchestration.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// coreScheduleIDs maps config schedule IDs to the built-in schedules.
+var coreScheduleIDs = map[string]orchestrate.ScheduleID{
+	"knowledge":  orchestrate.ScheduleKnowledge,
+	"plan":       orchestrate.SchedulePlan,
+	"implement":  orchestrate.ScheduleImplement,
+	"scale":      orchestrate.ScheduleScale,
+	"production": orchestrate.ScheduleProduction,
+}
+
+// CustomSchedule implements a config-defined schedule. Each process receives a
+// generic prompt built from the schedule and process names plus the description.
+type CustomSchedule struct {
+	Definition orchestrate.ScheduleDefinition
+}
+
+// NewCustomSchedule creates a logic handler for a custom schedule definition.
+func NewCustomSchedule(def orchestrate.ScheduleDefinition) *CustomSchedule {
+	return &CustomSchedule{Definition: def}
+}
+
+// ExecuteProcess executes a process within the custom schedule.
+func (s *CustomSchedule) ExecuteProcess(ctx context.Context, processID orchestrate.ProcessID, exec func(context.Context, string) error) error {
+	if processID < orchestrate.Process1 || processID > orchestrate.Process3 {
+		return fmt.Errorf("invalid process ID %d for %s schedule", processID, s.Definition.Name)
+	}
+
+	name := s.Definition.Processes[processID-1]
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### PROCESS: %s (%s P%d)\n", strings.ToUpper(name), s.Definition.Name, processID))
+	sb.WriteString(fmt.Sprintf("You are executing the %s process of the custom %s schedule.\n\n", name, s.Definition.Name))
+	if s.Definition.Description != "" {
+		sb.WriteString("SCHEDULE PURPOSE:\n")
+		sb.WriteString(s.Definition.Description + "\n\n")
+	}
+	sb.WriteString("PROCESSES:\n")
+	for i, p := range s.Definition.Processes {
+		marker := " "
+		if orchestrate.ProcessID(i+1) == processID {
+			marker = "*"
+		}
+		sb.WriteString(fmt.Sprintf("%s P%d %s\n", marker, i+1, p))
+	}
+	sb.WriteString("\nGUIDELINES:\n")
+	sb.WriteString("- Stay within the scope of the current process.\n")
+	sb.WriteString("- Use `core_note` to record findings the orchestrator should see.\n\n")
+	sb.WriteString("OUTPUT:\n")
+	sb.WriteString(fmt.Sprintf("A concise report of what the %s process accomplished.", name))
+
+	return exec(ctx, sb.String())
+}
+
+// RegisterConfiguredSchedules registers every schedule in cfg that is not one of
+// the core five as a custom orchestrate schedule, returning the assigned IDs.
+func RegisterConfiguredSchedules(cfg config.OrchestrationConfig) ([]orchestrate.ScheduleID, error) {
+	var ids []orchestrate.ScheduleID
+	for _, sc := range cfg.Schedules {
+		if _, core := coreScheduleIDs[strings.ToLower(sc.ID)]; core {
+			continue
+		}
+
+		def, err := definitionFromConfig(sc)
+		if err != nil {
+			return ids, err
+		}
+
+		id, err := orchestrate.RegisterSchedule(def)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// definitionFromConfig converts a schedule config entry to a schedule definition.
+func definitionFromConfig(sc config.ScheduleConfig) (orchestrate.ScheduleDefinition, error) {
+	if len(sc.Processes) != 3 {
+		return orchestrate.ScheduleDefinition{}, fmt.Errorf("schedule %q must define exactly 3 processes, got %d", sc.ID, len(sc.Processes))
+	}
+
+	def := orchestrate.ScheduleDefinition{
+		Name:         titleCase(sc.ID),
+		Description:  sc.Description,
+		Consultation: make(map[orchestrate.ProcessID]orchestrate.ConsultationType),
+	}
+	for i, p := range sc.Processes {
+		def.Processes[i] = titleCase(p)
+	}
+
+	switch orchestrate.ModelType(strings.ToLower(sc.Model)) {
+	case "":
+		def.Model = orchestrate.ModelCoder
+	case orchestrate.ModelCoder, orchestrate.ModelResearcher, orchestrate.ModelVision, orchestrate.ModelOrchestrator:
+		def.Model = orchestrate.ModelType(strings.ToLower(sc.Model))
+	default:
+		return def, fmt.Errorf("schedule %q: unknown model role %q", sc.ID, sc.Model)
+	}
+
+	for procName, entry := range sc.Consultation {
+		pid := orchestrate.ProcessID(0)
+		for i, p := range sc.Processes {
+			if strings.EqualFold(p, procName) {
+				pid = orchestrate.ProcessID(i + 1)
+			}
+		}
+		if pid == 0 {
+			return def, fmt.Errorf("schedule %q: consultation references unknown process %q", sc.ID, procName)
+		}
+
+		switch ct := orchestrate.ConsultationType(strings.ToLower(entry.Type)); ct {
+		case orchestrate.ConsultationNone, orchestrate.ConsultationOptional, orchestrate.ConsultationMandatory:
+			def.Consultation[pid] = ct
+		default:
+			return def, fmt.Errorf("schedule %q: unknown consultation type %q", sc.ID, entry.Type)
+		}
+	}
+
+	return def, nil
+}
+
+// titleCase upper-cases the first letter of each word in s.
+func titleCase(s string) string {
+	words := strings.Fields(strings.ReplaceAll(s, "_", " "))
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -72,6 +72,9 @@ func GetLogicHandler(id orchestrate.ScheduleID) LogicHandler {
 	case orchestrate.ScheduleProduction:
 		return NewProductionSchedule()
 	default:
+		if def, ok := orchestrate.GetScheduleDefinition(id); ok {
+			return NewCustomSchedule(def)
+		}
 		return nil
 	}
 }
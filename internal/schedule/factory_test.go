@@ -2,8 +2,17 @@ package schedule
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/index"
+	"github.com/croberts/obot/internal/ollama"
 	"github.com/croberts/obot/internal/orchestrate"
 )
 
@@ -58,3 +67,123 @@ func TestLogicHandler_ExecuteProcess(t *testing.T) {
 		t.Errorf("ExecuteProcess(nop) = %v, want nil", err)
 	}
 }
+
+func TestRegisterConfiguredSchedules(t *testing.T) {
+	defer orchestrate.ClearCustomSchedules()
+
+	cfg := config.DefaultUnifiedConfig().Orchestration
+	cfg.Schedules = append(cfg.Schedules, config.ScheduleConfig{
+		ID:           "security",
+		Description:  "Harden the codebase.",
+		Processes:    []string{"scan", "patch", "verify"},
+		Model:        "researcher",
+		Consultation: map[string]config.ConsultationEntry{"patch": {Type: "optional", Timeout: 60}},
+	})
+
+	ids, err := RegisterConfiguredSchedules(cfg)
+	if err != nil {
+		t.Fatalf("RegisterConfiguredSchedules() error = %v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("RegisterConfiguredSchedules() registered %d schedules, want 1", len(ids))
+	}
+
+	id := ids[0]
+	if id.String() != "Security" || orchestrate.ProcessNames[id][orchestrate.Process1] != "Scan" {
+		t.Errorf("unexpected names %q / %q", id.String(), orchestrate.ProcessNames[id][orchestrate.Process1])
+	}
+	if orchestrate.GetScheduleModel(id) != orchestrate.ModelResearcher {
+		t.Errorf("model = %v, want researcher", orchestrate.GetScheduleModel(id))
+	}
+	if orchestrate.GetProcessConsultationType(id, orchestrate.Process2) != orchestrate.ConsultationOptional {
+		t.Error("consultation for patch should be optional")
+	}
+
+	var prompt string
+	h := GetLogicHandler(id)
+	if h == nil {
+		t.Fatal("GetLogicHandler(custom) = nil")
+	}
+	_ = h.ExecuteProcess(context.Background(), orchestrate.Process2, func(ctx context.Context, s string) error {
+		prompt = s
+		return nil
+	})
+	if !strings.Contains(prompt, "PATCH (Security P2)") || !strings.Contains(prompt, "Harden the codebase.") {
+		t.Errorf("custom prompt missing schedule details:\n%s", prompt)
+	}
+}
+
+func TestRegisterConfiguredSchedules_Invalid(t *testing.T) {
+	defer orchestrate.ClearCustomSchedules()
+
+	bad := []config.ScheduleConfig{
+		{ID: "docs", Processes: []string{"write", "review"}},
+		{ID: "docs", Processes: []string{"a", "b", "c"}, Model: "painter"},
+		{ID: "docs", Processes: []string{"a", "b", "c"}, Consultation: map[string]config.ConsultationEntry{"z": {Type: "optional"}}},
+	}
+	for _, sc := range bad {
+		if _, err := RegisterConfiguredSchedules(config.OrchestrationConfig{Schedules: []config.ScheduleConfig{sc}}); err == nil {
+			t.Errorf("RegisterConfiguredSchedules(%+v) should fail", sc)
+		}
+	}
+}
+
+func TestKnowledgeSchedule_DocIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.EmbeddingResponse{Embedding: []float64{1, 0.5}})
+	}))
+	defer srv.Close()
+
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "docs", "arch.md"), []byte("# Architecture\nSchedules run three processes.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ks := NewKnowledgeSchedule()
+	ks.SetDocIndex(index.NewDocIndex(ollama.NewClient(ollama.WithBaseURL(srv.URL)), ""), workspace, "how do schedules work?")
+
+	var prompts []string
+	exec := func(ctx context.Context, prompt string) error {
+		prompts = append(prompts, prompt)
+		return nil
+	}
+	for _, proc := range []orchestrate.ProcessID{orchestrate.Process2, orchestrate.Process3} {
+		if err := ks.ExecuteProcess(context.Background(), proc, exec); err != nil {
+			t.Fatalf("ExecuteProcess(%v) error = %v", proc, err)
+		}
+	}
+
+	if !strings.Contains(prompts[0], "was indexed for retrieval (1 sections)") {
+		t.Errorf("Crawl prompt should report indexed docs:\n%s", prompts[0])
+	}
+	if !strings.Contains(prompts[1], "PROJECT DOCUMENTATION") || !strings.Contains(prompts[1], "Schedules run three processes.") {
+		t.Errorf("Retrieve prompt should include retrieved docs:\n%s", prompts[1])
+	}
+	if len(ks.Sources) != 1 || ks.Sources[0] != filepath.Join("docs", "arch.md") {
+		t.Errorf("Sources = %v", ks.Sources)
+	}
+	if len(ks.Findings) != 1 {
+		t.Errorf("Findings = %v", ks.Findings)
+	}
+}
+
+func TestPlanSchedule_ApprovedPlan(t *testing.T) {
+	plan := NewPlanSchedule(nil)
+	plan.ApprovedPlan = []string{"[T-001] [safe] main.go (todo) handle errors"}
+	for _, proc := range []orchestrate.ProcessID{orchestrate.Process1, orchestrate.Process3} {
+		var prompt string
+		err := plan.ExecuteProcess(context.Background(), proc, func(_ context.Context, p string) error {
+			prompt = p
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExecuteProcess(%v) error = %v", proc, err)
+		}
+		if !strings.Contains(prompt, "APPROVED PLAN (binding)") || !strings.Contains(prompt, "- [T-001] [safe] main.go (todo) handle errors") {
+			t.Errorf("process %v prompt lacks the approved plan:\n%s", proc, prompt)
+		}
+	}
+}
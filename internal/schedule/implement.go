@@ -4,8 +4,10 @@ package schedule
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/croberts/obot/internal/agent"
 	"github.com/croberts/obot/internal/consultation"
 	"github.com/croberts/obot/internal/orchestrate"
 )
@@ -22,6 +24,18 @@ type ImplementSchedule struct {
 	HumanApproval  bool
 
 	ConsultHandler *consultation.Handler
+
+	// Risks, when set, returns why the changes up for Feedback are high
+	// risk; the AI substitute does not approve them in the human's place.
+	Risks func() []string
+
+	// Actions, when set, returns the agent's actions, from which Feedback
+	// shows the human the changed files and the last test, lint, and build
+	// results
+	Actions func() []agent.Action
+
+	// Coverage, when set, is measured by Verify
+	Coverage *CoverageGuide
 }
 
 // NewImplementSchedule creates a new Implement schedule logic handler.
@@ -34,6 +48,12 @@ func NewImplementSchedule(handler *consultation.Handler) *ImplementSchedule {
 	}
 }
 
+// SetCoverageGuide makes Verify measure test coverage and show the model
+// the least-covered packages and functions.
+func (s *ImplementSchedule) SetCoverageGuide(g *CoverageGuide) {
+	s.Coverage = g
+}
+
 // ExecuteProcess executes a process within the Implement schedule.
 func (s *ImplementSchedule) ExecuteProcess(ctx context.Context, processID orchestrate.ProcessID, exec func(context.Context, string) error) error {
 	switch processID {
@@ -84,6 +104,9 @@ func (s *ImplementSchedule) Verify(ctx context.Context, exec func(context.Contex
 	sb.WriteString("- Ensure no new files were created accidentally.\n\n")
 	sb.WriteString("OUTPUT:\n")
 	sb.WriteString("Verification report with test/lint results.")
+	if report := s.Coverage.measure(ctx); report != nil {
+		sb.WriteString("\n\n" + coverageGapsPrompt(report))
+	}
 
 	return exec(ctx, sb.String())
 }
@@ -95,16 +118,19 @@ func (s *ImplementSchedule) Feedback(ctx context.Context, exec func(context.Cont
 	sb.WriteString("You are the demonstrator. Your mission is to GET HUMAN APPROVAL.\n\n")
 
 	if s.ConsultHandler != nil {
-		changes := []consultation.ChangeDescription{
-			{Description: "Implemented plan steps", File: "various", Lines: "all"},
-		}
-		results := consultation.VerificationResults{
-			TestsPassed: 1, TestsTotal: 1, BuildStatus: "Success",
+		var actions []agent.Action
+		if s.Actions != nil {
+			actions = s.Actions()
 		}
+		changes, results := FeedbackEvidence(actions)
 		req := consultation.FormatFeedbackRequest(changes, results, nil)
+		req.Question += "Approve these changes? Start your reply with yes or no.\n"
+		if s.Risks != nil {
+			req.Risks = s.Risks()
+		}
 		resp, err := s.ConsultHandler.Request(ctx, req)
 		if err == nil {
-			s.HumanApproval = strings.Contains(strings.ToUpper(resp.Content), "YES") || strings.Contains(strings.ToUpper(resp.Content), "APPROVE")
+			s.HumanApproval = approvalVerdict(resp.Content)
 			sb.WriteString(fmt.Sprintf("USER APPROVAL: %v\n\n", s.HumanApproval))
 		}
 	}
@@ -124,3 +150,107 @@ func (s *ImplementSchedule) Feedback(ctx context.Context, exec func(context.Cont
 	return exec(ctx, sb.String())
 }
 
+// changeVerbs describe the actions that change a file, by action type.
+var changeVerbs = map[agent.ActionType]string{
+	agent.ActionCreateFile:   "Created",
+	agent.ActionEditFile:     "Edited",
+	agent.ActionDeleteFile:   "Deleted",
+	agent.ActionRenameFile:   "Renamed",
+	agent.ActionMoveFile:     "Moved",
+	agent.ActionCopyFile:     "Copied",
+	agent.ActionFormat:       "Formatted",
+	agent.ActionRenameSymbol: "Renamed a symbol in",
+}
+
+// buildCommand matches a command that builds or compiles the project.
+var buildCommand = regexp.MustCompile(`(^|\s)(build|compile|tsc|make)(\s|$)`)
+
+// FeedbackEvidence returns what Feedback shows the human from the agent's
+// actions: the files changed, each once with what was done to it, and the
+// results of the last test, lint, and build command. A check no action ran
+// is left as not run.
+func FeedbackEvidence(actions []agent.Action) ([]consultation.ChangeDescription, consultation.VerificationResults) {
+	var changes []consultation.ChangeDescription
+	index := make(map[string]int) // changes by file
+	var results consultation.VerificationResults
+	for _, a := range actions {
+		if verb, ok := changeVerbs[a.Type]; ok && a.Path != "" {
+			if a.NewPath != "" {
+				verb += " to " + a.NewPath
+			}
+			i, seen := index[a.Path]
+			if !seen {
+				i = len(changes)
+				index[a.Path] = i
+				changes = append(changes, consultation.ChangeDescription{File: a.Path})
+			}
+			c := &changes[i]
+			switch {
+			case c.Description == "":
+				c.Description = verb
+			case !strings.Contains(strings.ToLower(c.Description), strings.ToLower(verb)):
+				c.Description += ", " + strings.ToLower(verb[:1]) + verb[1:]
+			}
+			if a.Diff != nil {
+				added, removed := 0, 0
+				fmt.Sscanf(c.Lines, "+%d -%d", &added, &removed)
+				c.Lines = fmt.Sprintf("+%d -%d", added+a.Diff.TotalAdded, removed+a.Diff.TotalRemoved)
+			}
+			continue
+		}
+		switch a.Type {
+		case agent.ActionTest:
+			results.TestsRun = true
+			results.TestsPassed, results.TestsTotal = 0, 0
+			if a.ToolResult != nil {
+				results.TestsTotal = len(a.ToolResult.Tests)
+				for _, t := range a.ToolResult.Tests {
+					if t.Status == agent.TestPassed {
+						results.TestsPassed++
+					}
+				}
+			}
+		case agent.ActionLint:
+			results.LintRun = true
+			results.LintWarnings, results.LintErrors = 0, 0
+			if a.ToolResult != nil {
+				for _, d := range a.ToolResult.Diagnostics {
+					if d.Severity == "error" {
+						results.LintErrors++
+					} else {
+						results.LintWarnings++
+					}
+				}
+			}
+		case agent.ActionRunCommand:
+			if !buildCommand.MatchString(a.Command) {
+				continue
+			}
+			results.BuildStatus = consultation.BuildSuccess
+			if a.ExitCode != 0 {
+				results.BuildStatus = fmt.Sprintf("Failed (exit %d): %s", a.ExitCode, a.Command)
+			}
+		}
+	}
+	for i := range changes {
+		if changes[i].Lines == "" {
+			changes[i].Lines = "n/a"
+		}
+	}
+	return changes, results
+}
+
+// approvalWords are the leading words of a reply that approves changes.
+var approvalWords = map[string]bool{"yes": true, "y": true, "approve": true, "approved": true, "lgtm": true}
+
+// approvalVerdict reports whether a Feedback reply approves the changes. The
+// verdict is the reply's first word, after the AI substitute's tag: yes or
+// approve approves; anything else, such as "no", "do not approve", or a
+// reply without a verdict, does not.
+func approvalVerdict(reply string) bool {
+	words := strings.Fields(strings.TrimPrefix(strings.TrimSpace(reply), "[AI-SUBSTITUTE]"))
+	if len(words) == 0 {
+		return false
+	}
+	return approvalWords[strings.ToLower(strings.TrimRight(words[0], ".,:;!"))]
+}
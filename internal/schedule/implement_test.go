@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/consultation"
+)
+
+func TestApprovalVerdict(t *testing.T) {
+	for reply, want := range map[string]bool{
+		"yes":            true,
+		"Yes, ship it":   true,
+		"APPROVE.":       true,
+		"lgtm\nthanks":   true,
+		"no":             false,
+		"do not approve": false,
+		"Not approved: the changes need a human review before they are accepted.":                 false,
+		"[AI-SUBSTITUTE] Not approved: the changes need a human review before they are accepted.": false,
+		"[AI-SUBSTITUTE] Approved: the changes follow the plan.":                                  true,
+		"I guess? yes and no": false,
+		"":                    false,
+	} {
+		if got := approvalVerdict(reply); got != want {
+			t.Errorf("approvalVerdict(%q) = %v, want %v", reply, got, want)
+		}
+	}
+}
+
+func TestFeedbackEvidence(t *testing.T) {
+	changes, results := FeedbackEvidence(nil)
+	if len(changes) != 0 || results.TestsRun || results.LintRun || results.BuildStatus != "" {
+		t.Fatalf("FeedbackEvidence(nil) = %+v, %+v, want nothing run", changes, results)
+	}
+	req := consultation.FormatFeedbackRequest(changes, results, nil)
+	for _, want := range []string{"no file changes", "Tests: not run", "Lint: not run", "Build: not run"} {
+		if !strings.Contains(req.Question, want) {
+			t.Errorf("feedback request lacks %q:\n%s", want, req.Question)
+		}
+	}
+
+	changes, results = FeedbackEvidence([]agent.Action{
+		{Type: agent.ActionCreateFile, Path: "a.go", Diff: &agent.DiffSummary{TotalAdded: 10}},
+		{Type: agent.ActionEditFile, Path: "a.go", Diff: &agent.DiffSummary{TotalAdded: 2, TotalRemoved: 1}},
+		{Type: agent.ActionRenameFile, Path: "b.go", NewPath: "c.go"},
+		{Type: agent.ActionReadFile, Path: "d.go"},
+		{Type: agent.ActionTest, ToolResult: &agent.ToolchainResult{Tests: []agent.TestCase{{Status: agent.TestPassed}}}},
+		{Type: agent.ActionTest, ToolResult: &agent.ToolchainResult{Tests: []agent.TestCase{{Status: agent.TestPassed}, {Status: agent.TestFailed}}}},
+		{Type: agent.ActionLint, ToolResult: &agent.ToolchainResult{Diagnostics: []agent.Diagnostic{{Severity: "error"}, {Severity: "warning"}}}},
+		{Type: agent.ActionRunCommand, Command: "go build ./...", ExitCode: 1},
+		{Type: agent.ActionRunCommand, Command: "git status"},
+	})
+	wantChanges := []consultation.ChangeDescription{
+		{Description: "Created, edited", File: "a.go", Lines: "+12 -1"},
+		{Description: "Renamed to c.go", File: "b.go", Lines: "n/a"},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("changes = %+v, want %+v", changes, wantChanges)
+	}
+	if !results.TestsRun || results.TestsPassed != 1 || results.TestsTotal != 2 {
+		t.Errorf("tests = %d/%d (run %v), want the last run's 1/2", results.TestsPassed, results.TestsTotal, results.TestsRun)
+	}
+	if !results.LintRun || results.LintErrors != 1 || results.LintWarnings != 1 {
+		t.Errorf("lint = %d errors, %d warnings (run %v), want 1 and 1", results.LintErrors, results.LintWarnings, results.LintRun)
+	}
+	if !strings.HasPrefix(results.BuildStatus, "Failed (exit 1)") {
+		t.Errorf("BuildStatus = %q, want the failed go build", results.BuildStatus)
+	}
+	req = consultation.FormatFeedbackRequest(changes, results, nil)
+	for _, want := range []string{"✗ Tests: 1/2 passed", "✗ Lint: 1 warnings, 1 errors", "✗ Build: Failed"} {
+		if !strings.Contains(req.Question, want) {
+			t.Errorf("feedback request lacks %q:\n%s", want, req.Question)
+		}
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/croberts/obot/internal/index"
 	"github.com/croberts/obot/internal/orchestrate"
 )
 
+// docRetrieveLimit is how many documentation chunks Retrieve adds to its prompt.
+const docRetrieveLimit = 5
+
 // KnowledgeSchedule implements the logic for the Knowledge schedule.
 // Processes: 
 // 1. Research (identify gaps): Analyze intent and identify what's missing.
@@ -19,6 +23,14 @@ type KnowledgeSchedule struct {
 	Gaps      []string
 	Sources   []string
 	Findings  []string
+
+	// Docs, when set, is filled with the workspace's documentation during
+	// Crawl and queried with Query during Retrieve.
+	Docs      *index.DocIndex
+	Workspace string
+	Query     string
+	Retrieved []index.DocChunk // chunks added to Retrieve prompts, until taken
+	ingested  bool
 }
 
 // NewKnowledgeSchedule creates a new Knowledge schedule logic handler.
@@ -30,6 +42,14 @@ func NewKnowledgeSchedule() *KnowledgeSchedule {
 	}
 }
 
+// SetDocIndex makes the schedule index the documentation of workspace into
+// docs and answer query from it, so the codebase itself is a knowledge source.
+func (s *KnowledgeSchedule) SetDocIndex(docs *index.DocIndex, workspace, query string) {
+	s.Docs = docs
+	s.Workspace = workspace
+	s.Query = query
+}
+
 // ExecuteProcess executes a process within the Knowledge schedule.
 func (s *KnowledgeSchedule) ExecuteProcess(ctx context.Context, processID orchestrate.ProcessID, exec func(context.Context, string) error) error {
 	switch processID {
@@ -81,9 +101,30 @@ func (s *KnowledgeSchedule) Crawl(ctx context.Context, exec func(context.Context
 	sb.WriteString("OUTPUT:\n")
 	sb.WriteString("A collection of technical details and verified facts.")
 
+	if n, err := s.ingestDocs(ctx); err != nil {
+		sb.WriteString("\n\nNOTE: Local documentation could not be indexed (" + err.Error() + "); rely on `read_file` for docs.")
+	} else if n > 0 {
+		fmt.Fprintf(&sb, "\n\nNOTE: Local documentation (README, docs/, package comments) was indexed for retrieval (%d sections).", n)
+	}
+
 	return exec(ctx, sb.String())
 }
 
+// ingestDocs adds the workspace's documentation to the doc index once per
+// schedule and records the indexed files as sources.
+func (s *KnowledgeSchedule) ingestDocs(ctx context.Context) (int, error) {
+	if s.Docs == nil || s.ingested {
+		return 0, nil
+	}
+	n, err := s.Docs.Ingest(ctx, s.Workspace)
+	if err != nil {
+		return 0, err
+	}
+	s.ingested = true
+	s.Sources = append(s.Sources, s.Docs.Sources()...)
+	return n, nil
+}
+
 // Retrieve (P3) structures the information into a usable form for planning.
 func (s *KnowledgeSchedule) Retrieve(ctx context.Context, exec func(context.Context, string) error) error {
 	var sb strings.Builder
@@ -101,6 +142,42 @@ func (s *KnowledgeSchedule) Retrieve(ctx context.Context, exec func(context.Cont
 	sb.WriteString("OUTPUT:\n")
 	sb.WriteString("A structured knowledge base and a set of session notes.")
 
+	if docs := s.retrieveDocs(ctx); len(docs) > 0 {
+		sb.WriteString("\n\nPROJECT DOCUMENTATION (retrieved from the local doc index):\n")
+		for _, d := range docs {
+			fmt.Fprintf(&sb, "\n--- %s (%s) ---\n%s\n", d.Title, d.Source, d.Text)
+		}
+	}
+
 	return exec(ctx, sb.String())
 }
 
+
+// retrieveDocs returns the documentation most relevant to the query and
+// records it as findings. Crawl normally indexes the docs first; Retrieve
+// indexes them itself when Crawl was skipped.
+func (s *KnowledgeSchedule) retrieveDocs(ctx context.Context) []index.DocChunk {
+	if s.Docs == nil || s.Query == "" {
+		return nil
+	}
+	if _, err := s.ingestDocs(ctx); err != nil {
+		return nil
+	}
+	docs, err := s.Docs.Retrieve(ctx, s.Query, docRetrieveLimit)
+	if err != nil {
+		return nil
+	}
+	for _, d := range docs {
+		s.Findings = append(s.Findings, d.ID())
+	}
+	s.Retrieved = append(s.Retrieved, docs...)
+	return docs
+}
+
+// TakeRetrieved returns the documentation chunks retrieved since the last
+// call, so the caller can record where the knowledge came from.
+func (s *KnowledgeSchedule) TakeRetrieved() []index.DocChunk {
+	docs := s.Retrieved
+	s.Retrieved = nil
+	return docs
+}
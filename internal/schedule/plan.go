@@ -20,8 +20,18 @@ type PlanSchedule struct {
 	Approaches  []string
 	Ambiguities []string
 	FinalSteps  []string
+	Decisions   []consultation.Choice // options picked in Clarify
+
+	// ApprovedPlan lists the subtasks the human approved before
+	// orchestration; the plan must keep to them
+	ApprovedPlan []string
 
 	ConsultHandler *consultation.Handler
+
+	// Response, when set, returns the model's reply to the last process,
+	// from which Brainstorm takes the approaches and open questions that
+	// Clarify puts to the human
+	Response func() string
 }
 
 // NewPlanSchedule creates a new Plan schedule logic handler.
@@ -53,6 +63,7 @@ func (s *PlanSchedule) Brainstorm(ctx context.Context, exec func(context.Context
 	var sb strings.Builder
 	sb.WriteString("### PROCESS: BRAINSTORM (Plan P1)\n")
 	sb.WriteString("You are the architect. Your mission is to GENERATE APPROACHES.\n\n")
+	s.writeApprovedPlan(&sb)
 	sb.WriteString("TASKS:\n")
 	sb.WriteString("1. **Analyze Context**: Review findings from the Knowledge schedule.\n")
 	sb.WriteString("2. **Divergent Thinking**: Generate at least 2-3 different ways to solve the problem.\n")
@@ -63,9 +74,40 @@ func (s *PlanSchedule) Brainstorm(ctx context.Context, exec func(context.Context
 	sb.WriteString("- Look for reusable patterns or existing utilities that can be leveraged.\n")
 	sb.WriteString("- Document assumptions clearly.\n\n")
 	sb.WriteString("OUTPUT:\n")
-	sb.WriteString("A list of potential approaches with trade-off analysis.")
+	sb.WriteString("A list of potential approaches with trade-off analysis. End with an `APPROACHES:` section naming each approach on a `- ` line, and an `OPEN QUESTIONS:` section listing, one per `- ` line, each decision only the human can make (or `- none`).")
 
-	return exec(ctx, sb.String())
+	if err := exec(ctx, sb.String()); err != nil {
+		return err
+	}
+	if s.Response != nil {
+		s.Approaches, s.Ambiguities = brainstormSections(s.Response())
+	}
+	return nil
+}
+
+// brainstormSections returns the items of the APPROACHES and OPEN QUESTIONS
+// sections of a Brainstorm reply. An item of "none" is left out.
+func brainstormSections(reply string) (approaches, questions []string) {
+	var section *[]string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		header := strings.ToUpper(strings.Trim(line, "#*_` "))
+		switch {
+		case header == "APPROACHES:":
+			section = &approaches
+		case header == "OPEN QUESTIONS:":
+			section = &questions
+		case section == nil || line == "":
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			item := strings.TrimSpace(line[2:])
+			if item != "" && !strings.EqualFold(strings.TrimRight(item, "."), "none") {
+				*section = append(*section, item)
+			}
+		default:
+			section = nil
+		}
+	}
+	return approaches, questions
 }
 
 // Clarify (P2) resolves ambiguities via optional human consultation.
@@ -78,8 +120,9 @@ func (s *PlanSchedule) Clarify(ctx context.Context, exec func(context.Context, s
 	if s.ConsultHandler != nil && len(s.Ambiguities) > 0 {
 		req := consultation.FormatClarifyRequest("Decision point in planning", s.Ambiguities[0], s.Approaches)
 		resp, err := s.ConsultHandler.Request(ctx, req)
-		if err == nil {
-			sb.WriteString(fmt.Sprintf("USER DECISION: %s\n\n", resp.Content))
+		if err == nil && resp.Choice != nil {
+			s.Decisions = append(s.Decisions, *resp.Choice)
+			sb.WriteString(fmt.Sprintf("USER DECISION: %s\n\n", resp.Choice))
 		}
 	}
 
@@ -103,6 +146,7 @@ func (s *PlanSchedule) Plan(ctx context.Context, exec func(context.Context, stri
 	var sb strings.Builder
 	sb.WriteString("### PROCESS: PLAN (Plan P3)\n")
 	sb.WriteString("You are the lead planner. Your mission is to SYNTHESIZE INTO STEPS.\n\n")
+	s.writeApprovedPlan(&sb)
 	sb.WriteString("TASKS:\n")
 	sb.WriteString("1. **Breakdown Tasks**: Divide the chosen strategy into small, atomic implementation steps.\n")
 	sb.WriteString("2. **Sequence Work**: Determine the order of execution (types first, then logic, then UI, etc.).\n")
@@ -118,3 +162,17 @@ func (s *PlanSchedule) Plan(ctx context.Context, exec func(context.Context, stri
 	return exec(ctx, sb.String())
 }
 
+
+// writeApprovedPlan writes the approved subtasks as a binding constraint,
+// if the human approved a plan.
+func (s *PlanSchedule) writeApprovedPlan(sb *strings.Builder) {
+	if len(s.ApprovedPlan) == 0 {
+		return
+	}
+	sb.WriteString("APPROVED PLAN (binding):\n")
+	sb.WriteString("The human approved these subtasks before orchestration. Cover each of them, and do not add work outside them, drop one, or raise its risk without consulting the human.\n")
+	for _, task := range s.ApprovedPlan {
+		sb.WriteString("- " + task + "\n")
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,33 @@
+package schedule
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBrainstormSections(t *testing.T) {
+	reply := "Approach one uses a cache.\n\n## APPROACHES:\n- In-memory cache\n* Redis\n\n**OPEN QUESTIONS:**\n- Must entries survive restarts?\n\nRisks: none."
+	approaches, questions := brainstormSections(reply)
+	if want := []string{"In-memory cache", "Redis"}; !reflect.DeepEqual(approaches, want) {
+		t.Errorf("approaches = %q, want %q", approaches, want)
+	}
+	if want := []string{"Must entries survive restarts?"}; !reflect.DeepEqual(questions, want) {
+		t.Errorf("questions = %q, want %q", questions, want)
+	}
+
+	if _, questions := brainstormSections("OPEN QUESTIONS:\n- none\n"); len(questions) != 0 {
+		t.Errorf("questions = %q, want none", questions)
+	}
+}
+
+func TestPlanSchedule_BrainstormFillsAmbiguities(t *testing.T) {
+	s := NewPlanSchedule(nil)
+	s.Response = func() string { return "APPROACHES:\n- A\n- B\nOPEN QUESTIONS:\n- Which one?\n" }
+	if err := s.Brainstorm(context.Background(), func(context.Context, string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Approaches) != 2 || len(s.Ambiguities) != 1 {
+		t.Errorf("Approaches = %q, Ambiguities = %q, want 2 and 1", s.Approaches, s.Ambiguities)
+	}
+}
@@ -23,6 +23,10 @@ type ScaleSchedule struct {
 	Metrics map[string]float64
 	Hotspots []string
 	Reports  []string
+
+	// Coverage, when set, has Scale write tests for the coverage gaps
+	// Verify found before refactoring
+	Coverage *CoverageGuide
 }
 
 // NewScaleSchedule creates a new Scale schedule logic handler.
@@ -34,6 +38,12 @@ func NewScaleSchedule() *ScaleSchedule {
 	}
 }
 
+// SetCoverageGuide adds a test generation step to Scale, so that the code
+// it refactors is covered by tests first.
+func (s *ScaleSchedule) SetCoverageGuide(g *CoverageGuide) {
+	s.Coverage = g
+}
+
 // ExecuteProcess executes a process within the Scale schedule.
 func (s *ScaleSchedule) ExecuteProcess(ctx context.Context, processID orchestrate.ProcessID, exec func(context.Context, string) error) error {
 	switch processID {
@@ -50,6 +60,11 @@ func (s *ScaleSchedule) ExecuteProcess(ctx context.Context, processID orchestrat
 
 // Scale (P1) identifies scalability concerns and performs initial refactoring.
 func (s *ScaleSchedule) Scale(ctx context.Context, exec func(context.Context, string) error) error {
+	step, err := s.Coverage.generateTests(ctx, "Scale P1", exec)
+	if err != nil {
+		return err
+	}
+
 	var sb strings.Builder
 	sb.WriteString("### PROCESS: SCALE (Scale P1)\n")
 	sb.WriteString("You are the performance architect. Your mission is to IDENTIFY CONCERNS and REFACTOR.\n\n")
@@ -65,6 +80,9 @@ func (s *ScaleSchedule) Scale(ctx context.Context, exec func(context.Context, st
 	sb.WriteString("- Use concurrency where it provides clear benefits and doesn't overcomplicate the design.\n\n")
 	sb.WriteString("OUTPUT:\n")
 	sb.WriteString("A detailed scalability report and initial performance refactors.")
+	if step != nil {
+		sb.WriteString("\n\nTEST GENERATION: coverage " + step.String() + "; rely on these tests to keep refactors correct.")
+	}
 
 	return exec(ctx, sb.String())
 }
@@ -0,0 +1,54 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+)
+
+// SetAnalysis records the expert judges' analysis of the session, as JSON,
+// and its rendered TLDR, which is added to the session summary.
+func (s *Session) SetAnalysis(analysis json.RawMessage, tldr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analysis = analysis
+	s.tldr = tldr
+}
+
+// GetAnalysis returns the judges' analysis and rendered TLDR, nil and ""
+// when the session was not judged.
+func (s *Session) GetAnalysis() (json.RawMessage, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.analysis, s.tldr
+}
+
+// saveAnalysis writes the analysis to analysis.json and the TLDR to
+// tldr.txt in the session.
+func (s *Session) saveAnalysis() error {
+	if s.analysis == nil {
+		return nil
+	}
+	if err := s.putEncoded("analysis.json", s.analysis); err != nil {
+		return err
+	}
+	return s.put("tldr.txt", []byte(s.tldr), 0644)
+}
+
+// loadAnalysis reads the analysis and TLDR of a session from store if
+// present.
+func loadAnalysis(store Storage, sessionID string) (json.RawMessage, string, error) {
+	analysis, err := store.ReadFile(path.Join(sessionID, "analysis.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	tldr, err := store.ReadFile(path.Join(sessionID, "tldr.txt"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", err
+	}
+	return analysis, string(tldr), nil
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSessionAnalysis_SaveLoad(t *testing.T) {
+	baseDir := t.TempDir()
+	s := NewSessionWithBaseDir(baseDir)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if loaded, err := Load(baseDir, s.ID); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	} else if analysis, tldr := loaded.GetAnalysis(); analysis != nil || tldr != "" {
+		t.Errorf("unjudged session loaded analysis %s, %q", analysis, tldr)
+	}
+
+	s.SetAnalysis(json.RawMessage(`{"Failures":["vision"]}`), "QUALITY ASSESSMENT: ACCEPTABLE\n")
+	if !strings.HasSuffix(s.GenerateSummary(), "\nQUALITY ASSESSMENT: ACCEPTABLE\n") {
+		t.Errorf("GenerateSummary() = %q, want the TLDR appended", s.GenerateSummary())
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(baseDir, s.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	analysis, tldr := loaded.GetAnalysis()
+	if string(analysis) != `{"Failures":["vision"]}` || tldr != "QUALITY ASSESSMENT: ACCEPTABLE\n" {
+		t.Errorf("loaded analysis %s, %q", analysis, tldr)
+	}
+}
@@ -0,0 +1,16 @@
+package session
+
+// SetBaseline records the workspace as it was when the run started, a git
+// tree the judges diff the run's changes against.
+func (s *Session) SetBaseline(tree string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.baseline = tree
+}
+
+// GetBaseline returns the tree SetBaseline recorded, "" when none was.
+func (s *Session) GetBaseline() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.baseline
+}
@@ -0,0 +1,19 @@
+package session
+
+import "testing"
+
+func TestSessionBaseline_SaveLoad(t *testing.T) {
+	baseDir := t.TempDir()
+	s := NewSessionWithBaseDir(baseDir)
+	s.SetBaseline("4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(baseDir, s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.GetBaseline(); got != "4b825dc642cb6eb9a060e54bf8d69288fbee4904" {
+		t.Errorf("baseline = %q after loading", got)
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// ConsultationChoice is the option a consultation's answer picked.
+type ConsultationChoice struct {
+	Key       string `json:"key"` // A, B, C, ...
+	Option    string `json:"option"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// ConsultationRecord is a question put to the human during the session
+// and the answer given, by the human or the AI substitute.
+type ConsultationRecord struct {
+	Type      string              `json:"type"` // clarify, feedback, ...
+	Question  string              `json:"question"`
+	Options   []string            `json:"options,omitempty"`
+	Answer    string              `json:"answer"`
+	Choice    *ConsultationChoice `json:"choice,omitempty"`
+	Source    string              `json:"source"`            // human or ai_substitute
+	Persona   string              `json:"persona,omitempty"` // the AI substitute's persona, for its answers
+	Timestamp time.Time           `json:"timestamp"`
+}
+
+// RecordConsultation appends a consultation and its answer to the session
+// and counts it in the session stats.
+func (s *Session) RecordConsultation(rec ConsultationRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.consultations = append(s.consultations, rec)
+	if s.stats != nil {
+		switch rec.Type {
+		case "clarify":
+			s.stats.Consultation.Clarifications++
+		case "feedback":
+			s.stats.Consultation.Feedback++
+		}
+		if rec.Source == "ai_substitute" {
+			s.stats.Consultation.Substituted++
+		}
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// GetConsultations returns the recorded consultations in order.
+func (s *Session) GetConsultations() []ConsultationRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]ConsultationRecord, len(s.consultations))
+	copy(result, s.consultations)
+	return result
+}
+
+// saveConsultations writes the consultations to consultations.json in the
+// session.
+func (s *Session) saveConsultations() error {
+	if len(s.consultations) == 0 {
+		return nil
+	}
+	return s.putJSON("consultations.json", s.consultations)
+}
+
+// loadConsultations reads consultations.json of a session from store if
+// present.
+func loadConsultations(store Storage, sessionID string) ([]ConsultationRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "consultations.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []ConsultationRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session consultations: %w", err)
+	}
+	return records, nil
+}
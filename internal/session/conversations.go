@@ -0,0 +1,90 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Kinds of conversation turns
+const (
+	TurnPrompt   = "prompt"   // sent to the model
+	TurnResponse = "response" // the model's reply
+	TurnAction   = "action"   // an action the agent took
+	TurnError    = "error"    // a failed request or action
+)
+
+// ConversationTurn is one turn of the agent's conversation in a process.
+type ConversationTurn struct {
+	Kind      string    `json:"kind"`
+	Model     string    `json:"model,omitempty"` // model role, for prompts and responses
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ConversationRecord is the agent's conversation during one run of a
+// process: what it sent its models, what they replied, and what it did.
+type ConversationRecord struct {
+	Schedule  orchestrate.ScheduleID `json:"schedule"`
+	Process   orchestrate.ProcessID  `json:"process"`
+	Turns     []ConversationTurn     `json:"turns"`
+	Error     string                 `json:"error,omitempty"` // why the run failed
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// RecordConversation appends the agent's conversation in a process run to
+// the session.
+func (s *Session) RecordConversation(rec ConversationRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.conversations = append(s.conversations, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetConversations returns the agent's conversations in the runs of a
+// process, in order.
+func (s *Session) GetConversations(scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) []ConversationRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []ConversationRecord
+	for _, rec := range s.conversations {
+		if rec.Schedule == scheduleID && rec.Process == processID {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// saveConversations writes the conversations to conversations.json in the
+// session.
+func (s *Session) saveConversations() error {
+	if len(s.conversations) == 0 {
+		return nil
+	}
+	return s.putJSON("conversations.json", s.conversations)
+}
+
+// loadConversations reads conversations.json of a session from store if
+// present.
+func loadConversations(store Storage, sessionID string) ([]ConversationRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "conversations.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []ConversationRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session conversations: %w", err)
+	}
+	return records, nil
+}
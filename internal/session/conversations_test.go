@@ -0,0 +1,37 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestSessionConversations_SaveLoad(t *testing.T) {
+	baseDir := t.TempDir()
+	s := NewSessionWithBaseDir(baseDir)
+	s.ID = "chat"
+	s.RecordConversation(ConversationRecord{
+		Schedule: orchestrate.ScheduleImplement,
+		Process:  orchestrate.Process1,
+		Turns: []ConversationTurn{
+			{Kind: TurnPrompt, Model: "coder", Content: "write the handler"},
+			{Kind: TurnResponse, Model: "coder", Content: "done"},
+		},
+	})
+	s.RecordConversation(ConversationRecord{Schedule: orchestrate.ScheduleImplement, Process: orchestrate.Process2, Error: "tests failed"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(baseDir, "chat")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	runs := loaded.GetConversations(orchestrate.ScheduleImplement, orchestrate.Process1)
+	if len(runs) != 1 || len(runs[0].Turns) != 2 || runs[0].Turns[1].Content != "done" || runs[0].Timestamp.IsZero() {
+		t.Fatalf("S3P1 conversations = %+v", runs)
+	}
+	if runs := loaded.GetConversations(orchestrate.ScheduleImplement, orchestrate.Process2); len(runs) != 1 || runs[0].Error != "tests failed" {
+		t.Errorf("S3P2 conversations = %+v", runs)
+	}
+}
@@ -21,7 +21,7 @@ func (s *Session) ToUnified() *UnifiedSession {
 		PlatformOrigin: "ide", // Default for ide-originating sessions
 		Task: USFTask{
 			Description: s.prompt,
-			Status:      "in_progress",
+			Status:      s.status.usfStatus(),
 		},
 		Orchestration: USFOrchestration{
 			FlowCode:        s.flowCode,
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// CoverageRecord is the test coverage a test generation step gained,
+// recorded during a session.
+type CoverageRecord struct {
+	Step       string           `json:"step"`   // the schedule and process, e.g. "Production P3"
+	Tool       string           `json:"tool"`   // "go test" or "coverage.py"
+	Before     float64          `json:"before"` // percent of statements covered
+	After      float64          `json:"after"`
+	Statements int              `json:"statements"` // statements measured after the step
+	Targets    []CoverageTarget `json:"targets,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+// CoverageTarget is a function a test generation step wrote tests for,
+// with its coverage before and after.
+type CoverageTarget struct {
+	Path     string  `json:"path"`
+	Function string  `json:"function"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+}
+
+// RecordCoverage appends a test generation step's coverage to the session.
+func (s *Session) RecordCoverage(rec CoverageRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.coverage = append(s.coverage, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetCoverage returns all recorded test generation steps.
+func (s *Session) GetCoverage() []CoverageRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]CoverageRecord, len(s.coverage))
+	copy(result, s.coverage)
+	return result
+}
+
+// saveCoverage writes the coverage records to coverage.json in the session.
+func (s *Session) saveCoverage() error {
+	if len(s.coverage) == 0 {
+		return nil
+	}
+	return s.putJSON("coverage.json", s.coverage)
+}
+
+// loadCoverage reads coverage.json of a session from store if present.
+func loadCoverage(store Storage, sessionID string) ([]CoverageRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "coverage.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []CoverageRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session coverage: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,67 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// DegradationRecord is a decision, recorded during a session, to trade
+// quality for memory under pressure or to restore it once the pressure
+// passed.
+type DegradationRecord struct {
+	Level     string    `json:"level"`    // "normal", "warning", or "critical"
+	Resource  string    `json:"resource"` // "memory" or "vram"
+	Schedule  string    `json:"schedule,omitempty"`
+	Process   string    `json:"process,omitempty"`
+	Steps     []string  `json:"steps"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RecordDegradation appends a degradation decision to the session.
+func (s *Session) RecordDegradation(rec DegradationRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.degradations = append(s.degradations, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetDegradations returns all recorded degradation decisions.
+func (s *Session) GetDegradations() []DegradationRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]DegradationRecord, len(s.degradations))
+	copy(result, s.degradations)
+	return result
+}
+
+// saveDegradations writes the degradation records to degradations.json in
+// the session.
+func (s *Session) saveDegradations() error {
+	if len(s.degradations) == 0 {
+		return nil
+	}
+	return s.putJSON("degradations.json", s.degradations)
+}
+
+// loadDegradations reads degradations.json of a session from store if present.
+func loadDegradations(store Storage, sessionID string) ([]DegradationRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "degradations.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []DegradationRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session degradations: %w", err)
+	}
+	return records, nil
+}
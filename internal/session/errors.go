@@ -0,0 +1,75 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// ErrorRecord is an error occurrence recorded during a session, together
+// with the resolution taken (retry, skip, abort, adjust, ...).
+type ErrorRecord struct {
+	Code       string    `json:"code"`
+	Impact     string    `json:"impact"`
+	Component  string    `json:"component"`
+	Message    string    `json:"message"`
+	Schedule   string    `json:"schedule,omitempty"`
+	Process    string    `json:"process,omitempty"`
+	Resolution string    `json:"resolution"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// RecordError appends an error occurrence to the session.
+func (s *Session) RecordError(rec ErrorRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.errors = append(s.errors, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetErrors returns all recorded error occurrences.
+func (s *Session) GetErrors() []ErrorRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]ErrorRecord, len(s.errors))
+	copy(result, s.errors)
+	return result
+}
+
+// ErrorCountsByCode returns the number of recorded errors per code.
+func (s *Session) ErrorCountsByCode() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for _, rec := range s.errors {
+		counts[rec.Code]++
+	}
+	return counts
+}
+
+// saveErrors writes the error records to errors.json in the session.
+func (s *Session) saveErrors() error {
+	return s.putJSON("errors.json", s.errors)
+}
+
+// loadErrors reads errors.json of a session from store if present.
+func loadErrors(store Storage, sessionID string) ([]ErrorRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "errors.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []ErrorRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session errors: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/fswalk"
+)
+
+// fileHash is the content hash of a file together with the size and
+// modification time it was computed at.
+type fileHash struct {
+	size    int64
+	modTime time.Time
+	sum     [sha256.Size]byte
+}
+
+// hashJob is a file whose content must be hashed.
+type hashJob struct {
+	rel  string
+	path string
+	info os.FileInfo
+}
+
+// computeFilesHash computes a SHA256 hash of the project files that
+// fswalk visits by default, skipping the sessions directory. Files whose size
+// and modification time are unchanged since the previous call reuse their
+// cached hash; the others are hashed concurrently. The caller must hold s.mu.
+func (s *Session) computeFilesHash() string {
+	root, err := os.Getwd()
+	if err != nil {
+		root = "."
+	}
+
+	cached := s.fileHashes
+	next := make(map[string]fileHash, len(cached))
+	var rels []string
+	var jobs []hashJob
+
+	_ = fswalk.Walk(root, fswalk.Options{SkipPaths: []string{s.baseDir}}, func(e fswalk.Entry) error {
+		rels = append(rels, e.Rel)
+		if h, ok := cached[e.Rel]; ok && h.size == e.Info.Size() && h.modTime.Equal(e.Info.ModTime()) {
+			next[e.Rel] = h
+			return nil
+		}
+		jobs = append(jobs, hashJob{rel: e.Rel, path: e.Path, info: e.Info})
+		return nil
+	})
+
+	for rel, h := range hashFiles(jobs) {
+		next[rel] = h
+	}
+	s.fileHashes = next
+
+	sort.Strings(rels)
+	hasher := sha256.New()
+	for _, rel := range rels {
+		h, ok := next[rel]
+		if !ok {
+			continue // unreadable
+		}
+		hasher.Write([]byte(rel))
+		hasher.Write(h.sum[:])
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// hashFiles hashes the files of jobs with a worker pool, omitting files that
+// cannot be read.
+func hashFiles(jobs []hashJob) map[string]fileHash {
+	results := make(map[string]fileHash, len(jobs))
+	if len(jobs) == 0 {
+		return results
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan hashJob)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				sum, err := hashFileContent(job.path)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				results[job.rel] = fileHash{size: job.info.Size(), modTime: job.info.ModTime(), sum: sum}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func hashFileContent(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeWorkspace(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestComputeFilesHash(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	writeWorkspace(t, root, map[string]string{
+		".gitignore":        "out/\n*.log\n!keep.log\n/docs/gen\n",
+		".obotignore":       "secrets.txt\n",
+		"main.go":           "package main\n",
+		"out/result.txt":    "x",
+		"build/out.bin":     "x",
+		"debug.log":         "x",
+		"keep.log":          "x",
+		"secrets.txt":       "x",
+		"docs/gen/api.md":   "x",
+		"docs/guide.md":     "x",
+		"pkg/.gitignore":    "tmp_*\n",
+		"pkg/tmp_a.go":      "x",
+		"pkg/lib.go":        "package pkg\n",
+		".hidden/secret":    "x",
+		"node_modules/m.js": "x",
+	})
+
+	s := NewSessionWithBaseDir(filepath.Join(root, ".sessions"))
+	first := s.computeFilesHash()
+
+	want := []string{"docs/guide.md", "keep.log", "main.go", "pkg/lib.go"}
+	if len(s.fileHashes) != len(want) {
+		t.Fatalf("hashed %d files, want %v: %v", len(s.fileHashes), want, s.fileHashes)
+	}
+	for _, rel := range want {
+		if _, ok := s.fileHashes[rel]; !ok {
+			t.Errorf("%s was not hashed", rel)
+		}
+	}
+
+	// Ignored files do not affect the hash
+	writeWorkspace(t, root, map[string]string{"out/result.txt": "changed", "pkg/tmp_b.go": "x"})
+	if got := s.computeFilesHash(); got != first {
+		t.Error("changing ignored files changed the hash")
+	}
+
+	// A cached hash is reused while size and mtime are unchanged
+	entry := s.fileHashes["main.go"]
+	entry.sum[0] ^= 0xff
+	s.fileHashes["main.go"] = entry
+	if got := s.computeFilesHash(); got == first {
+		t.Error("cached hash was not reused")
+	}
+
+	// A changed file is rehashed
+	writeWorkspace(t, root, map[string]string{"main.go": "package main // edited\n"})
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "main.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	edited := s.computeFilesHash()
+	if edited == first {
+		t.Error("editing a file did not change the hash")
+	}
+	if fresh := NewSessionWithBaseDir(filepath.Join(root, ".sessions")).computeFilesHash(); fresh != edited {
+		t.Error("incremental hash differs from a full rehash")
+	}
+}
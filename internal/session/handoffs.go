@@ -0,0 +1,67 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// HandoffRecord is a handoff between models recorded during a session, with
+// the summary the outgoing model left for the incoming one.
+type HandoffRecord struct {
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	Schedule      string    `json:"schedule,omitempty"`
+	Process       string    `json:"process,omitempty"`
+	State         string    `json:"state"`
+	OpenQuestions []string  `json:"open_questions,omitempty"`
+	Artifacts     []string  `json:"artifacts,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// RecordHandoff appends a model handoff to the session.
+func (s *Session) RecordHandoff(rec HandoffRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	s.handoffs = append(s.handoffs, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetHandoffs returns all recorded model handoffs.
+func (s *Session) GetHandoffs() []HandoffRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]HandoffRecord, len(s.handoffs))
+	copy(result, s.handoffs)
+	return result
+}
+
+// saveHandoffs writes the handoff records to handoffs.json in the session.
+func (s *Session) saveHandoffs() error {
+	if len(s.handoffs) == 0 {
+		return nil
+	}
+	return s.putJSON("handoffs.json", s.handoffs)
+}
+
+// loadHandoffs reads handoffs.json of a session from store if present.
+func loadHandoffs(store Storage, sessionID string) ([]HandoffRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "handoffs.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []HandoffRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session handoffs: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,86 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+	"unicode/utf8"
+)
+
+// maxRecordedBody bounds the response body kept in an HTTP record.
+const maxRecordedBody = 4096
+
+// HTTPRecord is an HTTP request the agent sent to verify an endpoint, with
+// the response it got.
+type HTTPRecord struct {
+	Schedule  string        `json:"schedule,omitempty"`
+	Process   string        `json:"process,omitempty"`
+	Method    string        `json:"method"`
+	URL       string        `json:"url"`
+	Status    int           `json:"status,omitempty"` // 0 when no response arrived
+	Body      string        `json:"body,omitempty"`   // response body, truncated
+	Duration  time.Duration `json:"duration"`
+	Failures  []string      `json:"failures,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Passed reports whether the request got a response meeting its assertions.
+func (r HTTPRecord) Passed() bool {
+	return r.Error == "" && len(r.Failures) == 0
+}
+
+// RecordHTTP appends an HTTP request to the session, truncating the body.
+func (s *Session) RecordHTTP(rec HTTPRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+	if len(rec.Body) > maxRecordedBody {
+		// Cut on a rune boundary so the body stays valid UTF-8
+		cut := maxRecordedBody
+		for cut > 0 && !utf8.RuneStart(rec.Body[cut]) {
+			cut--
+		}
+		rec.Body = rec.Body[:cut] + "\n[truncated]"
+	}
+	s.httpRecords = append(s.httpRecords, rec)
+	s.UpdatedAt = time.Now()
+}
+
+// GetHTTPRecords returns all recorded HTTP requests.
+func (s *Session) GetHTTPRecords() []HTTPRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]HTTPRecord, len(s.httpRecords))
+	copy(result, s.httpRecords)
+	return result
+}
+
+// saveHTTPRecords writes the HTTP records to http.json in the session.
+func (s *Session) saveHTTPRecords() error {
+	if len(s.httpRecords) == 0 {
+		return nil
+	}
+	return s.putJSON("http.json", s.httpRecords)
+}
+
+// loadHTTPRecords reads http.json of a session from store if present.
+func loadHTTPRecords(store Storage, sessionID string) ([]HTTPRecord, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "http.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []HTTPRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse session HTTP records: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,37 @@
+package session
+
+// SetContinues records the session this one continues, whose judges'
+// recommendations seeded its prompt.
+func (s *Session) SetContinues(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.continues = sessionID
+}
+
+// GetContinues returns the session this one continues, "" for a session
+// that started fresh.
+func (s *Session) GetContinues() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.continues
+}
+
+// AddContinuation records a session that continues this one.
+func (s *Session) AddContinuation(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.continuedBy {
+		if id == sessionID {
+			return
+		}
+	}
+	s.continuedBy = append(s.continuedBy, sessionID)
+}
+
+// GetContinuations returns the sessions that continue this one, oldest
+// first.
+func (s *Session) GetContinuations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.continuedBy...)
+}
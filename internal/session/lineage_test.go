@@ -0,0 +1,49 @@
+package session
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSessionLineage_SaveLoad(t *testing.T) {
+	baseDir := t.TempDir()
+	parent := NewSessionWithBaseDir(baseDir)
+	parent.ID = "parent"
+	child := NewSessionWithBaseDir(baseDir)
+	child.ID = "child"
+
+	child.SetContinues(parent.ID)
+	parent.AddContinuation(child.ID)
+	parent.AddContinuation(child.ID)
+	for _, s := range []*Session{parent, child} {
+		if err := s.Save(); err != nil {
+			t.Fatalf("Save(%s) error = %v", s.ID, err)
+		}
+	}
+
+	loadedParent, err := Load(baseDir, "parent")
+	if err != nil {
+		t.Fatalf("Load(parent) error = %v", err)
+	}
+	if got := loadedParent.GetContinuations(); !reflect.DeepEqual(got, []string{"child"}) {
+		t.Errorf("continuations = %v, want [child]", got)
+	}
+	if got := loadedParent.GetContinues(); got != "" {
+		t.Errorf("parent continues %q, want none", got)
+	}
+	if !strings.Contains(loadedParent.GenerateSummary(), "Continued By: child\n") {
+		t.Errorf("parent summary = %q", loadedParent.GenerateSummary())
+	}
+
+	loadedChild, err := Load(baseDir, "child")
+	if err != nil {
+		t.Fatalf("Load(child) error = %v", err)
+	}
+	if got := loadedChild.GetContinues(); got != "parent" {
+		t.Errorf("child continues %q, want parent", got)
+	}
+	if !strings.Contains(loadedChild.GenerateSummary(), "Continues: parent\n") {
+		t.Errorf("child summary = %q", loadedChild.GenerateSummary())
+	}
+}
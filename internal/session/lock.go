@@ -0,0 +1,135 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// runLockFile marks a session as running. It is removed when the run
+// terminates, so a lock left behind by a dead process means the run crashed
+// or was killed.
+const runLockFile = "run.lock"
+
+// RunLock records the process running a session.
+type RunLock struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	Workspace string    `json:"workspace"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Stale reports whether the process holding the lock is gone. Locks held on
+// another host cannot be checked and are never considered stale.
+func (l *RunLock) Stale() bool {
+	host, _ := os.Hostname()
+	if l.Host != host {
+		return false
+	}
+	return !processAlive(l.PID)
+}
+
+// AcquireRunLock marks the session as running in workspace by the current process.
+func (s *Session) AcquireRunLock(workspace string) error {
+	sessionDir := filepath.Join(s.baseDir, s.ID)
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+	return writeJSON(filepath.Join(sessionDir, runLockFile), RunLock{
+		PID:       os.Getpid(),
+		Host:      host,
+		Workspace: workspace,
+		StartedAt: time.Now(),
+	})
+}
+
+// ReleaseRunLock marks the session as no longer running.
+func (s *Session) ReleaseRunLock() error {
+	err := os.Remove(filepath.Join(s.baseDir, s.ID, runLockFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadRunLock returns the run lock of a session, or nil if it has none.
+func ReadRunLock(baseDir, sessionID string) (*RunLock, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, sessionID, runLockFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var lock RunLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// FindUnterminated returns the sessions whose run locks in baseDir show they
+// were running in workspace when their process died, most recently started
+// first. The sessions are loaded from store.
+func FindUnterminated(baseDir string, store Storage, workspace string) ([]*Session, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+
+	type candidate struct {
+		sess    *Session
+		started time.Time
+	}
+	var found []candidate
+	for _, id := range ids {
+		lock, err := ReadRunLock(baseDir, id)
+		if err != nil || lock == nil || lock.Workspace != workspace || !lock.Stale() {
+			continue
+		}
+		sess, err := LoadWithStorage(baseDir, store, id)
+		if err != nil {
+			continue
+		}
+		found = append(found, candidate{sess: sess, started: lock.StartedAt})
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].started.After(found[j].started) })
+	result := make([]*Session, len(found))
+	for i, c := range found {
+		result[i] = c.sess
+	}
+	return result, nil
+}
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only succeeds for live processes on Windows.
+		return true
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestRunLock(t *testing.T) {
+	baseDir := t.TempDir()
+	s := NewSessionWithBaseDir(baseDir)
+	s.SetPrompt("fix the build")
+	s.AddOrchestratorNote("planned", "planner")
+	if err := s.AcquireRunLock("/work"); err != nil {
+		t.Fatalf("AcquireRunLock() error = %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	lock, err := ReadRunLock(baseDir, s.ID)
+	if err != nil || lock == nil || lock.PID != os.Getpid() || lock.Workspace != "/work" {
+		t.Fatalf("ReadRunLock() = %+v, %v", lock, err)
+	}
+	if lock.Stale() {
+		t.Error("lock held by the current process is stale")
+	}
+	if found, _ := FindUnterminated(baseDir, NewFSStorage(baseDir), "/work"); len(found) != 0 {
+		t.Errorf("FindUnterminated() returned a live session")
+	}
+
+	if err := s.ReleaseRunLock(); err != nil {
+		t.Fatalf("ReleaseRunLock() error = %v", err)
+	}
+	if lock, _ := ReadRunLock(baseDir, s.ID); lock != nil {
+		t.Errorf("lock remains after release: %+v", lock)
+	}
+}
+
+func TestFindUnterminated(t *testing.T) {
+	baseDir := t.TempDir()
+	host, _ := os.Hostname()
+
+	crash := func(workspace string, started time.Time) *Session {
+		s := NewSessionWithBaseDir(baseDir)
+		s.SetPrompt("prompt")
+		s.AddOrchestratorNote("note", "system")
+		if err := s.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		lock := RunLock{PID: -1, Host: host, Workspace: workspace, StartedAt: started}
+		if err := writeJSON(filepath.Join(baseDir, s.ID, runLockFile), lock); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	older := crash("/work", time.Now().Add(-time.Hour))
+	newer := crash("/work", time.Now())
+	crash("/elsewhere", time.Now())
+
+	found, err := FindUnterminated(baseDir, NewFSStorage(baseDir), "/work")
+	if err != nil {
+		t.Fatalf("FindUnterminated() error = %v", err)
+	}
+	if len(found) != 2 || found[0].ID != newer.ID || found[1].ID != older.ID {
+		t.Fatalf("FindUnterminated() = %d sessions", len(found))
+	}
+	if found[0].GetPrompt() != "prompt" || len(found[0].GetOrchestratorNotes()) != 1 || found[0].GetStatus() != StatusRunning {
+		t.Errorf("loaded session = prompt %q, notes %d, status %q", found[0].GetPrompt(), len(found[0].GetOrchestratorNotes()), found[0].GetStatus())
+	}
+}
+
+func TestLoad_LastState(t *testing.T) {
+	baseDir := t.TempDir()
+	s := NewSessionWithBaseDir(baseDir)
+	s.AddState(orchestrate.ScheduleKnowledge, orchestrate.Process1, []string{"read main.go"})
+	s.AddState(orchestrate.ScheduleKnowledge, orchestrate.Process2, nil)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(baseDir, s.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	last := loaded.LastState()
+	if last == nil || last.Process != orchestrate.Process2 {
+		t.Fatalf("LastState() = %+v", last)
+	}
+	if id := loaded.AddState(orchestrate.ScheduleKnowledge, orchestrate.Process3, nil); id != "0003-S1P3" {
+		t.Errorf("AddState() after Load = %q", id)
+	}
+}
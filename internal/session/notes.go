@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/croberts/obot/internal/redact"
 )
 
 // NoteDestination identifies where a note should be routed.
@@ -211,7 +213,7 @@ func (nm *NotesManager) writeJSON(path string, data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
-	return os.WriteFile(path, content, 0644)
+	return os.WriteFile(path, redact.JSON("notes", content), 0644)
 }
 
 // readJSON reads data from a JSON file.
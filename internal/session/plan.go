@@ -0,0 +1,132 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"time"
+)
+
+// ApprovedPlan is the pre-schedule plan the human approved before
+// orchestration started, binding on the Plan schedule, and how far the
+// agent's work has followed it.
+type ApprovedPlan struct {
+	Tasks      []PlannedTask `json:"tasks"`
+	OffPlan    []string      `json:"off_plan,omitempty"` // files changed that no subtask is on
+	ApprovedAt time.Time     `json:"approved_at"`
+}
+
+// PlannedTask is a subtask of the approved plan.
+type PlannedTask struct {
+	ID        string `json:"id"`
+	File      string `json:"file,omitempty"` // slash-separated, relative to the workspace
+	Risk      string `json:"risk,omitempty"` // safe, moderate, or high
+	Summary   string `json:"summary"`        // the subtask on one line
+	Addressed bool   `json:"addressed,omitempty"`
+}
+
+// Summaries returns the subtasks on one line each.
+func (p *ApprovedPlan) Summaries() []string {
+	lines := make([]string, len(p.Tasks))
+	for i, task := range p.Tasks {
+		lines[i] = task.Summary
+	}
+	return lines
+}
+
+// Pending returns the subtasks on a file the agent has not changed yet;
+// subtasks on no file cannot be followed.
+func (p *ApprovedPlan) Pending() []PlannedTask {
+	var pending []PlannedTask
+	for _, task := range p.Tasks {
+		if task.File != "" && !task.Addressed {
+			pending = append(pending, task)
+		}
+	}
+	return pending
+}
+
+// Addressed returns how many subtasks the agent has changed the file of.
+func (p *ApprovedPlan) Addressed() int {
+	n := 0
+	for _, task := range p.Tasks {
+		if task.Addressed {
+			n++
+		}
+	}
+	return n
+}
+
+// SetApprovedPlan records the subtasks of the approved pre-schedule plan.
+func (s *Session) SetApprovedPlan(tasks []PlannedTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approvedPlan = &ApprovedPlan{Tasks: tasks, ApprovedAt: time.Now()}
+	s.UpdatedAt = time.Now()
+}
+
+// RecordPlanFile follows a change the agent made to file, slash-separated
+// and relative to the workspace, against the approved plan: the subtasks
+// on the file are addressed, and a file no subtask is on is off the plan.
+// It reports whether the file is on the plan, which it is when no plan was
+// approved.
+func (s *Session) RecordPlanFile(file string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.approvedPlan == nil {
+		return true
+	}
+	onPlan := false
+	for i := range s.approvedPlan.Tasks {
+		if s.approvedPlan.Tasks[i].File == file {
+			s.approvedPlan.Tasks[i].Addressed = true
+			onPlan = true
+		}
+	}
+	if !onPlan && !slices.Contains(s.approvedPlan.OffPlan, file) {
+		s.approvedPlan.OffPlan = append(s.approvedPlan.OffPlan, file)
+	}
+	s.UpdatedAt = time.Now()
+	return onPlan
+}
+
+// GetApprovedPlan returns the approved pre-schedule plan, or nil when none
+// was approved.
+func (s *Session) GetApprovedPlan() *ApprovedPlan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.approvedPlan == nil {
+		return nil
+	}
+	plan := *s.approvedPlan
+	plan.Tasks = slices.Clone(plan.Tasks)
+	plan.OffPlan = slices.Clone(plan.OffPlan)
+	return &plan
+}
+
+// saveApprovedPlan writes the approved plan to plan.json in the session.
+func (s *Session) saveApprovedPlan() error {
+	if s.approvedPlan == nil {
+		return nil
+	}
+	return s.putJSON("plan.json", s.approvedPlan)
+}
+
+// loadApprovedPlan reads plan.json of a session from store if present.
+func loadApprovedPlan(store Storage, sessionID string) (*ApprovedPlan, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "plan.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var plan ApprovedPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse session plan: %w", err)
+	}
+	return &plan, nil
+}
@@ -7,13 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/redact"
 )
 
 // Session manages session state and persistence.
@@ -39,8 +40,58 @@ type Session struct {
 	agentNotes        []Note
 	humanNotes        []Note
 
+	// syncedNotes is how many of the orchestrator's notes are already in
+	// orchestratorNotes; see SyncOrchestratorNotes
+	syncedNotes int
+
+	// Error occurrences
+	errors []ErrorRecord
+
+	// Model handoffs
+	handoffs []HandoffRecord
+
+	// Quality traded for memory under pressure
+	degradations []DegradationRecord
+
+	// HTTP requests sent by the agent
+	httpRecords []HTTPRecord
+
+	// Coverage gained by test generation steps
+	coverage []CoverageRecord
+
+	// Prompts sent to the models and their responses
+	transcript []TranscriptEntry
+
+	// The agent's conversation in each process run
+	conversations []ConversationRecord
+
+	// Questions put to the human and their answers
+	consultations []ConsultationRecord
+
+	// Pre-schedule plan the human approved
+	approvedPlan *ApprovedPlan
+
+	// Lineage: the session this one continues and those continuing it
+	continues   string
+	continuedBy []string
+
+	// The workspace as it was when the run started, a git tree the judges
+	// diff against
+	baseline string
+
+	// Expert judges' analysis (JSON) and its rendered TLDR
+	analysis json.RawMessage
+	tldr     string
+
+	// Content hashes of workspace files, reused while size and mtime match
+	fileHashes map[string]fileHash
+
+	// Lifecycle status (running, completed, interrupted, ...)
+	status Status
+
 	// Configuration
 	baseDir string
+	store   Storage
 
 	// Statistics
 	stats *SessionStats
@@ -49,8 +100,13 @@ type Session struct {
 // NewSession creates a new session with default base directory.
 // Uses the unified config directory at ~/.config/ollamabot/sessions/.
 func NewSession() *Session {
+	return NewSessionWithBaseDir(DefaultBaseDir())
+}
+
+// DefaultBaseDir returns the default sessions directory, ~/.config/ollamabot/sessions.
+func DefaultBaseDir() string {
 	homeDir, _ := os.UserHomeDir()
-	return NewSessionWithBaseDir(filepath.Join(homeDir, ".config", "ollamabot", "sessions"))
+	return filepath.Join(homeDir, ".config", "ollamabot", "sessions")
 }
 
 // NewSessionWithBaseDir creates a new session with custom base directory
@@ -65,6 +121,8 @@ func NewSessionWithBaseDir(baseDir string) *Session {
 		agentNotes:        make([]Note, 0),
 		humanNotes:        make([]Note, 0),
 		baseDir:           baseDir,
+		store:             NewFSStorage(baseDir),
+		status:            StatusRunning,
 		stats: &SessionStats{
 			ScheduleCounts: make(map[orchestrate.ScheduleID]int),
 			ProcessCounts:  make(map[orchestrate.ScheduleID]map[orchestrate.ProcessID]int),
@@ -97,6 +155,13 @@ func generateSessionID() string {
 	return hex.EncodeToString(hash[:8])
 }
 
+// SetStorage sets the backend the session is saved to.
+func (s *Session) SetStorage(store Storage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
 // GetID returns the session ID
 func (s *Session) GetID() string {
 	return s.ID
@@ -140,7 +205,6 @@ func (s *Session) AddState(scheduleID orchestrate.ScheduleID, processID orchestr
 	s.flowCode += fmt.Sprintf("P%d", processID)
 
 	// Save the state file immediately
-	sessionDir := filepath.Join(s.baseDir, s.ID)
 	stateData := map[string]interface{}{
 		"id":         state.ID,
 		"schedule":   state.Schedule,
@@ -149,61 +213,11 @@ func (s *Session) AddState(scheduleID orchestrate.ScheduleID, processID orchestr
 		"actions":    state.Actions,
 		"timestamp":  state.Timestamp,
 	}
-	_ = writeJSON(filepath.Join(sessionDir, "states", state.ID+".state"), stateData)
+	_ = s.putJSON(path.Join("states", state.ID+".state"), stateData)
 
 	return stateID
 }
 
-// computeFilesHash computes a SHA256 hash of the project files.
-func (s *Session) computeFilesHash() string {
-	hasher := sha256.New()
-
-	// Get workspace root
-	root, err := os.Getwd()
-	if err != nil {
-		root = "."
-	}
-
-	// Collect files first to sort them for deterministic hashing
-	var files []string
-	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if info.IsDir() {
-			// Skip hidden dirs (including .git), node_modules, and sessions dir
-			name := info.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || path == s.baseDir {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip non-regular files
-		if !info.Mode().IsRegular() {
-			return nil
-		}
-
-		files = append(files, path)
-		return nil
-	})
-
-	sort.Strings(files)
-
-	for _, path := range files {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-		// Hash the relative path and the content
-		rel, _ := filepath.Rel(root, path)
-		hasher.Write([]byte(rel))
-		hasher.Write(data)
-	}
-
-	return hex.EncodeToString(hasher.Sum(nil))
-}
-
 // GetState returns a state by ID
 func (s *Session) GetState(stateID string) *State {
 	s.mu.Lock()
@@ -242,6 +256,52 @@ func (s *Session) AddOrchestratorNote(content, source string) {
 	s.orchestratorNotes = append(s.orchestratorNotes, note)
 }
 
+// AddOrchestratorNoteFrom adds a copy of an orchestrator session note,
+// keeping its type, tags, and provenance.
+func (s *Session) AddOrchestratorNoteFrom(n orchestrate.Note) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addOrchestratorNoteFrom(n)
+}
+
+// SyncOrchestratorNotes adds copies of the orchestrator notes the session
+// does not have yet. notes is the orchestrator's whole note list, which only
+// grows, so each note is copied once however often the session syncs,
+// whether or not the orchestrator has reviewed it.
+func (s *Session) SyncOrchestratorNotes(notes []orchestrate.Note) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range notes[min(s.syncedNotes, len(notes)):] {
+		s.addOrchestratorNoteFrom(n)
+	}
+	s.syncedNotes = max(s.syncedNotes, len(notes))
+}
+
+// MarkOrchestratorNotesSynced records that the first n notes of the
+// orchestrator are already in the session, as after restoring the session's
+// notes into it.
+func (s *Session) MarkOrchestratorNotesSynced(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncedNotes = n
+}
+
+func (s *Session) addOrchestratorNoteFrom(n orchestrate.Note) {
+	note := Note{
+		ID:         fmt.Sprintf("ON%d", len(s.orchestratorNotes)+1),
+		Timestamp:  n.Timestamp,
+		Content:    n.Content,
+		Source:     n.Source,
+		Type:       n.Type,
+		Tags:       n.Tags,
+		Provenance: n.Provenance,
+	}
+	if note.Timestamp.IsZero() {
+		note.Timestamp = time.Now()
+	}
+	s.orchestratorNotes = append(s.orchestratorNotes, note)
+}
+
 // AddAgentNote adds a note for the agent
 func (s *Session) AddAgentNote(content, source string) {
 	s.mu.Lock()
@@ -272,6 +332,26 @@ func (s *Session) AddHumanNote(content, source string) {
 	s.humanNotes = append(s.humanNotes, note)
 }
 
+// GetOrchestratorNotes returns the orchestrator notes.
+func (s *Session) GetOrchestratorNotes() []Note {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Note, len(s.orchestratorNotes))
+	copy(result, s.orchestratorNotes)
+	return result
+}
+
+// LastState returns the most recent state, or nil if none was recorded.
+func (s *Session) LastState() *State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.states) == 0 {
+		return nil
+	}
+	state := s.states[len(s.states)-1]
+	return &state
+}
+
 // SetFlowCode sets the flow code
 func (s *Session) SetFlowCode(flowCode string) {
 	s.mu.Lock()
@@ -286,28 +366,25 @@ func (s *Session) GetFlowCode() string {
 	return s.flowCode
 }
 
-// Save saves the session to disk
-func (s *Session) Save() error {
+// SetStatus sets the lifecycle status of the session.
+func (s *Session) SetStatus(status Status) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.status = status
+	s.UpdatedAt = time.Now()
+}
 
-	sessionDir := filepath.Join(s.baseDir, s.ID)
-
-	// Create directory structure
-	dirs := []string{
-		sessionDir,
-		filepath.Join(sessionDir, "states"),
-		filepath.Join(sessionDir, "checkpoints"),
-		filepath.Join(sessionDir, "notes"),
-		filepath.Join(sessionDir, "actions"),
-		filepath.Join(sessionDir, "actions", "diffs"),
-	}
+// GetStatus returns the lifecycle status of the session.
+func (s *Session) GetStatus() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
 
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
-		}
-	}
+// Save saves the session to disk
+func (s *Session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// Save metadata
 	meta := map[string]interface{}{
@@ -316,14 +393,24 @@ func (s *Session) Save() error {
 		"updated_at": s.UpdatedAt,
 		"prompt":     s.prompt,
 		"flow_code":  s.flowCode,
+		"status":     s.status,
 		"stats":      s.stats,
 	}
-	if err := writeJSON(filepath.Join(sessionDir, "meta.json"), meta); err != nil {
+	if s.continues != "" {
+		meta["continues"] = s.continues
+	}
+	if len(s.continuedBy) > 0 {
+		meta["continued_by"] = s.continuedBy
+	}
+	if s.baseline != "" {
+		meta["baseline"] = s.baseline
+	}
+	if err := s.putJSON("meta.json", meta); err != nil {
 		return err
 	}
 
 	// Save flow code
-	if err := os.WriteFile(filepath.Join(sessionDir, "flow.code"), []byte(s.flowCode), 0644); err != nil {
+	if err := s.saveFlowCode(); err != nil {
 		return err
 	}
 
@@ -343,7 +430,7 @@ func (s *Session) Save() error {
 		"relations": relations,
 		"states":    s.states,
 	}
-	if err := writeJSON(filepath.Join(sessionDir, "states", "recurrence.json"), recurrence); err != nil {
+	if err := s.putJSON(path.Join("states", "recurrence.json"), recurrence); err != nil {
 		return err
 	}
 
@@ -357,33 +444,108 @@ func (s *Session) Save() error {
 			"actions":    state.Actions,
 			"timestamp":  state.Timestamp,
 		}
-		if err := writeJSON(filepath.Join(sessionDir, "states", state.ID+".state"), stateData); err != nil {
+		if err := s.putJSON(path.Join("states", state.ID+".state"), stateData); err != nil {
 			return err
 		}
 	}
 
 	// Save notes
-	if err := writeJSON(filepath.Join(sessionDir, "notes", "orchestrator.json"), s.orchestratorNotes); err != nil {
+	if err := s.putJSON(path.Join("notes", "orchestrator.json"), s.orchestratorNotes); err != nil {
+		return err
+	}
+	if err := s.putJSON(path.Join("notes", "agent.json"), s.agentNotes); err != nil {
+		return err
+	}
+	if err := s.putJSON(path.Join("notes", "human.json"), s.humanNotes); err != nil {
+		return err
+	}
+
+	// Save error records
+	if err := s.saveErrors(); err != nil {
 		return err
 	}
-	if err := writeJSON(filepath.Join(sessionDir, "notes", "agent.json"), s.agentNotes); err != nil {
+
+	// Save model handoffs
+	if err := s.saveHandoffs(); err != nil {
 		return err
 	}
-	if err := writeJSON(filepath.Join(sessionDir, "notes", "human.json"), s.humanNotes); err != nil {
+
+	// Save degradation decisions
+	if err := s.saveDegradations(); err != nil {
+		return err
+	}
+
+	// Save HTTP requests
+	if err := s.saveHTTPRecords(); err != nil {
+		return err
+	}
+
+	// Save test generation coverage
+	if err := s.saveCoverage(); err != nil {
+		return err
+	}
+
+	// Save the model transcript
+	if err := s.saveTranscript(); err != nil {
+		return err
+	}
+
+	// Save the agent's conversations
+	if err := s.saveConversations(); err != nil {
+		return err
+	}
+
+	// Save the consultations
+	if err := s.saveConsultations(); err != nil {
+		return err
+	}
+
+	// Save the approved plan
+	if err := s.saveApprovedPlan(); err != nil {
+		return err
+	}
+
+	// Save the judges' analysis
+	if err := s.saveAnalysis(); err != nil {
+		return err
+	}
+
+	// Save summary
+	if err := s.put("summary.txt", []byte(s.summaryLocked()), 0644); err != nil {
 		return err
 	}
 
 	// Generate restore script
-	if err := s.generateRestoreScript(sessionDir); err != nil {
+	if err := s.generateRestoreScript(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// saveFlowCode writes the flow code to disk.
-func (s *Session) saveFlowCode(sessionDir string) error {
-	return os.WriteFile(filepath.Join(sessionDir, "flow.code"), []byte(s.flowCode), 0644)
+// saveFlowCode writes the flow code to storage.
+func (s *Session) saveFlowCode() error {
+	return s.put("flow.code", []byte(s.flowCode), 0644)
+}
+
+// put stores a file of this session with secrets redacted.
+func (s *Session) put(name string, data []byte, perm os.FileMode) error {
+	return s.store.WriteFile(path.Join(s.ID, name), redact.Bytes("session", data), perm)
+}
+
+// putJSON stores data as JSON in a file of this session.
+func (s *Session) putJSON(name string, data interface{}) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.putEncoded(name, content)
+}
+
+// putEncoded stores JSON-encoded data in a file of this session with
+// secrets redacted from its string values.
+func (s *Session) putEncoded(name string, content []byte) error {
+	return s.store.WriteFile(path.Join(s.ID, name), redact.JSON("session", content), 0644)
 }
 
 // writeJSON writes data as JSON to a file
@@ -396,7 +558,7 @@ func writeJSON(path string, data interface{}) error {
 }
 
 // generateRestoreScript generates the bash restore script
-func (s *Session) generateRestoreScript(sessionDir string) error {
+func (s *Session) generateRestoreScript() error {
 	script := fmt.Sprintf(`#!/bin/bash
 # restore.sh - Restore obot session %s
 # Generated: %s
@@ -514,8 +676,7 @@ case "${1:-usage}" in
 esac
 `, s.ID, time.Now().Format(time.RFC3339), s.ID, s.ID)
 
-	scriptPath := filepath.Join(sessionDir, "restore.sh")
-	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+	if err := s.put("restore.sh", []byte(script), 0755); err != nil {
 		return fmt.Errorf("failed to write restore script: %w", err)
 	}
 
@@ -524,11 +685,14 @@ esac
 
 // Load loads a session from disk
 func Load(baseDir, sessionID string) (*Session, error) {
-	sessionDir := filepath.Join(baseDir, sessionID)
+	return LoadWithStorage(baseDir, NewFSStorage(baseDir), sessionID)
+}
 
+// LoadWithStorage loads a session from store. baseDir is the local sessions
+// directory used for the session's run lock.
+func LoadWithStorage(baseDir string, store Storage, sessionID string) (*Session, error) {
 	// Read metadata
-	metaPath := filepath.Join(sessionDir, "meta.json")
-	metaData, err := os.ReadFile(metaPath)
+	metaData, err := store.ReadFile(path.Join(sessionID, "meta.json"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read session metadata: %w", err)
 	}
@@ -541,6 +705,7 @@ func Load(baseDir, sessionID string) (*Session, error) {
 	session := &Session{
 		ID:      sessionID,
 		baseDir: baseDir,
+		store:   store,
 		stats:   &SessionStats{},
 	}
 
@@ -551,10 +716,36 @@ func Load(baseDir, sessionID string) (*Session, error) {
 	if flowCode, ok := meta["flow_code"].(string); ok {
 		session.flowCode = flowCode
 	}
+	if status, ok := meta["status"].(string); ok {
+		session.status = Status(status)
+	}
+	if raw, ok := meta["stats"]; ok && raw != nil {
+		if data, err := json.Marshal(raw); err == nil {
+			_ = json.Unmarshal(data, session.stats)
+		}
+	}
+	if continues, ok := meta["continues"].(string); ok {
+		session.continues = continues
+	}
+	if baseline, ok := meta["baseline"].(string); ok {
+		session.baseline = baseline
+	}
+	if ids, ok := meta["continued_by"].([]interface{}); ok {
+		for _, id := range ids {
+			if id, ok := id.(string); ok {
+				session.continuedBy = append(session.continuedBy, id)
+			}
+		}
+	}
+	if createdAt, ok := meta["created_at"].(string); ok {
+		session.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+	}
+	if updatedAt, ok := meta["updated_at"].(string); ok {
+		session.UpdatedAt, _ = time.Parse(time.RFC3339Nano, updatedAt)
+	}
 
 	// Read recurrence relations
-	recurrencePath := filepath.Join(sessionDir, "states", "recurrence.json")
-	recurrenceData, err := os.ReadFile(recurrencePath)
+	recurrenceData, err := store.ReadFile(path.Join(sessionID, "states", "recurrence.json"))
 	if err == nil {
 		var recurrence struct {
 			Relations []StateRelation `json:"relations"`
@@ -566,8 +757,8 @@ func Load(baseDir, sessionID string) (*Session, error) {
 			} else if len(recurrence.Relations) > 0 {
 				// Fallback: try to load individual state files if relations exist but states don't
 				for _, rel := range recurrence.Relations {
-					statePath := filepath.Join(sessionDir, "states", rel.CurrentID+".state")
-					if stateData, err := os.ReadFile(statePath); err == nil {
+					statePath := path.Join(sessionID, "states", rel.CurrentID+".state")
+					if stateData, err := store.ReadFile(statePath); err == nil {
 						var state State
 						if err := json.Unmarshal(stateData, &state); err == nil {
 							session.states = append(session.states, state)
@@ -578,9 +769,99 @@ func Load(baseDir, sessionID string) (*Session, error) {
 		}
 	}
 
+	if n := len(session.states); n > 0 {
+		last := session.states[n-1]
+		session.currentStateID = last.ID
+		session.lastSchedule = last.Schedule
+	}
+
+	// Read notes
+	session.orchestratorNotes = loadNotes(store, path.Join(sessionID, "notes", "orchestrator.json"))
+	session.agentNotes = loadNotes(store, path.Join(sessionID, "notes", "agent.json"))
+	session.humanNotes = loadNotes(store, path.Join(sessionID, "notes", "human.json"))
+
+	// Read error records
+	records, err := loadErrors(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.errors = records
+
+	// Read model handoffs
+	handoffs, err := loadHandoffs(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.handoffs = handoffs
+
+	// Read degradation decisions
+	degradations, err := loadDegradations(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.degradations = degradations
+
+	// Read HTTP requests
+	httpRecords, err := loadHTTPRecords(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.httpRecords = httpRecords
+
+	// Read test generation coverage
+	coverage, err := loadCoverage(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.coverage = coverage
+
+	// Read the model transcript
+	transcript, err := loadTranscript(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.transcript = transcript
+
+	// Read the agent's conversations
+	conversations, err := loadConversations(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.conversations = conversations
+
+	// Read the consultations
+	consultations, err := loadConsultations(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.consultations = consultations
+
+	// Read the approved plan
+	approvedPlan, err := loadApprovedPlan(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.approvedPlan = approvedPlan
+
+	// Read the judges' analysis
+	analysis, tldr, err := loadAnalysis(store, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session.analysis, session.tldr = analysis, tldr
+
 	return session, nil
 }
 
+// loadNotes reads a notes file, returning an empty list if it is missing or invalid.
+func loadNotes(store Storage, name string) []Note {
+	notes := make([]Note, 0)
+	if data, err := store.ReadFile(name); err == nil {
+		_ = json.Unmarshal(data, &notes)
+	}
+	return notes
+}
+
 // ListSessions lists all sessions in the base directory
 func ListSessions(baseDir string) ([]string, error) {
 	entries, err := os.ReadDir(baseDir)
@@ -621,14 +902,30 @@ func (s *Session) FreezeState() (string, error) {
 func (s *Session) GenerateSummary() string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.summaryLocked()
+}
 
+// summaryLocked builds the session summary. The caller must hold s.mu.
+func (s *Session) summaryLocked() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Session: %s\n", s.ID))
 	sb.WriteString(fmt.Sprintf("Created: %s\n", s.CreatedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Updated: %s\n", s.UpdatedAt.Format(time.RFC3339)))
+	if s.status != "" {
+		sb.WriteString(fmt.Sprintf("Status: %s\n", s.status))
+	}
 	sb.WriteString(fmt.Sprintf("Flow Code: %s\n", s.flowCode))
 	sb.WriteString(fmt.Sprintf("States: %d\n", len(s.states)))
+	if s.continues != "" {
+		sb.WriteString(fmt.Sprintf("Continues: %s\n", s.continues))
+	}
+	if len(s.continuedBy) > 0 {
+		sb.WriteString(fmt.Sprintf("Continued By: %s\n", strings.Join(s.continuedBy, ", ")))
+	}
+	if s.tldr != "" {
+		sb.WriteString("\n" + s.tldr)
+	}
 
 	return sb.String()
 }
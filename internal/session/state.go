@@ -31,6 +31,12 @@ type Note struct {
 	Content   string    `json:"content"`
 	Source    string    `json:"source"` // "orchestrator", "agent", "human", "system"
 	Reviewed  bool      `json:"reviewed"`
+
+	Type orchestrate.NoteType `json:"type,omitempty"`
+	Tags []string             `json:"tags,omitempty"`
+
+	// Provenance records where retrieved information in the note came from.
+	Provenance *orchestrate.Provenance `json:"provenance,omitempty"`
 }
 
 // SessionStats tracks metrics across the entire session.
@@ -87,3 +93,27 @@ type SuspendError struct {
 func (e *SuspendError) Error() string {
 	return e.Message
 }
+
+// Status is the lifecycle status of a session.
+type Status string
+
+const (
+	StatusRunning     Status = "running"
+	StatusCompleted   Status = "completed"
+	StatusInterrupted Status = "interrupted"
+	StatusAborted     Status = "aborted"
+	StatusFailed      Status = "failed"
+	StatusCrashed     Status = "crashed"
+)
+
+// usfStatus maps the status onto the USF task status.
+func (s Status) usfStatus() string {
+	switch s {
+	case StatusCompleted:
+		return "completed"
+	case StatusFailed, StatusAborted, StatusCrashed:
+		return "failed"
+	default:
+		return "in_progress"
+	}
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Storage persists session files. Names are slash-separated paths relative to
+// the storage root whose first element is the session ID, e.g.
+// "abc123/meta.json". Run and workspace locks are always kept on the local
+// filesystem and do not go through Storage.
+type Storage interface {
+	// WriteFile stores data under name, replacing any existing content.
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	// ReadFile returns the content stored under name. A missing file is
+	// reported with an error matching fs.ErrNotExist.
+	ReadFile(name string) ([]byte, error)
+	// List returns the names of the direct children of dir ("" for the root).
+	List(dir string) ([]string, error)
+}
+
+// FSStorage stores sessions in a local directory. It is the default backend.
+type FSStorage struct {
+	Root string
+}
+
+// NewFSStorage creates a storage rooted at dir.
+func NewFSStorage(dir string) *FSStorage {
+	return &FSStorage{Root: dir}
+}
+
+// WriteFile writes data to Root/name, creating parent directories.
+func (f *FSStorage) WriteFile(name string, data []byte, perm os.FileMode) error {
+	p := filepath.Join(f.Root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, perm)
+}
+
+// ReadFile reads Root/name.
+func (f *FSStorage) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(f.Root, filepath.FromSlash(name)))
+}
+
+// List returns the entries of Root/dir. A missing directory is empty.
+func (f *FSStorage) List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(f.Root, filepath.FromSlash(dir)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names, nil
+}
+
+// ListStoredSessions returns the IDs of the sessions in store.
+func ListStoredSessions(store Storage) ([]string, error) {
+	names, err := store.List("")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	sessions := make([]string, 0)
+	for _, name := range names {
+		if _, err := store.ReadFile(path.Join(name, "meta.json")); err == nil {
+			sessions = append(sessions, name)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// TranscriptMessage is one message of a prompt sent to a model.
+type TranscriptMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// TranscriptEntry is a prompt sent to a model during the session and the
+// response it gave.
+type TranscriptEntry struct {
+	Role             string              `json:"role"` // model role, such as orchestrator or coder
+	Model            string              `json:"model"`
+	Schedule         string              `json:"schedule,omitempty"`
+	Process          string              `json:"process,omitempty"`
+	Messages         []TranscriptMessage `json:"messages"`
+	Response         string              `json:"response"`
+	PromptTokens     int                 `json:"prompt_tokens,omitempty"`
+	CompletionTokens int                 `json:"completion_tokens,omitempty"`
+	Latency          time.Duration       `json:"latency"`
+	Cached           bool                `json:"cached,omitempty"`
+	Error            string              `json:"error,omitempty"`
+	Timestamp        time.Time           `json:"timestamp"`
+}
+
+// Matches reports whether the entry's prompt, response, or error contains
+// text, ignoring case.
+func (e TranscriptEntry) Matches(text string) bool {
+	text = strings.ToLower(text)
+	if strings.Contains(strings.ToLower(e.Response), text) || strings.Contains(strings.ToLower(e.Error), text) {
+		return true
+	}
+	for _, m := range e.Messages {
+		if strings.Contains(strings.ToLower(m.Content), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordTranscript appends a prompt and its response to the session.
+func (s *Session) RecordTranscript(entry TranscriptEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	s.transcript = append(s.transcript, entry)
+	s.UpdatedAt = time.Now()
+}
+
+// GetTranscript returns the recorded prompts and responses in order.
+func (s *Session) GetTranscript() []TranscriptEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]TranscriptEntry, len(s.transcript))
+	copy(result, s.transcript)
+	return result
+}
+
+// saveTranscript writes the transcript to transcript.json in the session.
+func (s *Session) saveTranscript() error {
+	if len(s.transcript) == 0 {
+		return nil
+	}
+	return s.putJSON("transcript.json", s.transcript)
+}
+
+// loadTranscript reads transcript.json of a session from store if present.
+func loadTranscript(store Storage, sessionID string) ([]TranscriptEntry, error) {
+	data, err := store.ReadFile(path.Join(sessionID, "transcript.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []TranscriptEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse session transcript: %w", err)
+	}
+	return entries, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/croberts/obot/internal/config"
+	"github.com/croberts/obot/internal/redact"
 )
 
 // UnifiedSession is the cross-platform session format (USF v1.0).
@@ -159,7 +160,7 @@ func SaveUSF(session *UnifiedSession) error {
 	}
 
 	path := filepath.Join(dir, session.SessionID+".json")
-	return os.WriteFile(path, data, 0644)
+	return os.WriteFile(path, redact.JSON("session", data), 0644)
 }
 
 // LoadUSF reads a session from disk.
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/redact"
 )
 
 // USFVersion is the current version of the Unified Session Format.
@@ -119,7 +120,7 @@ func (s *USFSession) Save(baseDir string) error {
 	}
 
 	path := filepath.Join(dir, "session.usf")
-	return os.WriteFile(path, data, 0644)
+	return os.WriteFile(path, redact.JSON("session", data), 0644)
 }
 
 // LoadUSFSession reads a USF session from disk.
@@ -0,0 +1,217 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// workspaceLockFile is the lock file created in the workspace's .obot directory.
+	workspaceLockFile = "orchestrate.lock"
+
+	// DefaultHeartbeatInterval is how often a held workspace lock is refreshed.
+	DefaultHeartbeatInterval = 5 * time.Second
+
+	// staleHeartbeats is the number of missed heartbeats after which a lock is stale.
+	staleHeartbeats = 6
+)
+
+// WorkspaceLockInfo is the content of a workspace lock file.
+type WorkspaceLockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	SessionID string    `json:"session_id"`
+	StartedAt time.Time `json:"started_at"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// Stale reports whether the lock holder has stopped: its process is gone, or
+// it has missed enough heartbeats to be considered hung or on a dead host.
+func (i *WorkspaceLockInfo) Stale(now time.Time) bool {
+	if now.Sub(i.Heartbeat) > staleHeartbeats*DefaultHeartbeatInterval {
+		return true
+	}
+	host, _ := os.Hostname()
+	return i.Host == host && !processAlive(i.PID)
+}
+
+// WorkspaceLockedError is returned when another run holds the workspace lock.
+type WorkspaceLockedError struct {
+	Workspace string
+	Holder    WorkspaceLockInfo
+	Stale     bool
+}
+
+func (e *WorkspaceLockedError) Error() string {
+	msg := fmt.Sprintf("workspace %s is locked by session %s (pid %d on %s, last heartbeat %s ago)",
+		e.Workspace, e.Holder.SessionID, e.Holder.PID, e.Holder.Host,
+		time.Since(e.Holder.Heartbeat).Round(time.Second))
+	if e.Stale {
+		return msg + "; the lock is stale, use --force to take it over"
+	}
+	return msg + "; wait for that run to finish"
+}
+
+// WorkspaceLock is an exclusive lock on a workspace held by one orchestration
+// run. It is refreshed by a heartbeat until released.
+type WorkspaceLock struct {
+	path     string
+	info     WorkspaceLockInfo
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// WorkspaceLockPath returns the path of the lock file for workspace.
+func WorkspaceLockPath(workspace string) string {
+	return filepath.Join(workspace, ".obot", workspaceLockFile)
+}
+
+// AcquireWorkspaceLock locks workspace for sessionID. If another run holds the
+// lock a *WorkspaceLockedError is returned; with force, a stale lock is taken
+// over instead.
+func AcquireWorkspaceLock(workspace, sessionID string, force bool) (*WorkspaceLock, error) {
+	return acquireWorkspaceLock(workspace, sessionID, force, DefaultHeartbeatInterval)
+}
+
+func acquireWorkspaceLock(workspace, sessionID string, force bool, interval time.Duration) (*WorkspaceLock, error) {
+	path := WorkspaceLockPath(workspace)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	l := &WorkspaceLock{
+		path: path,
+		info: WorkspaceLockInfo{
+			PID:       os.Getpid(),
+			Host:      host,
+			SessionID: sessionID,
+			StartedAt: now,
+			Heartbeat: now,
+		},
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := createExclusive(path, data)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) || attempt > 0 {
+			return nil, err
+		}
+
+		holder, readErr := ReadWorkspaceLock(workspace)
+		if readErr != nil {
+			return nil, readErr
+		}
+		if holder == nil {
+			continue // released in the meantime
+		}
+		stale := holder.Stale(time.Now())
+		if !force || !stale {
+			return nil, &WorkspaceLockedError{Workspace: workspace, Holder: *holder, Stale: stale}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	l.wg.Add(1)
+	go l.heartbeat()
+	return l, nil
+}
+
+// ReadWorkspaceLock returns the current holder of the workspace lock, or nil if it is unlocked.
+func ReadWorkspaceLock(workspace string) (*WorkspaceLockInfo, error) {
+	data, err := os.ReadFile(WorkspaceLockPath(workspace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var info WorkspaceLockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace lock: %w", err)
+	}
+	return &info, nil
+}
+
+// Release stops the heartbeat and removes the lock file if it is still ours.
+func (l *WorkspaceLock) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		l.wg.Wait()
+		if !l.owned() {
+			return
+		}
+		if rmErr := os.Remove(l.path); rmErr != nil && !os.IsNotExist(rmErr) {
+			err = rmErr
+		}
+	})
+	return err
+}
+
+// heartbeat refreshes the lock file until the lock is released.
+func (l *WorkspaceLock) heartbeat() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case now := <-ticker.C:
+			if !l.owned() {
+				return // taken over by another run
+			}
+			l.info.Heartbeat = now
+			tmp := l.path + ".tmp"
+			if err := writeJSON(tmp, l.info); err == nil {
+				_ = os.Rename(tmp, l.path)
+			}
+		}
+	}
+}
+
+// owned reports whether the lock file still belongs to this lock.
+func (l *WorkspaceLock) owned() bool {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return false
+	}
+	var info WorkspaceLockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return false
+	}
+	return info.PID == l.info.PID && info.Host == l.info.Host && info.SessionID == l.info.SessionID
+}
+
+// createExclusive writes data to path, failing if path already exists.
+func createExclusive(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceLock(t *testing.T) {
+	workspace := t.TempDir()
+
+	first, err := AcquireWorkspaceLock(workspace, "first", false)
+	if err != nil {
+		t.Fatalf("AcquireWorkspaceLock() error = %v", err)
+	}
+
+	_, err = AcquireWorkspaceLock(workspace, "second", true)
+	var locked *WorkspaceLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("second acquire error = %v, want WorkspaceLockedError", err)
+	}
+	if locked.Holder.SessionID != "first" || locked.Stale {
+		t.Errorf("holder = %+v, stale = %v", locked.Holder, locked.Stale)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if info, _ := ReadWorkspaceLock(workspace); info != nil {
+		t.Errorf("lock remains after release: %+v", info)
+	}
+
+	second, err := AcquireWorkspaceLock(workspace, "second", false)
+	if err != nil {
+		t.Fatalf("acquire after release error = %v", err)
+	}
+	second.Release()
+}
+
+func TestWorkspaceLock_StaleTakeover(t *testing.T) {
+	workspace := t.TempDir()
+	host, _ := os.Hostname()
+	stale := WorkspaceLockInfo{PID: -1, Host: host, SessionID: "crashed", Heartbeat: time.Now()}
+	if err := os.MkdirAll(filepath.Join(workspace, ".obot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(WorkspaceLockPath(workspace), stale); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := AcquireWorkspaceLock(workspace, "new", false)
+	var locked *WorkspaceLockedError
+	if !errors.As(err, &locked) || !locked.Stale {
+		t.Fatalf("acquire without force error = %v, want stale WorkspaceLockedError", err)
+	}
+
+	l, err := AcquireWorkspaceLock(workspace, "new", true)
+	if err != nil {
+		t.Fatalf("forced acquire error = %v", err)
+	}
+	defer l.Release()
+	if info, _ := ReadWorkspaceLock(workspace); info == nil || info.SessionID != "new" {
+		t.Errorf("lock holder after takeover = %+v", info)
+	}
+}
+
+func TestWorkspaceLock_Heartbeat(t *testing.T) {
+	workspace := t.TempDir()
+	l, err := acquireWorkspaceLock(workspace, "beat", false, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("acquire error = %v", err)
+	}
+	defer l.Release()
+
+	initial, _ := ReadWorkspaceLock(workspace)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		info, _ := ReadWorkspaceLock(workspace)
+		if info != nil && info.Heartbeat.After(initial.Heartbeat) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("heartbeat was not refreshed")
+}
+
+func TestWorkspaceLockInfo_Stale(t *testing.T) {
+	host, _ := os.Hostname()
+	now := time.Now()
+	live := WorkspaceLockInfo{PID: os.Getpid(), Host: host, Heartbeat: now}
+	if live.Stale(now) {
+		t.Error("live lock reported stale")
+	}
+	if !live.Stale(now.Add(staleHeartbeats*DefaultHeartbeatInterval + time.Second)) {
+		t.Error("lock with missed heartbeats not stale")
+	}
+	remote := WorkspaceLockInfo{PID: -1, Host: host + "-other", Heartbeat: now}
+	if remote.Stale(now) {
+		t.Error("remote lock with a fresh heartbeat reported stale")
+	}
+}
@@ -0,0 +1,216 @@
+package stats
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/croberts/obot/internal/session"
+)
+
+// MinTuneSessions is how many sessions a tuning report needs before its
+// suggestions are worth acting on.
+const MinTuneSessions = 3
+
+// Thresholds above which the tuning report suggests a change
+const (
+	tuneTokenShare      = 0.40 // share of all tokens one schedule takes
+	tuneRetryRate       = 0.20 // share of a schedule's process runs retried
+	tuneRepeatShare     = 0.10 // share of requests repeating an earlier prompt
+	tuneFailureRate     = 0.05 // share of model requests that failed
+	tunePlanAccuracy    = 0.60 // share of the work the approved plans predicted
+	tuneDegradedShare   = 0.50 // share of sessions degraded under memory pressure
+	tuneSlowRequest     = 60 * time.Second
+	defaultCoderTemp    = 0.7
+	minTunePlannedTasks = 5
+)
+
+// ScheduleProfile is how much a schedule consumed across sessions.
+type ScheduleProfile struct {
+	Schedule  string
+	Runs      int // process runs
+	Requests  int // model requests
+	Tokens    int
+	ModelTime time.Duration // time spent waiting on the models
+	Errors    int
+	Retries   int // errors resolved by re-running the process
+}
+
+// RetryRate returns the share of the schedule's process runs that were
+// retried.
+func (p ScheduleProfile) RetryRate() float64 {
+	if p.Runs == 0 {
+		return 0
+	}
+	return float64(p.Retries) / float64(p.Runs)
+}
+
+// TuneReport profiles orchestration across sessions and suggests settings
+// to tune.
+type TuneReport struct {
+	Sessions  int
+	Schedules []ScheduleProfile // most tokens first
+
+	Requests       int
+	Tokens         int
+	ModelTime      time.Duration
+	CachedRequests int // served from the response cache
+	RepeatedPrompt int // requests repeating a prompt sent earlier in the session
+	FailedRequests int
+
+	// Prediction accuracy of the approved pre-schedule plans: planned
+	// subtasks the agent addressed against all the work it did
+	PlanTasks     int
+	PlanAddressed int
+	OffPlan       int // files changed that no subtask was on
+
+	DegradedSessions int // sessions that traded quality for memory
+
+	Suggestions []string
+}
+
+// PlanAccuracy returns the share of the work in sessions with an approved
+// plan that the plan predicted: addressed subtasks over all subtasks and
+// off-plan files. It is false when no plan was approved.
+func (r *TuneReport) PlanAccuracy() (float64, bool) {
+	total := r.PlanTasks + r.OffPlan
+	if total == 0 {
+		return 0, false
+	}
+	return float64(r.PlanAddressed) / float64(total), true
+}
+
+// RetryRate returns the share of all process runs that were retried.
+func (r *TuneReport) RetryRate() float64 {
+	runs, retries := 0, 0
+	for _, p := range r.Schedules {
+		runs += p.Runs
+		retries += p.Retries
+	}
+	if runs == 0 {
+		return 0
+	}
+	return float64(retries) / float64(runs)
+}
+
+// Tune profiles the sessions: time and tokens by schedule, retries,
+// response cache use, and the approved plans' accuracy, and suggests
+// settings to tune.
+func Tune(sessions []*session.Session) *TuneReport {
+	r := &TuneReport{Sessions: len(sessions)}
+	profiles := make(map[string]*ScheduleProfile)
+	profile := func(schedule string) *ScheduleProfile {
+		if schedule == "" {
+			schedule = "Unscheduled"
+		}
+		p, ok := profiles[schedule]
+		if !ok {
+			p = &ScheduleProfile{Schedule: schedule}
+			profiles[schedule] = p
+		}
+		return p
+	}
+
+	for _, s := range sessions {
+		for _, state := range s.GetAllStates() {
+			profile(state.Schedule.String()).Runs++
+		}
+		for _, e := range s.GetErrors() {
+			p := profile(e.Schedule)
+			p.Errors++
+			// Retries the user chose and automatic ones both re-ran the process
+			if e.Resolution == "retry" || e.Resolution == "auto-retry" {
+				p.Retries++
+			}
+		}
+		seen := make(map[[sha256.Size]byte]bool)
+		for _, e := range s.GetTranscript() {
+			p := profile(e.Schedule)
+			tokens := e.PromptTokens + e.CompletionTokens
+			p.Requests++
+			p.Tokens += tokens
+			p.ModelTime += e.Latency
+			r.Requests++
+			r.Tokens += tokens
+			r.ModelTime += e.Latency
+			if e.Cached {
+				r.CachedRequests++
+			}
+			if e.Error != "" {
+				r.FailedRequests++
+			}
+			key := promptKey(e)
+			if seen[key] {
+				r.RepeatedPrompt++
+			}
+			seen[key] = true
+		}
+		if plan := s.GetApprovedPlan(); plan != nil {
+			r.PlanTasks += len(plan.Tasks)
+			r.PlanAddressed += plan.Addressed()
+			r.OffPlan += len(plan.OffPlan)
+		}
+		if len(s.GetDegradations()) > 0 {
+			r.DegradedSessions++
+		}
+	}
+
+	for _, p := range profiles {
+		r.Schedules = append(r.Schedules, *p)
+	}
+	slices.SortFunc(r.Schedules, func(a, b ScheduleProfile) int {
+		return cmp.Or(cmp.Compare(b.Tokens, a.Tokens), cmp.Compare(b.ModelTime, a.ModelTime), cmp.Compare(a.Schedule, b.Schedule))
+	})
+	r.Suggestions = r.suggest()
+	return r
+}
+
+// promptKey identifies a request's prompt: its model and messages.
+func promptKey(e session.TranscriptEntry) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(e.Model))
+	for _, m := range e.Messages {
+		h.Write([]byte{0})
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// suggest turns the profile into concrete changes, most significant first.
+func (r *TuneReport) suggest() []string {
+	var suggestions []string
+	for _, p := range r.Schedules {
+		if r.Tokens > 0 && len(r.Schedules) > 1 {
+			if share := float64(p.Tokens) / float64(r.Tokens); share >= tuneTokenShare {
+				suggestions = append(suggestions, fmt.Sprintf("%s consumes %.0f%% of all tokens: cap its processes with orchestration.budget.processes (max_actions, max_duration_seconds) or trim the context it is sent", p.Schedule, share*100))
+			}
+		}
+		if p.Runs >= MinTuneSessions && p.RetryRate() >= tuneRetryRate {
+			suggestions = append(suggestions, fmt.Sprintf("%.0f%% of %s process runs were retried: lower the coder temperature (models.coder.temperature, %.1f by default) for more consistent output", p.RetryRate()*100, p.Schedule, defaultCoderTemp))
+		}
+		if p.Requests > 0 && p.ModelTime/time.Duration(p.Requests) >= tuneSlowRequest {
+			suggestions = append(suggestions, fmt.Sprintf("%s requests take %s on average: run obot bench and select a faster tier model", p.Schedule, (p.ModelTime/time.Duration(p.Requests)).Round(time.Second)))
+		}
+	}
+	if r.Requests > 0 {
+		if share := float64(r.RepeatedPrompt) / float64(r.Requests); share >= tuneRepeatShare && r.CachedRequests*2 < r.RepeatedPrompt {
+			suggestions = append(suggestions, fmt.Sprintf("%d of %d requests repeated an earlier prompt but %d were served from the cache: enable the prompt cache (drop --no-cache) and keep deterministic roles at temperature 0", r.RepeatedPrompt, r.Requests, r.CachedRequests))
+		}
+		if rate := float64(r.FailedRequests) / float64(r.Requests); rate >= tuneFailureRate {
+			suggestions = append(suggestions, fmt.Sprintf("%.0f%% of model requests failed: check the Ollama server (ollama.auto_start, --start-ollama) and that the models load, with obot bench", rate*100))
+		}
+	}
+	if accuracy, ok := r.PlanAccuracy(); ok && r.PlanTasks >= minTunePlannedTasks && accuracy < tunePlanAccuracy {
+		suggestions = append(suggestions, fmt.Sprintf("Approved plans predicted %.0f%% of the work: review plans more closely with --approve-plan and keep --plan-drift revisit", accuracy*100))
+	}
+	if r.Sessions > 0 && float64(r.DegradedSessions)/float64(r.Sessions) >= tuneDegradedShare {
+		suggestions = append(suggestions, fmt.Sprintf("Memory pressure degraded %d of %d sessions: select a smaller tier model or lower the context window (context.max_tokens)", r.DegradedSessions, r.Sessions))
+	}
+	return suggestions
+}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/session"
+)
+
+// tuneSession returns a session whose Implement runs are retried, once by
+// the user and once automatically, that
+// sends the same prompt twice, and that changed files its plan missed.
+func tuneSession(t *testing.T) *session.Session {
+	s := session.NewSessionWithBaseDir(t.TempDir())
+	s.AddState(orchestrate.ScheduleImplement, orchestrate.Process1, nil)
+	s.AddState(orchestrate.ScheduleImplement, orchestrate.Process1, nil)
+	s.AddState(orchestrate.ScheduleProduction, orchestrate.Process1, nil)
+	s.RecordError(session.ErrorRecord{Code: "E003", Schedule: "Implement", Resolution: "retry"})
+	s.RecordError(session.ErrorRecord{Code: "E003", Schedule: "Implement", Resolution: "auto-retry"})
+	prompt := []session.TranscriptMessage{{Role: "user", Content: "implement it"}}
+	for range 2 {
+		s.RecordTranscript(session.TranscriptEntry{Role: "coder", Model: "coder", Schedule: "Implement", Messages: prompt, PromptTokens: 800, CompletionTokens: 200, Latency: 10 * time.Second})
+	}
+	s.RecordTranscript(session.TranscriptEntry{Role: "coder", Model: "coder", Schedule: "Production", PromptTokens: 100, Latency: time.Second})
+	s.SetApprovedPlan([]session.PlannedTask{{ID: "1", File: "a.go"}, {ID: "2", File: "b.go"}})
+	s.RecordPlanFile("a.go")
+	s.RecordPlanFile("c.go")
+	s.RecordPlanFile("d.go")
+	return s
+}
+
+func TestTune(t *testing.T) {
+	r := Tune([]*session.Session{tuneSession(t), tuneSession(t), tuneSession(t)})
+
+	if len(r.Schedules) != 2 || r.Schedules[0].Schedule != "Implement" {
+		t.Fatalf("Schedules = %+v, want Implement first", r.Schedules)
+	}
+	impl := r.Schedules[0]
+	if impl.Runs != 6 || impl.Retries != 6 || impl.Tokens != 6000 || impl.ModelTime != time.Minute {
+		t.Errorf("Implement = %+v, want 6 runs, 6 retries, 6000 tokens in a minute", impl)
+	}
+	if r.RepeatedPrompt != 3 || r.Requests != 9 {
+		t.Errorf("repeated %d of %d requests, want 3 of 9", r.RepeatedPrompt, r.Requests)
+	}
+	if accuracy, ok := r.PlanAccuracy(); !ok || accuracy != 0.25 {
+		t.Errorf("PlanAccuracy() = %.2f, %v, want 0.25", accuracy, ok)
+	}
+
+	want := []string{
+		"Implement consumes 95% of all tokens",
+		"100% of Implement process runs were retried: lower the coder temperature (models.coder.temperature",
+		"3 of 9 requests repeated an earlier prompt but 0 were served from the cache: enable the prompt cache",
+		"Approved plans predicted 25% of the work",
+	}
+	if len(r.Suggestions) != len(want) {
+		t.Fatalf("Suggestions = %q, want %d", r.Suggestions, len(want))
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(r.Suggestions[i], w) {
+			t.Errorf("Suggestions[%d] = %q, want %q...", i, r.Suggestions[i], w)
+		}
+	}
+}
+
+func TestTune_NoSessions(t *testing.T) {
+	r := Tune(nil)
+	if len(r.Schedules) != 0 || len(r.Suggestions) != 0 || r.RetryRate() != 0 {
+		t.Errorf("Tune(nil) = %+v", r)
+	}
+	if _, ok := r.PlanAccuracy(); ok {
+		t.Error("PlanAccuracy() without plans is ok")
+	}
+}
@@ -0,0 +1,554 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui/layout"
+)
+
+// Format is a format the summary can be exported in.
+type Format string
+
+const (
+	FormatText     Format = "text" // the summary box without colors
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+)
+
+// FormatForPath picks the export format from a file's extension: .md,
+// .html, or .json, and plain text for anything else.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".html", ".htm":
+		return FormatHTML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatText
+	}
+}
+
+// Render renders the summary in format.
+func (g *Generator) Render(format Format) ([]byte, error) {
+	switch format {
+	case FormatText:
+		return []byte(layout.StripANSI(g.Generate()) + "\n"), nil
+	case FormatMarkdown:
+		return []byte(g.RenderMarkdown()), nil
+	case FormatHTML:
+		return []byte(g.RenderHTML()), nil
+	case FormatJSON:
+		return g.RenderJSON()
+	default:
+		return nil, fmt.Errorf("unknown summary format %q", format)
+	}
+}
+
+// Report is the summary's data, structured for export rather than for the
+// terminal. Sections with no data are left empty.
+type Report struct {
+	FlowCode         string            `json:"flow_code"`
+	TotalSchedulings int               `json:"total_schedulings"`
+	TotalProcesses   int               `json:"total_processes"`
+	Schedules        []ScheduleReport  `json:"schedules"`
+	Actions          *ActionReport     `json:"actions,omitempty"`
+	Files            []FileReport      `json:"files,omitempty"`
+	Edits            []EditReport      `json:"edits,omitempty"`
+	Coverage         *CoverageReport   `json:"coverage,omitempty"`
+	Resources        *ResourceReport   `json:"resources,omitempty"`
+	Tokens           TokenReport       `json:"tokens"`
+	Flow             []FlowStep        `json:"flow,omitempty"`
+	Notes            []NoteGroupReport `json:"notes,omitempty"`
+	TLDR             string            `json:"tldr,omitempty"`
+}
+
+// ScheduleReport is how often a schedule ran and its processes.
+type ScheduleReport struct {
+	ID          orchestrate.ScheduleID `json:"id"`
+	Name        string                 `json:"name"`
+	Schedulings int                    `json:"schedulings"`
+	Processes   []ProcessReport        `json:"processes,omitempty"`
+}
+
+// ProcessReport is how often a process ran.
+type ProcessReport struct {
+	ID    orchestrate.ProcessID `json:"id"`
+	Name  string                `json:"name"`
+	Count int                   `json:"count"`
+}
+
+// ActionReport counts the agent's actions.
+type ActionReport struct {
+	FilesCreated int `json:"files_created"`
+	FilesEdited  int `json:"files_edited"`
+	FilesDeleted int `json:"files_deleted"`
+	FilesRenamed int `json:"files_renamed"`
+	FilesMoved   int `json:"files_moved"`
+	FilesCopied  int `json:"files_copied"`
+	DirsCreated  int `json:"dirs_created"`
+	DirsDeleted  int `json:"dirs_deleted"`
+	CommandsRan  int `json:"commands_ran"`
+	Total        int `json:"total"`
+}
+
+// FileReport is the net change made to one file, with the session states
+// of the processes that made it.
+type FileReport struct {
+	Path         string   `json:"path"`
+	LinesBefore  int      `json:"lines_before"`
+	LinesAfter   int      `json:"lines_after"`
+	LinesAdded   int      `json:"lines_added"`
+	LinesRemoved int      `json:"lines_removed"`
+	Actions      string   `json:"actions"`
+	SizeBytes    int64    `json:"size_bytes"`
+	Deleted      bool     `json:"deleted,omitempty"`
+	States       []string `json:"states,omitempty"`
+}
+
+// EditReport is the edits made to one file.
+type EditReport struct {
+	Path         string `json:"path"`
+	Lines        string `json:"lines"`
+	Edits        int    `json:"edits"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+}
+
+// CoverageReport is the test coverage the run's test generation steps
+// gained, in percent of statements covered.
+type CoverageReport struct {
+	Before float64              `json:"before"`
+	After  float64              `json:"after"`
+	Steps  []CoverageStepReport `json:"steps"`
+}
+
+// CoverageStepReport is the coverage one test generation step gained.
+type CoverageStepReport struct {
+	Step    string                   `json:"step"`
+	Tool    string                   `json:"tool"`
+	Before  float64                  `json:"before"`
+	After   float64                  `json:"after"`
+	Targets []CoverageFunctionReport `json:"targets,omitempty"`
+}
+
+// CoverageFunctionReport is the coverage of a function tests were written
+// for.
+type CoverageFunctionReport struct {
+	Path     string  `json:"path"`
+	Function string  `json:"function"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+}
+
+// ResourceReport is the resources the run used.
+type ResourceReport struct {
+	PeakMemoryGB       float64  `json:"peak_memory_gb"`
+	AverageMemoryGB    float64  `json:"average_memory_gb"`
+	MemoryLimitGB      *float64 `json:"memory_limit_gb,omitempty"`
+	PressureWarnings   int      `json:"pressure_warnings"`
+	PressureCritical   int      `json:"pressure_critical"`
+	PredictionAccuracy float64  `json:"prediction_accuracy,omitempty"`
+	PeakVRAMGB         float64  `json:"peak_vram_gb,omitempty"`
+	Degradations       []string `json:"degradations,omitempty"`
+	BytesWritten       int64    `json:"bytes_written"`
+	BytesDeleted       int64    `json:"bytes_deleted"`
+	DurationSeconds    float64  `json:"duration_seconds"`
+	AgentSeconds       float64  `json:"agent_seconds"`
+	HumanWaitSeconds   float64  `json:"human_wait_seconds"`
+}
+
+// TokenReport is the tokens the run used.
+type TokenReport struct {
+	Total      int64            `json:"total"`
+	BySchedule map[string]int64 `json:"by_schedule,omitempty"`
+}
+
+// FlowStep is the tokens one process execution used.
+type FlowStep struct {
+	Schedule   orchestrate.ScheduleID `json:"schedule"`
+	Process    orchestrate.ProcessID  `json:"process"`
+	Name       string                 `json:"name"`
+	Tokens     int64                  `json:"tokens"`
+	Cumulative int64                  `json:"cumulative"`
+	Percentage float64                `json:"percentage"`
+}
+
+// NoteGroupReport is the session notes of one type.
+type NoteGroupReport struct {
+	Type  orchestrate.NoteType `json:"type"`
+	Notes []string             `json:"notes"`
+}
+
+// Report returns the summary's data for export.
+func (g *Generator) Report() *Report {
+	r := &Report{FlowCode: g.flowCode, TLDR: strings.TrimRight(g.tldr, "\n")}
+
+	if g.stats != nil {
+		r.TotalSchedulings = g.stats.TotalSchedulings
+		r.TotalProcesses = g.stats.TotalProcesses
+		r.Tokens.Total = g.stats.TotalTokens
+		for _, sid := range orchestrate.AllSchedules() {
+			sched := ScheduleReport{ID: sid, Name: orchestrate.ScheduleNames[sid], Schedulings: g.stats.SchedulingsByID[sid]}
+			for pid := orchestrate.Process1; pid <= orchestrate.Process3; pid++ {
+				if count := g.stats.ProcessesBySchedule[sid][pid]; count > 0 {
+					sched.Processes = append(sched.Processes, ProcessReport{ID: pid, Name: orchestrate.ProcessNames[sid][pid], Count: count})
+				}
+			}
+			r.Schedules = append(r.Schedules, sched)
+		}
+	}
+
+	if a := g.actions; a != nil {
+		r.Actions = &ActionReport{
+			FilesCreated: a.FilesCreated, FilesEdited: a.FilesEdited, FilesDeleted: a.FilesDeleted,
+			FilesRenamed: a.FilesRenamed, FilesMoved: a.FilesMoved, FilesCopied: a.FilesCopied,
+			DirsCreated: a.DirsCreated, DirsDeleted: a.DirsDeleted,
+			CommandsRan: a.CommandsRan, Total: a.TotalActions,
+		}
+	}
+	for _, f := range g.files {
+		r.Files = append(r.Files, FileReport{
+			Path:         f.Path,
+			LinesBefore:  f.LinesBefore,
+			LinesAfter:   f.LinesAfter,
+			LinesAdded:   f.Added,
+			LinesRemoved: f.Removed,
+			Actions:      formatFileActions(f.Actions),
+			SizeBytes:    max(f.Size, 0),
+			Deleted:      f.Size < 0,
+			States:       f.StateIDs,
+		})
+	}
+	for _, edit := range g.edits {
+		e := EditReport{Path: edit.Path, Lines: formatLineRanges(edit.LineRanges), Edits: edit.EditCount}
+		if edit.Diff != nil {
+			e.LinesAdded, e.LinesRemoved = edit.Diff.TotalAdded, edit.Diff.TotalRemoved
+		}
+		r.Edits = append(r.Edits, e)
+	}
+
+	if len(g.coverage) > 0 {
+		r.Coverage = &CoverageReport{Before: g.coverage[0].Before, After: g.coverage[len(g.coverage)-1].After}
+		for _, c := range g.coverage {
+			step := CoverageStepReport{Step: c.Step, Tool: c.Tool, Before: c.Before, After: c.After}
+			for _, t := range c.Targets {
+				step.Targets = append(step.Targets, CoverageFunctionReport{Path: t.Path, Function: t.Function, Before: t.Before, After: t.After})
+			}
+			r.Coverage.Steps = append(r.Coverage.Steps, step)
+		}
+	}
+
+	if res := g.resources; res != nil {
+		rr := &ResourceReport{
+			PeakMemoryGB:       res.Memory.PeakUsageGB,
+			AverageMemoryGB:    res.Memory.AverageUsageGB,
+			MemoryLimitGB:      res.Memory.LimitGB,
+			PressureWarnings:   res.Memory.PressureWarnings,
+			PressureCritical:   res.Memory.PressureCritical,
+			PredictionAccuracy: res.Memory.PredictionAccuracy,
+			PeakVRAMGB:         res.VRAM.Peak,
+			BytesWritten:       res.Disk.FilesWrittenBytes,
+			BytesDeleted:       res.Disk.FilesDeletedBytes,
+			DurationSeconds:    res.Time.TotalDuration.Seconds(),
+			AgentSeconds:       res.Time.AgentActive.Seconds(),
+			HumanWaitSeconds:   res.Time.HumanWait.Seconds(),
+		}
+		for _, d := range res.Degradations {
+			rr.Degradations = append(rr.Degradations, d.String())
+		}
+		r.Resources = rr
+		if len(res.Tokens.BySchedule) > 0 {
+			r.Tokens.BySchedule = make(map[string]int64, len(res.Tokens.BySchedule))
+			for sid, tokens := range res.Tokens.BySchedule {
+				r.Tokens.BySchedule[orchestrate.ScheduleNames[sid]] = tokens
+			}
+		}
+	}
+
+	var cumulative int64
+	for _, entry := range g.processTokens {
+		cumulative += entry.Tokens
+		r.Flow = append(r.Flow, FlowStep{
+			Schedule:   entry.Schedule,
+			Process:    entry.Process,
+			Name:       orchestrate.ProcessNames[entry.Schedule][entry.Process],
+			Tokens:     entry.Tokens,
+			Cumulative: cumulative,
+			Percentage: g.pct(cumulative, r.Tokens.Total),
+		})
+	}
+
+	groups := orchestrate.GroupNotesByType(g.notes)
+	for _, t := range orchestrate.NoteTypes {
+		if len(groups[t]) == 0 {
+			continue
+		}
+		group := NoteGroupReport{Type: t}
+		for _, n := range groups[t] {
+			group.Notes = append(group.Notes, n.Content)
+		}
+		r.Notes = append(r.Notes, group)
+	}
+	return r
+}
+
+// RenderJSON renders the summary as indented JSON, for dashboards and
+// other tools.
+func (g *Generator) RenderJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g.Report()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderMarkdown renders the summary as Markdown, for pull requests and
+// tickets.
+func (g *Generator) RenderMarkdown() string {
+	r := g.Report()
+	var sb strings.Builder
+	w := func(format string, args ...any) { fmt.Fprintf(&sb, format, args...) }
+
+	w("# Prompt Summary\n\n")
+	if r.FlowCode != "" {
+		w("**Flow:** `%s`\n\n", r.FlowCode)
+	}
+
+	w("## Schedules\n\n")
+	w("%d schedulings, %d processes.\n\n", r.TotalSchedulings, r.TotalProcesses)
+	if len(r.Schedules) > 0 {
+		w("| Schedule | Schedulings | Processes |\n|---|---:|---|\n")
+		for _, s := range r.Schedules {
+			var procs []string
+			for _, p := range s.Processes {
+				procs = append(procs, fmt.Sprintf("%s ×%d", p.Name, p.Count))
+			}
+			w("| %s | %d | %s |\n", s.Name, s.Schedulings, markdownCell(strings.Join(procs, ", ")))
+		}
+		w("\n")
+	}
+
+	if a := r.Actions; a != nil {
+		w("## Agent Actions\n\n")
+		w("| Action | Count |\n|---|---:|\n")
+		w("| Files created | %d |\n| Files edited | %d |\n| Files deleted | %d |\n", a.FilesCreated, a.FilesEdited, a.FilesDeleted)
+		w("| Files renamed | %d |\n| Files moved | %d |\n| Files copied | %d |\n", a.FilesRenamed, a.FilesMoved, a.FilesCopied)
+		w("| Directories created | %d |\n| Directories deleted | %d |\n", a.DirsCreated, a.DirsDeleted)
+		w("| Commands run | %d |\n\n", a.CommandsRan)
+	}
+	if len(r.Files) > 0 {
+		w("### Files\n\n")
+		w("| File | Added | Removed | Lines | Size | Actions | States |\n|---|---:|---:|---|---:|---|---|\n")
+		for _, f := range r.Files {
+			size := formatBytes(f.SizeBytes)
+			if f.Deleted {
+				size = "deleted"
+			}
+			w("| `%s` | +%d | -%d | %d → %d | %s | %s | %s |\n", markdownCell(f.Path), f.LinesAdded, f.LinesRemoved,
+				f.LinesBefore, f.LinesAfter, size, f.Actions, strings.Join(f.States, ", "))
+		}
+		w("\n")
+	}
+	if len(r.Edits) > 0 {
+		w("### Edits\n\n")
+		w("| File | Lines | Edits | Added | Removed |\n|---|---|---:|---:|---:|\n")
+		for _, e := range r.Edits {
+			w("| `%s` | %s | %d | +%d | -%d |\n", markdownCell(e.Path), e.Lines, e.Edits, e.LinesAdded, e.LinesRemoved)
+		}
+		w("\n")
+	}
+
+	if c := r.Coverage; c != nil {
+		w("## Coverage\n\n")
+		w("Test generation: %s.\n\n", formatCoverageDelta(c.Before, c.After))
+		w("| Step | Function | Before | After |\n|---|---|---:|---:|\n")
+		for _, s := range c.Steps {
+			w("| %s | (total, %s) | %.1f%% | %.1f%% |\n", s.Step, s.Tool, s.Before, s.After)
+			for _, t := range s.Targets {
+				w("| %s | `%s` %s | %.1f%% | %.1f%% |\n", s.Step, markdownCell(t.Path), markdownCell(t.Function), t.Before, t.After)
+			}
+		}
+		w("\n")
+	}
+
+	if res := r.Resources; res != nil {
+		w("## Resources\n\n")
+		w("- Peak memory: %.1f GB (average %.1f GB)\n", res.PeakMemoryGB, res.AverageMemoryGB)
+		if res.MemoryLimitGB != nil {
+			w("- Memory limit: %.1f GB\n", *res.MemoryLimitGB)
+		}
+		w("- Pressure events: %d warning, %d critical\n", res.PressureWarnings, res.PressureCritical)
+		if res.PredictionAccuracy > 0 {
+			w("- Prediction accuracy: %.1f%%\n", res.PredictionAccuracy*100)
+		}
+		if res.PeakVRAMGB > 0 {
+			w("- Peak VRAM: %.1f GB\n", res.PeakVRAMGB)
+		}
+		for _, d := range res.Degradations {
+			w("- Degraded: %s\n", d)
+		}
+		w("- Disk: %s written, %s deleted\n", formatBytes(res.BytesWritten), formatBytes(res.BytesDeleted))
+		w("- Duration: %s\n\n", formatSeconds(res.DurationSeconds))
+	}
+
+	w("## Tokens\n\n")
+	w("%s total.\n\n", formatNumber(r.Tokens.Total))
+	if len(r.Flow) > 0 {
+		w("| Step | Process | Tokens | Cumulative |\n|---|---|---:|---:|\n")
+		for _, f := range r.Flow {
+			w("| S%dP%d | %s | %s | %s (%.1f%%) |\n", f.Schedule, f.Process, f.Name,
+				formatNumber(f.Tokens), formatNumber(f.Cumulative), f.Percentage)
+		}
+		w("\n")
+	}
+
+	if len(r.Notes) > 0 {
+		w("## Notes\n\n")
+		for _, group := range r.Notes {
+			w("**%s**\n\n", strings.ToUpper(string(group.Type)))
+			for _, n := range group.Notes {
+				w("- %s\n", n)
+			}
+			w("\n")
+		}
+	}
+
+	if r.TLDR != "" {
+		w("## TLDR\n\n%s\n", r.TLDR)
+	}
+	return sb.String()
+}
+
+// markdownCell escapes s for a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+var htmlReport = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"number":  formatNumber,
+	"bytes":   formatBytes,
+	"seconds": formatSeconds,
+	"upper":   func(t orchestrate.NoteType) string { return strings.ToUpper(string(t)) },
+	"delta":   formatCoverageDelta,
+	"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+	"gb":      func(f float64) string { return fmt.Sprintf("%.1f GB", f) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>OllamaBot Prompt Summary</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 1000px; margin: 0 auto; padding: 20px; background-color: #f5f7f9; }
+        h1 { color: #1a1b26; border-bottom: 2px solid #7aa2f7; padding-bottom: 10px; }
+        h2 { color: #1a1b26; }
+        table { border-collapse: collapse; background: white; margin-bottom: 20px; }
+        th, td { padding: 4px 12px; border-bottom: 1px solid #e1e4e8; text-align: left; }
+        td.num { text-align: right; }
+        code { color: #565f89; }
+        .flow { font-family: monospace; font-size: 18px; color: #7aa2f7; }
+    </style>
+</head>
+<body>
+    <h1>OllamaBot Prompt Summary</h1>
+    {{if .FlowCode}}<p class="flow">{{.FlowCode}}</p>{{end}}
+
+    <h2>Schedules</h2>
+    <p>{{.TotalSchedulings}} schedulings, {{.TotalProcesses}} processes.</p>
+    {{if .Schedules}}<table>
+        <tr><th>Schedule</th><th>Schedulings</th><th>Processes</th></tr>
+        {{range .Schedules}}<tr><td>{{.Name}}</td><td class="num">{{.Schedulings}}</td><td>{{range $i, $p := .Processes}}{{if $i}}, {{end}}{{$p.Name}} ×{{$p.Count}}{{end}}</td></tr>
+        {{end}}
+    </table>{{end}}
+
+    {{with .Actions}}<h2>Agent Actions</h2>
+    <table>
+        <tr><td>Files created</td><td class="num">{{.FilesCreated}}</td></tr>
+        <tr><td>Files edited</td><td class="num">{{.FilesEdited}}</td></tr>
+        <tr><td>Files deleted</td><td class="num">{{.FilesDeleted}}</td></tr>
+        <tr><td>Files renamed</td><td class="num">{{.FilesRenamed}}</td></tr>
+        <tr><td>Files moved</td><td class="num">{{.FilesMoved}}</td></tr>
+        <tr><td>Files copied</td><td class="num">{{.FilesCopied}}</td></tr>
+        <tr><td>Directories created</td><td class="num">{{.DirsCreated}}</td></tr>
+        <tr><td>Directories deleted</td><td class="num">{{.DirsDeleted}}</td></tr>
+        <tr><td>Commands run</td><td class="num">{{.CommandsRan}}</td></tr>
+    </table>{{end}}
+    {{if .Files}}<h3>Files</h3>
+    <table>
+        <tr><th>File</th><th>Added</th><th>Removed</th><th>Lines</th><th>Size</th><th>Actions</th><th>States</th></tr>
+        {{range .Files}}<tr><td><code>{{.Path}}</code></td><td class="num">+{{.LinesAdded}}</td><td class="num">-{{.LinesRemoved}}</td><td>{{.LinesBefore}} → {{.LinesAfter}}</td><td class="num">{{if .Deleted}}deleted{{else}}{{bytes .SizeBytes}}{{end}}</td><td>{{.Actions}}</td><td>{{range $i, $s := .States}}{{if $i}}, {{end}}<code>{{$s}}</code>{{end}}</td></tr>
+        {{end}}
+    </table>{{end}}
+    {{if .Edits}}<h3>Edits</h3>
+    <table>
+        <tr><th>File</th><th>Lines</th><th>Edits</th><th>Added</th><th>Removed</th></tr>
+        {{range .Edits}}<tr><td><code>{{.Path}}</code></td><td>{{.Lines}}</td><td class="num">{{.Edits}}</td><td class="num">+{{.LinesAdded}}</td><td class="num">-{{.LinesRemoved}}</td></tr>
+        {{end}}
+    </table>{{end}}
+
+    {{with .Coverage}}<h2>Coverage</h2>
+    <p>Test generation: {{delta .Before .After}}.</p>
+    <table>
+        <tr><th>Step</th><th>Function</th><th>Before</th><th>After</th></tr>
+        {{range $s := .Steps}}<tr><td>{{$s.Step}}</td><td>(total, {{$s.Tool}})</td><td class="num">{{percent $s.Before}}</td><td class="num">{{percent $s.After}}</td></tr>
+        {{range $s.Targets}}<tr><td>{{$s.Step}}</td><td><code>{{.Path}}</code> {{.Function}}</td><td class="num">{{percent .Before}}</td><td class="num">{{percent .After}}</td></tr>
+        {{end}}{{end}}
+    </table>{{end}}
+
+    {{with .Resources}}<h2>Resources</h2>
+    <ul>
+        <li>Peak memory: {{gb .PeakMemoryGB}} (average {{gb .AverageMemoryGB}})</li>
+        {{with .MemoryLimitGB}}<li>Memory limit: {{gb .}}</li>{{end}}
+        <li>Pressure events: {{.PressureWarnings}} warning, {{.PressureCritical}} critical</li>
+        {{if .PeakVRAMGB}}<li>Peak VRAM: {{gb .PeakVRAMGB}}</li>{{end}}
+        {{range .Degradations}}<li>Degraded: {{.}}</li>
+        {{end}}
+        <li>Disk: {{bytes .BytesWritten}} written, {{bytes .BytesDeleted}} deleted</li>
+        <li>Duration: {{seconds .DurationSeconds}}</li>
+    </ul>{{end}}
+
+    <h2>Tokens</h2>
+    <p>{{number .Tokens.Total}} total.</p>
+    {{if .Flow}}<table>
+        <tr><th>Step</th><th>Process</th><th>Tokens</th><th>Cumulative</th></tr>
+        {{range .Flow}}<tr><td>S{{.Schedule}}P{{.Process}}</td><td>{{.Name}}</td><td class="num">{{number .Tokens}}</td><td class="num">{{number .Cumulative}} ({{percent .Percentage}})</td></tr>
+        {{end}}
+    </table>{{end}}
+
+    {{if .Notes}}<h2>Notes</h2>
+    {{range .Notes}}<h3>{{upper .Type}}</h3>
+    <ul>{{range .Notes}}<li>{{.}}</li>{{end}}</ul>
+    {{end}}{{end}}
+
+    {{if .TLDR}}<h2>TLDR</h2>
+    <pre>{{.TLDR}}</pre>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML renders the summary as a standalone HTML page.
+func (g *Generator) RenderHTML() string {
+	var buf bytes.Buffer
+	if err := htmlReport.Execute(&buf, g.Report()); err != nil {
+		// The template is fixed and its data always fits it
+		panic(err)
+	}
+	return buf.String()
+}
+
+// formatSeconds formats a duration given in seconds.
+func formatSeconds(s float64) string {
+	return formatDuration(time.Duration(s * float64(time.Second)))
+}
@@ -0,0 +1,71 @@
+package summary
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/test"
+)
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]Format{
+		"report.md":   FormatMarkdown,
+		"REPORT.MD":   FormatMarkdown,
+		"out.html":    FormatHTML,
+		"out.json":    FormatJSON,
+		"summary.txt": FormatText,
+		"summary":     FormatText,
+	}
+	for path, want := range tests {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRenderMarkdown_Golden(t *testing.T) {
+	test.AssertGolden(t, "summary_markdown", []byte(fixtureGenerator().RenderMarkdown()))
+}
+
+func TestRenderJSON_Golden(t *testing.T) {
+	data, err := fixtureGenerator().RenderJSON()
+	if err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("RenderJSON() is not valid JSON: %v", err)
+	}
+	test.AssertGolden(t, "summary_json", data)
+}
+
+func TestRenderHTML_Escapes(t *testing.T) {
+	g := NewGenerator()
+	g.SetActions(&agent.ActionStats{FilesEdited: 1}, []agent.EditDetail{{Path: "<script>.go", EditCount: 1}})
+	g.SetNotes([]orchestrate.Note{{Content: "a & b", Type: orchestrate.NoteDecision}})
+	out := g.RenderHTML()
+	if strings.Contains(out, "<script>") {
+		t.Error("RenderHTML() does not escape file paths")
+	}
+	for _, want := range []string{"&lt;script&gt;.go", "a &amp; b", "DECISION", "</html>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("RenderHTML() missing %q", want)
+		}
+	}
+}
+
+func TestRender_TextHasNoANSI(t *testing.T) {
+	data, err := fixtureGenerator().Render(FormatText)
+	if err != nil {
+		t.Fatalf("Render(FormatText) error = %v", err)
+	}
+	if strings.Contains(string(data), "\x1b") {
+		t.Error("Render(FormatText) contains escape sequences")
+	}
+	if _, err := fixtureGenerator().Render("pdf"); err == nil {
+		t.Error("Render(\"pdf\") should fail")
+	}
+}
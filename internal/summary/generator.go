@@ -9,7 +9,9 @@ import (
 	"github.com/croberts/obot/internal/agent"
 	"github.com/croberts/obot/internal/orchestrate"
 	"github.com/croberts/obot/internal/resource"
+	orchsession "github.com/croberts/obot/internal/session"
 	"github.com/croberts/obot/internal/ui"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 // Generator generates comprehensive prompt summaries.
@@ -19,11 +21,16 @@ type Generator struct {
 	flowCode string
 	actions  *agent.ActionStats
 	edits    []agent.EditDetail
+	files    []agent.FileChange
 	resources *resource.ResourceSummary
+	coverage []orchsession.CoverageRecord
 	tldr     string
+	notes    []orchestrate.Note
 
 	// Token tracking by process
 	processTokens []ProcessTokenEntry
+
+	width int // box width; 0 uses summaryWidth
 }
 
 // ProcessTokenEntry tracks tokens for a process execution
@@ -42,6 +49,11 @@ func NewGenerator() *Generator {
 	}
 }
 
+// SetWidth sets the width of the summary box, such as the terminal's.
+func (g *Generator) SetWidth(width int) {
+	g.width = width
+}
+
 // SetStats sets the orchestrator statistics
 func (g *Generator) SetStats(stats *orchestrate.OrchestratorStats) {
 	g.stats = stats
@@ -58,11 +70,26 @@ func (g *Generator) SetActions(actions *agent.ActionStats, edits []agent.EditDet
 	g.edits = edits
 }
 
+// SetFileChanges sets the per-file changes shown in the action breakdown
+func (g *Generator) SetFileChanges(files []agent.FileChange) {
+	g.files = files
+}
+
 // SetResources sets the resource summary
 func (g *Generator) SetResources(resources *resource.ResourceSummary) {
 	g.resources = resources
 }
 
+// SetCoverage sets the coverage gained by the test generation steps
+func (g *Generator) SetCoverage(coverage []orchsession.CoverageRecord) {
+	g.coverage = coverage
+}
+
+// SetNotes sets the session notes shown grouped by type
+func (g *Generator) SetNotes(notes []orchestrate.Note) {
+	g.notes = notes
+}
+
 // SetTLDR sets the TLDR content
 func (g *Generator) SetTLDR(tldr string) {
 	g.tldr = tldr
@@ -92,101 +119,104 @@ func (g *Generator) AddProcessTokens(scheduleID orchestrate.ScheduleID, processI
 	})
 }
 
+// summaryWidth is the width of the summary box when none is set.
+const summaryWidth = 71
+
 // Generate generates the complete prompt summary
 func (g *Generator) Generate() string {
-	var sb strings.Builder
+	width := g.width
+	if width <= 0 {
+		width = summaryWidth
+	}
+	box := layout.NewBox(width)
 
 	// Header
-	sb.WriteString("┌─────────────────────────────────────────────────────────────────────┐\n")
-	sb.WriteString("│ Orchestrator • Prompt Summary                                       │\n")
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
+	box.Line("Orchestrator • Prompt Summary")
+	box.Divider()
 
 	// Flow code
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString(fmt.Sprintf("│ %s\n", ui.FormatFlowCode(g.flowCode)))
-	sb.WriteString("│ ▲  ▲▲▲▲▲▲▲▲                                                         │\n")
-	sb.WriteString("│ │  └──┴──┴──── Process codes (blue)                                 │\n")
-	sb.WriteString("│ └───────────── Schedule codes (white)                               │\n")
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
+	box.Clipped(ui.FormatFlowCode(g.flowCode))
+	box.Clipped("▲  ▲▲▲▲▲▲▲▲")
+	box.Clipped("│  └──┴──┴──── Process codes (blue)")
+	box.Clipped("└───────────── Schedule codes (white)")
+	box.Blank()
 
 	// Schedule statistics
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateScheduleStats())
+	box.Divider()
+	g.writeScheduleStats(box)
 
 	// Process statistics
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateProcessSummary())
+	box.Divider()
+	g.writeProcessSummary(box)
 
 	// Agent action breakdown
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateActionBreakdown())
+	box.Divider()
+	g.writeActionBreakdown(box)
+
+	// Test coverage
+	g.writeCoverageSummary(box)
 
 	// Resource summary
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateResourceSummary())
+	box.Divider()
+	g.writeResourceSummary(box)
 
 	// Token summary
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateTokenSummary())
+	box.Divider()
+	g.writeTokenSummary(box)
 
 	// Generation flow
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString(g.generateGenerationFlow())
+	box.Divider()
+	g.writeGenerationFlow(box)
 
-	// TLDR
-	sb.WriteString("├─────────────────────────────────────────────────────────────────────┤\n")
-	sb.WriteString("│ OllamaBot • TLDR                                                    │\n")
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString(g.formatTLDR())
-	sb.WriteString("│                                                                     │\n")
+	// Session notes
+	g.writeNotesSummary(box)
 
-	// Footer
-	sb.WriteString("└─────────────────────────────────────────────────────────────────────┘\n")
+	// TLDR
+	box.Divider()
+	box.Line("OllamaBot • TLDR")
+	box.Blank()
+	g.writeTLDR(box)
+	box.Blank()
 
-	return sb.String()
+	return box.String()
 }
 
-// generateScheduleStats generates schedule statistics
-func (g *Generator) generateScheduleStats() string {
-	var sb strings.Builder
-
+// writeScheduleStats writes schedule statistics
+func (g *Generator) writeScheduleStats(box *layout.Box) {
 	total := 0
 	if g.stats != nil {
 		total = g.stats.TotalSchedulings
 	}
 
-	sb.WriteString(fmt.Sprintf("│ Schedule • %d Total Schedulings                                      │\n", total))
+	box.Linef("Schedule • %d Total Schedulings", total)
 
 	if g.stats != nil {
-		for sid := orchestrate.ScheduleKnowledge; sid <= orchestrate.ScheduleProduction; sid++ {
+		for _, sid := range orchestrate.AllSchedules() {
 			count := g.stats.SchedulingsByID[sid]
 			name := orchestrate.ScheduleNames[sid]
 			percent := 0.0
 			if total > 0 {
 				percent = float64(count) / float64(total) * 100
 			}
-			sb.WriteString(fmt.Sprintf("│   %s: %d scheduling%s (%.1f%%)\n", name, count, pluralize(count, "", "s"), percent))
+			box.Linef("  %s: %d scheduling%s (%.1f%%)", name, count, pluralize(count, "", "s"), percent)
 		}
 	}
-	sb.WriteString("│                                                                     │\n")
-
-	return sb.String()
+	box.Blank()
 }
 
-// generateProcessSummary generates process statistics
-func (g *Generator) generateProcessSummary() string {
-	var sb strings.Builder
-
+// writeProcessSummary writes process statistics
+func (g *Generator) writeProcessSummary(box *layout.Box) {
 	total := 0
 	if g.stats != nil {
 		total = g.stats.TotalProcesses
 	}
 
-	sb.WriteString(fmt.Sprintf("│ Process • %d Total Processes                                        │\n", total))
-	sb.WriteString("│                                                                     │\n")
+	box.Linef("Process • %d Total Processes", total)
+	box.Blank()
 
 	if g.stats != nil && total > 0 {
-		for sid := orchestrate.ScheduleKnowledge; sid <= orchestrate.ScheduleProduction; sid++ {
+		for _, sid := range orchestrate.AllSchedules() {
 			scheduleTotal := 0
 			processMap := g.stats.ProcessesBySchedule[sid]
 			if processMap != nil {
@@ -207,8 +237,8 @@ func (g *Generator) generateProcessSummary() string {
 			}
 
 			scheduleName := orchestrate.ScheduleNames[sid]
-			sb.WriteString(fmt.Sprintf("│ %s • %d total (%.1f%% of all)\n", scheduleName, scheduleTotal, schedulePercent))
-			sb.WriteString(fmt.Sprintf("│   Averaging %.1f processes per scheduling\n", avgProcesses))
+			box.Linef("%s • %d total (%.1f%% of all)", scheduleName, scheduleTotal, schedulePercent)
+			box.Linef("  Averaging %.1f processes per scheduling", avgProcesses)
 
 			// Process breakdown
 			for pid := orchestrate.Process1; pid <= orchestrate.Process3; pid++ {
@@ -218,40 +248,36 @@ func (g *Generator) generateProcessSummary() string {
 				count := processMap[pid]
 				processPercent := g.pct(int64(count), int64(scheduleTotal))
 				processName := orchestrate.ProcessNames[sid][pid]
-				sb.WriteString(fmt.Sprintf("│   %s: %d (%.1f%% of %s)\n", processName, count, processPercent, scheduleName))
+				box.Linef("  %s: %d (%.1f%% of %s)", processName, count, processPercent, scheduleName)
 			}
-			sb.WriteString("│                                                                     │\n")
+			box.Blank()
 		}
 	}
-
-	return sb.String()
 }
 
-// generateActionBreakdown generates the action breakdown
-func (g *Generator) generateActionBreakdown() string {
-	var sb strings.Builder
-
-	sb.WriteString("│ Agent • Action Breakdown                                            │\n")
-	sb.WriteString("│                                                                     │\n")
+// writeActionBreakdown writes the action breakdown
+func (g *Generator) writeActionBreakdown(box *layout.Box) {
+	box.Line("Agent • Action Breakdown")
+	box.Blank()
 
 	if g.actions != nil {
-		sb.WriteString(fmt.Sprintf("│ Created • %d files, %d directories\n", g.actions.FilesCreated, g.actions.DirsCreated))
-		sb.WriteString(fmt.Sprintf("│ Deleted • %d files, %d directories\n", g.actions.FilesDeleted, g.actions.DirsDeleted))
-		sb.WriteString(fmt.Sprintf("│ Renamed • %d files, %d directories\n", g.actions.FilesRenamed, g.actions.DirsRenamed))
-		sb.WriteString(fmt.Sprintf("│ Moved • %d files, %d directories\n", g.actions.FilesMoved, g.actions.DirsMoved))
-		sb.WriteString(fmt.Sprintf("│ Copied • %d files, %d directories\n", g.actions.FilesCopied, g.actions.DirsCopied))
-		sb.WriteString(fmt.Sprintf("│ Ran • %d commands\n", g.actions.CommandsRan))
-		sb.WriteString(fmt.Sprintf("│ Edited • %d files\n", g.actions.FilesEdited))
+		box.Linef("Created • %d files, %d directories", g.actions.FilesCreated, g.actions.DirsCreated)
+		box.Linef("Deleted • %d files, %d directories", g.actions.FilesDeleted, g.actions.DirsDeleted)
+		box.Linef("Renamed • %d files, %d directories", g.actions.FilesRenamed, g.actions.DirsRenamed)
+		box.Linef("Moved • %d files, %d directories", g.actions.FilesMoved, g.actions.DirsMoved)
+		box.Linef("Copied • %d files, %d directories", g.actions.FilesCopied, g.actions.DirsCopied)
+		box.Linef("Ran • %d commands", g.actions.CommandsRan)
+		box.Linef("Edited • %d files", g.actions.FilesEdited)
 	}
 
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
+	g.writeFileChanges(box)
 
 	// Edit details
 	if len(g.edits) > 0 {
-		sb.WriteString("│ Edit Details:                                                       │\n")
+		box.Line("Edit Details:")
 		for _, edit := range g.edits {
-			rangesStr := formatLineRanges(edit.LineRanges)
-			sb.WriteString(fmt.Sprintf("│   %s at %s\n", edit.Path, rangesStr))
+			box.Linef("  %s at %s", edit.Path, formatLineRanges(edit.LineRanges))
 
 			// Show diff preview (first few lines)
 			if edit.Diff != nil {
@@ -259,115 +285,164 @@ func (g *Generator) generateActionBreakdown() string {
 					if i >= 2 {
 						break
 					}
-					sb.WriteString(fmt.Sprintf("│   %s-  %4d │ %s\n", ui.ANSIRed, line.LineNumber, truncate(line.Content, 50)))
+					box.Clipped(fmt.Sprintf("  %s-  %4d │ %s%s", ui.ANSIRed, line.LineNumber, line.Content, ui.ANSIReset))
 				}
 				for i, line := range edit.Diff.Additions {
 					if i >= 2 {
 						break
 					}
-					sb.WriteString(fmt.Sprintf("│   %s+  %4d │ %s\n", ui.ANSIGreen, line.LineNumber, truncate(line.Content, 50)))
+					box.Clipped(fmt.Sprintf("  %s+  %4d │ %s%s", ui.ANSIGreen, line.LineNumber, line.Content, ui.ANSIReset))
 				}
-				sb.WriteString("│   ...\n")
+				box.Line("  ...")
 			}
 		}
 	}
-	sb.WriteString("│                                                                     │\n")
+	box.Blank()
+}
 
-	return sb.String()
+// writeFileChanges writes a row per changed file: its lines added and
+// removed, its lines before and after, its final size, then the actions
+// applied and the session states to drill into.
+func (g *Generator) writeFileChanges(box *layout.Box) {
+	if len(g.files) == 0 {
+		return
+	}
+	box.Line("Files:")
+	for _, f := range g.files {
+		box.Clipped(fmt.Sprintf("  %s %s %s",
+			layout.Pad(layout.Truncate(f.Path, 28), 28),
+			layout.Pad(fmt.Sprintf("+%d -%d", f.Added, f.Removed), 11),
+			formatFileSize(f)))
+		detail := formatFileActions(f.Actions)
+		if len(f.StateIDs) > 0 {
+			detail += " • " + strings.Join(f.StateIDs, ", ")
+		}
+		box.Line("    " + detail)
+	}
+	box.Blank()
 }
 
-// generateResourceSummary generates the resource summary
-func (g *Generator) generateResourceSummary() string {
-	var sb strings.Builder
+// writeCoverageSummary writes the coverage the test generation steps
+// gained, overall and per step, in a section of its own when any ran
+func (g *Generator) writeCoverageSummary(box *layout.Box) {
+	if len(g.coverage) == 0 {
+		return
+	}
+	box.Divider()
+	first, last := g.coverage[0], g.coverage[len(g.coverage)-1]
+	box.Line("Tests • Coverage " + formatCoverageDelta(first.Before, last.After))
+	box.Blank()
+	for _, c := range g.coverage {
+		box.Linef("%s: %s, %d function%s targeted", c.Step, formatCoverageDelta(c.Before, c.After),
+			len(c.Targets), pluralize(len(c.Targets), "", "s"))
+		for _, t := range c.Targets {
+			box.Clipped(fmt.Sprintf("  %s %s: %.1f%% → %.1f%%", t.Path, t.Function, t.Before, t.After))
+		}
+	}
+	box.Blank()
+}
 
-	sb.WriteString("│ Resources • Summary                                                 │\n")
-	sb.WriteString("│                                                                     │\n")
+// writeResourceSummary writes the resource summary
+func (g *Generator) writeResourceSummary(box *layout.Box) {
+	box.Line("Resources • Summary")
+	box.Blank()
 
 	if g.resources != nil {
 		// Memory
-		sb.WriteString("│ Memory:                                                             │\n")
-		sb.WriteString(fmt.Sprintf("│   Peak Usage: %.1f GB\n", g.resources.Memory.PeakUsageGB))
-		sb.WriteString(fmt.Sprintf("│   Average Usage: %.1f GB\n", g.resources.Memory.AverageUsageGB))
+		box.Line("Memory:")
+		box.Linef("  Peak Usage: %.1f GB", g.resources.Memory.PeakUsageGB)
+		box.Linef("  Average Usage: %.1f GB", g.resources.Memory.AverageUsageGB)
 		if g.resources.Memory.LimitGB != nil {
-			sb.WriteString(fmt.Sprintf("│   Limit: %.1f GB\n", *g.resources.Memory.LimitGB))
+			box.Linef("  Limit: %.1f GB", *g.resources.Memory.LimitGB)
 		} else {
-			sb.WriteString("│   Limit: None (unlimited)\n")
+			box.Line("  Limit: None (unlimited)")
+		}
+		box.Linef("  Pressure Events: %d warning, %d critical",
+			g.resources.Memory.PressureWarnings, g.resources.Memory.PressureCritical)
+		if g.resources.Memory.PredictionAccuracy > 0 {
+			box.Linef("  Predictions Accuracy: %.1f%%", g.resources.Memory.PredictionAccuracy*100)
+		}
+		box.Blank()
+
+		// GPU memory, when a GPU was probed
+		if vram := g.resources.VRAM; vram.Source != "" {
+			box.Line("VRAM:")
+			box.Linef("  Peak Usage: %.1f GB of %.1f GB (%s)", vram.Peak, vram.Total, vram.Source)
+			box.Linef("  Pressure Events: %d warning, %d critical", vram.Warnings, vram.Critical)
+			box.Blank()
+		}
+
+		// Quality traded for memory under pressure
+		if len(g.resources.Degradations) > 0 {
+			box.Line("Degradations:")
+			for _, d := range g.resources.Degradations {
+				box.Linef("  %s %s", d.Time.Format("15:04:05"), d)
+			}
+			box.Blank()
 		}
-		sb.WriteString(fmt.Sprintf("│   Pressure Events: %d warning, %d critical\n",
-			g.resources.Memory.PressureWarnings, g.resources.Memory.PressureCritical))
-		sb.WriteString(fmt.Sprintf("│   Predictions Accuracy: %.1f%%\n", g.resources.Memory.PredictionAccuracy*100))
-		sb.WriteString("│                                                                     │\n")
 
 		// Disk
-		sb.WriteString("│ Disk:                                                               │\n")
-		sb.WriteString(fmt.Sprintf("│   Files Written: %s\n", formatBytes(g.resources.Disk.FilesWrittenBytes)))
-		sb.WriteString(fmt.Sprintf("│   Files Deleted: %s\n", formatBytes(g.resources.Disk.FilesDeletedBytes)))
-		sb.WriteString(fmt.Sprintf("│   Net Change: %s\n", formatBytesWithSign(g.resources.Disk.NetChangeBytes)))
-		sb.WriteString("│                                                                     │\n")
+		box.Line("Disk:")
+		box.Linef("  Files Written: %s", formatBytes(g.resources.Disk.FilesWrittenBytes))
+		box.Linef("  Files Deleted: %s", formatBytes(g.resources.Disk.FilesDeletedBytes))
+		box.Linef("  Net Change: %s", formatBytesWithSign(g.resources.Disk.NetChangeBytes))
+		box.Blank()
 
 		// Time
-		sb.WriteString("│ Time:                                                               │\n")
-		sb.WriteString(fmt.Sprintf("│   Total Duration: %s\n", formatDuration(g.resources.Time.TotalDuration)))
-		
+		box.Line("Time:")
+		box.Linef("  Total Duration: %s", formatDuration(g.resources.Time.TotalDuration))
+
 		totalMs := g.resources.Time.TotalDuration.Milliseconds()
 		if totalMs > 0 {
 			agentPercent := g.pct(g.resources.Time.AgentActive.Milliseconds(), totalMs)
 			humanPercent := g.pct(g.resources.Time.HumanWait.Milliseconds(), totalMs)
 			orchPercent := g.pct(g.resources.Time.Orchestrator.Milliseconds(), totalMs)
-			
-			sb.WriteString(fmt.Sprintf("│   Agent Active: %s (%.1f%%)\n", formatDuration(g.resources.Time.AgentActive), agentPercent))
-			sb.WriteString(fmt.Sprintf("│   Human Wait: %s (%.1f%%)\n", formatDuration(g.resources.Time.HumanWait), humanPercent))
-			sb.WriteString(fmt.Sprintf("│   Orchestrator: %s (%.1f%%)\n", formatDuration(g.resources.Time.Orchestrator), orchPercent))
+
+			box.Linef("  Agent Active: %s (%.1f%%)", formatDuration(g.resources.Time.AgentActive), agentPercent)
+			box.Linef("  Human Wait: %s (%.1f%%)", formatDuration(g.resources.Time.HumanWait), humanPercent)
+			box.Linef("  Orchestrator: %s (%.1f%%)", formatDuration(g.resources.Time.Orchestrator), orchPercent)
 		}
 	}
-	sb.WriteString("│                                                                     │\n")
-
-	return sb.String()
+	box.Blank()
 }
 
-// generateTokenSummary generates the token summary
-func (g *Generator) generateTokenSummary() string {
-	var sb strings.Builder
-
+// writeTokenSummary writes the token summary
+func (g *Generator) writeTokenSummary(box *layout.Box) {
 	totalTokens := int64(0)
 	if g.stats != nil {
 		totalTokens = g.stats.TotalTokens
 	}
 
-	sb.WriteString(fmt.Sprintf("│ Tokens • %s total                                              │\n", formatNumber(totalTokens)))
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString(fmt.Sprintf("│   Total Tokens: %s\n", formatNumber(totalTokens)))
+	box.Linef("Tokens • %s total", formatNumber(totalTokens))
+	box.Blank()
+	box.Linef("  Total Tokens: %s", formatNumber(totalTokens))
 
 	// Would need actual token breakdown by type
-	sb.WriteString(fmt.Sprintf("│   Inference Tokens: %s (70.0%%)\n", formatNumber(int64(float64(totalTokens)*0.70))))
-	sb.WriteString(fmt.Sprintf("│   Input Tokens: %s (25.0%%)\n", formatNumber(int64(float64(totalTokens)*0.25))))
-	sb.WriteString(fmt.Sprintf("│   Output Tokens: %s (45.0%%)\n", formatNumber(int64(float64(totalTokens)*0.45))))
-	sb.WriteString(fmt.Sprintf("│   Context Retrieval: %s (30.0%%)\n", formatNumber(int64(float64(totalTokens)*0.30))))
-	sb.WriteString("│                                                                     │\n")
+	box.Linef("  Inference Tokens: %s (70.0%%)", formatNumber(int64(float64(totalTokens)*0.70)))
+	box.Linef("  Input Tokens: %s (25.0%%)", formatNumber(int64(float64(totalTokens)*0.25)))
+	box.Linef("  Output Tokens: %s (45.0%%)", formatNumber(int64(float64(totalTokens)*0.45)))
+	box.Linef("  Context Retrieval: %s (30.0%%)", formatNumber(int64(float64(totalTokens)*0.30)))
+	box.Blank()
 
 	// By schedule
 	if g.resources != nil && len(g.resources.Tokens.BySchedule) > 0 {
-		sb.WriteString("│   By Schedule:\n")
-		for sid := orchestrate.ScheduleKnowledge; sid <= orchestrate.ScheduleProduction; sid++ {
+		box.Line("  By Schedule:")
+		for _, sid := range orchestrate.AllSchedules() {
 			tokens := g.resources.Tokens.BySchedule[sid]
 			percent := g.pct(tokens, totalTokens)
 			name := orchestrate.ScheduleNames[sid]
-			sb.WriteString(fmt.Sprintf("│     %s: %s (%.1f%%)\n", name, formatNumber(tokens), percent))
+			box.Linef("    %s: %s (%.1f%%)", name, formatNumber(tokens), percent)
 		}
 	}
-	sb.WriteString("│                                                                     │\n")
-
-	return sb.String()
+	box.Blank()
 }
 
-// generateGenerationFlow generates the generation flow breakdown
-func (g *Generator) generateGenerationFlow() string {
-	var sb strings.Builder
-
-	sb.WriteString("│ Generation Flow • Process-by-Process Token Recount                  │\n")
-	sb.WriteString("│                                                                     │\n")
-	sb.WriteString(fmt.Sprintf("│ %s\n", ui.FormatFlowCode(g.flowCode)))
-	sb.WriteString("│                                                                     │\n")
+// writeGenerationFlow writes the generation flow breakdown
+func (g *Generator) writeGenerationFlow(box *layout.Box) {
+	box.Line("Generation Flow • Process-by-Process Token Recount")
+	box.Blank()
+	box.Clipped(ui.FormatFlowCode(g.flowCode))
+	box.Blank()
 
 	// Show token breakdown by process with recalculation if necessary
 	currentSchedule := orchestrate.ScheduleID(0)
@@ -380,7 +455,7 @@ func (g *Generator) generateGenerationFlow() string {
 	for _, entry := range g.processTokens {
 		if entry.Schedule != currentSchedule {
 			currentSchedule = entry.Schedule
-			sb.WriteString(fmt.Sprintf("│ S%d (%s):\n", currentSchedule, orchestrate.ScheduleNames[currentSchedule]))
+			box.Linef("S%d (%s):", currentSchedule, orchestrate.ScheduleNames[currentSchedule])
 		}
 
 		cumulative += entry.Tokens
@@ -390,32 +465,50 @@ func (g *Generator) generateGenerationFlow() string {
 		}
 
 		processName := orchestrate.ProcessNames[entry.Schedule][entry.Process]
-		sb.WriteString(fmt.Sprintf("│   P%d %-10s +%s tokens    %s / %s (%.1f%%)\n",
+		box.Clipped(fmt.Sprintf("  P%d %s +%s tokens    %s / %s (%.1f%%)",
 			entry.Process,
-			processName,
+			layout.Pad(processName, 10),
 			formatNumber(entry.Tokens),
 			formatNumber(cumulative),
 			formatNumber(totalTokens),
 			percentage))
 	}
 
-	sb.WriteString("│                                                                     │\n")
-
-	return sb.String()
+	box.Blank()
 }
 
-// formatTLDR formats the TLDR section
-func (g *Generator) formatTLDR() string {
-	if g.tldr == "" {
-		return "│ (TLDR analysis pending)\n"
+// writeNotesSummary lists typed session notes grouped by type, in a section
+// of its own when there are any
+func (g *Generator) writeNotesSummary(box *layout.Box) {
+	groups := orchestrate.GroupNotesByType(g.notes)
+	started := false
+	for _, t := range orchestrate.NoteTypes {
+		notes := groups[t]
+		if len(notes) == 0 {
+			continue
+		}
+		if !started {
+			box.Divider()
+			box.Line("Notes")
+			started = true
+		}
+		box.Linef("%s (%d):", strings.ToUpper(string(t)), len(notes))
+		for _, n := range notes {
+			box.Line("  • " + n.Content)
+		}
+	}
+	if started {
+		box.Blank()
 	}
+}
 
-	var sb strings.Builder
-	lines := strings.Split(g.tldr, "\n")
-	for _, line := range lines {
-		sb.WriteString(fmt.Sprintf("│ %s\n", truncate(line, 68)))
+// writeTLDR writes the TLDR section
+func (g *Generator) writeTLDR(box *layout.Box) {
+	if g.tldr == "" {
+		box.Line("(TLDR analysis pending)")
+		return
 	}
-	return sb.String()
+	box.Line(strings.TrimRight(g.tldr, "\n"))
 }
 
 // Helper functions
@@ -439,6 +532,12 @@ func padRight(s string, length int) string {
 	return s + strings.Repeat(" ", length-len(s))
 }
 
+// formatCoverageDelta formats a change in coverage as
+// "61.2% → 68.0% (+6.8 points)".
+func formatCoverageDelta(before, after float64) string {
+	return fmt.Sprintf("%.1f%% → %.1f%% (%+.1f points)", before, after, after-before)
+}
+
 func pluralize(count int, singular, plural string) string {
 	if count == 1 {
 		return singular
@@ -462,11 +561,35 @@ func formatLineRanges(ranges []agent.LineRange) string {
 	return strings.Join(parts, ", ")
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// formatFileSize describes a changed file's lines before and after and its
+// final size.
+func formatFileSize(f agent.FileChange) string {
+	if f.Size < 0 {
+		return fmt.Sprintf("%d → 0 lines, deleted", f.LinesBefore)
 	}
-	return s[:maxLen-3] + "..."
+	return fmt.Sprintf("%d → %d lines, %s", f.LinesBefore, f.LinesAfter, formatBytes(f.Size))
+}
+
+// formatFileActions lists the actions applied to a file in the order first
+// applied, counting repeats, as in "create, edit ×2".
+func formatFileActions(actions []agent.ActionType) string {
+	var order []agent.ActionType
+	counts := make(map[agent.ActionType]int)
+	for _, a := range actions {
+		if counts[a] == 0 {
+			order = append(order, a)
+		}
+		counts[a]++
+	}
+	parts := make([]string, 0, len(order))
+	for _, a := range order {
+		part := strings.TrimSuffix(string(a), "_file")
+		if counts[a] > 1 {
+			part += fmt.Sprintf(" ×%d", counts[a])
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
 }
 
 func formatBytes(bytes int64) string {
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	orchsession "github.com/croberts/obot/internal/session"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -54,3 +56,83 @@ func TestGenerator_AddProcessTokens(t *testing.T) {
 		t.Error("Generate() output should contain token count 100")
 	}
 }
+
+func TestGenerator_NotesGroupedByType(t *testing.T) {
+	g := NewGenerator()
+	g.SetNotes([]orchestrate.Note{
+		{Content: "Use sqlite", Type: orchestrate.NoteDecision},
+		{Content: "Loop detected", Type: orchestrate.NoteGeneral},
+		{Content: "Migration may lock tables", Type: orchestrate.NoteRisk},
+		{Content: "Keep the v1 API", Type: orchestrate.NoteDecision},
+	})
+
+	out := g.Generate()
+	decisions := strings.Index(out, "DECISION (2):")
+	risks := strings.Index(out, "RISK (1):")
+	if decisions < 0 || risks < 0 || decisions > risks {
+		t.Fatalf("notes section missing or out of order:\n%s", out)
+	}
+	if strings.Contains(out, "Loop detected") {
+		t.Error("general notes should not be listed in the typed notes section")
+	}
+	if !strings.Contains(out, "• Keep the v1 API") {
+		t.Error("notes section missing note content")
+	}
+}
+
+func TestGenerator_BordersAlign(t *testing.T) {
+	g := NewGenerator()
+	g.SetFlowCode("S1P1P2P3S2P1")
+	g.SetNotes([]orchestrate.Note{
+		{Content: "日本語のメモ " + strings.Repeat("long note text ", 8), Type: orchestrate.NoteDecision},
+	})
+	g.SetTLDR("⚠ " + strings.Repeat("a very long TLDR line ", 6))
+
+	for _, line := range strings.Split(strings.TrimSuffix(g.Generate(), "\n"), "\n") {
+		if w := layout.Width(line); w != summaryWidth {
+			t.Errorf("line %q is %d columns wide, want %d", line, w, summaryWidth)
+		}
+	}
+}
+
+func TestGenerator_SetWidth(t *testing.T) {
+	g := NewGenerator()
+	g.SetWidth(100)
+	g.SetTLDR(strings.Repeat("a TLDR line ", 12))
+
+	for _, line := range strings.Split(strings.TrimSuffix(g.Generate(), "\n"), "\n") {
+		if w := layout.Width(line); w != 100 {
+			t.Errorf("line %q is %d columns wide, want 100", line, w)
+		}
+	}
+}
+
+func TestGenerator_Coverage(t *testing.T) {
+	g := NewGenerator()
+	g.SetCoverage([]orchsession.CoverageRecord{
+		{Step: "Scale P1", Tool: "go test", Before: 61.2, After: 64},
+		{Step: "Production P3", Tool: "go test", Before: 64, After: 68, Targets: []orchsession.CoverageTarget{
+			{Path: "internal/parse/parse.go", Function: "Parse", Before: 0, After: 85},
+		}},
+	})
+
+	out := g.Generate()
+	for _, want := range []string{
+		"Tests • Coverage 61.2% → 68.0% (+6.8 points)",
+		"Production P3: 64.0% → 68.0% (+4.0 points), 1 function targeted",
+		"internal/parse/parse.go Parse: 0.0% → 85.0%",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Generate() output missing %q:\n%s", want, out)
+		}
+	}
+	if c := g.Report().Coverage; c == nil || c.Before != 61.2 || c.After != 68 || len(c.Steps) != 2 {
+		t.Errorf("Report().Coverage = %+v", c)
+	}
+	if md := g.RenderMarkdown(); !strings.Contains(md, "Test generation: 61.2% → 68.0% (+6.8 points).") {
+		t.Errorf("RenderMarkdown() lacks the coverage delta:\n%s", md)
+	}
+	if html := g.RenderHTML(); !strings.Contains(html, "<code>internal/parse/parse.go</code> Parse") {
+		t.Error("RenderHTML() lacks the targeted function")
+	}
+}
@@ -0,0 +1,68 @@
+package summary
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/resource"
+	"github.com/croberts/obot/internal/test"
+	"github.com/croberts/obot/internal/ui/vterm"
+)
+
+// fixtureGenerator returns a generator filled with fixed results.
+func fixtureGenerator() *Generator {
+	g := NewGenerator()
+	g.SetFlowCode("S1P123S2P12")
+	g.SetStats(&orchestrate.OrchestratorStats{
+		TotalSchedulings: 2,
+		TotalProcesses:   5,
+		SchedulingsByID:  map[orchestrate.ScheduleID]int{orchestrate.ScheduleKnowledge: 1, orchestrate.SchedulePlan: 1},
+		ProcessesBySchedule: map[orchestrate.ScheduleID]map[orchestrate.ProcessID]int{
+			orchestrate.ScheduleKnowledge: {orchestrate.Process1: 1, orchestrate.Process2: 1, orchestrate.Process3: 1},
+			orchestrate.SchedulePlan:      {orchestrate.Process1: 1, orchestrate.Process2: 1},
+		},
+		TotalTokens: 4200,
+	})
+	g.SetActions(&agent.ActionStats{FilesCreated: 2, FilesEdited: 3, CommandsRan: 4}, nil)
+	g.SetFileChanges([]agent.FileChange{
+		{Path: "internal/api/handler.go", LinesBefore: 40, LinesAfter: 49, Added: 12, Removed: 3, Size: 1300,
+			Actions: []agent.ActionType{agent.ActionEditFile, agent.ActionEditFile}, StateIDs: []string{"0003-S3P1", "0004-S3P2"}},
+		{Path: "internal/api/handler_test.go", LinesAfter: 30, Added: 30, Size: 800,
+			Actions: []agent.ActionType{agent.ActionCreateFile}, StateIDs: []string{"0003-S3P1"}},
+		{Path: "old.go", LinesBefore: 8, Removed: 8, Size: -1,
+			Actions: []agent.ActionType{agent.ActionDeleteFile}, StateIDs: []string{"0004-S3P2"}},
+	})
+	g.SetResources(&resource.ResourceSummary{
+		Memory: resource.MemorySummary{PeakUsageGB: 6.5, AverageUsageGB: 4.25, PressureWarnings: 1},
+		Time:   resource.TimeSummary{TotalDuration: 90 * time.Second, AgentActive: 60 * time.Second},
+		Degradations: []resource.Degradation{{
+			Time:     time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+			Level:    resource.PressureWarning,
+			Resource: "memory",
+			Steps:    []string{"context window 8192 -> 4096 tokens"},
+		}},
+	})
+	g.AddProcessTokens(orchestrate.ScheduleKnowledge, orchestrate.Process1, 1200)
+	g.AddProcessTokens(orchestrate.SchedulePlan, orchestrate.Process2, 3000)
+	g.SetTLDR("Added the handler and its tests.")
+	return g
+}
+
+func TestGenerate_Golden(t *testing.T) {
+	g := fixtureGenerator()
+	screen := vterm.New(80, 200)
+	fmt.Fprintln(screen, g.Generate())
+	if screen.Styled() {
+		t.Error("summary leaves a style in effect")
+	}
+	if u := screen.Unhandled(); len(u) > 0 {
+		t.Errorf("summary writes unknown escape sequences %q", u)
+	}
+	if screen.Wraps() > 0 || screen.Scrolled() > 0 {
+		t.Errorf("summary wraps %d lines and scrolls %d", screen.Wraps(), screen.Scrolled())
+	}
+	test.AssertGolden(t, "summary", []byte(screen.String()+"\n"))
+}
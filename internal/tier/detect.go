@@ -160,6 +160,13 @@ func NewManager() *Manager {
 	}
 }
 
+// SetTier selects a tier and its model in place of the detected one, as
+// when benchmarks show the detected tier's model is too slow on this machine
+func (m *Manager) SetTier(t ModelTier) {
+	m.SelectedTier = t
+	m.SelectedModel = GetModelForTier(t)
+}
+
 // SetModelOverride sets a user-specified model override
 func (m *Manager) SetModelOverride(modelTag string) {
 	m.OverrideModel = modelTag
@@ -0,0 +1,150 @@
+// Package tokens counts prompt tokens so that context budgeting, cost
+// estimates, and summaries use the same numbers. Counts come from the
+// cl100k_base encoding embedded in the binary, or a BPE approximation when
+// that vocabulary cannot be loaded. Counting never touches the network.
+package tokens
+
+import (
+	"regexp"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+var (
+	encodingOnce sync.Once
+	encoding     *tiktoken.Tiktoken
+)
+
+// localEncoding returns the cl100k_base encoding, or nil if its vocabulary
+// cannot be loaded. Loading is attempted once per process. The vocabulary is
+// read from the embedded copy; tiktoken's default loader would download it.
+func localEncoding() *tiktoken.Tiktoken {
+	encodingOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+		if enc, err := tiktoken.GetEncoding("cl100k_base"); err == nil {
+			encoding = enc
+		}
+	})
+	return encoding
+}
+
+// Count returns the number of tokens in text using the local tokenizer.
+func Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	if enc := localEncoding(); enc != nil {
+		return len(enc.Encode(text, nil, nil))
+	}
+	return Approximate(text)
+}
+
+// Truncate shortens text to at most maxTokens tokens.
+func Truncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if enc := localEncoding(); enc != nil {
+		ids := enc.Encode(text, nil, nil)
+		if len(ids) <= maxTokens {
+			return text
+		}
+		return enc.Decode(ids[:maxTokens])
+	}
+
+	if Approximate(text) <= maxTokens {
+		return text
+	}
+	// Cut at the last piece boundary that fits the budget
+	used, end := 0, 0
+	for _, loc := range pieceRe.FindAllStringIndex(text, -1) {
+		n := pieceTokens(text[loc[0]:loc[1]])
+		if used+n > maxTokens {
+			break
+		}
+		used += n
+		end = loc[1]
+	}
+	return text[:end]
+}
+
+// pieceRe approximates the cl100k_base pre-tokenizer, which splits text into
+// contractions, words with an optional leading character, runs of up to three
+// digits, punctuation runs, and whitespace before the BPE merges are applied.
+var pieceRe = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// Approximate estimates the cl100k_base token count of text without a
+// vocabulary. Each pre-tokenizer piece is scored by how BPE typically merges
+// it: short words are one token, identifiers split at case changes and
+// roughly every six letters, and other scripts about one token per character.
+func Approximate(text string) int {
+	n := 0
+	for _, piece := range pieceRe.FindAllString(text, -1) {
+		n += pieceTokens(piece)
+	}
+	return n
+}
+
+// pieceTokens estimates the tokens of one pre-tokenizer piece.
+func pieceTokens(piece string) int {
+	r, _ := utf8.DecodeRuneInString(piece)
+	switch {
+	case unicode.IsSpace(r) && isSpace(piece):
+		// Runs of spaces and newlines merge into few tokens
+		return 1 + utf8.RuneCountInString(piece)/16
+	case unicode.IsDigit(r):
+		return 1
+	}
+
+	tokens, letters, marks := 0, 0, 0
+	var prev rune
+	flushLetters := func() {
+		tokens += (letters + 5) / 6
+		letters = 0
+	}
+	flushMarks := func() {
+		tokens += (marks + 1) / 2
+		marks = 0
+	}
+	for _, c := range piece {
+		switch {
+		case unicode.IsLetter(c) && c > unicode.MaxLatin1 && !unicode.In(c, unicode.Latin, unicode.Greek, unicode.Cyrillic):
+			// CJK and similar scripts are rarely merged
+			flushLetters()
+			flushMarks()
+			tokens++
+		case unicode.IsLetter(c):
+			flushMarks()
+			if unicode.IsUpper(c) && unicode.IsLower(prev) {
+				flushLetters()
+			}
+			letters++
+		case unicode.IsSpace(c):
+			// A leading space merges with the following word
+		default:
+			// Punctuation usually merges in pairs
+			flushLetters()
+			marks++
+		}
+		prev = c
+	}
+	flushLetters()
+	flushMarks()
+	if tokens == 0 {
+		tokens = 1
+	}
+	return tokens
+}
+
+func isSpace(s string) bool {
+	for _, c := range s {
+		if !unicode.IsSpace(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApproximate(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max int
+	}{
+		{"", 0, 0},
+		{"hello", 1, 1},
+		{"Hello, world! This is a simple sentence.", 9, 12},
+		{"func computeFilesHash(root string) (string, error) {", 10, 16},
+		{"1234567890", 4, 4},
+		{"日本語のテキスト", 6, 10},
+	}
+	for _, tt := range tests {
+		if got := Approximate(tt.text); got < tt.min || got > tt.max {
+			t.Errorf("Approximate(%q) = %d, want %d..%d", tt.text, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 50)
+	out := Truncate(text, 20)
+	if n := Count(out); n > 20 || n == 0 {
+		t.Errorf("Count(Truncate(text, 20)) = %d", n)
+	}
+	if !strings.HasPrefix(text, out) {
+		t.Errorf("Truncate() is not a prefix: %q", out)
+	}
+	if Truncate("short", 20) != "short" || Truncate("short", 0) != "" {
+		t.Error("Truncate() changed text within budget or kept text for zero budget")
+	}
+}
+
+func TestCount_UsesEmbeddedEncoding(t *testing.T) {
+	if localEncoding() == nil {
+		t.Fatal("localEncoding() = nil, want the embedded cl100k_base vocabulary")
+	}
+	// "hello world" is two cl100k_base tokens
+	if got := Count("hello world"); got != 2 {
+		t.Errorf("Count() = %d, want 2", got)
+	}
+}
@@ -126,6 +126,72 @@ func GitPush(ctx context.Context, workDir string, remote string, branch string)
 	return nil
 }
 
+// GitHead returns the commit hash of HEAD.
+func GitHead(ctx context.Context, workDir string) (string, error) {
+	head, err := gitExecWithContext(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(head), nil
+}
+
+// GitTopLevel returns the root directory of the repository containing workDir.
+func GitTopLevel(ctx context.Context, workDir string) (string, error) {
+	top, err := gitExecWithContext(ctx, workDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(top), nil
+}
+
+// GitChangedFiles returns the files that differ between two commits, relative
+// to the repository root.
+func GitChangedFiles(ctx context.Context, workDir, from, to string) ([]string, error) {
+	out, err := gitExecWithContext(ctx, workDir, "diff", "--name-only", "-z", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %w", err)
+	}
+	return splitNUL(out), nil
+}
+
+// GitDirtyFiles returns the modified, staged, deleted, and untracked files in
+// the working tree, relative to the repository root. Both sides of a rename
+// are included.
+func GitDirtyFiles(ctx context.Context, workDir string) ([]string, error) {
+	out, err := gitExecWithContext(ctx, workDir, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	var files []string
+	entries := splitNUL(out)
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		// A rename or copy is followed by its original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			if i+1 < len(entries) {
+				i++
+				files = append(files, entries[i])
+			}
+		}
+	}
+	return files, nil
+}
+
+// splitNUL splits NUL-terminated git output.
+func splitNUL(out string) []string {
+	var parts []string
+	for _, p := range strings.Split(out, "\x00") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
 // GitLog returns recent commit log.
 func GitLog(ctx context.Context, workDir string, count int) (string, error) {
 	if count <= 0 {
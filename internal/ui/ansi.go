@@ -4,61 +4,73 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
-// OllamaBot Tokyo Night Theme - True Color (24-bit) ANSI codes
-const (
+// Styles of the active theme, set by ApplyTheme and SetColors. They start
+// as TokyoBlueTheme's and are empty while colors are off.
+var (
 	// Reset
-	ANSIReset = "\033[0m"
-	Reset     = ANSIReset
+	ANSIReset string
+	Reset     string
 
-	// OllamaBot Tokyo Blue Theme - True Color
-	TokyoBlue     = "\033[38;2;125;207;255m" // #7dcfff - Primary accent
-	TokyoBlueBold = "\033[1;38;2;125;207;255m"
-	TokyoBlueDim  = "\033[2;38;2;125;207;255m"
+	// Accent (Tokyo Blue in the default theme)
+	TokyoBlue     string
+	TokyoBlueBold string
+	TokyoBlueDim  string
 
 	// Text colors
-	TextPrimary   = "\033[38;2;192;202;245m" // #c0caf5
-	TextSecondary = "\033[38;2;154;165;206m" // #9aa5ce
-	TextMuted     = "\033[38;2;86;95;137m"   // #565f89
-	TextBorder    = "\033[38;2;59;66;97m"    // #3b4261
+	TextPrimary   string
+	TextSecondary string
+	TextMuted     string
+	TextBorder    string
 
 	// White variants
-	ANSIWhite     = "\033[38;2;192;202;245m"
-	ANSIWhiteBold = "\033[1;38;2;192;202;245m"
-	ANSIWhiteDim  = "\033[2;38;2;192;202;245m"
+	ANSIWhite     string
+	ANSIWhiteBold string
+	ANSIWhiteDim  string
 
 	// Blue variants
-	ANSIBlue     = TokyoBlue
-	ANSIBlueBold = TokyoBlueBold
-
-	// Green variants
-	ANSIGreen = "\033[38;2;115;192;255m" // #73c0ff - Blue-tinted success
-
-	// Red variants
-	ANSIRed     = "\033[38;2;255;85;85m" // Bright red for deletions
-	ANSIRedBold = "\033[1;38;2;255;85;85m"
+	ANSIBlue     string
+	ANSIBlueBold string
 
 	// Status colors
-	ANSIYellow = "\033[38;2;224;175;104m" // #e0af68 - Warm yellow
-	ANSICyan   = TokyoBlue                // Use Tokyo Blue as cyan
+	ANSIGreen   string
+	ANSIRed     string
+	ANSIRedBold string
+	ANSIYellow  string
+	ANSICyan    string
 
 	// Model-specific colors
-	OrchestratorColor = "\033[38;2;122;162;247m" // #7aa2f7 - Royal blue
-	CoderColor        = "\033[38;2;125;207;255m" // #7dcfff - Cyan blue
-	ResearcherColor   = "\033[38;2;42;195;222m"  // #2ac3de - Teal blue
-	VisionColor       = "\033[38;2;90;143;212m"  // #5a8fd4 - Steel blue
+	OrchestratorColor string
+	CoderColor        string
+	ResearcherColor   string
+	VisionColor       string
 
 	// Flow code colors
-	FlowSchedule = ANSIWhiteBold
-	FlowProcess  = TokyoBlue
-	FlowError    = "\033[38;2;247;118;142m" // Red for X
+	FlowSchedule string
+	FlowProcess  string
+	FlowError    string
 
 	// Background colors
-	BgDark      = "\033[48;2;26;27;38m"  // #1a1b26
-	BgSecondary = "\033[48;2;36;40;59m"  // #24283b
-	BgSelection = "\033[48;2;51;70;124m" // #33467c
+	BgDark      string
+	BgSecondary string
+	BgSelection string
+
+	// Bold/Dim modifiers
+	ANSIBold string
+	Bold     string
+	ANSIDim  string
+	Dim      string
+)
 
+func init() {
+	setPalette(TokyoBlueTheme)
+}
+
+// Terminal control sequences
+const (
 	// Cursor control
 	CursorUp      = "\033[%dA"
 	CursorDown    = "\033[%dB"
@@ -74,12 +86,6 @@ const (
 	// Hide/show cursor
 	HideCursor = "\033[?25l"
 	ShowCursor = "\033[?25h"
-
-	// Bold/Dim modifiers
-	ANSIBold = "\033[1m"
-	Bold     = ANSIBold
-	ANSIDim  = "\033[2m"
-	Dim      = ANSIDim
 )
 
 // Color wraps text with a color code and reset
@@ -287,52 +293,23 @@ func ProgressBar(current, max float64, width int, filled, empty rune) string {
 	return strings.Repeat(string(filled), filledCount) + strings.Repeat(string(empty), emptyCount)
 }
 
-// Box draws a box around content
+// Box draws a box around content, clipping lines wider than the box
 func Box(content []string, width int) string {
-	var sb strings.Builder
-	sb.WriteString("┌")
-	sb.WriteString(strings.Repeat("─", width-2))
-	sb.WriteString("┐\n")
+	box := layout.NewBox(width)
 	for _, line := range content {
-		sb.WriteString("│ ")
-		if len(line) > width-4 {
-			sb.WriteString(line[:width-4])
-		} else {
-			sb.WriteString(line)
-			sb.WriteString(strings.Repeat(" ", width-4-len(line)))
-		}
-		sb.WriteString(" │\n")
+		box.Clipped(line)
 	}
-	sb.WriteString("└")
-	sb.WriteString(strings.Repeat("─", width-2))
-	sb.WriteString("┘\n")
-	return sb.String()
+	return box.String()
 }
 
-// BoxWithTitle draws a box with a title
+// BoxWithTitle draws a box with a centered title, clipping lines wider than
+// the box
 func BoxWithTitle(title string, content []string, width int) string {
-	var sb strings.Builder
-	titlePadded := " " + title + " "
-	sideWidth := (width - 2 - len(titlePadded)) / 2
-	sb.WriteString("┌")
-	sb.WriteString(strings.Repeat("─", sideWidth))
-	sb.WriteString(titlePadded)
-	sb.WriteString(strings.Repeat("─", width-2-sideWidth-len(titlePadded)))
-	sb.WriteString("┐\n")
+	box := layout.NewBox(width).SetTitle(title, layout.AlignCenter)
 	for _, line := range content {
-		sb.WriteString("│ ")
-		if len(line) > width-4 {
-			sb.WriteString(line[:width-4])
-		} else {
-			sb.WriteString(line)
-			sb.WriteString(strings.Repeat(" ", width-4-len(line)))
-		}
-		sb.WriteString(" │\n")
+		box.Clipped(line)
 	}
-	sb.WriteString("└")
-	sb.WriteString(strings.Repeat("─", width-2))
-	sb.WriteString("┘\n")
-	return sb.String()
+	return box.String()
 }
 
 // HorizontalLine creates a horizontal line
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui/layout"
+	"github.com/croberts/obot/internal/ui/term"
 )
 
 // App is the main terminal UI application for obot orchestrate.
@@ -29,6 +31,7 @@ type App struct {
 	chatHandler  *ChatHandler
 
 	// State
+	width           int
 	version         string
 	prompt          string
 	isRunning       bool
@@ -53,7 +56,7 @@ const (
 type Config struct {
 	DotIntervalMS    int
 	MemoryUpdateMS   int
-	Width            int
+	Width            int // columns; 0 follows the terminal
 	Colors           bool
 	MemoryGraph      bool
 	Animations       bool
@@ -64,7 +67,7 @@ func DefaultConfig() *Config {
 	return &Config{
 		DotIntervalMS:  250,
 		MemoryUpdateMS: 100,
-		Width:          80,
+		Width:          0,
 		Colors:         true,
 		MemoryGraph:    true,
 		Animations:     true,
@@ -81,17 +84,22 @@ func NewApp(stdin io.Reader, stdout, stderr io.Writer, config *Config, version s
 		stdin:           stdin,
 		stdout:          stdout,
 		stderr:          stderr,
+		width:           config.Width,
 		version:         version,
 		noteDestination: NoteTargetOrchestrator,
 		stopCh:          make(chan struct{}),
 	}
 
+	if app.width <= 0 {
+		app.width = term.Width(stdout)
+	}
+
 	// Initialize components
-	app.display = NewStatusDisplay(stdout, config.Width, time.Duration(config.DotIntervalMS)*time.Millisecond)
-	app.memoryViz = NewMemoryVisualization(stdout, config.Width)
-	app.output = NewOutputArea(stdout, config.Width)
+	app.display = NewStatusDisplay(stdout, app.width, time.Duration(config.DotIntervalMS)*time.Millisecond)
+	app.memoryViz = NewMemoryVisualization(stdout, app.width)
+	app.output = NewOutputArea(stdout, app.width)
 	app.inputHandler = NewInputHandler(stdin, stdout)
-	app.chatHandler = NewChatHandler(stdout, config.Width)
+	app.chatHandler = NewChatHandler(stdout, app.width)
 
 	return app
 }
@@ -125,6 +133,10 @@ func (a *App) Run(ctx context.Context) error {
 	// Start animation loop
 	go a.display.RunAnimationLoop()
 
+	// Follow terminal resizes
+	stopResize := term.NotifyResize(a.stdout, a.resize)
+	defer stopResize()
+
 	// Start input listener (Merges item 314 TUI internal cmds)
 	go a.inputHandler.Listen(func(prompt string) {
 		a.mu.Lock()
@@ -160,34 +172,42 @@ func (a *App) drawHeader() {
 	fmt.Fprint(a.stdout, header)
 }
 
+// resize adapts the layout to a new terminal width.
+func (a *App) resize(width int) {
+	a.mu.Lock()
+	a.width = width
+	a.mu.Unlock()
+	a.display.SetWidth(width)
+	a.memoryViz.SetWidth(width)
+}
+
+// contentWidth returns the width separators and the input box span: the
+// terminal width less a margin column.
+func (a *App) contentWidth() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return max(a.width-2, 20)
+}
+
 // drawSeparator draws a horizontal separator
 func (a *App) drawSeparator() {
-	fmt.Fprintln(a.stdout, Separator(78))
+	fmt.Fprintln(a.stdout, Separator(a.contentWidth()))
 }
 
 // drawInputArea draws the input area
 func (a *App) drawInputArea() {
 	var sb strings.Builder
 
-	sb.WriteString("┌")
-	sb.WriteString(strings.Repeat("─", 76))
-	sb.WriteString("┐\n")
-
-	sb.WriteString("│ Type your prompt here...")
-	sb.WriteString(strings.Repeat(" ", 50))
-	sb.WriteString(" │\n")
-
-	sb.WriteString("└")
-	sb.WriteString(strings.Repeat("─", 76))
-	sb.WriteString("┘\n")
+	width := a.contentWidth()
+	input := layout.NewBox(width)
+	input.Line("Type your prompt here...")
+	sb.WriteString(input.String())
 
-	// Buttons
+	// Buttons, right-aligned under the input box
 	if a.isRunning {
-		sb.WriteString(strings.Repeat(" ", 58))
-		sb.WriteString(FormatLabel("[Stop]"))
+		sb.WriteString(layout.PadLeft(FormatLabel("[Stop]"), width-14))
 	} else {
-		sb.WriteString(strings.Repeat(" ", 58))
-		sb.WriteString(FormatLabel("[Send]"))
+		sb.WriteString(layout.PadLeft(FormatLabel("[Send]"), width-14))
 	}
 	sb.WriteString("\n\n")
 
@@ -9,9 +9,16 @@ import (
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
-// StatusDisplay manages the stationary 4-line status display.
+// refreshInterval is the shortest time between repaints, so a burst of
+// state changes costs one repaint rather than one each.
+const refreshInterval = 100 * time.Millisecond
+
+// StatusDisplay manages the stationary 4-line status display. It repaints
+// when its state changes, and on the animation interval only while
+// something animates.
 type StatusDisplay struct {
 	mu     sync.Mutex
 	writer io.Writer
@@ -21,18 +28,26 @@ type StatusDisplay struct {
 	scheduleName      string
 	processName       string
 	agentAction       string
+	agentSpinning     bool
+	quotaStatus       string
 
 	// Animation state
 	animationTick int
 	animating     map[string]bool
 
+	// Repainting
+	changed   chan struct{}
+	lastFrame string
+
 	// Configuration
-	width         int
-	dotInterval   time.Duration
-	stopAnimation chan struct{}
+	width           int
+	dotInterval     time.Duration
+	refreshInterval time.Duration
+	stopAnimation   chan struct{}
 }
 
-// NewStatusDisplay creates a new status display
+// NewStatusDisplay creates a new status display. Lines longer than width
+// columns are truncated; a width of 0 leaves them whole.
 func NewStatusDisplay(writer io.Writer, width int, dotInterval time.Duration) *StatusDisplay {
 	return &StatusDisplay{
 		writer:            writer,
@@ -43,16 +58,27 @@ func NewStatusDisplay(writer io.Writer, width int, dotInterval time.Duration) *S
 		processName:       "",
 		agentAction:       "",
 		animating:         make(map[string]bool),
+		changed:           make(chan struct{}, 1),
+		refreshInterval:   refreshInterval,
 		stopAnimation:     make(chan struct{}),
 	}
 }
 
+// notify tells the animation loop the state changed. Callers hold mu.
+func (d *StatusDisplay) notify() {
+	select {
+	case d.changed <- struct{}{}:
+	default: // a repaint is already requested
+	}
+}
+
 // SetOrchestratorState sets the orchestrator state
 func (d *StatusDisplay) SetOrchestratorState(state orchestrate.OrchestratorState) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.orchestratorState = string(state)
 	d.animating["orchestrator"] = false
+	d.notify()
 }
 
 // SetSchedule sets the current schedule
@@ -61,6 +87,7 @@ func (d *StatusDisplay) SetSchedule(name string) {
 	defer d.mu.Unlock()
 	d.scheduleName = name
 	d.animating["schedule"] = false
+	d.notify()
 }
 
 // SetProcess sets the current process
@@ -69,6 +96,7 @@ func (d *StatusDisplay) SetProcess(name string) {
 	defer d.mu.Unlock()
 	d.processName = name
 	d.animating["process"] = false
+	d.notify()
 }
 
 // SetAgentAction sets the current agent action
@@ -76,13 +104,44 @@ func (d *StatusDisplay) SetAgentAction(action string) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.agentAction = action
+	d.agentSpinning = false
+	d.animating["agent"] = false
+	d.notify()
+}
+
+// SetAgentProgress sets the current agent action and shows a spinner before
+// it until the next SetAgentAction, for long-running work such as commands.
+func (d *StatusDisplay) SetAgentProgress(action string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.agentAction = action
+	d.agentSpinning = true
 	d.animating["agent"] = false
+	d.notify()
+}
+
+// SetQuotaStatus sets the model quota summary shown after the orchestrator state.
+func (d *StatusDisplay) SetQuotaStatus(status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.quotaStatus = status
+	d.notify()
+}
+
+// SetWidth sets the width lines are truncated to, such as when the
+// terminal is resized.
+func (d *StatusDisplay) SetWidth(width int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.width = width
+	d.notify()
 }
 
 // StartAnimation starts the dot animation for a component
 func (d *StatusDisplay) StartAnimation(component string) {
 	d.mu.Lock()
 	d.animating[component] = true
+	d.notify()
 	d.mu.Unlock()
 }
 
@@ -97,6 +156,12 @@ func (d *StatusDisplay) getAnimatedDots() string {
 	return dots[d.animationTick%len(dots)]
 }
 
+// getSpinner returns the current spinner frame
+func (d *StatusDisplay) getSpinner() string {
+	frames := []string{"|", "/", "-", "\\"}
+	return frames[d.animationTick%len(frames)]
+}
+
 // Render renders the status display
 func (d *StatusDisplay) Render() string {
 	d.mu.Lock()
@@ -112,6 +177,9 @@ func (d *StatusDisplay) Render() string {
 	} else {
 		sb.WriteString(FormatValue(d.orchestratorState))
 	}
+	if d.quotaStatus != "" {
+		sb.WriteString("  " + FormatValueMuted("["+d.quotaStatus+"]"))
+	}
 	sb.WriteString("\n")
 
 	// Schedule line
@@ -139,59 +207,139 @@ func (d *StatusDisplay) Render() string {
 	sb.WriteString(FormatBullet())
 	if d.animating["agent"] || d.agentAction == "" {
 		sb.WriteString(d.getAnimatedDots())
+	} else if d.agentSpinning {
+		sb.WriteString(FormatValueMuted(d.getSpinner()) + " " + FormatValue(d.agentAction))
 	} else {
 		sb.WriteString(FormatValue(d.agentAction))
 	}
 
-	return sb.String()
+	return d.fit(sb.String())
+}
+
+// fit truncates each line to the display width, one column short so a full
+// line does not wrap and break the in-place redraw, which moves up a fixed
+// four lines.
+func (d *StatusDisplay) fit(s string) string {
+	if d.width <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = layout.Truncate(line, d.width-1)
+	}
+	return strings.Join(lines, "\n")
 }
 
-// Update updates the display in place
+// Update advances the animation and redraws the display in place
 func (d *StatusDisplay) Update() {
 	d.mu.Lock()
 	d.animationTick++
 	d.mu.Unlock()
+	d.repaint()
+}
+
+// repaint redraws the display in place unless the frame is unchanged.
+func (d *StatusDisplay) repaint() {
+	frame := d.Render()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if frame == d.lastFrame {
+		return
+	}
+	d.lastFrame = frame
 
 	// Move cursor up 4 lines, clear, and re-render
 	output := CursorSave + MoveCursorUp(4)
 	for i := 0; i < 4; i++ {
 		output += ClearLine + "\n"
 	}
-	output += MoveCursorUp(4) + d.Render() + CursorRestore
-
-	d.mu.Lock()
+	output += MoveCursorUp(4) + frame + CursorRestore
 	fmt.Fprint(d.writer, output)
-	d.mu.Unlock()
 }
 
 // Draw draws the initial display
 func (d *StatusDisplay) Draw() {
+	frame := d.Render()
 	d.mu.Lock()
-	fmt.Fprintln(d.writer, d.Render())
+	d.lastFrame = frame
+	fmt.Fprintln(d.writer, frame)
 	d.mu.Unlock()
 }
 
-// RunAnimationLoop runs the animation loop
+// animated reports whether anything on the display animates.
+func (d *StatusDisplay) animated() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.agentSpinning {
+		return true
+	}
+	for _, animating := range d.animating {
+		if animating {
+			return true
+		}
+	}
+	return false
+}
+
+// RunAnimationLoop repaints the display until StopAnimations: on each state
+// change, at most once per refresh interval, and on the animation interval
+// while something animates. Nothing is drawn while nothing changes.
 func (d *StatusDisplay) RunAnimationLoop() {
-	ticker := time.NewTicker(d.dotInterval)
-	defer ticker.Stop()
+	var (
+		lastPaint time.Time
+		throttle  *time.Timer
+		throttled <-chan time.Time
+		ticker    *time.Ticker
+		ticks     <-chan time.Time
+	)
+	defer func() {
+		if throttle != nil {
+			throttle.Stop()
+		}
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
 
+	// Tick only while something animates
+	syncTicker := func() {
+		switch animated := d.animated(); {
+		case animated && ticker == nil:
+			ticker = time.NewTicker(d.dotInterval)
+			ticks = ticker.C
+		case !animated && ticker != nil:
+			ticker.Stop()
+			ticker, ticks = nil, nil
+		}
+	}
+	paint := func() {
+		d.repaint()
+		lastPaint = time.Now()
+		syncTicker()
+	}
+
+	syncTicker()
 	for {
 		select {
-		case <-ticker.C:
-			hasAnimation := false
-			d.mu.Lock()
-			for _, animating := range d.animating {
-				if animating {
-					hasAnimation = true
-					break
-				}
+		case <-d.changed:
+			if throttled != nil {
+				continue // the pending repaint picks the change up
 			}
-			d.mu.Unlock()
-
-			if hasAnimation {
-				d.Update()
+			if wait := d.refreshInterval - time.Since(lastPaint); wait > 0 {
+				throttle = time.NewTimer(wait)
+				throttled = throttle.C
+				continue
 			}
+			paint()
+		case <-throttled:
+			throttle, throttled = nil, nil
+			paint()
+		case <-ticks:
+			d.mu.Lock()
+			d.animationTick++
+			d.mu.Unlock()
+			paint()
 		case <-d.stopAnimation:
 			return
 		}
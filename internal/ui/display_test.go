@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/ui/layout"
+)
+
+func TestStatusDisplay_TruncatesToWidth(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewStatusDisplay(&buf, 40, time.Second)
+	d.SetSchedule("Implement")
+	d.SetProcess("Implement")
+	d.SetAgentAction("Edited " + strings.Repeat("internal/very/long/path/", 4) + "file.go")
+
+	lines := strings.Split(d.Render(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Render() has %d lines, want 4", len(lines))
+	}
+	for _, line := range lines {
+		if w := layout.Width(line); w >= 40 {
+			t.Errorf("line %q is %d columns wide, want under 40", layout.StripANSI(line), w)
+		}
+	}
+
+	d.SetWidth(0)
+	if !strings.Contains(d.Render(), "file.go") {
+		t.Error("Render() with no width truncated the agent line")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a writer and a reader.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStatusDisplay_RepaintsOnlyOnChange(t *testing.T) {
+	var buf syncBuffer
+	d := NewStatusDisplay(&buf, 80, time.Hour)
+	d.refreshInterval = 20 * time.Millisecond
+	d.SetOrchestratorState("Running")
+	d.SetSchedule("Plan")
+	d.SetProcess("Plan")
+	d.SetAgentAction("Idle")
+	d.Draw()
+	drawn := buf.String()
+
+	go d.RunAnimationLoop()
+	defer d.StopAnimations()
+
+	// Nothing changed, so nothing is repainted
+	time.Sleep(60 * time.Millisecond)
+	if buf.String() != drawn {
+		t.Fatal("display repainted without a state change")
+	}
+
+	// A burst of changes ends with the last shown
+	for _, action := range []string{"Reading", "Writing", "Testing"} {
+		d.SetAgentAction(action)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "Testing") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	out := strings.TrimPrefix(buf.String(), drawn)
+	if !strings.Contains(out, "Testing") {
+		t.Fatal("display not repainted after a state change")
+	}
+}
@@ -0,0 +1,145 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Align positions a box title on the top border.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+)
+
+// Box draws rows of text inside a border of box-drawing characters. Rows
+// are wrapped (or clipped) to the inner width and padded, so the right
+// border lines up whatever ANSI styles or wide runes the rows contain.
+type Box struct {
+	width   int                   // outer width in columns, borders included
+	paint   func(a ...any) string // styles the border; nil leaves it plain
+	title   string
+	align   Align
+	rounded bool
+	rows    []boxRow
+}
+
+type boxRow struct {
+	text    string
+	divider bool
+}
+
+// NewBox creates a box width columns wide, borders included.
+func NewBox(width int) *Box {
+	return &Box{width: max(width, 5)}
+}
+
+// SetTitle sets the title shown on the top border.
+func (b *Box) SetTitle(title string, align Align) *Box {
+	b.title, b.align = title, align
+	return b
+}
+
+// SetBorderStyle sets the ANSI style the border is drawn in.
+func (b *Box) SetBorderStyle(style string) *Box {
+	if style == "" {
+		b.paint = nil
+		return b
+	}
+	b.paint = func(a ...any) string { return style + fmt.Sprint(a...) + reset }
+	return b
+}
+
+// SetBorderFunc sets the function the border is styled with, such as the
+// SprintFunc of a color.
+func (b *Box) SetBorderFunc(paint func(a ...any) string) *Box {
+	b.paint = paint
+	return b
+}
+
+// SetRounded draws the box with rounded corners.
+func (b *Box) SetRounded(rounded bool) *Box {
+	b.rounded = rounded
+	return b
+}
+
+// InnerWidth returns the columns available to a row's text.
+func (b *Box) InnerWidth() int {
+	return b.width - 4
+}
+
+// Line adds text, wrapped to the inner width. Each line of a multi-line
+// text becomes at least one row.
+func (b *Box) Line(text string) {
+	for _, line := range Wrap(text, b.InnerWidth()) {
+		b.rows = append(b.rows, boxRow{text: line})
+	}
+}
+
+// Linef adds formatted text, wrapped to the inner width.
+func (b *Box) Linef(format string, args ...any) {
+	b.Line(fmt.Sprintf(format, args...))
+}
+
+// Clipped adds a row truncated to the inner width instead of wrapped, for
+// text whose columns matter, such as diff lines.
+func (b *Box) Clipped(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		b.rows = append(b.rows, boxRow{text: Truncate(line, b.InnerWidth())})
+	}
+}
+
+// Blank adds an empty row.
+func (b *Box) Blank() {
+	b.rows = append(b.rows, boxRow{})
+}
+
+// Divider adds a horizontal rule across the box.
+func (b *Box) Divider() {
+	b.rows = append(b.rows, boxRow{divider: true})
+}
+
+// String renders the box, each row ending in a newline.
+func (b *Box) String() string {
+	topLeft, topRight, bottomLeft, bottomRight := "┌", "┐", "└", "┘"
+	if b.rounded {
+		topLeft, topRight, bottomLeft, bottomRight = "╭", "╮", "╰", "╯"
+	}
+	border := func(s string) string {
+		if b.paint == nil {
+			return s
+		}
+		return b.paint(s)
+	}
+	span := b.width - 2
+
+	var sb strings.Builder
+	sb.WriteString(border(topLeft+b.topRule(span)+topRight) + "\n")
+	for _, row := range b.rows {
+		if row.divider {
+			sb.WriteString(border("├"+strings.Repeat("─", span)+"┤") + "\n")
+			continue
+		}
+		text := row.text
+		if strings.Contains(text, "\x1b") {
+			text += reset
+		}
+		sb.WriteString(border("│") + " " + Pad(text, b.InnerWidth()) + " " + border("│") + "\n")
+	}
+	sb.WriteString(border(bottomLeft+strings.Repeat("─", span)+bottomRight) + "\n")
+	return sb.String()
+}
+
+// topRule returns the top border between the corners, with the title.
+func (b *Box) topRule(span int) string {
+	if b.title == "" {
+		return strings.Repeat("─", span)
+	}
+	title := " " + Truncate(StripANSI(b.title), span-4) + " "
+	rest := span - Width(title)
+	if b.align == AlignCenter {
+		return strings.Repeat("─", rest/2) + title + strings.Repeat("─", rest-rest/2)
+	}
+	return "─" + title + strings.Repeat("─", rest-1)
+}
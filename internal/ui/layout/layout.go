@@ -0,0 +1,354 @@
+// Package layout measures, wraps, pads, and boxes terminal text. Widths are
+// display columns: ANSI escape sequences take none, wide runes (CJK, most
+// emoji) take two, and combining marks take none.
+package layout
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+const reset = "\x1b[0m"
+
+// Width returns the display width of s in terminal columns.
+func Width(s string) int {
+	w := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w += RuneWidth(r)
+		i += size
+	}
+	return w
+}
+
+// RuneWidth returns the display width of r: 0 for control characters,
+// combining marks and zero-width formatting runes, 2 for wide and
+// fullwidth runes, and 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0xfe00 && r <= 0xfe0f: // variation selectors
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// Pad appends spaces to s until it is width columns wide. Wider strings are
+// returned unchanged.
+func Pad(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft prepends spaces to s until it is width columns wide.
+func PadLeft(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// Center pads s on both sides to width columns, the extra space on the right.
+func Center(s string, width int) string {
+	n := width - Width(s)
+	if n <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+}
+
+// Truncate shortens s to at most width columns, ending it with "..." when
+// it is cut. Escape sequences are kept, and a style left open by the cut is
+// reset.
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	ellipsis := "..."
+	if width < len(ellipsis) {
+		ellipsis = ellipsis[:max(width, 0)]
+	}
+	limit := width - len(ellipsis)
+
+	var sb strings.Builder
+	styled := false
+	w := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s, i); n > 0 {
+			seq := s[i : i+n]
+			sb.WriteString(seq)
+			if isSGR(seq) {
+				styled = !isReset(seq)
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if w+RuneWidth(r) > limit {
+			break
+		}
+		w += RuneWidth(r)
+		sb.WriteString(s[i : i+size])
+		i += size
+	}
+	sb.WriteString(ellipsis)
+	if styled {
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// Wrap word-wraps s into lines of at most width columns. Newlines in s
+// start new lines, words longer than a line are broken, and continuation
+// lines are indented under the text of an indented or bulleted line
+// ("- ", "• ", "1. "). An indent is dropped for a rune that does not fit
+// after it, so only a wide rune at width 1 can overflow a line. A style
+// open at a break is reset at the end of the line and resumed on the next.
+func Wrap(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		lines = append(lines, wrapLine(strings.TrimRight(line, " \r"), width)...)
+	}
+	return lines
+}
+
+// wrapLine wraps a single line.
+func wrapLine(line string, width int) []string {
+	if Width(line) <= width {
+		return []string{line}
+	}
+	text := strings.TrimLeft(line, " ")
+	lead := len(line) - len(text)
+	if lead >= width {
+		// Leading space wider than the line is dropped
+		lead = 0
+	}
+	indent := hangingIndent(text, lead)
+	if indent >= width/2 {
+		indent = 0
+	}
+
+	w := &wrapper{width: width, indent: strings.Repeat(" ", indent)}
+	w.cur.WriteString(strings.Repeat(" ", lead))
+	w.curW = lead
+	w.start = lead
+	for _, word := range strings.Split(text, " ") {
+		w.addWord(word)
+	}
+	w.flush()
+	return w.lines
+}
+
+// hangingIndent returns the column continuation lines of a line start at:
+// the line's own indent plus the width of its list marker, if any.
+func hangingIndent(text string, lead int) int {
+	plain := StripANSI(text)
+	for _, marker := range []string{"• ", "- ", "* "} {
+		if strings.HasPrefix(plain, marker) {
+			return lead + Width(marker)
+		}
+	}
+	digits := 0
+	for digits < len(plain) && plain[digits] >= '0' && plain[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && strings.HasPrefix(plain[digits:], ". ") {
+		return lead + digits + 2
+	}
+	return lead
+}
+
+// wrapper accumulates wrapped lines.
+type wrapper struct {
+	width  int
+	indent string
+	lines  []string
+	cur    strings.Builder
+	curW   int
+	start  int    // width of the line's indent, before any words
+	style  string // SGR sequences in effect
+}
+
+// addWord appends a word, breaking the line before it when it does not fit.
+func (w *wrapper) addWord(word string) {
+	ww := Width(word)
+	atStart := w.curW == w.start
+	sep := 1
+	if atStart {
+		sep = 0
+	}
+	if w.curW+sep+ww <= w.width {
+		if sep == 1 {
+			w.cur.WriteByte(' ')
+		}
+		w.write(word)
+		w.curW += sep + ww
+		return
+	}
+	if !atStart {
+		w.flush()
+		w.newLine()
+		if word == "" {
+			return
+		}
+		if w.curW+ww <= w.width {
+			w.write(word)
+			w.curW += ww
+			return
+		}
+	}
+	w.breakWord(word)
+}
+
+// breakWord writes a word too long for a line, rune by rune.
+func (w *wrapper) breakWord(word string) {
+	for i := 0; i < len(word); {
+		if n := escapeLen(word, i); n > 0 {
+			w.write(word[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word[i:])
+		rw := RuneWidth(r)
+		if w.curW+rw > w.width && w.curW > w.start {
+			w.flush()
+			w.newLine()
+		}
+		if w.curW+rw > w.width && w.start > 0 {
+			w.dropIndent()
+		}
+		w.cur.WriteString(word[i : i+size])
+		w.curW += rw
+		i += size
+	}
+}
+
+// dropIndent removes the indent of a line that holds nothing else, for a
+// rune that does not fit after it.
+func (w *wrapper) dropIndent() {
+	line := w.cur.String()[w.start:]
+	w.cur.Reset()
+	w.cur.WriteString(line)
+	w.curW -= w.start
+	w.start = 0
+}
+
+// write appends text, tracking the styles it opens and resets.
+func (w *wrapper) write(s string) {
+	w.cur.WriteString(s)
+	for i := 0; i < len(s); {
+		n := escapeLen(s, i)
+		if n == 0 {
+			i++
+			continue
+		}
+		seq := s[i : i+n]
+		switch {
+		case isReset(seq):
+			w.style = ""
+		case isSGR(seq):
+			w.style += seq
+		}
+		i += n
+	}
+}
+
+// flush ends the current line, resetting an open style.
+func (w *wrapper) flush() {
+	line := w.cur.String()
+	if w.style != "" {
+		line += reset
+	}
+	w.lines = append(w.lines, line)
+	w.cur.Reset()
+}
+
+// newLine starts a continuation line, resuming the open style.
+func (w *wrapper) newLine() {
+	w.cur.WriteString(w.indent + w.style)
+	w.curW = len(w.indent)
+	w.start = w.curW
+}
+
+// escapeLen returns the length of the ANSI escape sequence at s[i], or 0.
+// An incomplete sequence ends where it stops being valid, so that it never
+// swallows the text or the sequence after it; a lone ESC is one byte long.
+func escapeLen(s string, i int) int {
+	if s[i] != 0x1b {
+		return 0
+	}
+	if i+1 >= len(s) {
+		return 1
+	}
+	switch c := s[i+1]; {
+	case c == '[': // CSI: parameters and intermediates, then a final byte
+		for j := i + 2; j < len(s); j++ {
+			switch {
+			case s[j] >= 0x40 && s[j] <= 0x7e:
+				return j - i + 1
+			case s[j] < 0x20 || s[j] > 0x3f:
+				return j - i
+			}
+		}
+		return len(s) - i
+	case c == ']': // OSC: terminated by BEL or ST
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j - i + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j - i + 2
+			}
+		}
+		return len(s) - i
+	case c >= 0x20 && c <= 0x7e:
+		return 2
+	}
+	return 1
+}
+
+// isSGR reports whether an escape sequence sets graphic attributes.
+func isSGR(seq string) bool {
+	return strings.HasPrefix(seq, "\x1b[") && strings.HasSuffix(seq, "m")
+}
+
+// isReset reports whether an SGR sequence resets all attributes.
+func isReset(seq string) bool {
+	return seq == "\x1b[0m" || seq == "\x1b[m"
+}
@@ -0,0 +1,157 @@
+package layout
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"hello", 5},
+		{"\x1b[1;34mhello\x1b[0m", 5},
+		{"\x1b]8;;https://example.com\x07link\x1b]8;;\x07", 4},
+		{"日本語", 6},
+		{"🦙 obot", 7},
+		{"é", 1}, // e + combining acute accent
+		{"⚠️", 1},
+		{"│ • ─", 5},
+		{"ab\x1b[", 2},                  // truncated CSI
+		{"ab\x1b[\x1b[0m", 2},           // truncated CSI before a reset
+		{"ab\x1b\x1b[0m", 2},            // lone ESC before a reset
+		{"\x1b世", 2},                    // lone ESC before a wide rune
+		{"\x1b[12\x1b[31mab\x1b[0m", 2}, // CSI cut off by another
+	}
+	for _, tt := range tests {
+		if got := Width(tt.in); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+	if got := StripANSI("ab\x1b[\x1b[0mcd\x1b"); got != "abcd" {
+		t.Errorf("StripANSI() of incomplete sequences = %q", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"a long line of text", 10, "a long ..."},
+		{"\x1b[31mred text here\x1b[0m", 8, "\x1b[31mred t...\x1b[0m"},
+		{"日本語テキスト", 7, "日本..."},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if Width(got) > tt.width {
+			t.Errorf("Truncate(%q, %d) is %d columns wide", tt.in, tt.width, Width(got))
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  []string
+	}{
+		{"fits", "one two", 10, []string{"one two"}},
+		{"words", "one two three four", 9, []string{"one two", "three", "four"}},
+		{"newlines", "one\n\ntwo", 10, []string{"one", "", "two"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"bullet", "• alpha beta gamma", 12, []string{"• alpha beta", "  gamma"}},
+		{"numbered", "  10. alpha beta gamma", 16, []string{"  10. alpha beta", "      gamma"}},
+		{"style carried", "\x1b[31mred words wrap\x1b[0m", 9, []string{"\x1b[31mred words\x1b[0m", "\x1b[31mwrap\x1b[0m"}},
+		{"wide runes", "日本 語テキ", 6, []string{"日本", "語テキ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.in, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+			}
+			for _, line := range got {
+				if Width(line) > tt.width {
+					t.Errorf("line %q is %d columns wide", line, Width(line))
+				}
+			}
+		})
+	}
+}
+
+func TestWrap_NeverExceedsWidth(t *testing.T) {
+	pieces := []string{" ", " ", "\n", "a", "bb", "word", "é", "e\u0301", "世界", "🙂", "- ", "• ", "1. ",
+		"\x1b[31m", "\x1b[0m", "\x1b[", "\x1b", "\x1b[1;", "\x1b]8;;x\x07"}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for n := 0; n < 20000; n++ {
+		var sb strings.Builder
+		for range rng.IntN(24) {
+			sb.WriteString(pieces[rng.IntN(len(pieces))])
+		}
+		in, width := sb.String(), 2+rng.IntN(10)
+		for _, line := range Wrap(in, width) {
+			if Width(line) > width {
+				t.Fatalf("Wrap(%q, %d) has line %q of width %d", in, width, line, Width(line))
+			}
+		}
+	}
+
+	in := " \n 🙂🙂- é\x1b[31m \x1b[31m\x1b[0mé 世界• bb \n\n"
+	for _, line := range Wrap(in, 2) {
+		if Width(line) > 2 {
+			t.Errorf("Wrap(%q, 2) has line %q of width %d", in, line, Width(line))
+		}
+	}
+}
+
+func TestPadAndCenter(t *testing.T) {
+	if got := Pad("\x1b[1mab\x1b[0m", 4); got != "\x1b[1mab\x1b[0m  " {
+		t.Errorf("Pad() = %q", got)
+	}
+	if got := PadLeft("日", 4); got != "  日" {
+		t.Errorf("PadLeft() = %q", got)
+	}
+	if got := Center("ab", 5); got != " ab  " {
+		t.Errorf("Center() = %q", got)
+	}
+}
+
+func TestBox(t *testing.T) {
+	b := NewBox(20).SetTitle("Title", AlignLeft)
+	b.Line("\x1b[32mgreen\x1b[0m and 日本")
+	b.Divider()
+	b.Line("a line long enough to wrap around")
+	b.Clipped("a clipped line that is cut")
+	b.Blank()
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if lines[0] != "┌─ Title ──────────┐" {
+		t.Errorf("top = %q", lines[0])
+	}
+	for _, line := range lines {
+		if Width(line) != 20 {
+			t.Errorf("row %q is %d columns wide, want 20", line, Width(line))
+		}
+	}
+	if !strings.Contains(b.String(), "│ a clipped lin... │") {
+		t.Errorf("clipped row missing:\n%s", b.String())
+	}
+	if len(lines) != 9 {
+		t.Errorf("box has %d rows, want 9:\n%s", len(lines), b.String())
+	}
+
+	centered := NewBox(12).SetTitle("ab", AlignCenter).SetRounded(true).String()
+	if !strings.HasPrefix(centered, "╭─── ab ───╮\n") {
+		t.Errorf("centered box = %q", centered)
+	}
+}
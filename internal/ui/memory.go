@@ -9,9 +9,11 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 // MemoryVisualization displays real-time memory usage with prediction.
@@ -24,8 +26,6 @@ type MemoryVisualization struct {
 	writer io.Writer
 
 	// Current values
-	currentGB   float64
-	peakGB      float64
 	predictGB   float64
 	totalGB     float64
 
@@ -33,9 +33,16 @@ type MemoryVisualization struct {
 	predictLabel string
 	predictBasis string
 
-	// History
-	history    []float64
-	maxSamples int
+	// GPU memory, shown when its total is known
+	vramGB        float64
+	vramTotalGB   float64
+	vramPredictGB float64
+
+	// History, written by the sampling loop under samplesMu and read
+	// through the snapshot it publishes
+	samplesMu sync.Mutex
+	samples   sampleWindow
+	snap      atomic.Pointer[memorySnapshot]
 
 	// Prediction tracking
 	lastPrediction    float64
@@ -44,11 +51,17 @@ type MemoryVisualization struct {
 	// Process history for prediction
 	processHistory map[string][]float64
 
+	// Redrawing in place, at most once per refresh interval
+	lastFrame     string
+	lastDraw      time.Time
+	redrawPending bool
+
 	// Configuration
-	width      int
-	barWidth   int
-	filledChar rune
-	emptyChar  rune
+	width           int
+	barWidth        int
+	filledChar      rune
+	emptyChar       rune
+	refreshInterval time.Duration
 }
 
 // PredictionAccuracy tracks how well our predictions match reality.
@@ -61,18 +74,25 @@ type PredictionAccuracy struct {
 
 // NewMemoryVisualization creates a new memory visualization
 func NewMemoryVisualization(writer io.Writer, width int) *MemoryVisualization {
-	return &MemoryVisualization{
+	m := &MemoryVisualization{
 		writer:            writer,
 		width:             width,
 		barWidth:          40,
 		filledChar:        '█',
 		emptyChar:         '░',
 		totalGB:           8.0, // Default, will be updated with actual system RAM
-		maxSamples:        3000, // 5 minutes at 10 samples/sec (100ms)
-		history:           make([]float64, 0, 3000),
+		refreshInterval:   refreshInterval,
+		samples:           newSampleWindow(3000), // 5 minutes at 10 samples/sec (100ms)
 		predictionHistory: make([]PredictionAccuracy, 0, 10),
 		processHistory:    make(map[string][]float64),
 	}
+	m.snap.Store(m.samples.snapshot())
+	return m
+}
+
+// snapshot returns the latest published view of the samples.
+func (m *MemoryVisualization) snapshot() *memorySnapshot {
+	return m.snap.Load()
 }
 
 // monitorLoop samples memory every 100ms via runtime.ReadMemStats
@@ -104,22 +124,14 @@ func (m *MemoryVisualization) SetTotalMemory(gb float64) {
 
 // Update updates the memory values and adds a sample to history
 func (m *MemoryVisualization) Update(currentGB, peakGB float64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
 
-	m.currentGB = currentGB
-	if currentGB > m.peakGB {
-		m.peakGB = currentGB
-	}
-	if peakGB > m.peakGB {
-		m.peakGB = peakGB
-	}
-
-	// Add to history
-	m.history = append(m.history, currentGB)
-	if len(m.history) > m.maxSamples {
-		m.history = m.history[1:]
+	m.samples.add(currentGB)
+	if peakGB > m.samples.peak {
+		m.samples.peak = peakGB
 	}
+	m.snap.Store(m.samples.snapshot())
 }
 
 // SetPrediction sets the predicted memory for the next operation
@@ -131,35 +143,73 @@ func (m *MemoryVisualization) SetPrediction(predictGB float64, label, basis stri
 	m.predictBasis = basis
 }
 
+// SetVRAM sets the GPU memory in use and its total; a 0 total hides it.
+func (m *MemoryVisualization) SetVRAM(usedGB, totalGB float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vramGB = usedGB
+	m.vramTotalGB = totalGB
+}
+
+// PredictModelLoad predicts memory for loading model for the next
+// operation: vramGB of GPU memory in use afterwards, and spillGB of the
+// model that does not fit in VRAM and lands in system RAM.
+func (m *MemoryVisualization) PredictModelLoad(model string, vramGB, spillGB float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.vramPredictGB = vramGB
+	m.predictGB = m.snapshot().current + spillGB
+	m.predictLabel = model
+	if spillGB > 0 {
+		m.predictBasis = fmt.Sprintf("%.1fGB spills from VRAM", spillGB)
+	} else {
+		m.predictBasis = "fits in VRAM"
+	}
+}
+
 // Render renders the memory visualization
 func (m *MemoryVisualization) Render() string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	var sb strings.Builder
+	snap := m.snapshot()
 
 	sb.WriteString(FormatLabel("Memory"))
 	sb.WriteString("\n")
 
+	barWidth := m.fitBarWidth()
+
 	// Current usage
-	currentBar := m.FormatMemoryBar(m.currentGB, m.totalGB)
+	currentBar := m.FormatMemoryBar(snap.current, m.totalGB)
 	sb.WriteString(fmt.Sprintf("├─ Current: %s  %.1f GB / %.1f GB",
-		currentBar, m.currentGB, m.totalGB))
+		currentBar, snap.current, m.totalGB))
 
 	// Add sparkline if history exists
-	if len(m.history) > 1 {
+	if snap.n > 1 {
 		sb.WriteString("  ")
-		sb.WriteString(m.renderSparkline(10))
+		sb.WriteString(renderSparkline(snap.recent))
 	}
 	sb.WriteString("\n")
 
 	// Peak usage
-	peakBar := ProgressBar(m.peakGB, m.totalGB, m.barWidth, m.filledChar, m.emptyChar)
+	peakBar := ProgressBar(snap.peak, m.totalGB, barWidth, m.filledChar, m.emptyChar)
 	sb.WriteString(fmt.Sprintf("├─ Peak:    %s  %.1f GB\n",
-		peakBar, m.peakGB))
+		peakBar, snap.peak))
+
+	// GPU memory, with where the prediction takes it
+	if m.vramTotalGB > 0 {
+		vramBar := m.FormatMemoryBar(m.vramGB, m.vramTotalGB)
+		sb.WriteString(fmt.Sprintf("├─ VRAM:    %s  %.1f GB / %.1f GB", vramBar, m.vramGB, m.vramTotalGB))
+		if m.vramPredictGB > 0 {
+			sb.WriteString(fmt.Sprintf(" → %.1f GB", m.vramPredictGB))
+		}
+		sb.WriteString("\n")
+	}
 
 	// Prediction
-	predictBar := ProgressBar(m.predictGB, m.totalGB, m.barWidth, m.filledChar, m.emptyChar)
+	predictBar := ProgressBar(m.predictGB, m.totalGB, barWidth, m.filledChar, m.emptyChar)
 	predictLabel := "--"
 	if m.predictLabel != "" {
 		if m.predictBasis != "" {
@@ -173,24 +223,29 @@ func (m *MemoryVisualization) Render() string {
 	sb.WriteString(fmt.Sprintf("└─ Predict: %s  %s",
 		predictBar, predictLabel))
 
-	return sb.String()
+	return m.fit(sb.String())
+}
+
+// fit truncates each line to the visualization width, one column short so
+// a full line does not wrap and break the in-place redraw.
+func (m *MemoryVisualization) fit(s string) string {
+	if m.width <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = layout.Truncate(line, m.width-1)
+	}
+	return strings.Join(lines, "\n")
 }
 
-// renderSparkline renders a small sparkline of the memory history
-func (m *MemoryVisualization) renderSparkline(width int) string {
-	if len(m.history) < 2 {
+// renderSparkline renders a small sparkline of the latest samples
+func renderSparkline(samples []float64) string {
+	if len(samples) < 2 {
 		return ""
 	}
 
 	sparks := []rune{' ', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
-	
-	// Get last 'width' samples
-	var samples []float64
-	if len(m.history) > width {
-		samples = m.history[len(m.history)-width:]
-	} else {
-		samples = m.history
-	}
 
 	// Find min/max in the visible window
 	min, max := samples[0], samples[0]
@@ -221,25 +276,63 @@ func (m *MemoryVisualization) renderSparkline(width int) string {
 
 // Draw draws the memory visualization
 func (m *MemoryVisualization) Draw() {
+	output := m.Render()
 	m.mu.Lock()
-	fmt.Fprintln(m.writer, m.Render())
+	m.lastFrame, m.lastDraw = output, time.Now()
+	fmt.Fprintln(m.writer, output)
 	m.mu.Unlock()
 }
 
-// UpdateInPlace updates the visualization in place
+// UpdateInPlace redraws the visualization in place if it changed. Redraws
+// happen at most once per refresh interval; a change within the interval
+// is drawn when it ends.
 func (m *MemoryVisualization) UpdateInPlace() {
 	m.mu.Lock()
-	output := MoveCursorUp(4) + m.Render() + "\n"
-	fmt.Fprint(m.writer, output)
+	if m.redrawPending {
+		m.mu.Unlock()
+		return
+	}
+	if wait := m.refreshInterval - time.Since(m.lastDraw); wait > 0 {
+		m.redrawPending = true
+		m.mu.Unlock()
+		time.AfterFunc(wait, func() {
+			m.mu.Lock()
+			m.redrawPending = false
+			m.mu.Unlock()
+			m.redraw()
+		})
+		return
+	}
 	m.mu.Unlock()
+	m.redraw()
+}
+
+// redraw writes the visualization over the last frame unless unchanged.
+func (m *MemoryVisualization) redraw() {
+	// Render locks, and has a line more when VRAM is shown
+	output := m.Render()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if output == m.lastFrame {
+		return
+	}
+	drawn := m.lastFrame
+	if drawn == "" {
+		drawn = output
+	}
+	m.lastFrame, m.lastDraw = output, time.Now()
+	fmt.Fprint(m.writer, MoveCursorUp(strings.Count(drawn, "\n")+1)+output+"\n")
 }
 
 // GetPressureStatus returns the memory pressure status
 func (m *MemoryVisualization) GetPressureStatus() string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.pressureStatusLocked()
+}
 
-	ratio := m.currentGB / m.totalGB
+func (m *MemoryVisualization) pressureStatusLocked() string {
+	ratio := m.snapshot().current / m.totalGB
 	if ratio >= 0.95 {
 		return FormatError("CRITICAL")
 	}
@@ -265,51 +358,42 @@ func (m *MemoryVisualization) FormatMemoryBar(current, total float64) string {
 
 // renderBar renders an ASCII progress bar using the visualization's settings
 func (m *MemoryVisualization) renderBar(current, total float64) string {
-	return ProgressBar(current, total, m.barWidth, m.filledChar, m.emptyChar)
+	return ProgressBar(current, total, m.fitBarWidth(), m.filledChar, m.emptyChar)
 }
 
-// GetHistoryStats returns statistics about the memory history
-func (m *MemoryVisualization) GetHistoryStats() (min, max, avg float64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// memoryLineWidth is the width of a memory line besides its bar: the label,
+// the sizes, and the sparkline.
+const memoryLineWidth = 46
 
-	if len(m.history) == 0 {
-		return 0, 0, 0
-	}
-
-	min = m.history[0]
-	max = m.history[0]
-	sum := 0.0
-
-	for _, v := range m.history {
-		if v < min {
-			min = v
-		}
-		if v > max {
-			max = v
-		}
-		sum += v
+// fitBarWidth returns the bar width, narrowed so the memory lines fit the
+// visualization width.
+func (m *MemoryVisualization) fitBarWidth() int {
+	if m.width <= 0 {
+		return m.barWidth
 	}
+	return max(min(m.barWidth, m.width-memoryLineWidth), 10)
+}
 
-	avg = sum / float64(len(m.history))
-	return
+// GetHistoryStats returns statistics about the memory history
+func (m *MemoryVisualization) GetHistoryStats() (min, max, avg float64) {
+	snap := m.snapshot()
+	return snap.min, snap.max, snap.avg
 }
 
 // ClearHistory clears the memory history
 func (m *MemoryVisualization) ClearHistory() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.history = m.history[:0]
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+	m.samples.reset(nil)
+	m.snap.Store(m.samples.snapshot())
 }
 
 // SetMaxSamples sets the maximum number of history samples
 func (m *MemoryVisualization) SetMaxSamples(n int) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.maxSamples = n
-	if len(m.history) > n {
-		m.history = m.history[len(m.history)-n:]
-	}
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+	m.samples.resize(n)
+	m.snap.Store(m.samples.snapshot())
 }
 
 // GetPrediction returns the current prediction values
@@ -322,21 +406,12 @@ func (m *MemoryVisualization) GetPrediction() (gb float64, label, basis string)
 // GetTrendGBps calculates the current memory growth trend in GB per second.
 // This is a simple linear regression over the last 10 samples.
 func (m *MemoryVisualization) GetTrendGBps() float64 {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	n := len(m.history)
-	if n < 5 {
+	samples := m.snapshot().recent
+	window := len(samples)
+	if window < 5 {
 		return 0
 	}
 
-	window := 10
-	if n < window {
-		window = n
-	}
-
-	samples := m.history[n-window:]
-	
 	// Simple slope calculation: (last - first) / time
 	// Assuming 10 samples per second (100ms interval).
 	first := samples[0]
@@ -350,34 +425,32 @@ func (m *MemoryVisualization) PredictOperation(requiredGB float64, operation str
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	m.predictGB = m.currentGB + requiredGB
+	current := m.snapshot().current
+	m.predictGB = current + requiredGB
 	m.predictLabel = operation
-	m.predictBasis = fmt.Sprintf("base: %.1fGB + req: %.1fGB", m.currentGB, requiredGB)
+	m.predictBasis = fmt.Sprintf("base: %.1fGB + req: %.1fGB", current, requiredGB)
 }
 
 // RenderDetailedHistory returns a larger, multi-line visualization of the memory history.
 func (m *MemoryVisualization) RenderDetailedHistory(height int) string {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if len(m.history) < 2 {
+	snap := m.snapshot()
+	if snap.n < 2 {
 		return "Insufficient history for detailed graph."
 	}
 
+	m.mu.Lock()
 	width := m.width - 10
+	m.mu.Unlock()
 	if width < 20 {
 		width = 20
 	}
 
 	// Get samples mapped to width
-	var displaySamples []float64
-	if len(m.history) > width {
-		displaySamples = m.history[len(m.history)-width:]
-	} else {
-		displaySamples = m.history
-	}
+	m.samplesMu.Lock()
+	displaySamples := m.samples.tail(width)
+	m.samplesMu.Unlock()
 
-	min, max, _ := m.getHistoryStatsLocked()
+	min, max := snap.min, snap.max
 	if max == min {
 		max = min + 1.0
 	}
@@ -418,29 +491,6 @@ func formatBytes(bytes int64) string {
 	}
 	return fmt.Sprintf("%.1f GB", float64(bytes)/(1024*1024*1024))
 }
-func (m *MemoryVisualization) getHistoryStatsLocked() (min, max, avg float64) {
-	if len(m.history) == 0 {
-		return 0, 0, 0
-	}
-
-	min = m.history[0]
-	max = m.history[0]
-	sum := 0.0
-
-	for _, v := range m.history {
-		if v < min {
-			min = v
-		}
-		if v > max {
-			max = v
-		}
-		sum += v
-	}
-
-	avg = sum / float64(len(m.history))
-	return
-}
-
 // GetFormattedStats returns a human-readable summary of memory statistics.
 func (m *MemoryVisualization) GetFormattedStats() string {
 	min, max, avg := m.GetHistoryStats()
@@ -456,7 +506,7 @@ func (m *MemoryVisualization) GetFormattedStats() string {
 
 	return fmt.Sprintf(
 		"Pressure: %s | Peak: %.1f GB | Range: [%.1f - %.1f] GB | Avg: %.1f GB | Trend: %s",
-		pressure, m.peakGB, min, max, avg, trendStr,
+		pressure, m.GetPeakGB(), min, max, avg, trendStr,
 	)
 }
 
@@ -470,7 +520,7 @@ func (m *MemoryVisualization) AutoPredict() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	prediction := m.currentGB + (trend * 30)
+	prediction := m.snapshot().current + (trend * 30)
 	if prediction < 0 {
 		prediction = 0
 	}
@@ -531,11 +581,16 @@ func (m *MemoryVisualization) RecordProcessUsage(schedID orchestrate.ScheduleID,
 
 // RenderSummaryBox returns a boxed visualization of current memory status and history.
 func (m *MemoryVisualization) RenderSummaryBox() string {
+	snap := m.snapshot()
+	stats := m.GetFormattedStats()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	lines := []string{
-		fmt.Sprintf("Current: %.1f / %.1f GB (%s)", m.currentGB, m.totalGB, m.GetPressureStatus()),
-		fmt.Sprintf("Peak:    %.1f GB", m.peakGB),
+		fmt.Sprintf("Current: %.1f / %.1f GB (%s)", snap.current, m.totalGB, m.pressureStatusLocked()),
+		fmt.Sprintf("Peak:    %.1f GB", snap.peak),
 		"",
-		m.GetFormattedStats(),
+		stats,
 		"",
 		"Prediction:",
 		fmt.Sprintf("  Value: %.1f GB", m.predictGB),
@@ -569,16 +624,12 @@ func (m *MemoryVisualization) SetBarWidth(width int) {
 
 // GetCurrentGB returns the last recorded current memory usage.
 func (m *MemoryVisualization) GetCurrentGB() float64 {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	return m.currentGB
+	return m.snapshot().current
 }
 
 // GetPeakGB returns the peak memory usage recorded.
 func (m *MemoryVisualization) GetPeakGB() float64 {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	return m.peakGB
+	return m.snapshot().peak
 }
 
 // RecordPredictionAccuracy records how accurate the last prediction was compared to current usage.
@@ -590,10 +641,11 @@ func (m *MemoryVisualization) RecordPredictionAccuracy() {
 		return
 	}
 
+	current := m.snapshot().current
 	accuracy := PredictionAccuracy{
 		Predicted: m.predictGB,
-		Actual:    m.currentGB,
-		Diff:      m.currentGB - m.predictGB,
+		Actual:    current,
+		Diff:      current - m.predictGB,
 		Label:     m.predictLabel,
 	}
 
@@ -628,104 +680,97 @@ func (m *MemoryVisualization) GetPredictionAccuracyStats() (avgDiff, maxDiff flo
 	return
 }
 
-// GetPercentile calculates the p-th percentile of memory usage in history.
-// p should be between 0 and 100.
-func (m *MemoryVisualization) GetPercentile(p float64) float64 {
+// SafetyMarginGB returns the memory to reserve beyond a prediction: the
+// worst recent underprediction, so that a repeat of it is still covered.
+func (m *MemoryVisualization) SafetyMarginGB() float64 {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	n := len(m.history)
-	if n == 0 {
-		return 0
+	margin := 0.0
+	for _, acc := range m.predictionHistory {
+		if acc.Diff > margin {
+			margin = acc.Diff
+		}
 	}
+	return margin
+}
 
-	// Sort history copy
-	sorted := make([]float64, n)
-	copy(sorted, m.history)
-	
-	// Quick sort
-	m.sortSamples(sorted)
-
-	index := (p / 100.0) * float64(n-1)
-	i := int(index)
-	fraction := index - float64(i)
+// GetPredictionAccuracy returns how close recent predictions came to the
+// actual usage, from 0 to 1, or 0 when none was recorded.
+func (m *MemoryVisualization) GetPredictionAccuracy() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	if i >= n-1 {
-		return sorted[n-1]
+	if len(m.predictionHistory) == 0 {
+		return 0
 	}
-
-	return sorted[i] + fraction*(sorted[i+1]-sorted[i])
+	sum := 0.0
+	for _, acc := range m.predictionHistory {
+		scale := math.Max(acc.Predicted, acc.Actual)
+		if scale > 0 {
+			sum += 1 - math.Abs(acc.Diff)/scale
+		} else {
+			sum++
+		}
+	}
+	return sum / float64(len(m.predictionHistory))
 }
 
-// sortSamples implements a simple bubble sort for the percentile calculation.
-// (In a high-perf scenario, we'd use sort.Float64s, but this is for LOC and demonstration).
-func (m *MemoryVisualization) sortSamples(s []float64) {
-	n := len(s)
-	for i := 0; i < n; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if s[j] > s[j+1] {
-				s[j], s[j+1] = s[j+1], s[j]
-			}
+// GetPercentile calculates the p-th percentile of memory usage in history.
+// p should be between 0 and 100. The sorted samples are kept until the next
+// sample, and sorting happens outside the lock the sampling loop takes.
+func (m *MemoryVisualization) GetPercentile(p float64) float64 {
+	m.samplesMu.Lock()
+	seq, sorted := m.samples.seq, m.samples.sorted
+	if sorted == nil || m.samples.sortedSeq != seq {
+		samples := m.samples.tail(m.samples.samples.Len())
+		m.samplesMu.Unlock()
+
+		sorted = sortedSamples(samples)
+
+		m.samplesMu.Lock()
+		if m.samples.seq == seq {
+			m.samples.sorted, m.samples.sortedSeq = sorted, seq
 		}
 	}
+	m.samplesMu.Unlock()
+
+	return percentile(sorted, p)
 }
 
 // GetVolatility calculates the standard deviation of memory usage.
 func (m *MemoryVisualization) GetVolatility() float64 {
-	_, _, avg := m.GetHistoryStats()
-	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	n := len(m.history)
-	if n < 2 {
-		return 0
-	}
-
-	sumSq := 0.0
-	for _, v := range m.history {
-		diff := v - avg
-		sumSq += diff * diff
-	}
-
-	return math.Sqrt(sumSq / float64(n-1))
+	return m.snapshot().stddev
 }
 
 // GetHistoryRange returns the time span covered by the history in seconds.
 func (m *MemoryVisualization) GetHistoryRange() int {
-	m.mu.Lock()
-	defer m.mu.Unlock()
 	// Assuming 10 samples per second (100ms interval)
-	return len(m.history) / 10
+	return m.snapshot().n / 10
 }
 
 // ResetPeak resets the peak memory usage to current usage.
 func (m *MemoryVisualization) ResetPeak() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.peakGB = m.currentGB
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+	m.samples.peak = m.samples.current
+	m.snap.Store(m.samples.snapshot())
 }
 
 // ExportHistory returns a copy of the memory history.
 func (m *MemoryVisualization) ExportHistory() []float64 {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	historyCopy := make([]float64, len(m.history))
-	copy(historyCopy, m.history)
-	return historyCopy
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+	return m.samples.tail(m.samples.samples.Len())
 }
 
 // ImportHistory replaces the current history with the provided one.
 func (m *MemoryVisualization) ImportHistory(history []float64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	m.history = make([]float64, len(history))
-	copy(m.history, history)
-	if len(m.history) > m.maxSamples {
-		m.history = m.history[len(m.history)-m.maxSamples:]
-	}
+	m.samplesMu.Lock()
+	defer m.samplesMu.Unlock()
+
+	m.samples.reset(history)
+	m.snap.Store(m.samples.snapshot())
 }
 
 // RenderPredictionInfo returns a formatted string about the current prediction.
@@ -745,11 +790,13 @@ func (m *MemoryVisualization) RenderPredictionInfo() string {
 
 // GetStatusSummary returns a single-line summary of the current memory status.
 func (m *MemoryVisualization) GetStatusSummary() string {
+	snap := m.snapshot()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	
-	return fmt.Sprintf("MEM: %.1f/%.1f GB (Peak: %.1f) | %s", 
-		m.currentGB, m.totalGB, m.peakGB, m.GetPressureStatus())
+
+	return fmt.Sprintf("MEM: %.1f/%.1f GB (Peak: %.1f) | %s",
+		snap.current, m.totalGB, snap.peak, m.pressureStatusLocked())
 }
 
 // Finalize ensures all metrics are captured and returns a final report.
@@ -762,7 +809,7 @@ func (m *MemoryVisualization) Finalize() string {
 	var sb strings.Builder
 	sb.WriteString(FormatLabelBold("Final Memory Report"))
 	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf("Peak Usage: %.2f GB\n", m.peakGB))
+	sb.WriteString(fmt.Sprintf("Peak Usage: %.2f GB\n", m.GetPeakGB()))
 	sb.WriteString(fmt.Sprintf("Range:      %.2f - %.2f GB (Avg: %.2f)\n", min, max, avg))
 	sb.WriteString(fmt.Sprintf("Prediction Accuracy (Avg Error): %.2f GB (Max: %.2f)\n", avgErr, maxErr))
 	sb.WriteString(fmt.Sprintf("Volatility: %.3f GB\n", m.GetVolatility()))
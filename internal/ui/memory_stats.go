@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"math"
+	"sort"
+
+	"github.com/croberts/obot/internal/ring"
+)
+
+// recentSamples is how many of the latest samples a snapshot carries, for
+// the sparkline and the trend.
+const recentSamples = 10
+
+// memorySnapshot is an immutable view of the memory samples. Update
+// publishes a new one after each sample, so that rendering and the stats
+// never wait on the sampling loop.
+type memorySnapshot struct {
+	seq     uint64 // samples recorded so far
+	n       int    // samples in the window
+	current float64
+	peak    float64
+	min     float64
+	max     float64
+	avg     float64
+	stddev  float64
+	recent  []float64 // the last recentSamples samples, oldest first
+}
+
+// seqSample is a sample and its position in the stream of samples.
+type seqSample struct {
+	seq uint64
+	v   float64
+}
+
+// sampleWindow holds the most recent samples along with their aggregates,
+// updated as samples enter and leave so that no stat rescans the window.
+type sampleWindow struct {
+	samples *ring.Buffer[float64]
+	seq     uint64
+	current float64
+	peak    float64
+	sum     float64
+	sumSq   float64
+
+	// Monotonic queues of the window's minimum and maximum candidates
+	minq *ring.Buffer[seqSample]
+	maxq *ring.Buffer[seqSample]
+
+	// Sorted copy of the window for percentiles, valid while sortedSeq is seq
+	sorted    []float64
+	sortedSeq uint64
+}
+
+// newSampleWindow returns a window of the latest maxSamples samples.
+func newSampleWindow(maxSamples int) sampleWindow {
+	return sampleWindow{
+		samples: ring.New[float64](maxSamples),
+		minq:    ring.New[seqSample](maxSamples),
+		maxq:    ring.New[seqSample](maxSamples),
+	}
+}
+
+// add appends a sample, evicting the oldest once the window is full.
+func (w *sampleWindow) add(v float64) {
+	if w.samples.Cap() == 0 {
+		return
+	}
+	w.seq++
+	w.current = v
+	if v > w.peak {
+		w.peak = v
+	}
+	if old, evicted := w.samples.Push(v); evicted {
+		w.sum -= old
+		w.sumSq -= old * old
+	}
+	w.sum += v
+	w.sumSq += v * v
+
+	oldest := w.seq - uint64(w.samples.Len()) + 1
+	pushMonotonic(w.minq, seqSample{w.seq, v}, oldest, func(back float64) bool { return back >= v })
+	pushMonotonic(w.maxq, seqSample{w.seq, v}, oldest, func(back float64) bool { return back <= v })
+}
+
+// pushMonotonic drops the candidates that left the window, from the front,
+// and those s supersedes, from the back, then pushes s.
+func pushMonotonic(q *ring.Buffer[seqSample], s seqSample, oldest uint64, superseded func(float64) bool) {
+	for front, ok := q.Front(); ok && front.seq < oldest; front, ok = q.Front() {
+		q.PopFront()
+	}
+	for back, ok := q.Back(); ok && superseded(back.v); back, ok = q.Back() {
+		q.PopBack()
+	}
+	q.Push(s)
+}
+
+// reset replaces the window's samples with samples, keeping the peak.
+func (w *sampleWindow) reset(samples []float64) {
+	w.samples.Reset()
+	w.minq.Reset()
+	w.maxq.Reset()
+	w.sum, w.sumSq = 0, 0
+	w.sorted = nil
+	for _, v := range samples {
+		w.add(v)
+	}
+}
+
+// resize changes how many samples the window holds, keeping the latest.
+func (w *sampleWindow) resize(maxSamples int) {
+	keep := w.tail(maxSamples)
+	w.samples.Resize(maxSamples)
+	w.minq.Resize(maxSamples)
+	w.maxq.Resize(maxSamples)
+	w.reset(keep)
+}
+
+// snapshot returns the window's current aggregates.
+func (w *sampleWindow) snapshot() *memorySnapshot {
+	s := &memorySnapshot{seq: w.seq, n: w.samples.Len(), current: w.current, peak: w.peak}
+	if s.n == 0 {
+		return s
+	}
+	minS, _ := w.minq.Front()
+	maxS, _ := w.maxq.Front()
+	s.min, s.max = minS.v, maxS.v
+	s.avg = w.sum / float64(s.n)
+	if s.n > 1 {
+		// Running sums can drift below zero for a flat window
+		variance := (w.sumSq - w.sum*w.sum/float64(s.n)) / float64(s.n-1)
+		s.stddev = math.Sqrt(max(variance, 0))
+	}
+	s.recent = w.tail(recentSamples)
+	return s
+}
+
+// tail returns a copy of the last n samples.
+func (w *sampleWindow) tail(n int) []float64 {
+	return w.samples.AppendLast(make([]float64, 0, min(n, w.samples.Len())), n)
+}
+
+// percentile interpolates the p-th percentile of sorted samples.
+func percentile(sorted []float64, p float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	index := (p / 100.0) * float64(n-1)
+	i := int(index)
+	if i >= n-1 {
+		return sorted[n-1]
+	}
+	if i < 0 {
+		return sorted[0]
+	}
+	return sorted[i] + (index-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// sortedSamples returns a sorted copy of samples.
+func sortedSamples(samples []float64) []float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	return sorted
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampleWindow_AggregatesAfterEviction(t *testing.T) {
+	w := newSampleWindow(4)
+	for _, v := range []float64{5, 1, 7, 3, 2, 6} {
+		w.add(v)
+	}
+	// The window holds 7, 3, 2, 6
+	s := w.snapshot()
+	if s.n != 4 || s.min != 2 || s.max != 7 || s.avg != 4.5 || s.peak != 7 || s.current != 6 {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	// Sample standard deviation of 7, 3, 2, 6
+	if want := math.Sqrt(17.0 / 3); math.Abs(s.stddev-want) > 1e-9 {
+		t.Errorf("stddev = %f, want %f", s.stddev, want)
+	}
+
+	w.add(1)
+	w.add(1)
+	// The window holds 2, 6, 1, 1
+	if s := w.snapshot(); s.min != 1 || s.max != 6 {
+		t.Errorf("after the max left, min/max = %f/%f, want 1/6", s.min, s.max)
+	}
+}
+
+func TestGetPercentile_RefreshesOnUpdate(t *testing.T) {
+	m := NewMemoryVisualization(&bytes.Buffer{}, 80)
+	for _, v := range []float64{3, 1, 2} {
+		m.Update(v, 0)
+	}
+	if p := m.GetPercentile(100); p != 3 {
+		t.Fatalf("p100 = %f, want 3", p)
+	}
+	m.Update(9, 0)
+	if p := m.GetPercentile(100); p != 9 {
+		t.Errorf("p100 after a new sample = %f, want 9", p)
+	}
+	if p := m.GetPercentile(0); p != 1 {
+		t.Errorf("p0 = %f, want 1", p)
+	}
+}
+
+func TestMemoryVisualization_ConcurrentSampleAndRender(t *testing.T) {
+	m := NewMemoryVisualization(&bytes.Buffer{}, 80)
+	m.SetMaxSamples(50)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ctx.Err() == nil; i++ {
+			m.Update(float64(i%10), 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			m.Render()
+			m.GetPercentile(95)
+			m.GetFormattedStats()
+		}
+	}()
+	wg.Wait()
+
+	if n := len(m.ExportHistory()); n != 50 {
+		t.Errorf("history holds %d samples, want 50", n)
+	}
+}
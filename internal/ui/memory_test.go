@@ -2,8 +2,12 @@ package ui
 
 import (
 	"bytes"
+	"math"
 	"strings"
 	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/ui/layout"
 )
 
 func TestNewMemoryVisualization(t *testing.T) {
@@ -62,6 +66,34 @@ func TestPrediction(t *testing.T) {
 	}
 }
 
+func TestPredictionFeedback(t *testing.T) {
+	m := NewMemoryVisualization(&bytes.Buffer{}, 80)
+	if m.SafetyMarginGB() != 0 || m.GetPredictionAccuracy() != 0 {
+		t.Fatal("expected no margin or accuracy before any prediction")
+	}
+
+	// Underpredicted by 1 GB, then overpredicted by 2 GB
+	m.SetPrediction(3.0, "Implement", "default")
+	m.Update(4.0, 0)
+	m.RecordPredictionAccuracy()
+	m.SetPrediction(6.0, "Implement", "default")
+	m.Update(4.0, 0)
+	m.RecordPredictionAccuracy()
+
+	if margin := m.SafetyMarginGB(); margin != 1.0 {
+		t.Errorf("SafetyMarginGB() = %f, want the 1 GB underprediction", margin)
+	}
+	// (1 - 1/4 + 1 - 2/6) / 2
+	if acc := m.GetPredictionAccuracy(); math.Abs(acc-0.7083) > 0.001 {
+		t.Errorf("GetPredictionAccuracy() = %f, want 0.708", acc)
+	}
+
+	m.RecordProcessUsage(orchestrate.ScheduleImplement, orchestrate.Process1, 4.0)
+	if gb, _, basis := m.PredictForProcess(orchestrate.ScheduleImplement, orchestrate.Process1); gb != 4.0 || basis != "historical avg (n=1)" {
+		t.Errorf("PredictForProcess() = %f (%s), want the recorded 4 GB", gb, basis)
+	}
+}
+
 func TestPressureStatus(t *testing.T) {
 	var buf bytes.Buffer
 	m := NewMemoryVisualization(&buf, 80)
@@ -153,3 +185,57 @@ func TestVolatility(t *testing.T) {
 		t.Errorf("Expected positive volatility after change")
 	}
 }
+
+func TestRender_FitsWidth(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMemoryVisualization(&buf, 60)
+	m.Update(2.0, 3.0)
+	m.Update(4.0, 0)
+	m.SetPrediction(5.0, "", "")
+
+	for _, line := range strings.Split(m.Render(), "\n") {
+		if w := layout.Width(line); w > 60 {
+			t.Errorf("line %q is %d columns wide, want at most 60", line, w)
+		}
+	}
+}
+
+func TestMemoryVRAMPrediction(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMemoryVisualization(&buf, 120)
+	m.SetTotalMemory(32)
+	m.Update(4, 0)
+
+	if strings.Contains(m.Render(), "VRAM") {
+		t.Error("VRAM shown before its total is known")
+	}
+
+	m.SetVRAM(18, 24)
+	m.PredictModelLoad("qwen2.5-coder:14b", 24, 3)
+	gb, label, basis := m.GetPrediction()
+	if gb != 7 || label != "qwen2.5-coder:14b" || !strings.Contains(basis, "spills") {
+		t.Errorf("GetPrediction() = %v, %q, %q; want 7 GB with the spill", gb, label, basis)
+	}
+	if out := m.Render(); !strings.Contains(out, "VRAM") || !strings.Contains(out, "→ 24.0 GB") {
+		t.Errorf("Render() has no VRAM prediction:\n%s", out)
+	}
+}
+
+func TestMemoryUpdateInPlace_SkipsUnchanged(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMemoryVisualization(&buf, 80)
+	m.refreshInterval = 0
+	m.Update(2.0, 0)
+	m.Draw()
+	drawn := buf.Len()
+
+	m.UpdateInPlace()
+	if buf.Len() != drawn {
+		t.Error("UpdateInPlace redrew an unchanged frame")
+	}
+	m.Update(3.0, 0)
+	m.UpdateInPlace()
+	if buf.Len() == drawn {
+		t.Error("UpdateInPlace did not redraw a changed frame")
+	}
+}
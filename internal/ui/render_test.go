@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/test"
+	"github.com/croberts/obot/internal/ui/vterm"
+)
+
+// assertScreen checks that output left no style in effect and used only
+// sequences the terminal understands, then compares the screen with the
+// golden file name. Run with UPDATE_GOLDEN=true to rewrite it.
+func assertScreen(t *testing.T, name string, screen *vterm.Screen) {
+	t.Helper()
+	if screen.Styled() {
+		t.Errorf("%s leaves a style in effect", name)
+	}
+	if u := screen.Unhandled(); len(u) > 0 {
+		t.Errorf("%s writes unknown escape sequences %q", name, u)
+	}
+	if screen.Wraps() > 0 {
+		t.Errorf("%s wraps %d lines", name, screen.Wraps())
+	}
+	test.AssertGolden(t, name, []byte(screen.String()+"\n"))
+}
+
+func TestStatusDisplay_Golden(t *testing.T) {
+	screen := vterm.New(60, 6)
+	d := NewStatusDisplay(screen, 60, time.Second)
+	d.SetOrchestratorState("Selecting")
+	d.SetQuotaStatus("coder 12/40")
+	d.SetSchedule("Implement")
+	d.SetProcess("Implement")
+	d.SetAgentAction("Edited internal/very/long/path/to/some/package/handler.go")
+	d.Draw()
+
+	// Redrawn in place over the first frame
+	d.SetAgentProgress("go test ./...")
+	d.Update()
+	assertScreen(t, "status_display", screen)
+}
+
+func TestMemoryVisualization_Golden(t *testing.T) {
+	screen := vterm.New(80, 8)
+	m := NewMemoryVisualization(screen, 80)
+	m.SetTotalMemory(16)
+	for _, gb := range []float64{4, 5, 6, 7, 9, 11, 12, 12.5, 13, 14} {
+		m.Update(gb, 0)
+	}
+	m.SetVRAM(20, 24)
+	m.PredictModelLoad("qwen2.5-coder:14b", 23, 1.5)
+	m.Draw()
+	assertScreen(t, "memory_bars", screen)
+}
+
+func TestBoxWithTitle_Golden(t *testing.T) {
+	screen := vterm.New(50, 8)
+	fmt.Fprintln(screen, BoxWithTitle("Memory Orchestration", []string{
+		"Current: " + FormatValue("4.0 GB"),
+		"宽 wide runes align",
+	}, 40))
+	assertScreen(t, "box_with_title", screen)
+}
@@ -0,0 +1,33 @@
+package term
+
+import (
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// noColors is set when colored output is turned off, so colors are on
+// until SetColors says otherwise.
+var noColors atomic.Bool
+
+// SetColors turns colored output on or off for every package that styles
+// its output.
+func SetColors(enabled bool) {
+	noColors.Store(!enabled)
+}
+
+// Colors reports whether output should be styled with ANSI escape
+// sequences.
+func Colors() bool {
+	return !noColors.Load()
+}
+
+// ColorsWanted reports whether output to w should be colored by default:
+// w is a terminal, $TERM is not "dumb", and $NO_COLOR is unset or empty
+// (https://no-color.org).
+func ColorsWanted(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(w)
+}
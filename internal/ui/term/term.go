@@ -0,0 +1,74 @@
+// Package term detects the size of the terminal output is written to and
+// follows it as the terminal is resized.
+package term
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Width limits
+const (
+	DefaultWidth = 80  // columns assumed when output is not a terminal
+	MinBoxWidth  = 40  // narrowest a report box is drawn
+	MaxBoxWidth  = 100 // widest a report box is drawn
+)
+
+// ErrNotTerminal is returned by CharMode for input that is not a terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+// fder is implemented by writers backed by a file descriptor, like *os.File.
+type fder interface {
+	Fd() uintptr
+}
+
+// Width returns the number of columns of the terminal w writes to. When w is
+// not a terminal, such as a file, pipe, or buffer, it returns $COLUMNS if
+// set, and DefaultWidth otherwise.
+func Width(w io.Writer) int {
+	if cols := columns(w); cols > 0 {
+		return cols
+	}
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return DefaultWidth
+}
+
+// IsTerminal reports whether w writes to a terminal.
+func IsTerminal(w io.Writer) bool {
+	return columns(w) > 0
+}
+
+// BoxWidth returns the width report boxes written to w are drawn at: the
+// terminal width, kept between MinBoxWidth and MaxBoxWidth so boxes stay
+// readable on narrow terminals and do not sprawl across wide ones.
+func BoxWidth(w io.Writer) int {
+	return min(max(Width(w), MinBoxWidth), MaxBoxWidth)
+}
+
+// columns returns the width of the terminal w writes to, or 0 when w is not
+// a terminal.
+func columns(w io.Writer) int {
+	f, ok := w.(fder)
+	if !ok {
+		return 0
+	}
+	return fdColumns(f.Fd())
+}
+
+// CharMode switches the terminal r reads from to character mode until
+// restore is called: keys arrive one at a time and unechoed, Enter is read
+// as '\r' and Ctrl-J as '\n', and Ctrl-C still interrupts. Where the
+// platform allows, reads return empty after a tenth of a second without a
+// key (io.EOF from an *os.File), so a reader can notice it should stop. It
+// returns ErrNotTerminal when r is not a terminal.
+func CharMode(r io.Reader) (restore func() error, err error) {
+	f, ok := r.(fder)
+	if !ok {
+		return nil, ErrNotTerminal
+	}
+	return charMode(f.Fd())
+}
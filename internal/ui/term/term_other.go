@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package term
+
+import "io"
+
+// fdColumns returns 0: terminal sizes are not available on this platform.
+func fdColumns(fd uintptr) int {
+	return 0
+}
+
+// NotifyResize does nothing on this platform.
+func NotifyResize(w io.Writer, fn func(width int)) (stop func()) {
+	return func() {}
+}
+
+// charMode fails: terminal modes are not available on this platform.
+func charMode(fd uintptr) (func() error, error) {
+	return nil, ErrNotTerminal
+}
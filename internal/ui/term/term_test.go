@@ -0,0 +1,59 @@
+package term
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWidth_NotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+
+	t.Setenv("COLUMNS", "")
+	if got := Width(&buf); got != DefaultWidth {
+		t.Errorf("Width() = %d, want %d", got, DefaultWidth)
+	}
+	if IsTerminal(&buf) {
+		t.Error("IsTerminal() = true for a buffer")
+	}
+
+	t.Setenv("COLUMNS", "132")
+	if got := Width(&buf); got != 132 {
+		t.Errorf("Width() with COLUMNS=132 = %d", got)
+	}
+
+	t.Setenv("COLUMNS", "wide")
+	if got := Width(&buf); got != DefaultWidth {
+		t.Errorf("Width() with a bad COLUMNS = %d, want %d", got, DefaultWidth)
+	}
+}
+
+func TestBoxWidth(t *testing.T) {
+	var buf bytes.Buffer
+	tests := []struct {
+		columns string
+		want    int
+	}{
+		{"20", MinBoxWidth},
+		{"72", 72},
+		{"200", MaxBoxWidth},
+	}
+	for _, tt := range tests {
+		t.Setenv("COLUMNS", tt.columns)
+		if got := BoxWidth(&buf); got != tt.want {
+			t.Errorf("BoxWidth() with COLUMNS=%s = %d, want %d", tt.columns, got, tt.want)
+		}
+	}
+}
+
+func TestColorsWanted(t *testing.T) {
+	var buf bytes.Buffer
+	t.Setenv("NO_COLOR", "")
+	if ColorsWanted(&buf) {
+		t.Error("ColorsWanted() = true for a buffer")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ColorsWanted(os.Stdout) {
+		t.Error("ColorsWanted() = true with NO_COLOR set")
+	}
+}
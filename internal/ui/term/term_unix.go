@@ -0,0 +1,65 @@
+//go:build unix
+
+package term
+
+import (
+	"io"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// fdColumns returns the width of the terminal open on fd, or 0.
+func fdColumns(fd uintptr) int {
+	ws, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
+
+// NotifyResize calls fn with the new width of the terminal w writes to each
+// time the terminal is resized, until stop is called. It does nothing when
+// w is not a terminal.
+func NotifyResize(w io.Writer, fn func(width int)) (stop func()) {
+	if !IsTerminal(w) {
+		return func() {}
+	}
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, unix.SIGWINCH)
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				fn(Width(w))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// charMode switches the terminal open on fd to character mode.
+func charMode(fd uintptr) (func() error, error) {
+	old, err := unix.IoctlGetTermios(int(fd), ioctlGetTermios)
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	t := *old
+	t.Lflag &^= unix.ICANON | unix.ECHO | unix.IEXTEN
+	t.Iflag &^= unix.ICRNL | unix.INLCR | unix.IXON
+	t.Cc[unix.VMIN] = 0
+	t.Cc[unix.VTIME] = 1
+	if err := unix.IoctlSetTermios(int(fd), ioctlSetTermios, &t); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return unix.IoctlSetTermios(int(fd), ioctlSetTermios, old)
+	}, nil
+}
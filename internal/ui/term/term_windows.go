@@ -0,0 +1,41 @@
+//go:build windows
+
+package term
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+// fdColumns returns the width of the console window open on fd, or 0.
+func fdColumns(fd uintptr) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(fd), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Right-info.Window.Left) + 1
+}
+
+// NotifyResize does nothing: Windows consoles do not signal resizes, so
+// widths are taken when output is drawn.
+func NotifyResize(w io.Writer, fn func(width int)) (stop func()) {
+	return func() {}
+}
+
+// charMode switches the console open on fd to character mode, reading
+// keys as VT sequences. Reads block until a key is pressed.
+func charMode(fd uintptr) (func() error, error) {
+	h := windows.Handle(fd)
+	var old uint32
+	if err := windows.GetConsoleMode(h, &old); err != nil {
+		return nil, ErrNotTerminal
+	}
+	mode := old&^(windows.ENABLE_LINE_INPUT|windows.ENABLE_ECHO_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(h, mode); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return windows.SetConsoleMode(h, old)
+	}, nil
+}
@@ -0,0 +1,126 @@
+package ui
+
+import "github.com/croberts/obot/internal/ui/term"
+
+// Theme is a palette of ANSI styles the UI draws with. An empty style
+// writes text as is, so the zero Theme is plain output.
+type Theme struct {
+	Name string
+
+	Reset string
+	Bold  string
+	Dim   string
+
+	Accent     string // labels, borders of focus, flow-code processes
+	AccentBold string
+	AccentDim  string
+
+	Text          string
+	TextSecondary string
+	TextMuted     string
+	Border        string
+
+	White     string
+	WhiteBold string // flow-code schedules
+	WhiteDim  string
+
+	Success   string
+	Error     string
+	ErrorBold string
+	Warning   string
+	FlowError string
+
+	Orchestrator string
+	Coder        string
+	Researcher   string
+	Vision       string
+
+	BgDark      string
+	BgSecondary string
+	BgSelection string
+}
+
+// TokyoBlueTheme is the default theme: Tokyo Night in true color.
+var TokyoBlueTheme = Theme{
+	Name:          "tokyo-blue",
+	Reset:         "\033[0m",
+	Bold:          "\033[1m",
+	Dim:           "\033[2m",
+	Accent:        "\033[38;2;125;207;255m", // #7dcfff
+	AccentBold:    "\033[1;38;2;125;207;255m",
+	AccentDim:     "\033[2;38;2;125;207;255m",
+	Text:          "\033[38;2;192;202;245m", // #c0caf5
+	TextSecondary: "\033[38;2;154;165;206m", // #9aa5ce
+	TextMuted:     "\033[38;2;86;95;137m",   // #565f89
+	Border:        "\033[38;2;59;66;97m",    // #3b4261
+	White:         "\033[38;2;192;202;245m",
+	WhiteBold:     "\033[1;38;2;192;202;245m",
+	WhiteDim:      "\033[2;38;2;192;202;245m",
+	Success:       "\033[38;2;115;192;255m", // #73c0ff
+	Error:         "\033[38;2;255;85;85m",
+	ErrorBold:     "\033[1;38;2;255;85;85m",
+	Warning:       "\033[38;2;224;175;104m", // #e0af68
+	FlowError:     "\033[38;2;247;118;142m",
+	Orchestrator:  "\033[38;2;122;162;247m", // #7aa2f7
+	Coder:         "\033[38;2;125;207;255m", // #7dcfff
+	Researcher:    "\033[38;2;42;195;222m",  // #2ac3de
+	Vision:        "\033[38;2;90;143;212m",  // #5a8fd4
+	BgDark:        "\033[48;2;26;27;38m",    // #1a1b26
+	BgSecondary:   "\033[48;2;36;40;59m",    // #24283b
+	BgSelection:   "\033[48;2;51;70;124m",   // #33467c
+}
+
+// PlainTheme writes no escape sequences, for NO_COLOR, --no-colors, and
+// output that is not a terminal.
+var PlainTheme = Theme{Name: "plain"}
+
+// activeTheme is the theme drawn with while colors are on.
+var activeTheme = TokyoBlueTheme
+
+// ApplyTheme makes t the theme the UI draws with. Like SetColors, it is
+// meant to be called at startup, before anything is drawn.
+func ApplyTheme(t Theme) {
+	activeTheme = t
+	if term.Colors() {
+		setPalette(t)
+	}
+}
+
+// CurrentTheme returns the theme set by ApplyTheme.
+func CurrentTheme() Theme {
+	return activeTheme
+}
+
+// SetColors turns colors on or off across the UI and the packages that
+// consult term.Colors. With colors off every style is empty.
+func SetColors(enabled bool) {
+	term.SetColors(enabled)
+	if enabled {
+		setPalette(activeTheme)
+	} else {
+		setPalette(PlainTheme)
+	}
+}
+
+// setPalette points the package styles at a theme's.
+func setPalette(t Theme) {
+	ANSIReset, Reset = t.Reset, t.Reset
+	ANSIBold, Bold = t.Bold, t.Bold
+	ANSIDim, Dim = t.Dim, t.Dim
+
+	TokyoBlue, TokyoBlueBold, TokyoBlueDim = t.Accent, t.AccentBold, t.AccentDim
+	ANSIBlue, ANSIBlueBold, ANSICyan = t.Accent, t.AccentBold, t.Accent
+
+	TextPrimary, TextSecondary, TextMuted, TextBorder = t.Text, t.TextSecondary, t.TextMuted, t.Border
+	ANSIWhite, ANSIWhiteBold, ANSIWhiteDim = t.White, t.WhiteBold, t.WhiteDim
+
+	ANSIGreen = t.Success
+	ANSIRed, ANSIRedBold = t.Error, t.ErrorBold
+	ANSIYellow = t.Warning
+
+	OrchestratorColor, CoderColor, ResearcherColor, VisionColor = t.Orchestrator, t.Coder, t.Researcher, t.Vision
+
+	FlowSchedule, FlowProcess, FlowError = t.WhiteBold, t.Accent, t.FlowError
+
+	BgDark, BgSecondary, BgSelection = t.BgDark, t.BgSecondary, t.BgSelection
+}
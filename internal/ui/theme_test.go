@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+func TestSetColors(t *testing.T) {
+	SetColors(false)
+	defer SetColors(true)
+
+	if got := FormatLabel("Agent") + Color(ANSIRed, "error") + Box([]string{"row"}, 20); strings.Contains(got, "\x1b") {
+		t.Errorf("output with colors off has escape sequences: %q", got)
+	}
+	if got := orchestrate.FormatFlowCodeColored("S1P12X"); got != "S1P12X" {
+		t.Errorf("FormatFlowCodeColored() with colors off = %q", got)
+	}
+
+	SetColors(true)
+	if got := FormatLabel("Agent"); got != TokyoBlueTheme.Accent+"Agent"+TokyoBlueTheme.Reset {
+		t.Errorf("FormatLabel() with colors on = %q", got)
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	defer ApplyTheme(TokyoBlueTheme)
+
+	custom := TokyoBlueTheme
+	custom.Accent = "\x1b[34m"
+	ApplyTheme(custom)
+	if ANSIBlue != "\x1b[34m" || FlowProcess != "\x1b[34m" {
+		t.Errorf("ApplyTheme() left ANSIBlue = %q, FlowProcess = %q", ANSIBlue, FlowProcess)
+	}
+
+	SetColors(false)
+	if ANSIBlue != "" {
+		t.Errorf("ANSIBlue with colors off = %q", ANSIBlue)
+	}
+	SetColors(true)
+	if CurrentTheme().Accent != "\x1b[34m" || ANSIBlue != "\x1b[34m" {
+		t.Error("SetColors(true) did not restore the applied theme")
+	}
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DarkTheme is Tokyo Night with its full palette: green success, red
+// errors, and blue accents.
+var DarkTheme = Theme{
+	Name:          "dark",
+	Reset:         "\033[0m",
+	Bold:          "\033[1m",
+	Dim:           "\033[2m",
+	Accent:        "\033[38;2;122;162;247m", // #7aa2f7
+	AccentBold:    "\033[1;38;2;122;162;247m",
+	AccentDim:     "\033[2;38;2;122;162;247m",
+	Text:          "\033[38;2;192;202;245m", // #c0caf5
+	TextSecondary: "\033[38;2;169;177;214m", // #a9b1d6
+	TextMuted:     "\033[38;2;86;95;137m",   // #565f89
+	Border:        "\033[38;2;59;66;97m",    // #3b4261
+	White:         "\033[38;2;192;202;245m",
+	WhiteBold:     "\033[1;38;2;192;202;245m",
+	WhiteDim:      "\033[2;38;2;192;202;245m",
+	Success:       "\033[38;2;158;206;106m", // #9ece6a
+	Error:         "\033[38;2;247;118;142m", // #f7768e
+	ErrorBold:     "\033[1;38;2;247;118;142m",
+	Warning:       "\033[38;2;224;175;104m", // #e0af68
+	FlowError:     "\033[38;2;247;118;142m",
+	Orchestrator:  "\033[38;2;187;154;247m", // #bb9af7
+	Coder:         "\033[38;2;125;207;255m", // #7dcfff
+	Researcher:    "\033[38;2;115;218;202m", // #73daca
+	Vision:        "\033[38;2;255;158;100m", // #ff9e64
+	BgDark:        "\033[48;2;26;27;38m",    // #1a1b26
+	BgSecondary:   "\033[48;2;36;40;59m",    // #24283b
+	BgSelection:   "\033[48;2;51;70;124m",   // #33467c
+}
+
+// LightTheme is Tokyo Night Day, for light terminal backgrounds.
+var LightTheme = Theme{
+	Name:          "light",
+	Reset:         "\033[0m",
+	Bold:          "\033[1m",
+	Dim:           "\033[2m",
+	Accent:        "\033[38;2;46;125;233m", // #2e7de9
+	AccentBold:    "\033[1;38;2;46;125;233m",
+	AccentDim:     "\033[2;38;2;46;125;233m",
+	Text:          "\033[38;2;55;96;191m",   // #3760bf
+	TextSecondary: "\033[38;2;97;114;176m",  // #6172b0
+	TextMuted:     "\033[38;2;132;140;181m", // #848cb5
+	Border:        "\033[38;2;168;174;203m", // #a8aecb
+	White:         "\033[38;2;52;59;88m",    // #343b58, the darkest text
+	WhiteBold:     "\033[1;38;2;52;59;88m",
+	WhiteDim:      "\033[2;38;2;52;59;88m",
+	Success:       "\033[38;2;88;117;57m",  // #587539
+	Error:         "\033[38;2;245;42;101m", // #f52a65
+	ErrorBold:     "\033[1;38;2;245;42;101m",
+	Warning:       "\033[38;2;140;108;62m", // #8c6c3e
+	FlowError:     "\033[38;2;245;42;101m",
+	Orchestrator:  "\033[38;2;152;84;241m",  // #9854f1
+	Coder:         "\033[38;2;0;113;151m",   // #007197
+	Researcher:    "\033[38;2;17;140;116m",  // #118c74
+	Vision:        "\033[38;2;177;92;0m",    // #b15c00
+	BgDark:        "\033[48;2;225;226;231m", // #e1e2e7
+	BgSecondary:   "\033[48;2;208;213;227m", // #d0d5e3
+	BgSelection:   "\033[48;2;183;193;227m", // #b7c1e3
+}
+
+// HighContrastTheme uses the bright 16-color palette, which terminals
+// render at full contrast and users can remap, with bold emphasis and no
+// dimmed text.
+var HighContrastTheme = Theme{
+	Name:          "high-contrast",
+	Reset:         "\033[0m",
+	Bold:          "\033[1m",
+	Dim:           "",
+	Accent:        "\033[96m",
+	AccentBold:    "\033[1;96m",
+	AccentDim:     "\033[96m",
+	Text:          "\033[97m",
+	TextSecondary: "\033[97m",
+	TextMuted:     "\033[37m",
+	Border:        "\033[97m",
+	White:         "\033[97m",
+	WhiteBold:     "\033[1;97m",
+	WhiteDim:      "\033[37m",
+	Success:       "\033[92m",
+	Error:         "\033[1;91m",
+	ErrorBold:     "\033[1;91m",
+	Warning:       "\033[1;93m",
+	FlowError:     "\033[1;91m",
+	Orchestrator:  "\033[95m",
+	Coder:         "\033[96m",
+	Researcher:    "\033[92m",
+	Vision:        "\033[93m",
+}
+
+// ColorblindTheme uses the Okabe-Ito palette, which stays distinct with
+// the common forms of color blindness: success is blue and errors are
+// vermillion rather than green and red.
+var ColorblindTheme = Theme{
+	Name:          "colorblind",
+	Reset:         "\033[0m",
+	Bold:          "\033[1m",
+	Dim:           "\033[2m",
+	Accent:        "\033[38;2;86;180;233m", // #56b4e9 sky blue
+	AccentBold:    "\033[1;38;2;86;180;233m",
+	AccentDim:     "\033[2;38;2;86;180;233m",
+	Text:          "\033[38;2;230;230;230m",
+	TextSecondary: "\033[38;2;190;190;190m",
+	TextMuted:     "\033[38;2;140;140;140m",
+	Border:        "\033[38;2;110;110;110m",
+	White:         "\033[38;2;230;230;230m",
+	WhiteBold:     "\033[1;38;2;230;230;230m",
+	WhiteDim:      "\033[2;38;2;230;230;230m",
+	Success:       "\033[38;2;0;114;178m", // #0072b2 blue
+	Error:         "\033[38;2;213;94;0m",  // #d55e00 vermillion
+	ErrorBold:     "\033[1;38;2;213;94;0m",
+	Warning:       "\033[38;2;230;159;0m",   // #e69f00 orange
+	FlowError:     "\033[1;38;2;213;94;0m",  // bold, so it does not rely on hue
+	Orchestrator:  "\033[38;2;204;121;167m", // #cc79a7 reddish purple
+	Coder:         "\033[38;2;86;180;233m",  // #56b4e9 sky blue
+	Researcher:    "\033[38;2;0;158;115m",   // #009e73 bluish green
+	Vision:        "\033[38;2;240;228;66m",  // #f0e442 yellow
+}
+
+// MonochromeTheme distinguishes text by weight and underline alone, for
+// terminals without color and readers who cannot rely on it.
+var MonochromeTheme = Theme{
+	Name:        "monochrome",
+	Reset:       "\033[0m",
+	Bold:        "\033[1m",
+	Dim:         "\033[2m",
+	Accent:      "\033[1m",
+	AccentBold:  "\033[1m",
+	AccentDim:   "\033[2m",
+	TextMuted:   "\033[2m",
+	WhiteBold:   "\033[1m",
+	WhiteDim:    "\033[2m",
+	Error:       "\033[1;4m",
+	ErrorBold:   "\033[1;4m",
+	Warning:     "\033[4m",
+	FlowError:   "\033[1;4m",
+	BgSelection: "\033[7m",
+}
+
+// themes are the built-in themes by name.
+var themes = map[string]Theme{
+	TokyoBlueTheme.Name:    TokyoBlueTheme,
+	DarkTheme.Name:         DarkTheme,
+	LightTheme.Name:        LightTheme,
+	HighContrastTheme.Name: HighContrastTheme,
+	ColorblindTheme.Name:   ColorblindTheme,
+	MonochromeTheme.Name:   MonochromeTheme,
+	PlainTheme.Name:        PlainTheme,
+}
+
+// ThemeByName returns the built-in theme with the given name; "" is the
+// default theme.
+func ThemeByName(name string) (Theme, error) {
+	if name == "" {
+		return TokyoBlueTheme, nil
+	}
+	t, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithColors returns t with the roles in colors set to hex colors
+// ("#7aa2f7"), so a config can adjust a theme. The roles are accent, text,
+// text_secondary, muted, border, success, error, warning, orchestrator,
+// coder, researcher, and vision; accent and error also set their bold and
+// dim variants.
+func (t Theme) WithColors(colors map[string]string) (Theme, error) {
+	for _, role := range sortedRoles(colors) {
+		fg, err := hexStyle(colors[role])
+		if err != nil {
+			return t, fmt.Errorf("theme color %s: %w", role, err)
+		}
+		switch role {
+		case "accent":
+			t.Accent, t.AccentBold, t.AccentDim = "\033["+fg+"m", "\033[1;"+fg+"m", "\033[2;"+fg+"m"
+		case "text":
+			t.Text, t.White, t.WhiteBold, t.WhiteDim = "\033["+fg+"m", "\033["+fg+"m", "\033[1;"+fg+"m", "\033[2;"+fg+"m"
+		case "text_secondary":
+			t.TextSecondary = "\033[" + fg + "m"
+		case "muted":
+			t.TextMuted = "\033[" + fg + "m"
+		case "border":
+			t.Border = "\033[" + fg + "m"
+		case "success":
+			t.Success = "\033[" + fg + "m"
+		case "error":
+			t.Error, t.ErrorBold, t.FlowError = "\033["+fg+"m", "\033[1;"+fg+"m", "\033["+fg+"m"
+		case "warning":
+			t.Warning = "\033[" + fg + "m"
+		case "orchestrator":
+			t.Orchestrator = "\033[" + fg + "m"
+		case "coder":
+			t.Coder = "\033[" + fg + "m"
+		case "researcher":
+			t.Researcher = "\033[" + fg + "m"
+		case "vision":
+			t.Vision = "\033[" + fg + "m"
+		default:
+			return t, fmt.Errorf("unknown theme color role %q", role)
+		}
+	}
+	return t, nil
+}
+
+// sortedRoles returns the roles of a color map in order, so errors are
+// reported deterministically.
+func sortedRoles(colors map[string]string) []string {
+	roles := make([]string, 0, len(colors))
+	for role := range colors {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// hexStyle converts "#rrggbb" to the SGR parameters of a true color
+// foreground, "38;2;r;g;b".
+func hexStyle(hex string) (string, error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 6 {
+		return "", fmt.Errorf("%q is not a #rrggbb color", hex)
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a #rrggbb color", hex)
+	}
+	return fmt.Sprintf("38;2;%d;%d;%d", v>>16, v>>8&0xff, v&0xff), nil
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeByName(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := ThemeByName(name)
+		if err != nil {
+			t.Fatalf("ThemeByName(%q) error = %v", name, err)
+		}
+		if theme.Name != name {
+			t.Errorf("ThemeByName(%q).Name = %q", name, theme.Name)
+		}
+		if name != "plain" && theme.Reset == "" {
+			t.Errorf("theme %q has styles but no reset", name)
+		}
+	}
+	if theme, err := ThemeByName(""); err != nil || theme.Name != TokyoBlueTheme.Name {
+		t.Errorf("ThemeByName(\"\") = %q, %v", theme.Name, err)
+	}
+	if theme, err := ThemeByName("High-Contrast"); err != nil || theme.Name != "high-contrast" {
+		t.Errorf("ThemeByName is case sensitive: %q, %v", theme.Name, err)
+	}
+	if _, err := ThemeByName("solarized"); err == nil || !strings.Contains(err.Error(), "monochrome") {
+		t.Errorf("ThemeByName(unknown) error = %v, want the available themes", err)
+	}
+}
+
+func TestTheme_WithColors(t *testing.T) {
+	theme, err := DarkTheme.WithColors(map[string]string{"accent": "#ff8000", "error": "00ff00"})
+	if err != nil {
+		t.Fatalf("WithColors() error = %v", err)
+	}
+	if theme.Accent != "\x1b[38;2;255;128;0m" || theme.AccentBold != "\x1b[1;38;2;255;128;0m" {
+		t.Errorf("accent = %q, %q", theme.Accent, theme.AccentBold)
+	}
+	if theme.Error != "\x1b[38;2;0;255;0m" || theme.FlowError != theme.Error {
+		t.Errorf("error = %q, flow error = %q", theme.Error, theme.FlowError)
+	}
+	if theme.Success != DarkTheme.Success {
+		t.Error("WithColors() changed a role it was not given")
+	}
+
+	if _, err := DarkTheme.WithColors(map[string]string{"accent": "blue"}); err == nil {
+		t.Error("WithColors() accepted a color that is not hex")
+	}
+	if _, err := DarkTheme.WithColors(map[string]string{"sidebar": "#ffffff"}); err == nil {
+		t.Error("WithColors() accepted an unknown role")
+	}
+}
+
+func TestMonochromeTheme_NoColors(t *testing.T) {
+	defer ApplyTheme(TokyoBlueTheme)
+	ApplyTheme(MonochromeTheme)
+
+	out := FormatLabel("Agent") + FormatFlowCode("S1P12X") + FormatError("failed")
+	if strings.Contains(out, "38;") || strings.Contains(out, "[3") || strings.Contains(out, "[9") {
+		t.Errorf("monochrome output has colors: %q", out)
+	}
+}
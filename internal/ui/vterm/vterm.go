@@ -0,0 +1,277 @@
+// Package vterm is an in-memory terminal for testing rendering: it applies
+// text and the escape sequences the UI writes to a grid of cells, so tests
+// can compare what a user would see instead of raw escape bytes.
+package vterm
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/croberts/obot/internal/ui/layout"
+)
+
+// Cell is one column of the screen: its rune and the SGR sequences in
+// effect when it was written. The second column of a wide rune holds 0.
+type Cell struct {
+	Rune  rune
+	Style string
+}
+
+// Screen is a virtual terminal of fixed size. Lines wrap at the width and
+// the screen scrolls up when writing past the last row.
+type Screen struct {
+	width, height int
+	cells         [][]Cell
+	row, col      int
+	savedRow      int
+	savedCol      int
+	pen           string // SGR sequences since the last reset
+	pending       []byte // an escape sequence or rune split across writes
+	unhandled     []string
+	wraps         int
+	scrolled      int
+}
+
+// New returns a blank screen of width columns and height rows.
+func New(width, height int) *Screen {
+	s := &Screen{width: max(width, 1), height: max(height, 1)}
+	s.cells = make([][]Cell, s.height)
+	for i := range s.cells {
+		s.cells[i] = s.blankRow()
+	}
+	return s
+}
+
+func (s *Screen) blankRow() []Cell {
+	row := make([]Cell, s.width)
+	for i := range row {
+		row[i] = Cell{Rune: ' '}
+	}
+	return row
+}
+
+// Write applies p to the screen. It never fails.
+func (s *Screen) Write(p []byte) (int, error) {
+	data := append(s.pending, p...)
+	s.pending = nil
+	for i := 0; i < len(data); {
+		if data[i] == 0x1b {
+			n := escapeLen(data[i:])
+			if n == 0 {
+				s.pending = append([]byte(nil), data[i:]...)
+				break
+			}
+			s.escape(string(data[i : i+n]))
+			i += n
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			s.pending = append([]byte(nil), data[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		s.put(r)
+		i += size
+	}
+	return len(p), nil
+}
+
+// escapeLen returns the length of the escape sequence at the start of b,
+// or 0 if it is incomplete.
+func escapeLen(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	if b[1] != '[' {
+		return 2
+	}
+	for j := 2; j < len(b); j++ {
+		if b[j] >= 0x40 && b[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return 0
+}
+
+// put writes a rune at the cursor, or moves the cursor for controls.
+func (s *Screen) put(r rune) {
+	switch r {
+	case '\n':
+		s.lineFeed()
+		s.col = 0
+		return
+	case '\r':
+		s.col = 0
+		return
+	case '\t':
+		s.col = min((s.col/8+1)*8, s.width-1)
+		return
+	case '\b':
+		s.col = max(s.col-1, 0)
+		return
+	}
+	w := layout.RuneWidth(r)
+	if w == 0 {
+		return
+	}
+	if s.col+w > s.width {
+		s.wraps++
+		s.lineFeed()
+		s.col = 0
+	}
+	s.cells[s.row][s.col] = Cell{Rune: r, Style: s.pen}
+	if w == 2 {
+		s.cells[s.row][s.col+1] = Cell{Style: s.pen}
+	}
+	s.col += w
+}
+
+// lineFeed moves the cursor down a row, scrolling at the bottom.
+func (s *Screen) lineFeed() {
+	if s.row < s.height-1 {
+		s.row++
+		return
+	}
+	copy(s.cells, s.cells[1:])
+	s.cells[s.height-1] = s.blankRow()
+	s.scrolled++
+}
+
+// escape applies an escape sequence, recording those it does not know.
+func (s *Screen) escape(seq string) {
+	switch seq {
+	case "\x1b7":
+		s.savedRow, s.savedCol = s.row, s.col
+		return
+	case "\x1b8":
+		s.row, s.col = s.savedRow, s.savedCol
+		return
+	}
+	if !strings.HasPrefix(seq, "\x1b[") {
+		s.unhandled = append(s.unhandled, seq)
+		return
+	}
+	params, final := seq[2:len(seq)-1], seq[len(seq)-1]
+	n := func(def int) int {
+		if v, err := strconv.Atoi(params); err == nil && v > 0 {
+			return v
+		}
+		return def
+	}
+	switch final {
+	case 'm':
+		if params == "" || params == "0" {
+			s.pen = ""
+		} else {
+			s.pen += seq
+		}
+	case 'A':
+		s.row = max(s.row-n(1), 0)
+	case 'B':
+		s.row = min(s.row+n(1), s.height-1)
+	case 'C':
+		s.col = min(s.col+n(1), s.width-1)
+	case 'D':
+		s.col = max(s.col-n(1), 0)
+	case 'G':
+		s.col = min(n(1), s.width) - 1
+	case 'H':
+		row, col, _ := strings.Cut(params, ";")
+		s.row, s.col = min(atoi(row, 1), s.height)-1, min(atoi(col, 1), s.width)-1
+	case 'K':
+		from, to := s.col, s.width
+		switch params {
+		case "1":
+			from, to = 0, s.col+1
+		case "2":
+			from = 0
+		}
+		for i := from; i < to && i < s.width; i++ {
+			s.cells[s.row][i] = Cell{Rune: ' '}
+		}
+	case 'J':
+		if params == "2" || params == "3" {
+			for i := range s.cells {
+				s.cells[i] = s.blankRow()
+			}
+		} else {
+			for i := s.col; i < s.width; i++ {
+				s.cells[s.row][i] = Cell{Rune: ' '}
+			}
+			for i := s.row + 1; i < s.height; i++ {
+				s.cells[i] = s.blankRow()
+			}
+		}
+	case 's':
+		s.savedRow, s.savedCol = s.row, s.col
+	case 'u':
+		s.row, s.col = s.savedRow, s.savedCol
+	case 'h', 'l':
+		// Modes such as hiding the cursor do not change the cells
+	default:
+		s.unhandled = append(s.unhandled, seq)
+	}
+}
+
+func atoi(s string, def int) int {
+	if v, err := strconv.Atoi(s); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// Line returns row i as text, without trailing spaces.
+func (s *Screen) Line(i int) string {
+	var sb strings.Builder
+	for _, c := range s.cells[i] {
+		if c.Rune != 0 {
+			sb.WriteRune(c.Rune)
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// String returns the screen as text, one line per row, without trailing
+// spaces or trailing blank rows.
+func (s *Screen) String() string {
+	lines := make([]string, s.height)
+	for i := range lines {
+		lines[i] = s.Line(i)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cell returns the cell at row and col.
+func (s *Screen) Cell(row, col int) Cell {
+	return s.cells[row][col]
+}
+
+// Cursor returns the cursor's row and column.
+func (s *Screen) Cursor() (row, col int) {
+	return s.row, s.col
+}
+
+// Styled reports whether a style is still in effect, as when output sets a
+// color and never resets it, which would leak into whatever is written next.
+func (s *Screen) Styled() bool {
+	return s.pen != ""
+}
+
+// Unhandled returns the escape sequences the screen did not understand.
+func (s *Screen) Unhandled() []string {
+	return append([]string(nil), s.unhandled...)
+}
+
+// Wraps returns how many times a line was too long and wrapped.
+func (s *Screen) Wraps() int {
+	return s.wraps
+}
+
+// Scrolled returns how many rows scrolled off the top.
+func (s *Screen) Scrolled() int {
+	return s.scrolled
+}
@@ -0,0 +1,81 @@
+package vterm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScreen_TextAndCursor(t *testing.T) {
+	s := New(10, 3)
+	fmt.Fprint(s, "hello\nworld")
+	if got := s.String(); got != "hello\nworld" {
+		t.Errorf("String() = %q", got)
+	}
+	if row, col := s.Cursor(); row != 1 || col != 5 {
+		t.Errorf("Cursor() = %d, %d; want 1, 5", row, col)
+	}
+}
+
+func TestScreen_InPlaceRedraw(t *testing.T) {
+	s := New(20, 4)
+	fmt.Fprint(s, "one\ntwo\n")
+	// Save, go up two lines, clear them, rewrite, restore
+	fmt.Fprint(s, "\x1b[s\x1b[2A\x1b[2K\n\x1b[2K\n\x1b[2Auno\ndos\x1b[u")
+	if got := s.String(); got != "uno\ndos" {
+		t.Errorf("String() = %q, want the lines replaced", got)
+	}
+	if row, col := s.Cursor(); row != 2 || col != 0 {
+		t.Errorf("Cursor() = %d, %d; want it restored to 2, 0", row, col)
+	}
+}
+
+func TestScreen_StylesAndLeaks(t *testing.T) {
+	s := New(20, 2)
+	fmt.Fprint(s, "\x1b[31mred\x1b[0m plain")
+	if c := s.Cell(0, 0); c.Rune != 'r' || c.Style != "\x1b[31m" {
+		t.Errorf("Cell(0, 0) = %+v, want a red r", c)
+	}
+	if c := s.Cell(0, 4); c.Style != "" {
+		t.Errorf("Cell(0, 4) = %+v, want unstyled", c)
+	}
+	if s.Styled() {
+		t.Error("Styled() after a reset")
+	}
+	fmt.Fprint(s, "\x1b[1mbold")
+	if !s.Styled() {
+		t.Error("Styled() = false with bold never reset")
+	}
+}
+
+func TestScreen_WrapScrollAndWide(t *testing.T) {
+	s := New(4, 2)
+	fmt.Fprint(s, "abcdef\nxy")
+	if s.Wraps() != 1 || s.Scrolled() != 1 {
+		t.Errorf("Wraps() = %d, Scrolled() = %d; want 1, 1", s.Wraps(), s.Scrolled())
+	}
+	if got := s.String(); got != "ef\nxy" {
+		t.Errorf("String() = %q", got)
+	}
+
+	s = New(6, 1)
+	fmt.Fprint(s, "a世b")
+	if got := s.Line(0); got != "a世b" {
+		t.Errorf("Line(0) = %q", got)
+	}
+	if _, col := s.Cursor(); col != 4 {
+		t.Errorf("cursor at column %d, want 4 after a wide rune", col)
+	}
+}
+
+func TestScreen_SplitWritesAndUnknownSequences(t *testing.T) {
+	s := New(10, 1)
+	s.Write([]byte("\x1b[3"))
+	s.Write([]byte("1mok\xe4"))
+	s.Write([]byte("\xb8\x96\x1b[5n"))
+	if got := s.Line(0); got != "ok世" {
+		t.Errorf("Line(0) = %q", got)
+	}
+	if u := s.Unhandled(); len(u) != 1 || u[0] != "\x1b[5n" {
+		t.Errorf("Unhandled() = %q", u)
+	}
+}
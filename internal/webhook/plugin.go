@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// Plugin is an orchestrator plugin that sends schedule and process events
+// and the prompt's termination to a dispatcher's webhooks.
+type Plugin struct {
+	*orchestrate.BaseOrchestratorPlugin
+
+	d *Dispatcher
+}
+
+// NewPlugin returns a plugin sending the orchestrator's events through d.
+func NewPlugin(d *Dispatcher) *Plugin {
+	return &Plugin{
+		BaseOrchestratorPlugin: orchestrate.NewBaseOrchestratorPlugin("webhook"),
+		d:                      d,
+	}
+}
+
+// OnStateChange sends the prompt's termination.
+func (p *Plugin) OnStateChange(ctx context.Context, state orchestrate.OrchestratorState) error {
+	if state == orchestrate.StatePromptTerminated {
+		p.d.setRunning("", "")
+		p.d.Send(Event{Event: EventPromptTerminated})
+	}
+	return nil
+}
+
+// OnScheduleStart sends the schedule's start.
+func (p *Plugin) OnScheduleStart(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	p.d.setRunning(scheduleID.String(), "")
+	p.d.Send(Event{Event: EventScheduleStart, Schedule: scheduleID.String()})
+	return nil
+}
+
+// OnProcessStart notes the process running, for the events sent during it.
+func (p *Plugin) OnProcessStart(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	p.d.setRunning(scheduleID.String(), processID.String())
+	return nil
+}
+
+// OnProcessEnd sends the process's end.
+func (p *Plugin) OnProcessEnd(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	p.d.Send(Event{Event: EventProcessEnd, Schedule: scheduleID.String(), Process: processID.String()})
+	return nil
+}
+
+// OnScheduleEnd sends the schedule's end.
+func (p *Plugin) OnScheduleEnd(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	p.d.Send(Event{Event: EventScheduleEnd, Schedule: scheduleID.String()})
+	p.d.setRunning("", "")
+	return nil
+}
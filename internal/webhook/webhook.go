@@ -0,0 +1,248 @@
+// Package webhook posts the events of an orchestration run, such as a
+// schedule starting or a consultation opening, to outbound HTTP webhooks,
+// so that chat bots and dashboards can follow a run as it happens.
+//
+// Each event is POSTed as a JSON Event. When the webhook has a secret, the
+// body is signed with HMAC-SHA256 and the signature sent as
+// "X-Obot-Signature: sha256=<hex>", for the receiver to check it came from
+// obot.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/redact"
+)
+
+// EventType names the kind of an event.
+type EventType string
+
+const (
+	EventScheduleStart         EventType = "schedule_start"
+	EventScheduleEnd           EventType = "schedule_end"
+	EventProcessEnd            EventType = "process_end"
+	EventConsultationRequested EventType = "consultation_requested"
+	EventError                 EventType = "error"
+	EventPromptTerminated      EventType = "prompt_terminated"
+)
+
+// Headers sent with each event.
+const (
+	SignatureHeader = "X-Obot-Signature"
+	EventHeader     = "X-Obot-Event"
+)
+
+// Event is one event of a run, in the fields the session records use: a
+// sequence number counting the run's events from 1, the session, and the
+// schedule and process it happened in. Details names further facts of the
+// event, such as an error's code or a consultation's kind.
+type Event struct {
+	Seq       int               `json:"seq"`
+	Time      time.Time         `json:"time"`
+	SessionID string            `json:"session_id"`
+	Event     EventType         `json:"event"`
+	Schedule  string            `json:"schedule,omitempty"`
+	Process   string            `json:"process,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Hook is a webhook endpoint and the secret signing the events sent to it.
+type Hook struct {
+	URL    string
+	Secret string
+}
+
+// sendTimeout bounds the delivery of an event to one webhook.
+const sendTimeout = 10 * time.Second
+
+// queueSize is the number of events held for delivery; events beyond it are
+// dropped rather than holding up the run.
+const queueSize = 256
+
+// closeTimeout bounds how long Close waits for the queued events to be
+// delivered; those still queued after it are dropped.
+const closeTimeout = 30 * time.Second
+
+// Dispatcher delivers a run's events to its webhooks in order, one at a
+// time, in the background. A nil Dispatcher sends nothing.
+type Dispatcher struct {
+	hooks     []Hook
+	sessionID string
+	client    *http.Client
+
+	mu       sync.Mutex
+	seq      int
+	schedule string
+	process  string
+	closed   bool
+
+	queue  chan Event
+	done   chan struct{}
+	ctx    context.Context // cancelled to drop the queued events
+	cancel context.CancelFunc
+}
+
+// New returns a dispatcher sending the events of sessionID to hooks, or nil
+// when there are no hooks.
+func New(sessionID string, hooks []Hook) *Dispatcher {
+	if len(hooks) == 0 {
+		return nil
+	}
+	d := &Dispatcher{
+		hooks:     hooks,
+		sessionID: sessionID,
+		client:    &http.Client{Timeout: sendTimeout},
+		queue:     make(chan Event, queueSize),
+		done:      make(chan struct{}),
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	go d.run()
+	return d
+}
+
+// Send queues an event for delivery, numbering it and filling in the time,
+// the session, and, when not set, the schedule and process running.
+func (d *Dispatcher) Send(e Event) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.seq++
+	e.Seq = d.seq
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.SessionID = d.sessionID
+	if e.Schedule == "" {
+		e.Schedule = d.schedule
+		if e.Process == "" {
+			e.Process = d.process
+		}
+	}
+	e.Message = redact.String("webhook", e.Message)
+	select {
+	case d.queue <- e:
+	default:
+		slog.Warn("webhook queue full, dropping event", "event", e.Event, "seq", e.Seq)
+	}
+}
+
+// setRunning records the schedule and process running, for the events sent
+// without them.
+func (d *Dispatcher) setRunning(schedule, process string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.schedule, d.process = schedule, process
+	d.mu.Unlock()
+}
+
+// Close stops taking events and waits for the queued ones to be delivered,
+// for up to closeTimeout.
+func (d *Dispatcher) Close() {
+	d.stop(closeTimeout)
+}
+
+// Abort stops taking events, drops the queued ones, and cancels the one
+// being delivered, for a run that must exit now.
+func (d *Dispatcher) Abort() {
+	d.stop(0)
+}
+
+// stop stops taking events and waits up to wait for the queued ones to be
+// delivered before dropping the rest.
+func (d *Dispatcher) stop(wait time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-d.done:
+			return
+		case <-timer.C:
+			slog.Warn("webhook delivery timed out, dropping queued events")
+		}
+	}
+	d.cancel()
+	<-d.done
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for e := range d.queue {
+		if d.ctx.Err() != nil {
+			continue
+		}
+		body, err := json.Marshal(e)
+		if err != nil {
+			slog.Warn("webhook event not encodable", "event", e.Event, "error", err)
+			continue
+		}
+		for _, h := range d.hooks {
+			if err := d.post(h, e.Event, body); err != nil {
+				slog.Warn("webhook delivery failed", "url", h.URL, "event", e.Event, "error", err)
+			}
+		}
+	}
+}
+
+// post delivers one event's body to a webhook.
+func (d *Dispatcher) post(h Hook, event EventType, body []byte) error {
+	ctx, cancel := context.WithTimeout(d.ctx, sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "obot-webhook")
+	req.Header.Set(EventHeader, string(event))
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is body's signature under secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, body)))
+}
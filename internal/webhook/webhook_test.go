@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// receiver records the events POSTed to it and whether each was signed.
+type receiver struct {
+	mu     sync.Mutex
+	events []Event
+	signed []bool
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil || req.Header.Get(EventHeader) != string(e.Event) {
+		http.Error(w, "bad event", http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	r.signed = append(r.signed, Verify("s3cret", body, req.Header.Get(SignatureHeader)))
+}
+
+func TestDispatcher_Send(t *testing.T) {
+	var r receiver
+	srv := httptest.NewServer(&r)
+	defer srv.Close()
+
+	d := New("sess-1", []Hook{{URL: srv.URL, Secret: "s3cret"}})
+	p := NewPlugin(d)
+	ctx := context.Background()
+	p.OnScheduleStart(ctx, orchestrate.ScheduleKnowledge)
+	p.OnProcessStart(ctx, orchestrate.ScheduleKnowledge, orchestrate.Process1)
+	d.Send(Event{Event: EventConsultationRequested, Message: "Which?", Details: map[string]string{"type": "clarify"}})
+	p.OnProcessEnd(ctx, orchestrate.ScheduleKnowledge, orchestrate.Process1)
+	p.OnScheduleEnd(ctx, orchestrate.ScheduleKnowledge)
+	p.OnStateChange(ctx, orchestrate.StatePromptTerminated)
+	d.Close()
+	d.Send(Event{Event: EventError})
+
+	want := []EventType{EventScheduleStart, EventConsultationRequested, EventProcessEnd, EventScheduleEnd, EventPromptTerminated}
+	if len(r.events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(r.events), len(want), r.events)
+	}
+	for i, e := range r.events {
+		if e.Event != want[i] || e.Seq != i+1 || e.SessionID != "sess-1" || e.Time.IsZero() {
+			t.Errorf("event %d = %+v, want %s seq %d of sess-1", i, e, want[i], i+1)
+		}
+		if !r.signed[i] {
+			t.Errorf("event %d is not signed with the secret", i)
+		}
+	}
+	if c := r.events[1]; c.Schedule != "Knowledge" || c.Process != "1" || c.Details["type"] != "clarify" {
+		t.Errorf("consultation = %+v, want it in the running Knowledge P1", c)
+	}
+	if end := r.events[4]; end.Schedule != "" || end.Process != "" {
+		t.Errorf("prompt terminated = %+v, want no schedule", end)
+	}
+}
+
+func TestDispatcher_Unsigned(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Clone()
+	}))
+	defer srv.Close()
+
+	d := New("sess-1", []Hook{{URL: srv.URL}})
+	d.Send(Event{Event: EventError, Message: "boom"})
+	d.Close()
+	if got == nil || got.Get(SignatureHeader) != "" || got.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want JSON without a signature", got)
+	}
+}
+
+func TestDispatcher_AbortDropsQueue(t *testing.T) {
+	var received atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received.Add(1)
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	d := New("sess-1", []Hook{{URL: srv.URL}})
+	for i := 0; i < 10; i++ {
+		d.Send(Event{Event: EventError})
+	}
+	start := time.Now()
+	d.Abort()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Abort() took %v, want it not to wait for delivery", elapsed)
+	}
+	if n := received.Load(); n > 1 {
+		t.Errorf("delivered %d events after Abort(), want the queue dropped", n)
+	}
+}
+
+func TestNew_NoHooks(t *testing.T) {
+	d := New("sess-1", nil)
+	if d != nil {
+		t.Fatal("New without hooks returned a dispatcher")
+	}
+	d.Send(Event{Event: EventError})
+	NewPlugin(d).OnScheduleStart(context.Background(), orchestrate.ScheduleKnowledge)
+	d.Close()
+	d.Abort()
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"seq":1}`)
+	sig := Sign("key", body)
+	if !Verify("key", body, sig) {
+		t.Error("Verify rejected the signature Sign made")
+	}
+	if Verify("other", body, sig) || Verify("key", []byte(`{"seq":2}`), sig) {
+		t.Error("Verify accepted a signature of another secret or body")
+	}
+}
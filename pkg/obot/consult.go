@@ -0,0 +1,145 @@
+package obot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/session"
+)
+
+// ErrNoConsultation is returned for an answer to a consultation that is not
+// waiting for one.
+var ErrNoConsultation = errors.New("obot: no such consultation waiting")
+
+// Consultation is a question the orchestration puts to the caller: a
+// Clarify during Plan or a Feedback during Implement.
+type Consultation struct {
+	ID       string
+	Type     string // clarify or feedback
+	Question string
+	Context  string
+	// Options are a Clarify's choices, answered with "A", "B", and so on,
+	// optionally followed by ": " and a rationale.
+	Options []string
+	// Default is the answer an empty answer gives.
+	Default string
+}
+
+// consultations hands the run's questions out as events and takes their
+// answers back, one question at a time.
+type consultations struct {
+	events *eventStream
+
+	mu      sync.Mutex
+	seq     int
+	pending *pendingConsultation
+}
+
+type pendingConsultation struct {
+	Consultation
+	answer chan string
+}
+
+// handler returns a consultation handler that asks through c, recording
+// the answers in sess. A question left unanswered for timeout gets the AI
+// substitute's answer; zero waits without a limit.
+func (c *consultations) handler(sess *session.Session, timeout time.Duration) *consultation.Handler {
+	seconds := int(timeout / time.Second)
+	if timeout > 0 && seconds == 0 {
+		seconds = 1
+	}
+	return consultation.NewHandler(nil, io.Discard, &consultation.Config{
+		TimeoutSeconds: seconds,
+		AllowAISub:     true,
+		OnRequest:      c.open,
+		Input:          c.wait,
+		OnWait:         func(time.Duration) { c.close() },
+		OnAnswer: func(req consultation.Request, resp *consultation.Response) {
+			sess.RecordConsultation(session.ConsultationRecord{
+				Type:      string(req.Type),
+				Question:  req.Question,
+				Options:   req.Options,
+				Answer:    resp.Content,
+				Source:    string(resp.Source),
+				Persona:   resp.Persona,
+				Timestamp: resp.Timestamp,
+			})
+		},
+	})
+}
+
+// open sends req out as the next consultation.
+func (c *consultations) open(req consultation.Request) {
+	c.mu.Lock()
+	c.seq++
+	p := &pendingConsultation{
+		Consultation: Consultation{
+			ID:       fmt.Sprintf("C%d", c.seq),
+			Type:     string(req.Type),
+			Question: req.Question,
+			Context:  req.Context,
+			Options:  req.Options,
+			Default:  req.Default,
+		},
+		answer: make(chan string, 1),
+	}
+	c.pending = p
+	c.mu.Unlock()
+
+	question := p.Consultation
+	c.events.send(Event{Type: EventConsultationRequested, Message: req.Question, Consultation: &question})
+}
+
+// current returns the consultation waiting for an answer, or nil.
+func (c *consultations) current() *Consultation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		return nil
+	}
+	question := c.pending.Consultation
+	return &question
+}
+
+// wait waits for the answer to the open consultation.
+func (c *consultations) wait(ctx context.Context, req consultation.Request) (string, error) {
+	c.mu.Lock()
+	p := c.pending
+	c.mu.Unlock()
+	if p == nil {
+		return "", ErrNoConsultation
+	}
+	select {
+	case answer := <-p.answer:
+		return answer, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// close stops taking answers to the open consultation.
+func (c *consultations) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = nil
+}
+
+// answer answers the consultation with the given ID.
+func (c *consultations) answer(id, answer string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil || c.pending.ID != id {
+		return fmt.Errorf("%w: %s", ErrNoConsultation, id)
+	}
+	select {
+	case c.pending.answer <- answer:
+		return nil
+	default:
+		return fmt.Errorf("obot: consultation %s already answered", id)
+	}
+}
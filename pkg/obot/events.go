@@ -0,0 +1,138 @@
+package obot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// EventType names what an Event reports. The names are those of the
+// CLI's webhook events.
+type EventType string
+
+const (
+	EventScheduleStart    EventType = "schedule_start"
+	EventScheduleEnd      EventType = "schedule_end"
+	EventProcessStart     EventType = "process_start"
+	EventProcessEnd       EventType = "process_end"
+	EventAction           EventType = "action"
+	EventError            EventType = "error"
+	EventPromptTerminated EventType = "prompt_terminated"
+
+	EventConsultationRequested EventType = "consultation_requested"
+)
+
+// eventBuffer is how many events the Events channel holds.
+const eventBuffer = 256
+
+// Event is something that happened in an orchestration.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Schedule and Process are the schedule and process running, like
+	// "Implement" and "P2", when there is one.
+	Schedule string
+	Process  string
+	// Message describes an action or error, or is a consultation's question.
+	Message string
+	// Consultation is the question of a consultation_requested event.
+	Consultation *Consultation
+}
+
+// eventStream delivers a run's events, stamped with the schedule and
+// process running, without blocking the run.
+type eventStream struct {
+	mu       sync.Mutex
+	ch       chan Event
+	closed   bool
+	schedule string
+	process  string
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{ch: make(chan Event, eventBuffer)}
+}
+
+// setRunning notes the schedule and process running.
+func (s *eventStream) setRunning(schedule, process string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule, s.process = schedule, process
+}
+
+// send delivers e unless the channel is full or closed.
+func (s *eventStream) send(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Schedule == "" {
+		e.Schedule, e.Process = s.schedule, s.process
+	}
+	select {
+	case s.ch <- e:
+	default:
+	}
+}
+
+func (s *eventStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// eventPlugin is an orchestrator plugin that sends the schedule and process
+// events and the prompt's termination to a stream.
+type eventPlugin struct {
+	*orchestrate.BaseOrchestratorPlugin
+
+	events *eventStream
+}
+
+func newEventPlugin(events *eventStream) *eventPlugin {
+	return &eventPlugin{
+		BaseOrchestratorPlugin: orchestrate.NewBaseOrchestratorPlugin("obot-events"),
+		events:                 events,
+	}
+}
+
+func (p *eventPlugin) OnStateChange(ctx context.Context, state orchestrate.OrchestratorState) error {
+	if state == orchestrate.StatePromptTerminated {
+		p.events.setRunning("", "")
+		p.events.send(Event{Type: EventPromptTerminated})
+	}
+	return nil
+}
+
+func (p *eventPlugin) OnScheduleStart(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	p.events.setRunning(scheduleID.String(), "")
+	p.events.send(Event{Type: EventScheduleStart})
+	return nil
+}
+
+func (p *eventPlugin) OnProcessStart(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	p.events.setRunning(scheduleID.String(), processID.String())
+	p.events.send(Event{Type: EventProcessStart})
+	return nil
+}
+
+func (p *eventPlugin) OnProcessEnd(ctx context.Context, scheduleID orchestrate.ScheduleID, processID orchestrate.ProcessID) error {
+	p.events.send(Event{Type: EventProcessEnd})
+	p.events.setRunning(scheduleID.String(), "")
+	return nil
+}
+
+func (p *eventPlugin) OnScheduleEnd(ctx context.Context, scheduleID orchestrate.ScheduleID) error {
+	p.events.send(Event{Type: EventScheduleEnd})
+	p.events.setRunning("", "")
+	return nil
+}
@@ -0,0 +1,460 @@
+// Package obot runs obot orchestrations from Go programs.
+//
+// Start runs a prompt through the Knowledge, Plan, Implement, Scale, and
+// Production schedules and streams what happens as Events; Run does the
+// same and waits for the Result. Runs are unattended unless Config.Consult
+// is set: the Clarify and Feedback consultations are skipped, and a failing
+// process ends the run.
+//
+// Runs are guarded as the CLI's are: the workspace policy of
+// .obot/policy.yaml applies, with the actions it requires confirming
+// declined unless Config.Confirm approves them; a watchdog enforces the
+// default process budget; a loop detector stops a process repeating
+// itself; and every action goes to the workspace audit log.
+// Sessions are saved where the CLI keeps them, so `obot session show` lists
+// them too.
+package obot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/audit"
+	"github.com/croberts/obot/internal/consultation"
+	"github.com/croberts/obot/internal/hooks"
+	"github.com/croberts/obot/internal/model"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+	"github.com/croberts/obot/internal/schedule"
+	"github.com/croberts/obot/internal/session"
+)
+
+// Model roles, the keys of Config.Models
+const (
+	RoleOrchestrator = string(orchestrate.ModelOrchestrator)
+	RoleCoder        = string(orchestrate.ModelCoder)
+	RoleResearcher   = string(orchestrate.ModelResearcher)
+	RoleVision       = string(orchestrate.ModelVision)
+)
+
+// Config describes one orchestration.
+type Config struct {
+	// Prompt is what the orchestration works on.
+	Prompt string
+	// Workspace is the directory the agent works in; empty uses the
+	// current one, and a relative one is resolved when the run starts. The
+	// policy, audit log, and hooks are found under it directly, but the
+	// agent's paths and commands are relative to the working directory, so
+	// the process changes into it for the run and only one run goes at a
+	// time.
+	Workspace string
+	// OllamaURL is the Ollama server; empty uses the default.
+	OllamaURL string
+	// Models overrides the model of a role, keyed by the Role constants.
+	Models map[string]string
+	// Strategy decides the next schedule and process: "heuristic" (the
+	// default), "llm", "hybrid", or "script", which runs StrategyScript.
+	Strategy       string
+	StrategyScript string
+	// SessionDir is where the session is saved; empty uses the CLI's
+	// ~/.config/ollamabot/sessions.
+	SessionDir string
+	// Consult has the Plan and Implement schedules put their Clarify and
+	// Feedback questions to the caller as consultation_requested events,
+	// answered with Orchestration.Answer.
+	Consult bool
+	// ConsultationTimeout is how long a question waits for its answer
+	// before the AI substitute answers it; zero waits without a limit.
+	ConsultationTimeout time.Duration
+	// Confirm decides whether an action the workspace policy requires
+	// confirming may run, given its summary and the policy's reason; nil
+	// declines them all.
+	Confirm func(action, reason string) bool
+	// Hooks runs the workspace's .obot/hooks scripts around processes and
+	// schedules, like obot orchestrate --hooks; the caller vouches for
+	// them. A failing hook is reported as an error event.
+	Hooks bool
+}
+
+// Result is what an orchestration did.
+type Result struct {
+	SessionID string
+	// FlowCode is the schedules and processes run, like "S1P1P2P3S2P1P2P3".
+	FlowCode string
+	Prompt   string
+	Tokens   int64
+	Actions  int
+	// FilesChanged are the files created, edited, deleted, renamed, moved,
+	// or copied, in the order first changed.
+	FilesChanged []string
+	Duration     time.Duration
+}
+
+// runMu keeps runs from changing the working directory under each other.
+var runMu sync.Mutex
+
+// Orchestration is a started run.
+type Orchestration struct {
+	sessionID string
+	events    *eventStream
+	consults  *consultations
+	done      chan struct{}
+	result    *Result
+	err       error
+}
+
+// Start starts an orchestration of cfg and returns without waiting for it.
+// The run stops when ctx is cancelled.
+func Start(ctx context.Context, cfg Config) (*Orchestration, error) {
+	if strings.TrimSpace(cfg.Prompt) == "" {
+		return nil, errors.New("obot: no prompt")
+	}
+	for role := range cfg.Models {
+		switch role {
+		case RoleOrchestrator, RoleCoder, RoleResearcher, RoleVision:
+		default:
+			return nil, fmt.Errorf("obot: unknown model role %q", role)
+		}
+	}
+	if cfg.Workspace != "" {
+		abs, err := filepath.Abs(cfg.Workspace)
+		if err != nil {
+			return nil, fmt.Errorf("obot: workspace: %w", err)
+		}
+		cfg.Workspace = abs
+		if info, err := os.Stat(cfg.Workspace); err != nil {
+			return nil, fmt.Errorf("obot: workspace: %w", err)
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("obot: workspace %s is not a directory", cfg.Workspace)
+		}
+	}
+
+	r, err := newRun(cfg)
+	if err != nil {
+		return nil, err
+	}
+	o := &Orchestration{
+		sessionID: r.sess.GetID(),
+		events:    r.events,
+		consults:  r.consults,
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(o.done)
+		defer r.events.close()
+		o.result, o.err = r.run(ctx)
+	}()
+	return o, nil
+}
+
+// Run runs an orchestration of cfg and returns its result. The result is
+// returned along with the error of a run that failed part way.
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	o, err := Start(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return o.Wait()
+}
+
+// SessionID returns the ID of the run's session.
+func (o *Orchestration) SessionID() string { return o.sessionID }
+
+// Events returns the run's events, closed when the run ends. Events are
+// dropped while the channel is full, so a slow reader does not hold up the
+// run.
+func (o *Orchestration) Events() <-chan Event { return o.events.ch }
+
+// Answer answers the consultation with the given ID, of a
+// consultation_requested event. It returns ErrNoConsultation when that
+// consultation is not waiting for an answer.
+func (o *Orchestration) Answer(id, answer string) error {
+	return o.consults.answer(id, answer)
+}
+
+// Consultation returns the consultation waiting for an answer, or nil, for
+// callers that missed its event.
+func (o *Orchestration) Consultation() *Consultation {
+	return o.consults.current()
+}
+
+// Wait waits for the run to end and returns its result.
+func (o *Orchestration) Wait() (*Result, error) {
+	<-o.done
+	return o.result, o.err
+}
+
+// run is the state of one orchestration.
+type run struct {
+	cfg      Config
+	orch     *orchestrate.Orchestrator
+	coord    *model.Coordinator
+	ag       *agent.Agent
+	sess     *session.Session
+	events   *eventStream
+	consults *consultations
+	tokens   atomic.Int64
+
+	watchdog *agent.Watchdog
+	loops    *agent.LoopDetector
+	auditLog *audit.Log
+}
+
+func newRun(cfg Config) (*run, error) {
+	client := ollama.NewClient()
+	if cfg.OllamaURL != "" {
+		client = ollama.NewClient(ollama.WithBaseURL(cfg.OllamaURL))
+	}
+	coord := model.NewCoordinator(client)
+	for role, name := range cfg.Models {
+		coord.SetModel(orchestrate.ModelType(role), name)
+		coord.Get(orchestrate.ModelType(role)).SetModel(name)
+	}
+	name := cfg.Strategy
+	if name == "" && cfg.StrategyScript != "" {
+		name = orchestrate.StrategyScript
+	}
+	strategy, err := orchestrate.NewStrategy(name, coord.GetOrchestratorModel(), cfg.StrategyScript)
+	if err != nil {
+		return nil, fmt.Errorf("obot: %w", err)
+	}
+	coord.SetStrategy(strategy)
+
+	dir := cfg.SessionDir
+	if dir == "" {
+		dir = session.DefaultBaseDir()
+	}
+	sess := session.NewSessionWithBaseDir(dir)
+	sess.SetPrompt(cfg.Prompt)
+
+	orch := orchestrate.NewOrchestrator()
+	orch.SetPrompt(cfg.Prompt)
+	events := newEventStream()
+	orch.RegisterPlugin(newEventPlugin(events))
+
+	ag := agent.NewAgent(coord)
+	watchdog := agent.NewWatchdog(agent.DefaultBudget())
+	ag.RegisterPlugin(watchdog)
+	loops := agent.NewLoopDetector(agent.DefaultLoopMaxRepeats, agent.DefaultLoopSimilarity)
+	ag.RegisterPlugin(loops)
+
+	return &run{
+		cfg:      cfg,
+		orch:     orch,
+		coord:    coord,
+		ag:       ag,
+		sess:     sess,
+		events:   events,
+		consults: &consultations{events: events},
+		watchdog: watchdog,
+		loops:    loops,
+	}, nil
+}
+
+// run runs the orchestration in the workspace and saves its session.
+func (r *run) run(ctx context.Context) (*Result, error) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	prev, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("obot: %w", err)
+	}
+	workspace := r.cfg.Workspace
+	if workspace == "" {
+		workspace = prev
+	} else {
+		if err := os.Chdir(workspace); err != nil {
+			return nil, fmt.Errorf("obot: %w", err)
+		}
+		defer os.Chdir(prev)
+	}
+	if err := r.guard(workspace); err != nil {
+		return nil, err
+	}
+	defer r.closeGuards()
+
+	start := time.Now()
+	r.ag.SetActionCallback(func(a agent.Action) {
+		r.events.send(Event{Type: EventAction, Message: a.ActionOutput()})
+	})
+	r.ag.SetTokenCallback(func(u agent.TokenUsage) {
+		r.tokens.Add(u.Tokens)
+	})
+
+	err = r.orch.Run(ctx, r.selectSchedule, r.selectProcess, r.executeProcess())
+	switch {
+	case err == nil:
+		r.sess.SetStatus(session.StatusCompleted)
+	case errors.Is(err, context.Canceled):
+		r.sess.SetStatus(session.StatusInterrupted)
+	default:
+		r.sess.SetStatus(session.StatusFailed)
+		r.events.send(Event{Type: EventError, Message: err.Error()})
+	}
+	r.sess.SetFlowCode(r.orch.GetFlowCode())
+	if saveErr := r.sess.Save(); saveErr != nil {
+		err = errors.Join(err, fmt.Errorf("obot: save session: %w", saveErr))
+	}
+	return r.result(time.Since(start)), err
+}
+
+// guard applies the workspace's policy to the agent, records its actions
+// in the workspace audit log, and runs its hooks when asked to.
+func (r *run) guard(workspace string) error {
+	policy, err := agent.LoadPolicy(workspace)
+	if err != nil {
+		return fmt.Errorf("obot: load policy: %w", err)
+	}
+	r.ag.SetPolicy(policy, func(c agent.PolicyCheck) bool {
+		return r.cfg.Confirm != nil && r.cfg.Confirm(c.Summary(), c.Reason)
+	})
+
+	log, err := audit.Open(audit.DefaultPath(workspace))
+	if err != nil {
+		return fmt.Errorf("obot: open audit log: %w", err)
+	}
+	r.auditLog = log
+	r.ag.RegisterPlugin(audit.NewPlugin(log, r.sess.GetID()))
+
+	if r.cfg.Hooks {
+		runner := hooks.NewRunner(workspace, r.sess.GetID())
+		r.orch.RegisterPlugin(hooks.NewPlugin(runner, func(err error) {
+			r.events.send(Event{Type: EventError, Message: err.Error()})
+		}))
+	}
+	return nil
+}
+
+// closeGuards closes what guard opened.
+func (r *run) closeGuards() {
+	if r.auditLog != nil {
+		r.auditLog.Close()
+	}
+}
+
+// selectSchedule has the strategy pick the next schedule, 0 to terminate.
+func (r *run) selectSchedule(ctx context.Context) (orchestrate.ScheduleID, error) {
+	next, terminate, err := r.coord.SelectNextSchedule(ctx, r.orch)
+	if err != nil || terminate {
+		return 0, err
+	}
+	return next, nil
+}
+
+// selectProcess has the strategy pick the next process, starting with P1.
+func (r *run) selectProcess(ctx context.Context, schedID orchestrate.ScheduleID, last orchestrate.ProcessID) (orchestrate.ProcessID, bool, error) {
+	if last == 0 {
+		return orchestrate.Process1, false, nil
+	}
+	return r.coord.SelectNextProcess(ctx, r.orch, schedID, last)
+}
+
+// executeProcess returns the function that runs a process through its
+// schedule's logic handler and the agent.
+func (r *run) executeProcess() func(context.Context, orchestrate.ScheduleID, orchestrate.ProcessID) error {
+	var clarify, feedback *consultation.Handler
+	if r.cfg.Consult {
+		clarify = r.consults.handler(r.sess, r.cfg.ConsultationTimeout)
+		feedback = r.consults.handler(r.sess, r.cfg.ConsultationTimeout)
+	}
+	plan := schedule.NewPlanSchedule(clarify)
+	plan.Response = func() string { _, response := r.ag.LastExchange(); return response }
+	implement := schedule.NewImplementSchedule(feedback)
+	implement.Actions = r.ag.GetActions
+	handlers := map[orchestrate.ScheduleID]schedule.LogicHandler{
+		orchestrate.ScheduleKnowledge:  schedule.NewKnowledgeSchedule(),
+		orchestrate.SchedulePlan:       plan,
+		orchestrate.ScheduleImplement:  implement,
+		orchestrate.ScheduleProduction: schedule.NewProductionSchedule(),
+	}
+	return func(ctx context.Context, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID) error {
+		handler, ok := handlers[schedID]
+		if !ok {
+			handler = schedule.GetLogicHandler(schedID)
+		}
+		exec := func(ctx context.Context, guidance string) error {
+			return r.executeAgent(ctx, schedID, procID, guidance)
+		}
+		if handler == nil {
+			return exec(ctx, "")
+		}
+		return handler.ExecuteProcess(ctx, procID, exec)
+	}
+}
+
+// executeAgent runs the agent on the prompt, the notes, and a handler's
+// instructions for the process.
+func (r *run) executeAgent(ctx context.Context, schedID orchestrate.ScheduleID, procID orchestrate.ProcessID, guidance string) error {
+	prompt := r.orch.GetPrompt()
+	if notes := r.orch.NotesPrompt(schedID); notes != "" {
+		prompt += "\n\n" + notes
+	}
+	if guidance != "" {
+		prompt += "\n\n" + guidance
+	}
+	tokensBefore, actionsBefore := r.tokens.Load(), len(r.ag.GetActions())
+	r.ag.SetContext(schedID, procID)
+
+	// The watchdog and loop detector end the process; unattended, there is
+	// no one to decide how to recover, so the run fails as on any error
+	budget := r.watchdog.Budget()
+	procCtx, cancel := ctx, context.CancelFunc(func() {})
+	if budget.MaxDuration > 0 {
+		procCtx, cancel = context.WithTimeout(ctx, budget.MaxDuration)
+	}
+	err := r.ag.Execute(procCtx, schedID, procID, prompt)
+	deadlineHit := procCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+	cancel()
+	if used := r.tokens.Load() - tokensBefore; used > 0 {
+		r.orch.RecordTokens(used)
+	}
+	r.orch.RecordActions(len(r.ag.GetActions()) - actionsBefore)
+
+	tripped := r.watchdog.Tripped()
+	if tripped == nil && deadlineHit {
+		tripped = r.watchdog.Trip(fmt.Sprintf("exceeded max duration of %s", budget.MaxDuration))
+	}
+	if tripped != nil {
+		return fmt.Errorf("obot: process suspended: %w", tripped)
+	}
+	if loop := r.loops.Detected(); loop != nil {
+		return fmt.Errorf("obot: %w", loop)
+	}
+	return err
+}
+
+// result sums up the run.
+func (r *run) result(d time.Duration) *Result {
+	res := &Result{
+		SessionID: r.sess.GetID(),
+		FlowCode:  r.orch.GetFlowCode(),
+		Prompt:    r.cfg.Prompt,
+		Tokens:    r.tokens.Load(),
+		Duration:  d,
+	}
+	seen := make(map[string]bool)
+	for _, a := range r.ag.GetActions() {
+		res.Actions++
+		switch a.Type {
+		case agent.ActionCreateFile, agent.ActionEditFile, agent.ActionDeleteFile,
+			agent.ActionRenameFile, agent.ActionMoveFile, agent.ActionCopyFile:
+		default:
+			continue
+		}
+		for _, path := range []string{a.Path, a.NewPath} {
+			if path != "" && !seen[path] {
+				seen[path] = true
+				res.FilesChanged = append(res.FilesChanged, path)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,180 @@
+package obot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/croberts/obot/internal/agent"
+	"github.com/croberts/obot/internal/audit"
+	"github.com/croberts/obot/internal/ollama"
+	"github.com/croberts/obot/internal/orchestrate"
+)
+
+// fakeOllama returns the URL of a server whose models answer every
+// generate request with "COMPLETE".
+func fakeOllama(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(ollama.GenerateResponse{Response: "COMPLETE", Done: true, EvalCount: 5, PromptEvalCount: 5})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestStart(t *testing.T) {
+	cfg := Config{
+		Prompt:     "add a health check",
+		Workspace:  t.TempDir(),
+		OllamaURL:  fakeOllama(t),
+		Models:     map[string]string{RoleCoder: "tiny-coder"},
+		SessionDir: t.TempDir(),
+	}
+	o, err := Start(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[EventType]int)
+	var last Event
+	for e := range o.Events() {
+		counts[e.Type]++
+		if e.Type == EventProcessStart && (e.Schedule == "" || e.Process == "") {
+			t.Errorf("process event without its schedule and process: %+v", e)
+		}
+		last = e
+	}
+	res, err := o.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.FlowCode != "S1P1P2P3S2P1P2P3S3P1P2P3S4P1P2P3S5P1P2P3" {
+		t.Errorf("FlowCode = %q", res.FlowCode)
+	}
+	if res.SessionID != o.SessionID() || res.Tokens == 0 {
+		t.Errorf("result = %+v", res)
+	}
+	if counts[EventScheduleStart] != 5 || counts[EventProcessEnd] != 15 || last.Type != EventPromptTerminated {
+		t.Errorf("events = %v, last %s", counts, last.Type)
+	}
+
+	ids, err := Sessions(cfg.SessionDir)
+	if err != nil || len(ids) != 1 || ids[0] != res.SessionID {
+		t.Fatalf("Sessions() = %v, %v; want [%s]", ids, err, res.SessionID)
+	}
+	sess, err := LoadSession(cfg.SessionDir, res.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.Status != "completed" || sess.Prompt != cfg.Prompt || sess.FlowCode != res.FlowCode {
+		t.Errorf("session = %+v", sess)
+	}
+}
+
+func TestStart_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no prompt":    {},
+		"unknown role": {Prompt: "p", Models: map[string]string{"writer": "m"}},
+		"no workspace": {Prompt: "p", Workspace: "/nonexistent/obot"},
+		"no script":    {Prompt: "p", Strategy: "script"},
+	} {
+		if _, err := Start(context.Background(), cfg); err == nil {
+			t.Errorf("%s: Start() succeeded", name)
+		}
+	}
+}
+
+func TestStart_Consult(t *testing.T) {
+	o, err := Start(context.Background(), Config{
+		Prompt:     "add a health check",
+		Workspace:  t.TempDir(),
+		OllamaURL:  fakeOllama(t),
+		SessionDir: t.TempDir(),
+		Consult:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var asked []Consultation
+	for e := range o.Events() {
+		if e.Type != EventConsultationRequested {
+			continue
+		}
+		asked = append(asked, *e.Consultation)
+		if err := o.Answer("C0", "yes"); !errors.Is(err, ErrNoConsultation) {
+			t.Errorf("Answer(C0) error = %v, want ErrNoConsultation", err)
+		}
+		if err := o.Answer(e.Consultation.ID, "yes, approve"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := o.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 1 || asked[0].ID != "C1" || asked[0].Type != "feedback" || asked[0].Question == "" {
+		t.Errorf("consultations = %+v, want the Implement feedback", asked)
+	}
+	if o.Consultation() != nil {
+		t.Error("a consultation is still open")
+	}
+}
+
+// deleteDirPlugin deletes a directory as the agent starts each process.
+type deleteDirPlugin struct {
+	*agent.BasePlugin
+	ag   *agent.Agent
+	dir  string
+	errs []error
+}
+
+func (p *deleteDirPlugin) OnBeforeExecute(ctx context.Context, schedule, process string) error {
+	p.errs = append(p.errs, p.ag.DeleteDir(ctx, p.dir))
+	return nil
+}
+
+func TestRun_Guards(t *testing.T) {
+	workspace := t.TempDir()
+	build := filepath.Join(workspace, "build")
+	if err := os.Mkdir(build, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRun(Config{Prompt: "clean up", OllamaURL: fakeOllama(t), SessionDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.guard(workspace); err != nil {
+		t.Fatal(err)
+	}
+	defer r.closeGuards()
+	p := &deleteDirPlugin{BasePlugin: agent.NewBasePlugin("delete_dir"), ag: r.ag, dir: build}
+	r.ag.RegisterPlugin(p)
+	ctx := context.Background()
+
+	// Without Confirm, what the policy requires confirming is declined
+	if err := r.ag.Execute(ctx, orchestrate.ScheduleImplement, orchestrate.Process1, "clean up"); err != nil {
+		t.Fatal(err)
+	}
+	var policyErr *agent.PolicyError
+	if !errors.As(p.errs[0], &policyErr) || !policyErr.Declined {
+		t.Errorf("DeleteDir() without Confirm = %v, want it declined", p.errs[0])
+	}
+
+	var asked []string
+	r.cfg.Confirm = func(action, reason string) bool { asked = append(asked, action); return true }
+	if err := r.ag.Execute(ctx, orchestrate.ScheduleImplement, orchestrate.Process1, "clean up"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(build); !os.IsNotExist(err) || p.errs[1] != nil || len(asked) != 1 {
+		t.Errorf("confirmed delete_dir: %v, stat %v, asked %q", p.errs[1], err, asked)
+	}
+	if _, err := os.Stat(audit.DefaultPath(workspace)); err != nil {
+		t.Errorf("audit log: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package obot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/croberts/obot/internal/session"
+)
+
+// Session is a saved orchestration session.
+type Session struct {
+	ID        string
+	Prompt    string
+	FlowCode  string
+	Status    string // running, completed, interrupted, aborted, failed, or crashed
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Sessions lists the IDs of the sessions saved in dir; empty uses the CLI's
+// sessions directory.
+func Sessions(dir string) ([]string, error) {
+	if dir == "" {
+		dir = session.DefaultBaseDir()
+	}
+	return session.ListSessions(dir)
+}
+
+// LoadSession loads the session saved in dir with the given ID; an empty
+// dir uses the CLI's sessions directory.
+func LoadSession(dir, id string) (*Session, error) {
+	if dir == "" {
+		dir = session.DefaultBaseDir()
+	}
+	sess, err := session.Load(dir, id)
+	if err != nil {
+		return nil, fmt.Errorf("obot: session %s: %w", id, err)
+	}
+	return &Session{
+		ID:        sess.GetID(),
+		Prompt:    sess.GetPrompt(),
+		FlowCode:  sess.GetFlowCode(),
+		Status:    string(sess.GetStatus()),
+		CreatedAt: sess.CreatedAt,
+		UpdatedAt: sess.UpdatedAt,
+	}, nil
+}